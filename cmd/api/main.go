@@ -2,20 +2,45 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
+	"expvar"
+	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/analytics"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/auditlog"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/bridge"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/executor"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/keystore"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/liquidity"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/reference"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/rfq"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/signer"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/sqlite"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/subgraph"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/txmanager"
 	"github.com/bimakw/dex-aggregator/internal/presentation/handlers"
 )
 
@@ -26,6 +51,9 @@ const (
 func main() {
 	rpcURL := getEnv("ETH_RPC_URL", "https://eth.llamarpc.com")
 	redisAddr := getEnv("REDIS_ADDR", "")
+	blocklistPath := getEnv("BLOCKLIST_PATH", "configs/blocklist.json")
+	tokensPath := getEnv("TOKENS_PATH", "configs/tokens.json")
+	tokenGroupsPath := getEnv("TOKEN_GROUPS_PATH", "configs/token_groups.json")
 	port := getEnv("PORT", "8080")
 
 	ethClient, err := ethereum.NewClient(rpcURL)
@@ -35,11 +63,33 @@ func main() {
 	defer ethClient.Close()
 	log.Printf("Connected to Ethereum (chain ID: %s)", ethClient.ChainID().String())
 
+	// Background workers stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// EMBEDDED=true runs the instance on its own: state Redis would share
+	// goes to one SQLite file instead, and the cache stays in memory
+	var embedded *sqlite.Store
+	if getEnv("EMBEDDED", "false") == "true" {
+		path := getEnv("EMBEDDED_DB_PATH", "dex-aggregator.db")
+		if embedded, err = sqlite.Open(path); err != nil {
+			log.Fatalf("Failed to open embedded database: %v", err)
+		}
+		defer embedded.Close()
+		if redisAddr != "" {
+			log.Println("Embedded mode ignores REDIS_ADDR")
+			redisAddr = ""
+		}
+		log.Printf("Embedded mode, persisting to %s", path)
+	}
+
 	var cacheClient cache.Cache
+	var redisCache *cache.RedisCache
 	if redisAddr != "" {
-		redisCache, err := cache.NewRedisCache(redisAddr, "", 0)
+		redisCache, err = cache.NewRedisCache(redisAddr, "", 0)
 		if err != nil {
 			log.Printf("Warning: Failed to connect to Redis: %v. Using in-memory cache.", err)
+			redisCache = nil
 			cacheClient = cache.NewInMemoryCache()
 		} else {
 			cacheClient = redisCache
@@ -50,32 +100,652 @@ func main() {
 		log.Println("Using in-memory cache")
 	}
 
-	uniswapV2 := dex.NewUniswapV2Client(ethClient)
-	uniswapV3 := dex.NewUniswapV3Client(ethClient)
-	sushiswap := dex.NewSushiswapClient(ethClient)
-	curve := dex.NewCurveClient(ethClient)
-	balancer := dex.NewBalancerClient(ethClient)
-	dexClients := []dex.DEXClient{uniswapV2, uniswapV3, sushiswap, curve, balancer}
+	// Instances sharing Redis elect one of them to run each singleton job;
+	// without it, the instance runs them all
+	instanceID := getEnv("INSTANCE_ID", defaultInstanceID())
+	var leaders sync.Map // Job name to *services.LeaderElection
+	runSingleton := func(name string, lead, follow func(ctx context.Context)) {
+		if redisCache == nil {
+			lead(workerCtx)
+			return
+		}
+		election := services.NewLeaderElection(cache.NewRedisLocker(redisCache.Client()), name, instanceID, services.DefaultLeaseTTL)
+		leaders.Store(name, election)
+		election.Run(workerCtx, lead, follow)
+	}
+	expvar.Publish("leader_jobs", expvar.Func(func() any {
+		leading := make(map[string]bool)
+		leaders.Range(func(name, election any) bool {
+			leading[name.(string)] = election.(*services.LeaderElection).IsLeader()
+			return true
+		})
+		return leading
+	}))
+
+	dexClients, err := dex.Build(ethClient, parseDEXList(getEnv("DEXES", "")))
+	if err != nil {
+		log.Fatalf("Failed to configure DEX adapters: %v", err)
+	}
+	log.Printf("Routing across %d DEX adapters", len(dexClients))
 
 	priceService := services.NewPriceService(dexClients, cacheClient)
+	maxPairAge, err := strconv.ParseUint(getEnv("PAIR_MAX_AGE_BLOCKS", "2"), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid PAIR_MAX_AGE_BLOCKS: %v", err)
+	}
+	priceService.SetFreshnessGuard(ethClient, maxPairAge)
+
+	// Venue success rates feed /api/v1/stats/venues and the venue circuit
+	// breaker, which VENUE_BREAKER=false turns off
+	venueStatsWindow, err := time.ParseDuration(getEnv("VENUE_STATS_WINDOW", "5m"))
+	if err != nil || venueStatsWindow < time.Minute {
+		log.Fatalf("Invalid VENUE_STATS_WINDOW, must be at least 1m: %q", getEnv("VENUE_STATS_WINDOW", ""))
+	}
+	venueStats := services.NewVenueStats(venueStatsWindow)
+	venueStats.SetBreaker(getEnv("VENUE_BREAKER", "true") != "false")
+	priceService.SetVenueStats(venueStats)
+
+	depegThreshold, err := strconv.ParseInt(getEnv("DEPEG_THRESHOLD_BPS", strconv.Itoa(services.DefaultDepegThresholdBps)), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid DEPEG_THRESHOLD_BPS: %v", err)
+	}
+	depegMonitor := services.NewDepegMonitor(priceService, depegThreshold)
+	priceService.SetStablecoinPegs(depegMonitor)
+	go depegMonitor.Run(workerCtx, time.Minute)
+	expvar.Publish("stablecoin_pegs", expvar.Func(func() any { return depegMonitor.Pegs() }))
+
+	intermediateCount, err := strconv.Atoi(getEnv("INTERMEDIATE_TOKENS", "8"))
+	if err != nil {
+		log.Fatalf("Invalid INTERMEDIATE_TOKENS: %v", err)
+	}
+	intermediates := services.NewIntermediateIndex(intermediateCount, []entities.Token{entities.WETH, entities.USDC, entities.USDT, entities.DAI})
+	priceService.SetPoolObserver(intermediates)
+	go intermediates.Run(workerCtx, 5*time.Minute)
+
+	pairWatchLimit, err := strconv.Atoi(getEnv("PAIR_WATCH_LIMIT", "200"))
+	if err != nil {
+		log.Fatalf("Invalid PAIR_WATCH_LIMIT: %v", err)
+	}
+	if pairWatchLimit > 0 {
+		pairWatcher := services.NewPairWatcher(ethClient, pairWatchLimit)
+		priceService.SetLivePairs(pairWatcher)
+		go pairWatcher.Run(workerCtx, 15*time.Second)
+		expvar.Publish("watched_pairs", expvar.Func(func() any { return pairWatcher.Stats() }))
+	}
+
+	headWatcher := ethereum.NewHeadWatcher(ethClient, 64)
+	go headWatcher.Run(workerCtx, 12*time.Second, func(reorg ethereum.Reorg) {
+		log.Printf("Reorg detected: blocks %d-%d orphaned, flushing pair and price cache", reorg.FromBlock, reorg.ToBlock)
+		if err := priceService.Invalidate(workerCtx, reorg.FromBlock); err != nil {
+			log.Printf("Warning: Failed to flush cache after reorg: %v", err)
+		}
+	})
+	expvar.Publish("chain_reorgs", expvar.Func(func() any { return headWatcher.Reorgs() }))
+	expvar.Publish("outbound_http", expvar.Func(func() any { return httpclient.Stats() }))
+	expvar.Publish("rpc_calls", expvar.Func(func() any { return ethClient.Calls() }))
+
+	// Per-hop gas is learned from recent swaps unless GAS_CALIBRATION opts out
+	var gasHandler *handlers.GasHandler
+	if getEnv("GAS_CALIBRATION", "true") != "false" {
+		gasCalibrator := services.NewGasCalibrator(ethClient, 500)
+		if redisCache != nil {
+			gasCalibrator.SetStore(redisCache)
+		} else if embedded != nil {
+			gasCalibrator.SetStore(embedded)
+		}
+		// One instance scans blocks for swaps; the others load its medians
+		go runSingleton("gas_calibrator", func(ctx context.Context) {
+			gasCalibrator.Run(ctx, time.Minute)
+		}, func(ctx context.Context) {
+			gasCalibrator.Follow(ctx, time.Minute)
+		})
+		gasHandler = handlers.NewGasHandler(gasCalibrator)
+	}
+
 	routerService := services.NewRouterService(priceService)
+	routerService.SetIntermediateIndex(intermediates)
+	if err := routerService.SetDefaultStrategy(getEnv("ROUTING_STRATEGY", services.DefaultStrategy)); err != nil {
+		log.Fatalf("Invalid ROUTING_STRATEGY (available: %s): %v", strings.Join(routerService.Strategies(), ", "), err)
+	}
+	quoteDeadline, err := time.ParseDuration(getEnv("QUOTE_DEADLINE", services.DefaultQuoteDeadline.String()))
+	if err != nil || quoteDeadline <= 0 {
+		log.Fatalf("Invalid QUOTE_DEADLINE: %q", getEnv("QUOTE_DEADLINE", ""))
+	}
+	routerService.SetQuoteDeadline(quoteDeadline)
+	// Quotes recommend a size within the impact ceiling and flag, or with
+	// TRADE_SIZE_REJECT=true reject, trades too large for the pools
+	sizeGuard := services.DefaultTradeSizeGuard
+	if sizeGuard.ImpactCeilingBps, err = strconv.ParseUint(getEnv("TRADE_SIZE_IMPACT_CEILING_BPS", strconv.FormatUint(sizeGuard.ImpactCeilingBps, 10)), 10, 64); err != nil || sizeGuard.ImpactCeilingBps == 0 || sizeGuard.ImpactCeilingBps >= 10000 {
+		log.Fatalf("Invalid TRADE_SIZE_IMPACT_CEILING_BPS: %q", getEnv("TRADE_SIZE_IMPACT_CEILING_BPS", ""))
+	}
+	if sizeGuard.MaxDepthBps, err = strconv.ParseUint(getEnv("TRADE_SIZE_MAX_DEPTH_BPS", strconv.FormatUint(sizeGuard.MaxDepthBps, 10)), 10, 64); err != nil {
+		log.Fatalf("Invalid TRADE_SIZE_MAX_DEPTH_BPS: %v", err)
+	}
+	sizeGuard.Reject = getEnv("TRADE_SIZE_REJECT", "false") == "true"
+	routerService.SetTradeSizeGuard(sizeGuard)
+	swapBuilder := swap.NewBuilder()
+	swapService := services.NewSwapService(swapBuilder, ethClient)
+	swapService.SetApprovalService(services.NewApprovalService(swapBuilder, ethClient))
+	// Each optional contract below joins the spenders users approve
+	spenders := services.NewSpenderDirectory(ethClient.ChainID().Uint64())
+	for _, router := range swap.Routers() {
+		spenders.Add(router)
+	}
+	// Split quotes become one transaction once the executor contract is deployed
+	if address := getEnv("EXECUTOR_ADDRESS", ""); address != "" {
+		if !common.IsHexAddress(address) {
+			log.Fatalf("Invalid EXECUTOR_ADDRESS: %s", address)
+		}
+		swapService.SetExecutor(executor.NewEncoder(common.HexToAddress(address)))
+		spenders.Add(entities.Spender{Name: "executor", Address: common.HexToAddress(address), DEXes: executor.DEXes, Purpose: "Split and mixed-venue swaps"})
+		log.Printf("Split and mixed-venue swaps execute through %s", address)
+	}
+	// Uniswap-only quotes go through the Universal Router, approved once via Permit2
+	if getEnv("UNIVERSAL_ROUTER", "false") == "true" {
+		swapService.SetUniversalRouter(swap.NewUniversalRouter(swap.UniversalRouterAddress, swap.Permit2Address))
+		spenders.Add(entities.Spender{Name: "permit2", Address: swap.Permit2Address, DEXes: []entities.DEXType{entities.DEXUniswapV2, entities.DEXUniswapV3}, Purpose: "Uniswap swaps through the Universal Router; approve Permit2, then allow the Universal Router on it"})
+		spenders.Add(entities.Spender{Name: "universal_router", Address: swap.UniversalRouterAddress, DEXes: []entities.DEXType{entities.DEXUniswapV2, entities.DEXUniswapV3}, Purpose: "Allowed on Permit2 rather than on the token"})
+		log.Printf("Uniswap swaps execute through the Universal Router")
+	}
+	// routeProcessor=true quotes carry their route for Sushi's RouteProcessor
+	routeProcessor := getEnv("ROUTE_PROCESSOR_ADDRESS", swap.RouteProcessorAddress.Hex())
+	if !common.IsHexAddress(routeProcessor) {
+		log.Fatalf("Invalid ROUTE_PROCESSOR_ADDRESS: %s", routeProcessor)
+	}
+	swapService.SetRouteProcessor(swap.NewRouteProcessor(common.HexToAddress(routeProcessor)))
+	spenders.Add(entities.Spender{Name: "route_processor", Address: common.HexToAddress(routeProcessor), DEXes: []entities.DEXType{entities.DEXUniswapV2, entities.DEXSushiswap, entities.DEXUniswapV3}, Purpose: "Routes built with routeProcessor=true, run through Sushi's RouteProcessor"})
+	feeService := services.NewFeeService(ethClient, priceService)
+	routerService.SetFeeService(feeService)
+	ensResolver := ethereum.NewENSResolver(ethClient)
+
+	// RFQ is enabled by configuring the settlement contract makers sign for
+	var rfqHub *rfq.Hub
+	if settlement := getEnv("RFQ_SETTLEMENT_ADDRESS", ""); settlement != "" {
+		makers, err := rfq.LoadMakers(getEnv("RFQ_MAKERS_PATH", "configs/makers.json"))
+		if err != nil {
+			log.Fatalf("Failed to load RFQ makers: %v", err)
+		}
+		timeout, err := time.ParseDuration(getEnv("RFQ_TIMEOUT", "300ms"))
+		if err != nil {
+			log.Fatalf("Invalid RFQ_TIMEOUT: %v", err)
+		}
+		rfqHub = rfq.NewHub(makers, rfq.NewDomain(ethClient.ChainID(), common.HexToAddress(settlement)), timeout)
+		routerService.SetRFQProvider(rfqHub)
+		spenders.Add(entities.Spender{Name: "rfq_settlement", Address: common.HexToAddress(settlement), DEXes: []entities.DEXType{entities.DEXRFQ}, Purpose: "Fills of market makers' firm quotes"})
+		log.Printf("RFQ enabled with %d registered makers", len(makers))
+	}
+
+	tokenTaxService := services.NewTokenTaxService(priceService, ethClient, ethClient)
+	screeningService := services.NewTokenScreeningService(tokenTaxService, entities.DefaultRegistry().GetAll())
+	if err := screeningService.LoadBlocklist(blocklistPath); err != nil {
+		log.Printf("Warning: Failed to load token blocklist: %v", err)
+	}
+
+	tokenRegistry := entities.DefaultRegistry()
+	tokenRegistry.SetReader(services.NewTokenMetadataService(ethClient))
+	if err := tokenRegistry.LoadFromFile(tokensPath); err != nil {
+		log.Printf("Warning: Failed to load token list: %v", err)
+	}
+	if err := tokenRegistry.LoadGroups(tokenGroupsPath); err != nil {
+		log.Printf("Warning: Failed to load token groups: %v", err)
+	}
+	// Pairs with no route are routed through their tokens' equivalents
+	routerService.SetTokenEquivalents(tokenRegistry)
+	// SIGHUP re-reads the token list; handlers share the registry
+	reloadTokens := make(chan os.Signal, 1)
+	signal.Notify(reloadTokens, syscall.SIGHUP)
+	go func() {
+		for range reloadTokens {
+			if err := tokenRegistry.Reload(); err != nil {
+				log.Printf("Warning: Failed to reload token list: %v", err)
+				continue
+			}
+			log.Printf("Reloaded token list: %d tokens", tokenRegistry.Count())
+		}
+	}()
+
+	// Tokens without a pool against a stablecoin are priced through these
+	// paths, in order
+	referencePaths, err := parseReferencePaths(getEnv("PRICE_REFERENCE_PATHS", "WETH,USDT,DAI,WBTC>WETH"), tokenRegistry)
+	if err != nil {
+		log.Fatalf("Invalid PRICE_REFERENCE_PATHS: %v", err)
+	}
+	priceService.SetReferencePaths(referencePaths)
+
+	// Verified pools are enabled by their TVL threshold; quotes may then ask
+	// to route through pools of canonical factories and listed tokens only
+	if minTVL := getEnv("VERIFIED_POOL_MIN_TVL_USD", ""); minTVL != "" {
+		usd, err := strconv.ParseUint(minTVL, 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid VERIFIED_POOL_MIN_TVL_USD: %v", err)
+		}
+		factories := []common.Address{dex.UniswapV2FactoryAddress, dex.SushiswapFactoryAddress, dex.UniswapV3FactoryAddress}
+		if value := getEnv("VERIFIED_POOL_FACTORIES", ""); value != "" {
+			factories = nil
+			for _, address := range strings.Split(value, ",") {
+				if address = strings.TrimSpace(address); !common.IsHexAddress(address) {
+					log.Fatalf("Invalid VERIFIED_POOL_FACTORIES: %q is not an address", address)
+				}
+				factories = append(factories, common.HexToAddress(address))
+			}
+		}
+		minTVLUSD := new(big.Int).Mul(new(big.Int).SetUint64(usd), big.NewInt(1e18))
+		priceService.SetPoolVerifier(services.NewPoolVerifier(factories, minTVLUSD, tokenRegistry))
+		log.Printf("Pool verification enabled: %d factories, TVL of at least $%d", len(factories), usd)
+	}
+
+	// Only mainnet has swap routing; other chains are reachable when the
+	// token on that side is a bridge asset
+	bridgeAdapters := []bridge.Adapter{
+		bridge.NewAcrossAdapter(getEnv("ACROSS_API_URL", bridge.AcrossAPIURL)),
+		bridge.NewStargateAdapter(getEnv("STARGATE_API_URL", bridge.StargateAPIURL)),
+	}
+	crossChainService := services.NewCrossChainService(bridgeAdapters, map[uint64]*services.RouterService{
+		entities.ChainEthereum: routerService,
+	}, priceService, feeService)
+
+	// Intents are enabled by configuring the settlement contract users sign for
+	var intentHandler *handlers.IntentHandler
+	if settlement := getEnv("INTENT_SETTLEMENT_ADDRESS", ""); settlement != "" {
+		intentDomain := services.NewIntentDomain(ethClient.ChainID(), common.HexToAddress(settlement))
+		spenders.Add(entities.Spender{Name: "intent_settlement", Address: common.HexToAddress(settlement), Purpose: "Settlement of signed intents"})
+		intentHandler = handlers.NewIntentHandler(services.NewIntentService(routerService, intentDomain), tokenRegistry, ensResolver)
+	}
+
+	// Dutch orders are enabled by configuring their settlement contract
+	var orderHandler *handlers.OrderHandler
+	if settlement := getEnv("ORDER_SETTLEMENT_ADDRESS", ""); settlement != "" {
+		orderDomain := services.NewOrderDomain(ethClient.ChainID(), common.HexToAddress(settlement))
+		spenders.Add(entities.Spender{Name: "order_settlement", Address: common.HexToAddress(settlement), Purpose: "Fills of signed Dutch orders"})
+		orderService := services.NewOrderService(routerService, swapBuilder, orderDomain)
+		if embedded != nil {
+			orderService.SetStore(embedded)
+		}
+		go orderService.Run(workerCtx, 12*time.Second)
+		orderHandler = handlers.NewOrderHandler(orderService, tokenRegistry, ensResolver)
+	}
+
+	// Integrator fees are enabled by configuring the fee collector contract
+	var feeHandler *handlers.FeeHandler
+	if collector := getEnv("FEE_COLLECTOR_ADDRESS", ""); collector != "" {
+		if !common.IsHexAddress(collector) {
+			log.Fatalf("Invalid FEE_COLLECTOR_ADDRESS: %s", collector)
+		}
+		startBlock, err := strconv.ParseUint(getEnv("FEE_COLLECTOR_START_BLOCK", "0"), 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid FEE_COLLECTOR_START_BLOCK: %v", err)
+		}
+		swapService.SetFeeCollector(common.HexToAddress(collector))
+		spenders.Add(entities.Spender{Name: "fee_collector", Address: common.HexToAddress(collector), Purpose: "Swaps that charge an integrator fee"})
+		feeLedger := services.NewFeeLedger(ethClient, common.HexToAddress(collector), startBlock, 3)
+		feeLedger.SetStateReader(ethClient)
+		if embedded != nil {
+			feeLedger.SetStore(embedded)
+		}
+		go feeLedger.Run(workerCtx, time.Minute)
+		feeHandler = handlers.NewFeeHandler(feeLedger, ensResolver)
+		log.Printf("Integrator fees enabled via collector %s", collector)
+	}
+
+	// Pool stats are enabled by configuring at least one venue's subgraph
+	var poolHandler *handlers.PoolHandler
+	if endpoints, err := parseSubgraphURLs(getEnv("SUBGRAPH_URLS", "")); err != nil {
+		log.Fatalf("Invalid SUBGRAPH_URLS: %v", err)
+	} else if len(endpoints) > 0 {
+		poolService := services.NewPoolService(subgraph.NewClient(endpoints), 500)
+		priceService.SetPoolStats(poolService)
+		go poolService.Run(workerCtx, 10*time.Minute)
+		poolHandler = handlers.NewPoolHandler(poolService, ensResolver)
+		log.Printf("Pool stats enabled from %d subgraphs", len(endpoints))
+	}
+
+	// Dust pools are left out of routing once a liquidity floor is set
+	if floor, err := parseLiquidityFloor(getEnv("MIN_POOL_LIQUIDITY_USD", ""), getEnv("MIN_POOL_LIQUIDITY_USD_BY_DEX", "")); err != nil {
+		log.Fatalf("Invalid pool liquidity floor: %v", err)
+	} else if floor.Default != nil || len(floor.ByDEX) > 0 {
+		priceService.SetLiquidityFloor(floor)
+		log.Printf("Pools below their liquidity floor are left out of routing")
+	}
 
 	healthHandler := handlers.NewHealthHandler(version)
-	quoteHandler := handlers.NewQuoteHandler(routerService)
-	priceHandler := handlers.NewPriceHandler(priceService)
+	// /ready reports 503 until the hot pairs have quoted once
+	warmupPairs, err := parseWarmupPairs(getEnv("WARMUP_PAIRS", "WETH/USDC,WETH/USDT,WETH/DAI,WBTC/WETH"), tokenRegistry)
+	if err != nil {
+		log.Fatalf("Invalid WARMUP_PAIRS: %v", err)
+	}
+	warmupTimeout, err := time.ParseDuration(getEnv("WARMUP_TIMEOUT", "2m"))
+	if err != nil {
+		log.Fatalf("Invalid WARMUP_TIMEOUT: %v", err)
+	}
+	warmup := services.NewWarmup(routerService, warmupPairs)
+	healthHandler.SetWarmup(warmup)
+	quoteHandler := handlers.NewQuoteHandler(routerService, screeningService, swapService, feeService, tokenRegistry, ensResolver)
+	quoteHandler.SetPriceService(priceService)
+	quoteHandler.SetQuoteBook(services.NewQuoteBook(routerService))
+	quoteHandler.SetChainHead(headWatcher)
+	if slippageBlocks, err := strconv.Atoi(getEnv("SLIPPAGE_AUTO_BLOCKS", strconv.Itoa(services.DefaultSlippageAutoBlocks))); err != nil {
+		log.Fatalf("Invalid SLIPPAGE_AUTO_BLOCKS: %v", err)
+	} else if slippageBlocks > 0 {
+		fill, err := strconv.ParseFloat(getEnv("SLIPPAGE_AUTO_FILL", strconv.FormatFloat(services.DefaultSlippageAutoFill, 'f', -1, 64)), 64)
+		if err != nil || fill <= 0 || fill > 1 {
+			log.Fatalf("Invalid SLIPPAGE_AUTO_FILL: %q", getEnv("SLIPPAGE_AUTO_FILL", ""))
+		}
+		quoteHandler.SetSlippageTuner(services.NewSlippageTuner(ethClient, slippageBlocks, fill))
+	}
+	var references []reference.Quoter
+	if key := getEnv("ZEROX_API_KEY", ""); key != "" {
+		references = append(references, reference.NewZeroExQuoter(getEnv("ZEROX_API_URL", reference.ZeroExAPIURL), key))
+	}
+	if key := getEnv("ONEINCH_API_KEY", ""); key != "" {
+		references = append(references, reference.NewOneInchQuoter(getEnv("ONEINCH_API_URL", reference.OneInchAPIURL), key))
+	}
+	if len(references) > 0 {
+		quoteHandler.SetCompareService(services.NewCompareService(routerService, references, ethClient.ChainID().Uint64()))
+	}
+
+	// Quote analytics are recorded whenever a store is configured, unless
+	// QUOTE_ANALYTICS opts out
+	var recorderDone chan struct{}
+	if endpoint := getEnv("ANALYTICS_CLICKHOUSE_URL", ""); endpoint != "" && getEnv("QUOTE_ANALYTICS", "true") != "false" {
+		retentionDays, err := strconv.Atoi(getEnv("ANALYTICS_RETENTION_DAYS", "90"))
+		if err != nil {
+			log.Fatalf("Invalid ANALYTICS_RETENTION_DAYS: %v", err)
+		}
+		sink, err := analytics.NewClickHouseSink(endpoint, getEnv("ANALYTICS_TABLE", "quotes"), retentionDays)
+		if err != nil {
+			log.Fatalf("Invalid quote analytics config: %v", err)
+		}
+		migrateCtx, cancel := context.WithTimeout(workerCtx, 30*time.Second)
+		err = sink.Migrate(migrateCtx)
+		cancel()
+		if err != nil {
+			log.Printf("Warning: Quote analytics disabled: %v", err)
+		} else {
+			recorder := services.NewQuoteRecorder(sink, 10000, 1000)
+			recorderDone = make(chan struct{})
+			go func() {
+				recorder.Run(workerCtx, 5*time.Second)
+				close(recorderDone)
+			}()
+			quoteHandler.SetQuoteRecorder(recorder)
+			expvar.Publish("quote_records_dropped", expvar.Func(func() any { return recorder.Dropped() }))
+			expvar.Publish("quote_records_failed", expvar.Func(func() any { return recorder.Failed() }))
+			log.Printf("Quote analytics enabled with %d-day retention", retentionDays)
+		}
+	}
+
+	// Stage timings of every quote, to find the slowest stage across traffic
+	latency := services.NewLatencyHistograms()
+	quoteHandler.SetLatencyHistograms(latency)
+	expvar.Publish("quote_stage_latency", expvar.Func(func() any { return latency.Snapshot() }))
+
+	// Shadow routing compares other strategies against a sample of served quotes
+	if strategies := getEnv("SHADOW_STRATEGIES", ""); strategies != "" {
+		names := strings.Split(strategies, ",")
+		for i, name := range names {
+			names[i] = strings.TrimSpace(name)
+			if !slices.Contains(routerService.Strategies(), names[i]) {
+				log.Fatalf("Invalid SHADOW_STRATEGIES (available: %s): %q", strings.Join(routerService.Strategies(), ", "), names[i])
+			}
+		}
+		sampleRate, err := strconv.ParseFloat(getEnv("SHADOW_SAMPLE_RATE", "0.01"), 64)
+		if err != nil || sampleRate <= 0 || sampleRate > 1 {
+			log.Fatalf("Invalid SHADOW_SAMPLE_RATE: %q", getEnv("SHADOW_SAMPLE_RATE", ""))
+		}
+		shadow := services.NewShadowEvaluator(routerService, names, sampleRate, 1000)
+		go shadow.Run(workerCtx, 4)
+		quoteHandler.SetShadowEvaluator(shadow)
+		expvar.Publish("shadow_routing", expvar.Func(func() any { return shadow.Stats() }))
+		expvar.Publish("shadow_routing_dropped", expvar.Func(func() any { return shadow.Dropped() }))
+		log.Printf("Shadowing %.2f%% of quotes with %s", sampleRate*100, strings.Join(names, ", "))
+	}
+
+	// A sample of quote requests is kept with the response sent, for
+	// incident reviews through the admin API
+	var auditLogHandler *handlers.AuditLogHandler
+	var auditLogDone chan struct{}
+	if path := getEnv("AUDIT_LOG_PATH", ""); path != "" {
+		sampleRate, err := strconv.ParseFloat(getEnv("AUDIT_LOG_SAMPLE_RATE", "0.01"), 64)
+		if err != nil || sampleRate <= 0 || sampleRate > 1 {
+			log.Fatalf("Invalid AUDIT_LOG_SAMPLE_RATE: %q", getEnv("AUDIT_LOG_SAMPLE_RATE", ""))
+		}
+		store, err := auditlog.NewFileStore(path)
+		if err != nil {
+			log.Fatalf("Invalid AUDIT_LOG_PATH: %v", err)
+		}
+		auditLog := services.NewAuditLog(store, sampleRate, 10000, 100)
+		auditLogDone = make(chan struct{})
+		go func() {
+			auditLog.Run(workerCtx, time.Second)
+			store.Close()
+			close(auditLogDone)
+		}()
+		quoteHandler.SetAuditLog(auditLog)
+		auditLogHandler = handlers.NewAuditLogHandler(auditLog)
+		expvar.Publish("audit_log_dropped", expvar.Func(func() any { return auditLog.Dropped() }))
+		expvar.Publish("audit_log_failed", expvar.Func(func() any { return auditLog.Failed() }))
+		log.Printf("Audit logging %.2f%% of quote requests to %s", sampleRate*100, path)
+	}
+
+	// The pools of the snapshot pairs are recorded at an interval, for
+	// backtesting against past liquidity through /export/liquidity
+	var liquidityHandler *handlers.LiquidityHandler
+	var snapshotsDone chan struct{}
+	if path := getEnv("LIQUIDITY_SNAPSHOT_PATH", ""); path != "" {
+		interval, err := time.ParseDuration(getEnv("LIQUIDITY_SNAPSHOT_INTERVAL", "5m"))
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid LIQUIDITY_SNAPSHOT_INTERVAL: %q", getEnv("LIQUIDITY_SNAPSHOT_INTERVAL", ""))
+		}
+		pairs, err := parseSnapshotPairs(getEnv("LIQUIDITY_SNAPSHOT_PAIRS", getEnv("WARMUP_PAIRS", "WETH/USDC,WETH/USDT,WETH/DAI,WBTC/WETH")), tokenRegistry)
+		if err != nil {
+			log.Fatalf("Invalid LIQUIDITY_SNAPSHOT_PAIRS: %v", err)
+		}
+		store, err := liquidity.NewFileStore(path)
+		if err != nil {
+			log.Fatalf("Invalid LIQUIDITY_SNAPSHOT_PATH: %v", err)
+		}
+		snapshotter := services.NewLiquiditySnapshotter(priceService, store, pairs)
+		snapshotsDone = make(chan struct{})
+		go func() {
+			runSingleton("liquidity_snapshots", func(ctx context.Context) {
+				snapshotter.Run(ctx, interval)
+			}, nil)
+			store.Close()
+			close(snapshotsDone)
+		}()
+		liquidityHandler = handlers.NewLiquidityHandler(snapshotter, tokenRegistry)
+		expvar.Publish("liquidity_snapshots_failed", expvar.Func(func() any { return snapshotter.Failed() }))
+		log.Printf("Snapshotting liquidity of %d pairs every %s to %s", len(pairs), interval, path)
+	}
+
+	// Integrator API keys are managed through the admin API, which is only
+	// mounted when it has a token
+	var apiKeyHandler *handlers.APIKeyHandler
+	adminToken := getEnv("ADMIN_API_TOKEN", "")
+	apiKeysRequired := getEnv("API_KEYS_REQUIRED", "false") == "true"
+	if adminToken != "" {
+		// Route bookmarks are kept with the keys they belong to
+		var store interface {
+			services.APIKeyStore
+			services.RouteBookmarkStore
+		}
+		if redisCache != nil {
+			store = keystore.NewRedisStore(redisCache.Client())
+		} else if embedded != nil {
+			store = embedded
+		} else {
+			log.Println("Warning: API keys are stored in memory and will be lost on restart")
+			store = keystore.NewMemoryStore()
+		}
+		apiKeyService := services.NewAPIKeyService(store, priceService)
+		apiKeyHandler = handlers.NewAPIKeyHandler(apiKeyService)
+		quoteHandler.SetAPIKeyService(apiKeyService)
+		quoteHandler.SetBookmarks(services.NewRouteBookmarkService(store))
+		log.Printf("API keys enabled (required: %t)", apiKeysRequired)
+	} else if apiKeysRequired {
+		log.Fatal("ADMIN_API_TOKEN is required when API keys are required")
+	}
+
+	// Requests over ADMISSION_CAPACITY queue, keyed traffic ahead of anonymous
+	var admission *services.AdmissionController
+	if capacity := getEnv("ADMISSION_CAPACITY", "0"); capacity != "0" {
+		admission, err = newAdmissionController(capacity)
+		if err != nil {
+			log.Fatalf("Invalid admission settings: %v", err)
+		}
+		expvar.Publish("admission", expvar.Func(func() any { return admission.Stats() }))
+		log.Printf("Admission control enabled for %s concurrent requests", capacity)
+	}
+	apiMiddleware := func(r chi.Router) {
+		if apiKeyHandler != nil {
+			r.Use(apiKeyHandler.Middleware(apiKeysRequired))
+		}
+		if admission != nil {
+			r.Use(handlers.AdmissionMiddleware(admission))
+		}
+	}
+
+	priceHandler := handlers.NewPriceHandler(priceService, tokenRegistry, ensResolver)
+	priceHandler.SetFXService(services.NewFXService(ethClient, services.MainnetFXFeeds))
+	priceHandler.SetChainHead(headWatcher)
+	spenderHandler := handlers.NewSpenderHandler(spenders)
+	venueStatsHandler := handlers.NewVenueStatsHandler(venueStats, priceService.Venues())
+	spreadHandler := handlers.NewSpreadHandler(services.NewSpreadService(priceService, ethClient), tokenRegistry, ensResolver)
+	tokenHandler := handlers.NewTokenHandler(tokenRegistry, tokenTaxService, ensResolver)
+	crossChainHandler := handlers.NewCrossChainHandler(crossChainService, tokenRegistry, ensResolver)
+	flashSwapHandler := handlers.NewFlashSwapHandler(swapService)
+	graphQLHandler := handlers.NewGraphQLHandler(quoteHandler, priceHandler, poolHandler)
+
+	var executionHandler *handlers.ExecutionHandler
+	executionToken := getEnv("EXECUTION_API_TOKEN", "")
+	if getEnv("EXECUTION_ENABLED", "false") == "true" {
+		txSigner, err := newSigner()
+		if err != nil {
+			log.Fatalf("Failed to configure execution signer: %v", err)
+		}
+		if executionToken == "" {
+			log.Fatal("EXECUTION_API_TOKEN is required when execution is enabled")
+		}
+		txManager := txmanager.New(ethClient, txSigner, ethClient.ChainID(), txmanager.DefaultConfig())
+		go txManager.Run(workerCtx)
+		executionService := services.NewExecutionService(routerService, swapService, feeService, txManager)
+		executionHandler = handlers.NewExecutionHandler(executionService, tokenRegistry, ensResolver)
+		log.Printf("Swap execution enabled for hot wallet %s", txSigner.Address().Hex())
+	}
+
+	corsConfig, err := newCORSConfig()
+	if err != nil {
+		log.Fatalf("Invalid CORS settings: %v", err)
+	}
+	hstsMaxAge, err := strconv.Atoi(getEnv("HSTS_MAX_AGE", "0"))
+	if err != nil || hstsMaxAge < 0 {
+		log.Fatalf("Invalid HSTS_MAX_AGE: %q", getEnv("HSTS_MAX_AGE", ""))
+	}
 
 	r := chi.NewRouter()
 
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(30 * time.Second))
-	r.Use(corsMiddleware)
+	r.Use(handlers.SecurityHeadersMiddleware(hstsMaxAge))
+	r.Use(handlers.CORSMiddleware(corsConfig))
 
 	r.Get("/health", healthHandler.Health)
+	r.Get("/ready", healthHandler.Ready)
+	r.Handle("/debug/vars", expvar.Handler())
+
+	if apiKeyHandler != nil {
+		r.Route("/api/v1/admin", func(r chi.Router) {
+			r.Use(bearerTokenMiddleware(adminToken))
+			r.Post("/keys", apiKeyHandler.Create)
+			r.Get("/keys", apiKeyHandler.List)
+			r.Get("/keys/{id}", apiKeyHandler.Get)
+			r.Patch("/keys/{id}", apiKeyHandler.Update)
+			r.Delete("/keys/{id}", apiKeyHandler.Delete)
+			r.Post("/tokens/reload", tokenHandler.ReloadTokens)
+			if gasHandler != nil {
+				r.Get("/gas", gasHandler.GetStats)
+			}
+			if auditLogHandler != nil {
+				r.Get("/audit", auditLogHandler.Search)
+			}
+		})
+	}
 
 	r.Route("/api/v1", func(r chi.Router) {
+		apiMiddleware(r)
 		r.Get("/quote", quoteHandler.GetQuote)
+		r.Get("/quote/plan", quoteHandler.GetPlan)
+		r.Get("/quote/{id}/validate", quoteHandler.ValidateQuote)
+		if apiKeyHandler != nil {
+			r.Get("/quote/by-name/{name}", quoteHandler.GetQuoteByName)
+			r.Get("/bookmarks", quoteHandler.ListBookmarks)
+			r.Get("/bookmarks/{name}", quoteHandler.GetBookmark)
+			r.Put("/bookmarks/{name}", quoteHandler.PutBookmark)
+			r.Delete("/bookmarks/{name}", quoteHandler.DeleteBookmark)
+		}
+		r.Post("/route/evaluate", quoteHandler.EvaluateRoute)
+		if len(references) > 0 {
+			r.Get("/quote/compare", quoteHandler.CompareQuote)
+		}
 		r.Get("/price/{tokenAddress}", priceHandler.GetPrice)
+		r.Get("/export/prices", priceHandler.ExportPrices)
+		if liquidityHandler != nil {
+			r.Get("/export/liquidity", liquidityHandler.ExportSnapshots)
+		}
+		r.Get("/spread", spreadHandler.GetSpread)
+		r.Get("/spenders", spenderHandler.ListSpenders)
+		r.Get("/stats/venues", venueStatsHandler.GetStats)
+		r.Get("/tokens", tokenHandler.ListTokens)
+		r.Get("/tokens/{address}", tokenHandler.GetToken)
+		r.Get("/crosschain/quote", crossChainHandler.GetQuote)
+		r.Post("/flashswap", flashSwapHandler.BuildFlashSwap)
+
+		if feeHandler != nil {
+			r.Get("/fees/{recipient}", feeHandler.GetAccrued)
+			r.Get("/fees/{recipient}/claim", feeHandler.PrepareClaim)
+			r.Get("/fees/{recipient}/reconcile", feeHandler.Reconcile)
+		}
+		if poolHandler != nil {
+			r.Get("/pools", poolHandler.ListPools)
+		}
+
+		if rfqHub != nil {
+			r.Get("/rfq/ws", rfqHub.ServeHTTP)
+		}
+
+		if orderHandler != nil {
+			r.Get("/orders", orderHandler.ListOrders)
+			r.Post("/orders/dutch", orderHandler.CreateDutch)
+			r.Get("/orders/{id}", orderHandler.GetOrder)
+		}
+
+		if intentHandler != nil {
+			r.Post("/intents", intentHandler.Submit)
+			r.Post("/intents/typed-data", intentHandler.TypedData)
+			r.Post("/intents/batch", intentHandler.ProposeSettlements)
+			r.Get("/intents/{id}", intentHandler.GetIntent)
+		}
+
+		if executionHandler != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(bearerTokenMiddleware(executionToken))
+				r.Post("/swap/execute", executionHandler.Execute)
+				r.Get("/tx/{hash}", executionHandler.GetTransaction)
+			})
+		}
+	})
+
+	r.Route("/api/v2", func(r chi.Router) {
+		apiMiddleware(r)
+		r.Get("/quote", quoteHandler.GetQuoteV2)
+		r.Get("/price/{tokenAddress}", priceHandler.GetPriceV2)
+	})
+
+	r.Group(func(r chi.Router) {
+		apiMiddleware(r)
+		r.Post("/graphql", graphQLHandler.ServeHTTP)
 	})
 
 	server := &http.Server{
@@ -86,6 +756,10 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Started once every service is configured, so warm quotes take the
+	// same path as served ones
+	go warmup.Run(workerCtx, 5*time.Second, warmupTimeout)
+
 	go func() {
 		log.Printf("Starting DEX Aggregator API v%s on port %s", version, port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -104,9 +778,183 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server shutdown error: %v", err)
 	}
+	// Write out quotes served before shutdown
+	stopWorkers()
+	if recorderDone != nil {
+		<-recorderDone
+	}
+	if auditLogDone != nil {
+		<-auditLogDone
+	}
+	if snapshotsDone != nil {
+		<-snapshotsDone
+	}
 	log.Println("Server stopped")
 }
 
+// parseDEXList splits a comma-separated DEXES value; empty enables all
+func parseDEXList(value string) []entities.DEXType {
+	var names []entities.DEXType
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, entities.DEXType(name))
+		}
+	}
+	return names
+}
+
+// parseWarmupPairs reads "IN/OUT" symbol pairs, each warmed with one whole
+// IN token
+func parseWarmupPairs(value string, registry *entities.TokenRegistry) ([]services.WarmupPair, error) {
+	var pairs []services.WarmupPair
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		in, out, ok := strings.Cut(entry, "/")
+		if !ok || in == "" || out == "" {
+			return nil, fmt.Errorf("expected IN/OUT, got %q", entry)
+		}
+		tokenIn, err := registry.LookupSymbol(strings.TrimSpace(in))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry, err)
+		}
+		tokenOut, err := registry.LookupSymbol(strings.TrimSpace(out))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry, err)
+		}
+		pairs = append(pairs, services.WarmupPair{
+			TokenIn:  tokenIn,
+			TokenOut: tokenOut,
+			AmountIn: new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tokenIn.Decimals)), nil),
+		})
+	}
+	return pairs, nil
+}
+
+// parseSnapshotPairs reads "A/B" symbol pairs whose pools are snapshotted
+func parseSnapshotPairs(value string, registry *entities.TokenRegistry) ([]services.SnapshotPair, error) {
+	warmup, err := parseWarmupPairs(value, registry)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]services.SnapshotPair, len(warmup))
+	for i, pair := range warmup {
+		pairs[i] = services.SnapshotPair{TokenA: pair.TokenIn, TokenB: pair.TokenOut}
+	}
+	return pairs, nil
+}
+
+// parseReferencePaths reads comma-separated paths of ">"-separated token
+// symbols, such as "WETH,WBTC>WETH"
+func parseReferencePaths(value string, registry *entities.TokenRegistry) ([][]entities.Token, error) {
+	var paths [][]entities.Token
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		var path []entities.Token
+		for _, symbol := range strings.Split(entry, ">") {
+			token, err := registry.LookupSymbol(strings.TrimSpace(symbol))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", entry, err)
+			}
+			path = append(path, token)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// parseSubgraphURLs reads "dex=url,dex=url" pairs
+func parseSubgraphURLs(value string) (map[entities.DEXType]string, error) {
+	endpoints := make(map[entities.DEXType]string)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, url, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || url == "" {
+			return nil, fmt.Errorf("expected dex=url, got %q", entry)
+		}
+		endpoints[entities.DEXType(strings.TrimSpace(name))] = strings.TrimSpace(url)
+	}
+	return endpoints, nil
+}
+
+// parseLiquidityFloor reads a floor in whole US dollars and "dex=usd,dex=usd"
+// overrides per venue
+func parseLiquidityFloor(value, byDEX string) (services.LiquidityFloor, error) {
+	usd := func(s string) (*big.Int, error) {
+		n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected whole US dollars, got %q", s)
+		}
+		return new(big.Int).Mul(new(big.Int).SetUint64(n), big.NewInt(1e18)), nil
+	}
+
+	floor := services.LiquidityFloor{ByDEX: make(map[entities.DEXType]*big.Int)}
+	if value != "" {
+		min, err := usd(value)
+		if err != nil {
+			return floor, fmt.Errorf("MIN_POOL_LIQUIDITY_USD: %w", err)
+		}
+		floor.Default = min
+	}
+	for _, entry := range strings.Split(byDEX, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, amount, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return floor, fmt.Errorf("MIN_POOL_LIQUIDITY_USD_BY_DEX: expected dex=usd, got %q", entry)
+		}
+		min, err := usd(amount)
+		if err != nil {
+			return floor, fmt.Errorf("MIN_POOL_LIQUIDITY_USD_BY_DEX: %w", err)
+		}
+		floor.ByDEX[entities.DEXType(strings.TrimSpace(name))] = min
+	}
+	return floor, nil
+}
+
+// newAdmissionController reads the admission limits; tier limits of 0 leave
+// a tier the whole capacity
+func newAdmissionController(capacity string) (*services.AdmissionController, error) {
+	total, err := strconv.Atoi(capacity)
+	if err != nil || total <= 0 {
+		return nil, fmt.Errorf("ADMISSION_CAPACITY must be a positive integer")
+	}
+	limits := make(map[services.Tier]int)
+	for tier, env := range map[services.Tier]string{
+		services.TierAnonymous: "ADMISSION_ANONYMOUS_LIMIT",
+		services.TierKey:       "ADMISSION_KEY_LIMIT",
+	} {
+		if limits[tier], err = strconv.Atoi(getEnv(env, "0")); err != nil {
+			return nil, fmt.Errorf("%s: %w", env, err)
+		}
+	}
+	maxQueue, err := strconv.Atoi(getEnv("ADMISSION_QUEUE", "100"))
+	if err != nil {
+		return nil, fmt.Errorf("ADMISSION_QUEUE: %w", err)
+	}
+	maxWait, err := time.ParseDuration(getEnv("ADMISSION_MAX_WAIT", "2s"))
+	if err != nil {
+		return nil, fmt.Errorf("ADMISSION_MAX_WAIT: %w", err)
+	}
+	return services.NewAdmissionController(total, limits, maxQueue, maxWait), nil
+}
+
+// defaultInstanceID names the instance in leader elections by its host
+// and process
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -114,17 +962,71 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// newSigner selects the hot wallet signer from the environment: an external
+// signer URL, an encrypted keystore, or a raw private key, in that order
+func newSigner() (signer.Signer, error) {
+	if url := getEnv("EXECUTION_SIGNER_URL", ""); url != "" {
+		address := getEnv("EXECUTION_SIGNER_ADDRESS", "")
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("EXECUTION_SIGNER_ADDRESS must be set for an external signer")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return signer.NewExternalSigner(ctx, url, common.HexToAddress(address))
+	}
+	if path := getEnv("EXECUTION_KEYSTORE_PATH", ""); path != "" {
+		return signer.NewKeystoreSigner(path, getEnv("EXECUTION_KEYSTORE_PASSPHRASE", ""))
+	}
+	if key := getEnv("EXECUTION_PRIVATE_KEY", ""); key != "" {
+		return signer.NewPrivateKeySigner(key)
+	}
+	return nil, fmt.Errorf("no signer configured (EXECUTION_SIGNER_URL, EXECUTION_KEYSTORE_PATH or EXECUTION_PRIVATE_KEY)")
+}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+func bearerTokenMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				handlers.WriteError(w, r, apperror.New(apperror.Unauthorized, "missing or invalid bearer token"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// newCORSConfig reads the deployment's CORS policy, each list
+// comma-separated; unset settings keep the defaults
+func newCORSConfig() (handlers.CORSConfig, error) {
+	config := handlers.DefaultCORSConfig()
+	for env, list := range map[string]*[]string{
+		"CORS_ALLOWED_ORIGINS": &config.AllowedOrigins,
+		"CORS_ALLOWED_METHODS": &config.AllowedMethods,
+		"CORS_ALLOWED_HEADERS": &config.AllowedHeaders,
+		"CORS_EXPOSED_HEADERS": &config.ExposedHeaders,
+	} {
+		if value := getEnv(env, ""); value != "" {
+			*list = splitList(value)
 		}
+	}
+	var err error
+	if config.AllowCredentials, err = strconv.ParseBool(getEnv("CORS_ALLOW_CREDENTIALS", "false")); err != nil {
+		return config, fmt.Errorf("CORS_ALLOW_CREDENTIALS: %w", err)
+	}
+	if config.MaxAge, err = strconv.Atoi(getEnv("CORS_MAX_AGE", "600")); err != nil {
+		return config, fmt.Errorf("CORS_MAX_AGE: %w", err)
+	}
+	return config, config.Validate()
+}
 
-		next.ServeHTTP(w, r)
-	})
+// splitList splits a comma-separated setting, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxLineSize bounds one corpus line; audit log entries of ladder quotes
+// run to a few hundred KB
+const maxLineSize = 16 << 20
+
+// request is one corpus entry. Audit log entries decode into it as they
+// are, ignoring what was served.
+type request struct {
+	Path   string            `json:"path"`
+	Params map[string]string `json:"params"`
+}
+
+// URL returns the request against base
+func (r request) URL(base string) string {
+	query := url.Values{}
+	for name, value := range r.Params {
+		query.Set(name, value)
+	}
+	return strings.TrimSuffix(base, "/") + r.Path + "?" + query.Encode()
+}
+
+// loadCorpus reads requests from a JSON lines file, skipping blank lines
+func loadCorpus(path string) ([]request, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var requests []request
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var req request
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !strings.HasPrefix(req.Path, "/") {
+			return nil, fmt.Errorf("line %d: path %q must start with /", line, req.Path)
+		}
+		requests = append(requests, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCorpus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus.jsonl")
+	corpus := `{"time":"2026-01-02T03:04:05Z","path":"/api/v1/quote","params":{"tokenIn":"WETH","tokenOut":"USDC","amountIn":"1"},"quotes":[],"responseHash":"ab"}
+
+{"path":"/api/v2/quote","params":{"tokenIn":"USDC","tokenOut":"DAI","amountIn":"100"}}
+`
+	if err := os.WriteFile(path, []byte(corpus), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := loadCorpus(path)
+	if err != nil {
+		t.Fatalf("loadCorpus: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	want := "http://localhost:8080/api/v1/quote?amountIn=1&tokenIn=WETH&tokenOut=USDC"
+	if got := requests[0].URL("http://localhost:8080/"); got != want {
+		t.Errorf("URL = %s, want %s", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"path":"api/v1/quote"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCorpus(path); err == nil {
+		t.Error("relative path was accepted")
+	}
+}
@@ -0,0 +1,201 @@
+// Command bench replays a corpus of quote requests against a running
+// service and reports latency percentiles and node calls per request.
+// Given a candidate build as well, it sends each request to both, compares
+// their quotes and exits 1 when the candidate is slower or quotes worse
+// beyond the tolerances. The corpus is JSON lines: the audit log
+// (AUDIT_LOG_PATH) as it is, or fixture lines with a path and params.
+//
+//	go run ./cmd/bench -corpus audit.jsonl -base http://release:8080 -candidate http://next:8080
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// target is a running build
+type target struct {
+	name string
+	url  string
+}
+
+func main() {
+	corpusPath := flag.String("corpus", "", `JSON lines of quote requests: the audit log, or {"path", "params"} fixtures`)
+	baseURL := flag.String("base", "http://localhost:8080", "build to measure")
+	candidateURL := flag.String("candidate", "", "build to compare with base, sent every request as well")
+	concurrency := flag.Int("concurrency", 4, "requests in flight per build")
+	repeat := flag.Int("repeat", 1, "times to replay the corpus")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of each request")
+	apiKey := flag.String("api-key", "", "API key to send as X-API-Key")
+	latencyTolerance := flag.Float64("latency-tolerance", 0.10, "p90 slowdown of the candidate allowed, as a fraction of base's")
+	qualityTolerance := flag.Float64("quality-tolerance-bps", 1, "quote differences within this many basis points count as equal")
+	flag.Parse()
+
+	if *corpusPath == "" || *concurrency < 1 || *repeat < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	requests, err := loadCorpus(*corpusPath)
+	if err != nil {
+		log.Fatalf("Failed to load corpus: %v", err)
+	}
+	if len(requests) == 0 {
+		log.Fatalf("Corpus %s has no requests", *corpusPath)
+	}
+
+	targets := []target{{name: "base", url: *baseURL}}
+	if *candidateURL != "" {
+		targets = append(targets, target{name: "candidate", url: *candidateURL})
+	}
+	client := &http.Client{Timeout: *timeout}
+
+	callsBefore := make([]uint64, len(targets))
+	callsKnown := make([]bool, len(targets))
+	for i, t := range targets {
+		callsBefore[i], callsKnown[i] = fetchCalls(client, t.url)
+	}
+
+	total := len(requests) * *repeat
+	results := make([][]result, len(targets))
+	for i := range results {
+		results[i] = make([]result, total)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range *concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				req := requests[job%len(requests)]
+				// Alternate which build goes first, so neither always
+				// meets the warmer node cache
+				for n := range targets {
+					i := n
+					if job%2 == 1 {
+						i = len(targets) - 1 - n
+					}
+					results[i][job] = send(client, req.URL(targets[i].url), *apiKey)
+				}
+			}
+		}()
+	}
+	for job := range total {
+		jobs <- job
+	}
+	close(jobs)
+	wg.Wait()
+
+	summaries := make([]summary, len(targets))
+	for i, t := range targets {
+		callsAfter, known := fetchCalls(client, t.url)
+		// A restart mid-run resets the counters
+		known = known && callsKnown[i] && callsAfter >= callsBefore[i]
+		summaries[i] = summarize(results[i], callsAfter-callsBefore[i], known)
+	}
+	printSummaries(targets, summaries)
+	if len(targets) == 1 {
+		return
+	}
+
+	c := compare(results[0], results[1], *qualityTolerance, 5)
+	fmt.Printf("\nquotes compared: %d, candidate better: %d, worse: %d, median delta: %+.2f bps\n",
+		c.compared, c.better, c.worse, c.median)
+	if c.lost > 0 {
+		fmt.Printf("requests base quoted that the candidate failed: %d\n", c.lost)
+	}
+	for _, d := range c.worst {
+		fmt.Printf("  %+.2f bps  %s\n", d.bps, requests[d.request%len(requests)].URL(""))
+	}
+
+	var regressions []string
+	if limit := float64(summaries[0].p90) * (1 + *latencyTolerance); float64(summaries[1].p90) > limit {
+		regressions = append(regressions, fmt.Sprintf("p90 latency %s over base's %s", ms(summaries[1].p90), ms(summaries[0].p90)))
+	}
+	if c.worse > 0 {
+		regressions = append(regressions, fmt.Sprintf("%d quote(s) worse than base", c.worse))
+	}
+	if c.lost > 0 {
+		regressions = append(regressions, fmt.Sprintf("%d request(s) failed that base quoted", c.lost))
+	}
+	if len(regressions) > 0 {
+		fmt.Println()
+		for _, regression := range regressions {
+			fmt.Println("regression:", regression)
+		}
+		os.Exit(1)
+	}
+}
+
+// send replays one request, timing it until the whole body is read
+func send(client *http.Client, url, apiKey string) result {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return result{}
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return result{latency: time.Since(start)}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	r := result{latency: time.Since(start), status: resp.StatusCode}
+	if err == nil && r.ok() {
+		r.amounts = parseAmounts(body)
+	}
+	return r
+}
+
+// fetchCalls sums the node calls a build has made, from the rpc_calls it
+// publishes on /debug/vars. Background jobs call the node too, so idle
+// builds give the cleanest numbers.
+func fetchCalls(client *http.Client, baseURL string) (uint64, bool) {
+	resp, err := client.Get(baseURL + "/debug/vars")
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+	var vars struct {
+		RPCCalls map[string]uint64 `json:"rpc_calls"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&vars) != nil || vars.RPCCalls == nil {
+		return 0, false
+	}
+	var total uint64
+	for _, n := range vars.RPCCalls {
+		total += n
+	}
+	return total, true
+}
+
+func printSummaries(targets []target, summaries []summary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "build\trequests\terrors\tp50\tp90\tp99\tmax\tnode calls/request")
+	for i, s := range summaries {
+		calls := "n/a"
+		if !math.IsNaN(s.callsPerReq) {
+			calls = fmt.Sprintf("%.1f", s.callsPerReq)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			targets[i].name, s.requests, s.errors, ms(s.p50), ms(s.p90), ms(s.p99), ms(s.max), calls)
+	}
+	w.Flush()
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
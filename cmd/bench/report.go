@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// result is one request's answer from one build
+type result struct {
+	latency time.Duration
+	status  int // 0 when no response came back
+	// amounts holds the amountOut of each quote served, one per size of a
+	// ladder; nil where a size had no quote
+	amounts []*big.Int
+}
+
+func (r result) ok() bool {
+	return r.status == http.StatusOK
+}
+
+// parseAmounts reads the amountOut of every quote in a v1 or v2 quote or
+// ladder response
+func parseAmounts(body []byte) []*big.Int {
+	var resp struct {
+		AmountOut json.RawMessage `json:"amountOut"`
+		Quotes    []struct {
+			Quote *struct {
+				AmountOut json.RawMessage `json:"amountOut"`
+			} `json:"quote"`
+		} `json:"quotes"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	if len(resp.AmountOut) > 0 {
+		return []*big.Int{parseAmount(resp.AmountOut)}
+	}
+	amounts := make([]*big.Int, len(resp.Quotes))
+	for i, q := range resp.Quotes {
+		if q.Quote != nil {
+			amounts[i] = parseAmount(q.Quote.AmountOut)
+		}
+	}
+	return amounts
+}
+
+// parseAmount reads a v1 decimal string or a v2 {raw, decimal} amount
+func parseAmount(raw json.RawMessage) *big.Int {
+	var value string
+	if json.Unmarshal(raw, &value) != nil {
+		var amount struct {
+			Raw string `json:"raw"`
+		}
+		if json.Unmarshal(raw, &amount) != nil {
+			return nil
+		}
+		value = amount.Raw
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil
+	}
+	return amount
+}
+
+// summary is one build's latency and node calls over the whole run
+type summary struct {
+	requests      int
+	errors        int
+	p50, p90, p99 time.Duration
+	max           time.Duration
+	callsPerReq   float64 // NaN when the build doesn't publish rpc_calls
+}
+
+func summarize(results []result, calls uint64, callsKnown bool) summary {
+	latencies := make([]time.Duration, 0, len(results))
+	s := summary{requests: len(results), callsPerReq: math.NaN()}
+	for _, r := range results {
+		if !r.ok() {
+			s.errors++
+		}
+		latencies = append(latencies, r.latency)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s.p50 = percentile(latencies, 0.50)
+	s.p90 = percentile(latencies, 0.90)
+	s.p99 = percentile(latencies, 0.99)
+	s.max = percentile(latencies, 1)
+	if callsKnown && len(results) > 0 {
+		s.callsPerReq = float64(calls) / float64(len(results))
+	}
+	return s
+}
+
+// percentile returns the nearest-rank q-th percentile of sorted
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// delta is how much more one of the candidate's quotes paid than the
+// base's for the same request, in basis points of the base's amount
+type delta struct {
+	request int
+	bps     float64
+}
+
+// comparison sets the candidate's quotes against the base's
+type comparison struct {
+	compared int
+	better   int
+	worse    int
+	// lost counts requests the base quoted that the candidate failed
+	lost   int
+	median float64
+	// worst holds the largest shortfalls, worst first
+	worst []delta
+}
+
+// compare matches every quote both builds served for a request, counting
+// differences beyond toleranceBps as better or worse
+func compare(base, candidate []result, toleranceBps float64, keepWorst int) comparison {
+	var c comparison
+	var deltas []delta
+	for i := range base {
+		if base[i].ok() && !candidate[i].ok() {
+			c.lost++
+			continue
+		}
+		if !base[i].ok() || len(base[i].amounts) != len(candidate[i].amounts) {
+			continue
+		}
+		for k, want := range base[i].amounts {
+			got := candidate[i].amounts[k]
+			if want == nil || got == nil || want.Sign() == 0 {
+				continue
+			}
+			diff := new(big.Float).SetInt(new(big.Int).Sub(got, want))
+			bps, _ := diff.Quo(diff.Mul(diff, big.NewFloat(10000)), new(big.Float).SetInt(want)).Float64()
+			deltas = append(deltas, delta{request: i, bps: bps})
+		}
+	}
+
+	c.compared = len(deltas)
+	if len(deltas) == 0 {
+		return c
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].bps < deltas[j].bps })
+	c.median = deltas[len(deltas)/2].bps
+	for _, d := range deltas {
+		switch {
+		case d.bps > toleranceBps:
+			c.better++
+		case d.bps < -toleranceBps:
+			c.worse++
+			if len(c.worst) < keepWorst {
+				c.worst = append(c.worst, d)
+			}
+		}
+	}
+	return c
+}
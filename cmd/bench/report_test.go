@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseAmounts(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"v1 quote", `{"amountOut":"2500000000"}`, []string{"2500000000"}},
+		{"v2 quote", `{"amountOut":{"raw":"2500000000","decimal":"2500"}}`, []string{"2500000000"}},
+		{"ladder", `{"quotes":[{"quote":{"amountOut":"10"}},{"error":{"code":"NO_ROUTE"}}]}`, []string{"10", ""}},
+		{"not json", `oops`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseAmounts([]byte(tt.body))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d amounts, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if (want == "") != (got[i] == nil) || (got[i] != nil && got[i].String() != want) {
+					t.Errorf("amount %d = %v, want %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	for q, want := range map[float64]time.Duration{0.5: 50 * time.Millisecond, 0.99: 99 * time.Millisecond, 1: 100 * time.Millisecond} {
+		if got := percentile(sorted, q); got != want {
+			t.Errorf("percentile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("percentile of nothing = %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	quoted := func(amounts ...int64) result {
+		r := result{status: http.StatusOK}
+		for _, a := range amounts {
+			r.amounts = append(r.amounts, big.NewInt(a))
+		}
+		return r
+	}
+	base := []result{quoted(10000), quoted(10000), quoted(10000), quoted(10000), {status: http.StatusNotFound}}
+	candidate := []result{quoted(10000), quoted(10010), quoted(9950), {status: http.StatusBadGateway}, quoted(10000)}
+
+	c := compare(base, candidate, 1, 5)
+	if c.compared != 3 || c.better != 1 || c.worse != 1 || c.lost != 1 {
+		t.Fatalf("compared %d, better %d, worse %d, lost %d; want 3, 1, 1, 1", c.compared, c.better, c.worse, c.lost)
+	}
+	if c.median != 0 {
+		t.Errorf("median = %v bps, want 0", c.median)
+	}
+	if len(c.worst) != 1 || c.worst[0].request != 2 || c.worst[0].bps != -50 {
+		t.Errorf("worst = %+v, want request 2 at -50 bps", c.worst)
+	}
+}
@@ -0,0 +1,60 @@
+// Command executor-dryrun checks that the executor contract would deploy:
+// it runs the creation bytecode against the node without sending anything
+// and prints the address, nonce and gas of the deployment.
+//
+//	ETH_RPC_URL=... go run ./cmd/executor-dryrun -bytecode Executor.bin -deployer 0x...
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/executor"
+)
+
+func main() {
+	bytecodePath := flag.String("bytecode", "", "file with the executor's hex creation bytecode")
+	deployer := flag.String("deployer", "", "address that would send the deployment")
+	flag.Parse()
+
+	if *bytecodePath == "" || !common.IsHexAddress(*deployer) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	raw, err := os.ReadFile(*bytecodePath)
+	if err != nil {
+		log.Fatalf("Failed to read bytecode: %v", err)
+	}
+	bytecode := common.FromHex(strings.TrimSpace(string(raw)))
+
+	rpcURL := os.Getenv("ETH_RPC_URL")
+	if rpcURL == "" {
+		rpcURL = "https://eth.llamarpc.com"
+	}
+	client, err := ethereum.NewClient(rpcURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to Ethereum: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deployment, err := executor.DryRunDeploy(ctx, client, common.HexToAddress(*deployer), bytecode)
+	if err != nil {
+		log.Fatalf("Dry run failed: %v", err)
+	}
+	fmt.Printf("chain:        %s\n", client.ChainID())
+	fmt.Printf("deployer:     %s (nonce %d)\n", deployment.Deployer.Hex(), deployment.Nonce)
+	fmt.Printf("executor:     %s\n", deployment.Address.Hex())
+	fmt.Printf("gas:          %d\n", deployment.Gas)
+	fmt.Printf("runtime code: %d bytes\n", deployment.RuntimeSize)
+}
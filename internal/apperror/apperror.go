@@ -0,0 +1,197 @@
+// Package apperror is the catalog of error codes the API reports. Each code
+// maps to one HTTP status and a message in every supported language, so
+// clients can branch on the code and show the message as is.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code is a machine-readable error code
+type Code string
+
+// Routing and pricing
+const (
+	NoRoute               Code = "NO_ROUTE"
+	InsufficientLiquidity Code = "INSUFFICIENT_LIQUIDITY"
+	AmountTooLarge        Code = "AMOUNT_TOO_LARGE"
+	UnsupportedToken      Code = "UNSUPPORTED_TOKEN"
+	RPCUnavailable        Code = "RPC_UNAVAILABLE" // The node, or every venue, failed to answer
+	UpstreamFailed        Code = "UPSTREAM_FAILED" // Some venues failed, and none of the others routed
+	TokenBlocked          Code = "TOKEN_BLOCKED"
+	PriceNotFound         Code = "PRICE_NOT_FOUND"
+	GasPriceUnavailable   Code = "GAS_PRICE_UNAVAILABLE"
+)
+
+// Request validation
+const (
+	InvalidBody      Code = "INVALID_BODY"
+	InvalidRequest   Code = "INVALID_REQUEST"
+	InvalidBatch     Code = "INVALID_BATCH"
+	MissingParams    Code = "MISSING_PARAMS"
+	MissingToken     Code = "MISSING_TOKEN"
+	InvalidToken     Code = "INVALID_TOKEN"
+	InvalidTokenIn   Code = "INVALID_TOKEN_IN"
+	InvalidTokenOut  Code = "INVALID_TOKEN_OUT"
+	AmbiguousToken   Code = "AMBIGUOUS_TOKEN"
+	InvalidAmount    Code = "INVALID_AMOUNT"
+	InvalidSlippage  Code = "INVALID_SLIPPAGE"
+	InvalidDeadline  Code = "INVALID_DEADLINE"
+	InvalidAddress   Code = "INVALID_ADDRESS"
+	InvalidSender    Code = "INVALID_SENDER"
+	InvalidRecipient Code = "INVALID_RECIPIENT"
+	InvalidReceiver  Code = "INVALID_RECEIVER"
+	InvalidFee       Code = "INVALID_FEE"
+	InvalidLiquidity Code = "INVALID_LIQUIDITY"
+	InvalidCurrency  Code = "INVALID_CURRENCY"
+	FeesDisabled     Code = "FEES_DISABLED"
+	VerifyDisabled   Code = "VERIFY_DISABLED"
+	InvalidStrategy  Code = "INVALID_STRATEGY"
+	InvalidSort      Code = "INVALID_SORT"
+	InvalidOffset    Code = "INVALID_OFFSET"
+	InvalidLimit     Code = "INVALID_LIMIT"
+	InvalidCursor    Code = "INVALID_CURSOR"
+	InvalidFilter    Code = "INVALID_FILTER"
+	InvalidFormat    Code = "INVALID_FORMAT"
+	InvalidChain     Code = "INVALID_CHAIN"
+	InvalidCycle     Code = "INVALID_CYCLE"
+	InvalidRoute     Code = "INVALID_ROUTE"
+	InvalidOrder     Code = "INVALID_ORDER"
+	InvalidIntent    Code = "INVALID_INTENT"
+	InvalidID        Code = "INVALID_ID"
+	InvalidHash      Code = "INVALID_HASH"
+	InvalidName      Code = "INVALID_NAME"
+	InvalidKey       Code = "INVALID_KEY"
+	InvalidPlan      Code = "INVALID_PLAN"
+	InvalidOptimize  Code = "INVALID_OPTIMIZE"
+	InvalidDEX       Code = "INVALID_DEX"
+	InvalidBookmark  Code = "INVALID_BOOKMARK"
+	InvalidBlock     Code = "INVALID_BLOCK"
+	InvalidPermit    Code = "INVALID_PERMIT"
+)
+
+// Lookups
+const (
+	QuoteNotFound    Code = "QUOTE_NOT_FOUND"
+	QuoteExpired     Code = "QUOTE_EXPIRED"
+	OrderNotFound    Code = "ORDER_NOT_FOUND"
+	IntentNotFound   Code = "INTENT_NOT_FOUND"
+	TxNotFound       Code = "TX_NOT_FOUND"
+	KeyNotFound      Code = "KEY_NOT_FOUND"
+	BookmarkNotFound Code = "BOOKMARK_NOT_FOUND"
+	PoolsDisabled    Code = "POOLS_DISABLED"
+)
+
+// Access
+const (
+	Unauthorized       Code = "UNAUTHORIZED"
+	MissingAPIKey      Code = "MISSING_API_KEY"
+	InvalidAPIKey      Code = "INVALID_API_KEY"
+	QuotaExceeded      Code = "QUOTA_EXCEEDED"
+	APIKeysUnavailable Code = "API_KEYS_UNAVAILABLE"
+	Overloaded         Code = "OVERLOADED"
+)
+
+// Execution and settlement
+const (
+	ExecutionFailed  Code = "EXECUTION_FAILED"
+	SettlementFailed Code = "SETTLEMENT_FAILED"
+	NothingToClaim   Code = "NOTHING_TO_CLAIM"
+	Internal         Code = "INTERNAL_ERROR"
+)
+
+var statuses = map[Code]int{
+	NoRoute:               http.StatusNotFound,
+	InsufficientLiquidity: http.StatusUnprocessableEntity,
+	AmountTooLarge:        http.StatusUnprocessableEntity,
+	UnsupportedToken:      http.StatusBadRequest,
+	RPCUnavailable:        http.StatusServiceUnavailable,
+	UpstreamFailed:        http.StatusBadGateway,
+	TokenBlocked:          http.StatusForbidden,
+	PriceNotFound:         http.StatusNotFound,
+	GasPriceUnavailable:   http.StatusServiceUnavailable,
+
+	QuoteNotFound:    http.StatusNotFound,
+	QuoteExpired:     http.StatusGone,
+	OrderNotFound:    http.StatusNotFound,
+	IntentNotFound:   http.StatusNotFound,
+	TxNotFound:       http.StatusNotFound,
+	KeyNotFound:      http.StatusNotFound,
+	BookmarkNotFound: http.StatusNotFound,
+	PoolsDisabled:    http.StatusNotFound,
+
+	Unauthorized:       http.StatusUnauthorized,
+	MissingAPIKey:      http.StatusUnauthorized,
+	InvalidAPIKey:      http.StatusUnauthorized,
+	QuotaExceeded:      http.StatusTooManyRequests,
+	APIKeysUnavailable: http.StatusServiceUnavailable,
+	Overloaded:         http.StatusServiceUnavailable,
+
+	ExecutionFailed:  http.StatusUnprocessableEntity,
+	SettlementFailed: http.StatusBadGateway,
+	NothingToClaim:   http.StatusUnprocessableEntity,
+	Internal:         http.StatusInternalServerError,
+}
+
+// Retryable reports whether the same request may succeed shortly, because
+// it failed on a node or venue rather than on the pair
+func (c Code) Retryable() bool {
+	return c == RPCUnavailable || c == UpstreamFailed
+}
+
+// Status is the HTTP status the code is served with. Codes without an
+// entry are request validation failures.
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// Error is an error with a catalog code. Detail says what went wrong with
+// this request, in English.
+type Error struct {
+	Code   Code
+	Detail string
+	Err    error // Optional cause
+}
+
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Wrap tags err with code, keeping err as the cause
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Detail: err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// As returns err as an *Error. An err wrapping a coded error keeps that
+// code with err's full message; any other err is tagged with fallback.
+func As(err error, fallback Code) *Error {
+	var coded *Error
+	if errors.As(err, &coded) {
+		if coded == err {
+			return coded
+		}
+		return &Error{Code: coded.Code, Detail: err.Error(), Err: err}
+	}
+	return Wrap(fallback, err)
+}
+
+// CodeOf returns the code err carries, or Internal
+func CodeOf(err error) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Internal
+}
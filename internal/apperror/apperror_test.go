@@ -0,0 +1,100 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCatalogIsComplete(t *testing.T) {
+	for code := range statuses {
+		if _, ok := messages[DefaultLanguage][code]; !ok {
+			t.Errorf("%s has a status but no %s message", code, DefaultLanguage)
+		}
+	}
+	for lang, catalog := range messages {
+		for code := range messages[DefaultLanguage] {
+			if _, ok := catalog[code]; !ok {
+				t.Errorf("%s has no %s message", code, lang)
+			}
+		}
+		if len(catalog) != len(messages[DefaultLanguage]) {
+			t.Errorf("%s has %d messages, %s has %d", lang, len(catalog), DefaultLanguage, len(messages[DefaultLanguage]))
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		code Code
+		want int
+	}{
+		{NoRoute, http.StatusNotFound},
+		{InsufficientLiquidity, http.StatusUnprocessableEntity},
+		{AmountTooLarge, http.StatusUnprocessableEntity},
+		{UnsupportedToken, http.StatusBadRequest},
+		{RPCUnavailable, http.StatusServiceUnavailable},
+		{InvalidAmount, http.StatusBadRequest},
+		{Internal, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Status(); got != tt.want {
+			t.Errorf("%s.Status() = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "en"},
+		{"id", "id"},
+		{"id-ID,id;q=0.9,en;q=0.8", "id"},
+		{"en-US,en;q=0.9,id;q=0.8", "en"},
+		{"fr-FR,id;q=0.5", "id"},
+		{"fr-FR,de;q=0.5", "en"},
+		{"en;q=0.2,ID;q=0.7", "id"},
+		{"id;q=0", "en"},
+		{"id;q=abc", "en"},
+	}
+	for _, tt := range tests {
+		if got := Language(tt.header); got != tt.want {
+			t.Errorf("Language(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMessageFallsBackToEnglish(t *testing.T) {
+	if got, want := NoRoute.Message("fr"), messages["en"][NoRoute]; got != want {
+		t.Errorf("Message(fr) = %q, want %q", got, want)
+	}
+	if got, want := Code("UNKNOWN").Message("id"), messages["en"][Internal]; got != want {
+		t.Errorf("unknown code message = %q, want %q", got, want)
+	}
+}
+
+func TestAs(t *testing.T) {
+	cause := New(RPCUnavailable, "dial tcp: connection refused")
+	wrapped := fmt.Errorf("failed to get prices: %w", cause)
+
+	got := As(wrapped, NoRoute)
+	if got.Code != RPCUnavailable || got.Detail != wrapped.Error() {
+		t.Errorf("As(wrapped) = %s %q, want %s %q", got.Code, got.Detail, RPCUnavailable, wrapped.Error())
+	}
+	if !errors.Is(got, cause) {
+		t.Error("As(wrapped) lost its cause")
+	}
+
+	if got := As(cause, NoRoute); got != cause {
+		t.Errorf("As(coded) = %v, want the error itself", got)
+	}
+	if got := As(errors.New("boom"), NoRoute); got.Code != NoRoute {
+		t.Errorf("As(plain) code = %s, want %s", got.Code, NoRoute)
+	}
+	if got := CodeOf(errors.New("boom")); got != Internal {
+		t.Errorf("CodeOf(plain) = %s, want %s", got, Internal)
+	}
+}
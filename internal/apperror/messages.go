@@ -0,0 +1,210 @@
+package apperror
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when the client accepts no supported language
+const DefaultLanguage = "en"
+
+// messages holds each code's message per language. Add a language by
+// adding a map with every code.
+var messages = map[string]map[Code]string{
+	"en": {
+		NoRoute:               "No route was found between these tokens.",
+		InsufficientLiquidity: "The pools for these tokens do not have enough liquidity for this trade.",
+		AmountTooLarge:        "The amount is too large to trade.",
+		UnsupportedToken:      "The token is not supported.",
+		RPCUnavailable:        "The blockchain node is unavailable. Try again shortly.",
+		UpstreamFailed:        "Some liquidity sources failed to answer. Try again shortly.",
+		TokenBlocked:          "The token is blocked.",
+		PriceNotFound:         "No price is available for this token.",
+		GasPriceUnavailable:   "The gas price is unavailable.",
+
+		InvalidBody:      "The request body is invalid.",
+		InvalidRequest:   "The request is invalid.",
+		InvalidBatch:     "The batch is invalid.",
+		MissingParams:    "Required parameters are missing.",
+		MissingToken:     "A token is required.",
+		InvalidToken:     "The token is invalid.",
+		InvalidTokenIn:   "The input token is invalid.",
+		InvalidTokenOut:  "The output token is invalid.",
+		AmbiguousToken:   "The token symbol matches several tokens. Use the token address.",
+		InvalidAmount:    "The amount is invalid.",
+		InvalidSlippage:  "The slippage is invalid.",
+		InvalidDeadline:  "The deadline is invalid.",
+		InvalidAddress:   "The address is invalid.",
+		InvalidSender:    "The sender is invalid.",
+		InvalidRecipient: "The recipient is invalid.",
+		InvalidReceiver:  "The receiver is invalid.",
+		InvalidFee:       "The fee is invalid.",
+		FeesDisabled:     "Integrator fees are not enabled.",
+		VerifyDisabled:   "Pool verification is not enabled.",
+		InvalidStrategy:  "The routing strategy is invalid.",
+		InvalidSort:      "The sort order is invalid.",
+		InvalidOffset:    "The offset is invalid.",
+		InvalidLimit:     "The limit is invalid.",
+		InvalidCursor:    "The page cursor is invalid.",
+		InvalidFilter:    "The filter is invalid.",
+		InvalidLiquidity: "The minimum liquidity is invalid.",
+		InvalidCurrency:  "The quote currency is not supported.",
+		InvalidFormat:    "The format is not supported.",
+		InvalidChain:     "The chain is invalid.",
+		InvalidCycle:     "The swap cycle is invalid.",
+		InvalidRoute:     "The route is invalid.",
+		InvalidOrder:     "The order is invalid.",
+		InvalidIntent:    "The intent is invalid.",
+		InvalidID:        "The ID is invalid.",
+		InvalidHash:      "The transaction hash is invalid.",
+		InvalidName:      "The name is invalid.",
+		InvalidKey:       "The API key settings are invalid.",
+		InvalidPlan:      "The execution plan settings are invalid.",
+		InvalidOptimize:  "The optimization target is invalid.",
+		InvalidDEX:       "The DEX list is invalid.",
+		InvalidBookmark:  "The route bookmark is invalid.",
+		InvalidBlock:     "The block is invalid.",
+		InvalidPermit:    "The Permit2 permit is invalid.",
+
+		QuoteNotFound:    "The quote was not found.",
+		QuoteExpired:     "The quote has expired. Request a new one.",
+		OrderNotFound:    "The order was not found.",
+		IntentNotFound:   "The intent was not found.",
+		TxNotFound:       "The transaction was not found.",
+		KeyNotFound:      "The API key was not found.",
+		BookmarkNotFound: "The route bookmark was not found.",
+		PoolsDisabled:    "Pool statistics are not enabled.",
+
+		Unauthorized:       "Authentication is required.",
+		MissingAPIKey:      "An API key is required.",
+		InvalidAPIKey:      "The API key is invalid or disabled.",
+		QuotaExceeded:      "The daily quota has been exceeded.",
+		APIKeysUnavailable: "API keys cannot be checked right now. Try again shortly.",
+		Overloaded:         "The service is busy. Try again shortly.",
+
+		ExecutionFailed:  "The swap could not be executed.",
+		SettlementFailed: "The settlement could not be built.",
+		NothingToClaim:   "There are no unclaimed fees in this token.",
+		Internal:         "An internal error occurred.",
+	},
+	"id": {
+		NoRoute:               "Tidak ditemukan rute antara token ini.",
+		InsufficientLiquidity: "Pool untuk token ini tidak memiliki likuiditas yang cukup untuk transaksi ini.",
+		AmountTooLarge:        "Jumlahnya terlalu besar untuk ditransaksikan.",
+		UnsupportedToken:      "Token tidak didukung.",
+		RPCUnavailable:        "Node blockchain tidak tersedia. Coba lagi sebentar lagi.",
+		UpstreamFailed:        "Sebagian sumber likuiditas gagal merespons. Coba lagi sebentar lagi.",
+		TokenBlocked:          "Token diblokir.",
+		PriceNotFound:         "Harga untuk token ini tidak tersedia.",
+		GasPriceUnavailable:   "Harga gas tidak tersedia.",
+
+		InvalidBody:      "Isi permintaan tidak valid.",
+		InvalidRequest:   "Permintaan tidak valid.",
+		InvalidBatch:     "Batch tidak valid.",
+		MissingParams:    "Parameter wajib tidak diisi.",
+		MissingToken:     "Token wajib diisi.",
+		InvalidToken:     "Token tidak valid.",
+		InvalidTokenIn:   "Token masukan tidak valid.",
+		InvalidTokenOut:  "Token keluaran tidak valid.",
+		AmbiguousToken:   "Simbol token cocok dengan beberapa token. Gunakan alamat token.",
+		InvalidAmount:    "Jumlah tidak valid.",
+		InvalidSlippage:  "Slippage tidak valid.",
+		InvalidDeadline:  "Batas waktu tidak valid.",
+		InvalidAddress:   "Alamat tidak valid.",
+		InvalidSender:    "Pengirim tidak valid.",
+		InvalidRecipient: "Penerima tidak valid.",
+		InvalidReceiver:  "Kontrak penerima tidak valid.",
+		InvalidFee:       "Biaya tidak valid.",
+		FeesDisabled:     "Biaya integrator tidak diaktifkan.",
+		VerifyDisabled:   "Verifikasi pool tidak diaktifkan.",
+		InvalidStrategy:  "Strategi rute tidak valid.",
+		InvalidSort:      "Urutan tidak valid.",
+		InvalidOffset:    "Offset tidak valid.",
+		InvalidLimit:     "Batas jumlah hasil tidak valid.",
+		InvalidCursor:    "Kursor halaman tidak valid.",
+		InvalidFilter:    "Filter tidak valid.",
+		InvalidLiquidity: "Likuiditas minimum tidak valid.",
+		InvalidCurrency:  "Mata uang kuotasi tidak didukung.",
+		InvalidFormat:    "Format tidak didukung.",
+		InvalidChain:     "Chain tidak valid.",
+		InvalidCycle:     "Siklus swap tidak valid.",
+		InvalidRoute:     "Rute tidak valid.",
+		InvalidOrder:     "Order tidak valid.",
+		InvalidIntent:    "Intent tidak valid.",
+		InvalidID:        "ID tidak valid.",
+		InvalidHash:      "Hash transaksi tidak valid.",
+		InvalidName:      "Nama tidak valid.",
+		InvalidKey:       "Pengaturan kunci API tidak valid.",
+		InvalidPlan:      "Pengaturan rencana eksekusi tidak valid.",
+		InvalidOptimize:  "Target optimasi tidak valid.",
+		InvalidDEX:       "Daftar DEX tidak valid.",
+		InvalidBookmark:  "Bookmark rute tidak valid.",
+		InvalidBlock:     "Blok tidak valid.",
+		InvalidPermit:    "Permit Permit2 tidak valid.",
+
+		QuoteNotFound:    "Kuotasi tidak ditemukan.",
+		QuoteExpired:     "Kuotasi sudah kedaluwarsa. Minta kuotasi baru.",
+		OrderNotFound:    "Order tidak ditemukan.",
+		IntentNotFound:   "Intent tidak ditemukan.",
+		TxNotFound:       "Transaksi tidak ditemukan.",
+		KeyNotFound:      "Kunci API tidak ditemukan.",
+		BookmarkNotFound: "Bookmark rute tidak ditemukan.",
+		PoolsDisabled:    "Statistik pool tidak diaktifkan.",
+
+		Unauthorized:       "Autentikasi diperlukan.",
+		MissingAPIKey:      "Kunci API diperlukan.",
+		InvalidAPIKey:      "Kunci API tidak valid atau dinonaktifkan.",
+		QuotaExceeded:      "Kuota harian telah terlampaui.",
+		APIKeysUnavailable: "Kunci API tidak dapat diperiksa saat ini. Coba lagi sebentar lagi.",
+		Overloaded:         "Layanan sedang sibuk. Coba lagi sebentar lagi.",
+
+		ExecutionFailed:  "Swap tidak dapat dieksekusi.",
+		SettlementFailed: "Settlement tidak dapat dibuat.",
+		NothingToClaim:   "Tidak ada biaya yang belum diklaim dalam token ini.",
+		Internal:         "Terjadi kesalahan internal.",
+	},
+}
+
+// Message returns the code's message in lang, falling back to English
+func (c Code) Message(lang string) string {
+	if msg, ok := messages[lang][c]; ok {
+		return msg
+	}
+	if msg, ok := messages[DefaultLanguage][c]; ok {
+		return msg
+	}
+	return messages[DefaultLanguage][Internal]
+}
+
+// Language picks the supported language the client prefers from an
+// Accept-Language header, matching on the primary subtag
+func Language(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := messages[primary]; ok && q > 0 {
+			candidates = append(candidates, candidate{primary, q})
+		}
+	}
+	if len(candidates) == 0 {
+		return DefaultLanguage
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	return candidates[0].lang
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// QuoteRecord is the analytics row kept for every served quote
+type QuoteRecord struct {
+	Time      time.Time
+	TokenIn   common.Address
+	TokenOut  common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	Venues    []DEXType // Venues the quote routed through; empty for RFQ fills
+	Strategy  string
+	RFQ       bool
+	Sources   []SourceDetail // Every venue asked, including failures
+	LatencyMs int64
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "time"
+
+// APIKey identifies an integrator. Only a hash of the key's secret is kept.
+type APIKey struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	SecretHash string    `json:"secretHash"`
+	DailyQuota int64     `json:"dailyQuota"` // Requests per UTC day; 0 is unlimited
+	Disabled   bool      `json:"disabled"`
+	Priority   bool      `json:"priority"` // Admitted ahead of other keys under load
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// APIKeyUsage counts a key's traffic, all time and for the current UTC day
+type APIKeyUsage struct {
+	Requests            int64
+	Quotes              int64
+	VolumeUSDCents      int64 // USD value of quoted input amounts
+	RequestsToday       int64
+	QuotesToday         int64
+	VolumeUSDCentsToday int64
+}
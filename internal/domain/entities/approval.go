@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenQuirks records where a token departs from ERC-20 in ways that change
+// how it is approved
+type TokenQuirks struct {
+	ApproveResetRequired bool `json:"approveResetRequired,omitempty"` // approve reverts when replacing one non-zero allowance with another
+	NoReturnValue        bool `json:"noReturnValue,omitempty"`        // approve and transfer return nothing instead of a bool
+	Blacklistable        bool `json:"blacklistable,omitempty"`        // the issuer can freeze addresses
+}
+
+// KnownTokenQuirks lists mainnet tokens whose quirks hold regardless of
+// what probing finds. Probing only sees a reset requirement when the
+// owner already has an allowance.
+var KnownTokenQuirks = map[common.Address]TokenQuirks{
+	USDT.Address: {ApproveResetRequired: true, NoReturnValue: true, Blacklistable: true},
+	USDC.Address: {Blacklistable: true},
+	// Legacy Kyber Network Crystal
+	common.HexToAddress("0xdd974D5C2e2928deA5F71b9825b8b646686BD200"): {ApproveResetRequired: true},
+	// BNB on Ethereum
+	common.HexToAddress("0xB8c77482e45F1F44dE1745F52C74426C631bDD52"): {NoReturnValue: true},
+}
+
+// ApprovalPlan lists the transactions an owner must send, in order, before
+// spender can pull Amount of Token. Steps is empty when the current
+// allowance already covers the swap.
+type ApprovalPlan struct {
+	Token     common.Address     `json:"token"`
+	Owner     common.Address     `json:"owner"`
+	Spender   common.Address     `json:"spender"`
+	Allowance *big.Int           `json:"allowance"` // Current allowance
+	Amount    *big.Int           `json:"amount"`
+	Quirks    TokenQuirks        `json:"quirks"`
+	Frozen    bool               `json:"frozen,omitempty"` // Token has blacklisted Owner, so the approvals and swap revert
+	Steps     []*SwapTransaction `json:"steps,omitempty"`
+
+	// Permit2Spender is the contract Spender, Permit2, passes the token on
+	// to, for swaps that pull their input through Permit2
+	Permit2Spender *common.Address `json:"permit2Spender,omitempty"`
+	// Permit is set when Permit2's allowance of Permit2Spender falls short.
+	// Signed and sent with the swap, it replaces Permit2's approval, the
+	// last of Steps.
+	Permit *Permit2Permit `json:"permit,omitempty"`
+}
+
+// Permit2Permit is Permit2's PermitSingle: an allowance of Spender to pull
+// up to Amount of Token until Expiration, granted by the owner's signature
+// rather than a transaction. Times are Unix seconds.
+type Permit2Permit struct {
+	Token       common.Address `json:"token"`
+	Amount      *big.Int       `json:"amount"` // uint160
+	Expiration  uint64         `json:"expiration"`
+	Nonce       uint64         `json:"nonce"` // Permit2's nonce for the owner, token and spender
+	Spender     common.Address `json:"spender"`
+	SigDeadline uint64         `json:"sigDeadline"`
+	Signature   []byte         `json:"signature,omitempty"`
+}
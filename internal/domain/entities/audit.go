@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// QuoteAudit records every on-chain input a quote was derived from: each
+// pool's state as it was read, with its block, and the amounts derived from
+// them. Replaying it needs no node, so a disputed quote can be re-derived
+// offline.
+type QuoteAudit struct {
+	TokenIn          common.Address `json:"tokenIn"`
+	TokenOut         common.Address `json:"tokenOut"`
+	AmountIn         *big.Int       `json:"amountIn"`
+	QuotedAtBlock    uint64         `json:"quotedAtBlock"`
+	Pools            []Pair         `json:"pools"`          // As read, including their block
+	Routes           []AuditRoute   `json:"routes"`         // The best route, or each leg of a split
+	RouteAmountOut   *big.Int       `json:"routeAmountOut"` // Summed over the routes, before fees
+	SlippageBps      uint64         `json:"slippageBps"`
+	IntegratorFeeBps uint64         `json:"integratorFeeBps,omitempty"`
+	AmountOut        *big.Int       `json:"amountOut"`
+	MinAmountOut     *big.Int       `json:"minAmountOut,omitempty"`
+	RFQOrder         *RFQOrder      `json:"rfqOrder,omitempty"` // A firm order, taken as quoted
+}
+
+// AuditRoute is one route of an audited quote
+type AuditRoute struct {
+	AmountIn  *big.Int   `json:"amountIn"`
+	AmountOut *big.Int   `json:"amountOut"`
+	Hops      []AuditHop `json:"hops"`
+}
+
+// AuditHop is one swap of an audited route against Pools[Pool]
+type AuditHop struct {
+	Pool      int            `json:"pool"`
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	AmountOut *big.Int       `json:"amountOut"`
+}
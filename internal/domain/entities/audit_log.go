@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// AuditLogEntry is a sampled quote request as it was answered, kept so an
+// incident review can find what a client was quoted and when
+type AuditLogEntry struct {
+	Time         time.Time         `json:"time"`
+	RequestID    string            `json:"requestId,omitempty"`
+	APIKeyID     string            `json:"apiKeyId,omitempty"`
+	ClientIP     string            `json:"clientIp,omitempty"`
+	Sender       string            `json:"sender,omitempty"` // As resolved, checksummed
+	Recipient    string            `json:"recipient,omitempty"`
+	Path         string            `json:"path"`
+	Params       map[string]string `json:"params"`
+	Quotes       []AuditLogQuote   `json:"quotes"`       // One per size of a ladder
+	ResponseHash string            `json:"responseHash"` // SHA-256 of the body sent, hex
+}
+
+// AuditLogQuote is one served quote: its chosen routes with the pool
+// states behind them, and every venue's quote for the whole trade
+type AuditLogQuote struct {
+	QuoteID string         `json:"quoteId,omitempty"`
+	Route   *QuoteAudit    `json:"route"`
+	Venues  []SourceDetail `json:"venues"`
+}
+
+// AuditLogFilter selects audit log entries. Empty fields match anything.
+type AuditLogFilter struct {
+	APIKeyID string
+	ClientIP string
+	Address  string // The sender or the recipient
+	From     time.Time
+	To       time.Time
+}
+
+// Matches reports whether entry passes every set field of f
+func (f AuditLogFilter) Matches(entry AuditLogEntry) bool {
+	switch {
+	case f.APIKeyID != "" && entry.APIKeyID != f.APIKeyID:
+		return false
+	case f.ClientIP != "" && entry.ClientIP != f.ClientIP:
+		return false
+	case f.Address != "" && !strings.EqualFold(entry.Sender, f.Address) && !strings.EqualFold(entry.Recipient, f.Address):
+		return false
+	case !f.From.IsZero() && entry.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && entry.Time.After(f.To):
+		return false
+	}
+	return true
+}
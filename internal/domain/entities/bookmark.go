@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RouteBookmark is a quote configuration an integrator saved under a name,
+// so a fixed flow such as "buy USDC with ETH" is quoted from an amount
+// alone. Names are unique per API key.
+type RouteBookmark struct {
+	Name         string          `json:"name"`
+	KeyID        string          `json:"keyId"`
+	TokenIn      common.Address  `json:"tokenIn"`
+	TokenOut     common.Address  `json:"tokenOut"`
+	DEXes        []DEXType       `json:"dexes,omitempty"`    // Empty routes across every venue
+	Slippage     string          `json:"slippage,omitempty"` // Basis points or "auto"; empty keeps the pair-class default
+	FeeBps       uint64          `json:"feeBps,omitempty"`
+	FeeRecipient *common.Address `json:"feeRecipient,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	UpdatedAt    time.Time       `json:"updatedAt"`
+}
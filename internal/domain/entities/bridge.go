@@ -0,0 +1,105 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Chain IDs supported by the bridge adapters
+const (
+	ChainEthereum uint64 = 1
+	ChainOptimism uint64 = 10
+	ChainPolygon  uint64 = 137
+	ChainBase     uint64 = 8453
+	ChainArbitrum uint64 = 42161
+)
+
+// BridgeAsset is a token that bridges move natively between chains
+type BridgeAsset struct {
+	Symbol    string
+	Name      string
+	Decimals  uint8
+	Addresses map[uint64]common.Address
+}
+
+// Token returns the asset's token on chainID
+func (a BridgeAsset) Token(chainID uint64) (Token, bool) {
+	addr, ok := a.Addresses[chainID]
+	if !ok {
+		return Token{}, false
+	}
+	return Token{Address: addr, Symbol: a.Symbol, Name: a.Name, Decimals: a.Decimals}, true
+}
+
+// BridgeAssets lists the assets cross-chain routes may bridge, most liquid first
+var BridgeAssets = []BridgeAsset{
+	{
+		Symbol:   USDC.Symbol,
+		Name:     USDC.Name,
+		Decimals: USDC.Decimals,
+		Addresses: map[uint64]common.Address{
+			ChainEthereum: USDC.Address,
+			ChainOptimism: common.HexToAddress("0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85"),
+			ChainPolygon:  common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"),
+			ChainBase:     common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+			ChainArbitrum: common.HexToAddress("0xaf88d065e77c8cC2239327C5EDb3A432268e5831"),
+		},
+	},
+	{
+		Symbol:   WETH.Symbol,
+		Name:     WETH.Name,
+		Decimals: WETH.Decimals,
+		Addresses: map[uint64]common.Address{
+			ChainEthereum: WETH.Address,
+			ChainOptimism: common.HexToAddress("0x4200000000000000000000000000000000000006"),
+			ChainPolygon:  common.HexToAddress("0x7ceB23fD6bC0adD59E62ac25578270cFf1b9f619"),
+			ChainBase:     common.HexToAddress("0x4200000000000000000000000000000000000006"),
+			ChainArbitrum: common.HexToAddress("0x82aF49447D8a07e3bd95BD0d56f35241523fBab1"),
+		},
+	},
+}
+
+// FindBridgeToken returns the bridge asset token at addr on chainID
+func FindBridgeToken(chainID uint64, addr common.Address) (Token, bool) {
+	for _, asset := range BridgeAssets {
+		if token, ok := asset.Token(chainID); ok && token.Address == addr {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// BridgeQuote is one bridge's offer to move AmountIn of an asset between
+// chains. Fee is in the bridged asset; NativeFee is paid in the source
+// chain's gas token on top of AmountIn.
+type BridgeQuote struct {
+	Bridge           string   `json:"bridge"`
+	SrcChainID       uint64   `json:"srcChainId"`
+	DstChainID       uint64   `json:"dstChainId"`
+	TokenIn          Token    `json:"tokenIn"`
+	TokenOut         Token    `json:"tokenOut"`
+	AmountIn         *big.Int `json:"amountIn"`
+	AmountOut        *big.Int `json:"amountOut"`
+	Fee              *big.Int `json:"fee"`
+	NativeFee        *big.Int `json:"nativeFee,omitempty"`
+	EstimatedTimeSec uint64   `json:"estimatedTimeSec"`
+}
+
+// CrossChainQuote composes an optional source-chain swap into a bridge
+// asset, the bridge leg, and an optional destination-chain swap
+type CrossChainQuote struct {
+	SrcChainID       uint64       `json:"srcChainId"`
+	DstChainID       uint64       `json:"dstChainId"`
+	TokenIn          Token        `json:"tokenIn"`
+	TokenOut         Token        `json:"tokenOut"`
+	AmountIn         *big.Int     `json:"amountIn"`
+	AmountOut        *big.Int     `json:"amountOut"`
+	MinAmountOut     *big.Int     `json:"minAmountOut"`
+	SlippageBps      uint64       `json:"slippageBps"`
+	SourceSwap       *Quote       `json:"sourceSwap,omitempty"`
+	Bridge           *BridgeQuote `json:"bridge"`
+	DestinationSwap  *Quote       `json:"destinationSwap,omitempty"`
+	EstimatedTimeSec uint64       `json:"estimatedTimeSec"`
+	TotalFeeUSD      *big.Int     `json:"totalFeeUsd,omitempty"` // 18 decimals; bridge fee plus swap gas
+}
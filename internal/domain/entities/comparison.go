@@ -0,0 +1,20 @@
+package entities
+
+import "math/big"
+
+// ReferenceQuote is another aggregator's answer for the same swap. DeltaBps
+// is our output relative to theirs; positive means we route better.
+type ReferenceQuote struct {
+	Source    string   `json:"source"`
+	AmountOut *big.Int `json:"amountOut,omitempty"`
+	Delta     *big.Int `json:"delta,omitempty"`
+	DeltaBps  int64    `json:"deltaBps"`
+	LatencyMs int64    `json:"latencyMs"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// QuoteComparison puts our best quote next to external references
+type QuoteComparison struct {
+	Quote      *Quote           `json:"quote"`
+	References []ReferenceQuote `json:"references"`
+}
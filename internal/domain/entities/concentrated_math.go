@@ -0,0 +1,371 @@
+package entities
+
+import (
+	"math/big"
+)
+
+// Uniswap V3 tick bounds
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+var (
+	q96          = new(big.Int).Lsh(big.NewInt(1), 96)
+	maxUint256   = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	feeDenom     = big.NewInt(1_000_000)
+	minSqrtRatio = big.NewInt(4295128739)
+	maxSqrtRatio = mustBig("1461446703485210103287273052203988822378723970342")
+)
+
+// sqrtRatioFactors are TickMath's per-bit multipliers, for bits 1 to 19
+var sqrtRatioFactors = []*big.Int{
+	mustHex("fff97272373d413259a46990580e213a"),
+	mustHex("fff2e50f5f656932ef12357cf3c7fdcc"),
+	mustHex("ffe5caca7e10e4e61c3624eaa0941cd0"),
+	mustHex("ffcb9843d60f6159c9db58835c926644"),
+	mustHex("ff973b41fa98c081472e6896dfb254c0"),
+	mustHex("ff2ea16466c96a3843ec78b326b52861"),
+	mustHex("fe5dee046a99a2a811c461f1969c3053"),
+	mustHex("fcbe86c7900a88aedcffc83b479aa3a4"),
+	mustHex("f987a7253ac413176f2b074cf7815e54"),
+	mustHex("f3392b0822b70005940c7a398e4b70f3"),
+	mustHex("e7159475a2c29b7443b29c7fa6e889d9"),
+	mustHex("d097f3bdfd2022b8845ad8f792aa5825"),
+	mustHex("a9f746462d870fdf8a65dc1f90e061e5"),
+	mustHex("70d869a156d2a1b890bb3df62baf32f7"),
+	mustHex("31be135f97d08fd981231505542fcfa6"),
+	mustHex("9aa508b5b7a84e1c677de54f3e99bc9"),
+	mustHex("5d6af8dedb81196699c329225ee604"),
+	mustHex("2216e584f5fa1ea926041bedfe98"),
+	mustHex("48a170391f7dc42444e8fa2"),
+}
+
+// ConcentratedLiquidity is a Uniswap V3 pool's state around the current
+// price, enough to simulate swaps without the quoter. Ticks lists every
+// initialized tick between TickLow and TickHigh in ascending order; a swap
+// that would leave that window stops at its edge.
+type ConcentratedLiquidity struct {
+	SqrtPriceX96 *big.Int   `json:"sqrtPriceX96"`
+	Tick         int32      `json:"tick"`
+	Liquidity    *big.Int   `json:"liquidity"`
+	TickLow      int32      `json:"tickLow"`
+	TickHigh     int32      `json:"tickHigh"`
+	Ticks        []TickData `json:"ticks"`
+}
+
+// TickData is an initialized tick and the liquidity added when it is
+// crossed upwards
+type TickData struct {
+	Index        int32    `json:"index"`
+	LiquidityNet *big.Int `json:"liquidityNet"`
+}
+
+// VirtualReserves are the constant product reserves matching the pool's
+// in-range liquidity at the current price
+func (c *ConcentratedLiquidity) VirtualReserves() (*big.Int, *big.Int) {
+	if c.SqrtPriceX96 == nil || c.SqrtPriceX96.Sign() == 0 || c.Liquidity == nil {
+		return big.NewInt(0), big.NewInt(0)
+	}
+	reserve0 := new(big.Int).Lsh(c.Liquidity, 96)
+	reserve0.Div(reserve0, c.SqrtPriceX96)
+	reserve1 := new(big.Int).Mul(c.Liquidity, c.SqrtPriceX96)
+	reserve1.Rsh(reserve1, 96)
+	return reserve0, reserve1
+}
+
+// amountOut simulates an exact input swap. Input that can't be filled
+// within the tick window is not counted, so the result is a lower bound.
+func (c *ConcentratedLiquidity) amountOut(amountIn *big.Int, zeroForOne bool, feePips uint64) *big.Int {
+	_, out := c.swap(zeroForOne, amountIn, true, feePips)
+	if out == nil {
+		return big.NewInt(0)
+	}
+	return out
+}
+
+// amountIn simulates an exact output swap, returning nil when the tick
+// window can't supply amountOut
+func (c *ConcentratedLiquidity) amountIn(amountOut *big.Int, zeroForOne bool, feePips uint64) *big.Int {
+	in, out := c.swap(zeroForOne, amountOut, false, feePips)
+	if in == nil || out.Cmp(amountOut) < 0 {
+		return nil
+	}
+	return in
+}
+
+// swap mirrors UniswapV3Pool.swap without a price limit. It returns the
+// input spent, including fees, and the output received.
+func (c *ConcentratedLiquidity) swap(zeroForOne bool, amount *big.Int, exactIn bool, feePips uint64) (*big.Int, *big.Int) {
+	if c.SqrtPriceX96 == nil || c.Liquidity == nil || feePips >= 1_000_000 {
+		return nil, nil
+	}
+
+	remaining := new(big.Int).Set(amount)
+	totalIn, totalOut := new(big.Int), new(big.Int)
+	sqrtPrice := new(big.Int).Set(c.SqrtPriceX96)
+	tick := c.Tick
+	liquidity := new(big.Int).Set(c.Liquidity)
+
+	for remaining.Sign() > 0 {
+		next, liquidityNet, edge := c.nextTick(tick, zeroForOne)
+		sqrtNext := sqrtRatioAtTick(next)
+
+		var in, out, fee *big.Int
+		var reached bool
+		sqrtPrice, in, out, fee, reached = swapStep(sqrtPrice, sqrtNext, liquidity, remaining, exactIn, feePips)
+		totalIn.Add(totalIn, in).Add(totalIn, fee)
+		totalOut.Add(totalOut, out)
+		if exactIn {
+			remaining.Sub(remaining, in).Sub(remaining, fee)
+		} else {
+			remaining.Sub(remaining, out)
+		}
+
+		if !reached {
+			break
+		}
+		if edge {
+			// Liquidity beyond the window is unknown
+			break
+		}
+		if liquidityNet != nil {
+			if zeroForOne {
+				liquidity.Sub(liquidity, liquidityNet)
+			} else {
+				liquidity.Add(liquidity, liquidityNet)
+			}
+			if liquidity.Sign() < 0 {
+				return nil, nil
+			}
+		}
+		if zeroForOne {
+			tick = next - 1
+		} else {
+			tick = next
+		}
+	}
+	return totalIn, totalOut
+}
+
+// nextTick returns the next initialized tick at or below tick when
+// zeroForOne, or above it otherwise, with its liquidity net. Without one in
+// the window it returns the window's edge.
+func (c *ConcentratedLiquidity) nextTick(tick int32, zeroForOne bool) (int32, *big.Int, bool) {
+	if zeroForOne {
+		for i := len(c.Ticks) - 1; i >= 0; i-- {
+			if c.Ticks[i].Index <= tick && c.Ticks[i].Index >= c.TickLow {
+				return c.Ticks[i].Index, c.Ticks[i].LiquidityNet, false
+			}
+		}
+		return max(c.TickLow, MinTick), nil, true
+	}
+	for _, t := range c.Ticks {
+		if t.Index > tick && t.Index <= c.TickHigh {
+			return t.Index, t.LiquidityNet, false
+		}
+	}
+	return min(c.TickHigh, MaxTick), nil, true
+}
+
+// swapStep mirrors SwapMath.computeSwapStep towards sqrtTarget. reached
+// reports whether the price got there.
+func swapStep(sqrtCurrent, sqrtTarget, liquidity, remaining *big.Int, exactIn bool, feePips uint64) (sqrtNext, amountIn, amountOut, fee *big.Int, reached bool) {
+	zeroForOne := sqrtCurrent.Cmp(sqrtTarget) >= 0
+	feeBig := new(big.Int).SetUint64(feePips)
+	feeComplement := new(big.Int).Sub(feeDenom, feeBig)
+
+	if exactIn {
+		lessFee := mulDiv(remaining, feeComplement, feeDenom)
+		if zeroForOne {
+			amountIn = amount0Delta(sqrtTarget, sqrtCurrent, liquidity, true)
+		} else {
+			amountIn = amount1Delta(sqrtCurrent, sqrtTarget, liquidity, true)
+		}
+		if lessFee.Cmp(amountIn) >= 0 {
+			sqrtNext = sqrtTarget
+		} else {
+			sqrtNext = nextSqrtPriceFromInput(sqrtCurrent, liquidity, lessFee, zeroForOne)
+		}
+	} else {
+		if zeroForOne {
+			amountOut = amount1Delta(sqrtTarget, sqrtCurrent, liquidity, false)
+		} else {
+			amountOut = amount0Delta(sqrtCurrent, sqrtTarget, liquidity, false)
+		}
+		if remaining.Cmp(amountOut) >= 0 {
+			sqrtNext = sqrtTarget
+		} else {
+			sqrtNext = nextSqrtPriceFromOutput(sqrtCurrent, liquidity, remaining, zeroForOne)
+		}
+	}
+
+	reached = sqrtNext.Cmp(sqrtTarget) == 0
+	if zeroForOne {
+		if !reached || !exactIn {
+			amountIn = amount0Delta(sqrtNext, sqrtCurrent, liquidity, true)
+		}
+		if !reached || exactIn {
+			amountOut = amount1Delta(sqrtNext, sqrtCurrent, liquidity, false)
+		}
+	} else {
+		if !reached || !exactIn {
+			amountIn = amount1Delta(sqrtCurrent, sqrtNext, liquidity, true)
+		}
+		if !reached || exactIn {
+			amountOut = amount0Delta(sqrtCurrent, sqrtNext, liquidity, false)
+		}
+	}
+
+	if !exactIn && amountOut.Cmp(remaining) > 0 {
+		amountOut = new(big.Int).Set(remaining)
+	}
+	if exactIn && !reached {
+		fee = new(big.Int).Sub(remaining, amountIn)
+	} else {
+		fee = mulDivRoundingUp(amountIn, feeBig, feeComplement)
+	}
+	return sqrtNext, amountIn, amountOut, fee, reached
+}
+
+// amount0Delta mirrors SqrtPriceMath.getAmount0Delta
+func amount0Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	if sqrtA.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(sqrtB, sqrtA)
+	if roundUp {
+		return divRoundingUp(mulDivRoundingUp(numerator1, numerator2, sqrtB), sqrtA)
+	}
+	out := mulDiv(numerator1, numerator2, sqrtB)
+	return out.Div(out, sqrtA)
+}
+
+// amount1Delta mirrors SqrtPriceMath.getAmount1Delta
+func amount1Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) *big.Int {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	diff := new(big.Int).Sub(sqrtB, sqrtA)
+	if roundUp {
+		return mulDivRoundingUp(liquidity, diff, q96)
+	}
+	return mulDiv(liquidity, diff, q96)
+}
+
+func nextSqrtPriceFromInput(sqrtPrice, liquidity, amountIn *big.Int, zeroForOne bool) *big.Int {
+	if zeroForOne {
+		return nextSqrtPriceFromAmount0(sqrtPrice, liquidity, amountIn, true)
+	}
+	return nextSqrtPriceFromAmount1(sqrtPrice, liquidity, amountIn, true)
+}
+
+func nextSqrtPriceFromOutput(sqrtPrice, liquidity, amountOut *big.Int, zeroForOne bool) *big.Int {
+	if zeroForOne {
+		return nextSqrtPriceFromAmount1(sqrtPrice, liquidity, amountOut, false)
+	}
+	return nextSqrtPriceFromAmount0(sqrtPrice, liquidity, amountOut, false)
+}
+
+// nextSqrtPriceFromAmount0 mirrors getNextSqrtPriceFromAmount0RoundingUp
+func nextSqrtPriceFromAmount0(sqrtPrice, liquidity, amount *big.Int, add bool) *big.Int {
+	if amount.Sign() == 0 {
+		return new(big.Int).Set(sqrtPrice)
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	product := new(big.Int).Mul(amount, sqrtPrice)
+	denominator := new(big.Int)
+	if add {
+		denominator.Add(numerator1, product)
+	} else {
+		denominator.Sub(numerator1, product)
+	}
+	if denominator.Sign() <= 0 {
+		// The output exceeds the range's reserves; price moves to the bound
+		return new(big.Int).Set(maxSqrtRatio)
+	}
+	return mulDivRoundingUp(numerator1, sqrtPrice, denominator)
+}
+
+// nextSqrtPriceFromAmount1 mirrors getNextSqrtPriceFromAmount1RoundingDown
+func nextSqrtPriceFromAmount1(sqrtPrice, liquidity, amount *big.Int, add bool) *big.Int {
+	if liquidity.Sign() == 0 {
+		return new(big.Int).Set(sqrtPrice)
+	}
+	shifted := new(big.Int).Lsh(amount, 96)
+	if add {
+		return shifted.Div(shifted, liquidity).Add(shifted, sqrtPrice)
+	}
+	quotient := divRoundingUp(shifted, liquidity)
+	if quotient.Cmp(sqrtPrice) >= 0 {
+		return new(big.Int).Set(minSqrtRatio)
+	}
+	return quotient.Sub(sqrtPrice, quotient)
+}
+
+// sqrtRatioAtTick mirrors TickMath.getSqrtRatioAtTick
+func sqrtRatioAtTick(tick int32) *big.Int {
+	absTick := int64(tick)
+	if absTick < 0 {
+		absTick = -absTick
+	}
+
+	ratio := mustHex("100000000000000000000000000000000")
+	if absTick&1 != 0 {
+		ratio = mustHex("fffcb933bd6fad37aa2d162d1a594001")
+	}
+	for bit, factor := range sqrtRatioFactors {
+		if absTick&(2<<bit) != 0 {
+			ratio.Mul(ratio, factor)
+			ratio.Rsh(ratio, 128)
+		}
+	}
+	if tick > 0 {
+		ratio = new(big.Int).Div(maxUint256, ratio)
+	}
+
+	// Round up when converting from Q128.128 to Q64.96
+	remainder := new(big.Int).And(ratio, big.NewInt(1<<32-1))
+	ratio.Rsh(ratio, 32)
+	if remainder.Sign() != 0 {
+		ratio.Add(ratio, big.NewInt(1))
+	}
+	return ratio
+}
+
+func mulDiv(a, b, denominator *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	return out.Div(out, denominator)
+}
+
+func mulDivRoundingUp(a, b, denominator *big.Int) *big.Int {
+	return divRoundingUp(new(big.Int).Mul(a, b), denominator)
+}
+
+func divRoundingUp(a, b *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+func mustHex(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex constant " + s)
+	}
+	return v
+}
+
+func mustBig(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid decimal constant " + s)
+	}
+	return v
+}
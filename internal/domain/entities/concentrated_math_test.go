@@ -0,0 +1,140 @@
+package entities
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSqrtRatioAtTick(t *testing.T) {
+	tests := []struct {
+		tick int32
+		want string
+	}{
+		{0, "79228162514264337593543950336"},
+		{MinTick, "4295128739"},
+		{MaxTick, "1461446703485210103287273052203988822378723970342"},
+	}
+	for _, tt := range tests {
+		if got := sqrtRatioAtTick(tt.tick).String(); got != tt.want {
+			t.Errorf("sqrtRatioAtTick(%d) = %s, want %s", tt.tick, got, tt.want)
+		}
+	}
+
+	// Intermediate ticks agree with sqrt(1.0001^tick) · 2^96
+	for _, tick := range []int32{1, -1, 60, -60, 887, 50000, -50000, 200000} {
+		got, _ := new(big.Float).SetInt(sqrtRatioAtTick(tick)).Float64()
+		want := math.Pow(1.0001, float64(tick)/2) * math.Pow(2, 96)
+		if math.Abs(got-want)/want > 1e-10 {
+			t.Errorf("sqrtRatioAtTick(%d) = %g, want %g", tick, got, want)
+		}
+	}
+}
+
+// v3Pair is a 0.3% pool at price 1 with liquidity L in [-600, 600) and 2L
+// in [600, 1200)
+func v3Pair() *Pair {
+	liquidity := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	return &Pair{
+		Token0: Token{Address: common.HexToAddress("0x01"), Decimals: 18},
+		Token1: Token{Address: common.HexToAddress("0x02"), Decimals: 18},
+		Fee:    3000,
+		Concentrated: &ConcentratedLiquidity{
+			SqrtPriceX96: sqrtRatioAtTick(0),
+			Tick:         0,
+			Liquidity:    liquidity,
+			TickLow:      -1200,
+			TickHigh:     1200,
+			Ticks: []TickData{
+				{Index: -600, LiquidityNet: liquidity},
+				{Index: 600, LiquidityNet: liquidity},
+				{Index: 1200, LiquidityNet: new(big.Int).Neg(new(big.Int).Mul(liquidity, big.NewInt(2)))},
+			},
+		},
+	}
+}
+
+func TestConcentratedGetAmountOut(t *testing.T) {
+	pair := v3Pair()
+
+	// A small trade within the range fills at about par less the 0.3% fee
+	out := pair.GetAmountOut(ether(1000), pair.Token0.Address)
+	if out.Cmp(ether(996)) < 0 || out.Cmp(ether(997)) > 0 {
+		t.Errorf("GetAmountOut() = %s, want about 996.9e18", out)
+	}
+
+	// Matches the closed form within one range: Δy = L·(√P - √P')
+	oneForZero := pair.GetAmountOut(ether(1000), pair.Token1.Address)
+	if oneForZero.Cmp(ether(996)) < 0 || oneForZero.Cmp(ether(997)) > 0 {
+		t.Errorf("reverse GetAmountOut() = %s, want about 996.9e18", oneForZero)
+	}
+
+	// There is no liquidity below tick -600, so a huge sale only receives
+	// the token1 held in [-600, 0)
+	huge := pair.GetAmountOut(ether(1_000_000_000), pair.Token0.Address)
+	capped := amount1Delta(sqrtRatioAtTick(-600), sqrtRatioAtTick(0), pair.Concentrated.Liquidity, false)
+	if huge.Cmp(capped) != 0 {
+		t.Errorf("GetAmountOut() = %s, want the range's %s", huge, capped)
+	}
+}
+
+func TestConcentratedCrossesTicks(t *testing.T) {
+	pair := v3Pair()
+
+	// Buying token0 past tick 600 doubles liquidity, so the second half of
+	// a large trade slips less than a single-range pool would
+	single := v3Pair()
+	single.Concentrated.Ticks = []TickData{
+		{Index: -600, LiquidityNet: single.Concentrated.Liquidity},
+		{Index: 1200, LiquidityNet: new(big.Int).Neg(single.Concentrated.Liquidity)},
+	}
+
+	amountIn := ether(60_000)
+	crossed := pair.GetAmountOut(amountIn, pair.Token1.Address)
+	flat := single.GetAmountOut(amountIn, single.Token1.Address)
+	if crossed.Cmp(flat) <= 0 {
+		t.Errorf("output across the deeper range %s should beat the single range %s", crossed, flat)
+	}
+}
+
+func TestConcentratedGetAmountIn(t *testing.T) {
+	pair := v3Pair()
+
+	for _, amount := range []*big.Int{ether(1), ether(1000), ether(20_000)} {
+		amountIn := pair.GetAmountIn(amount, pair.Token0.Address)
+		if amountIn == nil {
+			t.Fatalf("GetAmountIn(%s) = nil", amount)
+		}
+		// Spending the exact input must buy at least the requested output
+		if out := pair.GetAmountOut(amountIn, pair.Token0.Address); out.Cmp(amount) < 0 {
+			t.Errorf("GetAmountOut(GetAmountIn(%s)) = %s, want at least the requested amount", amount, out)
+		}
+		less := new(big.Int).Sub(amountIn, big.NewInt(2))
+		if out := pair.GetAmountOut(less, pair.Token0.Address); out.Cmp(amount) >= 0 {
+			t.Errorf("GetAmountIn(%s) = %s overpays: %s buys %s", amount, amountIn, less, out)
+		}
+	}
+
+	if got := pair.GetAmountIn(ether(100_000_000), pair.Token0.Address); got != nil {
+		t.Errorf("GetAmountIn() beyond the window = %s, want nil", got)
+	}
+}
+
+func TestConstantProductGetAmountIn(t *testing.T) {
+	pair := &Pair{
+		Token0:   Token{Address: common.HexToAddress("0x01")},
+		Token1:   Token{Address: common.HexToAddress("0x02")},
+		Reserve0: ether(1000),
+		Reserve1: ether(2000),
+		Fee:      30,
+	}
+	amountIn := pair.GetAmountIn(ether(10), pair.Token0.Address)
+	if out := pair.GetAmountOut(amountIn, pair.Token0.Address); out.Cmp(ether(10)) < 0 {
+		t.Errorf("GetAmountOut(GetAmountIn(10e18)) = %s, want at least 10e18", out)
+	}
+	if got := pair.GetAmountIn(ether(2000), pair.Token0.Address); got != nil {
+		t.Errorf("GetAmountIn(reserve) = %s, want nil", got)
+	}
+}
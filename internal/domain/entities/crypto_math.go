@@ -0,0 +1,247 @@
+package entities
+
+import (
+	"math/big"
+	"sort"
+)
+
+// cryptoAMultiplier is the fixed-point precision of CryptoCurve.A
+const cryptoAMultiplier = 10000
+
+var (
+	cryptoFeeDenominator = big.NewInt(1e10)
+	cryptoConvergence    = big.NewInt(1e14)
+)
+
+// CryptoCurve prices a pair with Curve's CryptoSwap math, used by the v2
+// crypto pools such as tricrypto. Unlike StableSwap the curve is centred on
+// PriceScale, which the pool moves towards its internal EMA oracle, and the
+// fee grows from MidFee to OutFee as the balances leave that centre. Like
+// StableCurve it needs every coin in the pool; Index0 and Index1 locate
+// Token0 and Token1.
+type CryptoCurve struct {
+	A          *big.Int   `json:"a"`     // A × N^N × 10000, as the pool reports it
+	Gamma      *big.Int   `json:"gamma"` // 18 decimals
+	D          *big.Int   `json:"d"`     // The invariant, as the pool last stored it
+	Balances   []*big.Int `json:"balances"`
+	Precisions []*big.Int `json:"precisions"` // Upscale each coin's balance to 18 decimals
+	PriceScale []*big.Int `json:"priceScale"` // Coins 1..N-1 in coin 0, 18 decimals
+	MidFee     *big.Int   `json:"midFee"`     // 1e10 = 100%
+	OutFee     *big.Int   `json:"outFee"`
+	FeeGamma   *big.Int   `json:"feeGamma"` // 18 decimals
+	Index0     int        `json:"index0"`
+	Index1     int        `json:"index1"`
+}
+
+// amountOut mirrors the pool's get_dy, charging the dynamic fee at the
+// balances after the swap
+func (c *CryptoCurve) amountOut(amountIn *big.Int, zeroForOne bool) *big.Int {
+	i, j := c.Index0, c.Index1
+	if !zeroForOne {
+		i, j = j, i
+	}
+	n := len(c.Balances)
+	if !c.valid() || i < 0 || j < 0 || i >= n || j >= n || i == j {
+		return big.NewInt(0)
+	}
+
+	xp := make([]*big.Int, n)
+	for k, balance := range c.Balances {
+		x := new(big.Int).Set(balance)
+		if k == i {
+			x.Add(x, amountIn)
+		}
+		xp[k] = c.scale(x, k)
+	}
+
+	y := cryptoNewtonY(c.A, c.Gamma, xp, c.D, j)
+	if y == nil {
+		return big.NewInt(0)
+	}
+	dy := new(big.Int).Sub(xp[j], y)
+	dy.Sub(dy, big.NewInt(1))
+	if dy.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	xp[j] = y
+
+	if j > 0 {
+		dy = divDown(dy, c.PriceScale[j-1])
+	}
+	dy.Div(dy, c.Precisions[j])
+	fee := new(big.Int).Mul(c.fee(xp), dy)
+	fee.Div(fee, cryptoFeeDenominator)
+	return dy.Sub(dy, fee)
+}
+
+func (c *CryptoCurve) valid() bool {
+	n := len(c.Balances)
+	if n < 2 || c.A == nil || c.A.Sign() <= 0 || c.Gamma == nil || c.Gamma.Sign() <= 0 || c.D == nil || c.D.Sign() <= 0 ||
+		c.MidFee == nil || c.OutFee == nil || c.FeeGamma == nil ||
+		len(c.Precisions) != n || len(c.PriceScale) != n-1 {
+		return false
+	}
+	for k := 0; k < n; k++ {
+		if c.Balances[k] == nil || c.Precisions[k] == nil || c.Precisions[k].Sign() <= 0 {
+			return false
+		}
+		if k > 0 && (c.PriceScale[k-1] == nil || c.PriceScale[k-1].Sign() <= 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// scale converts coin k's amount to the pool's internal units: 18 decimals,
+// priced in coin 0
+func (c *CryptoCurve) scale(amount *big.Int, k int) *big.Int {
+	x := new(big.Int).Mul(amount, c.Precisions[k])
+	if k > 0 {
+		x = mulDown(x, c.PriceScale[k-1])
+	}
+	return x
+}
+
+// fee is the dynamic fee at balances xp, in 1e10ths: MidFee when they are
+// balanced, sliding towards OutFee as they drift apart
+func (c *CryptoCurve) fee(xp []*big.Int) *big.Int {
+	n := big.NewInt(int64(len(xp)))
+	sum := new(big.Int)
+	for _, x := range xp {
+		sum.Add(sum, x)
+	}
+	if sum.Sign() == 0 {
+		return new(big.Int).Set(c.OutFee)
+	}
+	k := new(big.Int).Set(fixedOne)
+	for _, x := range xp {
+		k.Mul(k, n)
+		k.Mul(k, x)
+		k.Div(k, sum)
+	}
+	if c.FeeGamma.Sign() > 0 {
+		den := new(big.Int).Add(c.FeeGamma, fixedOne)
+		den.Sub(den, k)
+		k = divDown(c.FeeGamma, den)
+	}
+	if k.Cmp(fixedOne) > 0 {
+		k.Set(fixedOne)
+	}
+
+	fee := new(big.Int).Mul(c.MidFee, k)
+	fee.Add(fee, new(big.Int).Mul(c.OutFee, new(big.Int).Sub(fixedOne, k)))
+	return fee.Div(fee, fixedOne)
+}
+
+// cryptoNewtonY mirrors the pools' newton_y: the balance of coin i that
+// keeps invariant d with the other balances in xp. It returns nil when the
+// iteration doesn't converge or lands outside the pool's safe range.
+func cryptoNewtonY(ann, gamma *big.Int, xp []*big.Int, d *big.Int, i int) *big.Int {
+	n := big.NewInt(int64(len(xp)))
+	sorted := make([]*big.Int, len(xp))
+	copy(sorted, xp)
+	sorted[i] = big.NewInt(0)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].Cmp(sorted[b]) > 0 })
+	if sorted[len(sorted)-2].Sign() == 0 {
+		return nil
+	}
+
+	limit := new(big.Int).Div(sorted[0], cryptoConvergence)
+	if l := new(big.Int).Div(d, cryptoConvergence); l.Cmp(limit) > 0 {
+		limit = l
+	}
+	if limit.Cmp(big.NewInt(100)) < 0 {
+		limit = big.NewInt(100)
+	}
+
+	// Smallest balances first, to keep precision
+	y := new(big.Int).Div(d, n)
+	s := new(big.Int)
+	for j := 2; j <= len(xp); j++ {
+		x := sorted[len(xp)-j]
+		y.Mul(y, d)
+		y.Div(y, new(big.Int).Mul(x, n))
+		s.Add(s, x)
+	}
+	k0i := new(big.Int).Set(fixedOne)
+	for j := 0; j < len(xp)-1; j++ {
+		k0i.Mul(k0i, sorted[j])
+		k0i.Mul(k0i, n)
+		k0i.Div(k0i, d)
+	}
+
+	gamma1 := new(big.Int).Add(gamma, fixedOne)
+	for iter := 0; iter < 255; iter++ {
+		previous := y
+		if y.Sign() == 0 {
+			return nil
+		}
+
+		k0 := new(big.Int).Mul(k0i, y)
+		k0.Mul(k0, n)
+		k0.Div(k0, d)
+		if k0.Sign() == 0 {
+			return nil
+		}
+		sum := new(big.Int).Add(s, y)
+
+		g1k0 := new(big.Int).Sub(gamma1, k0)
+		g1k0.Abs(g1k0)
+		g1k0.Add(g1k0, big.NewInt(1))
+
+		// D / (A·N^N) · g1k0² / gamma²
+		mul1 := new(big.Int).Mul(fixedOne, d)
+		mul1.Div(mul1, gamma)
+		mul1.Mul(mul1, g1k0)
+		mul1.Div(mul1, gamma)
+		mul1.Mul(mul1, g1k0)
+		mul1.Mul(mul1, big.NewInt(cryptoAMultiplier))
+		mul1.Div(mul1, ann)
+
+		// 1 + 2·K0 / g1k0
+		mul2 := new(big.Int).Mul(big.NewInt(2e18), k0)
+		mul2.Div(mul2, g1k0)
+		mul2.Add(mul2, fixedOne)
+
+		yfprime := new(big.Int).Mul(fixedOne, y)
+		yfprime.Add(yfprime, new(big.Int).Mul(sum, mul2))
+		yfprime.Add(yfprime, mul1)
+		dyfprime := new(big.Int).Mul(d, mul2)
+		if yfprime.Cmp(dyfprime) < 0 {
+			y = new(big.Int).Rsh(previous, 1)
+			continue
+		}
+		yfprime.Sub(yfprime, dyfprime)
+		fprime := new(big.Int).Div(yfprime, y)
+		if fprime.Sign() == 0 {
+			return nil
+		}
+
+		yMinus := new(big.Int).Div(mul1, fprime)
+		yPlus := new(big.Int).Add(yfprime, new(big.Int).Mul(fixedOne, d))
+		yPlus.Div(yPlus, fprime)
+		yPlus.Add(yPlus, new(big.Int).Div(new(big.Int).Mul(yMinus, fixedOne), k0))
+		yMinus.Add(yMinus, new(big.Int).Div(new(big.Int).Mul(fixedOne, sum), fprime))
+		if yPlus.Cmp(yMinus) < 0 {
+			y = new(big.Int).Rsh(previous, 1)
+		} else {
+			y = yPlus.Sub(yPlus, yMinus)
+		}
+
+		diff := new(big.Int).Sub(y, previous)
+		diff.Abs(diff)
+		tolerance := new(big.Int).Div(y, cryptoConvergence)
+		if tolerance.Cmp(limit) < 0 {
+			tolerance = limit
+		}
+		if diff.Cmp(tolerance) < 0 {
+			// The pools revert outside 1% to 100x of D / N
+			frac := divDown(y, d)
+			if frac.Cmp(big.NewInt(1e16-1)) <= 0 || frac.Cmp(new(big.Int).Mul(big.NewInt(100), fixedOne)) > 0 {
+				return nil
+			}
+			return y
+		}
+	}
+	return nil
+}
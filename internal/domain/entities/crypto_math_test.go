@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+)
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// tricryptoPair is a balanced USDT/WBTC/WETH pool with tricrypto2's
+// parameters, $30M a side at BTC $30,000 and ETH $2,000, paired WETH/USDT
+func tricryptoPair() *Pair {
+	return &Pair{
+		Token0: WETH,
+		Token1: USDT,
+		Crypto: &CryptoCurve{
+			A:     big.NewInt(1707629),
+			Gamma: big.NewInt(11809167828997),
+			D:     new(big.Int).Mul(big.NewInt(90_000_000), fixedOne),
+			Balances: []*big.Int{
+				new(big.Int).Mul(big.NewInt(30_000_000), pow10(6)),
+				new(big.Int).Mul(big.NewInt(1000), pow10(8)),
+				ether(15_000),
+			},
+			Precisions: []*big.Int{pow10(12), pow10(10), big.NewInt(1)},
+			PriceScale: []*big.Int{ether(30_000), ether(2_000)},
+			MidFee:     big.NewInt(3_000_000),
+			OutFee:     big.NewInt(30_000_000),
+			FeeGamma:   big.NewInt(500_000_000_000_000),
+			Index0:     2,
+			Index1:     0,
+		},
+	}
+}
+
+func TestCryptoCurveGetAmountOut(t *testing.T) {
+	pair := tricryptoPair()
+
+	// 1 ETH at the pool's centre: $2,000 less the 0.03% mid fee
+	out := pair.GetAmountOut(ether(1), WETH.Address)
+	low, high := big.NewInt(1_999_00*1e4), big.NewInt(1_999_50*1e4)
+	if out.Cmp(low) < 0 || out.Cmp(high) > 0 {
+		t.Errorf("GetAmountOut(1 WETH) = %s, want between %s and %s", out, low, high)
+	}
+	back := pair.GetAmountOut(big.NewInt(2_000*1e6), USDT.Address)
+	if back.Cmp(ether(1)) >= 0 || back.Cmp(new(big.Int).Div(ether(999), big.NewInt(1000))) < 0 {
+		t.Errorf("GetAmountOut(2000 USDT) = %s, want just under 1 WETH", back)
+	}
+
+	// 1,500 ETH, 10% of the side, pays a far worse rate: impact and the
+	// fee rising towards its out fee
+	large := pair.GetAmountOut(ether(1_500), WETH.Address)
+	perEth := new(big.Int).Div(large, big.NewInt(1_500))
+	if perEth.Cmp(big.NewInt(1_990*1e6)) >= 0 || perEth.Cmp(big.NewInt(1_700*1e6)) <= 0 {
+		t.Errorf("GetAmountOut(1500 WETH) = %s USDT per WETH, want between 1700 and 1990", perEth)
+	}
+	if fee, mid := pair.Crypto.fee([]*big.Int{ether(30_000_000), ether(30_000_000), ether(33_000_000)}), pair.Crypto.MidFee; fee.Cmp(mid) <= 0 {
+		t.Errorf("fee off-centre = %s, want above the mid fee %s", fee, mid)
+	}
+
+	if pair.GetAmountIn(ether(1), WETH.Address) != nil {
+		t.Error("GetAmountIn() on a crypto pair should be unsupported")
+	}
+	broken := tricryptoPair()
+	broken.Crypto.PriceScale = broken.Crypto.PriceScale[:1]
+	if out := broken.GetAmountOut(ether(1), WETH.Address); out.Sign() != 0 {
+		t.Errorf("GetAmountOut() with a missing price scale = %s, want 0", out)
+	}
+}
@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+)
+
+// ExecutionPlan splits a trade too large for the pools into slices to send
+// one interval apart, for callers to TWAP by hand. Each slice is quoted
+// against the current pools, on the premise that arbitrage restores the
+// price between slices.
+type ExecutionPlan struct {
+	AmountIn          *big.Int      `json:"amountIn"`
+	ExpectedAmountOut *big.Int      `json:"expectedAmountOut"` // Sum over the slices
+	SingleAmountOut   *big.Int      `json:"singleAmountOut"`   // The whole trade at once
+	ImprovementBps    int64         `json:"improvementBps"`    // Of ExpectedAmountOut over SingleAmountOut
+	TargetImpactBps   int64         `json:"targetImpactBps"`   // Price impact each slice is sized to stay under
+	Interval          time.Duration `json:"interval"`
+	Slices            []PlanSlice   `json:"slices"`
+}
+
+// PlanSlice is one trade of an execution plan
+type PlanSlice struct {
+	AmountIn    *big.Int  `json:"amountIn"`
+	AmountOut   *big.Int  `json:"amountOut"`
+	PriceImpact *big.Int  `json:"priceImpact"` // Basis points
+	ExecuteAt   time.Time `json:"executeAt"`
+}
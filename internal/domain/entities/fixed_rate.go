@@ -0,0 +1,84 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FixedRate prices a converter that swaps at a set rate rather than along
+// a curve, such as Maker's PSM. One unit of Gem converts to To18 units of
+// the other token (1e12 for USDC into DAI). Selling Gem is charged Tin out
+// of the output, and buying it Tout on top of the input, both as
+// 18-decimal fractions; a fee of 100% or more, such as the PSM's HALTED
+// value, closes that direction. The pair's Reserve0 and Reserve1 hold what
+// the converter can pay out of each token.
+type FixedRate struct {
+	Gem  common.Address `json:"gem"`
+	To18 *big.Int       `json:"to18"`
+	Tin  *big.Int       `json:"tin"`
+	Tout *big.Int       `json:"tout"`
+}
+
+// amountOut mirrors the PSM's sellGem and buyGem, rounding down. It is
+// zero when the direction is halted or the converter can't pay it out.
+func (f *FixedRate) amountOut(amountIn *big.Int, tokenIn common.Address, reserveOut *big.Int) *big.Int {
+	if !f.valid() {
+		return big.NewInt(0)
+	}
+
+	var out *big.Int
+	if tokenIn == f.Gem {
+		if f.Tin.Cmp(fixedOne) >= 0 {
+			return big.NewInt(0)
+		}
+		out = new(big.Int).Mul(amountIn, f.To18)
+		out.Sub(out, mulDown(out, f.Tin))
+	} else {
+		if f.Tout.Cmp(fixedOne) >= 0 {
+			return big.NewInt(0)
+		}
+		out = new(big.Int).Mul(amountIn, fixedOne)
+		out.Div(out, new(big.Int).Add(fixedOne, f.Tout))
+		out.Div(out, f.To18)
+	}
+
+	if reserveOut == nil || out.Cmp(reserveOut) > 0 {
+		return big.NewInt(0)
+	}
+	return out
+}
+
+// amountIn rounds up, so converting it yields at least amountOut. It is nil
+// when the direction is halted or the converter can't pay amountOut.
+func (f *FixedRate) amountIn(amountOut *big.Int, tokenIn common.Address, reserveOut *big.Int) *big.Int {
+	if !f.valid() || reserveOut == nil || amountOut.Cmp(reserveOut) > 0 {
+		return nil
+	}
+
+	if tokenIn == f.Gem {
+		if f.Tin.Cmp(fixedOne) >= 0 {
+			return nil
+		}
+		// Enough Gem that its value less the fee covers amountOut
+		kept := new(big.Int).Sub(fixedOne, f.Tin)
+		value := new(big.Int).Mul(amountOut, fixedOne)
+		value.Add(value, kept)
+		value.Sub(value, big.NewInt(1))
+		value.Div(value, kept)
+		value.Add(value, f.To18)
+		value.Sub(value, big.NewInt(1))
+		return value.Div(value, f.To18)
+	}
+
+	if f.Tout.Cmp(fixedOne) >= 0 {
+		return nil
+	}
+	// The PSM charges exactly the Gem's value plus the fee on it
+	value := new(big.Int).Mul(amountOut, f.To18)
+	return value.Add(value, mulDown(value, f.Tout))
+}
+
+func (f *FixedRate) valid() bool {
+	return f.To18 != nil && f.To18.Sign() > 0 && f.Tin != nil && f.Tin.Sign() >= 0 && f.Tout != nil && f.Tout.Sign() >= 0
+}
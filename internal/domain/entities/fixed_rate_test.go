@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFixedRate(t *testing.T) {
+	// Maker's USDC PSM charging 0.1% in and 0.2% out, holding 1,000 USDC
+	// and 1,000 DAI to pay out
+	psm := func(tin, tout *big.Int) *Pair {
+		return &Pair{
+			Token0: DAI, Token1: USDC,
+			Reserve0: ether(1_000), Reserve1: big.NewInt(1_000e6),
+			Fixed: &FixedRate{Gem: USDC.Address, To18: pow10(12), Tin: tin, Tout: tout},
+		}
+	}
+	pair := psm(big.NewInt(1e15), big.NewInt(2e15))
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	tests := []struct {
+		name    string
+		pair    *Pair
+		tokenIn Token
+		amount  *big.Int
+		wantOut *big.Int
+	}{
+		{"sell gem", pair, USDC, big.NewInt(100e6), ether(999).Div(ether(999), big.NewInt(10))},
+		{"buy gem", pair, DAI, ether(1002).Div(ether(1002), big.NewInt(10)), big.NewInt(100e6)},
+		{"buy gem rounds down", pair, DAI, ether(1), big.NewInt(998_003)},
+		{"more than the pocket holds", pair, DAI, ether(1_100), big.NewInt(0)},
+		{"more than the psm holds", pair, USDC, big.NewInt(1_100e6), big.NewInt(0)},
+		{"halted", psm(big.NewInt(0), maxUint256), DAI, ether(1), big.NewInt(0)},
+		{"free", psm(big.NewInt(0), big.NewInt(0)), USDC, big.NewInt(1), pow10(12)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.pair.GetAmountOut(tt.amount, tt.tokenIn.Address)
+			if out.Cmp(tt.wantOut) != 0 {
+				t.Fatalf("GetAmountOut() = %s, want %s", out, tt.wantOut)
+			}
+			if out.Sign() == 0 {
+				return
+			}
+			in := tt.pair.GetAmountIn(out, tt.tokenIn.Address)
+			if in == nil || in.Cmp(tt.amount) > 0 {
+				t.Fatalf("GetAmountIn(%s) = %v, want at most %s", out, in, tt.amount)
+			}
+			if got := tt.pair.GetAmountOut(in, tt.tokenIn.Address); got.Cmp(out) < 0 {
+				t.Errorf("GetAmountOut(GetAmountIn(%s)) = %s, short of it", out, got)
+			}
+		})
+	}
+
+	if in := pair.GetAmountIn(big.NewInt(1_001e6), DAI.Address); in != nil {
+		t.Errorf("GetAmountIn() beyond the pocket = %s, want nil", in)
+	}
+	if in := psm(big.NewInt(0), maxUint256).GetAmountIn(big.NewInt(1e6), DAI.Address); in != nil {
+		t.Errorf("GetAmountIn() while halted = %s, want nil", in)
+	}
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CycleHop is one leg of an arbitrage cycle through a specific pool
+type CycleHop struct {
+	DEX       DEXType        `json:"dex"`
+	Pool      common.Address `json:"pool"`
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	Fee       uint64         `json:"fee,omitempty"` // V3 fee tier in hundredths of a bip
+	AmountOut *big.Int       `json:"amountOut"`     // Expected output of the leg
+}
+
+// ArbitrageCycle starts and ends in the same token; AmountIn of the first
+// leg's tokenIn goes in and the last leg's AmountOut comes back
+type ArbitrageCycle struct {
+	AmountIn *big.Int   `json:"amountIn"`
+	Hops     []CycleHop `json:"hops"`
+}
+
+// FlashSwap borrows the first leg's output from its pool, runs the remaining
+// legs in the receiver's callback and repays the pool from the proceeds.
+// Transaction must be sent by the receiver contract for V3 pools, which call
+// back msg.sender.
+type FlashSwap struct {
+	Transaction    *SwapTransaction `json:"transaction"`
+	Callback       string           `json:"callback"`     // Receiver function the pool calls
+	CallbackData   []byte           `json:"callbackData"` // Payload passed through to the callback
+	RepayToken     common.Address   `json:"repayToken"`
+	RepayAmount    *big.Int         `json:"repayAmount"`
+	ExpectedProfit *big.Int         `json:"expectedProfit"`
+}
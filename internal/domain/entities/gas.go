@@ -0,0 +1,12 @@
+package entities
+
+// GasStats is the gas a venue's swaps use per hop. MedianPerHop is learned
+// from the receipts of recent swaps and replaces DefaultPerHop in gas
+// estimates once it is set.
+type GasStats struct {
+	DEX           DEXType `json:"dex"`
+	DefaultPerHop uint64  `json:"defaultPerHop"`
+	MedianPerHop  uint64  `json:"medianPerHop,omitempty"`
+	Samples       int     `json:"samples"`
+	UpdatedAt     int64   `json:"updatedAt,omitempty"` // Unix seconds
+}
@@ -0,0 +1,52 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type IntentStatus string
+
+const (
+	IntentOpen    IntentStatus = "open"
+	IntentExpired IntentStatus = "expired"
+)
+
+// Intent is a signed order to sell SellAmount of SellToken for at least
+// MinBuyAmount of BuyToken before Deadline. ID is the EIP-712 digest.
+type Intent struct {
+	ID           common.Hash    `json:"id"`
+	Owner        common.Address `json:"owner"`
+	SellToken    Token          `json:"sellToken"`
+	BuyToken     Token          `json:"buyToken"`
+	SellAmount   *big.Int       `json:"sellAmount"`
+	MinBuyAmount *big.Int       `json:"minBuyAmount"`
+	Deadline     uint64         `json:"deadline"` // Unix seconds
+	Nonce        *big.Int       `json:"nonce"`
+	Signature    []byte         `json:"signature"`
+	Status       IntentStatus   `json:"status"`
+	ReceivedAt   int64          `json:"receivedAt"`
+}
+
+// IntentFill is one intent's share of a settlement. MatchedBuyAmount comes
+// from opposing intents, AMMBuyAmount from the AMM remainder swap.
+type IntentFill struct {
+	IntentID         common.Hash    `json:"intentId"`
+	Owner            common.Address `json:"owner"`
+	SellAmount       *big.Int       `json:"sellAmount"`
+	BuyAmount        *big.Int       `json:"buyAmount"`
+	MatchedBuyAmount *big.Int       `json:"matchedBuyAmount"`
+	AMMBuyAmount     *big.Int       `json:"ammBuyAmount"`
+}
+
+// Settlement is a proposed batch for one token pair: opposing intents trade
+// directly (coincidence of wants) and the net imbalance goes through AMMSwap
+type Settlement struct {
+	TokenA   Token        `json:"tokenA"`
+	TokenB   Token        `json:"tokenB"`
+	MatchedA *big.Int     `json:"matchedA"` // TokenA exchanged peer to peer
+	MatchedB *big.Int     `json:"matchedB"` // TokenB exchanged peer to peer
+	AMMSwap  *Quote       `json:"ammSwap,omitempty"`
+	Fills    []IntentFill `json:"fills"`
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// LiquiditySnapshot is one pool's reserves at a moment, kept so routing
+// strategies can be backtested against past liquidity
+type LiquiditySnapshot struct {
+	Time     time.Time      `json:"time"`
+	Block    uint64         `json:"block,omitempty"` // 0 when the venue doesn't report it
+	DEX      DEXType        `json:"dex"`
+	Pool     common.Address `json:"pool"`
+	Token0   Token          `json:"token0"`
+	Token1   Token          `json:"token1"`
+	Reserve0 *big.Int       `json:"reserve0"` // Raw units; virtual reserves for concentrated pools
+	Reserve1 *big.Int       `json:"reserve1"`
+	Fee      uint64         `json:"fee"` // As the pool's Pair reports it
+}
+
+// NewLiquiditySnapshot records pair's reserves as read at t
+func NewLiquiditySnapshot(pair *Pair, t time.Time) LiquiditySnapshot {
+	return LiquiditySnapshot{
+		Time:     t,
+		Block:    pair.BlockNumber,
+		DEX:      pair.DEX,
+		Pool:     pair.Address,
+		Token0:   pair.Token0,
+		Token1:   pair.Token1,
+		Reserve0: pair.Reserve0,
+		Reserve1: pair.Reserve1,
+		Fee:      pair.Fee,
+	}
+}
+
+// LiquiditySnapshotFilter selects snapshots. Empty fields match anything;
+// TokenA and TokenB match a pool holding both, in either order.
+type LiquiditySnapshotFilter struct {
+	TokenA common.Address
+	TokenB common.Address
+	DEX    DEXType
+	From   time.Time
+	To     time.Time
+}
+
+// Matches reports whether snapshot passes every set field of f
+func (f LiquiditySnapshotFilter) Matches(snapshot LiquiditySnapshot) bool {
+	holds := func(token common.Address) bool {
+		return token == (common.Address{}) || snapshot.Token0.Address == token || snapshot.Token1.Address == token
+	}
+	switch {
+	case !holds(f.TokenA) || !holds(f.TokenB):
+		return false
+	case f.DEX != "" && snapshot.DEX != f.DEX:
+		return false
+	case !f.From.IsZero() && snapshot.Time.Before(f.From):
+		return false
+	case !f.To.IsZero() && snapshot.Time.After(f.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package entities
+
+import "github.com/ethereum/go-ethereum/common"
+
+// ChainBSC is BNB Smart Chain, which has a wrapped native token but no
+// bridge adapter
+const ChainBSC uint64 = 56
+
+// NativeTokenAddress stands for a chain's gas token where a token address
+// is expected, the 0xEeee... convention wallets and other aggregators use
+var NativeTokenAddress = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// NativeCurrency is a chain's gas token and the ERC-20 that wraps it. Pools
+// only hold the wrapper, so native legs are routed through it.
+type NativeCurrency struct {
+	Symbol  string
+	Name    string
+	Wrapped Token
+}
+
+// Token returns the gas token as a token at NativeTokenAddress
+func (c NativeCurrency) Token() Token {
+	return Token{Address: NativeTokenAddress, Symbol: c.Symbol, Name: c.Name, Decimals: 18}
+}
+
+// NativeCurrencies lists each chain's gas token and its wrapper
+var NativeCurrencies = map[uint64]NativeCurrency{
+	ChainEthereum: {Symbol: "ETH", Name: "Ether", Wrapped: WETH},
+	ChainOptimism: {Symbol: "ETH", Name: "Ether", Wrapped: wrappedEther("0x4200000000000000000000000000000000000006")},
+	ChainBase:     {Symbol: "ETH", Name: "Ether", Wrapped: wrappedEther("0x4200000000000000000000000000000000000006")},
+	ChainArbitrum: {Symbol: "ETH", Name: "Ether", Wrapped: wrappedEther("0x82aF49447D8a07e3bd95BD0d56f35241523fBab1")},
+	// MATIC was renamed POL; the wrapper contract still reports WMATIC
+	ChainPolygon: {Symbol: "POL", Name: "POL", Wrapped: Token{
+		Address:  common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270"),
+		Symbol:   "WMATIC",
+		Name:     "Wrapped Matic",
+		Decimals: 18,
+	}},
+	ChainBSC: {Symbol: "BNB", Name: "BNB", Wrapped: Token{
+		Address:  common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c"),
+		Symbol:   "WBNB",
+		Name:     "Wrapped BNB",
+		Decimals: 18,
+	}},
+}
+
+// IsNative reports whether t is the gas token sentinel
+func (t Token) IsNative() bool {
+	return t.Address == NativeTokenAddress
+}
+
+// wrappedEther is WETH as deployed on a rollup
+func wrappedEther(addr string) Token {
+	return Token{Address: common.HexToAddress(addr), Symbol: WETH.Symbol, Name: WETH.Name, Decimals: WETH.Decimals}
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type OrderType string
+
+const (
+	OrderDutch OrderType = "dutch"
+)
+
+type OrderStatus string
+
+const (
+	OrderOpen     OrderStatus = "open"
+	OrderFillable OrderStatus = "fillable" // The AMM route currently meets the limit
+	OrderExpired  OrderStatus = "expired"
+)
+
+// Order is a signed swap order tracked by the order service. For Dutch
+// orders the acceptable output decays linearly from StartAmountOut at
+// StartTime to EndAmountOut at EndTime. ID is the EIP-712 digest.
+type Order struct {
+	ID             common.Hash    `json:"id"`
+	Type           OrderType      `json:"type"`
+	Owner          common.Address `json:"owner"`
+	TokenIn        Token          `json:"tokenIn"`
+	TokenOut       Token          `json:"tokenOut"`
+	AmountIn       *big.Int       `json:"amountIn"`
+	StartAmountOut *big.Int       `json:"startAmountOut"`
+	EndAmountOut   *big.Int       `json:"endAmountOut"`
+	StartTime      uint64         `json:"startTime"` // Unix seconds
+	EndTime        uint64         `json:"endTime"`
+	Nonce          *big.Int       `json:"nonce"`
+	Signature      []byte         `json:"signature"`
+	Status         OrderStatus    `json:"status"`
+	CheckedAt      int64          `json:"checkedAt,omitempty"`
+	Fill           *OrderFill     `json:"fill,omitempty"`
+}
+
+// LimitAt returns the minimum acceptable output at unix time now
+func (o *Order) LimitAt(now uint64) *big.Int {
+	switch {
+	case now <= o.StartTime:
+		return new(big.Int).Set(o.StartAmountOut)
+	case now >= o.EndTime:
+		return new(big.Int).Set(o.EndAmountOut)
+	}
+
+	// start - (start - end) * elapsed / duration
+	decay := new(big.Int).Sub(o.StartAmountOut, o.EndAmountOut)
+	decay.Mul(decay, new(big.Int).SetUint64(now-o.StartTime))
+	decay.Div(decay, new(big.Int).SetUint64(o.EndTime-o.StartTime))
+	return decay.Sub(o.StartAmountOut, decay)
+}
+
+// OrderFill records the AMM route that crossed an order's limit. The
+// transaction calls the order settlement contract, which consumes the
+// signed order, pays Owner and reverts below the limit.
+type OrderFill struct {
+	Quote          *Quote           `json:"quote"`
+	LimitAmountOut *big.Int         `json:"limitAmountOut"`
+	Transaction    *SwapTransaction `json:"transaction,omitempty"`
+	DetectedAt     int64            `json:"detectedAt"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestOrderLimitAt(t *testing.T) {
+	order := &Order{
+		StartAmountOut: big.NewInt(1000),
+		EndAmountOut:   big.NewInt(800),
+		StartTime:      100,
+		EndTime:        200,
+	}
+
+	tests := []struct {
+		name string
+		now  uint64
+		want int64
+	}{
+		{"before start", 50, 1000},
+		{"at start", 100, 1000},
+		{"quarter way", 125, 950},
+		{"half way", 150, 900},
+		{"at end", 200, 800},
+		{"after end", 300, 800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := order.LimitAt(tt.now); got.Int64() != tt.want {
+				t.Errorf("LimitAt(%d) = %s, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
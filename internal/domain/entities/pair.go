@@ -15,6 +15,10 @@ const (
 	DEXSushiswap DEXType = "sushiswap"
 	DEXCurve     DEXType = "curve"
 	DEXBalancer  DEXType = "balancer"
+	DEXLido      DEXType = "lido"
+	DEXWrapper   DEXType = "wrapper" // WETH and ERC-4626 vaults, converted by their contracts
+	DEXRFQ       DEXType = "rfq"     // Firm quotes from professional market makers
+	DEXMakerPSM  DEXType = "maker_psm"
 )
 
 // Pair represents a liquidity pair on a DEX
@@ -27,6 +31,32 @@ type Pair struct {
 	DEX       DEXType        `json:"dex"`
 	Fee       uint64         `json:"fee"` // Fee in basis points (e.g., 30 = 0.3%)
 	UpdatedAt int64          `json:"updatedAt"`
+	// BlockNumber is the block the reserves were read at; 0 when unknown.
+	// Adapters read the head before the pool state, so it is a lower bound.
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	// Factory deployed the pool; zero for venues that list their pools
+	Factory common.Address `json:"factory,omitzero"`
+	// Verification is set on quotes' hops when pools are verified
+	Verification *PoolVerification `json:"verification,omitempty"`
+	// TVL and 24h volume from the pool's subgraph, 18 decimals; nil when unknown
+	TVLUSD       *big.Int `json:"tvlUsd,omitempty"`
+	Volume24hUSD *big.Int `json:"volume24hUsd,omitempty"`
+	// Stable prices the pair with StableSwap math instead of constant product
+	Stable *StableCurve `json:"stable,omitempty"`
+	// Crypto prices the pair with Curve CryptoSwap math, fee included
+	Crypto *CryptoCurve `json:"crypto,omitempty"`
+	// Concentrated simulates Uniswap V3 swaps across ticks; Reserve0 and
+	// Reserve1 then hold the virtual reserves at the current price
+	Concentrated *ConcentratedLiquidity `json:"concentrated,omitempty"`
+	// Wrap converts at its contract's rate without slippage; Reserve0 and
+	// Reserve1 then hold deep virtual reserves at that rate
+	Wrap *WrapRate `json:"wrap,omitempty"`
+	// Fixed converts at a set rate less a fee per direction, up to what
+	// the converter holds in Reserve0 and Reserve1
+	Fixed *FixedRate `json:"fixed,omitempty"`
+	// Curve and Balancer pairs carry what their swap calls need
+	CurveSwap    *CurveSwap    `json:"curveSwap,omitempty"`
+	BalancerSwap *BalancerSwap `json:"balancerSwap,omitempty"`
 }
 
 // GetSpotPrice calculates the spot price of token0 in terms of token1
@@ -40,11 +70,46 @@ func (p *Pair) GetSpotPrice() *big.Int {
 	return new(big.Int).Div(numerator, p.Reserve0)
 }
 
+// LiquidityScore rates how easily the pool absorbs a trade in basis points:
+// 10000 minus the trade's share of the input reserve, floored at zero
+func (p *Pair) LiquidityScore(amountIn *big.Int, tokenIn common.Address) uint64 {
+	reserveIn := p.Reserve1
+	if tokenIn == p.Token0.Address {
+		reserveIn = p.Reserve0
+	}
+	if amountIn == nil || reserveIn == nil || reserveIn.Sign() <= 0 {
+		return 0
+	}
+
+	share := new(big.Int).Mul(amountIn, big.NewInt(10000))
+	share.Div(share, reserveIn)
+	if share.Cmp(big.NewInt(10000)) >= 0 {
+		return 0
+	}
+	return 10000 - share.Uint64()
+}
+
 func (p *Pair) GetAmountOut(amountIn *big.Int, tokenIn common.Address) *big.Int {
 	if amountIn == nil || amountIn.Sign() <= 0 {
 		return big.NewInt(0)
 	}
 
+	if p.Stable != nil {
+		return p.Stable.amountOut(amountIn, tokenIn == p.Token0.Address, p.Fee)
+	}
+	if p.Crypto != nil {
+		return p.Crypto.amountOut(amountIn, tokenIn == p.Token0.Address)
+	}
+	if p.Concentrated != nil {
+		return p.Concentrated.amountOut(amountIn, tokenIn == p.Token0.Address, p.Fee)
+	}
+	if p.Wrap != nil {
+		return p.Wrap.amountOut(amountIn, tokenIn)
+	}
+	if p.Fixed != nil {
+		return p.Fixed.amountOut(amountIn, tokenIn, p.reserveOut(tokenIn))
+	}
+
 	var reserveIn, reserveOut *big.Int
 	if tokenIn == p.Token0.Address {
 		reserveIn = p.Reserve0
@@ -71,3 +136,53 @@ func (p *Pair) GetAmountOut(amountIn *big.Int, tokenIn common.Address) *big.Int
 
 	return new(big.Int).Div(numerator, denominator)
 }
+
+// GetAmountIn returns the input needed to receive amountOut of the other
+// token, or nil when the pool can't supply it. Stable and crypto pairs
+// aren't supported and return nil.
+func (p *Pair) GetAmountIn(amountOut *big.Int, tokenIn common.Address) *big.Int {
+	if amountOut == nil || amountOut.Sign() <= 0 || p.Stable != nil || p.Crypto != nil {
+		return nil
+	}
+	if p.Concentrated != nil {
+		return p.Concentrated.amountIn(amountOut, tokenIn == p.Token0.Address, p.Fee)
+	}
+	if p.Wrap != nil {
+		return p.Wrap.amountIn(amountOut, tokenIn)
+	}
+	if p.Fixed != nil {
+		return p.Fixed.amountIn(amountOut, tokenIn, p.reserveOut(tokenIn))
+	}
+
+	reserveIn, reserveOut := p.Reserve0, p.Reserve1
+	if tokenIn != p.Token0.Address {
+		reserveIn, reserveOut = p.Reserve1, p.Reserve0
+	}
+	if reserveIn == nil || reserveOut == nil || reserveOut.Cmp(amountOut) <= 0 || p.Fee >= 10000 {
+		return nil
+	}
+
+	// amountIn = reserveIn * amountOut * 10000 / ((reserveOut - amountOut) * (10000 - fee)) + 1
+	numerator := new(big.Int).Mul(reserveIn, amountOut)
+	numerator.Mul(numerator, big.NewInt(10000))
+	denominator := new(big.Int).Sub(reserveOut, amountOut)
+	denominator.Mul(denominator, big.NewInt(10000-int64(p.Fee)))
+	amountIn := numerator.Div(numerator, denominator)
+	return amountIn.Add(amountIn, big.NewInt(1))
+}
+
+// reserveOut is the reserve of the token a swap from tokenIn pays out
+func (p *Pair) reserveOut(tokenIn common.Address) *big.Int {
+	if tokenIn == p.Token0.Address {
+		return p.Reserve1
+	}
+	return p.Reserve0
+}
+
+// reserveIn is the reserve of tokenIn
+func (p *Pair) reserveIn(tokenIn common.Address) *big.Int {
+	if tokenIn == p.Token0.Address {
+		return p.Reserve0
+	}
+	return p.Reserve1
+}
@@ -190,3 +190,29 @@ func TestGetAmountOutWithZeroReserves(t *testing.T) {
 		t.Errorf("GetAmountOut() with zero reserveIn = %v, want 0", got.String())
 	}
 }
+
+func TestPairLiquidityScore(t *testing.T) {
+	token0 := Token{Address: common.HexToAddress("0x01")}
+	token1 := Token{Address: common.HexToAddress("0x02")}
+	pair := &Pair{Token0: token0, Token1: token1, Reserve0: big.NewInt(1000), Reserve1: big.NewInt(200)}
+
+	tests := []struct {
+		name     string
+		amountIn int64
+		tokenIn  common.Address
+		want     uint64
+	}{
+		{"1% of reserve0", 10, token0.Address, 9900},
+		{"5% of reserve1", 10, token1.Address, 9500},
+		{"whole reserve", 1000, token0.Address, 0},
+		{"beyond reserve", 5000, token0.Address, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pair.LiquidityScore(big.NewInt(tt.amountIn), tt.tokenIn); got != tt.want {
+				t.Errorf("LiquidityScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
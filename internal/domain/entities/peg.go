@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StablecoinPeg is a stablecoin's USD value as implied by the other
+// stablecoins. PriceUSD has 18 decimals.
+type StablecoinPeg struct {
+	Token        common.Address `json:"token"`
+	Symbol       string         `json:"symbol"`
+	PriceUSD     *big.Int       `json:"priceUsd"`
+	DeviationBps int64          `json:"deviationBps"` // Signed distance from $1
+	Depegged     bool           `json:"depegged"`
+}
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PoolStats is indexer data for a pool. USD amounts have 18 decimals.
+type PoolStats struct {
+	DEX          DEXType        `json:"dex"`
+	Address      common.Address `json:"address"`
+	Tokens       []Token        `json:"tokens"`
+	FeeTier      uint64         `json:"feeTier,omitempty"` // V3 fee in hundredths of a bip
+	TVLUSD       *big.Int       `json:"tvlUsd"`
+	Volume24hUSD *big.Int       `json:"volume24hUsd"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+// Pool verification statuses
+const (
+	PoolVerified   = "verified"
+	PoolUnverified = "unverified"
+)
+
+// Reasons a pool fails verification
+const (
+	PoolUnknownFactory = "unknown_factory" // Not deployed by a canonical factory
+	PoolLowTVL         = "low_tvl"
+	PoolUnknownTVL     = "unknown_tvl"    // Neither the subgraph nor a priced reserve values it
+	PoolUnlistedToken  = "unlisted_token" // A token missing from the token list
+)
+
+// PoolVerification is how a pool measures up to the verified-pool policy
+type PoolVerification struct {
+	Status  string   `json:"status"`
+	Reasons []string `json:"reasons,omitempty"`
+}
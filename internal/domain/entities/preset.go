@@ -0,0 +1,49 @@
+package entities
+
+import "github.com/ethereum/go-ethereum/common"
+
+// RoutingPreset is a family of tokens that trade mostly against a few hubs.
+// Multi-hop quotes touching a member always try the hubs as intermediates,
+// whether or not the intermediate index ranks them.
+type RoutingPreset struct {
+	Name    string
+	Members []common.Address
+	Hubs    []Token
+}
+
+// RoutingPresets are the curated families. BTC wrappers are thin against
+// anything but each other and ETH: tBTC and cbBTC mostly trade into WBTC
+// on Curve and Uniswap V3, and WBTC into WETH.
+var RoutingPresets = []RoutingPreset{
+	{
+		Name:    "btc",
+		Members: []common.Address{WBTC.Address, TBTC.Address, CBBTC.Address},
+		Hubs:    []Token{WBTC, WETH},
+	},
+}
+
+// PresetHubs returns the hubs of every preset tokenIn or tokenOut belongs
+// to, without duplicates
+func PresetHubs(tokenIn, tokenOut common.Address) []Token {
+	var hubs []Token
+	seen := make(map[common.Address]bool)
+	for _, preset := range RoutingPresets {
+		member := false
+		for _, addr := range preset.Members {
+			if addr == tokenIn || addr == tokenOut {
+				member = true
+				break
+			}
+		}
+		if !member {
+			continue
+		}
+		for _, hub := range preset.Hubs {
+			if !seen[hub.Address] {
+				seen[hub.Address] = true
+				hubs = append(hubs, hub)
+			}
+		}
+	}
+	return hubs
+}
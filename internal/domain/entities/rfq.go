@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RFQRequest is sent to connected market makers to solicit firm quotes
+type RFQRequest struct {
+	ID        string         `json:"id"`
+	ChainID   uint64         `json:"chainId"`
+	TokenIn   Token          `json:"tokenIn"`
+	TokenOut  Token          `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	Taker     common.Address `json:"taker"`     // Zero means any taker may fill
+	ExpiresAt int64          `json:"expiresAt"` // Unix ms; responses after this are dropped
+}
+
+// RFQOrder is a market maker's signed firm quote. The EIP-712 signature
+// commits to every field except MakerName and Signature.
+type RFQOrder struct {
+	MakerName string         `json:"makerName"`
+	Maker     common.Address `json:"maker"`
+	Taker     common.Address `json:"taker"`
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn"`
+	AmountOut *big.Int       `json:"amountOut"`
+	Expiry    uint64         `json:"expiry"` // Unix seconds
+	Nonce     *big.Int       `json:"nonce"`
+	Signature []byte         `json:"signature"`
+}
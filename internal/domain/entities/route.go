@@ -2,14 +2,17 @@ package entities
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type Hop struct {
-	Pair     Pair           `json:"pair"`
-	TokenIn  common.Address `json:"tokenIn"`
-	TokenOut common.Address `json:"tokenOut"`
+	Pair      Pair           `json:"pair"`
+	TokenIn   common.Address `json:"tokenIn"`
+	TokenOut  common.Address `json:"tokenOut"`
+	AmountIn  *big.Int       `json:"amountIn,omitempty"` // Set by Route.FillHopAmounts
+	AmountOut *big.Int       `json:"amountOut,omitempty"`
 }
 
 type Route struct {
@@ -23,18 +26,92 @@ type Route struct {
 }
 
 type Quote struct {
-	TokenIn      Token              `json:"tokenIn"`
-	TokenOut     Token              `json:"tokenOut"`
-	AmountIn     *big.Int           `json:"amountIn"`
-	AmountOut    *big.Int           `json:"amountOut"`
-	BestRoute    *Route             `json:"bestRoute"`
-	SplitRoutes  []SplitRoute       `json:"splitRoutes,omitempty"` // Split order routes
-	PriceImpact  *big.Int           `json:"priceImpact"`
-	MinAmountOut *big.Int           `json:"minAmountOut,omitempty"` // After slippage
-	SlippageBps  uint64             `json:"slippageBps,omitempty"`  // Slippage in basis points
-	GasEstimate  uint64             `json:"gasEstimate"`
-	Sources      map[DEXType]string `json:"sources"` // Price quotes from each DEX
-	PriceWarning string             `json:"priceWarning,omitempty"`
+	ID              string             `json:"id,omitempty"` // Set once the quote is kept for validation
+	TokenIn         Token              `json:"tokenIn"`
+	TokenOut        Token              `json:"tokenOut"`
+	AmountIn        *big.Int           `json:"amountIn"`
+	AmountOut       *big.Int           `json:"amountOut"`
+	NativeIn        bool               `json:"nativeIn,omitempty"`  // TokenIn is the wrapper; the sender pays the gas token
+	NativeOut       bool               `json:"nativeOut,omitempty"` // TokenOut is the wrapper; the recipient gets the gas token
+	BestRoute       *Route             `json:"bestRoute"`
+	SplitRoutes     []SplitRoute       `json:"splitRoutes,omitempty"` // Split order routes
+	PriceImpact     *big.Int           `json:"priceImpact"`
+	MinAmountOut    *big.Int           `json:"minAmountOut,omitempty"`    // After slippage
+	SlippageBps     uint64             `json:"slippageBps,omitempty"`     // Slippage in basis points
+	SlippageDefault *SlippageDefault   `json:"slippageDefault,omitempty"` // Pair-class default, applied unless overridden
+	SlippageAuto    *SlippageAuto      `json:"slippageAuto,omitempty"`    // Set for slippage=auto
+	Strategy        string             `json:"strategy,omitempty"`        // Routing strategy that found the AMM routes
+	OptimizeFor     string             `json:"optimizeFor,omitempty"`     // What chose the routes, when not raw output
+	Venues          []DEXType          `json:"venues,omitempty"`          // The venues the request was limited to, if any
+	VerifiedPools   bool               `json:"verifiedPools,omitempty"`   // The request was limited to verified pools
+	ConvertedVia    *Token             `json:"convertedVia,omitempty"`    // Equivalent token routed through when the pair had no route
+	Fallback        *RouteDiagnostic   `json:"fallback,omitempty"`        // Why the pair had no route, when a relaxed retry found one
+	GasEstimate     uint64             `json:"gasEstimate"`
+	QuotedAtBlock   uint64             `json:"quotedAtBlock,omitempty"` // Oldest block any used pool was read at
+	PinnedBlock     uint64             `json:"pinnedBlock,omitempty"`   // The block every venue was read at, when the request pinned one
+	ExpiresAt       time.Time          `json:"expiresAt"`               // Also the deadline of the built transaction
+	GasSource       string             `json:"gasSource,omitempty"`     // "simulated" or "calibrated"
+	GasCost         *GasCost           `json:"gasCost,omitempty"`
+	IntegratorFee   *IntegratorFee     `json:"integratorFee,omitempty"`  // Already deducted from AmountOut
+	AmountInUSD     *big.Int           `json:"amountInUsd,omitempty"`    // 18 decimals
+	AmountOutUSD    *big.Int           `json:"amountOutUsd,omitempty"`   // 18 decimals
+	PriceImpactUSD  *big.Int           `json:"priceImpactUsd,omitempty"` // Output value lost to price impact, 18 decimals
+	Transaction     *SwapTransaction   `json:"transaction,omitempty"`
+	RouteProcessor  *ProcessorRoute    `json:"routeProcessor,omitempty"`
+	Approval        *ApprovalPlan      `json:"approval,omitempty"` // Approvals to send before Transaction
+	RFQOrder        *RFQOrder          `json:"rfqOrder,omitempty"` // Set when a market maker beat the AMM routes
+	Sources         map[DEXType]string `json:"sources"`            // Price quotes from each DEX
+	SourceDetails   []SourceDetail     `json:"sourceDetails,omitempty"`
+	Savings         *Savings           `json:"savings,omitempty"`
+	TradeSize       *TradeSize         `json:"tradeSize,omitempty"`
+	ExecutionPlan   *ExecutionPlan     `json:"executionPlan,omitempty"` // With plan=true, for trades over the impact threshold
+
+	PriceWarning  string         `json:"priceWarning,omitempty"`
+	TokenWarnings []TokenWarning `json:"tokenWarnings,omitempty"`
+}
+
+// Savings compares a quote's output with single-venue alternatives, each as
+// (quote - alternative) / alternative in basis points
+type Savings struct {
+	VsWorstBps  int64 `json:"vsWorstBps"`
+	VsMedianBps int64 `json:"vsMedianBps"`
+	// VsBestVenueBps is set when a split or market maker beat every single
+	// venue
+	VsBestVenueBps *int64 `json:"vsBestVenueBps,omitempty"`
+}
+
+// TradeSize relates a trade to the depth of the pools that quote its pair
+type TradeSize struct {
+	// MaxRecommendedAmountIn is the most the pools take, split across
+	// them, with each within ImpactCeilingBps of price impact
+	MaxRecommendedAmountIn *big.Int `json:"maxRecommendedAmountIn"`
+	ImpactCeilingBps       uint64   `json:"impactCeilingBps"`
+	AccessibleLiquidity    *big.Int `json:"accessibleLiquidity"` // The pools' reserves of the input token
+	ExceedsDepth           bool     `json:"exceedsDepth,omitempty"`
+}
+
+// QuoteValidation is the result of re-checking a served quote before it is
+// executed
+type QuoteValidation struct {
+	QuoteID          string
+	Valid            bool
+	Reason           string // Why the quote is no longer valid
+	ExpiresAt        time.Time
+	MinAmountOut     *big.Int
+	CurrentAmountOut *big.Int // Output of the same route search now
+}
+
+// SourceDetail describes how a single DEX responded while building a quote,
+// including venues that failed or returned no liquidity
+type SourceDetail struct {
+	DEX         DEXType  `json:"dex"`
+	AmountOut   *big.Int `json:"amountOut,omitempty"`
+	GasEstimate uint64   `json:"gasEstimate,omitempty"`
+	// LiquidityScore is 10000 minus the trade's share of the input reserve
+	// in basis points
+	LiquidityScore uint64 `json:"liquidityScore"`
+	LatencyMs      int64  `json:"latencyMs"`
+	Error          string `json:"error,omitempty"`
 }
 
 // SplitRoute represents a portion of an order routed through a specific DEX
@@ -45,6 +122,23 @@ type SplitRoute struct {
 	AmountOut  *big.Int `json:"amountOut"`
 }
 
+// FillHopAmounts sets each hop's input and output along the route. The last
+// hop reports the route's AmountOut, so venues priced off-chain, such as
+// market maker orders, show what was quoted.
+func (r *Route) FillHopAmounts() {
+	amount := r.AmountIn
+	for i := range r.Hops {
+		hop := &r.Hops[i]
+		hop.AmountIn = amount
+		if i == len(r.Hops)-1 && r.AmountOut != nil {
+			hop.AmountOut = r.AmountOut
+		} else if amount != nil {
+			hop.AmountOut = hop.Pair.GetAmountOut(amount, hop.TokenIn)
+		}
+		amount = hop.AmountOut
+	}
+}
+
 func (r *Route) CalculateAmountOut() *big.Int {
 	if len(r.Hops) == 0 || r.AmountIn == nil {
 		return big.NewInt(0)
@@ -61,6 +155,25 @@ func (r *Route) CalculateAmountOut() *big.Int {
 	return currentAmount
 }
 
+// SpotPrice returns the route's mid price before fees, in raw tokenOut per
+// raw tokenIn, as num/den: the product of each hop's reserve ratio. den is
+// zero when a hop has no reserves.
+func (r *Route) SpotPrice() (num, den *big.Int) {
+	num, den = big.NewInt(1), big.NewInt(1)
+	if len(r.Hops) == 0 {
+		return num, new(big.Int)
+	}
+	for _, hop := range r.Hops {
+		reserveIn, reserveOut := hop.Pair.reserveIn(hop.TokenIn), hop.Pair.reserveOut(hop.TokenIn)
+		if reserveIn == nil || reserveOut == nil || reserveIn.Sign() <= 0 {
+			return num, new(big.Int)
+		}
+		num.Mul(num, reserveOut)
+		den.Mul(den, reserveIn)
+	}
+	return num, den
+}
+
 // Price impact = (spotPrice - executionPrice) / spotPrice * 10000
 func (r *Route) CalculatePriceImpact() *big.Int {
 	if len(r.Hops) == 0 || r.AmountIn == nil || r.AmountIn.Sign() == 0 {
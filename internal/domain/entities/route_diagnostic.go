@@ -0,0 +1,44 @@
+package entities
+
+import "math/big"
+
+// Why a pair had no route, as RouteDiagnostic.Cause
+const (
+	NoRouteNoPools               = "no_pools"               // No venue holds the pair
+	NoRouteInsufficientLiquidity = "insufficient_liquidity" // Pools hold the pair but can't fill the amount
+	NoRouteBelowMinLiquidity     = "below_min_liquidity"    // The only pools are below the liquidity floor
+	NoRouteUnverified            = "unverified_pools"       // The only pools fail verification, on a verified-pools quote
+	NoRouteVenuesFailed          = "venues_failed"          // Venues that might hold the pair failed to answer
+)
+
+// Why one venue gave no route, as VenueDiagnostic.Reason
+const (
+	VenueNoPool                = "no_pool"
+	VenueBelowMinLiquidity     = "below_min_liquidity"
+	VenueUnverified            = "unverified_pool"
+	VenueCircuitOpen           = "circuit_open"
+	VenueError                 = "error"
+	VenueInsufficientLiquidity = "insufficient_liquidity" // Fills the probe but not the amount
+	VenueNoLiquidity           = "no_liquidity"           // Fills neither
+	VenueTooShallow            = "too_shallow"
+	VenueLowTVL                = "low_tvl"
+	VenueQuoted                = "quoted"
+)
+
+// RouteDiagnostic explains why a pair had no route of its own: each
+// venue's answer at the requested amount and at a far smaller probe, and
+// the constraints relaxed to retry it
+type RouteDiagnostic struct {
+	Cause       string            `json:"cause"`
+	ProbeAmount *big.Int          `json:"probeAmount,omitempty"`
+	Relaxed     []string          `json:"relaxed,omitempty"` // e.g. multi_hop
+	Venues      []VenueDiagnostic `json:"venues"`
+}
+
+// VenueDiagnostic is one venue's part in a RouteDiagnostic
+type VenueDiagnostic struct {
+	DEX      DEXType  `json:"dex"`
+	Reason   string   `json:"reason"`
+	Detail   string   `json:"detail,omitempty"`
+	ProbeOut *big.Int `json:"probeOut,omitempty"` // What the venue pays for the probe, when it fills it
+}
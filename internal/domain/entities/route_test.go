@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestRouteFillHopAmounts(t *testing.T) {
+	a := common.HexToAddress("0x01")
+	b := common.HexToAddress("0x02")
+	c := common.HexToAddress("0x03")
+	pairAB := Pair{Token0: Token{Address: a}, Token1: Token{Address: b}, Reserve0: ether(1000), Reserve1: ether(2000), Fee: 30}
+	pairBC := Pair{Token0: Token{Address: b}, Token1: Token{Address: c}, Reserve0: ether(5000), Reserve1: ether(5000), Fee: 30}
+
+	route := &Route{
+		Hops: []Hop{
+			{Pair: pairAB, TokenIn: a, TokenOut: b},
+			{Pair: pairBC, TokenIn: b, TokenOut: c},
+		},
+		AmountIn: ether(10),
+	}
+	route.AmountOut = route.CalculateAmountOut()
+	route.FillHopAmounts()
+
+	first, second := route.Hops[0], route.Hops[1]
+	if first.AmountIn.Cmp(route.AmountIn) != 0 {
+		t.Errorf("first hop AmountIn = %s, want %s", first.AmountIn, route.AmountIn)
+	}
+	if want := pairAB.GetAmountOut(ether(10), a); first.AmountOut.Cmp(want) != 0 {
+		t.Errorf("first hop AmountOut = %s, want %s", first.AmountOut, want)
+	}
+	if second.AmountIn.Cmp(first.AmountOut) != 0 {
+		t.Errorf("second hop AmountIn = %s, want the first hop's output %s", second.AmountIn, first.AmountOut)
+	}
+	if second.AmountOut.Cmp(route.AmountOut) != 0 {
+		t.Errorf("last hop AmountOut = %s, want the route's %s", second.AmountOut, route.AmountOut)
+	}
+
+	// An off-chain venue without reserves reports the quoted amount
+	rfq := &Route{
+		Hops:      []Hop{{Pair: Pair{DEX: DEXRFQ}, TokenIn: a, TokenOut: b}},
+		AmountIn:  ether(1),
+		AmountOut: big.NewInt(1999),
+	}
+	rfq.FillHopAmounts()
+	if got := rfq.Hops[0].AmountOut; got.Cmp(big.NewInt(1999)) != 0 {
+		t.Errorf("RFQ hop AmountOut = %s, want 1999", got)
+	}
+}
+
+func TestRouteSpotPrice(t *testing.T) {
+	a := common.HexToAddress("0x01")
+	b := common.HexToAddress("0x02")
+	c := common.HexToAddress("0x03")
+	pairAB := Pair{Token0: Token{Address: a}, Token1: Token{Address: b}, Reserve0: ether(1000), Reserve1: ether(2000), Fee: 30}
+	pairCB := Pair{Token0: Token{Address: c}, Token1: Token{Address: b}, Reserve0: ether(3000), Reserve1: ether(4000), Fee: 30}
+
+	// 2 B per A, then 3/4 C per B, fees left out
+	route := &Route{Hops: []Hop{
+		{Pair: pairAB, TokenIn: a, TokenOut: b},
+		{Pair: pairCB, TokenIn: b, TokenOut: c},
+	}}
+	num, den := route.SpotPrice()
+	if got := new(big.Rat).SetFrac(num, den); got.Cmp(big.NewRat(3, 2)) != 0 {
+		t.Errorf("SpotPrice() = %s, want 3/2", got.RatString())
+	}
+
+	rfq := &Route{Hops: []Hop{{Pair: Pair{DEX: DEXRFQ}, TokenIn: a, TokenOut: b}}}
+	if _, den := rfq.SpotPrice(); den.Sign() != 0 {
+		t.Errorf("SpotPrice(no reserves) den = %s, want 0", den)
+	}
+}
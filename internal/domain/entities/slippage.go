@@ -0,0 +1,65 @@
+package entities
+
+import "github.com/ethereum/go-ethereum/common"
+
+// PairClass groups token pairs by how much their price moves between quote
+// and execution
+type PairClass string
+
+const (
+	PairClassStable   PairClass = "stable"    // Both sides are USD stablecoins
+	PairClassMajor    PairClass = "major"     // Both sides are majors or stablecoins
+	PairClassLongTail PairClass = "long_tail" // One side is a long-tail token
+	PairClassExotic   PairClass = "exotic"    // Neither side is a major
+)
+
+// SlippageDefault is the slippage picked for a pair class and why
+type SlippageDefault struct {
+	Class  PairClass `json:"class"`
+	Bps    uint64    `json:"bps"`
+	Reason string    `json:"reason"`
+}
+
+// SlippageAuto is the slippage tuned from how the route's output moved
+// block over block, and why
+type SlippageAuto struct {
+	Bps             uint64  `json:"bps"`
+	Samples         int     `json:"samples"`         // Block-over-block changes measured; 0 when the default was kept
+	FillProbability float64 `json:"fillProbability"` // Share of the changes Bps would have absorbed
+	VolatilityBps   float64 `json:"volatilityBps"`   // Standard deviation of the changes
+	Reason          string  `json:"reason"`
+}
+
+// Stablecoins are the USD stablecoins treated as the stable pair class
+var Stablecoins = map[common.Address]bool{
+	USDC.Address: true,
+	USDT.Address: true,
+	DAI.Address:  true,
+}
+
+// MajorTokens are deep-liquidity tokens besides the stablecoins
+var MajorTokens = map[common.Address]bool{
+	WETH.Address:   true,
+	STETH.Address:  true,
+	WSTETH.Address: true,
+	RETH.Address:   true,
+}
+
+// IsMajorToken reports whether token is a stablecoin or another major
+func IsMajorToken(token Token) bool {
+	return Stablecoins[token.Address] || MajorTokens[token.Address]
+}
+
+// ClassifyPair returns the pair class of tokenA and tokenB
+func ClassifyPair(tokenA, tokenB Token) PairClass {
+	switch {
+	case Stablecoins[tokenA.Address] && Stablecoins[tokenB.Address]:
+		return PairClassStable
+	case IsMajorToken(tokenA) && IsMajorToken(tokenB):
+		return PairClassMajor
+	case IsMajorToken(tokenA) || IsMajorToken(tokenB):
+		return PairClassLongTail
+	default:
+		return PairClassExotic
+	}
+}
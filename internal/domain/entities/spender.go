@@ -0,0 +1,12 @@
+package entities
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Spender is a contract users approve to move their tokens, and what it
+// spends them for
+type Spender struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	DEXes   []DEXType      `json:"dexes,omitempty"` // Venues whose built swaps it runs; empty when not venue-specific
+	Purpose string         `json:"purpose"`
+}
@@ -0,0 +1,40 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VenuePrice is one venue's price for one whole TokenA of a Spread, in raw
+// TokenB units
+type VenuePrice struct {
+	DEX  DEXType        `json:"dex"`
+	Pool common.Address `json:"pool"`
+	Bid  *big.Int       `json:"bid"` // Received selling TokenA
+	Ask  *big.Int       `json:"ask"` // Paid buying TokenA
+}
+
+// Mid is halfway between the venue's bid and ask
+func (v VenuePrice) Mid() *big.Int {
+	mid := new(big.Int).Add(v.Bid, v.Ask)
+	return mid.Rsh(mid, 1)
+}
+
+// Spread is the best bid and ask for TokenA in TokenB across venues, fees
+// and price impact of one whole TokenA included. SpreadBps is negative when
+// one venue bids above another's ask. DivergenceBps is the widest gap
+// between two venues' mid prices, over the lower one.
+type Spread struct {
+	TokenA        Token        `json:"tokenA"`
+	TokenB        Token        `json:"tokenB"`
+	Bid           *big.Int     `json:"bid"`
+	BidDEX        DEXType      `json:"bidDex"`
+	Ask           *big.Int     `json:"ask"`
+	AskDEX        DEXType      `json:"askDex"`
+	SpreadBps     int64        `json:"spreadBps"`
+	DivergenceBps uint64       `json:"divergenceBps"`
+	Venues        []VenuePrice `json:"venues"`
+	Block         uint64       `json:"block,omitempty"` // Head the spread was computed at
+	UpdatedAt     int64        `json:"updatedAt"`
+}
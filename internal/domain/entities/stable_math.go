@@ -0,0 +1,287 @@
+package entities
+
+import (
+	"math/big"
+)
+
+// AmpPrecision is the fixed-point precision of StableCurve.Amp
+const AmpPrecision = 1000
+
+var fixedOne = big.NewInt(1e18)
+
+// StableCurve prices a pair with Balancer StableSwap math. The invariant
+// depends on every token in the pool, so Balances holds all of them
+// upscaled to 18 decimals, and Index0/Index1 locate Token0 and Token1.
+type StableCurve struct {
+	Amp      *big.Int   `json:"amp"` // A × AmpPrecision
+	Balances []*big.Int `json:"balances"`
+	Index0   int        `json:"index0"`
+	Index1   int        `json:"index1"`
+	// Scale0 and Scale1 upscale Token0 and Token1 amounts to 18 decimals,
+	// including any token rate, as 18-decimal fixed point
+	Scale0 *big.Int `json:"scale0"`
+	Scale1 *big.Int `json:"scale1"`
+	// Linear0 and Linear1 are set when the token reaches a boosted pool
+	// through a linear pool; the Scale is then that of the linear BPT
+	Linear0 *LinearLeg `json:"linear0,omitempty"`
+	Linear1 *LinearLeg `json:"linear1,omitempty"`
+}
+
+// LinearLeg is a Balancer linear pool, which trades its main token for its
+// BPT at par and only charges Fee on balances outside the target range.
+// Balances and targets are upscaled; WrappedBalance includes the wrapped
+// token's rate.
+type LinearLeg struct {
+	MainBalance    *big.Int `json:"mainBalance"`
+	WrappedBalance *big.Int `json:"wrappedBalance"`
+	VirtualSupply  *big.Int `json:"virtualSupply"`
+	LowerTarget    *big.Int `json:"lowerTarget"`
+	UpperTarget    *big.Int `json:"upperTarget"`
+	Fee            *big.Int `json:"fee"`       // 18 decimals
+	MainScale      *big.Int `json:"mainScale"` // Upscales main token amounts
+}
+
+// amountOut swaps through the curve, charging feeBps on the stable swap
+func (c *StableCurve) amountOut(amountIn *big.Int, zeroForOne bool, feeBps uint64) *big.Int {
+	in, out := c.Index0, c.Index1
+	scaleIn, scaleOut := c.Scale0, c.Scale1
+	linearIn, linearOut := c.Linear0, c.Linear1
+	if !zeroForOne {
+		in, out = out, in
+		scaleIn, scaleOut = scaleOut, scaleIn
+		linearIn, linearOut = linearOut, linearIn
+	}
+	if c.Amp == nil || scaleIn == nil || scaleOut == nil ||
+		in < 0 || out < 0 || in >= len(c.Balances) || out >= len(c.Balances) {
+		return big.NewInt(0)
+	}
+
+	amount := new(big.Int).Set(amountIn)
+	if linearIn != nil {
+		amount = linearIn.bptOutPerMainIn(upscale(amount, linearIn.MainScale))
+	}
+	amount = upscale(amount, scaleIn)
+
+	fee := new(big.Int).Mul(amount, new(big.Int).SetUint64(feeBps))
+	fee.Add(fee, big.NewInt(9999)).Div(fee, big.NewInt(10000))
+	amount.Sub(amount, fee)
+	if amount.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+
+	amount = stableOutGivenIn(c.Amp, c.Balances, in, out, amount)
+	if amount.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	amount = downscale(amount, scaleOut)
+
+	if linearOut != nil {
+		amount = downscale(linearOut.mainOutPerBptIn(amount), linearOut.MainScale)
+	}
+	if amount.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return amount
+}
+
+// bptOutPerMainIn mirrors LinearMath._calcBptOutPerMainIn
+func (l *LinearLeg) bptOutPerMainIn(mainIn *big.Int) *big.Int {
+	if l.VirtualSupply == nil || l.VirtualSupply.Sign() == 0 {
+		return l.toNominal(mainIn)
+	}
+	previous := l.toNominal(l.MainBalance)
+	after := l.toNominal(new(big.Int).Add(l.MainBalance, mainIn))
+	delta := after.Sub(after, previous)
+
+	invariant := new(big.Int).Add(previous, l.WrappedBalance)
+	if invariant.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	out := new(big.Int).Mul(l.VirtualSupply, delta)
+	return out.Div(out, invariant)
+}
+
+// mainOutPerBptIn mirrors LinearMath._calcMainOutPerBptIn
+func (l *LinearLeg) mainOutPerBptIn(bptIn *big.Int) *big.Int {
+	if l.VirtualSupply == nil || l.VirtualSupply.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	previous := l.toNominal(l.MainBalance)
+	invariant := new(big.Int).Add(previous, l.WrappedBalance)
+	delta := new(big.Int).Mul(invariant, bptIn)
+	delta.Div(delta, l.VirtualSupply)
+
+	after := previous.Sub(previous, delta)
+	if after.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	out := new(big.Int).Sub(l.MainBalance, l.fromNominal(after))
+	if out.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return out
+}
+
+// toNominal deducts the fee charged on the part of a balance outside the
+// targets
+func (l *LinearLeg) toNominal(real *big.Int) *big.Int {
+	switch {
+	case real.Cmp(l.LowerTarget) < 0:
+		fees := mulDown(new(big.Int).Sub(l.LowerTarget, real), l.Fee)
+		return fees.Sub(real, fees)
+	case real.Cmp(l.UpperTarget) <= 0:
+		return new(big.Int).Set(real)
+	default:
+		fees := mulDown(new(big.Int).Sub(real, l.UpperTarget), l.Fee)
+		return fees.Sub(real, fees)
+	}
+}
+
+// fromNominal inverts toNominal
+func (l *LinearLeg) fromNominal(nominal *big.Int) *big.Int {
+	switch {
+	case nominal.Cmp(l.LowerTarget) < 0:
+		num := new(big.Int).Add(nominal, mulDown(l.Fee, l.LowerTarget))
+		return divDown(num, new(big.Int).Add(fixedOne, l.Fee))
+	case nominal.Cmp(l.UpperTarget) <= 0:
+		return new(big.Int).Set(nominal)
+	default:
+		num := new(big.Int).Sub(nominal, mulDown(l.Fee, l.UpperTarget))
+		return divDown(num, new(big.Int).Sub(fixedOne, l.Fee))
+	}
+}
+
+// stableInvariant mirrors StableMath._calculateInvariant
+func stableInvariant(amp *big.Int, balances []*big.Int) *big.Int {
+	n := big.NewInt(int64(len(balances)))
+	sum := new(big.Int)
+	for _, balance := range balances {
+		if balance == nil || balance.Sign() <= 0 {
+			return big.NewInt(0)
+		}
+		sum.Add(sum, balance)
+	}
+
+	ampTimesTotal := new(big.Int).Mul(amp, n)
+	ampPrecision := big.NewInt(AmpPrecision)
+	invariant := new(big.Int).Set(sum)
+	for i := 0; i < 255; i++ {
+		dP := new(big.Int).Set(invariant)
+		for _, balance := range balances {
+			dP.Mul(dP, invariant)
+			dP.Div(dP, new(big.Int).Mul(balance, n))
+		}
+		previous := invariant
+
+		// ((A·n·S / P + D_P·n) · D) / ((A·n - P) · D / P + (n+1) · D_P)
+		num := new(big.Int).Mul(ampTimesTotal, sum)
+		num.Div(num, ampPrecision)
+		num.Add(num, new(big.Int).Mul(dP, n))
+		num.Mul(num, invariant)
+
+		den := new(big.Int).Sub(ampTimesTotal, ampPrecision)
+		den.Mul(den, invariant)
+		den.Div(den, ampPrecision)
+		den.Add(den, new(big.Int).Mul(new(big.Int).Add(n, big.NewInt(1)), dP))
+		if den.Sign() <= 0 {
+			return big.NewInt(0)
+		}
+
+		invariant = num.Div(num, den)
+		if new(big.Int).Sub(invariant, previous).CmpAbs(big.NewInt(1)) <= 0 {
+			return invariant
+		}
+	}
+	return invariant
+}
+
+// stableOutGivenIn mirrors StableMath._calcOutGivenIn on upscaled amounts
+func stableOutGivenIn(amp *big.Int, balances []*big.Int, in, out int, amountIn *big.Int) *big.Int {
+	invariant := stableInvariant(amp, balances)
+	if invariant.Sign() == 0 {
+		return big.NewInt(0)
+	}
+
+	updated := make([]*big.Int, len(balances))
+	copy(updated, balances)
+	updated[in] = new(big.Int).Add(balances[in], amountIn)
+
+	final := stableBalanceGivenInvariant(amp, updated, invariant, out)
+	amountOut := new(big.Int).Sub(balances[out], final)
+	return amountOut.Sub(amountOut, big.NewInt(1))
+}
+
+// stableBalanceGivenInvariant mirrors
+// StableMath._getTokenBalanceGivenInvariantAndAllOtherBalances
+func stableBalanceGivenInvariant(amp *big.Int, balances []*big.Int, invariant *big.Int, index int) *big.Int {
+	n := big.NewInt(int64(len(balances)))
+	ampTimesTotal := new(big.Int).Mul(amp, n)
+	ampPrecision := big.NewInt(AmpPrecision)
+
+	sum := new(big.Int).Set(balances[0])
+	pD := new(big.Int).Mul(balances[0], n)
+	for j := 1; j < len(balances); j++ {
+		pD.Mul(pD, balances[j])
+		pD.Mul(pD, n)
+		pD.Div(pD, invariant)
+		sum.Add(sum, balances[j])
+	}
+	sum.Sub(sum, balances[index])
+
+	inv2 := new(big.Int).Mul(invariant, invariant)
+	c := divUp(new(big.Int).Mul(inv2, ampPrecision), new(big.Int).Mul(ampTimesTotal, pD))
+	c.Mul(c, balances[index])
+
+	b := new(big.Int).Mul(invariant, ampPrecision)
+	b.Div(b, ampTimesTotal)
+	b.Add(b, sum)
+
+	balance := divUp(new(big.Int).Add(inv2, c), new(big.Int).Add(invariant, b))
+	for i := 0; i < 255; i++ {
+		previous := balance
+		num := new(big.Int).Mul(balance, balance)
+		num.Add(num, c)
+		den := new(big.Int).Lsh(balance, 1)
+		den.Add(den, b)
+		den.Sub(den, invariant)
+		if den.Sign() <= 0 {
+			return balances[index]
+		}
+		balance = divUp(num, den)
+		if new(big.Int).Sub(balance, previous).CmpAbs(big.NewInt(1)) <= 0 {
+			break
+		}
+	}
+	return balance
+}
+
+func upscale(amount, scale *big.Int) *big.Int {
+	return mulDown(amount, scale)
+}
+
+func downscale(amount, scale *big.Int) *big.Int {
+	return divDown(amount, scale)
+}
+
+// mulDown and divDown are 18-decimal fixed-point operations
+func mulDown(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	return out.Div(out, fixedOne)
+}
+
+func divDown(a, b *big.Int) *big.Int {
+	if b.Sign() == 0 {
+		return big.NewInt(0)
+	}
+	out := new(big.Int).Mul(a, fixedOne)
+	return out.Div(out, b)
+}
+
+func divUp(a, b *big.Int) *big.Int {
+	if a.Sign() == 0 {
+		return new(big.Int)
+	}
+	out := new(big.Int).Sub(a, big.NewInt(1))
+	out.Div(out, b)
+	return out.Add(out, big.NewInt(1))
+}
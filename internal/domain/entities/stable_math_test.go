@@ -0,0 +1,137 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func stablePair(amp int64, reserve0, reserve1 int64) *Pair {
+	return &Pair{
+		Token0:   Token{Address: common.HexToAddress("0x01"), Decimals: 18},
+		Token1:   Token{Address: common.HexToAddress("0x02"), Decimals: 6},
+		Reserve0: ether(reserve0),
+		Reserve1: big.NewInt(reserve1 * 1e6),
+		Fee:      1,
+		Stable: &StableCurve{
+			Amp:      big.NewInt(amp * AmpPrecision),
+			Balances: []*big.Int{ether(reserve0), ether(reserve1)},
+			Index0:   0,
+			Index1:   1,
+			Scale0:   big.NewInt(1e18),
+			Scale1:   new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil),
+		},
+	}
+}
+
+func TestStableCurveGetAmountOut(t *testing.T) {
+	pair := stablePair(200, 10_000_000, 10_000_000)
+
+	// 100k of a 10M/10M pool: close to par, less the 1bp fee
+	out := pair.GetAmountOut(ether(100_000), pair.Token0.Address)
+	low, high := big.NewInt(99_980*1e6), big.NewInt(99_990*1e6)
+	if out.Cmp(low) < 0 || out.Cmp(high) > 0 {
+		t.Errorf("GetAmountOut() = %s, want between %s and %s", out, low, high)
+	}
+
+	// Constant product would lose about 1% on the same trade
+	cp := *pair
+	cp.Stable = nil
+	if cpOut := cp.GetAmountOut(ether(100_000), pair.Token0.Address); cpOut.Cmp(out) >= 0 {
+		t.Errorf("constant product output %s should be below stable output %s", cpOut, out)
+	}
+
+	// The reverse direction handles the 6-decimal input
+	back := pair.GetAmountOut(big.NewInt(100_000*1e6), pair.Token1.Address)
+	if back.Cmp(ether(99_980)) < 0 || back.Cmp(ether(99_990)) > 0 {
+		t.Errorf("reverse GetAmountOut() = %s, want about 99985e18", back)
+	}
+}
+
+func TestStableCurveImbalancedPool(t *testing.T) {
+	// Selling into the scarce side pays more than par
+	pair := stablePair(100, 2_000_000, 18_000_000)
+	out := pair.GetAmountOut(big.NewInt(10_000*1e6), pair.Token1.Address)
+	if out.Cmp(ether(10_000)) >= 0 {
+		t.Errorf("GetAmountOut() = %s, want below par for the abundant token", out)
+	}
+	out = pair.GetAmountOut(ether(10_000), pair.Token0.Address)
+	if out.Cmp(big.NewInt(10_000*1e6)) <= 0 {
+		t.Errorf("GetAmountOut() = %s, want above par for the scarce token", out)
+	}
+}
+
+func TestLinearLeg(t *testing.T) {
+	leg := &LinearLeg{
+		MainBalance:    ether(500_000),
+		WrappedBalance: ether(1_500_000),
+		VirtualSupply:  ether(2_000_000),
+		LowerTarget:    ether(400_000),
+		UpperTarget:    ether(600_000),
+		Fee:            big.NewInt(1e14), // 0.01%
+		MainScale:      big.NewInt(1e18),
+	}
+
+	tests := []struct {
+		name  string
+		fn    func(*big.Int) *big.Int
+		in    *big.Int
+		want  *big.Int
+		exact bool
+	}{
+		{"main in within targets is at par", leg.bptOutPerMainIn, ether(50_000), ether(50_000), true},
+		{"bpt in within targets is at par", leg.mainOutPerBptIn, ether(50_000), ether(50_000), true},
+		{"main in above the upper target pays the fee", leg.bptOutPerMainIn, ether(200_000), ether(200_000), false},
+		{"bpt in below the lower target pays the fee", leg.mainOutPerBptIn, ether(200_000), ether(200_000), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fn(tt.in)
+			if tt.exact && got.Cmp(tt.want) != 0 {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if !tt.exact && got.Cmp(tt.want) >= 0 {
+				t.Errorf("got %s, want less than %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStableCurveBoostedRoute(t *testing.T) {
+	// USDC -> bb-a-USDC -> bb-a-DAI -> DAI through a 3-token boosted pool
+	linear := func(mainScale *big.Int) *LinearLeg {
+		return &LinearLeg{
+			MainBalance:    ether(1_000_000),
+			WrappedBalance: ether(9_000_000),
+			VirtualSupply:  ether(10_000_000),
+			LowerTarget:    ether(500_000),
+			UpperTarget:    ether(2_000_000),
+			Fee:            big.NewInt(1e14),
+			MainScale:      mainScale,
+		}
+	}
+	pair := &Pair{
+		Token0: Token{Address: common.HexToAddress("0x01"), Decimals: 18}, // DAI
+		Token1: Token{Address: common.HexToAddress("0x02"), Decimals: 6},  // USDC
+		Stable: &StableCurve{
+			Amp:      big.NewInt(1472 * AmpPrecision),
+			Balances: []*big.Int{ether(10_000_000), ether(10_000_000), ether(10_000_000)},
+			Index0:   1,
+			Index1:   2,
+			Scale0:   big.NewInt(1e18),
+			Scale1:   big.NewInt(1e18),
+			Linear0:  linear(big.NewInt(1e18)),
+			Linear1:  linear(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)),
+		},
+	}
+
+	out := pair.GetAmountOut(big.NewInt(100_000*1e6), pair.Token1.Address)
+	if out.Cmp(ether(99_990)) < 0 || out.Cmp(ether(100_000)) > 0 {
+		t.Errorf("GetAmountOut() = %s, want about 100000e18", out)
+	}
+}
@@ -35,8 +35,87 @@ var USDT = Token{
 
 // DAI is Dai Stablecoin on Ethereum mainnet
 var DAI = Token{
-	Address:  common.HexToAddress("0x6B175474E89094C44Da98b954EesfdfdAD3Ef9FB"),
+	Address:  common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"),
 	Symbol:   "DAI",
 	Name:     "Dai Stablecoin",
 	Decimals: 18,
 }
+
+// SDAI is Maker's Savings Dai on Ethereum mainnet, an ERC-4626 vault of
+// DAI earning the DSR
+var SDAI = Token{
+	Address:  common.HexToAddress("0x83F20F44975D03b1b09e64809B757c47f942BEeA"),
+	Symbol:   "sDAI",
+	Name:     "Savings Dai",
+	Decimals: 18,
+}
+
+// USDS is Sky's stablecoin on Ethereum mainnet, converted 1:1 with DAI
+var USDS = Token{
+	Address:  common.HexToAddress("0xdC035D45d973E3EC169d2276DDab16f1e407384F"),
+	Symbol:   "USDS",
+	Name:     "USDS Stablecoin",
+	Decimals: 18,
+}
+
+// STETH is Lido Staked Ether on Ethereum mainnet (rebasing)
+var STETH = Token{
+	Address:  common.HexToAddress("0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84"),
+	Symbol:   "stETH",
+	Name:     "Lido Staked Ether",
+	Decimals: 18,
+}
+
+// WSTETH is Lido Wrapped Staked Ether on Ethereum mainnet (non-rebasing)
+var WSTETH = Token{
+	Address:  common.HexToAddress("0x7f39C581F595B53c5cb19bD0b3f8dA6c935E2Ca0"),
+	Symbol:   "wstETH",
+	Name:     "Wrapped liquid staked Ether 2.0",
+	Decimals: 18,
+}
+
+// RETH is Rocket Pool ETH on Ethereum mainnet
+var RETH = Token{
+	Address:  common.HexToAddress("0xae78736Cd615f374D3085123A210448E74Fc6393"),
+	Symbol:   "rETH",
+	Name:     "Rocket Pool ETH",
+	Decimals: 18,
+}
+
+// WBTC is Wrapped BTC on Ethereum mainnet. Like the other BTC wrappers
+// with 8 decimals, one satoshi is its smallest unit.
+var WBTC = Token{
+	Address:  common.HexToAddress("0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599"),
+	Symbol:   "WBTC",
+	Name:     "Wrapped BTC",
+	Decimals: 8,
+}
+
+// TBTC is Threshold's tBTC v2 on Ethereum mainnet, which has 18 decimals
+var TBTC = Token{
+	Address:  common.HexToAddress("0x18084fbA666a33d37592fA2633fD49a74DD93a88"),
+	Symbol:   "tBTC",
+	Name:     "tBTC v2",
+	Decimals: 18,
+}
+
+// CBBTC is Coinbase Wrapped BTC on Ethereum mainnet
+var CBBTC = Token{
+	Address:  common.HexToAddress("0xcbB7C0000aB88B473b1f5aFd9ef808440eed33Bf"),
+	Symbol:   "cbBTC",
+	Name:     "Coinbase Wrapped BTC",
+	Decimals: 8,
+}
+
+// UnknownToken stands in for a token whose metadata couldn't be found. Its
+// 18 decimals are a guess, so amounts in whole tokens may be off.
+func UnknownToken(addr common.Address) Token {
+	return Token{Address: addr, Symbol: "UNKNOWN", Decimals: 18}
+}
+
+// TokenWarning flags a token that passed the blocklist but looks risky to trade
+type TokenWarning struct {
+	Token   common.Address `json:"token"`
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+}
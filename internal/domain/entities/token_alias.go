@@ -0,0 +1,232 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AliasRule is how a member of a token group is exchanged for the group's
+// canonical token
+type AliasRule string
+
+const (
+	// AliasWrap converts 1:1 through the wrapper contract. Only the gas token
+	// has one the swap transactions can call, so only it may use the rule.
+	AliasWrap AliasRule = "wrap"
+	// AliasSwap converts through pools, e.g. bridged USDC.e and native USDC.
+	// Quotes with no route of their own are routed through the other
+	// members, inserting the conversion as a hop.
+	AliasSwap AliasRule = "swap"
+)
+
+// TokenGroup is a set of tokens integrators pass for one another, such as
+// a token and its bridged variants. A symbol several members share
+// resolves to the canonical token instead of being ambiguous.
+type TokenGroup struct {
+	Name      string
+	Canonical common.Address
+	Members   []TokenAlias
+}
+
+// TokenAlias is a group member other than the canonical token
+type TokenAlias struct {
+	Address common.Address
+	Rule    AliasRule
+	Symbols []string // Further spellings resolving to the member, e.g. USDCE
+}
+
+// TokenGroupsConfig is the token group file, one entry per chain and group
+type TokenGroupsConfig struct {
+	Groups []struct {
+		Name      string `json:"name"`
+		ChainID   uint64 `json:"chainId,omitempty"` // Ethereum mainnet when unset
+		Canonical string `json:"canonical"`
+		Members   []struct {
+			Address string    `json:"address"`
+			Rule    AliasRule `json:"rule"`
+			Symbols []string  `json:"symbols,omitempty"`
+		} `json:"members"`
+	} `json:"groups"`
+}
+
+// nativeGroup is the built-in group of the gas token and its wrapper
+func nativeGroup(native *NativeCurrency) []TokenGroup {
+	if native == nil {
+		return nil
+	}
+	return []TokenGroup{{
+		Name:      native.Symbol,
+		Canonical: native.Wrapped.Address,
+		Members:   []TokenAlias{{Address: NativeTokenAddress, Rule: AliasWrap}},
+	}}
+}
+
+// LoadGroups adds the token groups for the registry's chain from a group
+// file, replacing any loaded before. The gas token's group is built in.
+// Reload re-reads the file.
+func (r *TokenRegistry) LoadGroups(path string) error {
+	groups, err := r.readGroups(path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groupPath = path
+	r.setGroups(groups)
+	return nil
+}
+
+// readGroups parses a group file, keeping the groups for the registry's chain
+func (r *TokenRegistry) readGroups(path string) ([]TokenGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token groups: %w", err)
+	}
+	var config TokenGroupsConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse token groups: %w", err)
+	}
+
+	var groups []TokenGroup
+	seen := make(map[common.Address]string)
+	for _, gc := range config.Groups {
+		chainID := gc.ChainID
+		if chainID == 0 {
+			chainID = ChainEthereum
+		}
+		if chainID != r.chainID {
+			continue
+		}
+		if !common.IsHexAddress(gc.Canonical) {
+			return nil, fmt.Errorf("group %s: invalid canonical address %q", gc.Name, gc.Canonical)
+		}
+		group := TokenGroup{Name: gc.Name, Canonical: common.HexToAddress(gc.Canonical)}
+		for _, mc := range gc.Members {
+			if !common.IsHexAddress(mc.Address) {
+				return nil, fmt.Errorf("group %s: invalid member address %q", gc.Name, mc.Address)
+			}
+			member := TokenAlias{Address: common.HexToAddress(mc.Address), Rule: mc.Rule, Symbols: mc.Symbols}
+			switch member.Rule {
+			case AliasSwap:
+			case AliasWrap:
+				if member.Address != NativeTokenAddress || r.native == nil || group.Canonical != r.native.Wrapped.Address {
+					return nil, fmt.Errorf("group %s: only the gas token converts to its wrapper by the wrap rule", gc.Name)
+				}
+			default:
+				return nil, fmt.Errorf("group %s: unknown rule %q for %s", gc.Name, mc.Rule, mc.Address)
+			}
+			group.Members = append(group.Members, member)
+		}
+		for _, addr := range group.addresses() {
+			if other, ok := seen[addr]; ok {
+				return nil, fmt.Errorf("%s is in both group %s and %s", addr.Hex(), other, gc.Name)
+			}
+			seen[addr] = gc.Name
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// setGroups indexes the built-in groups followed by groups, a configured
+// group taking over the gas token's. Callers hold r.mu.
+func (r *TokenRegistry) setGroups(groups []TokenGroup) {
+	r.groups = nil
+	r.groupOf = make(map[common.Address]int)
+	r.aliasSymbols = make(map[string]common.Address)
+	for _, group := range append(nativeGroup(r.native), groups...) {
+		idx := len(r.groups)
+		for _, addr := range group.addresses() {
+			if old, ok := r.groupOf[addr]; ok {
+				// Only the built-in group can be overlapped
+				r.groups[old] = TokenGroup{}
+			}
+			r.groupOf[addr] = idx
+		}
+		for _, member := range group.Members {
+			for _, symbol := range member.Symbols {
+				r.aliasSymbols[strings.ToUpper(symbol)] = member.Address
+			}
+		}
+		r.groups = append(r.groups, group)
+	}
+}
+
+// addresses are the canonical token followed by the members
+func (g TokenGroup) addresses() []common.Address {
+	addrs := []common.Address{g.Canonical}
+	for _, member := range g.Members {
+		addrs = append(addrs, member.Address)
+	}
+	return addrs
+}
+
+// Groups returns the chain's token groups
+func (r *TokenRegistry) Groups() []TokenGroup {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	groups := make([]TokenGroup, 0, len(r.groups))
+	for _, group := range r.groups {
+		if group.Canonical != (common.Address{}) {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// GroupOf returns the group addr belongs to, as canonical token or member
+func (r *TokenRegistry) GroupOf(addr common.Address) (TokenGroup, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	idx, ok := r.groupOf[addr]
+	if !ok || r.groups[idx].Canonical == (common.Address{}) {
+		return TokenGroup{}, false
+	}
+	return r.groups[idx], true
+}
+
+// Equivalents returns the registered tokens addr converts to through pools:
+// the rest of its group, bar members that only wrap
+func (r *TokenRegistry) Equivalents(addr common.Address) []Token {
+	group, ok := r.GroupOf(addr)
+	if !ok {
+		return nil
+	}
+	candidates := []common.Address{group.Canonical}
+	for _, member := range group.Members {
+		if member.Rule == AliasSwap {
+			candidates = append(candidates, member.Address)
+		}
+	}
+
+	var tokens []Token
+	for _, candidate := range candidates {
+		if candidate == addr {
+			continue
+		}
+		if token, ok := r.GetByAddress(candidate); ok {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// canonicalFor resolves addrs all sharing a symbol to their group's
+// canonical token, when they are all one group. Callers hold r.mu.
+func (r *TokenRegistry) canonicalFor(addrs []common.Address) (Token, bool) {
+	idx, ok := r.groupOf[addrs[0]]
+	if !ok {
+		return Token{}, false
+	}
+	for _, addr := range addrs[1:] {
+		if other, ok := r.groupOf[addr]; !ok || other != idx {
+			return Token{}, false
+		}
+	}
+	token, ok := r.byAddress[r.groups[idx].Canonical]
+	return token, ok
+}
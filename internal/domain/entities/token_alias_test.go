@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTokenRegistryGroups(t *testing.T) {
+	native := Token{Address: common.HexToAddress("0xaf88d065e77c8cC2239327C5EDb3A432268e5831"), Symbol: "USDC", Decimals: 6}
+	bridged := Token{Address: common.HexToAddress("0xFF970A61A04b1cA14834A43f5dE4533eBDDB5CC8"), Symbol: "USDC", Decimals: 6}
+	r := NewTokenRegistry(ChainArbitrum)
+	r.Register(native)
+	r.Register(bridged)
+	if _, err := r.LookupSymbol("USDC"); !errors.Is(err, ErrAmbiguousSymbol) {
+		t.Fatalf("LookupSymbol() before groups error = %v", err)
+	}
+	if err := r.LoadGroups("../../../configs/token_groups.json"); err != nil {
+		t.Fatalf("LoadGroups() error = %v", err)
+	}
+
+	lookups := []struct {
+		symbol string
+		want   common.Address
+	}{
+		{"usdc", native.Address},       // Shared by the group, so canonical
+		{"USDC.e", bridged.Address},    // Alias symbol
+		{"usdce", bridged.Address},     // Alias symbol
+		{"ETH", NativeTokenAddress},    // Built-in gas token group
+		{"WETH", common.Address{}},     // Not registered
+		{"USDbC", common.Address{}},    // Another chain's alias
+		{"USDC.e.e", common.Address{}}, // Unknown
+		{"usdt", common.Address{}},     // Unknown
+	}
+	for _, tt := range lookups {
+		got, err := r.LookupSymbol(tt.symbol)
+		if (err == nil) != (tt.want != common.Address{}) || got.Address != tt.want {
+			t.Errorf("LookupSymbol(%q) = %s, %v, want %s", tt.symbol, got.Address.Hex(), err, tt.want.Hex())
+		}
+	}
+
+	if eq := r.Equivalents(bridged.Address); len(eq) != 1 || eq[0].Address != native.Address {
+		t.Errorf("Equivalents(USDC.e) = %v", eq)
+	}
+	if eq := r.Equivalents(native.Address); len(eq) != 1 || eq[0].Address != bridged.Address {
+		t.Errorf("Equivalents(USDC) = %v", eq)
+	}
+	// The gas token only wraps, so its wrapper has no pool equivalent
+	if eq := r.Equivalents(NativeCurrencies[ChainArbitrum].Wrapped.Address); len(eq) != 0 {
+		t.Errorf("Equivalents(WETH) = %v", eq)
+	}
+	if group, ok := r.GroupOf(NativeTokenAddress); !ok || group.Members[0].Rule != AliasWrap {
+		t.Errorf("GroupOf(ETH) = %+v, %v", group, ok)
+	}
+	if n := len(r.Groups()); n != 2 {
+		t.Errorf("%d groups, want the gas token's and USDC", n)
+	}
+}
+
+func TestTokenRegistryLoadGroupsRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups string
+	}{
+		{"unknown rule", `{"groups":[{"name":"x","canonical":"0x1111111111111111111111111111111111111111","members":[{"address":"0x2222222222222222222222222222222222222222","rule":"bridge"}]}]}`},
+		{"wrap without a wrapper", `{"groups":[{"name":"x","canonical":"0x1111111111111111111111111111111111111111","members":[{"address":"0x2222222222222222222222222222222222222222","rule":"wrap"}]}]}`},
+		{"token in two groups", `{"groups":[
+			{"name":"x","canonical":"0x1111111111111111111111111111111111111111","members":[{"address":"0x2222222222222222222222222222222222222222","rule":"swap"}]},
+			{"name":"y","canonical":"0x3333333333333333333333333333333333333333","members":[{"address":"0x2222222222222222222222222222222222222222","rule":"swap"}]}]}`},
+		{"invalid address", `{"groups":[{"name":"x","canonical":"0xnothex","members":[]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "groups.json")
+			if err := os.WriteFile(path, []byte(tt.groups), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := DefaultRegistry().LoadGroups(path); err == nil {
+				t.Error("LoadGroups() accepted the file")
+			}
+		})
+	}
+}
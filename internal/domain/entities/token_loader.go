@@ -1,9 +1,13 @@
 package entities
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -14,79 +18,329 @@ type TokenConfig struct {
 	Symbol   string `json:"symbol"`
 	Name     string `json:"name"`
 	Decimals uint8  `json:"decimals"`
+	ChainID  uint64 `json:"chainId,omitempty"` // Ethereum mainnet when unset
 }
 
 type TokensConfig struct {
 	Tokens []TokenConfig `json:"tokens"`
 }
 
+var (
+	ErrUnknownSymbol   = errors.New("unknown token symbol")
+	ErrAmbiguousSymbol = errors.New("ambiguous token symbol")
+)
+
+// TokenReader reads an unlisted token's metadata from the chain
+type TokenReader interface {
+	ReadToken(ctx context.Context, addr common.Address) (Token, error)
+}
+
+// TokenRegistry holds the tokens of one chain. The chain's gas token
+// resolves by symbol and at NativeTokenAddress without being registered.
+// It is safe for concurrent use, so one registry is shared by every
+// handler and can be reloaded while serving.
 type TokenRegistry struct {
+	chainID uint64
+	native  *NativeCurrency
+
+	mu        sync.RWMutex
+	builtin   []Token // Registered in code; kept across reloads
+	path      string  // Token list given to LoadFromFile, re-read by Reload
 	byAddress map[common.Address]Token
-	bySymbol  map[string]Token
+	bySymbol  map[string][]common.Address // keyed by upper-cased symbol
 	all       []Token
+
+	reader     TokenReader
+	discovered map[common.Address]Token // Read by Resolve; metadata doesn't change
+
+	groupPath    string // Group file given to LoadGroups, re-read by Reload
+	groups       []TokenGroup
+	groupOf      map[common.Address]int    // Index into groups
+	aliasSymbols map[string]common.Address // keyed by upper-cased symbol
 }
 
-func NewTokenRegistry() *TokenRegistry {
-	return &TokenRegistry{
-		byAddress: make(map[common.Address]Token),
-		bySymbol:  make(map[string]Token),
-		all:       make([]Token, 0),
+func NewTokenRegistry(chainID uint64) *TokenRegistry {
+	var native *NativeCurrency
+	if currency, ok := NativeCurrencies[chainID]; ok {
+		native = &currency
+	}
+	r := &TokenRegistry{
+		chainID:    chainID,
+		native:     native,
+		discovered: make(map[common.Address]Token),
 	}
+	r.reset()
+	r.setGroups(nil)
+	return r
 }
 
+// LoadFromFile adds the tokens for the registry's chain from a token list.
+// Nothing is added when any entry is invalid, but Reload still retries the
+// file.
 func (r *TokenRegistry) LoadFromFile(path string) error {
+	r.mu.Lock()
+	r.path = path
+	r.mu.Unlock()
+
+	tokens, err := r.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range tokens {
+		r.add(token)
+	}
+	return nil
+}
+
+// Reload replaces the tokens from the token list with its current contents, keeping the tokens registered in code, and re-reads the
+// token groups. The registry is unchanged when either file can't be read.
+func (r *TokenRegistry) Reload() error {
+	r.mu.RLock()
+	path, groupPath := r.path, r.groupPath
+	r.mu.RUnlock()
+	if path == "" {
+		return errors.New("no token list has been loaded")
+	}
+
+	tokens, err := r.readFile(path)
+	if err != nil {
+		return err
+	}
+	var groups []TokenGroup
+	if groupPath != "" {
+		if groups, err = r.readGroups(groupPath); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if groupPath != "" {
+		r.setGroups(groups)
+	}
+	r.reset()
+	for _, token := range r.builtin {
+		r.add(token)
+	}
+	for _, token := range tokens {
+		r.add(token)
+	}
+	return nil
+}
+
+// readFile parses a token list, keeping the entries for the registry's chain
+func (r *TokenRegistry) readFile(path string) ([]Token, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read token config: %w", err)
+		return nil, fmt.Errorf("failed to read token config: %w", err)
 	}
 
 	var config TokensConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse token config: %w", err)
+		return nil, fmt.Errorf("failed to parse token config: %w", err)
 	}
 
+	tokens := make([]Token, 0, len(config.Tokens))
 	for _, tc := range config.Tokens {
-		token := Token{
+		chainID := tc.ChainID
+		if chainID == 0 {
+			chainID = ChainEthereum
+		}
+		if chainID != r.chainID {
+			continue
+		}
+		if !common.IsHexAddress(tc.Address) {
+			return nil, fmt.Errorf("invalid address %q for token %s", tc.Address, tc.Symbol)
+		}
+		tokens = append(tokens, Token{
 			Address:  common.HexToAddress(tc.Address),
 			Symbol:   tc.Symbol,
 			Name:     tc.Name,
 			Decimals: tc.Decimals,
-		}
-		r.Register(token)
+		})
 	}
-
-	return nil
+	return tokens, nil
 }
 
+// Register adds a token, replacing any earlier entry for the same address
 func (r *TokenRegistry) Register(token Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.builtin = append(r.builtin, token)
+	r.add(token)
+}
+
+// reset empties the lookup tables. Callers hold r.mu.
+func (r *TokenRegistry) reset() {
+	r.byAddress = make(map[common.Address]Token)
+	r.bySymbol = make(map[string][]common.Address)
+	r.all = make([]Token, 0)
+}
+
+// add indexes token, replacing any entry for its address. Callers hold r.mu.
+func (r *TokenRegistry) add(token Token) {
+	if old, ok := r.byAddress[token.Address]; ok {
+		r.remove(old)
+	}
+	key := strings.ToUpper(token.Symbol)
 	r.byAddress[token.Address] = token
-	r.bySymbol[token.Symbol] = token
+	r.bySymbol[key] = append(r.bySymbol[key], token.Address)
 	r.all = append(r.all, token)
 }
 
+// remove drops token from the lookup tables. Callers hold r.mu.
+func (r *TokenRegistry) remove(token Token) {
+	key := strings.ToUpper(token.Symbol)
+	addrs := r.bySymbol[key][:0]
+	for _, addr := range r.bySymbol[key] {
+		if addr != token.Address {
+			addrs = append(addrs, addr)
+		}
+	}
+	r.bySymbol[key] = addrs
+
+	for i, t := range r.all {
+		if t.Address == token.Address {
+			r.all = append(r.all[:i], r.all[i+1:]...)
+			break
+		}
+	}
+}
+
+// ChainID returns the chain the registry's tokens are deployed on
+func (r *TokenRegistry) ChainID() uint64 {
+	return r.chainID
+}
+
+// Native returns the chain's gas token and its wrapper
+func (r *TokenRegistry) Native() (NativeCurrency, bool) {
+	if r.native == nil {
+		return NativeCurrency{}, false
+	}
+	return *r.native, true
+}
+
+// Wrap returns the token pools trade for token: the wrapper for the gas
+// token, token itself otherwise. native reports whether it was replaced.
+func (r *TokenRegistry) Wrap(token Token) (wrapped Token, native bool) {
+	if !token.IsNative() || r.native == nil {
+		return token, false
+	}
+	return r.native.Wrapped, true
+}
+
 func (r *TokenRegistry) GetByAddress(addr common.Address) (Token, bool) {
+	if addr == NativeTokenAddress && r.native != nil {
+		return r.native.Token(), true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	token, ok := r.byAddress[addr]
 	return token, ok
 }
 
+// SetReader lets Resolve read unlisted tokens from the chain
+func (r *TokenRegistry) SetReader(reader TokenReader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reader = reader
+}
+
+// Resolve returns the token at addr, reading an unlisted token's symbol and
+// decimals through the reader so 6- and 8-decimal tokens aren't taken for
+// 18. A token that can't be read is UnknownToken.
+func (r *TokenRegistry) Resolve(ctx context.Context, addr common.Address) Token {
+	if token, ok := r.GetByAddress(addr); ok {
+		return token
+	}
+	r.mu.RLock()
+	token, ok := r.discovered[addr]
+	reader := r.reader
+	r.mu.RUnlock()
+	if ok {
+		return token
+	}
+	if reader == nil {
+		return UnknownToken(addr)
+	}
+
+	token, err := reader.ReadToken(ctx, addr)
+	if err != nil {
+		return UnknownToken(addr)
+	}
+	r.mu.Lock()
+	r.discovered[addr] = token
+	r.mu.Unlock()
+	return token
+}
+
+// GetBySymbol returns the only token with the symbol, ignoring case
 func (r *TokenRegistry) GetBySymbol(symbol string) (Token, bool) {
-	token, ok := r.bySymbol[symbol]
-	return token, ok
+	token, err := r.LookupSymbol(symbol)
+	return token, err == nil
+}
+
+// LookupSymbol finds a token by case-insensitive symbol. Symbols shared by
+// several addresses return ErrAmbiguousSymbol, and callers must use an
+// address, unless the addresses are one token group, which resolves to its
+// canonical token. The gas token's symbol and the groups' alias symbols
+// match when no registered token has them.
+func (r *TokenRegistry) LookupSymbol(symbol string) (Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	addrs := r.bySymbol[strings.ToUpper(symbol)]
+	switch len(addrs) {
+	case 0:
+		if r.native != nil && strings.EqualFold(symbol, r.native.Symbol) {
+			return r.native.Token(), nil
+		}
+		if addr, ok := r.aliasSymbols[strings.ToUpper(symbol)]; ok {
+			if addr == NativeTokenAddress && r.native != nil {
+				return r.native.Token(), nil
+			}
+			if token, ok := r.byAddress[addr]; ok {
+				return token, nil
+			}
+		}
+		return Token{}, ErrUnknownSymbol
+	case 1:
+		return r.byAddress[addrs[0]], nil
+	default:
+		if token, ok := r.canonicalFor(addrs); ok {
+			return token, nil
+		}
+		return Token{}, ErrAmbiguousSymbol
+	}
 }
 
+// GetAll returns a copy of the registered tokens in registration order
 func (r *TokenRegistry) GetAll() []Token {
-	return r.all
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Token(nil), r.all...)
 }
 
 func (r *TokenRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return len(r.all)
 }
 
+// DefaultRegistry returns the Ethereum mainnet tokens the aggregator quotes
+// out of the box
 func DefaultRegistry() *TokenRegistry {
-	r := NewTokenRegistry()
+	r := NewTokenRegistry(ChainEthereum)
 	r.Register(WETH)
 	r.Register(USDC)
 	r.Register(USDT)
 	r.Register(DAI)
+	r.Register(SDAI)
+	r.Register(USDS)
+	r.Register(STETH)
+	r.Register(WSTETH)
+	r.Register(RETH)
 	return r
 }
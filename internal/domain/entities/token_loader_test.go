@@ -0,0 +1,213 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTokenRegistryLookupSymbol(t *testing.T) {
+	r := DefaultRegistry()
+	fake := Token{Address: common.HexToAddress("0x1111111111111111111111111111111111111111"), Symbol: "USDC", Decimals: 6}
+
+	tests := []struct {
+		name    string
+		extra   []Token
+		symbol  string
+		want    common.Address
+		wantErr error
+	}{
+		{"exact case", nil, "WETH", WETH.Address, nil},
+		{"case-insensitive", nil, "steth", STETH.Address, nil},
+		{"unknown", nil, "PEPE", common.Address{}, ErrUnknownSymbol},
+		{"re-registered address stays unique", []Token{WETH}, "weth", WETH.Address, nil},
+		{"shared symbol", []Token{fake}, "usdc", common.Address{}, ErrAmbiguousSymbol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, token := range tt.extra {
+				r.Register(token)
+			}
+			got, err := r.LookupSymbol(tt.symbol)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LookupSymbol(%q) error = %v, want %v", tt.symbol, err, tt.wantErr)
+			}
+			if got.Address != tt.want {
+				t.Errorf("LookupSymbol(%q) = %s, want %s", tt.symbol, got.Address.Hex(), tt.want.Hex())
+			}
+		})
+	}
+}
+
+func TestTokenRegistryLoadFromFile(t *testing.T) {
+	r := DefaultRegistry()
+	if err := r.LoadFromFile("../../../configs/tokens.json"); err != nil {
+		t.Fatalf("LoadFromFile() error = %v", err)
+	}
+	if token, ok := r.GetBySymbol("DAI"); !ok || token.Address != DAI.Address {
+		t.Errorf("DAI = %s, want %s", token.Address.Hex(), DAI.Address.Hex())
+	}
+	if _, ok := r.GetBySymbol("wbtc"); !ok {
+		t.Error("WBTC from the token list is not registered")
+	}
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	if err := os.WriteFile(path, []byte(`{"tokens":[{"address":"0xnothex","symbol":"BAD","decimals":18}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewTokenRegistry(ChainEthereum).LoadFromFile(path); err == nil {
+		t.Error("LoadFromFile() accepted an invalid address")
+	}
+}
+
+func TestTokenRegistryNative(t *testing.T) {
+	tests := []struct {
+		chainID uint64
+		symbol  string
+		wrapped common.Address
+	}{
+		{ChainEthereum, "eth", WETH.Address},
+		{ChainArbitrum, "ETH", common.HexToAddress("0x82aF49447D8a07e3bd95BD0d56f35241523fBab1")},
+		{ChainPolygon, "POL", common.HexToAddress("0x0d500B1d8E8eF31E21C99d1Db9A6444d3ADf1270")},
+		{ChainBSC, "bnb", common.HexToAddress("0xbb4CdB9CBd36B01bD1cBaEBF2De08d9173bc095c")},
+	}
+
+	for _, tt := range tests {
+		r := NewTokenRegistry(tt.chainID)
+		native, err := r.LookupSymbol(tt.symbol)
+		if err != nil || !native.IsNative() {
+			t.Fatalf("chain %d: LookupSymbol(%q) = %s, %v", tt.chainID, tt.symbol, native.Address.Hex(), err)
+		}
+		if byAddress, ok := r.GetByAddress(NativeTokenAddress); !ok || byAddress != native {
+			t.Errorf("chain %d: GetByAddress(native) = %+v", tt.chainID, byAddress)
+		}
+		wrapped, ok := r.Wrap(native)
+		if !ok || wrapped.Address != tt.wrapped {
+			t.Errorf("chain %d: Wrap() = %s, want %s", tt.chainID, wrapped.Address.Hex(), tt.wrapped.Hex())
+		}
+		if same, ok := r.Wrap(wrapped); ok || same != wrapped {
+			t.Errorf("chain %d: Wrap() replaced the wrapper", tt.chainID)
+		}
+	}
+
+	if _, ok := NewTokenRegistry(999).GetByAddress(NativeTokenAddress); ok {
+		t.Error("registry for an unknown chain resolved the native token")
+	}
+}
+
+func TestTokenRegistryLoadsOneChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	config := `{"tokens":[
+		{"address":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48","symbol":"USDC","decimals":6},
+		{"address":"0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359","symbol":"USDC","decimals":6,"chainId":137}
+	]}`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for chainID, want := range map[uint64]common.Address{
+		ChainEthereum: USDC.Address,
+		ChainPolygon:  common.HexToAddress("0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359"),
+	} {
+		r := NewTokenRegistry(chainID)
+		if err := r.LoadFromFile(path); err != nil {
+			t.Fatal(err)
+		}
+		if token, err := r.LookupSymbol("USDC"); err != nil || token.Address != want || r.Count() != 1 {
+			t.Errorf("chain %d: USDC = %s (%v, %d tokens), want %s", chainID, token.Address.Hex(), err, r.Count(), want.Hex())
+		}
+	}
+}
+
+func TestTokenRegistryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	write := func(config string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pepe := `{"address":"0x6982508145454Ce325dDbE47a25d4ec3d2311933","symbol":"PEPE","decimals":18}`
+	wbtc := `{"address":"0x2260FAC5E5542a773Aa44fBCFeDf7C193bc2C599","symbol":"WBTC","decimals":8}`
+
+	r := DefaultRegistry()
+	if err := r.Reload(); err == nil {
+		t.Error("Reload() succeeded before a token list was loaded")
+	}
+	write(`{"tokens":[` + pepe + `]}`)
+	if err := r.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Readers keep working while the list is swapped
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if _, ok := r.GetBySymbol("WETH"); !ok {
+					t.Error("built-in token missing during reload")
+					return
+				}
+				r.GetAll()
+			}
+		}()
+	}
+	write(`{"tokens":[` + wbtc + `]}`)
+	for i := 0; i < 10; i++ {
+		if err := r.Reload(); err != nil {
+			t.Fatalf("Reload() error = %v", err)
+		}
+	}
+	wg.Wait()
+
+	if _, ok := r.GetBySymbol("PEPE"); ok {
+		t.Error("token removed from the list is still registered")
+	}
+	if _, ok := r.GetBySymbol("wbtc"); !ok {
+		t.Error("token added to the list is not registered")
+	}
+
+	count := r.Count()
+	write(`{"tokens":[{"address":"0xnothex","symbol":"BAD"}]}`)
+	if err := r.Reload(); err == nil || r.Count() != count {
+		t.Errorf("Reload() of an invalid list: err = %v, %d tokens, want %d", err, r.Count(), count)
+	}
+}
+
+type fakeTokenReader map[common.Address]Token
+
+func (f fakeTokenReader) ReadToken(ctx context.Context, addr common.Address) (Token, error) {
+	if token, ok := f[addr]; ok {
+		return token, nil
+	}
+	return Token{}, errors.New("execution reverted")
+}
+
+func TestTokenRegistryResolve(t *testing.T) {
+	renBTC := Token{Address: common.HexToAddress("0xEB4C2781e4ebA804CE9a9803C67d0893436bB27D"), Symbol: "renBTC", Decimals: 8}
+	junk := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	r := DefaultRegistry()
+	if got := r.Resolve(context.Background(), renBTC.Address); got != UnknownToken(renBTC.Address) {
+		t.Errorf("Resolve() without a reader = %+v, want UnknownToken", got)
+	}
+
+	r.SetReader(fakeTokenReader{renBTC.Address: renBTC})
+	if got := r.Resolve(context.Background(), renBTC.Address); got.Decimals != 8 || got.Symbol != "renBTC" {
+		t.Errorf("Resolve() = %+v, want renBTC with 8 decimals", got)
+	}
+	if got := r.Resolve(context.Background(), WETH.Address); got != WETH {
+		t.Errorf("Resolve(WETH) = %+v, want the registered token", got)
+	}
+	if got := r.Resolve(context.Background(), junk); got != UnknownToken(junk) {
+		t.Errorf("Resolve() of an unreadable token = %+v, want UnknownToken", got)
+	}
+}
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenTax is what a simulated buy and sell through a token's WETH pool
+// showed the token keeping on top of the pool fee
+type TokenTax struct {
+	Pool           common.Address `json:"pool"`
+	BuyTaxBps      uint64         `json:"buyTaxBps"`
+	SellTaxBps     uint64         `json:"sellTaxBps"`
+	MaxTransaction *big.Int       `json:"maxTransaction,omitempty"` // Largest amount the pool could send in one buy, when the token caps it
+	CheckedAt      int64          `json:"checkedAt"`
+}
@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SwapTransaction is an unsigned transaction that executes a route
+type SwapTransaction struct {
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Data  []byte         `json:"data"`
+	Value *big.Int       `json:"value"`
+	Gas   uint64         `json:"gas,omitempty"`
+}
+
+// ProcessorRoute is a quote encoded for Sushi's RouteProcessor: the
+// route bytes an integrator's own processRoute call takes, and that call
+// built for the quote
+type ProcessorRoute struct {
+	Address     common.Address   `json:"address"`
+	Route       []byte           `json:"route"`
+	Transaction *SwapTransaction `json:"transaction"`
+}
+
+// IntegratorFee is a referral fee taken from a swap's output by the fee
+// collector and credited to Recipient
+type IntegratorFee struct {
+	Bps       uint64         `json:"bps"`
+	Recipient common.Address `json:"recipient"`
+	Amount    *big.Int       `json:"amount"` // Expected fee in the output token
+}
+
+// FeeAccrual is the total fee an integrator has been credited in one
+// token, and how much of it they have claimed
+type FeeAccrual struct {
+	Recipient common.Address `json:"recipient"`
+	Token     common.Address `json:"token"`
+	Amount    *big.Int       `json:"amount"`
+	Swaps     uint64         `json:"swaps"`
+	Claimed   *big.Int       `json:"claimed"`
+	Claims    uint64         `json:"claims"`
+}
+
+// Claimable is the part of the accrual not yet claimed
+func (a FeeAccrual) Claimable() *big.Int {
+	if a.Claimed == nil {
+		return new(big.Int).Set(a.Amount)
+	}
+	return new(big.Int).Sub(a.Amount, a.Claimed)
+}
+
+// FeeClaim is a withdrawal of an integrator's claimable fee in one token
+// from the fee collector, built for them to send
+type FeeClaim struct {
+	Recipient    common.Address   `json:"recipient"`
+	Token        common.Address   `json:"token"`
+	Amount       *big.Int         `json:"amount"`       // Claimable as of ThroughBlock
+	ThroughBlock uint64           `json:"throughBlock"` // Last block the ledger has scanned
+	Transaction  *SwapTransaction `json:"transaction"`
+}
+
+// FeeReconciliation compares what the ledger's logs say an integrator can
+// claim in one token with what the fee collector itself holds for them,
+// both as of one block
+type FeeReconciliation struct {
+	Token   common.Address `json:"token"`
+	Ledger  *big.Int       `json:"ledger"`
+	Onchain *big.Int       `json:"onchain"`
+}
+
+// Matches reports whether the ledger and the collector agree
+func (r FeeReconciliation) Matches() bool {
+	return r.Ledger.Cmp(r.Onchain) == 0
+}
+
+// GasCost prices a quote's gas estimate under EIP-1559 fee suggestions.
+// CostWei uses the expected effective price (base fee + priority fee).
+type GasCost struct {
+	BaseFeePerGas        *big.Int `json:"baseFeePerGas"`
+	MaxFeePerGas         *big.Int `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *big.Int `json:"maxPriorityFeePerGas"`
+	CostWei              *big.Int `json:"costWei"`
+	CostUSD              *big.Int `json:"costUsd,omitempty"` // 18 decimals
+}
+
+// TxStatus is the lifecycle state of a broadcast transaction
+type TxStatus string
+
+const (
+	TxPending   TxStatus = "pending"
+	TxMined     TxStatus = "mined" // Included, waiting for confirmations
+	TxConfirmed TxStatus = "confirmed"
+	TxFailed    TxStatus = "failed"
+)
+
+// ExecutionRecord tracks a swap broadcast by the execution service.
+// Replaced lists earlier hashes for the same nonce that were resubmitted.
+type ExecutionRecord struct {
+	Hash          common.Hash    `json:"hash"`
+	From          common.Address `json:"from"`
+	Nonce         uint64         `json:"nonce"`
+	TokenIn       Token          `json:"tokenIn"`
+	TokenOut      Token          `json:"tokenOut"`
+	AmountIn      *big.Int       `json:"amountIn"`
+	MinAmountOut  *big.Int       `json:"minAmountOut"`
+	Status        TxStatus       `json:"status"`
+	Replaced      []common.Hash  `json:"replaced,omitempty"`
+	BlockNumber   uint64         `json:"blockNumber,omitempty"`
+	Confirmations uint64         `json:"confirmations,omitempty"`
+	GasUsed       uint64         `json:"gasUsed,omitempty"`
+	SubmittedAt   int64          `json:"submittedAt"`
+}
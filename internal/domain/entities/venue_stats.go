@@ -0,0 +1,26 @@
+package entities
+
+// QuoteOutcomes counts how a venue's quotes ended
+type QuoteOutcomes struct {
+	Quotes      uint64  `json:"quotes"`
+	Successes   uint64  `json:"successes"`
+	Errors      uint64  `json:"errors"`      // The venue failed to answer, e.g. an RPC error
+	NoLiquidity uint64  `json:"noLiquidity"` // No pool, or one too small to quote
+	SuccessRate float64 `json:"successRate"` // Successes over quotes, 0-1
+}
+
+// VenueStats is a venue's quote outcomes over the stats window, in total
+// and per pair, and the state of its circuit breaker
+type VenueStats struct {
+	DEX DEXType `json:"dex"`
+	QuoteOutcomes
+	Circuit string           `json:"circuit"` // "closed", "open" or "half-open"
+	Pairs   []VenuePairStats `json:"pairs,omitempty"`
+}
+
+// VenuePairStats is a venue's quote outcomes for one pair, either way round
+type VenuePairStats struct {
+	Token0 Token `json:"token0"`
+	Token1 Token `json:"token1"`
+	QuoteOutcomes
+}
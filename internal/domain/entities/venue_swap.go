@@ -0,0 +1,34 @@
+package entities
+
+import "github.com/ethereum/go-ethereum/common"
+
+// CurveSwap is what a Curve pool's exchange call needs. Index0 and Index1
+// are the coin indexes of Token0 and Token1.
+type CurveSwap struct {
+	Index0 int `json:"index0"`
+	Index1 int `json:"index1"`
+	// Uint256 pools take uint256 indexes rather than int128
+	Uint256 bool `json:"uint256,omitempty"`
+	// Underlying pools swap the coins they lend out through
+	// exchange_underlying
+	Underlying bool `json:"underlying,omitempty"`
+	// ETH pools hold native ETH where the pair lists WETH, which is then
+	// paid in as the call's value and paid out as ETH
+	ETH bool `json:"eth,omitempty"`
+}
+
+// BalancerSwap locates a Balancer pair's pool in the Vault. Tokens reaching
+// a boosted pool through a linear pool carry that pool in Linear0 or
+// Linear1, as a step of its own in the Vault swap.
+type BalancerSwap struct {
+	PoolID  common.Hash         `json:"poolId"`
+	Linear0 *BalancerLinearStep `json:"linear0,omitempty"`
+	Linear1 *BalancerLinearStep `json:"linear1,omitempty"`
+}
+
+// BalancerLinearStep is a linear pool between a main token and the BPT
+// the boosted pool holds
+type BalancerLinearStep struct {
+	PoolID common.Hash    `json:"poolId"`
+	BPT    common.Address `json:"bpt"`
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WrapRoute is a token and the token wrapping it, converted by the
+// wrapper's contract at its rate rather than traded in a pool
+type WrapRoute struct {
+	Underlying Token
+	Wrapped    Token
+	DEX        DEXType // The venue quoting the conversion
+}
+
+// WrapRoutes are the wraps quoted as virtual pools. wstETH and sDAI wrap
+// at a rate that grows with staking and DSR yield; WETH wraps 1:1.
+var WrapRoutes = []WrapRoute{
+	{Underlying: NativeCurrencies[ChainEthereum].Token(), Wrapped: WETH, DEX: DEXWrapper},
+	{Underlying: STETH, Wrapped: WSTETH, DEX: DEXLido},
+	{Underlying: DAI, Wrapped: SDAI, DEX: DEXWrapper},
+}
+
+// WrapCounterparts returns the tokens addr wraps or is wrapped by, so a
+// route can convert into one before or after trading. The gas token is
+// left out, since no pool holds it.
+func WrapCounterparts(addr common.Address) []Token {
+	var counterparts []Token
+	for _, wrap := range WrapRoutes {
+		switch addr {
+		case wrap.Underlying.Address:
+			counterparts = append(counterparts, wrap.Wrapped)
+		case wrap.Wrapped.Address:
+			if !wrap.Underlying.IsNative() {
+				counterparts = append(counterparts, wrap.Underlying)
+			}
+		}
+	}
+	return counterparts
+}
+
+// WrapRate prices a wrap: Rate underlying tokens per wrapped token, as
+// 18-decimal fixed point. Both tokens have 18 decimals.
+type WrapRate struct {
+	Wrapped common.Address `json:"wrapped"`
+	Rate    *big.Int       `json:"rate"`
+}
+
+// amountOut rounds down, as the wrapper contracts do
+func (w *WrapRate) amountOut(amountIn *big.Int, tokenIn common.Address) *big.Int {
+	if w.Rate == nil || w.Rate.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	if tokenIn == w.Wrapped {
+		out := new(big.Int).Mul(amountIn, w.Rate)
+		return out.Div(out, fixedOne)
+	}
+	out := new(big.Int).Mul(amountIn, fixedOne)
+	return out.Div(out, w.Rate)
+}
+
+// amountIn rounds up, so converting it yields at least amountOut
+func (w *WrapRate) amountIn(amountOut *big.Int, tokenIn common.Address) *big.Int {
+	if w.Rate == nil || w.Rate.Sign() <= 0 {
+		return nil
+	}
+	numerator, denominator := new(big.Int).Mul(amountOut, w.Rate), fixedOne
+	if tokenIn == w.Wrapped {
+		numerator, denominator = new(big.Int).Mul(amountOut, fixedOne), w.Rate
+	}
+	numerator.Add(numerator, denominator)
+	numerator.Sub(numerator, big.NewInt(1))
+	return numerator.Div(numerator, denominator)
+}
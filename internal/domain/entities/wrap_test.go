@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestWrapRate(t *testing.T) {
+	// 1.1 DAI per sDAI
+	pair := &Pair{
+		Token0: DAI, Token1: SDAI,
+		Reserve0: big.NewInt(11e17), Reserve1: big.NewInt(1e18),
+		Wrap: &WrapRate{Wrapped: SDAI.Address, Rate: big.NewInt(11e17)},
+	}
+
+	tests := []struct {
+		name      string
+		tokenIn   Token
+		amount    int64
+		wantOut   int64
+		wantInFor int64 // Input GetAmountIn asks for the same output
+	}{
+		{"redeem", SDAI, 10, 11, 10},
+		{"deposit rounds down", DAI, 10, 9, 10},
+		{"large", SDAI, 1e18, 11e17, 1e18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := pair.GetAmountOut(big.NewInt(tt.amount), tt.tokenIn.Address)
+			if out.Int64() != tt.wantOut {
+				t.Fatalf("GetAmountOut() = %s, want %d", out, tt.wantOut)
+			}
+			in := pair.GetAmountIn(out, tt.tokenIn.Address)
+			if in == nil || in.Int64() != tt.wantInFor {
+				t.Errorf("GetAmountIn(%s) = %v, want %d", out, in, tt.wantInFor)
+			}
+		})
+	}
+}
+
+func TestWrapCounterparts(t *testing.T) {
+	tests := []struct {
+		name  string
+		token Token
+		want  []Token
+	}{
+		{"wraps", STETH, []Token{WSTETH}},
+		{"unwraps", SDAI, []Token{DAI}},
+		{"gas token left out", WETH, nil},
+		{"no wrap", USDC, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapCounterparts(tt.token.Address)
+			if len(got) != len(tt.want) {
+				t.Fatalf("WrapCounterparts() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Address != tt.want[i].Address {
+					t.Errorf("WrapCounterparts()[%d] = %s, want %s", i, got[i].Symbol, tt.want[i].Symbol)
+				}
+			}
+		})
+	}
+}
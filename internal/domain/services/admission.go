@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Tier ranks callers for admission; a higher tier is let in first when the
+// RPC budget is spent
+type Tier int
+
+const (
+	TierAnonymous Tier = iota
+	TierKey            // Any valid API key
+	TierPriority       // Keys flagged as priority, e.g. internal consumers
+	numTiers
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierKey:
+		return "key"
+	case TierPriority:
+		return "priority"
+	default:
+		return "anonymous"
+	}
+}
+
+var (
+	ErrQueueFull    = errors.New("admission queue is full")
+	ErrQueueTimeout = errors.New("timed out waiting for admission")
+)
+
+// AdmissionStats is one tier's traffic since startup. QueueTime covers
+// requests that had to wait, admitted or not.
+type AdmissionStats struct {
+	InFlight     int           `json:"inFlight"`
+	Queued       int           `json:"queued"`
+	Admitted     int64         `json:"admitted"`
+	Rejected     int64         `json:"rejected"` // Queue full or waited too long
+	Waited       int64         `json:"waited"`
+	QueueTime    time.Duration `json:"queueTimeNs"`
+	MaxQueueTime time.Duration `json:"maxQueueTimeNs"`
+}
+
+type admissionWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// AdmissionController caps how many requests run at once, since each fans
+// out into RPC calls. Requests over the cap wait in a queue per tier and are
+// admitted highest tier first, in arrival order within a tier. A tier limit
+// caps that tier alone, leaving the rest of the capacity to the others.
+type AdmissionController struct {
+	capacity int
+	limits   [numTiers]int
+	maxQueue int
+	maxWait  time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	queues   [numTiers][]*admissionWaiter
+	stats    [numTiers]AdmissionStats
+}
+
+// NewAdmissionController admits up to capacity requests at once. A tier
+// missing from limits, or limited to 0, may use all of it. maxQueue bounds
+// each tier's queue and maxWait how long a request stays in it.
+func NewAdmissionController(capacity int, limits map[Tier]int, maxQueue int, maxWait time.Duration) *AdmissionController {
+	c := &AdmissionController{
+		capacity: capacity,
+		maxQueue: maxQueue,
+		maxWait:  maxWait,
+	}
+	for tier := Tier(0); tier < numTiers; tier++ {
+		c.limits[tier] = capacity
+		if limit := limits[tier]; limit > 0 && limit < capacity {
+			c.limits[tier] = limit
+		}
+	}
+	return c
+}
+
+// Acquire waits for a slot and returns the func that gives it back. It fails
+// with ErrQueueFull, ErrQueueTimeout or ctx's error.
+func (c *AdmissionController) Acquire(ctx context.Context, tier Tier) (release func(), err error) {
+	if tier < 0 || tier >= numTiers {
+		tier = TierAnonymous
+	}
+
+	c.mu.Lock()
+	if len(c.queues[tier]) == 0 && c.canRun(tier) {
+		c.admit(tier)
+		c.mu.Unlock()
+		return c.releaser(tier), nil
+	}
+	if len(c.queues[tier]) >= c.maxQueue {
+		c.stats[tier].Rejected++
+		c.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	waiter := &admissionWaiter{ready: make(chan struct{})}
+	c.queues[tier] = append(c.queues[tier], waiter)
+	c.stats[tier].Queued++
+	c.mu.Unlock()
+
+	start := time.Now()
+	timer := time.NewTimer(c.maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-waiter.ready:
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recordWait(tier, time.Since(start))
+	if waiter.granted {
+		// A slot may have been handed over just as the wait ended
+		return c.releaser(tier), nil
+	}
+	c.dequeue(tier, waiter)
+	c.stats[tier].Rejected++
+	return nil, err
+}
+
+// Stats returns every tier's counters, keyed by tier name
+func (c *AdmissionController) Stats() map[string]AdmissionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]AdmissionStats, numTiers)
+	for tier := Tier(0); tier < numTiers; tier++ {
+		stats[tier.String()] = c.stats[tier]
+	}
+	return stats
+}
+
+func (c *AdmissionController) canRun(tier Tier) bool {
+	return c.inFlight < c.capacity && c.stats[tier].InFlight < c.limits[tier]
+}
+
+func (c *AdmissionController) admit(tier Tier) {
+	c.inFlight++
+	c.stats[tier].InFlight++
+	c.stats[tier].Admitted++
+}
+
+func (c *AdmissionController) recordWait(tier Tier, waited time.Duration) {
+	stats := &c.stats[tier]
+	stats.Waited++
+	stats.QueueTime += waited
+	if waited > stats.MaxQueueTime {
+		stats.MaxQueueTime = waited
+	}
+}
+
+func (c *AdmissionController) dequeue(tier Tier, waiter *admissionWaiter) {
+	queue := c.queues[tier]
+	for i, w := range queue {
+		if w == waiter {
+			c.queues[tier] = append(queue[:i], queue[i+1:]...)
+			c.stats[tier].Queued--
+			return
+		}
+	}
+}
+
+// releaser frees the slot once, then hands free slots to the queues from
+// the highest tier down
+func (c *AdmissionController) releaser(tier Tier) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+
+			c.inFlight--
+			c.stats[tier].InFlight--
+			for t := numTiers - 1; t >= 0; t-- {
+				for len(c.queues[t]) > 0 && c.canRun(t) {
+					waiter := c.queues[t][0]
+					c.queues[t] = c.queues[t][1:]
+					c.stats[t].Queued--
+					c.admit(t)
+					waiter.granted = true
+					close(waiter.ready)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdmissionPrefersHigherTiers(t *testing.T) {
+	c := NewAdmissionController(1, nil, 10, time.Second)
+	ctx := context.Background()
+
+	release, err := c.Acquire(ctx, TierAnonymous)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	order := make(chan Tier, 2)
+	acquire := func(tier Tier) {
+		rel, err := c.Acquire(ctx, tier)
+		if err != nil {
+			t.Errorf("Acquire(%s) error = %v", tier, err)
+			return
+		}
+		order <- tier
+		rel()
+	}
+	go acquire(TierAnonymous)
+	waitQueued(t, c, "anonymous", 1)
+	go acquire(TierPriority)
+	waitQueued(t, c, "priority", 1)
+
+	release()
+	if first, second := <-order, <-order; first != TierPriority || second != TierAnonymous {
+		t.Errorf("admitted %s then %s, want priority then anonymous", first, second)
+	}
+	if stats := c.Stats()["anonymous"]; stats.Admitted != 2 || stats.Waited != 1 || stats.InFlight != 0 {
+		t.Errorf("anonymous stats = %+v", stats)
+	}
+}
+
+func TestAdmissionTierLimit(t *testing.T) {
+	c := NewAdmissionController(3, map[Tier]int{TierAnonymous: 1}, 0, time.Second)
+	ctx := context.Background()
+
+	if _, err := c.Acquire(ctx, TierAnonymous); err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	// The anonymous tier is at its limit while capacity is left for keys
+	if _, err := c.Acquire(ctx, TierAnonymous); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("second anonymous Acquire() error = %v, want %v", err, ErrQueueFull)
+	}
+	if _, err := c.Acquire(ctx, TierKey); err != nil {
+		t.Errorf("key Acquire() error = %v", err)
+	}
+}
+
+func TestAdmissionQueueTimeout(t *testing.T) {
+	c := NewAdmissionController(1, nil, 1, 20*time.Millisecond)
+	ctx := context.Background()
+
+	release, _ := c.Acquire(ctx, TierKey)
+	defer release()
+	if _, err := c.Acquire(ctx, TierKey); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("Acquire() error = %v, want %v", err, ErrQueueTimeout)
+	}
+	stats := c.Stats()["key"]
+	if stats.Rejected != 1 || stats.Queued != 0 || stats.MaxQueueTime < 20*time.Millisecond {
+		t.Errorf("key stats = %+v", stats)
+	}
+}
+
+func waitQueued(t *testing.T, c *AdmissionController, tier string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for c.Stats()[tier].Queued < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s queue never reached %d", tier, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Errors returned by APIKeyService
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrQuotaExceeded  = errors.New("daily quota exceeded")
+)
+
+// apiKeyPrefix marks issued keys, which read dxa_<id>_<secret>
+const apiKeyPrefix = "dxa_"
+
+// APIKeyStore persists keys and their usage counters. Day is a UTC date,
+// e.g. 2024-05-01. GetKey returns nil, nil for an unknown ID.
+type APIKeyStore interface {
+	SaveKey(ctx context.Context, key entities.APIKey) error
+	GetKey(ctx context.Context, id string) (*entities.APIKey, error)
+	ListKeys(ctx context.Context) ([]entities.APIKey, error)
+	DeleteKey(ctx context.Context, id string) error
+	// IncrRequests counts a request and returns the day's total
+	IncrRequests(ctx context.Context, id, day string) (int64, error)
+	IncrQuotes(ctx context.Context, id, day string, volumeUSDCents int64) error
+	Usage(ctx context.Context, id, day string) (entities.APIKeyUsage, error)
+}
+
+// APIKeyUpdate changes the fields that are set
+type APIKeyUpdate struct {
+	Name       *string
+	DailyQuota *int64
+	Disabled   *bool
+	Priority   *bool
+}
+
+// APIKeyService issues integrator API keys, enforces their daily quotas and
+// accounts for the quotes they are served
+type APIKeyService struct {
+	store        APIKeyStore
+	priceService *PriceService
+	now          func() time.Time
+}
+
+func NewAPIKeyService(store APIKeyStore, priceService *PriceService) *APIKeyService {
+	return &APIKeyService{
+		store:        store,
+		priceService: priceService,
+		now:          time.Now,
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *APIKeyService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Issue creates a key and returns it with its full secret, which is not
+// stored and can't be recovered later
+func (s *APIKeyService) Issue(ctx context.Context, name string, dailyQuota int64) (*entities.APIKey, string, error) {
+	if dailyQuota < 0 {
+		return nil, "", fmt.Errorf("daily quota must not be negative")
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := entities.APIKey{
+		ID:         id,
+		Name:       name,
+		SecretHash: hashSecret(secret),
+		DailyQuota: dailyQuota,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.store.SaveKey(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to save api key: %w", err)
+	}
+	return &key, apiKeyPrefix + id + "_" + secret, nil
+}
+
+func (s *APIKeyService) Get(ctx context.Context, id string) (*entities.APIKey, error) {
+	key, err := s.store.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context) ([]entities.APIKey, error) {
+	return s.store.ListKeys(ctx)
+}
+
+func (s *APIKeyService) Update(ctx context.Context, id string, update APIKeyUpdate) (*entities.APIKey, error) {
+	key, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		key.Name = *update.Name
+	}
+	if update.DailyQuota != nil {
+		if *update.DailyQuota < 0 {
+			return nil, fmt.Errorf("daily quota must not be negative")
+		}
+		key.DailyQuota = *update.DailyQuota
+	}
+	if update.Disabled != nil {
+		key.Disabled = *update.Disabled
+	}
+	if update.Priority != nil {
+		key.Priority = *update.Priority
+	}
+	if err := s.store.SaveKey(ctx, *key); err != nil {
+		return nil, fmt.Errorf("failed to save api key: %w", err)
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.store.DeleteKey(ctx, id)
+}
+
+func (s *APIKeyService) Usage(ctx context.Context, id string) (entities.APIKeyUsage, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return entities.APIKeyUsage{}, err
+	}
+	return s.store.Usage(ctx, id, usageDay(s.now()))
+}
+
+// Authenticate resolves a presented key and counts the request against its
+// daily quota
+func (s *APIKeyService) Authenticate(ctx context.Context, presented string) (*entities.APIKey, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(presented, apiKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(presented, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.store.GetKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.Disabled || subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	requests, err := s.store.IncrRequests(ctx, id, usageDay(s.now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count request: %w", err)
+	}
+	if key.DailyQuota > 0 && requests > key.DailyQuota {
+		return key, ErrQuotaExceeded
+	}
+	return key, nil
+}
+
+// RecordQuote counts a quote served to keyID and the USD value of its input.
+// Tokens without a USD price count towards quotes but not volume.
+func (s *APIKeyService) RecordQuote(ctx context.Context, keyID string, quote *entities.Quote) {
+	if err := s.store.IncrQuotes(ctx, keyID, usageDay(s.now()), s.volumeCents(ctx, quote)); err != nil {
+		log.Printf("api keys: failed to record quote for %s: %v", keyID, err)
+	}
+}
+
+// volumeCents values the quote's input in USD cents, or 0 when unpriced
+func (s *APIKeyService) volumeCents(ctx context.Context, quote *entities.Quote) int64 {
+	if s.priceService == nil {
+		return 0
+	}
+	price, err := s.priceService.GetTokenPrice(ctx, quote.TokenIn)
+	if err != nil || price == nil {
+		return 0
+	}
+	// amountIn * price (18 decimals) / 10^decimals, in cents
+	value := new(big.Int).Mul(quote.AmountIn, price)
+	value.Div(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(quote.TokenIn.Decimals)+16), nil))
+	if !value.IsInt64() {
+		return 0
+	}
+	return value.Int64()
+}
+
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
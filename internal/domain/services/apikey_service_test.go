@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/keystore"
+)
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(keystore.NewMemoryStore(), nil)
+
+	key, secret, err := svc.Issue(ctx, "partner", 2)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !strings.HasPrefix(secret, apiKeyPrefix+key.ID+"_") {
+		t.Fatalf("secret %q does not embed key id %s", secret, key.ID)
+	}
+	if strings.Contains(key.SecretHash, strings.TrimPrefix(secret, apiKeyPrefix+key.ID+"_")) {
+		t.Fatal("stored key contains the plain secret")
+	}
+
+	tests := []struct {
+		name      string
+		presented string
+		wantErr   error
+	}{
+		{"valid", secret, nil},
+		{"second request within quota", secret, nil},
+		{"over quota", secret, ErrQuotaExceeded},
+		{"wrong secret", apiKeyPrefix + key.ID + "_deadbeef", ErrInvalidAPIKey},
+		{"unknown id", apiKeyPrefix + "0000_" + "deadbeef", ErrInvalidAPIKey},
+		{"malformed", "not-a-key", ErrInvalidAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Authenticate(ctx, tt.presented)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	usage, err := svc.Usage(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.Requests != 3 || usage.RequestsToday != 3 {
+		t.Errorf("requests = %d/%d today, want 3/3", usage.Requests, usage.RequestsToday)
+	}
+}
+
+func TestAPIKeyServiceDisabledKey(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(keystore.NewMemoryStore(), nil)
+
+	key, secret, err := svc.Issue(ctx, "partner", 0)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	disabled := true
+	if _, err := svc.Update(ctx, key.ID, APIKeyUpdate{Disabled: &disabled}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("Authenticate() error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+
+	if err := svc.Delete(ctx, key.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := svc.Get(ctx, key.ID); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Fatalf("Get() after delete error = %v, want %v", err, ErrAPIKeyNotFound)
+	}
+}
+
+func TestAPIKeyServiceRecordQuote(t *testing.T) {
+	ctx := context.Background()
+	svc := NewAPIKeyService(keystore.NewMemoryStore(), nil)
+
+	key, _, err := svc.Issue(ctx, "partner", 0)
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	quote := &entities.Quote{
+		TokenIn:  entities.Token{Symbol: "UNPRICED", Decimals: 18},
+		AmountIn: big.NewInt(1e18),
+	}
+	svc.RecordQuote(ctx, key.ID, quote)
+	svc.RecordQuote(ctx, key.ID, quote)
+
+	usage, err := svc.Usage(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.Quotes != 2 || usage.QuotesToday != 2 {
+		t.Errorf("quotes = %d/%d today, want 2/2", usage.Quotes, usage.QuotesToday)
+	}
+	if usage.VolumeUSDCents != 0 {
+		t.Errorf("volume = %d cents, want 0 without prices", usage.VolumeUSDCents)
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var (
+	// allowance(address,address)
+	allowanceSelector = common.Hex2Bytes("dd62ed3e")
+	// approve(address,uint256)
+	approveSelector = common.Hex2Bytes("095ea7b3")
+	// allowance(address owner, address token, address spender) on Permit2
+	permit2AllowanceSelector = crypto.Keccak256([]byte("allowance(address,address,address)"))[:4]
+	// isBlackListed(address) on USDT, isBlacklisted(address) on USDC and its
+	// FiatToken clones
+	blacklistSelectors = [][]byte{common.Hex2Bytes("e47d6060"), common.Hex2Bytes("fe575a87")}
+)
+
+// ApprovalBuilder encodes the approvals a swap needs
+type ApprovalBuilder interface {
+	BuildApprovals(owner, token, spender common.Address, allowance, amount *big.Int, quirks entities.TokenQuirks) []*entities.SwapTransaction
+	BuildPermit2Approval(owner, permit2, token, spender common.Address, amount *big.Int, expiration uint64) *entities.SwapTransaction
+}
+
+// ApprovalService reads allowances and probes tokens for non-standard
+// approve behaviour so swaps come with the approvals they need
+type ApprovalService struct {
+	builder ApprovalBuilder
+	caller  ContractCaller
+}
+
+func NewApprovalService(builder ApprovalBuilder, caller ContractCaller) *ApprovalService {
+	return &ApprovalService{
+		builder: builder,
+		caller:  caller,
+	}
+}
+
+// Plan returns the approvals owner must send before spender can pull
+// amount of token. Known quirks are merged with what simulating approve
+// from owner shows.
+func (s *ApprovalService) Plan(ctx context.Context, token, owner, spender common.Address, amount *big.Int) (*entities.ApprovalPlan, error) {
+	allowance, err := s.allowance(ctx, token, owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowance: %w", err)
+	}
+
+	quirks := entities.KnownTokenQuirks[token]
+	if allowance.Cmp(amount) < 0 {
+		s.probeApprove(ctx, token, owner, spender, amount, allowance, &quirks)
+	}
+
+	frozen, blacklistable := s.blacklisted(ctx, token, owner)
+	if blacklistable {
+		quirks.Blacklistable = true
+	}
+
+	return &entities.ApprovalPlan{
+		Token:     token,
+		Owner:     owner,
+		Spender:   spender,
+		Allowance: allowance,
+		Amount:    amount,
+		Quirks:    quirks,
+		Frozen:    frozen,
+		Steps:     s.builder.BuildApprovals(owner, token, spender, allowance, amount, quirks),
+	}, nil
+}
+
+// PlanPermit2 returns the approvals owner must send before spender can pull
+// amount of token through Permit2 until expiration: the token's approval of
+// Permit2, then Permit2's approval of spender when its allowance is short
+// or lapses sooner. That last approval also comes as a permit owner can
+// sign instead.
+func (s *ApprovalService) PlanPermit2(ctx context.Context, token, owner, permit2, spender common.Address, amount *big.Int, expiration time.Time) (*entities.ApprovalPlan, error) {
+	plan, err := s.Plan(ctx, token, owner, permit2, amount)
+	if err != nil {
+		return nil, err
+	}
+	plan.Permit2Spender = &spender
+
+	data := make([]byte, 4+32*3)
+	copy(data[0:4], permit2AllowanceSelector)
+	copy(data[16:36], owner.Bytes())
+	copy(data[48:68], token.Bytes())
+	copy(data[80:100], spender.Bytes())
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &permit2, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Permit2 allowance: %w", err)
+	}
+	if len(result) < 96 {
+		return nil, fmt.Errorf("Permit2 allowance returned %d bytes", len(result))
+	}
+	allowed := new(big.Int).SetBytes(result[0:32])
+	expires := new(big.Int).SetBytes(result[32:64])
+	if allowed.Cmp(amount) < 0 || expires.Cmp(big.NewInt(expiration.Unix())) < 0 {
+		plan.Steps = append(plan.Steps, s.builder.BuildPermit2Approval(owner, permit2, token, spender, amount, uint64(expiration.Unix())))
+		plan.Permit = &entities.Permit2Permit{
+			Token:       token,
+			Amount:      amount,
+			Expiration:  uint64(expiration.Unix()),
+			Nonce:       new(big.Int).SetBytes(result[64:96]).Uint64(),
+			Spender:     spender,
+			SigDeadline: uint64(expiration.Unix()),
+		}
+	}
+	return plan, nil
+}
+
+// Frozen reports whether token has blacklisted account. Tokens without a
+// known blacklist getter are never frozen.
+func (s *ApprovalService) Frozen(ctx context.Context, token, account common.Address) bool {
+	frozen, _ := s.blacklisted(ctx, token, account)
+	return frozen
+}
+
+func (s *ApprovalService) allowance(ctx context.Context, token, owner, spender common.Address) (*big.Int, error) {
+	data := make([]byte, 4+32*2)
+	copy(data[0:4], allowanceSelector)
+	copy(data[16:36], owner.Bytes())
+	copy(data[48:68], spender.Bytes())
+
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("allowance returned %d bytes", len(result))
+	}
+	return new(big.Int).SetBytes(result[0:32]), nil
+}
+
+// probeApprove simulates approve(spender, amount) from owner the way
+// SafeERC20 checks it. An empty return marks a token that returns no bool;
+// a revert or false over an existing allowance marks one that has to be
+// reset to zero first.
+func (s *ApprovalService) probeApprove(ctx context.Context, token, owner, spender common.Address, amount, allowance *big.Int, quirks *entities.TokenQuirks) {
+	data := make([]byte, 4+32*2)
+	copy(data[0:4], approveSelector)
+	copy(data[16:36], spender.Bytes())
+	amount.FillBytes(data[36:68])
+
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{
+		From: owner,
+		To:   &token,
+		Data: data,
+	})
+	switch {
+	case err != nil || (len(result) >= 32 && new(big.Int).SetBytes(result[0:32]).Sign() == 0):
+		// With no allowance to replace the failure is something else, such
+		// as a paused token
+		if allowance.Sign() > 0 {
+			quirks.ApproveResetRequired = true
+		}
+	case len(result) == 0:
+		quirks.NoReturnValue = true
+	}
+}
+
+// blacklisted asks token whether account is frozen through the common
+// blacklist getters. blacklistable is false when the token has neither.
+func (s *ApprovalService) blacklisted(ctx context.Context, token, account common.Address) (frozen, blacklistable bool) {
+	for _, selector := range blacklistSelectors {
+		data := make([]byte, 4+32)
+		copy(data[0:4], selector)
+		copy(data[16:36], account.Bytes())
+
+		result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data})
+		if err != nil || len(result) < 32 {
+			continue
+		}
+		return new(big.Int).SetBytes(result[0:32]).Sign() != 0, true
+	}
+	return false, false
+}
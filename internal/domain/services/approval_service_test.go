@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+// mockToken answers allowance, approve and a USDC-style isBlacklisted
+type mockToken struct {
+	allowance     *big.Int
+	approveResult []byte
+	approveErr    error
+	blacklist     bool
+	frozen        bool
+
+	// Permit2's allowance of the spender, when the mock also plays Permit2
+	permit2Amount  *big.Int
+	permit2Expires int64
+	permit2Nonce   int64
+}
+
+func (m *mockToken) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+
+	switch selector := msg.Data[:4]; {
+	case bytes.Equal(selector, permit2AllowanceSelector) && m.permit2Amount != nil:
+		return append(append(common.LeftPadBytes(m.permit2Amount.Bytes(), 32), word(m.permit2Expires)...), word(m.permit2Nonce)...), nil
+	case bytes.Equal(selector, allowanceSelector):
+		return common.LeftPadBytes(m.allowance.Bytes(), 32), nil
+	case bytes.Equal(selector, approveSelector):
+		return m.approveResult, m.approveErr
+	case bytes.Equal(selector, blacklistSelectors[1]) && m.blacklist:
+		if m.frozen {
+			return word(1), nil
+		}
+		return word(0), nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestApprovalServicePlan(t *testing.T) {
+	unknown := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	amount := big.NewInt(1000)
+	ok := common.LeftPadBytes([]byte{1}, 32)
+
+	tests := []struct {
+		name       string
+		token      common.Address
+		mock       *mockToken
+		wantQuirks entities.TokenQuirks
+		wantFrozen bool
+		wantSteps  []*big.Int // Approved amounts, in order
+	}{
+		{
+			name:      "standard",
+			token:     unknown,
+			mock:      &mockToken{allowance: big.NewInt(0), approveResult: ok},
+			wantSteps: []*big.Int{amount},
+		},
+		{
+			name:  "allowance covers swap",
+			token: unknown,
+			mock:  &mockToken{allowance: big.NewInt(5000), approveResult: ok},
+		},
+		{
+			name:       "reset required",
+			token:      unknown,
+			mock:       &mockToken{allowance: big.NewInt(10), approveErr: errors.New("execution reverted")},
+			wantQuirks: entities.TokenQuirks{ApproveResetRequired: true},
+			wantSteps:  []*big.Int{big.NewInt(0), amount},
+		},
+		{
+			name:       "no return value",
+			token:      unknown,
+			mock:       &mockToken{allowance: big.NewInt(0)},
+			wantQuirks: entities.TokenQuirks{NoReturnValue: true},
+			wantSteps:  []*big.Int{amount},
+		},
+		{
+			name:       "frozen owner",
+			token:      unknown,
+			mock:       &mockToken{allowance: big.NewInt(0), approveResult: ok, blacklist: true, frozen: true},
+			wantQuirks: entities.TokenQuirks{Blacklistable: true},
+			wantFrozen: true,
+			wantSteps:  []*big.Int{amount},
+		},
+		{
+			name:       "known USDT over an allowance",
+			token:      entities.USDT.Address,
+			mock:       &mockToken{allowance: big.NewInt(10)},
+			wantQuirks: entities.KnownTokenQuirks[entities.USDT.Address],
+			wantSteps:  []*big.Int{big.NewInt(0), amount},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewApprovalService(swap.NewBuilder(), tt.mock)
+			spender := swap.UniswapV2RouterAddress
+
+			plan, err := service.Plan(context.Background(), tt.token, owner, spender, amount)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if plan.Quirks != tt.wantQuirks {
+				t.Errorf("quirks = %+v, want %+v", plan.Quirks, tt.wantQuirks)
+			}
+			if plan.Frozen != tt.wantFrozen {
+				t.Errorf("frozen = %v, want %v", plan.Frozen, tt.wantFrozen)
+			}
+			if len(plan.Steps) != len(tt.wantSteps) {
+				t.Fatalf("got %d steps, want %d", len(plan.Steps), len(tt.wantSteps))
+			}
+			for i, step := range plan.Steps {
+				if step.To != tt.token || step.From != owner {
+					t.Errorf("step %d sends %s -> %s", i, step.From.Hex(), step.To.Hex())
+				}
+				if got := common.BytesToAddress(step.Data[4:36]); got != spender {
+					t.Errorf("step %d spender = %s", i, got.Hex())
+				}
+				if got := new(big.Int).SetBytes(step.Data[36:68]); got.Cmp(tt.wantSteps[i]) != 0 {
+					t.Errorf("step %d amount = %s, want %s", i, got, tt.wantSteps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApprovalServicePlanPermit2(t *testing.T) {
+	token := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	amount := big.NewInt(1000)
+	expiration := time.Unix(1700000000, 0)
+	ok := common.LeftPadBytes([]byte{1}, 32)
+
+	tests := []struct {
+		name       string
+		mock       *mockToken
+		wantSteps  int
+		wantPermit bool // The last step is Permit2's approval of the router
+	}{
+		{"first swap", &mockToken{allowance: big.NewInt(0), approveResult: ok, permit2Amount: big.NewInt(0), permit2Nonce: 4}, 2, true},
+		{"Permit2 approved", &mockToken{allowance: big.NewInt(5000), approveResult: ok, permit2Amount: big.NewInt(0)}, 1, true},
+		{"router allowance lapses first", &mockToken{allowance: big.NewInt(5000), permit2Amount: big.NewInt(5000), permit2Expires: expiration.Unix() - 1}, 1, true},
+		{"both allowances cover the swap", &mockToken{allowance: big.NewInt(5000), permit2Amount: big.NewInt(5000), permit2Expires: expiration.Unix()}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewApprovalService(swap.NewBuilder(), tt.mock)
+			plan, err := service.PlanPermit2(context.Background(), token, owner, swap.Permit2Address, swap.UniversalRouterAddress, amount, expiration)
+			if err != nil {
+				t.Fatalf("PlanPermit2() error = %v", err)
+			}
+			if plan.Spender != swap.Permit2Address || plan.Permit2Spender == nil || *plan.Permit2Spender != swap.UniversalRouterAddress {
+				t.Errorf("spender = %s via %v, want Permit2 allowing the Universal Router", plan.Spender.Hex(), plan.Permit2Spender)
+			}
+			if len(plan.Steps) != tt.wantSteps {
+				t.Fatalf("got %d steps, want %d", len(plan.Steps), tt.wantSteps)
+			}
+			if !tt.wantPermit {
+				if plan.Permit != nil {
+					t.Errorf("permit = %+v, want none once Permit2 allows the router", plan.Permit)
+				}
+				return
+			}
+			want := entities.Permit2Permit{
+				Token: token, Amount: amount, Expiration: uint64(expiration.Unix()), Nonce: uint64(tt.mock.permit2Nonce),
+				Spender: swap.UniversalRouterAddress, SigDeadline: uint64(expiration.Unix()),
+			}
+			if permit := plan.Permit; permit == nil || permit.Amount.Cmp(amount) != 0 || permit.Token != want.Token || permit.Expiration != want.Expiration ||
+				permit.Nonce != want.Nonce || permit.Spender != want.Spender || permit.SigDeadline != want.SigDeadline {
+				t.Errorf("permit = %+v, want %+v", plan.Permit, want)
+			}
+			last := plan.Steps[len(plan.Steps)-1]
+			if last.To != swap.Permit2Address || common.BytesToAddress(last.Data[4:36]) != token || common.BytesToAddress(last.Data[36:68]) != swap.UniversalRouterAddress {
+				t.Errorf("last step = %x to %s, want Permit2 approving the router", last.Data, last.To.Hex())
+			}
+			if got := new(big.Int).SetBytes(last.Data[100:132]); got.Int64() != expiration.Unix() {
+				t.Errorf("Permit2 expiration = %s, want %d", got, expiration.Unix())
+			}
+		})
+	}
+}
+
+func TestSwapServiceAttachPermittedTransaction(t *testing.T) {
+	owner := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	route := &entities.Route{
+		Hops: []entities.Hop{{
+			Pair:     entities.Pair{DEX: entities.DEXUniswapV2, Fee: 30},
+			TokenIn:  entities.WETH.Address,
+			TokenOut: entities.USDC.Address,
+		}},
+		TokenIn:  entities.WETH,
+		TokenOut: entities.USDC,
+		AmountIn: big.NewInt(1e18),
+	}
+	newQuote := func() *entities.Quote {
+		return &entities.Quote{TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1e18), BestRoute: route, ExpiresAt: time.Unix(1700000000, 0)}
+	}
+
+	// Permit2 holds an approval of the token, but hasn't allowed the router
+	mock := &mockToken{allowance: big.NewInt(2e18), permit2Amount: big.NewInt(0), permit2Nonce: 7}
+	service := NewSwapService(swap.NewBuilder(), testutil.NewFakeChain(150000, big.NewInt(1), big.NewInt(1)))
+	service.SetApprovalService(NewApprovalService(swap.NewBuilder(), mock))
+	service.SetUniversalRouter(swap.NewUniversalRouter(swap.UniversalRouterAddress, swap.Permit2Address))
+
+	unsigned := newQuote()
+	if err := service.AttachTransaction(context.Background(), unsigned, owner, owner); err != nil {
+		t.Fatalf("AttachTransaction() error = %v", err)
+	}
+	if plan := unsigned.Approval; plan == nil || len(plan.Steps) != 1 || plan.Permit == nil || plan.Permit.Nonce != 7 {
+		t.Fatalf("approval = %+v, want Permit2's approval with a permit to sign instead", unsigned.Approval)
+	}
+
+	signed := &entities.Permit2Permit{Expiration: 1700000000, Nonce: 7, SigDeadline: 1700000000, Signature: bytes.Repeat([]byte{1}, 65)}
+	quote := newQuote()
+	if err := service.AttachPermittedTransaction(context.Background(), quote, owner, owner, signed); err != nil {
+		t.Fatalf("AttachPermittedTransaction() error = %v", err)
+	}
+	if signed.Token != entities.WETH.Address || signed.Amount.Cmp(quote.AmountIn) != 0 || signed.Spender != swap.UniversalRouterAddress {
+		t.Errorf("permit = %+v, want the quote's input allowed to the router", signed)
+	}
+	if plan := quote.Approval; plan == nil || len(plan.Steps) != 0 || plan.Permit != signed {
+		t.Errorf("approval = %+v, want the signed permit in place of Permit2's approval", quote.Approval)
+	}
+	if quote.Transaction == nil || len(quote.Transaction.Data) <= len(unsigned.Transaction.Data) {
+		t.Error("transaction doesn't carry the permit")
+	}
+
+	quote = newQuote()
+	quote.IntegratorFee = &entities.IntegratorFee{Bps: 10}
+	if err := service.AttachPermittedTransaction(context.Background(), quote, owner, owner, signed); err == nil {
+		t.Error("AttachPermittedTransaction() took a permit for a swap through the fee collector")
+	}
+}
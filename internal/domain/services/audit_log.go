@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// AuditLogStore is append-only storage for audit log entries
+type AuditLogStore interface {
+	Append(ctx context.Context, entries []entities.AuditLogEntry) error
+	// Search returns the entries matching filter, newest first
+	Search(ctx context.Context, filter entities.AuditLogFilter) ([]entities.AuditLogEntry, error)
+}
+
+// AuditLog keeps a sample of quote requests as they were answered, for
+// incident reviews. Like QuoteRecorder it writes in the background and
+// drops entries rather than slow a request down.
+type AuditLog struct {
+	store      AuditLogStore
+	sampleRate float64
+	batchSize  int
+	queue      chan entities.AuditLogEntry
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewAuditLog keeps sampleRate of requests, in (0, 1], buffering up to
+// queueSize entries and writing them in batches of at most batchSize
+func NewAuditLog(store AuditLogStore, sampleRate float64, queueSize, batchSize int) *AuditLog {
+	return &AuditLog{
+		store:      store,
+		sampleRate: sampleRate,
+		batchSize:  batchSize,
+		queue:      make(chan entities.AuditLogEntry, queueSize),
+	}
+}
+
+// Sampled decides whether a request is logged, so the entry is only built
+// for the requests that are
+func (a *AuditLog) Sampled() bool {
+	return rand.Float64() < a.sampleRate
+}
+
+// Record queues an entry
+func (a *AuditLog) Record(entry entities.AuditLogEntry) {
+	select {
+	case a.queue <- entry:
+	default:
+		a.dropped.Add(1)
+	}
+}
+
+// Search returns the stored entries matching filter, newest first
+func (a *AuditLog) Search(ctx context.Context, filter entities.AuditLogFilter) ([]entities.AuditLogEntry, error) {
+	return a.store.Search(ctx, filter)
+}
+
+// Dropped returns how many entries were discarded because the queue was full
+func (a *AuditLog) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Failed returns how many entries were lost to store errors
+func (a *AuditLog) Failed() uint64 {
+	return a.failed.Load()
+}
+
+// Run writes a batch whenever batchSize entries are queued or interval
+// passes, and flushes what is left when ctx is cancelled
+func (a *AuditLog) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]entities.AuditLogEntry, 0, a.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			batch = a.drain(batch)
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			a.flush(flushCtx, batch)
+			cancel()
+			return
+		case entry := <-a.queue:
+			batch = append(batch, entry)
+			if len(batch) >= a.batchSize {
+				a.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			a.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// drain moves every queued entry into batch
+func (a *AuditLog) drain(batch []entities.AuditLogEntry) []entities.AuditLogEntry {
+	for {
+		select {
+		case entry := <-a.queue:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+}
+
+func (a *AuditLog) flush(ctx context.Context, batch []entities.AuditLogEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := a.store.Append(ctx, batch); err != nil {
+		a.failed.Add(uint64(len(batch)))
+		log.Printf("audit log: failed to write %d entries: %v", len(batch), err)
+	}
+}
+
+// NewAuditLogQuote captures a served quote's chosen routes and every
+// venue's quote
+func NewAuditLogQuote(quote *entities.Quote) entities.AuditLogQuote {
+	return entities.AuditLogQuote{
+		QuoteID: quote.ID,
+		Route:   NewQuoteAudit(quote),
+		Venues:  quote.SourceDetails,
+	}
+}
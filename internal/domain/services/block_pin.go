@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+// ErrBlockAhead is returned for a pin past the chain head
+var ErrBlockAhead = errors.New("block is ahead of the chain head")
+
+// PinBlock returns ctx with its pool reads pinned to block, or to the
+// current head for 0. Each venue is otherwise read at whatever block its
+// node call lands on, so Sources can compare different states. Pinned
+// reads skip live pairs and cached pairs from other blocks.
+func (s *PriceService) PinBlock(ctx context.Context, block uint64) (context.Context, error) {
+	if s.head == nil {
+		return nil, errors.New("block pinning needs the chain head")
+	}
+	head, err := s.head.BlockNumber(ethereum.WithBlock(ctx, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head block: %w", err)
+	}
+	switch {
+	case block == 0:
+		block = head
+	case block > head:
+		return nil, fmt.Errorf("%w: %d, head is %d", ErrBlockAhead, block, head)
+	}
+	return ethereum.WithBlock(ctx, block), nil
+}
+
+// PinBlock pins the quotes made with the returned context to block, see
+// PriceService.PinBlock
+func (s *RouterService) PinBlock(ctx context.Context, block uint64) (context.Context, error) {
+	return s.priceService.PinBlock(ctx, block)
+}
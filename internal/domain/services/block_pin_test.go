@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestPriceServicePinBlock(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	newPair := func(block uint64) *entities.Pair {
+		return &entities.Pair{
+			Token0: token0, Token1: token1, DEX: entities.DEXUniswapV2, Fee: 30,
+			Reserve0: big.NewInt(1e18), Reserve1: big.NewInt(1e18), BlockNumber: block,
+		}
+	}
+
+	if _, err := NewPriceService(nil, nil).PinBlock(context.Background(), 0); err == nil {
+		t.Error("PinBlock() without a head succeeded")
+	}
+
+	c := cache.NewInMemoryCache()
+	key := cache.PairCacheKey(entities.DEXUniswapV2, token0.Address.Hex(), token1.Address.Hex())
+	if err := c.SetPair(context.Background(), key, newPair(110), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	venue := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	venue.SetPair(newPair(100))
+	priceService := NewPriceService([]dex.DEXClient{venue}, c)
+	priceService.SetFreshnessGuard(mockHead(110), 2)
+
+	ctx, err := priceService.PinBlock(context.Background(), 0)
+	if err != nil || ethereum.PinnedBlock(ctx) != 110 {
+		t.Fatalf("PinBlock(0) = block %d, %v, want the head", ethereum.PinnedBlock(ctx), err)
+	}
+	if _, err := priceService.PinBlock(context.Background(), 111); !errors.Is(err, ErrBlockAhead) {
+		t.Errorf("PinBlock() past the head error = %v, want ErrBlockAhead", err)
+	}
+
+	// The cached pair is from another block than the pin, so the venue is
+	// read; the guard measures age from the pin, not the head
+	ctx, err = priceService.PinBlock(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("PinBlock(100) error = %v", err)
+	}
+	results, err := priceService.GetPrices(ctx, token0, token1, big.NewInt(1e15))
+	if err != nil || results[0].Error != nil {
+		t.Fatalf("GetPrices() pinned error = %v, %v", err, results[0].Error)
+	}
+	if results[0].Pair.BlockNumber != 100 || venue.Reads(token0.Address, token1.Address) != 1 {
+		t.Errorf("pinned read pair from block %d after %d venue reads, want block 100 read once",
+			results[0].Pair.BlockNumber, venue.Reads(token0.Address, token1.Address))
+	}
+	if cached, _ := c.GetPair(context.Background(), key); cached.BlockNumber != 110 {
+		t.Errorf("cached pair block = %d, want the pinned read left out of the cache", cached.BlockNumber)
+	}
+}
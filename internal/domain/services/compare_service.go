@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/reference"
+)
+
+// CompareService measures routing quality by quoting a swap ourselves and
+// through external aggregators at the same time
+type CompareService struct {
+	routerService *RouterService
+	references    []reference.Quoter
+	chainID       uint64
+}
+
+func NewCompareService(routerService *RouterService, references []reference.Quoter, chainID uint64) *CompareService {
+	return &CompareService{
+		routerService: routerService,
+		references:    references,
+		chainID:       chainID,
+	}
+}
+
+// Compare returns our smart quote with every reference's output and delta.
+// A failing reference is reported, not fatal; our own quote failing is.
+func (s *CompareService) Compare(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.QuoteComparison, error) {
+	references := make([]entities.ReferenceQuote, len(s.references))
+	var wg sync.WaitGroup
+	for i, quoter := range s.references {
+		wg.Add(1)
+		go func(idx int, q reference.Quoter) {
+			defer wg.Done()
+			start := time.Now()
+			amountOut, err := q.Quote(ctx, s.chainID, tokenIn.Address, tokenOut.Address, amountIn)
+			references[idx] = entities.ReferenceQuote{
+				Source:    q.Name(),
+				AmountOut: amountOut,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				references[idx].Error = err.Error()
+			}
+		}(i, quoter)
+	}
+
+	quote, err := s.routerService.GetSmartQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range references {
+		ref := &references[i]
+		if ref.AmountOut == nil || ref.AmountOut.Sign() <= 0 {
+			continue
+		}
+		ref.Delta = new(big.Int).Sub(quote.AmountOut, ref.AmountOut)
+		ref.DeltaBps = deltaBps(quote.AmountOut, ref.AmountOut)
+	}
+
+	return &entities.QuoteComparison{
+		Quote:      quote,
+		References: references,
+	}, nil
+}
+
+// deltaBps is (ours - theirs) / theirs in basis points
+func deltaBps(ours, theirs *big.Int) int64 {
+	delta := new(big.Int).Sub(ours, theirs)
+	delta.Mul(delta, big.NewInt(10000))
+	delta.Quo(delta, theirs)
+	if !delta.IsInt64() {
+		// Only reachable when the reference quotes dust
+		return math.MaxInt64
+	}
+	return delta.Int64()
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/reference"
+)
+
+type fixedQuoter struct {
+	name      string
+	amountOut *big.Int
+	err       error
+}
+
+func (q fixedQuoter) Name() string { return q.name }
+
+func (q fixedQuoter) Quote(ctx context.Context, chainID uint64, tokenIn, tokenOut common.Address, amountIn *big.Int) (*big.Int, error) {
+	return q.amountOut, q.err
+}
+
+func TestCompareServiceCompare(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+
+	client := NewMockDEXClient(entities.DEXUniswapV2)
+	client.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, DEX: entities.DEXUniswapV2, Fee: 30,
+		Reserve0: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+	})
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{client}, &MockCache{}))
+
+	amountIn := big.NewInt(1e18)
+	quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, amountIn, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours := quote.AmountOut
+
+	// References 1% below and 1% above our output, plus one that fails
+	worse := new(big.Int).Div(new(big.Int).Mul(ours, big.NewInt(100)), big.NewInt(101))
+	better := new(big.Int).Div(new(big.Int).Mul(ours, big.NewInt(101)), big.NewInt(100))
+	compareService := NewCompareService(routerService, []reference.Quoter{
+		fixedQuoter{name: "worse", amountOut: worse},
+		fixedQuoter{name: "better", amountOut: better},
+		fixedQuoter{name: "down", err: errors.New("status 503")},
+	}, 1)
+
+	comparison, err := compareService.Compare(context.Background(), token0, token1, amountIn, 50)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if comparison.Quote.AmountOut.Cmp(ours) != 0 {
+		t.Errorf("our amountOut = %s, want %s", comparison.Quote.AmountOut, ours)
+	}
+
+	want := map[string]int64{"worse": 100, "better": -99}
+	for _, ref := range comparison.References {
+		if ref.Source == "down" {
+			if ref.Error == "" || ref.Delta != nil {
+				t.Errorf("failed reference = %+v, want an error and no delta", ref)
+			}
+			continue
+		}
+		if ref.DeltaBps != want[ref.Source] {
+			t.Errorf("%s deltaBps = %d, want %d", ref.Source, ref.DeltaBps, want[ref.Source])
+		}
+		if new(big.Int).Add(ref.AmountOut, ref.Delta).Cmp(ours) != 0 {
+			t.Errorf("%s delta = %s does not reconcile with amountOut", ref.Source, ref.Delta)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/bridge"
+)
+
+// Rough inclusion time for a swap leg on either side of the bridge
+const swapLegTimeSec = 12
+
+// CrossChainService quotes swaps between chains by composing a source swap
+// into a bridge asset, a bridge transfer and a destination swap. A swap leg
+// needs a router for its chain; without one the token on that side must be
+// the bridge asset itself.
+type CrossChainService struct {
+	adapters     []bridge.Adapter
+	routers      map[uint64]*RouterService
+	priceService *PriceService // Mainnet pricing for USD fee totals
+	feeService   *FeeService   // Mainnet gas pricing for swap legs
+}
+
+func NewCrossChainService(adapters []bridge.Adapter, routers map[uint64]*RouterService, priceService *PriceService, feeService *FeeService) *CrossChainService {
+	return &CrossChainService{
+		adapters:     adapters,
+		routers:      routers,
+		priceService: priceService,
+		feeService:   feeService,
+	}
+}
+
+// GetQuote tries every bridge asset available on both chains and returns the
+// composition with the highest output
+func (s *CrossChainService) GetQuote(ctx context.Context, srcChainID uint64, tokenIn entities.Token, dstChainID uint64, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.CrossChainQuote, error) {
+	if srcChainID == dstChainID {
+		return nil, fmt.Errorf("source and destination chain are the same; use /quote")
+	}
+	if slippageBps == 0 {
+		slippageBps = DefaultSlippageBps
+	}
+
+	var best *entities.CrossChainQuote
+	var lastErr error
+	for _, asset := range entities.BridgeAssets {
+		quote, err := s.quoteVia(ctx, asset, srcChainID, tokenIn, dstChainID, tokenOut, amountIn, slippageBps)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if best == nil || quote.AmountOut.Cmp(best.AmountOut) > 0 {
+			best = quote
+		}
+	}
+
+	if best == nil {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no bridge asset connects chains %d and %d", srcChainID, dstChainID)
+		}
+		return nil, lastErr
+	}
+
+	s.attachFeeUSD(ctx, best)
+	return best, nil
+}
+
+// quoteVia composes a cross-chain quote that bridges through asset
+func (s *CrossChainService) quoteVia(ctx context.Context, asset entities.BridgeAsset, srcChainID uint64, tokenIn entities.Token, dstChainID uint64, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.CrossChainQuote, error) {
+	srcAsset, ok := asset.Token(srcChainID)
+	if !ok {
+		return nil, fmt.Errorf("%s is not bridgeable from chain %d", asset.Symbol, srcChainID)
+	}
+	dstAsset, ok := asset.Token(dstChainID)
+	if !ok {
+		return nil, fmt.Errorf("%s is not bridgeable to chain %d", asset.Symbol, dstChainID)
+	}
+
+	result := &entities.CrossChainQuote{
+		SrcChainID:  srcChainID,
+		DstChainID:  dstChainID,
+		TokenIn:     tokenIn,
+		TokenOut:    tokenOut,
+		AmountIn:    amountIn,
+		SlippageBps: slippageBps,
+	}
+
+	// Source leg: tokenIn -> bridge asset
+	bridgeAmount := amountIn
+	if tokenIn.Address != srcAsset.Address {
+		swap, err := s.swapOn(ctx, srcChainID, tokenIn, srcAsset, amountIn, slippageBps)
+		if err != nil {
+			return nil, err
+		}
+		result.SourceSwap = swap
+		result.EstimatedTimeSec += swapLegTimeSec
+		bridgeAmount = swap.AmountOut
+	}
+
+	// Bridge leg
+	bridgeQuote, err := s.bestBridgeQuote(ctx, bridge.Request{
+		SrcChainID: srcChainID,
+		DstChainID: dstChainID,
+		TokenIn:    srcAsset,
+		TokenOut:   dstAsset,
+		AmountIn:   bridgeAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Bridge = bridgeQuote
+	result.EstimatedTimeSec += bridgeQuote.EstimatedTimeSec
+	result.AmountOut = bridgeQuote.AmountOut
+
+	// Destination leg: bridge asset -> tokenOut
+	if tokenOut.Address != dstAsset.Address {
+		swap, err := s.swapOn(ctx, dstChainID, dstAsset, tokenOut, bridgeQuote.AmountOut, slippageBps)
+		if err != nil {
+			return nil, err
+		}
+		result.DestinationSwap = swap
+		result.EstimatedTimeSec += swapLegTimeSec
+		result.AmountOut = swap.AmountOut
+	}
+
+	// Slippage applies once to the end-to-end output
+	minAmountOut := new(big.Int).Mul(result.AmountOut, big.NewInt(10000-int64(slippageBps)))
+	result.MinAmountOut = minAmountOut.Div(minAmountOut, big.NewInt(10000))
+
+	return result, nil
+}
+
+func (s *CrossChainService) swapOn(ctx context.Context, chainID uint64, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.Quote, error) {
+	router, ok := s.routers[chainID]
+	if !ok {
+		return nil, fmt.Errorf("no swap routing on chain %d for %s -> %s", chainID, tokenIn.Symbol, tokenOut.Symbol)
+	}
+	quote, err := router.GetSmartQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if chainID == entities.ChainEthereum && s.feeService != nil {
+		// Gas pricing is best-effort, as for single-chain quotes
+		_ = s.feeService.AttachGasCost(ctx, quote)
+	}
+	return quote, nil
+}
+
+// bestBridgeQuote queries all adapters concurrently and keeps the best output
+func (s *CrossChainService) bestBridgeQuote(ctx context.Context, req bridge.Request) (*entities.BridgeQuote, error) {
+	quotes := make([]*entities.BridgeQuote, len(s.adapters))
+	errs := make([]error, len(s.adapters))
+
+	var wg sync.WaitGroup
+	for i, adapter := range s.adapters {
+		wg.Add(1)
+		go func(i int, adapter bridge.Adapter) {
+			defer wg.Done()
+			quotes[i], errs[i] = adapter.Quote(ctx, req)
+		}(i, adapter)
+	}
+	wg.Wait()
+
+	var best *entities.BridgeQuote
+	for _, q := range quotes {
+		if q != nil && q.AmountOut.Sign() > 0 && (best == nil || q.AmountOut.Cmp(best.AmountOut) > 0) {
+			best = q
+		}
+	}
+	if best == nil {
+		for _, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("no bridge quote for %s: %w", req.TokenIn.Symbol, err)
+			}
+		}
+		return nil, fmt.Errorf("no bridge quote for %s", req.TokenIn.Symbol)
+	}
+	return best, nil
+}
+
+// attachFeeUSD totals the bridge fee and mainnet swap gas in USD. It is
+// best-effort: legs that cannot be priced are left out.
+func (s *CrossChainService) attachFeeUSD(ctx context.Context, quote *entities.CrossChainQuote) {
+	if s.priceService == nil {
+		return
+	}
+
+	total := new(big.Int)
+	priced := false
+
+	// Bridge assets share a symbol across chains, so price the mainnet twin
+	if mainnetAsset, ok := mainnetBridgeToken(quote.Bridge.TokenIn.Symbol); ok {
+		if price, err := s.priceService.GetTokenPrice(ctx, mainnetAsset); err == nil {
+			// feeUsd = fee * price / 10^decimals
+			feeUSD := new(big.Int).Mul(quote.Bridge.Fee, price)
+			feeUSD.Div(feeUSD, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(mainnetAsset.Decimals)), nil))
+			total.Add(total, feeUSD)
+			priced = true
+		}
+	}
+
+	for _, swap := range []*entities.Quote{quote.SourceSwap, quote.DestinationSwap} {
+		if swap != nil && swap.GasCost != nil && swap.GasCost.CostUSD != nil {
+			total.Add(total, swap.GasCost.CostUSD)
+			priced = true
+		}
+	}
+
+	if priced {
+		quote.TotalFeeUSD = total
+	}
+}
+
+func mainnetBridgeToken(symbol string) (entities.Token, bool) {
+	for _, asset := range entities.BridgeAssets {
+		if asset.Symbol == symbol {
+			return asset.Token(entities.ChainEthereum)
+		}
+	}
+	return entities.Token{}, false
+}
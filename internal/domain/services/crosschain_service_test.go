@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/bridge"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// mockBridge charges feeBps on USDC transfers and rejects other assets
+type mockBridge struct {
+	name   string
+	feeBps int64
+}
+
+func (m *mockBridge) Name() string {
+	return m.name
+}
+
+func (m *mockBridge) Quote(ctx context.Context, req bridge.Request) (*entities.BridgeQuote, error) {
+	if req.TokenIn.Symbol != entities.USDC.Symbol {
+		return nil, fmt.Errorf("%s: unsupported asset", m.name)
+	}
+	fee := new(big.Int).Mul(req.AmountIn, big.NewInt(m.feeBps))
+	fee.Div(fee, big.NewInt(10000))
+	return &entities.BridgeQuote{
+		Bridge:           m.name,
+		TokenIn:          req.TokenIn,
+		TokenOut:         req.TokenOut,
+		AmountIn:         req.AmountIn,
+		AmountOut:        new(big.Int).Sub(req.AmountIn, fee),
+		Fee:              fee,
+		EstimatedTimeSec: 60,
+	}, nil
+}
+
+func TestCrossChainServiceGetQuote(t *testing.T) {
+	token := entities.Token{
+		Address:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		Symbol:   "TOKEN",
+		Decimals: 6,
+	}
+
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(token.Address, entities.USDC.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   token,
+		Token1:   entities.USDC,
+		Reserve0: big.NewInt(1e12),
+		Reserve1: big.NewInt(1e12),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	// The WETH leg is quoted too, but the mock bridges reject it
+	mockV2.SetPair(token.Address, entities.WETH.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x2222"),
+		Token0:   token,
+		Token1:   entities.WETH,
+		Reserve0: big.NewInt(1e12),
+		Reserve1: big.NewInt(1e18),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+
+	service := NewCrossChainService(
+		[]bridge.Adapter{&mockBridge{name: "slow", feeBps: 20}, &mockBridge{name: "fast", feeBps: 5}},
+		map[uint64]*RouterService{entities.ChainEthereum: router},
+		nil, nil,
+	)
+
+	arbUSDC, _ := entities.BridgeAssets[0].Token(entities.ChainArbitrum)
+	quote, err := service.GetQuote(context.Background(), entities.ChainEthereum, token, entities.ChainArbitrum, arbUSDC, big.NewInt(1e6), 50)
+	if err != nil {
+		t.Fatalf("GetQuote() error = %v", err)
+	}
+
+	if quote.SourceSwap == nil || quote.DestinationSwap != nil {
+		t.Errorf("legs = source %v, destination %v; want source swap only", quote.SourceSwap != nil, quote.DestinationSwap != nil)
+	}
+	if quote.Bridge.Bridge != "fast" {
+		t.Errorf("bridge = %s, want fast", quote.Bridge.Bridge)
+	}
+	if quote.Bridge.AmountIn.Cmp(quote.SourceSwap.AmountOut) != 0 || quote.AmountOut.Cmp(quote.Bridge.AmountOut) != 0 {
+		t.Errorf("amounts do not chain: swap out %s, bridge in %s, bridge out %s, total out %s",
+			quote.SourceSwap.AmountOut, quote.Bridge.AmountIn, quote.Bridge.AmountOut, quote.AmountOut)
+	}
+	if quote.EstimatedTimeSec != swapLegTimeSec+60 {
+		t.Errorf("EstimatedTimeSec = %d, want %d", quote.EstimatedTimeSec, swapLegTimeSec+60)
+	}
+
+	// No router on Arbitrum, so a non-bridge-asset output cannot be reached
+	opUSDC, _ := entities.BridgeAssets[0].Token(entities.ChainOptimism)
+	if _, err := service.GetQuote(context.Background(), entities.ChainOptimism, opUSDC, entities.ChainArbitrum, token, big.NewInt(1e6), 50); err == nil {
+		t.Error("GetQuote() without a destination router succeeded, want error")
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// DefaultDepegThresholdBps flags a stablecoin more than 1% from $1
+const DefaultDepegThresholdBps = 100
+
+// monitoredStablecoins are cross-checked against each other
+var monitoredStablecoins = []entities.Token{entities.USDC, entities.USDT, entities.DAI}
+
+// DepegMonitor prices each stablecoin in USDC, takes the median as the
+// dollar and flags stablecoins that deviate from it by more than the
+// threshold. Using the median keeps a USDC depeg from looking like every
+// other stablecoin depegging.
+type DepegMonitor struct {
+	priceService *PriceService
+	thresholdBps int64
+
+	mu   sync.RWMutex
+	pegs map[common.Address]entities.StablecoinPeg
+}
+
+func NewDepegMonitor(priceService *PriceService, thresholdBps int64) *DepegMonitor {
+	return &DepegMonitor{
+		priceService: priceService,
+		thresholdBps: thresholdBps,
+		pegs:         make(map[common.Address]entities.StablecoinPeg),
+	}
+}
+
+// Check refreshes the cross rates. Stablecoins without a quote against USDC
+// keep their previous state.
+func (m *DepegMonitor) Check(ctx context.Context) error {
+	one := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+	// USD value of each stablecoin with USDC as the unit, 18 decimals
+	inUSDC := map[common.Address]*big.Int{entities.USDC.Address: one}
+	for _, stable := range monitoredStablecoins[1:] {
+		// 1000 tokens keeps fees and rounding small relative to the threshold
+		amountIn := new(big.Int).Mul(big.NewInt(1000), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(stable.Decimals)), nil))
+		best, err := m.priceService.GetBestPrice(ctx, stable, entities.USDC, amountIn)
+		if err != nil || best.AmountOut.Sign() <= 0 {
+			continue
+		}
+		// amountOut is in USDC's decimals for 1000 tokens
+		rate := scaleTo18(best.AmountOut, entities.USDC.Decimals)
+		inUSDC[stable.Address] = rate.Div(rate, big.NewInt(1000))
+	}
+	if len(inUSDC) < 3 {
+		// Two stablecoins can't say which one moved
+		return fmt.Errorf("only %d of %d stablecoins priced", len(inUSDC), len(monitoredStablecoins))
+	}
+
+	dollar := medianBigInt(slices.Collect(maps.Values(inUSDC)))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, stable := range monitoredStablecoins {
+		price := new(big.Int).Mul(inUSDC[stable.Address], one)
+		price.Div(price, dollar)
+
+		deviation := new(big.Int).Sub(price, one)
+		deviation.Mul(deviation, big.NewInt(10000))
+		deviation.Quo(deviation, one)
+		bps := deviation.Int64()
+
+		peg := entities.StablecoinPeg{
+			Token:        stable.Address,
+			Symbol:       stable.Symbol,
+			PriceUSD:     price,
+			DeviationBps: bps,
+			Depegged:     bps > m.thresholdBps || bps < -m.thresholdBps,
+		}
+		if previous := m.pegs[stable.Address]; previous.Depegged != peg.Depegged {
+			if peg.Depegged {
+				log.Printf("Depeg: %s at $%s (%d bps)", stable.Symbol, formatUSD(price), bps)
+			} else {
+				log.Printf("Depeg recovered: %s at $%s", stable.Symbol, formatUSD(price))
+			}
+		}
+		m.pegs[stable.Address] = peg
+	}
+	return nil
+}
+
+// Run checks every interval until ctx is cancelled
+func (m *DepegMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Check(ctx); err != nil {
+			log.Printf("Warning: depeg check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PegPrice returns a stablecoin's USD value with 18 decimals. Unknown or
+// unchecked tokens report $1 and not depegged.
+func (m *DepegMonitor) PegPrice(token common.Address) (*big.Int, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	peg, ok := m.pegs[token]
+	if !ok {
+		return new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), false
+	}
+	return new(big.Int).Set(peg.PriceUSD), peg.Depegged
+}
+
+// Pegs returns the latest state of every monitored stablecoin
+func (m *DepegMonitor) Pegs() []entities.StablecoinPeg {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pegs := make([]entities.StablecoinPeg, 0, len(m.pegs))
+	for _, stable := range monitoredStablecoins {
+		if peg, ok := m.pegs[stable.Address]; ok {
+			pegs = append(pegs, peg)
+		}
+	}
+	return pegs
+}
+
+// formatUSD renders an 18-decimal USD amount with four decimals
+func formatUSD(price *big.Int) string {
+	scaled := new(big.Int).Div(price, big.NewInt(1e14))
+	return fmt.Sprintf("%d.%04d", scaled.Int64()/10000, scaled.Int64()%10000)
+}
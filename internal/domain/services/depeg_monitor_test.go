@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// stablePool quotes stable for USDC at usdcPerStable, with reserves deep
+// enough that a 1000 token probe barely moves the price
+func stablePool(dexType entities.DEXType, stable entities.Token, usdcPerStable float64) *MockDEXClient {
+	scale := func(token entities.Token, units float64) *big.Int {
+		v, _ := new(big.Float).Mul(big.NewFloat(units), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil))).Int(nil)
+		return v
+	}
+	client := NewMockDEXClient(dexType)
+	client.SetPair(stable.Address, entities.USDC.Address, &entities.Pair{
+		Token0:   stable,
+		Token1:   entities.USDC,
+		Reserve0: scale(stable, 1e9),
+		Reserve1: scale(entities.USDC, 1e9*usdcPerStable),
+		DEX:      dexType,
+	})
+	return client
+}
+
+func TestDepegMonitor(t *testing.T) {
+	tests := []struct {
+		name         string
+		usdtRate     float64 // USDC per USDT
+		daiRate      float64 // USDC per DAI
+		wantDepegged map[string]bool
+		wantUSDC     string
+	}{
+		{"all on peg", 1.0005, 0.9995, map[string]bool{}, "1.0000"},
+		{"USDC off peg", 1.12, 1.12, map[string]bool{"USDC": true}, "0.8928"},
+		{"DAI off peg", 1.0, 0.95, map[string]bool{"DAI": true}, "1.0000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceService := NewPriceService([]dex.DEXClient{
+				stablePool(entities.DEXUniswapV2, entities.USDT, tt.usdtRate),
+				stablePool(entities.DEXCurve, entities.DAI, tt.daiRate),
+			}, &MockCache{})
+			monitor := NewDepegMonitor(priceService, DefaultDepegThresholdBps)
+			priceService.SetStablecoinPegs(monitor)
+
+			if err := monitor.Check(context.Background()); err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			for _, peg := range monitor.Pegs() {
+				if peg.Depegged != tt.wantDepegged[peg.Symbol] {
+					t.Errorf("%s depegged = %v (%d bps), want %v", peg.Symbol, peg.Depegged, peg.DeviationBps, tt.wantDepegged[peg.Symbol])
+				}
+			}
+
+			usdcPrice, err := priceService.GetTokenPrice(context.Background(), entities.USDC)
+			if err != nil {
+				t.Fatalf("GetTokenPrice() error = %v", err)
+			}
+			if got := formatUSD(usdcPrice); got != tt.wantUSDC {
+				t.Errorf("USDC price = $%s, want $%s", got, tt.wantUSDC)
+			}
+
+			warning := priceService.DepegWarning(entities.WETH)
+			if wantWarning := tt.wantDepegged["USDC"]; (warning != "") != wantWarning || wantWarning && !strings.Contains(warning, "USDC") {
+				t.Errorf("DepegWarning(WETH) = %q", warning)
+			}
+			if tt.wantDepegged["DAI"] && !strings.Contains(priceService.DepegWarning(entities.DAI), "DAI is off peg") {
+				t.Errorf("DepegWarning(DAI) = %q", priceService.DepegWarning(entities.DAI))
+			}
+		})
+	}
+}
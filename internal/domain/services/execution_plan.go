@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Execution plan bounds
+const (
+	MaxPlanSlices       = 24
+	DefaultPlanInterval = 5 * time.Minute
+)
+
+// planSliceCounts are the slice counts tried, smallest first, when the
+// caller leaves the count to the planner
+var planSliceCounts = []int{2, 3, 4, 6, 8, 12, 16, 24}
+
+// SliceQuoter quotes one slice of a planned trade
+type SliceQuoter func(ctx context.Context, amountIn *big.Int) (*entities.Quote, error)
+
+// PlanExecution splits the trade single quoted into slices one interval
+// apart. With slices zero it picks the fewest slices, up to MaxPlanSlices,
+// whose price impact stays under targetImpactBps. Slices are quoted
+// against one snapshot of the pools.
+func PlanExecution(ctx context.Context, quoteSlice SliceQuoter, single *entities.Quote, slices int, targetImpactBps int64, interval time.Duration) (*entities.ExecutionPlan, error) {
+	ctx = ensurePairSnapshot(ctx)
+	amountIn := single.AmountIn
+	if slices > 0 && amountIn.Cmp(big.NewInt(int64(slices))) < 0 {
+		return nil, errors.New("amount is too small to slice")
+	}
+
+	var quotes map[int]*entities.Quote
+	switch {
+	case slices == 1 || (slices == 0 && withinImpact(single, targetImpactBps)):
+		slices = 1
+	case slices > 0:
+		quotes = quoteSliceCounts(ctx, quoteSlice, amountIn, []int{slices})
+	default:
+		var counts []int
+		for _, n := range planSliceCounts {
+			if amountIn.Cmp(big.NewInt(int64(n))) >= 0 {
+				counts = append(counts, n)
+			}
+		}
+		quotes = quoteSliceCounts(ctx, quoteSlice, amountIn, counts)
+		slices = 1
+		for _, n := range counts {
+			if quotes[n] != nil {
+				slices = n
+				if withinImpact(quotes[n], targetImpactBps) {
+					break
+				}
+			}
+		}
+	}
+	if slices > 1 && quotes[slices] == nil {
+		return nil, errors.New("no route for a slice")
+	}
+
+	plan := &entities.ExecutionPlan{
+		AmountIn:          amountIn,
+		ExpectedAmountOut: new(big.Int),
+		SingleAmountOut:   single.AmountOut,
+		TargetImpactBps:   targetImpactBps,
+		Interval:          interval,
+		Slices:            make([]entities.PlanSlice, slices),
+	}
+	start := time.Now().UTC()
+	for i := range plan.Slices {
+		quote := single
+		if slices > 1 {
+			quote = quotes[slices]
+		}
+		if i == slices-1 && slices > 1 {
+			// The last slice takes what dividing left over
+			rest := new(big.Int).Mul(quote.AmountIn, big.NewInt(int64(slices-1)))
+			rest.Sub(amountIn, rest)
+			if rest.Cmp(quote.AmountIn) != 0 {
+				var err error
+				if quote, err = quoteSlice(ctx, rest); err != nil {
+					return nil, err
+				}
+			}
+		}
+		plan.Slices[i] = entities.PlanSlice{
+			AmountIn:    quote.AmountIn,
+			AmountOut:   quote.AmountOut,
+			PriceImpact: quote.PriceImpact,
+			ExecuteAt:   start.Add(time.Duration(i) * interval),
+		}
+		plan.ExpectedAmountOut.Add(plan.ExpectedAmountOut, quote.AmountOut)
+	}
+	if single.AmountOut.Sign() > 0 {
+		gain := new(big.Int).Sub(plan.ExpectedAmountOut, single.AmountOut)
+		gain.Mul(gain, big.NewInt(10000))
+		plan.ImprovementBps = gain.Quo(gain, single.AmountOut).Int64()
+	}
+	return plan, nil
+}
+
+// quoteSliceCounts quotes an even slice of amountIn for each count. The
+// first quote reads the pools; the rest reuse them concurrently. A count
+// whose slice found no route is left out.
+func quoteSliceCounts(ctx context.Context, quoteSlice SliceQuoter, amountIn *big.Int, counts []int) map[int]*entities.Quote {
+	quotes := make(map[int]*entities.Quote, len(counts))
+	var mu sync.Mutex
+	quoteCount := func(n int) {
+		quote, err := quoteSlice(ctx, new(big.Int).Quo(amountIn, big.NewInt(int64(n))))
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		quotes[n] = quote
+		mu.Unlock()
+	}
+	if len(counts) == 0 {
+		return quotes
+	}
+
+	quoteCount(counts[0])
+	var wg sync.WaitGroup
+	for _, n := range counts[1:] {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			quoteCount(n)
+		}(n)
+	}
+	wg.Wait()
+	return quotes
+}
+
+func withinImpact(quote *entities.Quote, targetImpactBps int64) bool {
+	return quote.PriceImpact == nil || quote.PriceImpact.Cmp(big.NewInt(targetImpactBps)) <= 0
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// constantProductQuoter quotes a fee-free x*y=k pool of reserve on each side
+func constantProductQuoter(reserve int64) SliceQuoter {
+	return func(ctx context.Context, amountIn *big.Int) (*entities.Quote, error) {
+		r := big.NewInt(reserve)
+		out := new(big.Int).Mul(amountIn, r)
+		out.Quo(out, new(big.Int).Add(r, amountIn))
+		// Impact against the spot price of 1, in bps
+		impact := new(big.Int).Sub(amountIn, out)
+		impact.Mul(impact, big.NewInt(10000))
+		impact.Quo(impact, amountIn)
+		return &entities.Quote{AmountIn: amountIn, AmountOut: out, PriceImpact: impact}, nil
+	}
+}
+
+func TestPlanExecution(t *testing.T) {
+	quoteSlice := constantProductQuoter(1_000_000)
+	ctx := context.Background()
+	quote := func(amount int64) *entities.Quote {
+		q, _ := quoteSlice(ctx, big.NewInt(amount))
+		return q
+	}
+
+	tests := []struct {
+		name       string
+		amountIn   int64
+		slices     int
+		wantSlices int
+	}{
+		// 50,000 in loses ~476 bps; a 1% target needs slices of ~10,000
+		{"fewest slices under the target", 50_000, 0, 6},
+		{"already under the target", 5_000, 0, 1},
+		{"count asked for", 50_000, 3, 3},
+		{"more than the largest count needs", 1_000_000, 0, 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			single := quote(tt.amountIn)
+			plan, err := PlanExecution(ctx, quoteSlice, single, tt.slices, 100, time.Minute)
+			if err != nil {
+				t.Fatalf("PlanExecution() error = %v", err)
+			}
+			if len(plan.Slices) != tt.wantSlices {
+				t.Fatalf("slices = %d, want %d", len(plan.Slices), tt.wantSlices)
+			}
+
+			totalIn, totalOut := new(big.Int), new(big.Int)
+			for i, slice := range plan.Slices {
+				totalIn.Add(totalIn, slice.AmountIn)
+				totalOut.Add(totalOut, slice.AmountOut)
+				if want := plan.Slices[0].ExecuteAt.Add(time.Duration(i) * time.Minute); !slice.ExecuteAt.Equal(want) {
+					t.Errorf("slice %d at %v, want %v", i, slice.ExecuteAt, want)
+				}
+			}
+			if totalIn.Int64() != tt.amountIn {
+				t.Errorf("slices add up to %s, want %d", totalIn, tt.amountIn)
+			}
+			if totalOut.Cmp(plan.ExpectedAmountOut) != 0 {
+				t.Errorf("ExpectedAmountOut = %s, slices add up to %s", plan.ExpectedAmountOut, totalOut)
+			}
+			if tt.wantSlices > 1 && (plan.ExpectedAmountOut.Cmp(single.AmountOut) <= 0 || plan.ImprovementBps <= 0) {
+				t.Errorf("plan pays %s (%d bps), the trade at once %s", plan.ExpectedAmountOut, plan.ImprovementBps, single.AmountOut)
+			}
+		})
+	}
+
+	// The remainder of an uneven split goes to the last slice
+	plan, err := PlanExecution(ctx, quoteSlice, quote(50_001), 4, 100, time.Minute)
+	if err != nil {
+		t.Fatalf("PlanExecution() error = %v", err)
+	}
+	if got := plan.Slices[3].AmountIn.Int64(); got != 12_501 {
+		t.Errorf("last slice = %d, want 12501", got)
+	}
+
+	if _, err := PlanExecution(ctx, quoteSlice, quote(3), 4, 100, time.Minute); err == nil {
+		t.Error("PlanExecution() sliced 3 units four ways")
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/txmanager"
+)
+
+// Gas limit headroom over the simulated estimate in percent
+const gasLimitBufferPercent = 120
+
+// ErrTxNotFound is returned when a hash was not broadcast by this service
+var ErrTxNotFound = errors.New("transaction not found")
+
+// ExecutionService quotes, signs and broadcasts swaps from a hot wallet.
+// Nonces, replacement and confirmation tracking are owned by the txmanager.
+type ExecutionService struct {
+	routerService *RouterService
+	swapService   *SwapService
+	feeService    *FeeService
+	txManager     *txmanager.Manager
+
+	mu      sync.Mutex
+	records map[uint64]*entities.ExecutionRecord
+}
+
+func NewExecutionService(routerService *RouterService, swapService *SwapService, feeService *FeeService, txManager *txmanager.Manager) *ExecutionService {
+	return &ExecutionService{
+		routerService: routerService,
+		swapService:   swapService,
+		feeService:    feeService,
+		txManager:     txManager,
+		records:       make(map[uint64]*entities.ExecutionRecord),
+	}
+}
+
+// Address returns the hot wallet address
+func (s *ExecutionService) Address() common.Address {
+	return s.txManager.Address()
+}
+
+// Execute quotes the swap, builds it for the hot wallet and broadcasts it.
+// The wallet must already hold tokenIn and have approved the router, or the
+// executor for split quotes.
+func (s *ExecutionService) Execute(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.ExecutionRecord, error) {
+	quote, err := s.routerService.GetSmartQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.swapService.AttachTransaction(ctx, quote, s.txManager.Address(), s.txManager.Address()); err != nil {
+		return nil, err
+	}
+	if quote.Transaction == nil {
+		return nil, fmt.Errorf("split routes cannot be executed as a single transaction without the executor contract")
+	}
+	if quote.Approval != nil && len(quote.Approval.Steps) > 0 {
+		return nil, fmt.Errorf("hot wallet has not approved %s to spend %s", quote.Approval.Spender.Hex(), tokenIn.Symbol)
+	}
+	if quote.GasSource != GasSourceSimulated {
+		return nil, fmt.Errorf("swap simulation failed; check wallet balance and router allowance")
+	}
+
+	fees, err := s.feeService.SuggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.txManager.Send(ctx, txmanager.Request{
+		To:        quote.Transaction.To,
+		Data:      quote.Transaction.Data,
+		Value:     quote.Transaction.Value,
+		Gas:       quote.Transaction.Gas * gasLimitBufferPercent / 100,
+		GasTipCap: new(big.Int).Set(fees.MaxPriorityFeePerGas),
+		GasFeeCap: new(big.Int).Set(fees.MaxFeePerGas),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	record := &entities.ExecutionRecord{
+		From:         s.txManager.Address(),
+		Nonce:        tx.Nonce,
+		TokenIn:      tokenIn,
+		TokenOut:     tokenOut,
+		AmountIn:     amountIn,
+		MinAmountOut: quote.MinAmountOut,
+	}
+	applyTx(record, tx)
+
+	s.mu.Lock()
+	s.records[tx.Nonce] = record
+	s.mu.Unlock()
+
+	return record, nil
+}
+
+// GetTransaction returns the tracked record with the txmanager's latest view.
+// Hashes of replaced attempts resolve to the same record.
+func (s *ExecutionService) GetTransaction(ctx context.Context, hash common.Hash) (*entities.ExecutionRecord, error) {
+	tx, err := s.txManager.Get(hash)
+	if err != nil {
+		if errors.Is(err, txmanager.ErrUnknownTx) {
+			return nil, ErrTxNotFound
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[tx.Nonce]
+	if !ok {
+		return nil, ErrTxNotFound
+	}
+	applyTx(record, tx)
+
+	return record, nil
+}
+
+// applyTx copies the txmanager's tracking state onto the swap record
+func applyTx(record *entities.ExecutionRecord, tx *txmanager.Tx) {
+	record.Hash = tx.Hash
+	record.Replaced = tx.Replaced
+	record.Status = tx.Status
+	record.BlockNumber = tx.BlockNumber
+	record.Confirmations = tx.Confirmations
+	record.GasUsed = tx.GasUsed
+	record.SubmittedAt = tx.SubmittedAt.Unix()
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/signer"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/txmanager"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestExecutionServiceResubmitsUnderpriced(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+	ctx := context.Background()
+
+	venue := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	venue.SetPair(&entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   entities.USDC,
+		Token1:   entities.WETH,
+		Reserve0: big.NewInt(30_000_000e6),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	chain := testutil.NewFakeChain(150000, gwei(10), gwei(1))
+	key, _ := crypto.GenerateKey()
+	wallet, err := signer.NewPrivateKeySigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewExecutionService(
+		NewRouterService(NewPriceService([]dex.DEXClient{venue}, &MockCache{})),
+		NewSwapService(swap.NewBuilder(), chain),
+		NewFeeService(chain, nil),
+		txmanager.New(chain, wallet, big.NewInt(1), txmanager.DefaultConfig()),
+	)
+
+	// The mempool still holds an attempt at this nonce, so the first send
+	// is underpriced and the manager resubmits with bumped fees
+	chain.RejectUnderpriced(1)
+	record, err := service.Execute(ctx, entities.WETH, entities.USDC, big.NewInt(1e18), 50)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	sent := chain.Sent()
+	if len(sent) != 1 || sent[0].Hash() != record.Hash {
+		t.Fatalf("sent %d transactions, want the one recorded", len(sent))
+	}
+	// 2 * 10 gwei base fee + 1 gwei tip, then bumped by 25%
+	if got, want := sent[0].GasFeeCap(), big.NewInt(26_250_000_000); got.Cmp(want) != 0 {
+		t.Errorf("max fee = %s, want %s", got, want)
+	}
+	if got, want := sent[0].GasTipCap(), big.NewInt(1_250_000_000); got.Cmp(want) != 0 {
+		t.Errorf("tip = %s, want %s", got, want)
+	}
+	if sent[0].Gas() != 180000 {
+		t.Errorf("gas = %d, want the 150000 estimate plus 20%%", sent[0].Gas())
+	}
+	if record.From != wallet.Address() || record.Status != entities.TxPending || record.MinAmountOut == nil {
+		t.Errorf("record = %+v, want pending from the hot wallet with a minimum out", record)
+	}
+
+	chain.Mine(record.Hash, 100)
+	if err := service.txManager.Poll(ctx); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	got, err := service.GetTransaction(ctx, record.Hash)
+	if err != nil {
+		t.Fatalf("GetTransaction() error = %v", err)
+	}
+	if got.Status != entities.TxMined || got.BlockNumber != 100 || got.GasUsed != 150000 {
+		t.Errorf("after mining: status %s, block %d, gas used %d", got.Status, got.BlockNumber, got.GasUsed)
+	}
+
+	if _, err := service.GetTransaction(ctx, common.HexToHash("0xdead")); !errors.Is(err, ErrTxNotFound) {
+		t.Errorf("GetTransaction(unknown) error = %v, want ErrTxNotFound", err)
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const (
+	// Number of recent blocks sampled for fee suggestions
+	feeHistoryBlocks = 10
+	// Priority fee percentile paid by included transactions
+	priorityFeePercentile = 50
+)
+
+// FeeHistoryProvider fetches eth_feeHistory data
+type FeeHistoryProvider interface {
+	FeeHistory(ctx context.Context, blockCount uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// FeeSuggestion holds EIP-1559 fee parameters for the next block
+type FeeSuggestion struct {
+	BaseFeePerGas        *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// FeeService suggests EIP-1559 fees and prices quote gas in ETH and USD
+type FeeService struct {
+	provider     FeeHistoryProvider
+	priceService *PriceService
+	cacheTTL     time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	cached    *FeeSuggestion
+	expiresAt time.Time
+}
+
+func NewFeeService(provider FeeHistoryProvider, priceService *PriceService) *FeeService {
+	return &FeeService{
+		provider:     provider,
+		priceService: priceService,
+		cacheTTL:     12 * time.Second, // One block
+		now:          time.Now,
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *FeeService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SuggestFees returns maxFeePerGas = 2 * nextBaseFee + tip, where tip is the
+// median of the recent per-block priority fee percentile
+func (s *FeeService) SuggestFees(ctx context.Context) (*FeeSuggestion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && s.now().Before(s.expiresAt) {
+		return s.cached, nil
+	}
+
+	history, err := s.provider.FeeHistory(ctx, feeHistoryBlocks, []float64{priorityFeePercentile})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, fmt.Errorf("empty fee history")
+	}
+
+	// The last base fee entry is the projection for the next block
+	nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for _, rewards := range history.Reward {
+		if len(rewards) > 0 && rewards[0] != nil {
+			tips = append(tips, rewards[0])
+		}
+	}
+	tip := medianBigInt(tips)
+
+	maxFee := new(big.Int).Mul(nextBaseFee, big.NewInt(2))
+	maxFee.Add(maxFee, tip)
+
+	s.cached = &FeeSuggestion{
+		BaseFeePerGas:        nextBaseFee,
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: tip,
+	}
+	s.expiresAt = s.now().Add(s.cacheTTL)
+
+	return s.cached, nil
+}
+
+// AttachGasCost prices the quote's gas estimate. The USD figure is omitted
+// when WETH cannot be priced.
+func (s *FeeService) AttachGasCost(ctx context.Context, quote *entities.Quote) error {
+	fees, err := s.SuggestFees(ctx)
+	if err != nil {
+		return err
+	}
+
+	effectivePrice := new(big.Int).Add(fees.BaseFeePerGas, fees.MaxPriorityFeePerGas)
+	costWei := new(big.Int).Mul(effectivePrice, new(big.Int).SetUint64(quote.GasEstimate))
+
+	gasCost := &entities.GasCost{
+		BaseFeePerGas:        fees.BaseFeePerGas,
+		MaxFeePerGas:         fees.MaxFeePerGas,
+		MaxPriorityFeePerGas: fees.MaxPriorityFeePerGas,
+		CostWei:              costWei,
+	}
+
+	if s.priceService != nil {
+		if ethPrice, err := s.priceService.GetTokenPrice(ctx, entities.WETH); err == nil {
+			// costUsd = costWei * ethPrice / 1e18
+			costUSD := new(big.Int).Mul(costWei, ethPrice)
+			costUSD.Div(costUSD, big.NewInt(1e18))
+			gasCost.CostUSD = costUSD
+		}
+	}
+
+	quote.GasCost = gasCost
+	return nil
+}
+
+// medianBigInt returns the median of values, or zero for an empty slice
+func medianBigInt(values []*big.Int) *big.Int {
+	if len(values) == 0 {
+		return big.NewInt(0)
+	}
+
+	sorted := make([]*big.Int, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return new(big.Int).Set(sorted[mid])
+	}
+	sum := new(big.Int).Add(sorted[mid-1], sorted[mid])
+	return sum.Div(sum, big.NewInt(2))
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+type mockFeeHistory struct {
+	history *ethereum.FeeHistory
+}
+
+func (m *mockFeeHistory) FeeHistory(ctx context.Context, blockCount uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return m.history, nil
+}
+
+func TestFeeServiceAttachGasCost(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+
+	provider := &mockFeeHistory{history: &ethereum.FeeHistory{
+		BaseFee: []*big.Int{gwei(18), gwei(19), gwei(20)},
+		Reward:  [][]*big.Int{{gwei(1)}, {gwei(3)}, {gwei(2)}},
+	}}
+	feeService := NewFeeService(provider, nil)
+
+	quote := &entities.Quote{GasEstimate: 100000}
+	if err := feeService.AttachGasCost(context.Background(), quote); err != nil {
+		t.Fatalf("AttachGasCost() error = %v", err)
+	}
+
+	if quote.GasCost.BaseFeePerGas.Cmp(gwei(20)) != 0 {
+		t.Errorf("BaseFeePerGas = %s, want 20 gwei", quote.GasCost.BaseFeePerGas)
+	}
+	if quote.GasCost.MaxPriorityFeePerGas.Cmp(gwei(2)) != 0 {
+		t.Errorf("MaxPriorityFeePerGas = %s, want 2 gwei (median)", quote.GasCost.MaxPriorityFeePerGas)
+	}
+	if quote.GasCost.MaxFeePerGas.Cmp(gwei(42)) != 0 {
+		t.Errorf("MaxFeePerGas = %s, want 42 gwei", quote.GasCost.MaxFeePerGas)
+	}
+	// (20 + 2) gwei * 100000 gas
+	if quote.GasCost.CostWei.Cmp(gwei(2200000)) != 0 {
+		t.Errorf("CostWei = %s, want 2200000 gwei", quote.GasCost.CostWei)
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Currency is what a price is quoted in
+type Currency string
+
+const (
+	CurrencyUSD Currency = "USD"
+	CurrencyETH Currency = "ETH"
+	CurrencyBTC Currency = "BTC"
+	CurrencyEUR Currency = "EUR"
+)
+
+// ErrUnsupportedCurrency is returned for a currency without a feed
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// FXFeed is a Chainlink aggregator pricing one unit of Currency in USD
+type FXFeed struct {
+	Currency  Currency
+	Address   common.Address
+	Heartbeat time.Duration // The feed updates at least this often
+}
+
+// MainnetFXFeeds are the Chainlink USD feeds on Ethereum mainnet
+var MainnetFXFeeds = []FXFeed{
+	{CurrencyETH, common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"), time.Hour},
+	{CurrencyBTC, common.HexToAddress("0xF4030086522a5bEEa4988F8cA5B36dbC97BeE88c"), time.Hour},
+	{CurrencyEUR, common.HexToAddress("0xb49f677943BC038e9857d61E7d053CaA2C1734C1"), 24 * time.Hour},
+}
+
+var (
+	// latestRoundData()
+	latestRoundDataSelector = common.Hex2Bytes("feaf968c")
+)
+
+// fxCacheTTL is how long a feed's answer is reused; feeds move far less
+// often than prices are requested
+const fxCacheTTL = 30 * time.Second
+
+// FXService converts USD prices into other currencies with Chainlink feeds
+type FXService struct {
+	caller ContractCaller
+	feeds  map[Currency]FXFeed
+	now    func() time.Time
+
+	mu    sync.Mutex
+	rates map[Currency]fxRate
+}
+
+type fxRate struct {
+	usd     *big.Int // USD per unit, 18 decimals
+	fetched time.Time
+}
+
+func NewFXService(caller ContractCaller, feeds []FXFeed) *FXService {
+	byCurrency := make(map[Currency]FXFeed, len(feeds))
+	for _, feed := range feeds {
+		byCurrency[feed.Currency] = feed
+	}
+	return &FXService{
+		caller: caller,
+		feeds:  byCurrency,
+		now:    time.Now,
+		rates:  make(map[Currency]fxRate),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *FXService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// ParseCurrency validates a currency code, ignoring case. USD is always
+// supported; others need a feed.
+func (s *FXService) ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(code))
+	if currency == CurrencyUSD {
+		return currency, nil
+	}
+	if s != nil {
+		if _, ok := s.feeds[currency]; ok {
+			return currency, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+}
+
+// Convert prices usd, 18 decimals, in currency, also 18 decimals
+func (s *FXService) Convert(ctx context.Context, usd *big.Int, currency Currency) (*big.Int, error) {
+	if currency == CurrencyUSD {
+		return new(big.Int).Set(usd), nil
+	}
+	rate, err := s.Rate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	price := new(big.Int).Mul(usd, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	return price.Div(price, rate), nil
+}
+
+// Rate is the USD value of one unit of currency with 18 decimals
+func (s *FXService) Rate(ctx context.Context, currency Currency) (*big.Int, error) {
+	feed, ok := s.feeds[currency]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	s.mu.Lock()
+	cached, ok := s.rates[currency]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.fetched) < fxCacheTTL {
+		return cached.usd, nil
+	}
+
+	usd, err := s.readFeed(ctx, feed)
+	if err != nil {
+		return nil, fmt.Errorf("%s/USD feed: %w", currency, err)
+	}
+	s.mu.Lock()
+	s.rates[currency] = fxRate{usd: usd, fetched: s.now()}
+	s.mu.Unlock()
+	return usd, nil
+}
+
+// readFeed reads the feed's latest answer scaled to 18 decimals, rejecting
+// answers that aren't positive or are older than twice the heartbeat
+func (s *FXService) readFeed(ctx context.Context, feed FXFeed) (*big.Int, error) {
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &feed.Address, Data: decimalsSelector})
+	if err != nil {
+		return nil, fmt.Errorf("decimals() failed: %w", err)
+	}
+	if len(result) < 32 {
+		return nil, fmt.Errorf("decimals() returned %d bytes", len(result))
+	}
+	decimals := new(big.Int).SetBytes(result[:32])
+	if !decimals.IsUint64() || decimals.Uint64() > 36 {
+		return nil, fmt.Errorf("decimals() returned %s", decimals)
+	}
+
+	// (roundId, answer, startedAt, updatedAt, answeredInRound)
+	result, err = s.caller.CallContract(ctx, ethereum.CallMsg{To: &feed.Address, Data: latestRoundDataSelector})
+	if err != nil {
+		return nil, fmt.Errorf("latestRoundData() failed: %w", err)
+	}
+	if len(result) < 160 {
+		return nil, fmt.Errorf("latestRoundData() returned %d bytes", len(result))
+	}
+	// answer is an int256; a set top bit is negative
+	answer := new(big.Int).SetBytes(result[32:64])
+	if answer.Sign() == 0 || answer.Bit(255) == 1 {
+		return nil, fmt.Errorf("answer %s is not positive", answer)
+	}
+	updatedAt := time.Unix(new(big.Int).SetBytes(result[96:128]).Int64(), 0)
+	if age := s.now().Sub(updatedAt); age > 2*feed.Heartbeat {
+		return nil, fmt.Errorf("answer is %s old", age.Truncate(time.Second))
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	answer.Mul(answer, scale)
+	return answer.Div(answer, new(big.Int).Exp(big.NewInt(10), decimals, nil)), nil
+}
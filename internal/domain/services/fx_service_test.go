@@ -0,0 +1,100 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// mockFeeds answers decimals and latestRoundData for Chainlink feeds by
+// address
+type mockFeeds struct {
+	answers map[common.Address]int64 // 8 decimals
+	updated time.Time
+	calls   int
+}
+
+func (m *mockFeeds) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	answer, ok := m.answers[*msg.To]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	m.calls++
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	switch {
+	case bytes.Equal(msg.Data, decimalsSelector):
+		return word(8), nil
+	case bytes.Equal(msg.Data, latestRoundDataSelector):
+		data := append(word(1), word(answer)...)
+		data = append(data, word(m.updated.Unix())...)
+		data = append(data, word(m.updated.Unix())...)
+		return append(data, word(1)...), nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestFXServiceConvert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	eth, eur := MainnetFXFeeds[0], MainnetFXFeeds[2]
+	feeds := &mockFeeds{
+		answers: map[common.Address]int64{eth.Address: 2000_00000000, eur.Address: 1_25000000},
+		updated: now.Add(-10 * time.Minute),
+	}
+	fx := NewFXService(feeds, MainnetFXFeeds)
+	fx.now = func() time.Time { return now }
+	usd := new(big.Int).Mul(big.NewInt(500), big.NewInt(1e18)) // $500
+
+	tests := []struct {
+		currency Currency
+		want     string
+	}{
+		{CurrencyUSD, "500000000000000000000"},
+		{CurrencyETH, "250000000000000000"}, // 0.25 ETH at $2000
+		{CurrencyEUR, "400000000000000000000"},
+	}
+	for _, tt := range tests {
+		got, err := fx.Convert(context.Background(), usd, tt.currency)
+		if err != nil {
+			t.Fatalf("Convert(%s) error = %v", tt.currency, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("Convert(%s) = %s, want %s", tt.currency, got, tt.want)
+		}
+	}
+
+	// Answers are reused within the cache TTL
+	calls := feeds.calls
+	if _, err := fx.Convert(context.Background(), usd, CurrencyETH); err != nil || feeds.calls != calls {
+		t.Errorf("cached Convert() made %d calls, error = %v", feeds.calls-calls, err)
+	}
+
+	// The BTC feed reverts; the EUR answer goes stale after two days
+	if _, err := fx.Convert(context.Background(), usd, CurrencyBTC); err == nil {
+		t.Error("Convert(BTC) succeeded without a feed answer")
+	}
+	fx.now = func() time.Time { return now.Add(49 * time.Hour) }
+	if _, err := fx.Convert(context.Background(), usd, CurrencyEUR); err == nil {
+		t.Error("Convert(EUR) accepted a stale answer")
+	}
+}
+
+func TestFXServiceParseCurrency(t *testing.T) {
+	fx := NewFXService(&mockFeeds{}, MainnetFXFeeds)
+	for code, want := range map[string]Currency{"usd": CurrencyUSD, "Eth": CurrencyETH, "BTC": CurrencyBTC, "eur": CurrencyEUR, "JPY": ""} {
+		got, err := fx.ParseCurrency(code)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("ParseCurrency(%q) = %q, %v", code, got, err)
+		}
+	}
+	// Without feeds only USD is supported
+	var none *FXService
+	if _, err := none.ParseCurrency("ETH"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("nil ParseCurrency(ETH) error = %v", err)
+	}
+}
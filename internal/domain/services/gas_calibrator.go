@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// gasSampleBlocks is how far back the first pass reaches, and the most
+// blocks any pass scans
+const gasSampleBlocks = 50
+
+// gasSamplesPerPass caps the receipts fetched per venue on each pass
+const gasSamplesPerPass = 20
+
+// minGasSamples is how many swaps a venue needs before its median replaces
+// the constant
+const minGasSamples = 20
+
+// gasVenue is a set of venues whose swaps emit the same event. Uniswap V2
+// and Sushiswap run the same pair contract, so they are calibrated together.
+type gasVenue struct {
+	topic common.Hash
+	dexes []entities.DEXType
+}
+
+var gasVenues = []gasVenue{
+	{crypto.Keccak256Hash([]byte("Swap(address,uint256,uint256,uint256,uint256,address)")), []entities.DEXType{entities.DEXUniswapV2, entities.DEXSushiswap}},
+	{crypto.Keccak256Hash([]byte("Swap(address,address,int256,int256,uint160,uint128,int24)")), []entities.DEXType{entities.DEXUniswapV3}},
+	{crypto.Keccak256Hash([]byte("TokenExchange(address,int128,uint256,int128,uint256)")), []entities.DEXType{entities.DEXCurve}},
+	// Emitted by the Balancer Vault
+	{crypto.Keccak256Hash([]byte("Swap(bytes32,address,address,uint256,uint256)")), []entities.DEXType{entities.DEXBalancer}},
+}
+
+// ReceiptBackend is the node access GasCalibrator needs
+type ReceiptBackend interface {
+	LogBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// GasStatsStore persists learned medians so a restart doesn't fall back to
+// the constants
+type GasStatsStore interface {
+	LoadGasStats(ctx context.Context) ([]entities.GasStats, error)
+	SaveGasStats(ctx context.Context, stats []entities.GasStats) error
+}
+
+// GasCalibrator learns what a hop through each venue costs from the
+// receipts of swaps in recent blocks. A transaction counts when every swap
+// it made was on one venue: its gas above the 21000 base, divided by its
+// swap events, is one sample. The median of the latest samples replaces
+// the venue's constant in route gas estimates.
+type GasCalibrator struct {
+	backend ReceiptBackend
+	window  int
+	store   GasStatsStore
+
+	mu      sync.RWMutex
+	samples [][]uint64 // Per gasVenues entry, oldest first
+	learned map[entities.DEXType]entities.GasStats
+	next    uint64
+}
+
+// NewGasCalibrator keeps the latest window samples per venue
+func NewGasCalibrator(backend ReceiptBackend, window int) *GasCalibrator {
+	return &GasCalibrator{
+		backend: backend,
+		window:  window,
+		samples: make([][]uint64, len(gasVenues)),
+		learned: make(map[entities.DEXType]entities.GasStats),
+	}
+}
+
+// SetStore persists medians after every pass and restores them on Run
+func (c *GasCalibrator) SetStore(store GasStatsStore) {
+	c.store = store
+}
+
+// Sample scans the blocks since the last pass for swaps and updates the
+// medians of venues with enough samples
+func (c *GasCalibrator) Sample(ctx context.Context) error {
+	head, err := c.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	c.mu.RLock()
+	from := c.next
+	c.mu.RUnlock()
+	// Samples only need to be recent, so blocks missed while down are skipped
+	from = max(from, max(head+1, gasSampleBlocks)-gasSampleBlocks)
+	if from > head {
+		return nil
+	}
+
+	topics := make([]common.Hash, len(gasVenues))
+	for i, venue := range gasVenues {
+		topics[i] = venue.topic
+	}
+	logs, err := c.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(head),
+		Topics:    [][]common.Hash{topics},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get swap logs %d-%d: %w", from, head, err)
+	}
+
+	samples := make([][]uint64, len(gasVenues))
+	for venue, txs := range candidateSwaps(logs) {
+		for _, hash := range txs {
+			receipt, err := c.backend.TransactionReceipt(ctx, hash)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				continue
+			}
+			if perHop, ok := gasPerSwap(receipt, venue); ok {
+				samples[venue] = append(samples[venue], perHop)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.next = head + 1
+	for venue := range gasVenues {
+		c.samples[venue] = append(c.samples[venue], samples[venue]...)
+		if excess := len(c.samples[venue]) - c.window; excess > 0 {
+			c.samples[venue] = c.samples[venue][excess:]
+		}
+	}
+	learned := c.refresh()
+	c.mu.Unlock()
+
+	if c.store != nil {
+		if err := c.store.SaveGasStats(ctx, learned); err != nil {
+			return fmt.Errorf("failed to save gas stats: %w", err)
+		}
+	}
+	return nil
+}
+
+// refresh recomputes the medians and hands them to estimateGas. Callers
+// hold c.mu.
+func (c *GasCalibrator) refresh() []entities.GasStats {
+	now := time.Now().Unix()
+	for i, venue := range gasVenues {
+		if len(c.samples[i]) < minGasSamples {
+			continue
+		}
+		median := medianGas(c.samples[i])
+		for _, dexType := range venue.dexes {
+			c.learned[dexType] = entities.GasStats{
+				DEX:           dexType,
+				DefaultPerHop: gasPerHopByDEX[dexType],
+				MedianPerHop:  median,
+				Samples:       len(c.samples[i]),
+				UpdatedAt:     now,
+			}
+		}
+	}
+
+	learned := make([]entities.GasStats, 0, len(c.learned))
+	for dexType, stats := range c.learned {
+		learnedGasPerHop.Store(dexType, stats.MedianPerHop)
+		learned = append(learned, stats)
+	}
+	return learned
+}
+
+// Load restores medians saved by an earlier run
+func (c *GasCalibrator) Load(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	stored, err := c.store.LoadGasStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load gas stats: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, stats := range stored {
+		if stats.MedianPerHop == 0 || stats.Samples < minGasSamples {
+			continue
+		}
+		stats.DefaultPerHop = gasPerHopByDEX[stats.DEX]
+		c.learned[stats.DEX] = stats
+	}
+	c.refresh()
+	return nil
+}
+
+// Stats returns every venue's constant and, when learned, its median
+func (c *GasCalibrator) Stats() []entities.GasStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := make([]entities.GasStats, 0, len(gasPerHopByDEX))
+	for dexType, defaultGas := range gasPerHopByDEX {
+		venue, ok := c.learned[dexType]
+		if !ok {
+			venue = entities.GasStats{DEX: dexType, DefaultPerHop: defaultGas}
+		}
+		stats = append(stats, venue)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].DEX < stats[j].DEX })
+	return stats
+}
+
+// Run restores saved medians, then samples every interval until ctx is
+// cancelled
+func (c *GasCalibrator) Run(ctx context.Context, interval time.Duration) {
+	if err := c.Load(ctx); err != nil {
+		log.Printf("gas calibrator: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Sample(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("gas calibrator: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Follow restores the medians another instance saves every interval until
+// ctx is cancelled, leaving the block scans to it
+func (c *GasCalibrator) Follow(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Load(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("gas calibrator: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// candidateSwaps groups the transactions behind swap logs by the venue of
+// their first swap, keeping the latest gasSamplesPerPass of each
+func candidateSwaps(logs []types.Log) map[int][]common.Hash {
+	seen := make(map[common.Hash]bool)
+	candidates := make(map[int][]common.Hash)
+	for i := len(logs) - 1; i >= 0; i-- {
+		entry := logs[i]
+		if entry.Removed || len(entry.Topics) == 0 || seen[entry.TxHash] {
+			continue
+		}
+		seen[entry.TxHash] = true
+		if venue := swapVenue(entry); venue >= 0 && len(candidates[venue]) < gasSamplesPerPass {
+			candidates[venue] = append(candidates[venue], entry.TxHash)
+		}
+	}
+	return candidates
+}
+
+// gasPerSwap divides a successful transaction's gas over its swaps when
+// they were all on venue
+func gasPerSwap(receipt *types.Receipt, venue int) (uint64, bool) {
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.GasUsed <= 21000 {
+		return 0, false
+	}
+	swaps := 0
+	for _, entry := range receipt.Logs {
+		switch swapVenue(*entry) {
+		case -1:
+		case venue:
+			swaps++
+		default:
+			return 0, false
+		}
+	}
+	if swaps == 0 {
+		return 0, false
+	}
+	return (receipt.GasUsed - 21000) / uint64(swaps), true
+}
+
+// swapVenue returns the gasVenues index of a swap log, or -1
+func swapVenue(entry types.Log) int {
+	if len(entry.Topics) == 0 {
+		return -1
+	}
+	for i, venue := range gasVenues {
+		if entry.Topics[0] == venue.topic {
+			return i
+		}
+	}
+	return -1
+}
+
+func medianGas(samples []uint64) uint64 {
+	sorted := append([]uint64(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+type mockReceiptBackend struct {
+	head     uint64
+	logs     []types.Log
+	receipts map[common.Hash]*types.Receipt
+	query    ethereum.FilterQuery
+}
+
+func (m *mockReceiptBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *mockReceiptBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	m.query = query
+	return m.logs, nil
+}
+
+func (m *mockReceiptBackend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := m.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, errors.New("not found")
+}
+
+// addSwap records a transaction that made one swap per venue in venues
+func (m *mockReceiptBackend) addSwap(id int64, gasUsed uint64, status uint64, venues ...int) {
+	hash := common.BigToHash(big.NewInt(id))
+	receipt := &types.Receipt{Status: status, GasUsed: gasUsed, TxHash: hash}
+	// Transfers and other logs don't count as swaps
+	receipt.Logs = append(receipt.Logs, &types.Log{Topics: []common.Hash{common.HexToHash("0xddf252ad")}, TxHash: hash})
+	for _, venue := range venues {
+		entry := types.Log{Topics: []common.Hash{gasVenues[venue].topic}, TxHash: hash, BlockNumber: m.head}
+		receipt.Logs = append(receipt.Logs, &entry)
+		m.logs = append(m.logs, entry)
+	}
+	m.receipts[hash] = receipt
+}
+
+type memoryGasStore struct {
+	stats []entities.GasStats
+}
+
+func (s *memoryGasStore) LoadGasStats(ctx context.Context) ([]entities.GasStats, error) {
+	return s.stats, nil
+}
+
+func (s *memoryGasStore) SaveGasStats(ctx context.Context, stats []entities.GasStats) error {
+	s.stats = stats
+	return nil
+}
+
+func TestGasCalibratorLearnsMedians(t *testing.T) {
+	t.Cleanup(learnedGasPerHop.Clear)
+
+	backend := &mockReceiptBackend{head: 1000, receipts: make(map[common.Hash]*types.Receipt)}
+	// Twenty single-venue V2 swaps: ten one-hop at 21000+90000 and ten
+	// two-hop at 21000+2*110000, for a median of 100000 per hop
+	for i := int64(0); i < 10; i++ {
+		backend.addSwap(i, 111000, types.ReceiptStatusSuccessful, 0)
+		backend.addSwap(100+i, 241000, types.ReceiptStatusSuccessful, 0, 0)
+	}
+	// One V3 sample, too few to replace its constant, besides a mixed and
+	// a failed transaction that don't count
+	backend.addSwap(200, 5000000, types.ReceiptStatusSuccessful, 0, 1)
+	backend.addSwap(201, 5000000, types.ReceiptStatusFailed, 1)
+	backend.addSwap(300, 200000, types.ReceiptStatusSuccessful, 1)
+
+	store := &memoryGasStore{}
+	calibrator := NewGasCalibrator(backend, 500)
+	calibrator.SetStore(store)
+	if err := calibrator.Sample(context.Background()); err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if from := backend.query.FromBlock.Uint64(); from != 1000-gasSampleBlocks+1 {
+		t.Errorf("scanned from block %d", from)
+	}
+
+	stats := make(map[entities.DEXType]entities.GasStats)
+	for _, venue := range calibrator.Stats() {
+		stats[venue.DEX] = venue
+	}
+	for _, dexType := range []entities.DEXType{entities.DEXUniswapV2, entities.DEXSushiswap} {
+		if got := stats[dexType]; got.MedianPerHop != 100000 || got.Samples != 20 {
+			t.Errorf("%s = %+v, want a median of 100000 over 20 samples", dexType, got)
+		}
+	}
+	if len(calibrator.samples[1]) != 1 {
+		t.Errorf("got %d V3 samples, want 1", len(calibrator.samples[1]))
+	}
+	if got := stats[entities.DEXUniswapV3]; got.MedianPerHop != 0 || got.DefaultPerHop != gasPerHopByDEX[entities.DEXUniswapV3] {
+		t.Errorf("uniswap_v3 = %+v, want its constant only", got)
+	}
+	if len(store.stats) != 2 {
+		t.Errorf("saved %d venues, want 2", len(store.stats))
+	}
+
+	route := &entities.Route{Hops: []entities.Hop{{Pair: entities.Pair{DEX: entities.DEXSushiswap}}, {Pair: entities.Pair{DEX: entities.DEXUniswapV3}}}}
+	if got, want := estimateGas(route), uint64(21000+100000+gasPerHopByDEX[entities.DEXUniswapV3]); got != want {
+		t.Errorf("estimateGas() = %d, want %d", got, want)
+	}
+
+	// A restart picks up the saved medians before sampling
+	learnedGasPerHop.Clear()
+	restarted := NewGasCalibrator(backend, 500)
+	restarted.SetStore(store)
+	if err := restarted.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := gasPerHop(entities.DEXUniswapV2); got != 100000 {
+		t.Errorf("gas per hop after restart = %d, want 100000", got)
+	}
+}
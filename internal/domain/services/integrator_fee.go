@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+)
+
+// MaxIntegratorFeeBps caps the referral fee an integrator can charge (3%)
+const MaxIntegratorFeeBps = 300
+
+// feeLogBlockRange bounds each eth_getLogs request
+const feeLogBlockRange = 10000
+
+var (
+	feeCollectedTopic = crypto.Keccak256Hash([]byte(swap.FeeCollectedEvent))
+	feeClaimedTopic   = crypto.Keccak256Hash([]byte(swap.FeeClaimedEvent))
+)
+
+// ErrNothingToClaim is returned by PrepareClaim when the ledger holds no
+// unclaimed fee for the recipient in the token
+var ErrNothingToClaim = errors.New("nothing to claim")
+
+// ApplyIntegratorFee deducts a referral fee of bps from the quote's output
+// and minimum, paid to recipient. Market maker fills settle outside the fee
+// collector, so they are left without a fee.
+func ApplyIntegratorFee(quote *entities.Quote, bps uint64, recipient common.Address) {
+	if quote.RFQOrder != nil || bps == 0 {
+		return
+	}
+
+	fee := new(big.Int).Mul(quote.AmountOut, new(big.Int).SetUint64(bps))
+	fee.Div(fee, big.NewInt(10000))
+	quote.AmountOut = new(big.Int).Sub(quote.AmountOut, fee)
+	if quote.MinAmountOut != nil {
+		minAmount := new(big.Int).Mul(quote.MinAmountOut, big.NewInt(10000-int64(bps)))
+		quote.MinAmountOut = minAmount.Div(minAmount, big.NewInt(10000))
+	}
+	quote.IntegratorFee = &entities.IntegratorFee{
+		Bps:       bps,
+		Recipient: recipient,
+		Amount:    fee,
+	}
+}
+
+// LogBackend is the node access FeeLedger needs
+type LogBackend interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// FeeLedgerStore checkpoints a collector's ledger, so a restart resumes
+// scanning where the last run stopped rather than at the deployment block
+type FeeLedgerStore interface {
+	LoadFeeLedger(ctx context.Context, collector common.Address) (accruals []entities.FeeAccrual, next uint64, err error)
+	SaveFeeLedger(ctx context.Context, collector common.Address, accruals []entities.FeeAccrual, next uint64) error
+}
+
+// FeeLedger keeps each integrator's account with the fee collector: the
+// fees credited to them from its FeeCollected logs, less what they have
+// withdrawn from its FeeClaimed logs. Logs are read a few blocks behind the
+// head so shallow reorgs don't leave phantom fees behind.
+type FeeLedger struct {
+	backend       LogBackend
+	state         StateReader // Reads the collector's balances for Reconcile; nil disables it
+	store         FeeLedgerStore
+	collector     common.Address
+	confirmations uint64
+
+	pollMu   sync.Mutex // Serialises Poll
+	mu       sync.RWMutex
+	next     uint64 // First block not yet scanned
+	accruals map[common.Address]map[common.Address]*entities.FeeAccrual
+}
+
+// NewFeeLedger scans collector's logs from startBlock, the block it was
+// deployed at
+func NewFeeLedger(backend LogBackend, collector common.Address, startBlock, confirmations uint64) *FeeLedger {
+	return &FeeLedger{
+		backend:       backend,
+		collector:     collector,
+		confirmations: confirmations,
+		next:          startBlock,
+		accruals:      make(map[common.Address]map[common.Address]*entities.FeeAccrual),
+	}
+}
+
+// SetStateReader lets Reconcile read the collector's own balances
+func (l *FeeLedger) SetStateReader(state StateReader) {
+	l.state = state
+}
+
+// SetStore checkpoints the ledger after every poll and restores it on Run
+func (l *FeeLedger) SetStore(store FeeLedgerStore) {
+	l.store = store
+}
+
+// Load restores the ledger checkpointed by an earlier run, when it got
+// further than the start block
+func (l *FeeLedger) Load(ctx context.Context) error {
+	if l.store == nil {
+		return nil
+	}
+	accruals, next, err := l.store.LoadFeeLedger(ctx, l.collector)
+	if err != nil {
+		return fmt.Errorf("failed to load fee ledger: %w", err)
+	}
+
+	l.pollMu.Lock()
+	defer l.pollMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if next <= l.next {
+		return nil
+	}
+	l.next = next
+	l.accruals = make(map[common.Address]map[common.Address]*entities.FeeAccrual)
+	for _, accrual := range accruals {
+		if l.accruals[accrual.Recipient] == nil {
+			l.accruals[accrual.Recipient] = make(map[common.Address]*entities.FeeAccrual)
+		}
+		l.accruals[accrual.Recipient][accrual.Token] = &accrual
+	}
+	return nil
+}
+
+// Poll reads FeeCollected and FeeClaimed logs up to the confirmed head
+func (l *FeeLedger) Poll(ctx context.Context) error {
+	head, err := l.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	if head < l.confirmations {
+		return nil
+	}
+	safe := head - l.confirmations
+
+	l.pollMu.Lock()
+	defer l.pollMu.Unlock()
+
+	l.mu.RLock()
+	from := l.next
+	l.mu.RUnlock()
+	if from > safe {
+		return nil
+	}
+
+	for from <= safe {
+		to := min(from+feeLogBlockRange-1, safe)
+		logs, err := l.backend.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{l.collector},
+			Topics:    [][]common.Hash{{feeCollectedTopic, feeClaimedTopic}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get fee logs %d-%d: %w", from, to, err)
+		}
+
+		l.mu.Lock()
+		for _, entry := range logs {
+			l.record(entry)
+		}
+		l.next = to + 1
+		l.mu.Unlock()
+		from = to + 1
+	}
+	return l.save(ctx)
+}
+
+// save checkpoints every accrual and the next block to scan. Callers hold
+// l.pollMu.
+func (l *FeeLedger) save(ctx context.Context) error {
+	if l.store == nil {
+		return nil
+	}
+	l.mu.RLock()
+	next := l.next
+	var accruals []entities.FeeAccrual
+	for _, byToken := range l.accruals {
+		for _, accrual := range byToken {
+			snapshot := *accrual
+			snapshot.Amount = new(big.Int).Set(accrual.Amount)
+			snapshot.Claimed = new(big.Int).Set(accrual.Claimed)
+			accruals = append(accruals, snapshot)
+		}
+	}
+	l.mu.RUnlock()
+
+	if err := l.store.SaveFeeLedger(ctx, l.collector, accruals, next); err != nil {
+		return fmt.Errorf("failed to save fee ledger: %w", err)
+	}
+	return nil
+}
+
+// record adds one FeeCollected or FeeClaimed log. Callers hold l.mu.
+func (l *FeeLedger) record(entry types.Log) {
+	if entry.Removed || len(entry.Topics) != 3 || len(entry.Data) != 32 {
+		return
+	}
+	claimed := entry.Topics[0] == feeClaimedTopic
+	recipient := common.BytesToAddress(entry.Topics[1].Bytes())
+	token := common.BytesToAddress(entry.Topics[2].Bytes())
+
+	byToken, ok := l.accruals[recipient]
+	if !ok {
+		byToken = make(map[common.Address]*entities.FeeAccrual)
+		l.accruals[recipient] = byToken
+	}
+	accrual, ok := byToken[token]
+	if !ok {
+		accrual = &entities.FeeAccrual{Recipient: recipient, Token: token, Amount: new(big.Int), Claimed: new(big.Int)}
+		byToken[token] = accrual
+	}
+	amount := new(big.Int).SetBytes(entry.Data)
+	if claimed {
+		accrual.Claimed.Add(accrual.Claimed, amount)
+		accrual.Claims++
+		return
+	}
+	accrual.Amount.Add(accrual.Amount, amount)
+	accrual.Swaps++
+}
+
+// Accrued returns the fees paid to recipient, one entry per token, and the
+// last block included
+func (l *FeeLedger) Accrued(recipient common.Address) ([]entities.FeeAccrual, uint64) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	accruals := make([]entities.FeeAccrual, 0, len(l.accruals[recipient]))
+	for _, accrual := range l.accruals[recipient] {
+		snapshot := *accrual
+		snapshot.Amount = new(big.Int).Set(accrual.Amount)
+		snapshot.Claimed = new(big.Int).Set(accrual.Claimed)
+		accruals = append(accruals, snapshot)
+	}
+	sort.Slice(accruals, func(i, j int) bool {
+		return accruals[i].Token.Cmp(accruals[j].Token) < 0
+	})
+
+	var through uint64
+	if l.next > 0 {
+		through = l.next - 1
+	}
+	return accruals, through
+}
+
+// PrepareClaim builds recipient's withdrawal of its fees in token, paid to
+// to. The collector pays out everything credited when the claim lands,
+// which can include fees from blocks the ledger hasn't scanned yet.
+func (l *FeeLedger) PrepareClaim(recipient, token, to common.Address) (*entities.FeeClaim, error) {
+	accruals, through := l.Accrued(recipient)
+	for _, accrual := range accruals {
+		if accrual.Token != token {
+			continue
+		}
+		claimable := accrual.Claimable()
+		if claimable.Sign() <= 0 {
+			break
+		}
+		return &entities.FeeClaim{
+			Recipient:    recipient,
+			Token:        token,
+			Amount:       claimable,
+			ThroughBlock: through,
+			Transaction:  swap.BuildFeeClaim(l.collector, recipient, token, to),
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s in %s", ErrNothingToClaim, recipient.Hex(), token.Hex())
+}
+
+// Reconcile checks recipient's claimable fee in every token the ledger has
+// seen against the collector's accruedFees at the last block scanned. A
+// mismatch means logs were missed or the collector credited fees without
+// emitting them.
+func (l *FeeLedger) Reconcile(ctx context.Context, recipient common.Address) ([]entities.FeeReconciliation, uint64, error) {
+	if l.state == nil {
+		return nil, 0, errors.New("fee ledger has no state reader")
+	}
+
+	accruals, through := l.Accrued(recipient)
+	block := new(big.Int).SetUint64(through)
+	reconciliations := make([]entities.FeeReconciliation, 0, len(accruals))
+	for _, accrual := range accruals {
+		result, err := l.state.CallContractAt(ctx, ethereum.CallMsg{
+			To:   &l.collector,
+			Data: swap.PackAccruedFees(recipient, accrual.Token),
+		}, block)
+		if err != nil {
+			return nil, 0, fmt.Errorf("accruedFees call for %s failed: %w", accrual.Token.Hex(), err)
+		}
+		if len(result) != 32 {
+			return nil, 0, fmt.Errorf("invalid accruedFees response for %s: %d bytes", accrual.Token.Hex(), len(result))
+		}
+		reconciliations = append(reconciliations, entities.FeeReconciliation{
+			Token:   accrual.Token,
+			Ledger:  accrual.Claimable(),
+			Onchain: new(big.Int).SetBytes(result),
+		})
+	}
+	return reconciliations, through, nil
+}
+
+// Run restores the checkpointed ledger, then polls every interval until
+// ctx is cancelled
+func (l *FeeLedger) Run(ctx context.Context, interval time.Duration) {
+	if err := l.Load(ctx); err != nil {
+		log.Printf("fee ledger: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := l.Poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("fee ledger: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestApplyIntegratorFee(t *testing.T) {
+	feeTo := common.HexToAddress("0xf0")
+
+	quote := &entities.Quote{AmountOut: big.NewInt(10000), MinAmountOut: big.NewInt(9950)}
+	ApplyIntegratorFee(quote, 30, feeTo)
+	if quote.AmountOut.Int64() != 9970 || quote.MinAmountOut.Int64() != 9920 {
+		t.Errorf("net amounts = %s, %s, want 9970, 9920", quote.AmountOut, quote.MinAmountOut)
+	}
+	if quote.IntegratorFee == nil || quote.IntegratorFee.Amount.Int64() != 30 || quote.IntegratorFee.Recipient != feeTo {
+		t.Errorf("IntegratorFee = %+v", quote.IntegratorFee)
+	}
+
+	rfq := &entities.Quote{AmountOut: big.NewInt(10000), RFQOrder: &entities.RFQOrder{}}
+	ApplyIntegratorFee(rfq, 30, feeTo)
+	if rfq.IntegratorFee != nil || rfq.AmountOut.Int64() != 10000 {
+		t.Error("fee applied to a market maker fill")
+	}
+}
+
+type mockLogBackend struct {
+	head    uint64
+	logs    []types.Log
+	queries []ethereum.FilterQuery
+}
+
+func (m *mockLogBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *mockLogBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	m.queries = append(m.queries, query)
+	var logs []types.Log
+	for _, l := range m.logs {
+		if l.BlockNumber >= query.FromBlock.Uint64() && l.BlockNumber <= query.ToBlock.Uint64() {
+			logs = append(logs, l)
+		}
+	}
+	return logs, nil
+}
+
+func TestFeeLedger(t *testing.T) {
+	collector := common.HexToAddress("0xc0")
+	feeTo := common.HexToAddress("0xf0")
+	feeLog := func(block uint64, token entities.Token, amount int64) types.Log {
+		return types.Log{
+			Address:     collector,
+			BlockNumber: block,
+			Topics:      []common.Hash{feeCollectedTopic, common.BytesToHash(feeTo.Bytes()), common.BytesToHash(token.Address.Bytes())},
+			Data:        common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		}
+	}
+
+	backend := &mockLogBackend{head: 25003, logs: []types.Log{
+		feeLog(100, entities.USDC, 30),
+		feeLog(15000, entities.USDC, 70),
+		feeLog(20000, entities.WETH, 5),
+		feeLog(25001, entities.USDC, 1000), // Not yet confirmed
+	}}
+	ledger := NewFeeLedger(backend, collector, 100, 3)
+	if err := ledger.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(backend.queries) != 3 {
+		t.Errorf("made %d log queries, want 3 chunks of at most %d blocks", len(backend.queries), feeLogBlockRange)
+	}
+
+	accruals, through := ledger.Accrued(feeTo)
+	if through != 25000 {
+		t.Errorf("through block = %d, want 25000", through)
+	}
+	if len(accruals) != 2 {
+		t.Fatalf("Accrued() = %d tokens, want 2", len(accruals))
+	}
+	byToken := map[common.Address]entities.FeeAccrual{}
+	for _, a := range accruals {
+		byToken[a.Token] = a
+	}
+	if usdc := byToken[entities.USDC.Address]; usdc.Amount.Int64() != 100 || usdc.Swaps != 2 {
+		t.Errorf("USDC accrual = %s over %d swaps, want 100 over 2", usdc.Amount, usdc.Swaps)
+	}
+
+	// The next poll picks up where the last stopped
+	backend.head = 25010
+	backend.queries = nil
+	if err := ledger.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(backend.queries) != 1 || backend.queries[0].FromBlock.Uint64() != 25001 {
+		t.Errorf("second poll queries = %+v, want one from block 25001", backend.queries)
+	}
+	accruals, _ = ledger.Accrued(feeTo)
+	for _, a := range accruals {
+		if a.Token == entities.USDC.Address && a.Amount.Int64() != 1100 {
+			t.Errorf("USDC accrual = %s, want 1100", a.Amount)
+		}
+	}
+
+	// A restarted ledger resumes from its checkpoint, not the start block
+	store := &memFeeLedgerStore{}
+	ledger.SetStore(store)
+	backend.head = 25011
+	if err := ledger.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	restarted := NewFeeLedger(backend, collector, 100, 3)
+	restarted.SetStore(store)
+	if err := restarted.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	backend.head = 25012
+	backend.queries = nil
+	if err := restarted.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(backend.queries) != 1 || backend.queries[0].FromBlock.Uint64() != 25009 {
+		t.Errorf("restarted poll queries = %+v, want one from block 25009", backend.queries)
+	}
+	accruals, _ = restarted.Accrued(feeTo)
+	if len(accruals) != 2 || accruals[0].Amount.Int64() != 1100 {
+		t.Errorf("restored accruals = %+v, want 1100 USDC first", accruals)
+	}
+}
+
+type memFeeLedgerStore struct {
+	accruals []entities.FeeAccrual
+	next     uint64
+}
+
+func (m *memFeeLedgerStore) LoadFeeLedger(ctx context.Context, collector common.Address) ([]entities.FeeAccrual, uint64, error) {
+	return m.accruals, m.next, nil
+}
+
+func (m *memFeeLedgerStore) SaveFeeLedger(ctx context.Context, collector common.Address, accruals []entities.FeeAccrual, next uint64) error {
+	m.accruals, m.next = accruals, next
+	return nil
+}
+
+// mockCollector answers accruedFees with a balance per token, recording
+// the block it was asked at
+type mockCollector struct {
+	balances map[common.Address]int64
+	block    *big.Int
+}
+
+func (m *mockCollector) CallContractAt(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	m.block = block
+	token := common.BytesToAddress(msg.Data[4+32 : 4+64])
+	return common.LeftPadBytes(big.NewInt(m.balances[token]).Bytes(), 32), nil
+}
+
+func (m *mockCollector) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func TestFeeLedgerClaims(t *testing.T) {
+	collector := common.HexToAddress("0xc0")
+	feeTo, payTo := common.HexToAddress("0xf0"), common.HexToAddress("0xa0")
+	feeLog := func(topic common.Hash, block uint64, token entities.Token, amount int64) types.Log {
+		return types.Log{
+			Address:     collector,
+			BlockNumber: block,
+			Topics:      []common.Hash{topic, common.BytesToHash(feeTo.Bytes()), common.BytesToHash(token.Address.Bytes())},
+			Data:        common.LeftPadBytes(big.NewInt(amount).Bytes(), 32),
+		}
+	}
+
+	backend := &mockLogBackend{head: 110, logs: []types.Log{
+		feeLog(feeCollectedTopic, 100, entities.USDC, 300),
+		feeLog(feeCollectedTopic, 101, entities.WETH, 5),
+		feeLog(feeClaimedTopic, 102, entities.USDC, 250),
+		feeLog(feeCollectedTopic, 103, entities.USDC, 50),
+		feeLog(feeClaimedTopic, 104, entities.WETH, 5),
+	}}
+	ledger := NewFeeLedger(backend, collector, 100, 3)
+	if err := ledger.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if topics := backend.queries[0].Topics[0]; len(topics) != 2 {
+		t.Errorf("log query topics = %v, want FeeCollected and FeeClaimed", topics)
+	}
+
+	accruals, _ := ledger.Accrued(feeTo)
+	if usdc := accruals[0]; usdc.Token != entities.USDC.Address || usdc.Amount.Int64() != 350 || usdc.Claimed.Int64() != 250 ||
+		usdc.Claimable().Int64() != 100 || usdc.Swaps != 2 || usdc.Claims != 1 {
+		t.Errorf("USDC accrual = %+v, want 350 credited and 250 claimed", usdc)
+	}
+
+	claim, err := ledger.PrepareClaim(feeTo, entities.USDC.Address, payTo)
+	if err != nil {
+		t.Fatalf("PrepareClaim() error = %v", err)
+	}
+	if claim.Amount.Int64() != 100 || claim.ThroughBlock != 107 || claim.Transaction.From != feeTo || claim.Transaction.To != collector {
+		t.Errorf("claim = %+v, want 100 USDC through block 107, sent by the recipient to the collector", claim)
+	}
+	for _, token := range []entities.Token{entities.WETH, entities.DAI} {
+		if _, err := ledger.PrepareClaim(feeTo, token.Address, payTo); !errors.Is(err, ErrNothingToClaim) {
+			t.Errorf("PrepareClaim(%s) error = %v, want ErrNothingToClaim", token.Symbol, err)
+		}
+	}
+
+	if _, _, err := ledger.Reconcile(context.Background(), feeTo); err == nil {
+		t.Error("Reconcile() without a state reader succeeded")
+	}
+	state := &mockCollector{balances: map[common.Address]int64{entities.USDC.Address: 100, entities.WETH.Address: 7}}
+	ledger.SetStateReader(state)
+	reconciliations, through, err := ledger.Reconcile(context.Background(), feeTo)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if through != 107 || state.block.Uint64() != 107 || len(reconciliations) != 2 {
+		t.Fatalf("Reconcile() = %d tokens through %d, read at %v", len(reconciliations), through, state.block)
+	}
+	for _, r := range reconciliations {
+		if want := r.Token == entities.USDC.Address; r.Matches() != want {
+			t.Errorf("%s reconciliation = ledger %s, onchain %s, matches %v", r.Token.Hex(), r.Ledger, r.Onchain, r.Matches())
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+)
+
+var intentTypeHash = crypto.Keccak256([]byte("Intent(address owner,address sellToken,address buyToken,uint256 sellAmount,uint256 minBuyAmount,uint256 deadline,uint256 nonce)"))
+
+var (
+	ErrIntentNotFound = errors.New("intent not found")
+	ErrInvalidIntent  = errors.New("invalid intent")
+)
+
+// NewIntentDomain returns the EIP-712 domain users sign intents in
+func NewIntentDomain(chainID *big.Int, settlement common.Address) eip712.Domain {
+	return eip712.Domain{
+		Name:              "DEX Aggregator Intents",
+		Version:           "1",
+		ChainID:           chainID,
+		VerifyingContract: settlement,
+	}
+}
+
+// IntentHash returns the EIP-712 digest an owner signs for intent
+func IntentHash(domain eip712.Domain, intent *entities.Intent) common.Hash {
+	return domain.Digest(crypto.Keccak256(
+		intentTypeHash,
+		eip712.Address(intent.Owner),
+		eip712.Address(intent.SellToken.Address),
+		eip712.Address(intent.BuyToken.Address),
+		eip712.Uint(intent.SellAmount),
+		eip712.Uint(intent.MinBuyAmount),
+		eip712.Uint(new(big.Int).SetUint64(intent.Deadline)),
+		eip712.Uint(intent.Nonce),
+	))
+}
+
+// intentTypes is Intent as typed data
+var intentTypes = map[string][]eip712.Field{
+	"Intent": {
+		{Name: "owner", Type: "address"},
+		{Name: "sellToken", Type: "address"},
+		{Name: "buyToken", Type: "address"},
+		{Name: "sellAmount", Type: "uint256"},
+		{Name: "minBuyAmount", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+	},
+}
+
+// IntentTypedData returns intent as the typed data its owner signs with
+// eth_signTypedData_v4, hashing to IntentHash
+func IntentTypedData(domain eip712.Domain, intent *entities.Intent) eip712.TypedData {
+	return domain.TypedData("Intent", intentTypes, map[string]interface{}{
+		"owner":        intent.Owner.Hex(),
+		"sellToken":    intent.SellToken.Address.Hex(),
+		"buyToken":     intent.BuyToken.Address.Hex(),
+		"sellAmount":   intent.SellAmount.String(),
+		"minBuyAmount": intent.MinBuyAmount.String(),
+		"deadline":     strconv.FormatUint(intent.Deadline, 10),
+		"nonce":        intent.Nonce.String(),
+	})
+}
+
+// IntentService collects signed swap intents and proposes batch settlements
+// that match opposing intents directly and route the rest through AMMs
+type IntentService struct {
+	routerService *RouterService
+	domain        eip712.Domain
+	now           func() time.Time
+
+	mu      sync.Mutex
+	intents map[common.Hash]*entities.Intent
+}
+
+func NewIntentService(routerService *RouterService, domain eip712.Domain) *IntentService {
+	return &IntentService{
+		routerService: routerService,
+		domain:        domain,
+		now:           time.Now,
+		intents:       make(map[common.Hash]*entities.Intent),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *IntentService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// TypedData returns intent as the typed data its owner signs to submit it
+func (s *IntentService) TypedData(intent *entities.Intent) eip712.TypedData {
+	return IntentTypedData(s.domain, intent)
+}
+
+// Submit validates the intent and its owner's signature and stores it open
+func (s *IntentService) Submit(intent *entities.Intent) error {
+	if intent.SellToken.Address == intent.BuyToken.Address {
+		return fmt.Errorf("%w: sell and buy token are the same", ErrInvalidIntent)
+	}
+	if intent.SellAmount.Sign() <= 0 || intent.MinBuyAmount.Sign() <= 0 {
+		return fmt.Errorf("%w: amounts must be positive", ErrInvalidIntent)
+	}
+	if intent.Deadline <= uint64(s.now().Unix()) {
+		return fmt.Errorf("%w: deadline has passed", ErrInvalidIntent)
+	}
+
+	intent.ID = IntentHash(s.domain, intent)
+	signer, err := eip712.Recover(intent.ID, intent.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidIntent, err)
+	}
+	if signer != intent.Owner {
+		return fmt.Errorf("%w: signed by %s, not owner %s", ErrInvalidIntent, signer.Hex(), intent.Owner.Hex())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.intents[intent.ID]; exists {
+		return fmt.Errorf("%w: already submitted", ErrInvalidIntent)
+	}
+	intent.Status = entities.IntentOpen
+	intent.ReceivedAt = s.now().Unix()
+	s.intents[intent.ID] = intent
+
+	return nil
+}
+
+// Get returns a submitted intent
+func (s *IntentService) Get(id common.Hash) (*entities.Intent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intent, ok := s.intents[id]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	s.expire(intent, uint64(s.now().Unix()))
+	return intent, nil
+}
+
+// intentPair groups open intents on one token pair by direction
+type intentPair struct {
+	tokenA, tokenB entities.Token
+	sellA, sellB   []*entities.Intent
+}
+
+// ProposeSettlements batches open intents per token pair. Proposals do not
+// change intent state; intents whose limit cannot be met are left out.
+func (s *IntentService) ProposeSettlements(ctx context.Context) ([]entities.Settlement, error) {
+	now := uint64(s.now().Unix())
+	pairs := make(map[string]*intentPair)
+
+	s.mu.Lock()
+	for _, intent := range s.intents {
+		s.expire(intent, now)
+		if intent.Status != entities.IntentOpen {
+			continue
+		}
+
+		tokenA, tokenB := intent.SellToken, intent.BuyToken
+		if bytes.Compare(tokenA.Address.Bytes(), tokenB.Address.Bytes()) > 0 {
+			tokenA, tokenB = tokenB, tokenA
+		}
+		key := tokenA.Address.Hex() + "-" + tokenB.Address.Hex()
+		pair, ok := pairs[key]
+		if !ok {
+			pair = &intentPair{tokenA: tokenA, tokenB: tokenB}
+			pairs[key] = pair
+		}
+		if intent.SellToken.Address == tokenA.Address {
+			pair.sellA = append(pair.sellA, intent)
+		} else {
+			pair.sellB = append(pair.sellB, intent)
+		}
+	}
+	s.mu.Unlock()
+
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var settlements []entities.Settlement
+	for _, key := range keys {
+		settlement, err := s.settlePair(ctx, pairs[key])
+		if err != nil {
+			return nil, err
+		}
+		if settlement != nil {
+			settlements = append(settlements, *settlement)
+		}
+	}
+	return settlements, nil
+}
+
+// settlePair drops the intent furthest below its limit until every
+// remaining intent is satisfied
+func (s *IntentService) settlePair(ctx context.Context, pair *intentPair) (*entities.Settlement, error) {
+	// Opposing flow crosses at the mid price of the best route for one unit
+	// of tokenA. Its quoted output would charge the pool fee and impact to
+	// the sellers of tokenA alone, who don't trade with the pool.
+	priceNum, priceDen := big.NewInt(1), big.NewInt(1)
+	if len(pair.sellA) > 0 && len(pair.sellB) > 0 {
+		probe := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(pair.tokenA.Decimals)), nil)
+		quote, err := s.routerService.GetSmartQuote(ctx, pair.tokenA, pair.tokenB, probe, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price %s/%s: %w", pair.tokenA.Symbol, pair.tokenB.Symbol, err)
+		}
+		priceNum, priceDen = quote.BestRoute.SpotPrice()
+		if priceNum.Sign() == 0 || priceDen.Sign() == 0 {
+			// Market maker quotes have no reserves to take a mid from
+			priceNum, priceDen = quote.AmountOut, probe
+		}
+	}
+
+	for len(pair.sellA) > 0 || len(pair.sellB) > 0 {
+		settlement, err := s.match(ctx, pair, priceNum, priceDen)
+		if err != nil {
+			return nil, err
+		}
+
+		worst := -1
+		intents := append(append([]*entities.Intent{}, pair.sellA...), pair.sellB...)
+		for i, fill := range settlement.Fills {
+			if fill.BuyAmount.Cmp(intents[i].MinBuyAmount) >= 0 {
+				continue
+			}
+			// Lowest BuyAmount / MinBuyAmount is furthest from its limit
+			if worst < 0 || new(big.Int).Mul(fill.BuyAmount, intents[worst].MinBuyAmount).Cmp(
+				new(big.Int).Mul(settlement.Fills[worst].BuyAmount, intents[i].MinBuyAmount)) < 0 {
+				worst = i
+			}
+		}
+		if worst < 0 {
+			return settlement, nil
+		}
+
+		if worst < len(pair.sellA) {
+			pair.sellA = append(pair.sellA[:worst], pair.sellA[worst+1:]...)
+		} else {
+			i := worst - len(pair.sellA)
+			pair.sellB = append(pair.sellB[:i], pair.sellB[i+1:]...)
+		}
+	}
+
+	return nil, nil
+}
+
+// match crosses the two sides at priceNum/priceDen (tokenB per tokenA), sends
+// the excess side's remainder through the router and splits proceeds pro
+// rata. AMM proceeds use MinAmountOut so fills hold within slippage. The
+// remainder swap uses the flat DefaultSlippageBps so a settlement's haircut
+// doesn't depend on pair class.
+func (s *IntentService) match(ctx context.Context, pair *intentPair, priceNum, priceDen *big.Int) (*entities.Settlement, error) {
+	sumA, sumB := sumSell(pair.sellA), sumSell(pair.sellB)
+
+	settlement := &entities.Settlement{TokenA: pair.tokenA, TokenB: pair.tokenB}
+	ammOutA, ammOutB := new(big.Int), new(big.Int)
+
+	sumBInA := new(big.Int).Mul(sumB, priceDen)
+	sumBInA.Div(sumBInA, priceNum)
+
+	if sumA.Cmp(sumBInA) >= 0 {
+		settlement.MatchedA = sumBInA
+		settlement.MatchedB = sumB
+		if remainder := new(big.Int).Sub(sumA, sumBInA); remainder.Sign() > 0 {
+			quote, err := s.routerService.GetSmartQuote(ctx, pair.tokenA, pair.tokenB, remainder, DefaultSlippageBps)
+			if err != nil {
+				return nil, fmt.Errorf("failed to route remainder: %w", err)
+			}
+			settlement.AMMSwap = quote
+			ammOutB = quote.MinAmountOut
+		}
+	} else {
+		settlement.MatchedA = sumA
+		settlement.MatchedB = new(big.Int).Mul(sumA, priceNum)
+		settlement.MatchedB.Div(settlement.MatchedB, priceDen)
+		if remainder := new(big.Int).Sub(sumB, settlement.MatchedB); remainder.Sign() > 0 {
+			quote, err := s.routerService.GetSmartQuote(ctx, pair.tokenB, pair.tokenA, remainder, DefaultSlippageBps)
+			if err != nil {
+				return nil, fmt.Errorf("failed to route remainder: %w", err)
+			}
+			settlement.AMMSwap = quote
+			ammOutA = quote.MinAmountOut
+		}
+	}
+
+	settlement.Fills = append(
+		proRataFills(pair.sellA, sumA, settlement.MatchedB, ammOutB),
+		proRataFills(pair.sellB, sumB, settlement.MatchedA, ammOutA)...,
+	)
+	return settlement, nil
+}
+
+// proRataFills splits matched and AMM proceeds by each intent's sell share
+func proRataFills(intents []*entities.Intent, total, matched, amm *big.Int) []entities.IntentFill {
+	fills := make([]entities.IntentFill, 0, len(intents))
+	for _, intent := range intents {
+		matchedShare := new(big.Int).Mul(matched, intent.SellAmount)
+		matchedShare.Div(matchedShare, total)
+		ammShare := new(big.Int).Mul(amm, intent.SellAmount)
+		ammShare.Div(ammShare, total)
+
+		fills = append(fills, entities.IntentFill{
+			IntentID:         intent.ID,
+			Owner:            intent.Owner,
+			SellAmount:       intent.SellAmount,
+			BuyAmount:        new(big.Int).Add(matchedShare, ammShare),
+			MatchedBuyAmount: matchedShare,
+			AMMBuyAmount:     ammShare,
+		})
+	}
+	return fills
+}
+
+func sumSell(intents []*entities.Intent) *big.Int {
+	sum := new(big.Int)
+	for _, intent := range intents {
+		sum.Add(sum, intent.SellAmount)
+	}
+	return sum
+}
+
+// expire marks an open intent past its deadline. Callers hold s.mu.
+func (s *IntentService) expire(intent *entities.Intent, now uint64) {
+	if intent.Status == entities.IntentOpen && intent.Deadline <= now {
+		intent.Status = entities.IntentExpired
+	}
+}
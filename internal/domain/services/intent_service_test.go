@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func signedIntent(t *testing.T, service *IntentService, key *ecdsa.PrivateKey, sell, buy entities.Token, sellAmount, minBuy int64) *entities.Intent {
+	intent := &entities.Intent{
+		Owner:        crypto.PubkeyToAddress(key.PublicKey),
+		SellToken:    sell,
+		BuyToken:     buy,
+		SellAmount:   new(big.Int).Mul(big.NewInt(sellAmount), big.NewInt(1e18)),
+		MinBuyAmount: new(big.Int).Mul(big.NewInt(minBuy), big.NewInt(1e18)),
+		Deadline:     uint64(time.Now().Add(time.Hour).Unix()),
+		Nonce:        big.NewInt(0),
+	}
+	sig, err := crypto.Sign(IntentHash(service.domain, intent).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent.Signature = sig
+	return intent
+}
+
+func TestIntentServiceProposeSettlements(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   tokenA,
+		Token1:   tokenB,
+		Reserve0: new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+	service := NewIntentService(router, NewIntentDomain(big.NewInt(1), common.HexToAddress("0xdd")))
+
+	alice, _ := crypto.GenerateKey()
+	bob, _ := crypto.GenerateKey()
+	carol, _ := crypto.GenerateKey()
+
+	sellA := signedIntent(t, service, alice, tokenA, tokenB, 100, 98)
+	sellB := signedIntent(t, service, bob, tokenB, tokenA, 40, 39)
+	greedy := signedIntent(t, service, carol, tokenB, tokenA, 10, 20) // Limit far above market
+
+	for _, intent := range []*entities.Intent{sellA, sellB, greedy} {
+		if err := service.Submit(intent); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	tampered := signedIntent(t, service, alice, tokenA, tokenB, 100, 98)
+	tampered.MinBuyAmount = big.NewInt(1)
+	if err := service.Submit(tampered); !errors.Is(err, ErrInvalidIntent) {
+		t.Errorf("Submit(tampered) error = %v, want ErrInvalidIntent", err)
+	}
+
+	settlements, err := service.ProposeSettlements(context.Background())
+	if err != nil {
+		t.Fatalf("ProposeSettlements() error = %v", err)
+	}
+	if len(settlements) != 1 {
+		t.Fatalf("got %d settlements, want 1", len(settlements))
+	}
+
+	settlement := settlements[0]
+	if len(settlement.Fills) != 2 {
+		t.Fatalf("got %d fills, want 2 (greedy intent excluded)", len(settlement.Fills))
+	}
+	// Bob's 40 B cross directly; Alice's excess A goes to the AMM
+	if settlement.MatchedB.Cmp(sellB.SellAmount) != 0 || settlement.AMMSwap == nil {
+		t.Errorf("MatchedB = %s, AMMSwap = %v; want bob fully matched and an AMM remainder", settlement.MatchedB, settlement.AMMSwap != nil)
+	}
+	for _, fill := range settlement.Fills {
+		intent, _ := service.Get(fill.IntentID)
+		if fill.BuyAmount.Cmp(intent.MinBuyAmount) < 0 {
+			t.Errorf("fill for %s buys %s, below limit %s", fill.Owner.Hex(), fill.BuyAmount, intent.MinBuyAmount)
+		}
+	}
+}
+
+func TestIntentServiceCrossesAtMidPrice(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+
+	// 2 B per A, with a 1% fee that matched flow never pays
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   tokenA,
+		Token1:   tokenB,
+		Reserve0: new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(2000000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      100,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+	service := NewIntentService(router, NewIntentDomain(big.NewInt(1), common.HexToAddress("0xdd")))
+
+	alice, _ := crypto.GenerateKey()
+	bob, _ := crypto.GenerateKey()
+	sellA := signedIntent(t, service, alice, tokenA, tokenB, 10, 20)
+	sellB := signedIntent(t, service, bob, tokenB, tokenA, 20, 10)
+	for _, intent := range []*entities.Intent{sellA, sellB} {
+		if err := service.Submit(intent); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	settlements, err := service.ProposeSettlements(context.Background())
+	if err != nil {
+		t.Fatalf("ProposeSettlements() error = %v", err)
+	}
+	if len(settlements) != 1 || len(settlements[0].Fills) != 2 {
+		t.Fatalf("got %d settlements, want 1 with both intents", len(settlements))
+	}
+	// Both sides cross in full at 2 B per A; neither pays the fee to the other
+	settlement := settlements[0]
+	if settlement.AMMSwap != nil {
+		t.Errorf("AMMSwap = %+v, want none for balanced flow", settlement.AMMSwap)
+	}
+	for _, fill := range settlement.Fills {
+		intent, _ := service.Get(fill.IntentID)
+		if fill.BuyAmount.Cmp(intent.MinBuyAmount) != 0 || fill.AMMBuyAmount.Sign() != 0 {
+			t.Errorf("fill for %s buys %s (%s from the AMM), want exactly %s", fill.Owner.Hex(), fill.BuyAmount, fill.AMMBuyAmount, intent.MinBuyAmount)
+		}
+	}
+}
+
+func TestIntentTypedDataMatchesHash(t *testing.T) {
+	service := NewIntentService(nil, NewIntentDomain(big.NewInt(1), common.HexToAddress("0xdd")))
+	key, _ := crypto.GenerateKey()
+	intent := signedIntent(t, service, key, entities.WETH, entities.USDC, 2, 5000)
+
+	// The typed data a wallet is given must hash to the digest Submit checks
+	data, err := json.Marshal(service.TypedData(intent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typed apitypes.TypedData
+	if err := json.Unmarshal(data, &typed); err != nil {
+		t.Fatalf("typed data %s: %v", data, err)
+	}
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := IntentHash(service.domain, intent); !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("IntentHash() = %x, typed data hashes to %x", got, want)
+	}
+}
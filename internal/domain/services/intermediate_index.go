@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// PoolObserver is told about every pool PriceService reads
+type PoolObserver interface {
+	Observe(pair *entities.Pair)
+}
+
+// IntermediateIndex ranks tokens by how many distinct pools they were seen
+// in and serves the top N as multi-hop intermediates. The ranking is
+// recomputed by Refresh so candidates stay stable between refreshes.
+type IntermediateIndex struct {
+	size  int
+	seeds []entities.Token
+
+	mu     sync.RWMutex
+	pools  map[string]bool
+	counts map[common.Address]int
+	tokens map[common.Address]entities.Token
+	top    []entities.Token
+}
+
+// NewIntermediateIndex serves up to size candidates. Seeds are used until
+// enough pools have been observed, and break ties in pool count.
+func NewIntermediateIndex(size int, seeds []entities.Token) *IntermediateIndex {
+	idx := &IntermediateIndex{
+		size:   size,
+		seeds:  seeds,
+		pools:  make(map[string]bool),
+		counts: make(map[common.Address]int),
+		tokens: make(map[common.Address]entities.Token),
+	}
+	idx.Refresh()
+	return idx
+}
+
+// Observe records a pool and the two tokens it holds
+func (idx *IntermediateIndex) Observe(pair *entities.Pair) {
+	if pair == nil {
+		return
+	}
+	key := poolKey(pair)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if idx.pools[key] {
+		return
+	}
+	idx.pools[key] = true
+	for _, token := range []entities.Token{pair.Token0, pair.Token1} {
+		idx.counts[token.Address]++
+		if _, ok := idx.tokens[token.Address]; !ok || token.Symbol != "" {
+			idx.tokens[token.Address] = token
+		}
+	}
+}
+
+// Refresh re-ranks the observed tokens
+func (idx *IntermediateIndex) Refresh() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	seedRank := make(map[common.Address]int, len(idx.seeds))
+	candidates := make([]entities.Token, 0, len(idx.tokens)+len(idx.seeds))
+	for i, seed := range idx.seeds {
+		seedRank[seed.Address] = i + 1
+		if _, ok := idx.tokens[seed.Address]; !ok {
+			candidates = append(candidates, seed)
+		}
+	}
+	for _, token := range idx.tokens {
+		candidates = append(candidates, token)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].Address, candidates[j].Address
+		if idx.counts[a] != idx.counts[b] {
+			return idx.counts[a] > idx.counts[b]
+		}
+		// Seeds first in their given order, then by address for determinism
+		ra, rb := seedRank[a], seedRank[b]
+		switch {
+		case ra != 0 && rb != 0:
+			return ra < rb
+		case ra != 0 || rb != 0:
+			return ra != 0
+		}
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+
+	if len(candidates) > idx.size {
+		candidates = candidates[:idx.size]
+	}
+	idx.top = candidates
+}
+
+// Candidates returns the intermediates ranked at the last Refresh
+func (idx *IntermediateIndex) Candidates() []entities.Token {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]entities.Token(nil), idx.top...)
+}
+
+// Run refreshes the ranking every interval until ctx is cancelled
+func (idx *IntermediateIndex) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			idx.Refresh()
+		}
+	}
+}
+
+// poolKey identifies a pool by venue and token pair, since not every venue
+// reports a pool address
+func poolKey(pair *entities.Pair) string {
+	a, b := pair.Token0.Address.Hex(), pair.Token1.Address.Hex()
+	if b < a {
+		a, b = b, a
+	}
+	return string(pair.DEX) + ":" + pair.Address.Hex() + ":" + a + ":" + b
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestIntermediateIndex(t *testing.T) {
+	token := func(n int64, symbol string) entities.Token {
+		return entities.Token{Address: common.BigToAddress(big.NewInt(n)), Symbol: symbol}
+	}
+	weth, usdc := token(1, "WETH"), token(2, "USDC")
+	hub, a, b, c := token(10, "HUB"), token(11, "A"), token(12, "B"), token(13, "C")
+
+	index := NewIntermediateIndex(3, []entities.Token{weth, usdc})
+
+	symbols := func() []string {
+		var got []string
+		for _, token := range index.Candidates() {
+			got = append(got, token.Symbol)
+		}
+		return got
+	}
+	assertSymbols := func(want ...string) {
+		t.Helper()
+		got := symbols()
+		if len(got) != len(want) {
+			t.Fatalf("Candidates() = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Candidates() = %v, want %v", got, want)
+			}
+		}
+	}
+
+	assertSymbols("WETH", "USDC")
+
+	for _, other := range []entities.Token{a, b, c, weth} {
+		index.Observe(&entities.Pair{Token0: hub, Token1: other, DEX: entities.DEXUniswapV2})
+	}
+	index.Observe(&entities.Pair{Token0: a, Token1: b, DEX: entities.DEXUniswapV2})
+	// The same pool seen again must not count twice
+	index.Observe(&entities.Pair{Token0: b, Token1: a, DEX: entities.DEXUniswapV2})
+
+	// Unchanged until refreshed
+	assertSymbols("WETH", "USDC")
+
+	index.Refresh()
+	// HUB: 4 pools; A, B: 2; WETH, C: 1; USDC: 0
+	assertSymbols("HUB", "A", "B")
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLeaseTTL is how long a leader's lock outlives its last renewal,
+// and so how long a job goes unrun when its leader dies
+const DefaultLeaseTTL = 30 * time.Second
+
+// Locker holds named locks that expire unless extended, shared by every
+// instance using the same store
+type Locker interface {
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	Extend(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, owner string) error
+}
+
+// LeaderElection picks one instance, among those sharing a Locker, to run
+// a singleton background job. The leader holds the job's lock and renews
+// it every third of the TTL; when it stops renewing, another instance
+// takes the lock once it expires.
+type LeaderElection struct {
+	locker Locker
+	name   string
+	owner  string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+// NewLeaderElection elects owner, the instance's ID, to run the job
+// named name
+func NewLeaderElection(locker Locker, name, owner string, ttl time.Duration) *LeaderElection {
+	return &LeaderElection{locker: locker, name: name, owner: owner, ttl: ttl}
+}
+
+// IsLeader reports whether this instance holds the job's lock
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run campaigns until ctx is cancelled, running lead while this instance
+// leads and follow, which may be nil, while another does. Each runs with a
+// context cancelled when the role changes, and returns before the other
+// starts. The lock is released on the way out, so a successor need not
+// wait for it to expire.
+func (e *LeaderElection) Run(ctx context.Context, lead, follow func(ctx context.Context)) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	stop := func() {}
+	for first := true; ; first = false {
+		leading := e.campaign(ctx)
+		if was := e.leader.Swap(leading); first || was != leading {
+			stop()
+			if leading {
+				log.Printf("%s: leading as %s", e.name, e.owner)
+				stop = startJob(ctx, lead)
+			} else {
+				stop = startJob(ctx, follow)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			stop()
+			if e.leader.Swap(false) {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := e.locker.Release(releaseCtx, e.name, e.owner); err != nil {
+					log.Printf("%s: failed to release leadership: %v", e.name, err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign renews the lock if this instance holds it, else tries to take
+// it. Renewal comes first even for followers, so a leader that missed one
+// renewal keeps a lock that hasn't expired.
+func (e *LeaderElection) campaign(ctx context.Context) bool {
+	renewed, err := e.locker.Extend(ctx, e.name, e.owner, e.ttl)
+	if err == nil && !renewed {
+		renewed, err = e.locker.Acquire(ctx, e.name, e.owner, e.ttl)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("%s: leader election failed: %v", e.name, err)
+		}
+		return false
+	}
+	return renewed
+}
+
+// startJob runs job until the returned stop is called, which waits for it
+// to return
+func startJob(ctx context.Context, job func(ctx context.Context)) (stop func()) {
+	if job == nil {
+		return func() {}
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job(jobCtx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
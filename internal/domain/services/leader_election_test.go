@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memLocker is a Locker whose locks expire on the wall clock
+type memLocker struct {
+	mu    sync.Mutex
+	locks map[string]memLock
+}
+
+type memLock struct {
+	owner   string
+	expires time.Time
+}
+
+func (l *memLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[name]; ok && time.Now().Before(lock.expires) {
+		return false, nil
+	}
+	l.locks[name] = memLock{owner: owner, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (l *memLocker) Extend(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if lock, ok := l.locks[name]; !ok || lock.owner != owner || !time.Now().Before(lock.expires) {
+		return false, nil
+	}
+	l.locks[name] = memLock{owner: owner, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (l *memLocker) Release(ctx context.Context, name, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks[name].owner == owner {
+		delete(l.locks, name)
+	}
+	return nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	locker := &memLocker{locks: make(map[string]memLock)}
+	var leading, following atomic.Int32
+	job := func(counter *atomic.Int32) func(context.Context) {
+		return func(ctx context.Context) {
+			counter.Add(1)
+			<-ctx.Done()
+			counter.Add(-1)
+		}
+	}
+
+	ttl := 60 * time.Millisecond
+	a := NewLeaderElection(locker, "snapshots", "a", ttl)
+	b := NewLeaderElection(locker, "snapshots", "b", ttl)
+	ctxA, stopA := context.WithCancel(context.Background())
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	doneA := make(chan struct{})
+	go func() {
+		a.Run(ctxA, job(&leading), job(&following))
+		close(doneA)
+	}()
+	waitFor(t, a.IsLeader)
+	go b.Run(ctxB, job(&leading), job(&following))
+
+	// Renewals keep a leading across several TTLs
+	time.Sleep(3 * ttl)
+	if !a.IsLeader() || b.IsLeader() || leading.Load() != 1 || following.Load() != 1 {
+		t.Fatalf("a leads %v, b leads %v, %d leading and %d following jobs; want a alone leading", a.IsLeader(), b.IsLeader(), leading.Load(), following.Load())
+	}
+
+	// a steps down and releases the lock, so b takes over
+	stopA()
+	<-doneA
+	waitFor(t, b.IsLeader)
+	waitFor(t, func() bool { return leading.Load() == 1 && following.Load() == 0 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// LiquiditySnapshotStore is append-only storage for liquidity snapshots
+type LiquiditySnapshotStore interface {
+	Append(ctx context.Context, snapshots []entities.LiquiditySnapshot) error
+	// Scan calls fn with each snapshot matching filter, oldest first, and
+	// stops at the first error fn returns
+	Scan(ctx context.Context, filter entities.LiquiditySnapshotFilter, fn func(entities.LiquiditySnapshot) error) error
+}
+
+// SnapshotPair is a pair whose pools are snapshotted
+type SnapshotPair struct {
+	TokenA entities.Token
+	TokenB entities.Token
+}
+
+func (p SnapshotPair) String() string {
+	return p.TokenA.Symbol + "/" + p.TokenB.Symbol
+}
+
+// LiquiditySnapshotter records the reserves of every pool of a set of
+// pairs at a fixed interval, for backtesting routing strategies against
+// the liquidity they would have seen
+type LiquiditySnapshotter struct {
+	priceService *PriceService
+	store        LiquiditySnapshotStore
+	pairs        []SnapshotPair
+	now          func() time.Time
+
+	failed atomic.Uint64
+}
+
+func NewLiquiditySnapshotter(priceService *PriceService, store LiquiditySnapshotStore, pairs []SnapshotPair) *LiquiditySnapshotter {
+	return &LiquiditySnapshotter{
+		priceService: priceService,
+		store:        store,
+		pairs:        pairs,
+		now:          time.Now,
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *LiquiditySnapshotter) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Pairs returns the pairs snapshotted
+func (s *LiquiditySnapshotter) Pairs() []SnapshotPair {
+	return s.pairs
+}
+
+// Failed returns how many snapshot rounds could not be stored
+func (s *LiquiditySnapshotter) Failed() uint64 {
+	return s.failed.Load()
+}
+
+// Snapshot reads every pool of every pair and stores them as one batch
+// stamped with the same time, returning how many pools it stored
+func (s *LiquiditySnapshotter) Snapshot(ctx context.Context) (int, error) {
+	at := s.now().UTC()
+	var snapshots []entities.LiquiditySnapshot
+	for _, pair := range s.pairs {
+		for _, pool := range s.priceService.ReadPools(ctx, pair.TokenA, pair.TokenB) {
+			snapshots = append(snapshots, entities.NewLiquiditySnapshot(pool, at))
+		}
+	}
+	if len(snapshots) == 0 {
+		return 0, nil
+	}
+	if err := s.store.Append(ctx, snapshots); err != nil {
+		s.failed.Add(1)
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// Export calls fn with the stored snapshots matching filter, oldest first
+func (s *LiquiditySnapshotter) Export(ctx context.Context, filter entities.LiquiditySnapshotFilter, fn func(entities.LiquiditySnapshot) error) error {
+	return s.store.Scan(ctx, filter, fn)
+}
+
+// Run takes a snapshot now and then every interval until ctx is cancelled
+func (s *LiquiditySnapshotter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Snapshot(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("liquidity snapshot: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ReadPools reads every pool each venue holds for a pair, bypassing the
+// cache, ordered by venue and pool address. Venues that fail or hold no
+// pool are left out.
+func (s *PriceService) ReadPools(ctx context.Context, tokenA, tokenB entities.Token) []*entities.Pair {
+	var mu sync.Mutex
+	var pools []*entities.Pair
+	var wg sync.WaitGroup
+	for _, client := range s.dexClients {
+		wg.Add(1)
+		go func(c dex.DEXClient) {
+			defer wg.Done()
+			var read []*entities.Pair
+			if multi, ok := c.(dex.MultiPoolClient); ok {
+				read, _ = multi.GetPairsByTokens(ctx, tokenA, tokenB)
+			} else if pair, err := c.GetPairByTokens(ctx, tokenA, tokenB); err == nil && pair != nil {
+				read = []*entities.Pair{pair}
+			}
+			mu.Lock()
+			pools = append(pools, read...)
+			mu.Unlock()
+		}(client)
+	}
+	wg.Wait()
+
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].DEX != pools[j].DEX {
+			return pools[i].DEX < pools[j].DEX
+		}
+		return pools[i].Address.Hex() < pools[j].Address.Hex()
+	})
+	return pools
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+type memorySnapshotStore struct {
+	snapshots []entities.LiquiditySnapshot
+	err       error
+}
+
+func (s *memorySnapshotStore) Append(ctx context.Context, snapshots []entities.LiquiditySnapshot) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.snapshots = append(s.snapshots, snapshots...)
+	return nil
+}
+
+func (s *memorySnapshotStore) Scan(ctx context.Context, filter entities.LiquiditySnapshotFilter, fn func(entities.LiquiditySnapshot) error) error {
+	for _, snapshot := range s.snapshots {
+		if filter.Matches(snapshot) {
+			if err := fn(snapshot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestLiquiditySnapshotter(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	var clients []dex.DEXClient
+	for i, dexType := range []entities.DEXType{entities.DEXSushiswap, entities.DEXUniswapV2} {
+		fake := testutil.NewFakeDEX(dexType)
+		fake.SetPair(&entities.Pair{
+			Address:  common.BigToAddress(big.NewInt(int64(0x1111 + i))),
+			Token0:   token0,
+			Token1:   token1,
+			Reserve0: big.NewInt(1000),
+			Reserve1: big.NewInt(2000),
+			DEX:      dexType,
+			Fee:      30,
+		})
+		clients = append(clients, fake)
+	}
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	priceService := NewPriceService(clients, testutil.NewFakeCache(clock.Now))
+	store := &memorySnapshotStore{}
+	snapshotter := NewLiquiditySnapshotter(priceService, store, []SnapshotPair{{TokenA: token1, TokenB: token0}})
+	snapshotter.SetClock(clock.Now)
+
+	for range 2 {
+		if n, err := snapshotter.Snapshot(context.Background()); err != nil || n != 2 {
+			t.Fatalf("Snapshot() = %d, %v, want 2 pools", n, err)
+		}
+		clock.Advance(5 * time.Minute)
+	}
+
+	// Filters on the pair in either order, the venue and the window
+	var got []entities.LiquiditySnapshot
+	err := snapshotter.Export(context.Background(), entities.LiquiditySnapshotFilter{
+		TokenA: token0.Address,
+		TokenB: token1.Address,
+		DEX:    entities.DEXUniswapV2,
+		From:   time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC),
+	}, func(snapshot entities.LiquiditySnapshot) error {
+		got = append(got, snapshot)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(got) != 1 || got[0].DEX != entities.DEXUniswapV2 || !got[0].Time.Equal(time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)) {
+		t.Fatalf("Export() = %+v, want the second uniswap_v2 snapshot", got)
+	}
+	if got[0].Reserve1.Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("reserve1 = %s, want 2000", got[0].Reserve1)
+	}
+
+	store.err = errors.New("disk full")
+	if _, err := snapshotter.Snapshot(context.Background()); err == nil || snapshotter.Failed() != 1 {
+		t.Errorf("Snapshot() error = %v, failed = %d, want a counted failure", err, snapshotter.Failed())
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// OptimizeFor is what the router maximizes when choosing between routes
+type OptimizeFor string
+
+const (
+	// OptimizeOutput takes the most tokens out, whatever the gas
+	OptimizeOutput OptimizeFor = "output"
+	// OptimizeNetOutput takes the most tokens out after paying for gas
+	OptimizeNetOutput OptimizeFor = "netOutput"
+	// OptimizeGas takes the cheapest route whose output is within
+	// ComparableOutputBps of the best
+	OptimizeGas OptimizeFor = "gas"
+)
+
+// ComparableOutputBps is how far below the best output a route may pay and
+// still be taken for its lower gas under OptimizeGas (0.05%)
+const ComparableOutputBps = 5
+
+// ParseOptimizeFor reads an optimizeFor value; empty is OptimizeOutput
+func ParseOptimizeFor(value string) (OptimizeFor, error) {
+	switch mode := OptimizeFor(value); mode {
+	case "":
+		return OptimizeOutput, nil
+	case OptimizeOutput, OptimizeNetOutput, OptimizeGas:
+		return mode, nil
+	}
+	return "", fmt.Errorf("optimizeFor must be %s, %s or %s", OptimizeOutput, OptimizeNetOutput, OptimizeGas)
+}
+
+type optimizeForKey struct{}
+
+// WithOptimizeFor makes the quotes made with ctx choose their routes by
+// mode rather than by raw output
+func WithOptimizeFor(ctx context.Context, mode OptimizeFor) context.Context {
+	return context.WithValue(ctx, optimizeForKey{}, mode)
+}
+
+func optimizeForFrom(ctx context.Context) OptimizeFor {
+	if mode, ok := ctx.Value(optimizeForKey{}).(OptimizeFor); ok && mode != "" {
+		return mode
+	}
+	return OptimizeOutput
+}
+
+// routeCandidate is one way to fill a trade, by what it pays and costs
+type routeCandidate struct {
+	amountOut *big.Int
+	gas       uint64
+}
+
+func routesCandidate(routes []*entities.Route) routeCandidate {
+	amountOut := big.NewInt(0)
+	for _, route := range routes {
+		amountOut.Add(amountOut, route.AmountOut)
+	}
+	return routeCandidate{amountOut: amountOut, gas: routesGas(routes)}
+}
+
+// prefer returns the index of the candidate o chooses. Ties go to the
+// earlier candidate. Without a gas price, netOutput chooses by output.
+func (o RouteOptions) prefer(candidates []routeCandidate) int {
+	best := 0
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].amountOut.Cmp(candidates[best].amountOut) > 0 {
+			best = i
+		}
+	}
+
+	switch {
+	case o.OptimizeFor == OptimizeNetOutput && o.GasPriceOut != nil:
+		bestNet := o.netOutput(candidates[0])
+		best = 0
+		for i := 1; i < len(candidates); i++ {
+			if net := o.netOutput(candidates[i]); net.Cmp(bestNet) > 0 {
+				best, bestNet = i, net
+			}
+		}
+	case o.OptimizeFor == OptimizeGas:
+		floor := new(big.Int).Mul(candidates[best].amountOut, big.NewInt(10000-ComparableOutputBps))
+		floor.Div(floor, big.NewInt(10000))
+		for i, candidate := range candidates {
+			if candidate.amountOut.Cmp(floor) < 0 {
+				continue
+			}
+			if candidate.gas < candidates[best].gas ||
+				(candidate.gas == candidates[best].gas && candidate.amountOut.Cmp(candidates[best].amountOut) > 0) {
+				best = i
+			}
+		}
+	}
+	return best
+}
+
+// netOutput is a candidate's output less its gas, priced in tokenOut
+func (o RouteOptions) netOutput(candidate routeCandidate) *big.Int {
+	cost := new(big.Int).Mul(o.GasPriceOut, new(big.Int).SetUint64(candidate.gas))
+	cost.Div(cost, big.NewInt(1e18))
+	return cost.Sub(candidate.amountOut, cost)
+}
+
+// SetFeeService lets netOutput quotes price gas at the suggested fees
+func (s *RouterService) SetFeeService(feeService *FeeService) {
+	s.feeService = feeService
+}
+
+// gasPriceOut is what one unit of gas costs in tokenOut's raw units, as
+// 18-decimal fixed point, at the suggested fees. Nil when the fees or
+// either token's USD price can't be read.
+func (s *RouterService) gasPriceOut(ctx context.Context, tokenOut entities.Token) *big.Int {
+	if s.feeService == nil {
+		return nil
+	}
+	fees, err := s.feeService.SuggestFees(ctx)
+	if err != nil {
+		return nil
+	}
+	weiPerGas := new(big.Int).Add(fees.BaseFeePerGas, fees.MaxPriorityFeePerGas)
+	if tokenOut.Address == entities.WETH.Address {
+		return weiPerGas.Mul(weiPerGas, big.NewInt(1e18))
+	}
+
+	// wei * ethUSD / 1e18 dollars buy that many dollars / outUSD tokens
+	ethUSD, err := s.priceService.GetTokenPrice(ctx, entities.WETH)
+	if err != nil {
+		return nil
+	}
+	outUSD, err := s.priceService.GetTokenPrice(ctx, tokenOut)
+	if err != nil || outUSD.Sign() <= 0 {
+		return nil
+	}
+	price := new(big.Int).Mul(weiPerGas, ethUSD)
+	price.Mul(price, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tokenOut.Decimals)), nil))
+	return price.Div(price, outUSD)
+}
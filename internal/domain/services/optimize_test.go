@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestRouteOptionsPrefer(t *testing.T) {
+	// A split paying the most, a single pool 0.04% behind it for a third of
+	// the gas, and a cheaper pool 0.2% behind
+	candidates := []routeCandidate{
+		{amountOut: big.NewInt(1_000_000), gas: 392000},
+		{amountOut: big.NewInt(999_600), gas: 121000},
+		{amountOut: big.NewInt(998_000), gas: 100000},
+	}
+	tests := []struct {
+		name string
+		opts RouteOptions
+		want int
+	}{
+		{"output", RouteOptions{OptimizeFor: OptimizeOutput}, 0},
+		{"unset", RouteOptions{}, 0},
+		{"gas takes the cheapest comparable route", RouteOptions{OptimizeFor: OptimizeGas}, 1},
+		{"netOutput pays for gas", RouteOptions{OptimizeFor: OptimizeNetOutput, GasPriceOut: big.NewInt(1e18)}, 2},
+		{"netOutput with cheap gas", RouteOptions{OptimizeFor: OptimizeNetOutput, GasPriceOut: big.NewInt(1e15)}, 0},
+		{"netOutput without a gas price", RouteOptions{OptimizeFor: OptimizeNetOutput}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.prefer(candidates); got != tt.want {
+				t.Errorf("prefer() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptimizeFor(t *testing.T) {
+	for value, want := range map[string]OptimizeFor{"": OptimizeOutput, "output": OptimizeOutput, "netOutput": OptimizeNetOutput, "gas": OptimizeGas} {
+		if got, err := ParseOptimizeFor(value); err != nil || got != want {
+			t.Errorf("ParseOptimizeFor(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseOptimizeFor("netoutput"); err == nil {
+		t.Error("ParseOptimizeFor(netoutput) accepted a misspelling")
+	}
+}
+
+func TestRouterServiceOptimizeFor(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)) }
+	tier := func(addr string, fee uint64) *entities.Pair {
+		return &entities.Pair{
+			Address: common.HexToAddress(addr), Token0: entities.USDC, Token1: entities.WETH,
+			Reserve0: big.NewInt(200_000e6), Reserve1: ether(100), DEX: entities.DEXUniswapV3, Fee: fee,
+		}
+	}
+	low, high := tier("0x0500", 5), tier("0x3000", 30)
+	v3 := &mockMultiPoolClient{MockDEXClient: NewMockDEXClient(entities.DEXUniswapV3), pools: []*entities.Pair{low, high}}
+	v3.SetPair(entities.WETH.Address, entities.USDC.Address, low)
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{v3}, &MockCache{}))
+	routerService.SetFeeService(NewFeeService(&mockFeeHistory{history: &ethereum.FeeHistory{
+		BaseFee: []*big.Int{gwei(20)},
+		Reward:  [][]*big.Int{{gwei(2)}},
+	}}, nil))
+
+	// Splitting 0.2 WETH across both tiers pays 0.014% more, and 0.5 WETH
+	// 0.14% more, both less than the split's extra gas of about 13 USDC
+	for _, tt := range []struct {
+		mode     OptimizeFor
+		amountIn *big.Int
+		splits   int
+	}{
+		{OptimizeOutput, big.NewInt(2e17), 2},
+		{OptimizeNetOutput, big.NewInt(2e17), 0},
+		{OptimizeNetOutput, big.NewInt(5e17), 0},
+		{OptimizeGas, big.NewInt(2e17), 0},
+		{OptimizeGas, big.NewInt(5e17), 2}, // Not comparable output
+	} {
+		ctx := WithOptimizeFor(context.Background(), tt.mode)
+		quote, err := routerService.GetSmartQuote(ctx, entities.WETH, entities.USDC, tt.amountIn, 50)
+		if err != nil {
+			t.Fatalf("GetSmartQuote(%s) error = %v", tt.mode, err)
+		}
+		if len(quote.SplitRoutes) != tt.splits {
+			t.Errorf("GetSmartQuote(%s, %s) split %d ways, want %d", tt.mode, tt.amountIn, len(quote.SplitRoutes), tt.splits)
+		}
+		if tt.mode != OptimizeOutput && quote.OptimizeFor != string(tt.mode) {
+			t.Errorf("OptimizeFor = %q, want %q", quote.OptimizeFor, tt.mode)
+		}
+	}
+}
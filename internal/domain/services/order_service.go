@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+)
+
+var dutchOrderTypeHash = crypto.Keccak256([]byte("DutchOrder(address owner,address tokenIn,address tokenOut,uint256 amountIn,uint256 startAmountOut,uint256 endAmountOut,uint256 startTime,uint256 endTime,uint256 nonce)"))
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	ErrInvalidOrder  = errors.New("invalid order")
+)
+
+// NewOrderDomain returns the EIP-712 domain owners sign orders in
+func NewOrderDomain(chainID *big.Int, settlement common.Address) eip712.Domain {
+	return eip712.Domain{
+		Name:              "DEX Aggregator Orders",
+		Version:           "1",
+		ChainID:           chainID,
+		VerifyingContract: settlement,
+	}
+}
+
+// DutchOrderHash returns the EIP-712 digest an owner signs for order
+func DutchOrderHash(domain eip712.Domain, order *entities.Order) common.Hash {
+	return domain.Digest(crypto.Keccak256(
+		dutchOrderTypeHash,
+		eip712.Address(order.Owner),
+		eip712.Address(order.TokenIn.Address),
+		eip712.Address(order.TokenOut.Address),
+		eip712.Uint(order.AmountIn),
+		eip712.Uint(order.StartAmountOut),
+		eip712.Uint(order.EndAmountOut),
+		eip712.Uint(new(big.Int).SetUint64(order.StartTime)),
+		eip712.Uint(new(big.Int).SetUint64(order.EndTime)),
+		eip712.Uint(order.Nonce),
+	))
+}
+
+// OrderStore persists orders, so they outlive a restart. Fills are left
+// out; the next check finds them again.
+type OrderStore interface {
+	SaveOrder(ctx context.Context, order *entities.Order) error
+	ListOrders(ctx context.Context) ([]*entities.Order, error)
+}
+
+// DutchFillBuilder encodes a call to the order settlement contract that
+// fills a signed Dutch order through a route
+type DutchFillBuilder interface {
+	BuildDutchFill(route *entities.Route, order *entities.Order, limit *big.Int, settlement common.Address) (*entities.SwapTransaction, error)
+}
+
+// OrderService tracks signed orders and watches for AMM routes that cross
+// their limit, producing fill calldata when they do
+type OrderService struct {
+	routerService *RouterService
+	fillBuilder   DutchFillBuilder
+	domain        eip712.Domain
+	store         OrderStore
+	now           func() time.Time
+
+	mu     sync.Mutex
+	orders map[common.Hash]*entities.Order
+}
+
+func NewOrderService(routerService *RouterService, fillBuilder DutchFillBuilder, domain eip712.Domain) *OrderService {
+	return &OrderService{
+		routerService: routerService,
+		fillBuilder:   fillBuilder,
+		domain:        domain,
+		now:           time.Now,
+		orders:        make(map[common.Hash]*entities.Order),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *OrderService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetStore persists orders as they are submitted and expire, and restores
+// them on Run
+func (s *OrderService) SetStore(store OrderStore) {
+	s.store = store
+}
+
+// Load restores the orders saved by an earlier run. Orders come back open,
+// to be checked again.
+func (s *OrderService) Load(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	stored, err := s.store.ListOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, order := range stored {
+		if order.Status != entities.OrderExpired {
+			order.Status = entities.OrderOpen
+		}
+		order.Fill = nil
+		s.orders[order.ID] = order
+	}
+	return nil
+}
+
+// CreateDutch validates a Dutch order and its owner's signature
+func (s *OrderService) CreateDutch(order *entities.Order) error {
+	if order.TokenIn.Address == order.TokenOut.Address {
+		return fmt.Errorf("%w: tokenIn and tokenOut are the same", ErrInvalidOrder)
+	}
+	if order.AmountIn.Sign() <= 0 || order.EndAmountOut.Sign() <= 0 {
+		return fmt.Errorf("%w: amounts must be positive", ErrInvalidOrder)
+	}
+	if order.StartAmountOut.Cmp(order.EndAmountOut) < 0 {
+		return fmt.Errorf("%w: startAmountOut must be at least endAmountOut", ErrInvalidOrder)
+	}
+	if order.EndTime <= order.StartTime {
+		return fmt.Errorf("%w: endTime must be after startTime", ErrInvalidOrder)
+	}
+	if order.EndTime <= uint64(s.now().Unix()) {
+		return fmt.Errorf("%w: order has already ended", ErrInvalidOrder)
+	}
+
+	order.Type = entities.OrderDutch
+	order.ID = DutchOrderHash(s.domain, order)
+	signer, err := eip712.Recover(order.ID, order.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOrder, err)
+	}
+	if signer != order.Owner {
+		return fmt.Errorf("%w: signed by %s, not owner %s", ErrInvalidOrder, signer.Hex(), order.Owner.Hex())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.orders[order.ID]; exists {
+		return fmt.Errorf("%w: already submitted", ErrInvalidOrder)
+	}
+	order.Status = entities.OrderOpen
+	if err := s.save(context.Background(), order); err != nil {
+		return err
+	}
+	s.orders[order.ID] = order
+
+	return nil
+}
+
+// Get returns a snapshot of a tracked order
+func (s *OrderService) Get(id common.Hash) (*entities.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	snapshot := *order
+	return &snapshot, nil
+}
+
+// List returns snapshots of every tracked order, in no particular order
+func (s *OrderService) List() []*entities.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*entities.Order, 0, len(s.orders))
+	for _, order := range s.orders {
+		snapshot := *order
+		orders = append(orders, &snapshot)
+	}
+	return orders
+}
+
+// Run restores saved orders, then checks them every interval until ctx is
+// cancelled
+func (s *OrderService) Run(ctx context.Context, interval time.Duration) {
+	if err := s.Load(ctx); err != nil {
+		log.Printf("orders: %v", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckOrders(ctx)
+		}
+	}
+}
+
+// CheckOrders re-quotes every live order against its current limit. Orders
+// move between open and fillable as prices and the limit change.
+func (s *OrderService) CheckOrders(ctx context.Context) {
+	now := uint64(s.now().Unix())
+
+	s.mu.Lock()
+	var live []*entities.Order
+	for _, order := range s.orders {
+		if order.Status == entities.OrderExpired {
+			continue
+		}
+		if now >= order.EndTime {
+			order.Status = entities.OrderExpired
+			order.Fill = nil
+			if err := s.save(ctx, order); err != nil {
+				log.Printf("Order %s: %v", order.ID.Hex(), err)
+			}
+			continue
+		}
+		if now >= order.StartTime {
+			live = append(live, order)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, order := range live {
+		fill, err := s.checkOrder(ctx, order, now)
+		if err != nil {
+			log.Printf("Order %s check failed: %v", order.ID.Hex(), err)
+		}
+
+		s.mu.Lock()
+		order.CheckedAt = int64(now)
+		order.Fill = fill
+		if fill != nil {
+			order.Status = entities.OrderFillable
+		} else {
+			order.Status = entities.OrderOpen
+		}
+		s.mu.Unlock()
+	}
+}
+
+// save persists a copy of order without its fill. Callers hold s.mu.
+func (s *OrderService) save(ctx context.Context, order *entities.Order) error {
+	if s.store == nil {
+		return nil
+	}
+	saved := *order
+	saved.Fill = nil
+	if err := s.store.SaveOrder(ctx, &saved); err != nil {
+		return fmt.Errorf("failed to save order: %w", err)
+	}
+	return nil
+}
+
+// checkOrder returns a fill when the best single route meets the limit
+func (s *OrderService) checkOrder(ctx context.Context, order *entities.Order, now uint64) (*entities.OrderFill, error) {
+	// Single routes only: a fill must be one transaction
+	quote, err := s.routerService.GetQuote(ctx, order.TokenIn, order.TokenOut, order.AmountIn)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := order.LimitAt(now)
+	if quote.AmountOut.Cmp(limit) < 0 {
+		return nil, nil
+	}
+
+	// The owner is protected at the limit; output above it is the filler's margin
+	quote.MinAmountOut = limit
+	fill := &entities.OrderFill{
+		Quote:          quote,
+		LimitAmountOut: limit,
+		DetectedAt:     s.now().Unix(),
+	}
+	if s.fillBuilder != nil {
+		// Venues the builder can't encode are still reported, without calldata
+		tx, err := s.fillBuilder.BuildDutchFill(quote.BestRoute, order, limit, s.domain.VerifyingContract)
+		if err != nil {
+			log.Printf("Order %s fill not built: %v", order.ID.Hex(), err)
+		}
+		fill.Transaction = tx
+	}
+
+	return fill, nil
+}
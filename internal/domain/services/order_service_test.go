@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+)
+
+func TestOrderServiceCheckOrders(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   tokenA,
+		Token1:   tokenB,
+		Reserve0: new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+	settlement := common.HexToAddress("0xdd")
+	service := NewOrderService(router, swap.NewBuilder(), NewOrderDomain(big.NewInt(1), settlement))
+	store := &memOrderStore{orders: make(map[common.Hash]entities.Order)}
+	service.SetStore(store)
+
+	key, _ := crypto.GenerateKey()
+	now := uint64(time.Now().Unix())
+	newOrder := func(startOut, endOut int64) *entities.Order {
+		order := &entities.Order{
+			Owner:          crypto.PubkeyToAddress(key.PublicKey),
+			TokenIn:        tokenA,
+			TokenOut:       tokenB,
+			AmountIn:       big.NewInt(1e18),
+			StartAmountOut: big.NewInt(startOut),
+			EndAmountOut:   big.NewInt(endOut),
+			StartTime:      now - 50,
+			EndTime:        now + 50,
+			Nonce:          big.NewInt(startOut),
+		}
+		sig, _ := crypto.Sign(DutchOrderHash(service.domain, order).Bytes(), key)
+		order.Signature = sig
+		if err := service.CreateDutch(order); err != nil {
+			t.Fatalf("CreateDutch() error = %v", err)
+		}
+		return order
+	}
+
+	// The pool pays ~0.997 B per A; the first limit has decayed to ~0.8
+	crossed := newOrder(15e17, 1e17)
+	notYet := newOrder(2e18, 11e17)
+
+	service.CheckOrders(context.Background())
+
+	got, _ := service.Get(crossed.ID)
+	if got.Status != entities.OrderFillable || got.Fill == nil {
+		t.Fatalf("crossed order status = %s, want fillable with a fill", got.Status)
+	}
+	if got.Fill.Quote.MinAmountOut.Cmp(got.Fill.LimitAmountOut) != 0 {
+		t.Errorf("fill MinAmountOut = %s, want limit %s", got.Fill.Quote.MinAmountOut, got.Fill.LimitAmountOut)
+	}
+	// The fill goes through the settlement, which consumes the signed order
+	if tx := got.Fill.Transaction; tx == nil || tx.To != settlement || !bytes.Contains(tx.Data, crossed.Signature) {
+		t.Errorf("fill transaction = %+v, want a settlement call carrying the order's signature", tx)
+	}
+
+	if got, _ := service.Get(notYet.ID); got.Status != entities.OrderOpen || got.Fill != nil {
+		t.Errorf("uncrossed order status = %s, want open without a fill", got.Status)
+	}
+
+	// A restarted service restores both orders, open until checked again
+	restarted := NewOrderService(router, nil, service.domain)
+	restarted.SetStore(store)
+	if err := restarted.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, err := restarted.Get(crossed.ID); err != nil || got.Status != entities.OrderOpen || got.Fill != nil {
+		t.Errorf("restored order = %+v, %v, want open without a fill", got, err)
+	}
+	if len(restarted.List()) != 2 {
+		t.Errorf("restored %d orders, want 2", len(restarted.List()))
+	}
+}
+
+type memOrderStore struct {
+	orders map[common.Hash]entities.Order
+}
+
+func (m *memOrderStore) SaveOrder(ctx context.Context, order *entities.Order) error {
+	m.orders[order.ID] = *order
+	return nil
+}
+
+func (m *memOrderStore) ListOrders(ctx context.Context) ([]*entities.Order, error) {
+	orders := make([]*entities.Order, 0, len(m.orders))
+	for _, order := range m.orders {
+		orders = append(orders, &order)
+	}
+	return orders, nil
+}
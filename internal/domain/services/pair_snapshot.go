@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
+)
+
+// pairSnapshot holds the pools read on one request, so quoting several
+// amounts of a pair, or routes sharing a pair in either direction, reads
+// each pool once and prices every candidate against the same state with
+// the pair's own math
+type pairSnapshot struct {
+	mu      sync.Mutex
+	pairs   map[string]snapshotPair
+	pools   map[string][]*entities.Pair
+	amounts map[string]snapshotAmount
+}
+
+type snapshotPair struct {
+	pair *entities.Pair
+	err  error // The venue failed or has no pool
+}
+
+// snapshotAmount is a venue's own quote for one amount, kept for venues
+// whose pairs can't price a trade by themselves
+type snapshotAmount struct {
+	amountOut *big.Int
+	err       error
+}
+
+type pairSnapshotKey struct{}
+
+// WithPairSnapshot makes the quotes made with ctx share the pools the
+// first of them read, including venues that failed
+func WithPairSnapshot(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pairSnapshotKey{}, &pairSnapshot{
+		pairs:   make(map[string]snapshotPair),
+		pools:   make(map[string][]*entities.Pair),
+		amounts: make(map[string]snapshotAmount),
+	})
+}
+
+// ensurePairSnapshot is WithPairSnapshot unless ctx already holds one, so
+// a quote made inside another, such as the direct leg of a multi-hop
+// quote, shares the pools the outer quote read
+func ensurePairSnapshot(ctx context.Context) context.Context {
+	if pairSnapshotFrom(ctx) != nil {
+		return ctx
+	}
+	return WithPairSnapshot(ctx)
+}
+
+// pairSnapshotFrom returns the request's snapshot. A nil snapshot holds
+// nothing and keeps nothing.
+func pairSnapshotFrom(ctx context.Context) *pairSnapshot {
+	snapshot, _ := ctx.Value(pairSnapshotKey{}).(*pairSnapshot)
+	return snapshot
+}
+
+// snapshotPairKey names a venue's pool for two tokens in either order: a
+// pool is the same whichever way a route crosses it
+func snapshotPairKey(dexType entities.DEXType, tokenA, tokenB common.Address) string {
+	a, b := tokenA.Hex(), tokenB.Hex()
+	if b < a {
+		a, b = b, a
+	}
+	return cache.PairCacheKey(dexType, a, b)
+}
+
+// snapshotAmountKey names a venue's quote for amountIn in one direction
+func snapshotAmountKey(dexType entities.DEXType, tokenIn, tokenOut common.Address, amountIn *big.Int) string {
+	return fmt.Sprintf("%s:%s:%s:%s", dexType, tokenIn.Hex(), tokenOut.Hex(), amountIn)
+}
+
+func (s *pairSnapshot) pair(key string) (snapshotPair, bool) {
+	if s == nil {
+		return snapshotPair{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	held, ok := s.pairs[key]
+	return held, ok
+}
+
+func (s *pairSnapshot) setPair(key string, pair *entities.Pair, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pairs[key] = snapshotPair{pair: pair, err: err}
+}
+
+func (s *pairSnapshot) poolsFor(key string) ([]*entities.Pair, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pools, ok := s.pools[key]
+	return pools, ok
+}
+
+func (s *pairSnapshot) setPools(key string, pools []*entities.Pair) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pools[key] = pools
+}
+
+func (s *pairSnapshot) amount(key string) (snapshotAmount, bool) {
+	if s == nil {
+		return snapshotAmount{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	held, ok := s.amounts[key]
+	if ok && held.amountOut != nil {
+		held.amountOut = new(big.Int).Set(held.amountOut)
+	}
+	return held, ok
+}
+
+func (s *pairSnapshot) setAmount(key string, amountOut *big.Int, err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if amountOut != nil {
+		amountOut = new(big.Int).Set(amountOut)
+	}
+	s.amounts[key] = snapshotAmount{amountOut: amountOut, err: err}
+}
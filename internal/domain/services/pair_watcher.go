@@ -0,0 +1,312 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// syncTopic is Sync(uint112,uint112), emitted by V2-style pairs with their
+// new reserves after every swap, mint and burn
+var syncTopic = crypto.Keccak256Hash([]byte("Sync(uint112,uint112)"))
+
+// LogSubscriber streams contract logs; it needs a websocket or IPC endpoint
+type LogSubscriber interface {
+	SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+type watchedPair struct {
+	pair       *entities.Pair // Latest reserves, nil until seeded
+	subscribed bool           // Covered by the current subscription
+	live       bool           // Seeded after the subscription started
+	logBlock   uint64         // Position of the last state applied
+	logIndex   uint
+	lastUsed   time.Time
+}
+
+// PairWatcher keeps the reserves of actively quoted V2-style pairs current
+// from their Sync events, so quotes on them skip getReserves and the cache
+// TTL. Pairs join when PriceService reads them and the least recently
+// quoted are dropped past the limit. A pair is served once it has been read
+// at or after the block its subscription started, and stops being served
+// when the subscription drops or a reorg removes one of its logs.
+type PairWatcher struct {
+	source LogSubscriber
+	limit  int
+
+	mu       sync.Mutex
+	pairs    map[common.Address]*watchedPair
+	byTokens map[string]common.Address
+	since    uint64 // Head when the current subscription started
+	disabled bool
+	changed  chan struct{}
+}
+
+// PairWatcherStats is published under watched_pairs
+type PairWatcherStats struct {
+	Watched int `json:"watched"`
+	Live    int `json:"live"`
+}
+
+func NewPairWatcher(source LogSubscriber, limit int) *PairWatcher {
+	return &PairWatcher{
+		source:   source,
+		limit:    limit,
+		pairs:    make(map[common.Address]*watchedPair),
+		byTokens: make(map[string]common.Address),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Observe starts watching a V2-style pair, and seeds its reserves when the
+// read is recent enough for the subscription to carry on from it
+func (w *PairWatcher) Observe(pair *entities.Pair) {
+	if !watchable(pair) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.disabled {
+		return
+	}
+	watched, ok := w.pairs[pair.Address]
+	if !ok {
+		if len(w.pairs) >= w.limit {
+			w.evict()
+		}
+		watched = &watchedPair{}
+		w.pairs[pair.Address] = watched
+		w.byTokens[pairTokensKey(pair.DEX, pair.Token0.Address, pair.Token1.Address)] = pair.Address
+		w.notify()
+	}
+	watched.lastUsed = time.Now()
+
+	if watched.subscribed && !watched.live && pair.BlockNumber >= w.since {
+		seed := *pair
+		watched.pair = &seed
+		watched.live = true
+		// Logs from the block the reserves were read at are already in them
+		watched.logBlock, watched.logIndex = pair.BlockNumber, math.MaxUint
+	}
+}
+
+// Pair returns the live pair for two tokens on a venue, or nil when it
+// isn't watched or can't be trusted yet
+func (w *PairWatcher) Pair(dexType entities.DEXType, tokenA, tokenB common.Address) *entities.Pair {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watched, ok := w.pairs[w.byTokens[pairTokensKey(dexType, tokenA, tokenB)]]
+	if !ok || !watched.live {
+		return nil
+	}
+	watched.lastUsed = time.Now()
+	pair := *watched.pair
+	return &pair
+}
+
+// Stats reports how many pairs are watched and how many are being served
+func (w *PairWatcher) Stats() PairWatcherStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := PairWatcherStats{Watched: len(w.pairs)}
+	for _, watched := range w.pairs {
+		if watched.live {
+			stats.Live++
+		}
+	}
+	return stats
+}
+
+// Run keeps the subscription in step with the watched pairs until ctx is
+// cancelled, resubscribing at most once per interval. It returns early when
+// the endpoint can't subscribe (plain HTTP), leaving quotes on the cache.
+func (w *PairWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var sub ethereum.Subscription
+	var subErr <-chan error
+	var logs chan types.Log
+	defer func() {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+	}()
+
+	dirty, connected := false, false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.changed:
+			dirty = true
+		case <-ticker.C:
+			if !dirty {
+				continue
+			}
+			newLogs := make(chan types.Log, 256)
+			newSub, err := w.subscribe(ctx, newLogs)
+			if err != nil {
+				if !connected {
+					log.Printf("pair watcher: subscription unavailable (%v), reading reserves per quote", err)
+					w.disable()
+					return
+				}
+				log.Printf("pair watcher: failed to resubscribe: %v", err)
+				continue
+			}
+			if sub != nil {
+				sub.Unsubscribe()
+			}
+			sub, subErr, logs = newSub, newSub.Err(), newLogs
+			dirty, connected = false, true
+		case err := <-subErr:
+			log.Printf("pair watcher: subscription dropped (%v), resubscribing", err)
+			w.unsubscribeAll()
+			sub, subErr, logs = nil, nil, nil
+			dirty = true
+		case l := <-logs:
+			w.apply(l)
+		}
+	}
+}
+
+// subscribe opens a Sync subscription for every watched pair. Pairs it
+// covers have to be seeded again from a read at or after the new head.
+func (w *PairWatcher) subscribe(ctx context.Context, logs chan types.Log) (ethereum.Subscription, error) {
+	w.mu.Lock()
+	addresses := make([]common.Address, 0, len(w.pairs))
+	for addr := range w.pairs {
+		addresses = append(addresses, addr)
+	}
+	w.mu.Unlock()
+
+	sub, err := w.source.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{syncTopic}},
+	}, logs)
+	if err != nil {
+		return nil, err
+	}
+	// Logs after the head are certain to arrive on the new subscription
+	head, err := w.source.BlockNumber(ctx)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.since = head
+	for _, watched := range w.pairs {
+		watched.subscribed, watched.live = false, false
+	}
+	for _, addr := range addresses {
+		if watched, ok := w.pairs[addr]; ok {
+			watched.subscribed = true
+		}
+	}
+	return sub, nil
+}
+
+// apply updates a live pair's reserves from its Sync log
+func (w *PairWatcher) apply(l types.Log) {
+	if len(l.Topics) == 0 || l.Topics[0] != syncTopic || len(l.Data) < 64 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watched, ok := w.pairs[l.Address]
+	if !ok || !watched.live {
+		return
+	}
+	if l.Removed {
+		// Reorged out: the reserves may be from the orphaned chain
+		watched.live = false
+		return
+	}
+	if l.BlockNumber < watched.logBlock || (l.BlockNumber == watched.logBlock && l.Index <= watched.logIndex) {
+		return
+	}
+
+	updated := *watched.pair
+	updated.Reserve0 = new(big.Int).SetBytes(l.Data[0:32])
+	updated.Reserve1 = new(big.Int).SetBytes(l.Data[32:64])
+	updated.BlockNumber = l.BlockNumber
+	updated.UpdatedAt = time.Now().Unix()
+	watched.pair = &updated
+	watched.logBlock, watched.logIndex = l.BlockNumber, l.Index
+}
+
+func (w *PairWatcher) unsubscribeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, watched := range w.pairs {
+		watched.subscribed, watched.live = false, false
+	}
+}
+
+func (w *PairWatcher) disable() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.disabled = true
+	w.pairs = make(map[common.Address]*watchedPair)
+	w.byTokens = make(map[string]common.Address)
+}
+
+// evict drops the least recently quoted pair. Callers hold w.mu.
+func (w *PairWatcher) evict() {
+	var oldest common.Address
+	var oldestUsed time.Time
+	for addr, watched := range w.pairs {
+		if oldestUsed.IsZero() || watched.lastUsed.Before(oldestUsed) {
+			oldest, oldestUsed = addr, watched.lastUsed
+		}
+	}
+	delete(w.pairs, oldest)
+	for key, addr := range w.byTokens {
+		if addr == oldest {
+			delete(w.byTokens, key)
+		}
+	}
+	w.notify()
+}
+
+// notify asks Run to resubscribe. Callers hold w.mu.
+func (w *PairWatcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+// watchable reports whether pair is a constant-product pool that emits Sync
+func watchable(pair *entities.Pair) bool {
+	return pair != nil && pair.BlockNumber != 0 && pair.Stable == nil && pair.Concentrated == nil &&
+		(pair.DEX == entities.DEXUniswapV2 || pair.DEX == entities.DEXSushiswap)
+}
+
+func pairTokensKey(dexType entities.DEXType, tokenA, tokenB common.Address) string {
+	if bytes.Compare(tokenB.Bytes(), tokenA.Bytes()) < 0 {
+		tokenA, tokenB = tokenB, tokenA
+	}
+	return string(dexType) + ":" + tokenA.Hex() + ":" + tokenB.Hex()
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+type mockSubscription struct {
+	errs chan error
+}
+
+func (s *mockSubscription) Unsubscribe()      {}
+func (s *mockSubscription) Err() <-chan error { return s.errs }
+
+type mockLogSource struct {
+	head  uint64
+	query ethereum.FilterQuery
+	err   error
+}
+
+func (m *mockLogSource) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.query = query
+	return &mockSubscription{errs: make(chan error)}, nil
+}
+
+func (m *mockLogSource) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func syncLog(pair common.Address, block uint64, index uint, reserve0, reserve1 int64) types.Log {
+	data := append(common.LeftPadBytes(big.NewInt(reserve0).Bytes(), 32), common.LeftPadBytes(big.NewInt(reserve1).Bytes(), 32)...)
+	return types.Log{Address: pair, Topics: []common.Hash{syncTopic}, Data: data, BlockNumber: block, Index: index}
+}
+
+func watchedTestPair(addr common.Address, block uint64) *entities.Pair {
+	return &entities.Pair{
+		Address:     addr,
+		Token0:      entities.USDC,
+		Token1:      entities.WETH,
+		Reserve0:    big.NewInt(1000),
+		Reserve1:    big.NewInt(1),
+		DEX:         entities.DEXUniswapV2,
+		Fee:         30,
+		BlockNumber: block,
+	}
+}
+
+func TestPairWatcherFollowsSyncEvents(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1111")
+	source := &mockLogSource{head: 100}
+	w := NewPairWatcher(source, 10)
+
+	w.Observe(watchedTestPair(addr, 99))
+	if w.Pair(entities.DEXUniswapV2, entities.WETH.Address, entities.USDC.Address) != nil {
+		t.Fatal("pair served before it was subscribed")
+	}
+
+	if _, err := w.subscribe(ctx, make(chan types.Log)); err != nil {
+		t.Fatal(err)
+	}
+	if len(source.query.Addresses) != 1 || source.query.Addresses[0] != addr {
+		t.Errorf("subscribed to %v, want %s", source.query.Addresses, addr.Hex())
+	}
+
+	// A read from before the subscription started may miss logs
+	w.Observe(watchedTestPair(addr, 99))
+	if w.Pair(entities.DEXUniswapV2, entities.WETH.Address, entities.USDC.Address) != nil {
+		t.Fatal("pair seeded from a read older than the subscription")
+	}
+	w.Observe(watchedTestPair(addr, 100))
+
+	reserves := func() (int64, int64) {
+		t.Helper()
+		pair := w.Pair(entities.DEXUniswapV2, entities.WETH.Address, entities.USDC.Address)
+		if pair == nil {
+			t.Fatal("pair not served")
+		}
+		return pair.Reserve0.Int64(), pair.Reserve1.Int64()
+	}
+	if r0, r1 := reserves(); r0 != 1000 || r1 != 1 {
+		t.Fatalf("seeded reserves = %d/%d", r0, r1)
+	}
+
+	w.apply(syncLog(addr, 100, 4, 5, 5)) // Already in the seed
+	w.apply(syncLog(addr, 101, 2, 2000, 2))
+	w.apply(syncLog(addr, 101, 1, 7, 7)) // Out of order
+	if r0, r1 := reserves(); r0 != 2000 || r1 != 2 {
+		t.Errorf("reserves = %d/%d, want 2000/2", r0, r1)
+	}
+
+	removed := syncLog(addr, 101, 2, 2000, 2)
+	removed.Removed = true
+	w.apply(removed)
+	if w.Pair(entities.DEXUniswapV2, entities.USDC.Address, entities.WETH.Address) != nil {
+		t.Error("pair still served after a reorg removed its log")
+	}
+}
+
+func TestPairWatcherEvictsLeastRecentlyQuoted(t *testing.T) {
+	w := NewPairWatcher(&mockLogSource{}, 2)
+	for i, token := range []entities.Token{entities.WETH, entities.USDT, entities.DAI} {
+		pair := watchedTestPair(common.BigToAddress(big.NewInt(int64(i+1))), 1)
+		pair.Token1 = token
+		w.Observe(pair)
+	}
+
+	if stats := w.Stats(); stats.Watched != 2 {
+		t.Errorf("watched = %d, want 2", stats.Watched)
+	}
+	if _, ok := w.pairs[common.BigToAddress(big.NewInt(1))]; ok {
+		t.Error("oldest pair was not evicted")
+	}
+}
+
+func TestPairWatcherIgnoresOtherPools(t *testing.T) {
+	w := NewPairWatcher(&mockLogSource{}, 10)
+	for _, pair := range []*entities.Pair{
+		{Address: common.HexToAddress("0x1"), DEX: entities.DEXUniswapV3, BlockNumber: 1},
+		{Address: common.HexToAddress("0x2"), DEX: entities.DEXUniswapV2, BlockNumber: 1, Stable: &entities.StableCurve{}},
+		{Address: common.HexToAddress("0x3"), DEX: entities.DEXSushiswap},
+	} {
+		w.Observe(pair)
+	}
+	if stats := w.Stats(); stats.Watched != 0 {
+		t.Errorf("watched = %d, want 0", stats.Watched)
+	}
+}
+
+func TestPriceServiceServesLivePairs(t *testing.T) {
+	addr := common.HexToAddress("0x1111")
+	source := &mockLogSource{head: 100}
+	w := NewPairWatcher(source, 10)
+
+	client := NewMockDEXClient(entities.DEXUniswapV2)
+	client.SetPair(entities.USDC.Address, entities.WETH.Address, watchedTestPair(addr, 100))
+	priceService := NewPriceService([]dex.DEXClient{client}, nil)
+	priceService.SetLivePairs(w)
+
+	ctx := context.Background()
+	if _, err := priceService.GetBestPrice(ctx, entities.WETH, entities.USDC, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.subscribe(ctx, make(chan types.Log)); err != nil {
+		t.Fatal(err)
+	}
+	// The next read seeds the watcher; later ones come from the events
+	if _, err := priceService.GetBestPrice(ctx, entities.WETH, entities.USDC, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	w.apply(syncLog(addr, 150, 0, 4000, 2))
+	client.SetError(errors.New("node down"))
+
+	best, err := priceService.GetBestPrice(ctx, entities.WETH, entities.USDC, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("GetBestPrice() error = %v", err)
+	}
+	if best.Pair.BlockNumber != 150 || best.Pair.Reserve0.Int64() != 4000 {
+		t.Errorf("pair at block %d with reserve0 %s, want the Sync update", best.Pair.BlockNumber, best.Pair.Reserve0)
+	}
+}
+
+func TestPairWatcherStopsWithoutSubscriptions(t *testing.T) {
+	w := NewPairWatcher(&mockLogSource{err: errors.New("notifications not supported")}, 10)
+	w.Observe(watchedTestPair(common.HexToAddress("0x1111"), 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	w.Run(ctx, time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatal("Run() kept going after the subscription failed")
+	}
+
+	w.Observe(watchedTestPair(common.HexToAddress("0x2222"), 1))
+	if stats := w.Stats(); stats.Watched != 0 {
+		t.Errorf("watched = %d after disabling, want 0", stats.Watched)
+	}
+}
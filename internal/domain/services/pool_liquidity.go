@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// ErrBelowMinLiquidity marks a pool left out of routing as dust
+var ErrBelowMinLiquidity = errors.New("pool liquidity below minimum")
+
+// LiquidityFloor is the USD liquidity, with 18 decimals, a pool needs to be
+// routed through. Venues missing from ByDEX use Default; nil means no floor.
+type LiquidityFloor struct {
+	Default *big.Int
+	ByDEX   map[entities.DEXType]*big.Int
+}
+
+// For returns the floor for a venue, nil when it has none
+func (f LiquidityFloor) For(dex entities.DEXType) *big.Int {
+	if floor, ok := f.ByDEX[dex]; ok {
+		return floor
+	}
+	return f.Default
+}
+
+type minLiquidityKey struct{}
+
+// WithMinPoolLiquidity overrides the configured floor for every venue on
+// the requests made with ctx. Zero routes through pools of any size.
+func WithMinPoolLiquidity(ctx context.Context, usd *big.Int) context.Context {
+	return context.WithValue(ctx, minLiquidityKey{}, usd)
+}
+
+// SetLiquidityFloor leaves pools below floor out of GetPrices and
+// GetPoolPrices, so no route goes through them
+func (s *PriceService) SetLiquidityFloor(floor LiquidityFloor) {
+	s.liquidityFloor = floor
+}
+
+// floorFor returns the floor a venue's pools must clear on this request
+func (s *PriceService) floorFor(ctx context.Context, dex entities.DEXType) *big.Int {
+	if usd, ok := ctx.Value(minLiquidityKey{}).(*big.Int); ok {
+		return usd
+	}
+	return s.liquidityFloor.For(dex)
+}
+
+// pruneDust turns results from pools below their venue's floor into
+// ErrBelowMinLiquidity failures. A pool's liquidity is its subgraph TVL
+// when known, and otherwise twice the value of its reserve of whichever
+// token can be priced. Pools that can't be valued are kept.
+func (s *PriceService) pruneDust(ctx context.Context, tokenIn, tokenOut entities.Token, results []PriceResult) {
+	var prices map[common.Address]*big.Int
+	for i := range results {
+		p := &results[i]
+		floor := s.floorFor(ctx, p.DEX)
+		if !isValidPrice(*p) || floor == nil || floor.Sign() <= 0 {
+			continue
+		}
+
+		liquidity := s.poolLiquidityUSD(ctx, p.Pair, tokenIn, tokenOut, &prices)
+		if liquidity != nil && liquidity.Cmp(floor) < 0 {
+			p.Error = fmt.Errorf("%w: $%s against $%s", ErrBelowMinLiquidity, formatUSD(liquidity), formatUSD(floor))
+		}
+	}
+}
+
+// poolLiquidityUSD is a pool's subgraph TVL when known, and otherwise
+// twice the value of its reserve of whichever token can be priced; nil
+// when neither is. prices holds the side prices, tokenIn's first, across
+// calls for one pair.
+func (s *PriceService) poolLiquidityUSD(ctx context.Context, pair *entities.Pair, tokenIn, tokenOut entities.Token, prices *map[common.Address]*big.Int) *big.Int {
+	if pair.TVLUSD != nil {
+		return pair.TVLUSD
+	}
+	if *prices == nil {
+		*prices = s.sidePrices(ctx, tokenIn, tokenOut)
+	}
+	return reserveLiquidityUSD(pair, *prices)
+}
+
+// sidePrices prices the pair's tokens in USD, leaving out those it can't.
+// The pools that price them aren't held to the floor or verified.
+func (s *PriceService) sidePrices(ctx context.Context, tokens ...entities.Token) map[common.Address]*big.Int {
+	ctx = WithMinPoolLiquidity(ctx, new(big.Int))
+	ctx = context.WithValue(ctx, verifiedPoolsKey{}, false)
+	prices := make(map[common.Address]*big.Int)
+	for _, token := range tokens {
+		if price, err := s.GetTokenPrice(ctx, token); err == nil {
+			prices[token.Address] = price
+			// One side is enough to value a pool
+			break
+		}
+	}
+	return prices
+}
+
+// reserveLiquidityUSD values a pool at twice its reserve of a priced token,
+// or nil when neither token is priced
+func reserveLiquidityUSD(pair *entities.Pair, prices map[common.Address]*big.Int) *big.Int {
+	sides := []struct {
+		token   entities.Token
+		reserve *big.Int
+	}{{pair.Token0, pair.Reserve0}, {pair.Token1, pair.Reserve1}}
+	for _, side := range sides {
+		if price, ok := prices[side.token.Address]; ok && side.reserve != nil {
+			value := usdValue(side.reserve, side.token.Decimals, price)
+			return value.Mul(value, big.NewInt(2))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestPriceServiceLiquidityFloor(t *testing.T) {
+	token := entities.Token{Address: common.HexToAddress("0x00000000000000000000000000000000000000aa"), Symbol: "X", Decimals: 18}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	usd := func(n int64) *big.Int { return ether(n) }
+
+	// WETH is worth $2000; the V2 pool holds 1 WETH and Sushiswap's 100
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: new(big.Int).Mul(big.NewInt(2e9), big.NewInt(1e6)), Reserve1: ether(1e6),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	v2.SetPair(token.Address, entities.WETH.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: token, Token1: entities.WETH,
+		Reserve0: ether(1000), Reserve1: ether(1), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(token.Address, entities.WETH.Address, &entities.Pair{
+		Address: common.HexToAddress("0x3333"), Token0: token, Token1: entities.WETH,
+		Reserve0: ether(100000), Reserve1: ether(100), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+
+	tests := []struct {
+		name     string
+		floor    LiquidityFloor
+		override *big.Int
+		dust     map[entities.DEXType]bool
+	}{
+		{"no floor", LiquidityFloor{}, nil, map[entities.DEXType]bool{}},
+		{"default floor", LiquidityFloor{Default: usd(10000)}, nil, map[entities.DEXType]bool{entities.DEXUniswapV2: true}},
+		{"venue floor", LiquidityFloor{Default: usd(10000), ByDEX: map[entities.DEXType]*big.Int{entities.DEXSushiswap: usd(1000000)}}, nil,
+			map[entities.DEXType]bool{entities.DEXUniswapV2: true, entities.DEXSushiswap: true}},
+		{"request lowers the floor", LiquidityFloor{Default: usd(10000)}, new(big.Int), map[entities.DEXType]bool{}},
+		{"request raises the floor", LiquidityFloor{}, usd(1000000), map[entities.DEXType]bool{entities.DEXUniswapV2: true, entities.DEXSushiswap: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceService := NewPriceService([]dex.DEXClient{v2, sushi}, &MockCache{})
+			priceService.SetLiquidityFloor(tt.floor)
+			ctx := context.Background()
+			if tt.override != nil {
+				ctx = WithMinPoolLiquidity(ctx, tt.override)
+			}
+
+			prices, err := priceService.GetPrices(ctx, token, entities.WETH, ether(1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range prices {
+				if dust := errors.Is(p.Error, ErrBelowMinLiquidity); dust != tt.dust[p.DEX] {
+					t.Errorf("%s: error = %v, want dust %v", p.DEX, p.Error, tt.dust[p.DEX])
+				}
+			}
+
+			_, err = NewRouterService(priceService).GetQuote(ctx, token, entities.WETH, ether(1))
+			allDust := tt.dust[entities.DEXUniswapV2] && tt.dust[entities.DEXSushiswap]
+			if code := apperror.CodeOf(err); allDust != (code == apperror.InsufficientLiquidity) {
+				t.Errorf("GetQuote() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// MinPoolTVLUSD is the subgraph TVL below which a pool is treated as shallow
+// whenever a deeper pool can take the trade ($10k, 18 decimals)
+var MinPoolTVLUSD = new(big.Int).Mul(big.NewInt(10000), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// PoolStatsSource lists a venue's largest pools from an indexer
+type PoolStatsSource interface {
+	DEXes() []entities.DEXType
+	TopPools(ctx context.Context, dexType entities.DEXType, first int) ([]entities.PoolStats, error)
+}
+
+// Sort keys for PoolQuery
+const (
+	PoolSortTVL    = "tvl"
+	PoolSortVolume = "volume"
+)
+
+// PoolQuery filters, sorts and pages List results
+type PoolQuery struct {
+	DEX    entities.DEXType // Empty for every venue
+	Token  *common.Address  // Only pools holding this token
+	SortBy string           // PoolSortTVL (default) or PoolSortVolume
+	Asc    bool
+	Offset int
+	Limit  int
+}
+
+// PoolService keeps the top pools of every indexed venue in memory
+type PoolService struct {
+	source PoolStatsSource
+	perDEX int
+
+	mu        sync.RWMutex
+	pools     []entities.PoolStats
+	byAddress map[common.Address]entities.PoolStats
+}
+
+func NewPoolService(source PoolStatsSource, perDEX int) *PoolService {
+	return &PoolService{
+		source:    source,
+		perDEX:    perDEX,
+		byAddress: make(map[common.Address]entities.PoolStats),
+	}
+}
+
+// Refresh reloads every venue. A venue that fails keeps its previous pools.
+func (s *PoolService) Refresh(ctx context.Context) error {
+	fetched := make(map[entities.DEXType][]entities.PoolStats)
+	var lastErr error
+	for _, dexType := range s.source.DEXes() {
+		pools, err := s.source.TopPools(ctx, dexType, s.perDEX)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		fetched[dexType] = pools
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pools []entities.PoolStats
+	for _, p := range s.pools {
+		if _, ok := fetched[p.DEX]; !ok {
+			pools = append(pools, p)
+		}
+	}
+	for _, venuePools := range fetched {
+		pools = append(pools, venuePools...)
+	}
+
+	s.pools = pools
+	s.byAddress = make(map[common.Address]entities.PoolStats, len(pools))
+	for _, p := range pools {
+		s.byAddress[p.Address] = p
+	}
+	return lastErr
+}
+
+// Run refreshes every interval until ctx is cancelled
+func (s *PoolService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			log.Printf("Warning: pool stats refresh failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lookup returns the indexed stats for a pool address
+func (s *PoolService) Lookup(addr common.Address) (entities.PoolStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats, ok := s.byAddress[addr]
+	return stats, ok
+}
+
+// List returns one page of pools matching query and the total match count
+func (s *PoolService) List(query PoolQuery) ([]entities.PoolStats, int, error) {
+	var key func(entities.PoolStats) *big.Int
+	switch query.SortBy {
+	case "", PoolSortTVL:
+		key = func(p entities.PoolStats) *big.Int { return p.TVLUSD }
+	case PoolSortVolume:
+		key = func(p entities.PoolStats) *big.Int { return p.Volume24hUSD }
+	default:
+		return nil, 0, fmt.Errorf("unknown sort %q, use %s or %s", query.SortBy, PoolSortTVL, PoolSortVolume)
+	}
+
+	s.mu.RLock()
+	var matches []entities.PoolStats
+	for _, p := range s.pools {
+		if query.DEX != "" && p.DEX != query.DEX {
+			continue
+		}
+		if query.Token != nil && !poolHasToken(p, *query.Token) {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		cmp := key(matches[i]).Cmp(key(matches[j]))
+		if query.Asc {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	total := len(matches)
+	if query.Offset >= total {
+		return []entities.PoolStats{}, total, nil
+	}
+	end := total
+	if query.Limit > 0 && query.Offset+query.Limit < total {
+		end = query.Offset + query.Limit
+	}
+	return matches[query.Offset:end], total, nil
+}
+
+func poolHasToken(p entities.PoolStats, token common.Address) bool {
+	for _, t := range p.Tokens {
+		if t.Address == token {
+			return true
+		}
+	}
+	return false
+}
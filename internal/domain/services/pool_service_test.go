@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+type mockPoolStatsSource struct {
+	pools map[entities.DEXType][]entities.PoolStats
+	err   error
+}
+
+func (m *mockPoolStatsSource) DEXes() []entities.DEXType {
+	return []entities.DEXType{entities.DEXUniswapV2, entities.DEXUniswapV3}
+}
+
+func (m *mockPoolStatsSource) TopPools(ctx context.Context, dexType entities.DEXType, first int) ([]entities.PoolStats, error) {
+	if m.err != nil && dexType == entities.DEXUniswapV3 {
+		return nil, m.err
+	}
+	return m.pools[dexType], nil
+}
+
+func usdAmount(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), big.NewInt(1e18))
+}
+
+func TestPoolServiceList(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x01"), Symbol: "A"}
+	tokenB := entities.Token{Address: common.HexToAddress("0x02"), Symbol: "B"}
+	tokenC := entities.Token{Address: common.HexToAddress("0x03"), Symbol: "C"}
+	pool := func(dexType entities.DEXType, addr string, tvl, volume int64, tokens ...entities.Token) entities.PoolStats {
+		return entities.PoolStats{DEX: dexType, Address: common.HexToAddress(addr), Tokens: tokens, TVLUSD: usdAmount(tvl), Volume24hUSD: usdAmount(volume)}
+	}
+
+	source := &mockPoolStatsSource{pools: map[entities.DEXType][]entities.PoolStats{
+		entities.DEXUniswapV2: {pool(entities.DEXUniswapV2, "0xa1", 300, 10, tokenA, tokenB), pool(entities.DEXUniswapV2, "0xa2", 100, 50, tokenA, tokenC)},
+		entities.DEXUniswapV3: {pool(entities.DEXUniswapV3, "0xb1", 200, 30, tokenB, tokenC)},
+	}}
+	service := NewPoolService(source, 10)
+	if err := service.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tokenAAddr := tokenA.Address
+	tests := []struct {
+		name      string
+		query     PoolQuery
+		want      []string
+		wantTotal int
+	}{
+		{"default tvl desc", PoolQuery{}, []string{"0xa1", "0xb1", "0xa2"}, 3},
+		{"volume asc", PoolQuery{SortBy: PoolSortVolume, Asc: true}, []string{"0xa1", "0xb1", "0xa2"}, 3},
+		{"volume desc paged", PoolQuery{SortBy: PoolSortVolume, Offset: 1, Limit: 1}, []string{"0xb1"}, 3},
+		{"offset past end", PoolQuery{Offset: 5}, nil, 3},
+		{"by dex", PoolQuery{DEX: entities.DEXUniswapV3}, []string{"0xb1"}, 1},
+		{"by token", PoolQuery{Token: &tokenAAddr}, []string{"0xa1", "0xa2"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pools, total, err := service.List(tt.query)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if total != tt.wantTotal || len(pools) != len(tt.want) {
+				t.Fatalf("List() = %d pools of %d, want %d of %d", len(pools), total, len(tt.want), tt.wantTotal)
+			}
+			for i, addr := range tt.want {
+				if pools[i].Address != common.HexToAddress(addr) {
+					t.Errorf("pools[%d] = %s, want %s", i, pools[i].Address.Hex(), addr)
+				}
+			}
+		})
+	}
+
+	if _, _, err := service.List(PoolQuery{SortBy: "fees"}); err == nil {
+		t.Error("List(sort=fees) error = nil")
+	}
+
+	// A failing venue keeps its previous pools
+	source.err = errors.New("subgraph down")
+	source.pools[entities.DEXUniswapV2] = nil
+	if err := service.Refresh(context.Background()); err == nil {
+		t.Error("Refresh() error = nil, want the venue failure")
+	}
+	if _, ok := service.Lookup(common.HexToAddress("0xb1")); !ok {
+		t.Error("v3 pool dropped after a failed refresh")
+	}
+	if _, ok := service.Lookup(common.HexToAddress("0xa1")); ok {
+		t.Error("v2 pool kept after its venue returned no pools")
+	}
+}
+
+func TestRouterServiceSkipsLowTVLPools(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	deep := NewMockDEXClient(entities.DEXUniswapV2)
+	deep.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Address: common.HexToAddress("0xa1"), Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(10000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	// Reserves look deep on chain, but the indexer values the pool at $500
+	fake := NewMockDEXClient(entities.DEXSushiswap)
+	fake.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Address: common.HexToAddress("0xa2"), Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(12000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+
+	source := &mockPoolStatsSource{pools: map[entities.DEXType][]entities.PoolStats{
+		entities.DEXUniswapV2: {
+			{DEX: entities.DEXUniswapV2, Address: common.HexToAddress("0xa1"), TVLUSD: usdAmount(5000000), Volume24hUSD: usdAmount(1)},
+			{DEX: entities.DEXSushiswap, Address: common.HexToAddress("0xa2"), TVLUSD: usdAmount(500), Volume24hUSD: usdAmount(1)},
+		},
+	}}
+	poolService := NewPoolService(source, 10)
+	if err := poolService.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	priceService := NewPriceService([]dex.DEXClient{deep, fake}, &MockCache{})
+	priceService.SetPoolStats(poolService)
+	routerService := NewRouterService(priceService)
+
+	quote, err := routerService.GetStrategyQuote(context.Background(), "direct", token0, token1, ether(1), 50)
+	if err != nil {
+		t.Fatalf("GetStrategyQuote() error = %v", err)
+	}
+	if got := quote.BestRoute.Hops[0].Pair.DEX; got != entities.DEXUniswapV2 {
+		t.Errorf("best DEX = %s, want uniswap_v2 over the low-TVL pool", got)
+	}
+	if tvl := quote.BestRoute.Hops[0].Pair.TVLUSD; tvl == nil || tvl.Cmp(usdAmount(5000000)) != 0 {
+		t.Errorf("best pair TVLUSD = %v, want enriched $5M", tvl)
+	}
+	for _, sd := range quote.SourceDetails {
+		if sd.DEX == entities.DEXSushiswap && sd.Error != "pool TVL below minimum" {
+			t.Errorf("sushiswap detail error = %q", sd.Error)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// ErrPoolUnverified marks a pool left out of a verified-pools quote
+var ErrPoolUnverified = errors.New("pool not verified")
+
+// ListedTokens reports whether a token is on the token list, such as
+// entities.TokenRegistry
+type ListedTokens interface {
+	GetByAddress(addr common.Address) (entities.Token, bool)
+}
+
+// PoolVerifier holds pools to the verified-pool policy: deployed by a
+// canonical factory, worth at least a minimum in USD, and between tokens
+// on the token list
+type PoolVerifier struct {
+	factories map[common.Address]bool
+	minTVLUSD *big.Int // 18 decimals
+	tokens    ListedTokens
+}
+
+func NewPoolVerifier(factories []common.Address, minTVLUSD *big.Int, tokens ListedTokens) *PoolVerifier {
+	v := &PoolVerifier{factories: make(map[common.Address]bool), minTVLUSD: minTVLUSD, tokens: tokens}
+	for _, factory := range factories {
+		v.factories[factory] = true
+	}
+	return v
+}
+
+// Verify checks pair, worth liquidity in USD or nil when unknown, against
+// the policy
+func (v *PoolVerifier) Verify(pair *entities.Pair, liquidity *big.Int) *entities.PoolVerification {
+	var reasons []string
+	if !v.factories[pair.Factory] {
+		reasons = append(reasons, entities.PoolUnknownFactory)
+	}
+	switch {
+	case liquidity == nil:
+		reasons = append(reasons, entities.PoolUnknownTVL)
+	case liquidity.Cmp(v.minTVLUSD) < 0:
+		reasons = append(reasons, entities.PoolLowTVL)
+	}
+	for _, token := range []entities.Token{pair.Token0, pair.Token1} {
+		if _, ok := v.tokens.GetByAddress(token.Address); !ok {
+			reasons = append(reasons, entities.PoolUnlistedToken)
+			break
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &entities.PoolVerification{Status: entities.PoolUnverified, Reasons: reasons}
+	}
+	return &entities.PoolVerification{Status: entities.PoolVerified}
+}
+
+type verifiedPoolsKey struct{}
+
+// WithVerifiedPools routes the quotes made with ctx through verified pools
+// alone, and leaves market makers out
+func WithVerifiedPools(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verifiedPoolsKey{}, true)
+}
+
+// verifiedPoolsOnly reports whether ctx limits quotes to verified pools
+func verifiedPoolsOnly(ctx context.Context) bool {
+	only, _ := ctx.Value(verifiedPoolsKey{}).(bool)
+	return only
+}
+
+// SetPoolVerifier reports how each pool on a quote's routes measures up to
+// verifier, and lets requests limit quotes to the pools that pass
+func (s *PriceService) SetPoolVerifier(verifier *PoolVerifier) {
+	s.verifier = verifier
+}
+
+// VerifiesPools reports whether quotes can be limited to verified pools
+func (s *RouterService) VerifiesPools() bool {
+	return s.priceService.verifier != nil
+}
+
+// pruneUnverified turns results from pools that fail verification into
+// ErrPoolUnverified failures, on requests limited to verified pools
+func (s *PriceService) pruneUnverified(ctx context.Context, tokenIn, tokenOut entities.Token, results []PriceResult) {
+	if s.verifier == nil || !verifiedPoolsOnly(ctx) {
+		return
+	}
+	var prices map[common.Address]*big.Int
+	for i := range results {
+		p := &results[i]
+		if !isValidPrice(*p) {
+			continue
+		}
+		verification := s.verifier.Verify(p.Pair, s.poolLiquidityUSD(ctx, p.Pair, tokenIn, tokenOut, &prices))
+		if verification.Status != entities.PoolVerified {
+			p.Error = fmt.Errorf("%w: %s", ErrPoolUnverified, strings.Join(verification.Reasons, ", "))
+		}
+	}
+}
+
+// verifyHops sets the verification of every pool on the quote's routes
+func (s *PriceService) verifyHops(ctx context.Context, quote *entities.Quote) {
+	if s.verifier == nil {
+		return
+	}
+	routes := []*entities.Route{quote.BestRoute}
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+	}
+	for _, route := range routes {
+		if route == nil {
+			continue
+		}
+		for i := range route.Hops {
+			pair := &route.Hops[i].Pair
+			var prices map[common.Address]*big.Int
+			liquidity := s.poolLiquidityUSD(ctx, pair, tokenOf(pair, route.Hops[i].TokenIn), tokenOf(pair, route.Hops[i].TokenOut), &prices)
+			pair.Verification = s.verifier.Verify(pair, liquidity)
+		}
+	}
+}
+
+// tokenOf returns whichever of the pair's tokens is at addr
+func tokenOf(pair *entities.Pair, addr common.Address) entities.Token {
+	if pair.Token1.Address == addr {
+		return pair.Token1
+	}
+	return pair.Token0
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestPoolVerification(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	newPair := func(dexType entities.DEXType, address string, factory common.Address, usdc int64) *entities.Pair {
+		return &entities.Pair{
+			Address: common.HexToAddress(address), Token0: entities.USDC, Token1: entities.WETH,
+			Reserve0: big.NewInt(usdc * 1e6), Reserve1: ether(1_000),
+			DEX: dexType, Fee: 30, Factory: factory, TVLUSD: ether(2 * usdc),
+		}
+	}
+	// The Sushiswap pool pays more but wasn't deployed by a listed factory
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, newPair(entities.DEXUniswapV2, "0x1111", dex.UniswapV2FactoryAddress, 2_000_000))
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(entities.WETH.Address, entities.USDC.Address, newPair(entities.DEXSushiswap, "0x2222", common.HexToAddress("0xbad"), 2_100_000))
+
+	priceService := NewPriceService([]dex.DEXClient{v2, sushi}, &MockCache{})
+	router := NewRouterService(priceService)
+	if router.VerifiesPools() {
+		t.Fatal("VerifiesPools() = true before a verifier is set")
+	}
+	priceService.SetPoolVerifier(NewPoolVerifier([]common.Address{dex.UniswapV2FactoryAddress}, ether(1_000_000), entities.DefaultRegistry()))
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		dex     entities.DEXType
+		status  string
+		reasons int
+	}{
+		{"every pool", context.Background(), entities.DEXSushiswap, entities.PoolUnverified, 1},
+		{"verified pools", WithVerifiedPools(context.Background()), entities.DEXUniswapV2, entities.PoolVerified, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := router.GetStrategyQuote(tt.ctx, "direct", entities.WETH, entities.USDC, ether(1), 50)
+			if err != nil {
+				t.Fatalf("GetStrategyQuote() error = %v", err)
+			}
+			pair := quote.BestRoute.Hops[0].Pair
+			if pair.DEX != tt.dex || pair.Verification == nil || pair.Verification.Status != tt.status || len(pair.Verification.Reasons) != tt.reasons {
+				t.Errorf("hop through %s verified as %+v, want %s through %s", pair.DEX, pair.Verification, tt.status, tt.dex)
+			}
+			if quote.VerifiedPools != verifiedPoolsOnly(tt.ctx) {
+				t.Errorf("VerifiedPools = %t", quote.VerifiedPools)
+			}
+		})
+	}
+
+	// With the TVL threshold above every pool, nothing is left to route
+	priceService.SetPoolVerifier(NewPoolVerifier([]common.Address{dex.UniswapV2FactoryAddress}, ether(10_000_000), entities.DefaultRegistry()))
+	_, err := router.GetStrategyQuote(WithVerifiedPools(context.Background()), "direct", entities.WETH, entities.USDC, ether(1), 50)
+	if apperror.CodeOf(err) != apperror.NoRoute {
+		t.Errorf("quote without verified pools error = %v, want %s", err, apperror.NoRoute)
+	}
+}
+
+func TestPoolVerificationMultiHop(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// No WETH/USDC pool, so the quote routes through DAI
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.DAI.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.DAI, Token1: entities.WETH,
+		Reserve0: ether(2_000_000), Reserve1: ether(1_000),
+		DEX: entities.DEXUniswapV2, Fee: 30, Factory: dex.UniswapV2FactoryAddress, TVLUSD: ether(4_000_000),
+	})
+	v2.SetPair(entities.DAI.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.DAI, Token1: entities.USDC,
+		Reserve0: ether(2_000_000), Reserve1: big.NewInt(2_000_000e6),
+		DEX: entities.DEXUniswapV2, Fee: 30, Factory: dex.UniswapV2FactoryAddress, TVLUSD: ether(4_000_000),
+	})
+	priceService := NewPriceService([]dex.DEXClient{v2}, &MockCache{})
+	priceService.SetPoolVerifier(NewPoolVerifier([]common.Address{dex.UniswapV2FactoryAddress}, ether(1_000_000), entities.DefaultRegistry()))
+	router := NewRouterService(priceService)
+
+	quote, err := router.GetMultiHopQuote(WithVerifiedPools(context.Background()), entities.WETH, entities.USDC, ether(1), []entities.Token{entities.DAI})
+	if err != nil {
+		t.Fatalf("GetMultiHopQuote() error = %v", err)
+	}
+	if len(quote.BestRoute.Hops) != 2 || !quote.VerifiedPools {
+		t.Fatalf("quote over %d hops, VerifiedPools = %t, want a verified route through DAI", len(quote.BestRoute.Hops), quote.VerifiedPools)
+	}
+	for _, hop := range quote.BestRoute.Hops {
+		if hop.Pair.Verification == nil || hop.Pair.Verification.Status != entities.PoolVerified {
+			t.Errorf("hop through %s verified as %+v, want %s", hop.Pair.Address.Hex(), hop.Pair.Verification, entities.PoolVerified)
+		}
+	}
+}
@@ -7,15 +7,59 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
+// StablecoinPegs reports a stablecoin's USD value with 18 decimals and
+// whether it is off peg
+type StablecoinPegs interface {
+	PegPrice(token common.Address) (*big.Int, bool)
+}
+
+// PoolStatsLookup returns indexer stats for a pool address
+type PoolStatsLookup interface {
+	Lookup(addr common.Address) (entities.PoolStats, bool)
+}
+
+// LivePairs serves pairs kept current between reads, such as PairWatcher.
+// They bypass the cache and the freshness guard.
+type LivePairs interface {
+	PoolObserver
+	Pair(dexType entities.DEXType, tokenA, tokenB common.Address) *entities.Pair
+}
+
+// HeadProvider reports the chain head for reserve freshness checks
+type HeadProvider interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
 type PriceService struct {
 	dexClients []dex.DEXClient
 	cache      cache.Cache
 	cacheTTL   time.Duration
+
+	head       HeadProvider
+	maxPairAge uint64 // Blocks a pair may trail the head
+
+	poolObserver PoolObserver
+	livePairs    LivePairs
+	pegs         StablecoinPegs
+	poolStats    PoolStatsLookup
+
+	referencePaths [][]entities.Token // Tried in order by GetTokenPriceIn
+
+	liquidityFloor LiquidityFloor
+	venueStats     *VenueStats
+	verifier       *PoolVerifier
+
+	reorgMu      sync.Mutex
+	reorgEpoch   uint64 // Incremented by Invalidate
+	orphanedFrom uint64 // First block orphaned by the latest reorg
 }
 
 func NewPriceService(dexClients []dex.DEXClient, c cache.Cache) *PriceService {
@@ -23,67 +67,320 @@ func NewPriceService(dexClients []dex.DEXClient, c cache.Cache) *PriceService {
 		dexClients: dexClients,
 		cache:      c,
 		cacheTTL:   10 * time.Second, // Short TTL for price data
+
+		referencePaths: DefaultReferencePaths,
+	}
+}
+
+// SetFreshnessGuard refetches cached pairs read more than maxAgeBlocks
+// behind the chain head, and rejects fresh reads from a lagging node.
+// Wall-clock TTLs alone can serve reserves from before a reorg.
+func (s *PriceService) SetFreshnessGuard(head HeadProvider, maxAgeBlocks uint64) {
+	s.head = head
+	s.maxPairAge = maxAgeBlocks
+}
+
+// SetPoolObserver reports every pool read, cached or fresh, to observer
+func (s *PriceService) SetPoolObserver(observer PoolObserver) {
+	s.poolObserver = observer
+}
+
+// SetLivePairs serves pairs from live before the cache, and reports every
+// pool read to it
+func (s *PriceService) SetLivePairs(live LivePairs) {
+	s.livePairs = live
+}
+
+// SetStablecoinPegs stops GetTokenPrice assuming each stablecoin is worth
+// exactly $1
+func (s *PriceService) SetStablecoinPegs(pegs StablecoinPegs) {
+	s.pegs = pegs
+}
+
+// SetPoolStats attaches subgraph TVL and volume to the pairs GetPrices returns
+func (s *PriceService) SetPoolStats(lookup PoolStatsLookup) {
+	s.poolStats = lookup
+}
+
+// SetVenueStats counts every venue's quote outcomes in stats and leaves
+// out venues whose circuit it has opened
+func (s *PriceService) SetVenueStats(stats *VenueStats) {
+	s.venueStats = stats
+}
+
+// Invalidate flushes cached pairs and prices after a reorg orphaned
+// fromBlock onwards, and marks quotes in flight on those blocks as orphaned
+func (s *PriceService) Invalidate(ctx context.Context, fromBlock uint64) error {
+	s.reorgMu.Lock()
+	s.reorgEpoch++
+	s.orphanedFrom = fromBlock
+	s.reorgMu.Unlock()
+
+	if s.cache == nil {
+		return nil
 	}
+	return s.cache.Flush(ctx)
 }
 
-// PriceResult contains price data from a DEX
+// ReorgEpoch identifies the reorgs seen so far; see Orphaned
+func (s *PriceService) ReorgEpoch() uint64 {
+	s.reorgMu.Lock()
+	defer s.reorgMu.Unlock()
+	return s.reorgEpoch
+}
+
+// Orphaned reports whether reserves read at block by work that started at
+// epoch were orphaned by a reorg detected since
+func (s *PriceService) Orphaned(epoch, block uint64) bool {
+	s.reorgMu.Lock()
+	defer s.reorgMu.Unlock()
+	return s.reorgEpoch != epoch && block != 0 && block >= s.orphanedFrom
+}
+
+// isStale reports whether pair trails headBlock by more than maxPairAge.
+// headBlock 0 means the head is unknown and nothing is stale.
+func (s *PriceService) isStale(pair *entities.Pair, headBlock uint64) bool {
+	return headBlock != 0 && pair.BlockNumber+s.maxPairAge < headBlock
+}
+
+func (s *PriceService) observe(ctx context.Context, pair *entities.Pair) {
+	if s.poolObserver != nil {
+		s.poolObserver.Observe(pair)
+	}
+	// A pinned read can predate events the live pairs already skipped
+	if s.livePairs != nil && ethereum.PinnedBlock(ctx) == 0 {
+		s.livePairs.Observe(pair)
+	}
+}
+
+// enrich returns a copy of pair carrying its subgraph stats, leaving the
+// cached pair untouched
+func (s *PriceService) enrich(pair *entities.Pair) *entities.Pair {
+	if s.poolStats == nil {
+		return pair
+	}
+	stats, ok := s.poolStats.Lookup(pair.Address)
+	if !ok {
+		return pair
+	}
+	enriched := *pair
+	enriched.TVLUSD = stats.TVLUSD
+	enriched.Volume24hUSD = stats.Volume24hUSD
+	return &enriched
+}
+
+// PriceResult contains price data from a DEX. Results from GetPoolPrices
+// share a DEX and are told apart by Pair.
 type PriceResult struct {
 	DEX       entities.DEXType
 	AmountOut *big.Int
 	Pair      *entities.Pair
 	Error     error
+	Latency   time.Duration // Time spent fetching from cache or DEX
+
+	// LiquidityScore is how easily the pool absorbs amountIn, see
+	// entities.Pair.LiquidityScore
+	LiquidityScore uint64
 }
 
 func (s *PriceService) GetPrices(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) ([]PriceResult, error) {
-	results := make([]PriceResult, len(s.dexClients))
+	clients := s.clientsFor(ctx)
+	results := make([]PriceResult, len(clients))
 	var wg sync.WaitGroup
+	timing := QuoteTimingFrom(ctx)
+	snapshot := pairSnapshotFrom(ctx)
+	waitStart := time.Now()
 
-	for i, client := range s.dexClients {
+	epoch := s.ReorgEpoch()
+	pinned := ethereum.PinnedBlock(ctx)
+	var headBlock uint64
+	if pinned != 0 {
+		headBlock = pinned
+	} else if s.head != nil {
+		// Without a head the guard is skipped rather than failing the quote
+		headBlock, _ = s.head.BlockNumber(ctx)
+	}
+
+	for i, client := range clients {
 		wg.Add(1)
 		go func(idx int, c dex.DEXClient) {
 			defer wg.Done()
-
+			start := time.Now()
+			if s.venueStats != nil && !s.venueStats.Allow(c.DEXType()) {
+				results[idx] = PriceResult{DEX: c.DEXType(), Error: fmt.Errorf("%w: %s", ErrVenueCircuitOpen, c.DEXType())}
+				return
+			}
+			if c.Capabilities().NeedsOnchainQuote {
+				defer s.quoteOnchain(ctx, c, &results[idx], tokenIn, tokenOut, amountIn)
+			}
 			cacheKey := cache.PairCacheKey(c.DEXType(), tokenIn.Address.Hex(), tokenOut.Address.Hex())
+			snapshotKey := snapshotPairKey(c.DEXType(), tokenIn.Address, tokenOut.Address)
+
+			if held, ok := snapshot.pair(snapshotKey); ok {
+				if held.err != nil {
+					results[idx] = PriceResult{DEX: c.DEXType(), Error: held.err, Latency: time.Since(start)}
+					return
+				}
+				results[idx] = s.priceResult(ctx, c.DEXType(), held.pair, tokenIn, amountIn, start)
+				return
+			}
+
+			if s.livePairs != nil && pinned == 0 {
+				if livePair := s.livePairs.Pair(c.DEXType(), tokenIn.Address, tokenOut.Address); livePair != nil {
+					snapshot.setPair(snapshotKey, livePair, nil)
+					results[idx] = s.priceResult(ctx, c.DEXType(), livePair, tokenIn, amountIn, start)
+					return
+				}
+			}
 
 			if s.cache != nil {
-				if cachedPair, err := s.cache.GetPair(ctx, cacheKey); err == nil && cachedPair != nil {
-					amountOut := cachedPair.GetAmountOut(amountIn, tokenIn.Address)
-					results[idx] = PriceResult{
-						DEX:       c.DEXType(),
-						AmountOut: amountOut,
-						Pair:      cachedPair,
-					}
+				cachedPair, err := s.cache.GetPair(ctx, cacheKey)
+				timing.Add(StageCache, time.Since(start))
+				if err == nil && cachedPair != nil && !s.isStale(cachedPair, headBlock) && (pinned == 0 || cachedPair.BlockNumber == pinned) {
+					snapshot.setPair(snapshotKey, cachedPair, nil)
+					results[idx] = s.priceResult(ctx, c.DEXType(), cachedPair, tokenIn, amountIn, start)
 					return
 				}
 			}
 
 			// Fetch from DEX
+			fetchStart := time.Now()
 			pair, err := c.GetPairByTokens(ctx, tokenIn, tokenOut)
+			timing.Add(DEXStage(c.DEXType()), time.Since(fetchStart))
+			if err == nil && pair == nil {
+				err = fmt.Errorf("%w: %s/%s", dex.ErrPoolNotFound, tokenIn.Symbol, tokenOut.Symbol)
+			}
+			if err == nil && pair.BlockNumber != 0 && s.isStale(pair, headBlock) {
+				err = fmt.Errorf("reserves from block %d trail head %d", pair.BlockNumber, headBlock)
+			}
+			snapshot.setPair(snapshotKey, pair, err)
 			if err != nil {
 				results[idx] = PriceResult{
-					DEX:   c.DEXType(),
-					Error: err,
+					DEX:     c.DEXType(),
+					Error:   err,
+					Latency: time.Since(start),
 				}
 				return
 			}
 
-			if s.cache != nil {
+			// A pinned block may be behind the head, so its reads aren't cached
+			if s.cache != nil && pinned == 0 && !s.Orphaned(epoch, pair.BlockNumber) {
 				_ = s.cache.SetPair(ctx, cacheKey, pair, s.cacheTTL)
 			}
-
-			amountOut := pair.GetAmountOut(amountIn, tokenIn.Address)
-			results[idx] = PriceResult{
-				DEX:       c.DEXType(),
-				AmountOut: amountOut,
-				Pair:      pair,
-			}
+			results[idx] = s.priceResult(ctx, c.DEXType(), pair, tokenIn, amountIn, start)
 		}(i, client)
 	}
 
 	wg.Wait()
+	// Pricing pools for the liquidity floor reads pairs of its own, timed
+	// by their own calls
+	timing.wait(time.Since(waitStart))
+	s.pruneDust(ctx, tokenIn, tokenOut, results)
+	s.pruneUnverified(ctx, tokenIn, tokenOut, results)
+	if s.venueStats != nil {
+		for _, result := range results {
+			s.venueStats.record(ctx, tokenIn, tokenOut, result)
+		}
+	}
 	return results, nil
 }
 
+// GetPoolPrices quotes each pool of the venues that hold several for a
+// pair, such as every Uniswap V3 fee tier, as a venue of its own. Other
+// venues are left out. Pools are read fresh rather than from the cache,
+// since only orders large enough to split need them, unless the request
+// holds a pair snapshot that already has them.
+func (s *PriceService) GetPoolPrices(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) []PriceResult {
+	headBlock := ethereum.PinnedBlock(ctx)
+	if headBlock == 0 && s.head != nil {
+		headBlock, _ = s.head.BlockNumber(ctx)
+	}
+
+	var mu sync.Mutex
+	var results []PriceResult
+	var wg sync.WaitGroup
+	timing := QuoteTimingFrom(ctx)
+	snapshot := pairSnapshotFrom(ctx)
+	waitStart := time.Now()
+	for _, client := range s.clientsFor(ctx) {
+		multi, ok := client.(dex.MultiPoolClient)
+		if !ok || client.Capabilities().NeedsOnchainQuote {
+			// GetAmountOut quotes the venue, not each of its pools
+			continue
+		}
+		wg.Add(1)
+		go func(c dex.MultiPoolClient) {
+			defer wg.Done()
+			start := time.Now()
+			poolsKey := "pools:" + snapshotPairKey(c.DEXType(), tokenIn.Address, tokenOut.Address)
+
+			pairs, ok := snapshot.poolsFor(poolsKey)
+			if !ok {
+				var err error
+				pairs, err = c.GetPairsByTokens(ctx, tokenIn, tokenOut)
+				timing.Add(DEXStage(c.DEXType()), time.Since(start))
+				snapshot.setPools(poolsKey, pairs)
+				if err != nil {
+					return
+				}
+			}
+			for _, pair := range pairs {
+				if pair.BlockNumber != 0 && s.isStale(pair, headBlock) {
+					continue
+				}
+				result := s.priceResult(ctx, c.DEXType(), pair, tokenIn, amountIn, start)
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}(multi)
+	}
+
+	wg.Wait()
+	timing.wait(time.Since(waitStart))
+	s.pruneDust(ctx, tokenIn, tokenOut, results)
+	s.pruneUnverified(ctx, tokenIn, tokenOut, results)
+	return results
+}
+
+// priceResult quotes amountIn against a pair read from any source
+func (s *PriceService) priceResult(ctx context.Context, dexType entities.DEXType, pair *entities.Pair, tokenIn entities.Token, amountIn *big.Int, start time.Time) PriceResult {
+	s.observe(ctx, pair)
+	pair = s.enrich(pair)
+	return PriceResult{
+		DEX:            dexType,
+		AmountOut:      pair.GetAmountOut(amountIn, tokenIn.Address),
+		Pair:           pair,
+		Latency:        time.Since(start),
+		LiquidityScore: pair.LiquidityScore(amountIn, tokenIn.Address),
+	}
+}
+
+// quoteOnchain replaces a result's pair-math amount with the venue's own
+// quote, for venues whose pairs can't price a trade by themselves. A
+// request's snapshot keeps the quote for any later route asking the same.
+func (s *PriceService) quoteOnchain(ctx context.Context, c dex.DEXClient, result *PriceResult, tokenIn, tokenOut entities.Token, amountIn *big.Int) {
+	if result.Error != nil {
+		return
+	}
+	snapshot := pairSnapshotFrom(ctx)
+	amountKey := snapshotAmountKey(c.DEXType(), tokenIn.Address, tokenOut.Address, amountIn)
+	held, ok := snapshot.amount(amountKey)
+	if !ok {
+		start := time.Now()
+		held.amountOut, held.err = c.GetAmountOut(ctx, amountIn, tokenIn, tokenOut)
+		elapsed := time.Since(start)
+		QuoteTimingFrom(ctx).Add(DEXStage(c.DEXType()), elapsed)
+		result.Latency += elapsed
+		snapshot.setAmount(amountKey, held.amountOut, held.err)
+	}
+	if held.err != nil {
+		result.AmountOut, result.Error = nil, held.err
+		return
+	}
+	result.AmountOut = held.amountOut
+}
+
 func (s *PriceService) GetBestPrice(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*PriceResult, error) {
 	prices, err := s.GetPrices(ctx, tokenIn, tokenOut, amountIn)
 	if err != nil {
@@ -104,44 +401,119 @@ func (s *PriceService) GetBestPrice(ctx context.Context, tokenIn, tokenOut entit
 	}
 
 	if best == nil {
+		if err := upstreamError(prices); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("no valid prices found")
 	}
 
 	return best, nil
 }
 
-// GetTokenPrice returns the price of a token in USD (using stablecoins as reference)
+// GetTokenPrice returns the price of a token in USD, the USD index price
+// from GetTokenPriceIndex
 func (s *PriceService) GetTokenPrice(ctx context.Context, token entities.Token) (*big.Int, error) {
-	if token.Address == entities.USDC.Address {
-		// USDC price is $1 (represented as 10^18 for 18 decimal precision)
-		return new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), nil
+	index, err := s.GetTokenPriceIndex(ctx, token)
+	if err != nil {
+		return nil, err
 	}
+	return index.PriceUSD, nil
+}
 
-	// Try direct pair with USDC
-	oneToken := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(token.Decimals)), nil)
-	best, err := s.GetBestPrice(ctx, token, entities.USDC, oneToken)
-	if err == nil && best.AmountOut != nil && best.AmountOut.Sign() > 0 {
-		price := new(big.Int).Mul(best.AmountOut, new(big.Int).Exp(big.NewInt(10), big.NewInt(12), nil))
-		return price, nil
+// GetTokenPriceUSDC returns what one whole token sells for in USDC, with 18
+// decimals. Tokens without a USDC pool are priced through the reference
+// paths.
+func (s *PriceService) GetTokenPriceUSDC(ctx context.Context, token entities.Token) (*big.Int, error) {
+	return s.GetTokenPriceIn(ctx, token, entities.USDC)
+}
+
+// GetTokenPriceIn returns what one whole token sells for in reference, with
+// 18 decimals whatever the reference's own decimals. Tokens without a pool
+// against the reference are priced through the first reference path that
+// reaches it.
+func (s *PriceService) GetTokenPriceIn(ctx context.Context, token, reference entities.Token) (*big.Int, error) {
+	if token.Address == reference.Address {
+		return new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), nil
 	}
+	// Prices read every venue at the latest block, whichever the quote is
+	// limited or pinned to
+	ctx = ethereum.WithBlock(WithVenues(ctx, nil), 0)
 
-	// Try via WETH
-	if token.Address != entities.WETH.Address {
-		wethResult, err := s.GetBestPrice(ctx, token, entities.WETH, oneToken)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get price: %w", err)
+	// A path whose venues failed may have priced the token, which is worth
+	// reporting over the last path's missing pool
+	var failure error
+	for _, path := range append([][]entities.Token{nil}, s.referencePaths...) {
+		if pathTouches(path, token, reference) {
+			continue
 		}
-
-		wethPrice, err := s.GetTokenPrice(ctx, entities.WETH)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get WETH price: %w", err)
+		price, err := s.priceThrough(ctx, token, reference, path)
+		if err == nil {
+			return price, nil
 		}
+		if failure == nil || (isUpstreamFailure(err) && !isUpstreamFailure(failure)) {
+			failure = err
+		}
+	}
+	return nil, fmt.Errorf("unable to determine price for token %s: %w", token.Symbol, failure)
+}
 
-		// price = (token/WETH) * (WETH/USD)
-		price := new(big.Int).Mul(wethResult.AmountOut, wethPrice)
-		price.Div(price, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
-		return price, nil
+// AttachUSDValues prices the quote's input and output in USD and adds the
+// output lost to price impact to the price warning. A value is left out
+// when its token can't be priced.
+func (s *PriceService) AttachUSDValues(ctx context.Context, quote *entities.Quote) {
+	if price, err := s.GetTokenPrice(ctx, quote.TokenIn); err == nil {
+		quote.AmountInUSD = usdValue(quote.AmountIn, quote.TokenIn.Decimals, price)
 	}
+	price, err := s.GetTokenPrice(ctx, quote.TokenOut)
+	if err != nil {
+		return
+	}
+	quote.AmountOutUSD = usdValue(quote.AmountOut, quote.TokenOut.Decimals, price)
+
+	if quote.PriceImpact == nil || quote.PriceImpact.Sign() <= 0 {
+		return
+	}
+	// Impact is measured against the spot output, so the loss is
+	// amountOut * impact / (10000 - impact)
+	keep := new(big.Int).Sub(big.NewInt(10000), quote.PriceImpact)
+	if keep.Sign() <= 0 {
+		// Nothing comes out, so everything put in is lost
+		quote.PriceImpactUSD = quote.AmountInUSD
+	} else {
+		impact := new(big.Int).Mul(quote.AmountOutUSD, quote.PriceImpact)
+		quote.PriceImpactUSD = impact.Div(impact, keep)
+	}
+	if quote.PriceWarning != "" && quote.PriceImpactUSD != nil {
+		quote.PriceWarning += fmt.Sprintf(" (about $%s)", formatUSD(quote.PriceImpactUSD))
+	}
+}
 
-	return nil, fmt.Errorf("unable to determine price for token %s", token.Symbol)
+// scaleTo18 rescales a raw amount of a token with decimals to 18 decimals
+func scaleTo18(amount *big.Int, decimals uint8) *big.Int {
+	if decimals <= 18 {
+		return new(big.Int).Mul(amount, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(18-decimals)), nil))
+	}
+	return new(big.Int).Div(amount, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-18)), nil))
+}
+
+// usdValue converts a raw token amount to USD with 18 decimals, given the
+// token's price per whole token
+func usdValue(amount *big.Int, decimals uint8, price *big.Int) *big.Int {
+	value := new(big.Int).Mul(amount, price)
+	return value.Div(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+// DepegWarning explains how a stablecoin depeg affects token's price, or
+// returns "" when the pegs hold
+func (s *PriceService) DepegWarning(token entities.Token) string {
+	if s.pegs == nil {
+		return ""
+	}
+	if price, depegged := s.pegs.PegPrice(token.Address); depegged {
+		return fmt.Sprintf("%s is off peg at $%s against the stablecoin median", token.Symbol, formatUSD(price))
+	}
+	if price, depegged := s.pegs.PegPrice(entities.USDC.Address); depegged {
+		return fmt.Sprintf("USDC is off peg at $%s; prices are referenced to the stablecoin median instead", formatUSD(price))
+	}
+	return ""
 }
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+type mockHead uint64
+
+func (m mockHead) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(m), nil
+}
+
+func TestPriceServiceFreshnessGuard(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	newPair := func(reserve1 int64, block uint64) *entities.Pair {
+		return &entities.Pair{
+			Token0: token0, Token1: token1, DEX: entities.DEXUniswapV2, Fee: 30,
+			Reserve0: big.NewInt(1e18), Reserve1: big.NewInt(reserve1), BlockNumber: block,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		cached    *entities.Pair
+		live      *entities.Pair
+		head      uint64
+		wantBlock uint64
+		wantErr   bool
+	}{
+		{"recent cached pair is served", newPair(2e18, 100), newPair(1e18, 101), 102, 100, false},
+		{"old cached pair is refetched", newPair(2e18, 100), newPair(1e18, 110), 110, 110, false},
+		{"lagging node is rejected", nil, newPair(1e18, 100), 110, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cache.NewInMemoryCache()
+			if tt.cached != nil {
+				key := cache.PairCacheKey(entities.DEXUniswapV2, token0.Address.Hex(), token1.Address.Hex())
+				if err := c.SetPair(context.Background(), key, tt.cached, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			client := NewMockDEXClient(entities.DEXUniswapV2)
+			client.SetPair(token0.Address, token1.Address, tt.live)
+
+			priceService := NewPriceService([]dex.DEXClient{client}, c)
+			priceService.SetFreshnessGuard(mockHead(tt.head), 2)
+
+			results, err := priceService.GetPrices(context.Background(), token0, token1, big.NewInt(1e15))
+			if err != nil {
+				t.Fatalf("GetPrices() error = %v", err)
+			}
+			if (results[0].Error != nil) != tt.wantErr {
+				t.Fatalf("result error = %v, wantErr %v", results[0].Error, tt.wantErr)
+			}
+			if !tt.wantErr && results[0].Pair.BlockNumber != tt.wantBlock {
+				t.Errorf("pair block = %d, want %d", results[0].Pair.BlockNumber, tt.wantBlock)
+			}
+		})
+	}
+}
+
+func TestPriceServiceInvalidate(t *testing.T) {
+	c := cache.NewInMemoryCache()
+	key := cache.PairCacheKey(entities.DEXUniswapV2, "a", "b")
+	if err := c.SetPair(context.Background(), key, &entities.Pair{BlockNumber: 100}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	priceService := NewPriceService(nil, c)
+
+	epoch := priceService.ReorgEpoch()
+	if err := priceService.Invalidate(context.Background(), 100); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+
+	if pair, _ := c.GetPair(context.Background(), key); pair != nil {
+		t.Error("cached pair survived the reorg")
+	}
+	if !priceService.Orphaned(epoch, 100) {
+		t.Error("quote at the orphaned block is not reported orphaned")
+	}
+	if priceService.Orphaned(epoch, 99) {
+		t.Error("quote below the fork is reported orphaned")
+	}
+	if priceService.Orphaned(priceService.ReorgEpoch(), 100) {
+		t.Error("quote started after the reorg is reported orphaned")
+	}
+}
+
+func TestPriceServiceAttachUSDValues(t *testing.T) {
+	mock := NewMockDEXClient(entities.DEXUniswapV2)
+	mock.SetPair(entities.WETH.Address, entities.USDC.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   entities.USDC,
+		Token1:   entities.WETH,
+		Reserve0: new(big.Int).Mul(big.NewInt(2e9), big.NewInt(1e6)),
+		Reserve1: new(big.Int).Mul(big.NewInt(1e6), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	priceService := NewPriceService([]dex.DEXClient{mock}, &MockCache{})
+
+	wethPrice, err := priceService.GetTokenPrice(context.Background(), entities.WETH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quote := &entities.Quote{
+		TokenIn:      entities.WETH,
+		TokenOut:     entities.USDC,
+		AmountIn:     big.NewInt(1e18),
+		AmountOut:    big.NewInt(1980e6),
+		PriceImpact:  big.NewInt(100),
+		PriceWarning: "High price impact: 1.00%",
+	}
+	priceService.AttachUSDValues(context.Background(), quote)
+
+	if quote.AmountInUSD.Cmp(wethPrice) != 0 {
+		t.Errorf("AmountInUSD = %s, want %s", quote.AmountInUSD, wethPrice)
+	}
+	wantOut := new(big.Int).Mul(big.NewInt(1980), big.NewInt(1e18))
+	if quote.AmountOutUSD.Cmp(wantOut) != 0 {
+		t.Errorf("AmountOutUSD = %s, want %s", quote.AmountOutUSD, wantOut)
+	}
+	// $1980 is 99% of the spot output, so $20 was lost
+	wantImpact := new(big.Int).Mul(big.NewInt(20), big.NewInt(1e18))
+	if quote.PriceImpactUSD.Cmp(wantImpact) != 0 {
+		t.Errorf("PriceImpactUSD = %s, want %s", quote.PriceImpactUSD, wantImpact)
+	}
+	if want := "High price impact: 1.00% (about $20.0000)"; quote.PriceWarning != want {
+		t.Errorf("PriceWarning = %q, want %q", quote.PriceWarning, want)
+	}
+
+	unpriced := entities.Token{Address: common.HexToAddress("0x00000000000000000000000000000000000000aa"), Symbol: "NOPE", Decimals: 18}
+	quote = &entities.Quote{TokenIn: entities.WETH, TokenOut: unpriced, AmountIn: big.NewInt(1e18), AmountOut: big.NewInt(1e18)}
+	priceService.AttachUSDValues(context.Background(), quote)
+	if quote.AmountInUSD == nil || quote.AmountOutUSD != nil || quote.PriceImpactUSD != nil {
+		t.Errorf("unpriced output: in %v, out %v, impact %v", quote.AmountInUSD, quote.AmountOutUSD, quote.PriceImpactUSD)
+	}
+}
+
+func TestPriceServiceGetTokenPriceIn(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// WETH at 2000 USDC (6 decimals) and 2000 DAI (18 decimals)
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: big.NewInt(2_000_000_000e6), Reserve1: ether(1_000_000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	v2.SetPair(entities.WETH.Address, entities.DAI.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.DAI, Token1: entities.WETH,
+		Reserve0: ether(2_000_000_000), Reserve1: ether(1_000_000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	priceService := NewPriceService([]dex.DEXClient{v2}, &MockCache{})
+
+	for _, reference := range []entities.Token{entities.USDC, entities.DAI} {
+		price, err := priceService.GetTokenPriceIn(context.Background(), entities.WETH, reference)
+		if err != nil {
+			t.Fatalf("GetTokenPriceIn(%s) error = %v", reference.Symbol, err)
+		}
+		// 2000 less the 0.3% fee, in 18 decimals whatever the reference's
+		if price.Cmp(ether(1990)) < 0 || price.Cmp(ether(2000)) > 0 {
+			t.Errorf("GetTokenPriceIn(%s) = %s, want about 1994e18", reference.Symbol, price)
+		}
+	}
+}
+
+func TestPriceServiceReferencePaths(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	daiOnly := entities.Token{Address: common.HexToAddress("0xd1"), Symbol: "DMID", Decimals: 18}
+	btcOnly := entities.Token{Address: common.HexToAddress("0xb1"), Symbol: "BMID", Decimals: 18}
+	// DMID at 2 DAI; BMID at 0.001 WBTC, WBTC at 20 WETH and WETH at 2000 USDC
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	for i, pair := range []*entities.Pair{
+		{Token0: daiOnly, Token1: entities.DAI, Reserve0: ether(1_000_000), Reserve1: ether(2_000_000)},
+		{Token0: entities.USDC, Token1: entities.DAI, Reserve0: big.NewInt(1_000_000_000e6), Reserve1: ether(1_000_000_000)},
+		{Token0: btcOnly, Token1: entities.WBTC, Reserve0: ether(1_000_000), Reserve1: big.NewInt(1_000e8)},
+		{Token0: entities.WBTC, Token1: entities.WETH, Reserve0: big.NewInt(50_000e8), Reserve1: ether(1_000_000)},
+		{Token0: entities.USDC, Token1: entities.WETH, Reserve0: big.NewInt(2_000_000_000e6), Reserve1: ether(1_000_000)},
+	} {
+		pair.Address = common.BigToAddress(big.NewInt(int64(i + 1)))
+		pair.DEX, pair.Fee = entities.DEXUniswapV2, 30
+		v2.SetPair(pair.Token0.Address, pair.Token1.Address, pair)
+	}
+	priceService := NewPriceService([]dex.DEXClient{v2}, &MockCache{})
+
+	tests := []struct {
+		token    entities.Token
+		low, max int64 // Whole USDC, bounding the price less three hops' fees
+	}{
+		{daiOnly, 1980, 2000},
+		{btcOnly, 39_600, 40_000},
+	}
+	for _, tt := range tests {
+		price, err := priceService.GetTokenPriceIn(context.Background(), tt.token, entities.USDC)
+		if err != nil {
+			t.Fatalf("GetTokenPriceIn(%s) error = %v", tt.token.Symbol, err)
+		}
+		if price.Cmp(new(big.Int).Div(ether(tt.low), big.NewInt(1000))) < 0 || price.Cmp(new(big.Int).Div(ether(tt.max), big.NewInt(1000))) > 0 {
+			t.Errorf("GetTokenPriceIn(%s) = %s, want between %d and %d thousandths", tt.token.Symbol, price, tt.low, tt.max)
+		}
+	}
+
+	// Paths left out of the priority list aren't tried
+	priceService.SetReferencePaths([][]entities.Token{{entities.WETH}})
+	if _, err := priceService.GetTokenPriceIn(context.Background(), btcOnly, entities.USDC); err == nil {
+		t.Error("GetTokenPriceIn(BMID) through WETH alone succeeded, want an error")
+	}
+}
+
+func TestPriceServiceQuotesOnchainWhenPairsCantPrice(t *testing.T) {
+	pair := &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.DAI,
+		Reserve0: big.NewInt(1e12), Reserve1: new(big.Int).Mul(big.NewInt(1e6), big.NewInt(1e18)),
+		DEX: entities.DEXCurve, Fee: 4,
+	}
+	quoted := new(big.Int).Mul(big.NewInt(999), big.NewInt(1e18))
+	amountIn := big.NewInt(1000e6)
+
+	tests := []struct {
+		name string
+		caps dex.Capabilities
+		want *big.Int
+	}{
+		{"pair math", dex.Capabilities{}, pair.GetAmountOut(amountIn, entities.USDC.Address)},
+		{"venue quote", dex.Capabilities{NeedsOnchainQuote: true}, quoted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curve := NewMockDEXClient(entities.DEXCurve)
+			curve.SetPair(entities.USDC.Address, entities.DAI.Address, pair)
+			curve.SetAmountOut(quoted)
+			curve.SetCapabilities(tt.caps)
+			results, err := NewPriceService([]dex.DEXClient{curve}, &MockCache{}).GetPrices(context.Background(), entities.USDC, entities.DAI, amountIn)
+			if err != nil {
+				t.Fatalf("GetPrices() error = %v", err)
+			}
+			if got := results[0].AmountOut; got == nil || got.Cmp(tt.want) != 0 {
+				t.Errorf("AmountOut = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceServicePairSnapshot(t *testing.T) {
+	pair := &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.USDC, Token1: entities.DAI,
+		Reserve0: big.NewInt(1e12), Reserve1: new(big.Int).Mul(big.NewInt(1e6), big.NewInt(1e18)),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	}
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.USDC.Address, entities.DAI.Address, pair)
+	service := NewPriceService([]dex.DEXClient{v2}, &MockCache{})
+	ctx := WithPairSnapshot(context.Background())
+
+	if _, err := service.GetPrices(ctx, entities.USDC, entities.DAI, big.NewInt(1000e6)); err != nil {
+		t.Fatalf("GetPrices() error = %v", err)
+	}
+	// Later sizes of the request price against the pool the first one read
+	v2.SetError(errors.New("node down"))
+	for _, amountIn := range []*big.Int{big.NewInt(5000e6), big.NewInt(25000e6)} {
+		results, err := service.GetPrices(ctx, entities.USDC, entities.DAI, amountIn)
+		if err != nil {
+			t.Fatalf("GetPrices(%s) error = %v", amountIn, err)
+		}
+		want := pair.GetAmountOut(amountIn, entities.USDC.Address)
+		if got := results[0].AmountOut; got == nil || got.Cmp(want) != 0 {
+			t.Errorf("GetPrices(%s) AmountOut = %v, want %s", amountIn, got, want)
+		}
+	}
+
+	if results, _ := service.GetPrices(context.Background(), entities.USDC, entities.DAI, big.NewInt(1000e6)); len(results) > 0 && results[0].Error == nil {
+		t.Error("a request without the snapshot reused its pool")
+	}
+}
+
+func TestPriceServiceGetTokenPriceIndex(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// WETH sells for 2000 USDC, 2010 USDT and 3000 DAI, a DAI pool off the market
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	for i, leg := range []struct {
+		stable  entities.Token
+		reserve *big.Int
+	}{
+		{entities.USDC, big.NewInt(2_000_000_000e6)},
+		{entities.USDT, big.NewInt(2_010_000_000e6)},
+		{entities.DAI, ether(3_000_000_000)},
+	} {
+		v2.SetPair(entities.WETH.Address, leg.stable.Address, &entities.Pair{
+			Address: common.BigToAddress(big.NewInt(int64(i + 1))), Token0: leg.stable, Token1: entities.WETH,
+			Reserve0: leg.reserve, Reserve1: ether(1_000_000), DEX: entities.DEXUniswapV2,
+		})
+	}
+	priceService := NewPriceService([]dex.DEXClient{v2}, &MockCache{})
+
+	index, err := priceService.GetTokenPriceIndex(context.Background(), entities.WETH)
+	if err != nil {
+		t.Fatalf("GetTokenPriceIndex() error = %v", err)
+	}
+	// The USDT leg is the median, not the outlying DAI pool
+	if got := formatUSD(index.PriceUSD); got != "2009.9979" {
+		t.Errorf("PriceUSD = $%s, want the USDT leg", got)
+	}
+	want := map[string]struct {
+		median       bool
+		deviationBps int64
+	}{"USDC": {false, -49}, "USDT": {true, 0}, "DAI": {false, 4925}}
+	for _, leg := range index.Legs {
+		w := want[leg.Stable.Symbol]
+		if leg.Error != nil || leg.Median != w.median || leg.DeviationBps != w.deviationBps {
+			t.Errorf("%s leg = median %v, %d bps, error %v; want %v, %d bps", leg.Stable.Symbol, leg.Median, leg.DeviationBps, leg.Error, w.median, w.deviationBps)
+		}
+	}
+
+	if price, err := priceService.GetTokenPrice(context.Background(), entities.WETH); err != nil || price.Cmp(index.PriceUSD) != 0 {
+		t.Errorf("GetTokenPrice() = %v, %v, want the index price", price, err)
+	}
+}
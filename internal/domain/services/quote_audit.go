@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// NewQuoteAudit records the pool states and amounts a finished quote was
+// derived from. Build it after fees and slippage are applied.
+func NewQuoteAudit(quote *entities.Quote) *entities.QuoteAudit {
+	audit := &entities.QuoteAudit{
+		TokenIn:       quote.TokenIn.Address,
+		TokenOut:      quote.TokenOut.Address,
+		AmountIn:      quote.AmountIn,
+		QuotedAtBlock: quote.QuotedAtBlock,
+		SlippageBps:   quote.SlippageBps,
+		AmountOut:     quote.AmountOut,
+		MinAmountOut:  quote.MinAmountOut,
+		RFQOrder:      quote.RFQOrder,
+	}
+	audit.RouteAmountOut = quote.AmountOut
+	if quote.IntegratorFee != nil {
+		audit.IntegratorFeeBps = quote.IntegratorFee.Bps
+		audit.RouteAmountOut = new(big.Int).Add(quote.AmountOut, quote.IntegratorFee.Amount)
+	}
+
+	var routes []*entities.Route
+	var amounts [][2]*big.Int
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+		amounts = append(amounts, [2]*big.Int{split.AmountIn, split.AmountOut})
+	}
+	if len(routes) == 0 && quote.BestRoute != nil {
+		routes = append(routes, quote.BestRoute)
+		amounts = append(amounts, [2]*big.Int{quote.AmountIn, audit.RouteAmountOut})
+	}
+
+	// A pool read once is listed once, however many hops use it
+	seen := make(map[string]int)
+	for i, route := range routes {
+		auditRoute := entities.AuditRoute{AmountIn: amounts[i][0], AmountOut: amounts[i][1]}
+		amount := amounts[i][0]
+		for _, hop := range route.Hops {
+			key := fmt.Sprintf("%s@%d", hop.Pair.Address.Hex(), hop.Pair.BlockNumber)
+			pool, ok := seen[key]
+			if !ok {
+				pool = len(audit.Pools)
+				seen[key] = pool
+				audit.Pools = append(audit.Pools, hop.Pair)
+			}
+			out := hopAmountOut(hop.Pair, hop.TokenIn, amount, hop.AmountOut)
+			auditRoute.Hops = append(auditRoute.Hops, entities.AuditHop{
+				Pool: pool, TokenIn: hop.TokenIn, TokenOut: hop.TokenOut, AmountIn: amount, AmountOut: out,
+			})
+			amount = out
+		}
+		audit.Routes = append(audit.Routes, auditRoute)
+	}
+	return audit
+}
+
+// hopAmountOut derives a hop's output from its pool. Market maker hops
+// aren't priced by a pool, so their quoted output stands.
+func hopAmountOut(pair entities.Pair, tokenIn common.Address, amountIn, quoted *big.Int) *big.Int {
+	if pair.DEX == entities.DEXRFQ {
+		return quoted
+	}
+	return pair.GetAmountOut(amountIn, tokenIn)
+}
+
+// ReplayQuoteAudit re-derives an audited quote from its pool states alone
+// and describes every amount that doesn't match what was quoted. No
+// mismatches means the quote follows from the recorded chain state.
+func ReplayQuoteAudit(audit *entities.QuoteAudit) []string {
+	var mismatches []string
+	mismatch := func(what string, got, want *big.Int) {
+		if got == nil || want == nil || got.Cmp(want) != 0 {
+			mismatches = append(mismatches, fmt.Sprintf("%s: replayed %v, quoted %v", what, got, want))
+		}
+	}
+
+	total := new(big.Int)
+	for i, route := range audit.Routes {
+		amount := route.AmountIn
+		for j, hop := range route.Hops {
+			if hop.Pool < 0 || hop.Pool >= len(audit.Pools) {
+				mismatches = append(mismatches, fmt.Sprintf("route %d hop %d: no pool %d", i, j, hop.Pool))
+				return mismatches
+			}
+			mismatch(fmt.Sprintf("route %d hop %d amountIn", i, j), amount, hop.AmountIn)
+			amount = hopAmountOut(audit.Pools[hop.Pool], hop.TokenIn, amount, hop.AmountOut)
+			mismatch(fmt.Sprintf("route %d hop %d amountOut", i, j), amount, hop.AmountOut)
+		}
+		mismatch(fmt.Sprintf("route %d amountOut", i), amount, route.AmountOut)
+		if amount != nil {
+			total.Add(total, amount)
+		}
+	}
+	mismatch("routeAmountOut", total, audit.RouteAmountOut)
+
+	if audit.RFQOrder != nil {
+		// The order is firm: no slippage and no integrator fee
+		mismatch("minAmountOut", audit.RFQOrder.AmountOut, audit.MinAmountOut)
+		return mismatches
+	}
+	amountOut, minAmountOut := new(big.Int).Set(total), slippageFloor(total, audit.SlippageBps)
+	if bps := audit.IntegratorFeeBps; bps > 0 {
+		fee := new(big.Int).Mul(amountOut, new(big.Int).SetUint64(bps))
+		amountOut.Sub(amountOut, fee.Div(fee, big.NewInt(10000)))
+		minAmountOut.Mul(minAmountOut, big.NewInt(10000-int64(bps)))
+		minAmountOut.Div(minAmountOut, big.NewInt(10000))
+	}
+	mismatch("amountOut", amountOut, audit.AmountOut)
+	mismatch("minAmountOut", minAmountOut, audit.MinAmountOut)
+	return mismatches
+}
+
+// slippageFloor is amountOut * (10000 - slippageBps) / 10000
+func slippageFloor(amountOut *big.Int, slippageBps uint64) *big.Int {
+	floor := new(big.Int).Mul(amountOut, big.NewInt(10000-int64(slippageBps)))
+	return floor.Div(floor, big.NewInt(10000))
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestQuoteAuditReplays(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+	reserve := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: tokenA, Token1: tokenB,
+		Reserve0: reserve(1000), Reserve1: reserve(2000), DEX: entities.DEXUniswapV2, Fee: 30, BlockNumber: 100,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2}, &MockCache{}))
+	quote, err := router.GetSmartQuote(context.Background(), tokenA, tokenB, reserve(5), 50)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	ApplyIntegratorFee(quote, 25, common.HexToAddress("0xfee"))
+
+	// The artifact must survive the trip through a response
+	raw, err := json.Marshal(NewQuoteAudit(quote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audit entities.QuoteAudit
+	if err := json.Unmarshal(raw, &audit); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.Pools) != 1 || audit.Pools[0].BlockNumber != 100 || audit.IntegratorFeeBps != 25 {
+		t.Fatalf("audit = %s", raw)
+	}
+	if mismatches := ReplayQuoteAudit(&audit); len(mismatches) != 0 {
+		t.Fatalf("ReplayQuoteAudit() = %v, want a clean replay", mismatches)
+	}
+
+	// A quote that doesn't follow from the recorded reserves is caught
+	audit.Pools[0].Reserve1 = reserve(1990)
+	mismatches := ReplayQuoteAudit(&audit)
+	if len(mismatches) == 0 || !strings.Contains(mismatches[0], "route 0 hop 0 amountOut") {
+		t.Errorf("ReplayQuoteAudit() = %v, want the hop flagged", mismatches)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Errors returned by QuoteBook
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	ErrQuoteExpired  = errors.New("quote expired")
+)
+
+// expiredQuoteRetention is how long an expired quote is remembered, so
+// validating it reports expiry rather than an unknown ID
+const expiredQuoteRetention = 10 * time.Minute
+
+// QuoteBook keeps served quotes until shortly after they expire so clients
+// can validate them before executing
+type QuoteBook struct {
+	routerService *RouterService
+	now           func() time.Time
+
+	mu         sync.Mutex
+	quotes     map[string]*entities.Quote
+	lastPruned time.Time
+}
+
+func NewQuoteBook(routerService *RouterService) *QuoteBook {
+	return &QuoteBook{
+		routerService: routerService,
+		now:           time.Now,
+		quotes:        make(map[string]*entities.Quote),
+		lastPruned:    time.Now(),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (b *QuoteBook) SetClock(now func() time.Time) {
+	b.now = now
+}
+
+// Put assigns the quote an ID and keeps it
+func (b *QuoteBook) Put(quote *entities.Quote) error {
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	quote.ID = id
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.now().Sub(b.lastPruned) > time.Minute {
+		b.prune()
+	}
+	b.quotes[quote.ID] = quote
+	return nil
+}
+
+// Validate rejects an expired quote, and otherwise re-runs its route search
+// to check the output still meets the quote's minimum
+func (b *QuoteBook) Validate(ctx context.Context, id string) (*entities.QuoteValidation, error) {
+	b.mu.Lock()
+	quote, ok := b.quotes[id]
+	b.mu.Unlock()
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	validation := &entities.QuoteValidation{
+		QuoteID:      id,
+		ExpiresAt:    quote.ExpiresAt,
+		MinAmountOut: quote.MinAmountOut,
+	}
+	if !b.now().Before(quote.ExpiresAt) {
+		return validation, ErrQuoteExpired
+	}
+
+	// A market maker's signed order fills at its amount until it expires
+	if quote.RFQOrder != nil {
+		validation.Valid = true
+		validation.CurrentAmountOut = quote.RFQOrder.AmountOut
+		return validation, nil
+	}
+
+	if quote.OptimizeFor != "" {
+		ctx = WithOptimizeFor(ctx, OptimizeFor(quote.OptimizeFor))
+	}
+	if len(quote.Venues) > 0 {
+		ctx = WithVenues(ctx, quote.Venues)
+	}
+	if quote.VerifiedPools {
+		ctx = WithVerifiedPools(ctx)
+	}
+	current, err := b.routerService.GetStrategyQuote(ctx, quote.Strategy, quote.TokenIn, quote.TokenOut, quote.AmountIn, quote.SlippageBps)
+	if err != nil {
+		validation.Reason = "no route: " + err.Error()
+		return validation, nil
+	}
+	if fee := quote.IntegratorFee; fee != nil {
+		ApplyIntegratorFee(current, fee.Bps, fee.Recipient)
+	}
+	validation.CurrentAmountOut = current.AmountOut
+	if quote.MinAmountOut != nil && current.AmountOut.Cmp(quote.MinAmountOut) < 0 {
+		validation.Reason = "price moved below minAmountOut"
+		return validation, nil
+	}
+	validation.Valid = true
+	return validation, nil
+}
+
+// prune drops quotes past their retention. Callers hold b.mu.
+func (b *QuoteBook) prune() {
+	cutoff := b.now().Add(-expiredQuoteRetention)
+	for id, quote := range b.quotes {
+		if quote.ExpiresAt.Before(cutoff) {
+			delete(b.quotes, id)
+		}
+	}
+	b.lastPruned = b.now()
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestQuoteBookValidate(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   token0,
+		Token1:   token1,
+		Reserve0: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+	routerService.SetQuoteDeadline(time.Minute)
+	book := NewQuoteBook(routerService)
+
+	newQuote := func() *entities.Quote {
+		quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, big.NewInt(1e18), 50)
+		if err != nil {
+			t.Fatalf("GetSmartQuote() error = %v", err)
+		}
+		if err := book.Put(quote); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		return quote
+	}
+
+	quote := newQuote()
+	if until := time.Until(quote.ExpiresAt); until <= 58*time.Second || until > time.Minute {
+		t.Errorf("ExpiresAt in %s, want about a minute", until)
+	}
+	validation, err := book.Validate(context.Background(), quote.ID)
+	if err != nil || !validation.Valid {
+		t.Fatalf("Validate(fresh) = %+v, %v, want valid", validation, err)
+	}
+
+	// The pool moved against the quote beyond its slippage
+	moved := newQuote()
+	moved.MinAmountOut = new(big.Int).Add(validation.CurrentAmountOut, big.NewInt(1))
+	validation, err = book.Validate(context.Background(), moved.ID)
+	if err != nil || validation.Valid || validation.Reason == "" {
+		t.Errorf("Validate(moved) = %+v, %v, want invalid with a reason", validation, err)
+	}
+
+	expired := newQuote()
+	ApplyDeadline(expired, -time.Second)
+	if _, err := book.Validate(context.Background(), expired.ID); !errors.Is(err, ErrQuoteExpired) {
+		t.Errorf("Validate(expired) error = %v, want ErrQuoteExpired", err)
+	}
+
+	if _, err := book.Validate(context.Background(), "unknown"); !errors.Is(err, ErrQuoteNotFound) {
+		t.Errorf("Validate(unknown) error = %v, want ErrQuoteNotFound", err)
+	}
+}
+
+func TestQuoteBookExpiresByClock(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	fake := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	fake.SetPair(&entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   token0,
+		Token1:   token1,
+		Reserve0: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	clock := testutil.NewFakeClock(time.Now())
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{fake}, testutil.NewFakeCache(clock.Now)))
+	routerService.SetQuoteDeadline(time.Minute)
+	book := NewQuoteBook(routerService)
+	book.SetClock(clock.Now)
+
+	put := func() *entities.Quote {
+		quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, big.NewInt(1e18), 50)
+		if err != nil {
+			t.Fatalf("GetSmartQuote() error = %v", err)
+		}
+		if err := book.Put(quote); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		return quote
+	}
+	quote := put()
+
+	clock.Advance(59 * time.Second)
+	if validation, err := book.Validate(context.Background(), quote.ID); err != nil || !validation.Valid {
+		t.Errorf("Validate(before expiry) = %+v, %v, want valid", validation, err)
+	}
+	clock.Advance(time.Second)
+	if _, err := book.Validate(context.Background(), quote.ID); !errors.Is(err, ErrQuoteExpired) {
+		t.Errorf("Validate(at expiry) error = %v, want ErrQuoteExpired", err)
+	}
+
+	// Past retention the next Put prunes it
+	clock.Advance(expiredQuoteRetention + time.Second)
+	put()
+	if _, err := book.Validate(context.Background(), quote.ID); !errors.Is(err, ErrQuoteNotFound) {
+		t.Errorf("Validate(pruned) error = %v, want ErrQuoteNotFound", err)
+	}
+}
+
+func TestApplyDeadlineRFQExpiry(t *testing.T) {
+	soon := time.Now().Add(10 * time.Second).Truncate(time.Second)
+	quote := &entities.Quote{RFQOrder: &entities.RFQOrder{Expiry: uint64(soon.Unix())}}
+	ApplyDeadline(quote, time.Minute)
+	if !quote.ExpiresAt.Equal(soon) {
+		t.Errorf("ExpiresAt = %s, want the maker order's expiry %s", quote.ExpiresAt, soon)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// QuoteSink stores batches of quote records
+type QuoteSink interface {
+	InsertQuotes(ctx context.Context, records []entities.QuoteRecord) error
+}
+
+// QuoteRecorder queues served quotes and writes them to a QuoteSink in the
+// background, so recording never adds latency to a quote. When the queue
+// is full records are dropped rather than blocking the request.
+type QuoteRecorder struct {
+	sink      QuoteSink
+	batchSize int
+	queue     chan entities.QuoteRecord
+
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewQuoteRecorder buffers up to queueSize records and writes them in
+// batches of at most batchSize
+func NewQuoteRecorder(sink QuoteSink, queueSize, batchSize int) *QuoteRecorder {
+	return &QuoteRecorder{
+		sink:      sink,
+		batchSize: batchSize,
+		queue:     make(chan entities.QuoteRecord, queueSize),
+	}
+}
+
+// Record queues a served quote with the time it took to build
+func (r *QuoteRecorder) Record(quote *entities.Quote, latency time.Duration) {
+	select {
+	case r.queue <- newQuoteRecord(quote, latency):
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many records were discarded because the queue was full
+func (r *QuoteRecorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Failed returns how many records were lost to sink errors
+func (r *QuoteRecorder) Failed() uint64 {
+	return r.failed.Load()
+}
+
+// Run writes a batch whenever batchSize records are queued or interval
+// passes, and flushes what is left when ctx is cancelled
+func (r *QuoteRecorder) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	batch := make([]entities.QuoteRecord, 0, r.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			batch = r.drain(batch)
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			r.flush(flushCtx, batch)
+			cancel()
+			return
+		case record := <-r.queue:
+			batch = append(batch, record)
+			if len(batch) >= r.batchSize {
+				r.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// drain moves every queued record into batch
+func (r *QuoteRecorder) drain(batch []entities.QuoteRecord) []entities.QuoteRecord {
+	for {
+		select {
+		case record := <-r.queue:
+			batch = append(batch, record)
+		default:
+			return batch
+		}
+	}
+}
+
+func (r *QuoteRecorder) flush(ctx context.Context, batch []entities.QuoteRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := r.sink.InsertQuotes(ctx, batch); err != nil {
+		r.failed.Add(uint64(len(batch)))
+		log.Printf("quote recorder: failed to write %d records: %v", len(batch), err)
+	}
+}
+
+func newQuoteRecord(quote *entities.Quote, latency time.Duration) entities.QuoteRecord {
+	record := entities.QuoteRecord{
+		Time:      time.Now().UTC(),
+		TokenIn:   quote.TokenIn.Address,
+		TokenOut:  quote.TokenOut.Address,
+		AmountIn:  quote.AmountIn,
+		AmountOut: quote.AmountOut,
+		Strategy:  quote.Strategy,
+		RFQ:       quote.RFQOrder != nil,
+		Sources:   quote.SourceDetails,
+		LatencyMs: latency.Milliseconds(),
+	}
+
+	if record.RFQ {
+		return record
+	}
+	seen := make(map[entities.DEXType]bool)
+	addVenues := func(route *entities.Route) {
+		for _, hop := range route.Hops {
+			if !seen[hop.Pair.DEX] {
+				seen[hop.Pair.DEX] = true
+				record.Venues = append(record.Venues, hop.Pair.DEX)
+			}
+		}
+	}
+	if len(quote.SplitRoutes) > 0 {
+		for _, split := range quote.SplitRoutes {
+			addVenues(split.Route)
+		}
+	} else if quote.BestRoute != nil {
+		addVenues(quote.BestRoute)
+	}
+	return record
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+type mockQuoteSink struct {
+	mu      sync.Mutex
+	batches [][]entities.QuoteRecord
+}
+
+func (m *mockQuoteSink) InsertQuotes(ctx context.Context, records []entities.QuoteRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches = append(m.batches, append([]entities.QuoteRecord(nil), records...))
+	return nil
+}
+
+func (m *mockQuoteSink) records() []entities.QuoteRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all []entities.QuoteRecord
+	for _, batch := range m.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func recorderQuote(venues ...entities.DEXType) *entities.Quote {
+	quote := &entities.Quote{
+		TokenIn:   entities.WETH,
+		TokenOut:  entities.USDC,
+		AmountIn:  big.NewInt(1000),
+		AmountOut: big.NewInt(990),
+		Strategy:  "greedy",
+		SourceDetails: []entities.SourceDetail{
+			{DEX: entities.DEXUniswapV2, AmountOut: big.NewInt(990)},
+			{DEX: entities.DEXCurve, Error: "no pool"},
+		},
+	}
+	for _, venue := range venues {
+		route := &entities.Route{Hops: []entities.Hop{{Pair: entities.Pair{DEX: venue}}}}
+		quote.SplitRoutes = append(quote.SplitRoutes, entities.SplitRoute{Route: route})
+		quote.BestRoute = route
+	}
+	if len(venues) == 1 {
+		quote.SplitRoutes = nil
+	}
+	return quote
+}
+
+func TestQuoteRecorder(t *testing.T) {
+	sink := &mockQuoteSink{}
+	recorder := NewQuoteRecorder(sink, 2, 10)
+
+	recorder.Record(recorderQuote(entities.DEXUniswapV2), 15*time.Millisecond)
+	recorder.Record(recorderQuote(entities.DEXUniswapV2, entities.DEXSushiswap, entities.DEXUniswapV2), time.Millisecond)
+	recorder.Record(recorderQuote(entities.DEXUniswapV2), time.Millisecond)
+	if got := recorder.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1 with a full queue", got)
+	}
+
+	// Cancelling flushes the queued records
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder.Run(ctx, time.Hour)
+
+	records := sink.records()
+	if len(records) != 2 {
+		t.Fatalf("recorded %d quotes, want 2", len(records))
+	}
+	if records[0].LatencyMs != 15 || records[0].Strategy != "greedy" || len(records[0].Sources) != 2 {
+		t.Errorf("records[0] = %+v", records[0])
+	}
+	if got := records[0].Venues; len(got) != 1 || got[0] != entities.DEXUniswapV2 {
+		t.Errorf("single-route venues = %v", got)
+	}
+	if got := records[1].Venues; len(got) != 2 || got[0] != entities.DEXUniswapV2 || got[1] != entities.DEXSushiswap {
+		t.Errorf("split venues = %v, want each venue once", got)
+	}
+}
+
+func TestQuoteRecorderBatches(t *testing.T) {
+	sink := &mockQuoteSink{}
+	recorder := NewQuoteRecorder(sink, 10, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		recorder.Record(recorderQuote(entities.DEXUniswapV2), time.Millisecond)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(sink.records()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.batches) != 2 || len(sink.batches[0]) != 2 || len(sink.batches[1]) != 1 {
+		t.Errorf("batch sizes wrong: %d batches", len(sink.batches))
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Stages of a quote request that QuoteTiming tells apart. Each venue's
+// adapter is a stage of its own, see DEXStage.
+const (
+	StageCache         = "cache"         // Pair cache reads
+	StageRouting       = "routing"       // Route finding, less the time waiting on pairs
+	StageRFQ           = "rfq"           // Waiting on market makers
+	StagePrepare       = "prepare"       // Screening, transaction, gas and USD values
+	StageSerialization = "serialization" // Building and encoding the response
+)
+
+// DEXStage is the stage of reading pairs from dex's adapter
+func DEXStage(dex entities.DEXType) string {
+	return "dex." + string(dex)
+}
+
+// QuoteTiming adds up the time one quote request spends in each stage.
+// Venues are read concurrently, so stages overlap and their sum can exceed
+// the request's wall time. A nil QuoteTiming ignores everything.
+type QuoteTiming struct {
+	mu     sync.Mutex
+	stages map[string]time.Duration
+	// waited is the wall time spent waiting on pair reads, which routing
+	// subtracts from its own
+	waited time.Duration
+}
+
+type quoteTimingKey struct{}
+
+// WithQuoteTiming returns ctx carrying a new QuoteTiming that the services
+// a quote passes through report into
+func WithQuoteTiming(ctx context.Context) (context.Context, *QuoteTiming) {
+	timing := &QuoteTiming{stages: make(map[string]time.Duration)}
+	return context.WithValue(ctx, quoteTimingKey{}, timing), timing
+}
+
+// QuoteTimingFrom returns ctx's QuoteTiming, nil when the request isn't timed
+func QuoteTimingFrom(ctx context.Context) *QuoteTiming {
+	timing, _ := ctx.Value(quoteTimingKey{}).(*QuoteTiming)
+	return timing
+}
+
+// Add charges d to stage
+func (t *QuoteTiming) Add(stage string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.stages[stage] += d
+	t.mu.Unlock()
+}
+
+// wait records wall time spent waiting on pair reads
+func (t *QuoteTiming) wait(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.waited += d
+	t.mu.Unlock()
+}
+
+func (t *QuoteTiming) waitedFor() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.waited
+}
+
+// Stages returns the time charged to each stage so far
+func (t *QuoteTiming) Stages() map[string]time.Duration {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stages := make(map[string]time.Duration, len(t.stages))
+	for stage, d := range t.stages {
+		stages[stage] = d
+	}
+	return stages
+}
+
+// latencyBucketsMs are the histogram bucket upper bounds; slower samples
+// land in a final unbounded bucket
+var latencyBucketsMs = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// LatencyHistograms aggregates the stage timings of every quote request,
+// so the stage to speed up is the one that is slow across traffic rather
+// than in one request
+type LatencyHistograms struct {
+	mu     sync.Mutex
+	stages map[string]*latencyHistogram
+}
+
+type latencyHistogram struct {
+	counts []uint64 // One per bucket, plus the unbounded one
+	count  uint64
+	sumMs  float64
+}
+
+func NewLatencyHistograms() *LatencyHistograms {
+	return &LatencyHistograms{stages: make(map[string]*latencyHistogram)}
+}
+
+// Observe adds one request's stage timings and its total, as stage "total"
+func (h *LatencyHistograms) Observe(timing *QuoteTiming, total time.Duration) {
+	stages := timing.Stages()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for stage, d := range stages {
+		h.observe(stage, d)
+	}
+	h.observe("total", total)
+}
+
+func (h *LatencyHistograms) observe(stage string, d time.Duration) {
+	hist := h.stages[stage]
+	if hist == nil {
+		hist = &latencyHistogram{counts: make([]uint64, len(latencyBucketsMs)+1)}
+		h.stages[stage] = hist
+	}
+	ms := DurationMs(d)
+	hist.counts[sort.SearchFloat64s(latencyBucketsMs, ms)]++
+	hist.count++
+	hist.sumMs += ms
+}
+
+// LatencySummary is one stage's histogram. Buckets count samples at or
+// below each bound in ms, "+Inf" holding the rest; percentiles are the
+// upper bound of the bucket they fall in.
+type LatencySummary struct {
+	Count   uint64            `json:"count"`
+	MeanMs  float64           `json:"meanMs"`
+	P50Ms   float64           `json:"p50Ms"`
+	P95Ms   float64           `json:"p95Ms"`
+	P99Ms   float64           `json:"p99Ms"`
+	Buckets map[string]uint64 `json:"buckets"`
+}
+
+// Snapshot summarizes every stage seen so far
+func (h *LatencyHistograms) Snapshot() map[string]LatencySummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshot := make(map[string]LatencySummary, len(h.stages))
+	for stage, hist := range h.stages {
+		buckets := make(map[string]uint64, len(hist.counts))
+		for i, n := range hist.counts {
+			buckets[bucketLabel(i)] = n
+		}
+		snapshot[stage] = LatencySummary{
+			Count:   hist.count,
+			MeanMs:  hist.sumMs / float64(hist.count),
+			P50Ms:   hist.percentile(0.50),
+			P95Ms:   hist.percentile(0.95),
+			P99Ms:   hist.percentile(0.99),
+			Buckets: buckets,
+		}
+	}
+	return snapshot
+}
+
+// percentile is the upper bound of the bucket holding quantile q, or the
+// largest bound when it falls past them (JSON has no infinity)
+func (hist *latencyHistogram) percentile(q float64) float64 {
+	rank := uint64(math.Ceil(q * float64(hist.count)))
+	var seen uint64
+	for i, n := range hist.counts {
+		seen += n
+		if seen >= rank && i < len(latencyBucketsMs) {
+			return latencyBucketsMs[i]
+		}
+	}
+	return latencyBucketsMs[len(latencyBucketsMs)-1]
+}
+
+func bucketLabel(i int) string {
+	if i == len(latencyBucketsMs) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(latencyBucketsMs[i], 'f', -1, 64)
+}
+
+// DurationMs is d in milliseconds, to the microsecond
+func DurationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
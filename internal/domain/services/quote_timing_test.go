@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestQuoteTimingStages(t *testing.T) {
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: big.NewInt(2e12), Reserve1: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2}, &MockCache{}))
+
+	ctx, timing := WithQuoteTiming(context.Background())
+	if _, err := router.GetSmartQuote(ctx, entities.WETH, entities.USDC, big.NewInt(1e18), 0); err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	stages := timing.Stages()
+	for _, stage := range []string{StageCache, DEXStage(entities.DEXUniswapV2), StageRouting} {
+		if _, ok := stages[stage]; !ok {
+			t.Errorf("stage %s missing from %v", stage, stages)
+		}
+	}
+	if _, ok := stages[StageRFQ]; ok {
+		t.Errorf("rfq timed without a provider")
+	}
+
+	// Untimed requests are ignored
+	QuoteTimingFrom(context.Background()).Add(StageCache, time.Second)
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	histograms := NewLatencyHistograms()
+	for _, ms := range []int{1, 3, 3, 8, 40, 40, 40, 40, 90, 7000} {
+		ctx, timing := WithQuoteTiming(context.Background())
+		QuoteTimingFrom(ctx).Add(StageRouting, time.Duration(ms)*time.Millisecond)
+		histograms.Observe(timing, time.Duration(ms)*time.Millisecond)
+	}
+
+	snapshot := histograms.Snapshot()
+	routing, total := snapshot[StageRouting], snapshot["total"]
+	if routing.Count != 10 || total.Count != 10 {
+		t.Fatalf("counts = %d, %d, want 10", routing.Count, total.Count)
+	}
+	if routing.MeanMs != 726.5 {
+		t.Errorf("MeanMs = %v, want 726.5", routing.MeanMs)
+	}
+	if routing.P50Ms != 50 || routing.P95Ms != 5000 || routing.P99Ms != 5000 {
+		t.Errorf("percentiles = %v, %v, %v", routing.P50Ms, routing.P95Ms, routing.P99Ms)
+	}
+	want := map[string]uint64{"1": 1, "5": 2, "10": 1, "50": 4, "100": 1, "+Inf": 1}
+	for label, n := range routing.Buckets {
+		if n != want[label] {
+			t.Errorf("bucket %s = %d, want %d", label, n, want[label])
+		}
+	}
+}
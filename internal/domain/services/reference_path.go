@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// DefaultReferencePaths price tokens without a pool against the reference
+// through WETH, then the other index stablecoins, then WBTC and WETH for
+// tokens that only trade against BTC
+var DefaultReferencePaths = [][]entities.Token{
+	{entities.WETH},
+	{entities.USDT},
+	{entities.DAI},
+	{entities.WBTC, entities.WETH},
+}
+
+// SetReferencePaths sets the intermediate tokens, in priority order, that
+// tokens without a pool against the reference are priced through. Nil
+// prices direct pools only.
+func (s *PriceService) SetReferencePaths(paths [][]entities.Token) {
+	s.referencePaths = paths
+}
+
+// priceThrough prices one whole token in reference across hops, as the
+// product of each hop's price for one whole token
+func (s *PriceService) priceThrough(ctx context.Context, token, reference entities.Token, hops []entities.Token) (*big.Int, error) {
+	one := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	price := new(big.Int).Set(one)
+	from := token
+	for _, to := range append(hops[:len(hops):len(hops)], reference) {
+		oneFrom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(from.Decimals)), nil)
+		best, err := s.GetBestPrice(ctx, from, to, oneFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price %s in %s: %w", from.Symbol, to.Symbol, err)
+		}
+		if best.AmountOut == nil || best.AmountOut.Sign() <= 0 {
+			return nil, fmt.Errorf("failed to price %s in %s: no output", from.Symbol, to.Symbol)
+		}
+		price.Mul(price, scaleTo18(best.AmountOut, to.Decimals))
+		price.Div(price, one)
+		from = to
+	}
+	return price, nil
+}
+
+// pathTouches reports whether path passes through token or reference,
+// which a shorter path already covers
+func pathTouches(path []entities.Token, token, reference entities.Token) bool {
+	for _, hop := range path {
+		if hop.Address == token.Address || hop.Address == reference.Address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Errors returned by RouteBookmarkService
+var (
+	ErrBookmarkNotFound = errors.New("route bookmark not found")
+	ErrInvalidBookmark  = errors.New("invalid route bookmark")
+)
+
+// MaxBookmarksPerKey caps the bookmarks one API key may hold
+const MaxBookmarksPerKey = 100
+
+// bookmarkName is what a bookmark name may be, so it reads as one URL path
+// segment without escaping
+var bookmarkName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// RouteBookmarkStore persists each API key's route bookmarks. GetBookmark
+// returns nil, nil for an unknown name.
+type RouteBookmarkStore interface {
+	SaveBookmark(ctx context.Context, bookmark entities.RouteBookmark) error
+	GetBookmark(ctx context.Context, keyID, name string) (*entities.RouteBookmark, error)
+	// ListBookmarks returns a key's bookmarks ordered by name
+	ListBookmarks(ctx context.Context, keyID string) ([]entities.RouteBookmark, error)
+	DeleteBookmark(ctx context.Context, keyID, name string) error
+}
+
+// RouteBookmarkService keeps the named quote configurations integrators
+// register under their API keys
+type RouteBookmarkService struct {
+	store RouteBookmarkStore
+	now   func() time.Time
+}
+
+func NewRouteBookmarkService(store RouteBookmarkStore) *RouteBookmarkService {
+	return &RouteBookmarkService{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *RouteBookmarkService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Save creates the bookmark or replaces the key's bookmark of the same
+// name, keeping its creation time. The caller validates the quote
+// parameters it holds.
+func (s *RouteBookmarkService) Save(ctx context.Context, bookmark entities.RouteBookmark) (*entities.RouteBookmark, error) {
+	if !bookmarkName.MatchString(bookmark.Name) {
+		return nil, fmt.Errorf("%w: name must be 1-64 lowercase letters, digits, - or _", ErrInvalidBookmark)
+	}
+	existing, err := s.store.GetBookmark(ctx, bookmark.KeyID, bookmark.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	bookmark.CreatedAt, bookmark.UpdatedAt = now, now
+	if existing != nil {
+		bookmark.CreatedAt = existing.CreatedAt
+	} else {
+		held, err := s.store.ListBookmarks(ctx, bookmark.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if len(held) >= MaxBookmarksPerKey {
+			return nil, fmt.Errorf("%w: a key may hold at most %d bookmarks", ErrInvalidBookmark, MaxBookmarksPerKey)
+		}
+	}
+	if err := s.store.SaveBookmark(ctx, bookmark); err != nil {
+		return nil, fmt.Errorf("failed to save route bookmark: %w", err)
+	}
+	return &bookmark, nil
+}
+
+func (s *RouteBookmarkService) Get(ctx context.Context, keyID, name string) (*entities.RouteBookmark, error) {
+	bookmark, err := s.store.GetBookmark(ctx, keyID, name)
+	if err != nil {
+		return nil, err
+	}
+	if bookmark == nil {
+		return nil, ErrBookmarkNotFound
+	}
+	return bookmark, nil
+}
+
+func (s *RouteBookmarkService) List(ctx context.Context, keyID string) ([]entities.RouteBookmark, error) {
+	return s.store.ListBookmarks(ctx, keyID)
+}
+
+func (s *RouteBookmarkService) Delete(ctx context.Context, keyID, name string) error {
+	if _, err := s.Get(ctx, keyID, name); err != nil {
+		return err
+	}
+	return s.store.DeleteBookmark(ctx, keyID, name)
+}
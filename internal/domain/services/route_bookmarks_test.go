@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/keystore"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestRouteBookmarkService(t *testing.T) {
+	ctx := context.Background()
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	svc := NewRouteBookmarkService(keystore.NewMemoryStore())
+	svc.SetClock(clock.Now)
+
+	bookmark := entities.RouteBookmark{Name: "buy-usdc", KeyID: "k1", TokenIn: entities.WETH.Address, TokenOut: entities.USDC.Address}
+	if _, err := svc.Save(ctx, bookmark); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	clock.Advance(time.Hour)
+	bookmark.Slippage = "30"
+	saved, err := svc.Save(ctx, bookmark)
+	if err != nil {
+		t.Fatalf("Save() replacing error = %v", err)
+	}
+	if !saved.CreatedAt.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) || !saved.UpdatedAt.Equal(clock.Now()) {
+		t.Errorf("replaced bookmark created %v, updated %v", saved.CreatedAt, saved.UpdatedAt)
+	}
+
+	// Names are per key
+	if _, err := svc.Get(ctx, "k2", "buy-usdc"); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("Get() another key's bookmark error = %v, want ErrBookmarkNotFound", err)
+	}
+	for _, name := range []string{"", "Buy", "buy usdc", "-buy"} {
+		if _, err := svc.Save(ctx, entities.RouteBookmark{Name: name, KeyID: "k1"}); !errors.Is(err, ErrInvalidBookmark) {
+			t.Errorf("Save(%q) error = %v, want ErrInvalidBookmark", name, err)
+		}
+	}
+
+	for i := 1; i < MaxBookmarksPerKey; i++ {
+		if _, err := svc.Save(ctx, entities.RouteBookmark{Name: fmt.Sprintf("flow-%d", i), KeyID: "k1"}); err != nil {
+			t.Fatalf("Save() bookmark %d error = %v", i+1, err)
+		}
+	}
+	if _, err := svc.Save(ctx, entities.RouteBookmark{Name: "one-too-many", KeyID: "k1"}); !errors.Is(err, ErrInvalidBookmark) {
+		t.Errorf("Save() past the cap error = %v, want ErrInvalidBookmark", err)
+	}
+	if _, err := svc.Save(ctx, bookmark); err != nil {
+		t.Errorf("Save() replacing at the cap error = %v", err)
+	}
+
+	if err := svc.Delete(ctx, "k1", "buy-usdc"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := svc.Delete(ctx, "k1", "buy-usdc"); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrBookmarkNotFound", err)
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// MaxRouteLegs bounds the hops of a submitted route
+const MaxRouteLegs = 4
+
+// RouteLeg is one hop of a route a client submits: the pool to trade
+// through and the tokens in and out of it. An empty DEX matches any venue.
+type RouteLeg struct {
+	DEX      entities.DEXType
+	Pool     common.Address
+	TokenIn  entities.Token
+	TokenOut entities.Token
+}
+
+// RouteEvaluation is a submitted route priced as a quote next to the
+// router's best quote for the same trade. DeltaBps is positive when the
+// submitted route pays more. BestErr says why there is no best quote.
+type RouteEvaluation struct {
+	Quote    *entities.Quote
+	Best     *entities.Quote
+	BestErr  error
+	DeltaBps int64
+}
+
+// EvaluateRoute prices amountIn through exactly the submitted pools, in
+// order, and compares the result to the router's best quote
+func (s *RouterService) EvaluateRoute(ctx context.Context, legs []RouteLeg, amountIn *big.Int, slippageBps uint64) (*RouteEvaluation, error) {
+	if len(legs) == 0 || len(legs) > MaxRouteLegs {
+		return nil, apperror.New(apperror.InvalidRoute, fmt.Sprintf("a route has 1-%d hops", MaxRouteLegs))
+	}
+	for i, leg := range legs {
+		if leg.TokenIn.Address == leg.TokenOut.Address {
+			return nil, apperror.New(apperror.InvalidRoute, fmt.Sprintf("hop %d swaps %s for itself", i, leg.TokenIn.Symbol))
+		}
+		if i > 0 && legs[i-1].TokenOut.Address != leg.TokenIn.Address {
+			return nil, apperror.New(apperror.InvalidRoute, fmt.Sprintf("hop %d does not start with hop %d's output", i, i-1))
+		}
+	}
+	tokenIn, tokenOut := legs[0].TokenIn, legs[len(legs)-1].TokenOut
+	// The best quote compared against prices the same pools the route did
+	ctx = ensurePairSnapshot(ctx)
+
+	route := &entities.Route{TokenIn: tokenIn, TokenOut: tokenOut, AmountIn: amountIn}
+	amount := amountIn
+	for i, leg := range legs {
+		prices, err := s.priceService.GetPrices(ctx, leg.TokenIn, leg.TokenOut, amount)
+		if err != nil {
+			return nil, fmt.Errorf("hop %d: %w", i, err)
+		}
+		var result *PriceResult
+		for j := range prices {
+			p := &prices[j]
+			if p.Pair != nil && p.Pair.Address == leg.Pool && (leg.DEX == "" || p.DEX == leg.DEX) {
+				result = p
+				break
+			}
+		}
+		switch {
+		case result == nil:
+			return nil, apperror.New(apperror.InvalidRoute, fmt.Sprintf("hop %d: no pool %s trades %s for %s", i, leg.Pool.Hex(), leg.TokenIn.Symbol, leg.TokenOut.Symbol))
+		case result.Error != nil:
+			return nil, fmt.Errorf("hop %d: %w", i, result.Error)
+		case !quoted(*result):
+			return nil, apperror.New(apperror.InsufficientLiquidity, fmt.Sprintf("hop %d: pool %s can't fill the trade", i, leg.Pool.Hex()))
+		}
+		route.Hops = append(route.Hops, entities.Hop{Pair: *result.Pair, TokenIn: leg.TokenIn.Address, TokenOut: leg.TokenOut.Address})
+		amount = result.AmountOut
+	}
+	route.AmountOut = amount
+	route.FillHopAmounts()
+	route.GasEstimate = estimateGas(route)
+
+	slippageDefault := DefaultSlippage(tokenIn, tokenOut)
+	if slippageBps == 0 {
+		slippageBps = slippageDefault.Bps
+	}
+	quote := &entities.Quote{
+		TokenIn:         tokenIn,
+		TokenOut:        tokenOut,
+		AmountIn:        amountIn,
+		AmountOut:       amount,
+		BestRoute:       route,
+		PriceImpact:     route.CalculatePriceImpact(),
+		GasEstimate:     route.GasEstimate,
+		Sources:         make(map[entities.DEXType]string),
+		SlippageDefault: &slippageDefault,
+	}
+	quote.QuotedAtBlock = quotedAtBlock(quote)
+	quote.VerifiedPools = verifiedPoolsOnly(ctx)
+	s.priceService.verifyHops(ctx, quote)
+	ApplyDeadline(quote, s.deadline)
+	applySlippageProtection(quote, slippageBps)
+	if quote.PriceImpact != nil && quote.PriceImpact.Cmp(big.NewInt(PriceImpactWarningThreshold)) > 0 {
+		quote.PriceWarning = fmt.Sprintf("High price impact: %.2f%%", float64(quote.PriceImpact.Int64())/100.0)
+	}
+
+	evaluation := &RouteEvaluation{Quote: quote}
+	evaluation.Best, evaluation.BestErr = s.GetSmartQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps)
+	if evaluation.BestErr == nil {
+		delta := new(big.Int).Sub(quote.AmountOut, evaluation.Best.AmountOut)
+		evaluation.DeltaBps = bpsOf(delta, evaluation.Best.AmountOut).Int64()
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestRouterServiceEvaluateRoute(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+	tokenC := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000003"), Symbol: "C", Decimals: 18}
+	reserve := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	deep, shallow := common.HexToAddress("0x1111"), common.HexToAddress("0x2222")
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address: deep, Token0: tokenA, Token1: tokenB,
+		Reserve0: reserve(100000), Reserve1: reserve(100000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address: shallow, Token0: tokenA, Token1: tokenB,
+		Reserve0: reserve(1000), Reserve1: reserve(1000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+	sushi.SetPair(tokenB.Address, tokenC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x3333"), Token0: tokenB, Token1: tokenC,
+		Reserve0: reserve(1000), Reserve1: reserve(1000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2, sushi}, &MockCache{}))
+	amountIn := reserve(10)
+
+	// Forcing the shallow pool costs about 1% against the deep one
+	evaluation, err := router.EvaluateRoute(context.Background(), []RouteLeg{
+		{DEX: entities.DEXSushiswap, Pool: shallow, TokenIn: tokenA, TokenOut: tokenB},
+	}, amountIn, 50)
+	if err != nil {
+		t.Fatalf("EvaluateRoute() error = %v", err)
+	}
+	if hops := evaluation.Quote.BestRoute.Hops; len(hops) != 1 || hops[0].Pair.Address != shallow {
+		t.Fatalf("evaluated route = %+v, want the shallow pool", hops)
+	}
+	if evaluation.Best == nil || evaluation.Best.BestRoute.Hops[0].Pair.Address != deep {
+		t.Fatalf("best = %+v, err %v, want the deep pool", evaluation.Best, evaluation.BestErr)
+	}
+	if evaluation.DeltaBps > -90 || evaluation.DeltaBps < -110 {
+		t.Errorf("DeltaBps = %d, want about -100", evaluation.DeltaBps)
+	}
+	if evaluation.Quote.MinAmountOut == nil || evaluation.Quote.SlippageBps != 50 {
+		t.Errorf("slippage protection not applied: %+v", evaluation.Quote)
+	}
+
+	tests := []struct {
+		name string
+		legs []RouteLeg
+		code apperror.Code
+	}{
+		{"pool without the pair", []RouteLeg{{Pool: common.HexToAddress("0x3333"), TokenIn: tokenA, TokenOut: tokenB}}, apperror.InvalidRoute},
+		{"wrong venue", []RouteLeg{{DEX: entities.DEXUniswapV2, Pool: shallow, TokenIn: tokenA, TokenOut: tokenB}}, apperror.InvalidRoute},
+		{"broken chain", []RouteLeg{
+			{Pool: deep, TokenIn: tokenA, TokenOut: tokenB},
+			{Pool: common.HexToAddress("0x3333"), TokenIn: tokenA, TokenOut: tokenC},
+		}, apperror.InvalidRoute},
+		{"no hops", nil, apperror.InvalidRoute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := router.EvaluateRoute(context.Background(), tt.legs, amountIn, 0)
+			if code := apperror.CodeOf(err); code != tt.code {
+				t.Errorf("EvaluateRoute() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+
+	// Two hops through B
+	evaluation, err = router.EvaluateRoute(context.Background(), []RouteLeg{
+		{Pool: deep, TokenIn: tokenA, TokenOut: tokenB},
+		{Pool: common.HexToAddress("0x3333"), TokenIn: tokenB, TokenOut: tokenC},
+	}, amountIn, 0)
+	if err != nil {
+		t.Fatalf("two-hop EvaluateRoute() error = %v", err)
+	}
+	if hops := evaluation.Quote.BestRoute.Hops; len(hops) != 2 || hops[1].AmountIn.Cmp(hops[0].AmountOut) != 0 {
+		t.Errorf("two-hop route = %+v", hops)
+	}
+}
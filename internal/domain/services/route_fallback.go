@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// fallbackProbeDivisor sizes the probe that tells a pair without pools
+// from pools that can't take the amount: a thousandth of it
+const fallbackProbeDivisor = 1000
+
+// RelaxMultiHop is the relaxation that routes through intermediate tokens
+// where the strategy quoted the pair's own pools
+const RelaxMultiHop = "multi_hop"
+
+// NoRouteError is a quote that found no route even with its constraints
+// relaxed, with the diagnostic of why
+type NoRouteError struct {
+	Err        error // The original quote's error, which keeps its code
+	Diagnostic *entities.RouteDiagnostic
+}
+
+func (e *NoRouteError) Error() string { return e.Err.Error() }
+
+func (e *NoRouteError) Unwrap() error { return e.Err }
+
+// FallbackQuote retries a quote that found no route with relaxed
+// constraints: routes of up to two hops through intermediate tokens, from
+// any pool that quotes. The quote carries a diagnostic of why the pair had
+// no route of its own, from each venue's answer at amountIn and at a
+// thousandth of it. When the retry finds nothing too, cause is returned in
+// a NoRouteError with the diagnostic.
+func (s *RouterService) FallbackQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64, cause error) (*entities.Quote, error) {
+	ctx = ensurePairSnapshot(ctx)
+	diagnostic, err := s.diagnoseNoRoute(ctx, tokenIn, tokenOut, amountIn)
+	if err != nil {
+		return nil, cause
+	}
+	diagnostic.Relaxed = []string{RelaxMultiHop}
+
+	quote, err := s.GetMultiHopQuote(ctx, tokenIn, tokenOut, amountIn, nil)
+	if err != nil {
+		return nil, &NoRouteError{Err: cause, Diagnostic: diagnostic}
+	}
+	slippageDefault := DefaultSlippage(tokenIn, tokenOut)
+	if slippageBps == 0 {
+		slippageBps = slippageDefault.Bps
+	}
+	quote.SlippageDefault = &slippageDefault
+	applySlippageProtection(quote, slippageBps)
+	quote.Fallback = diagnostic
+	return quote, nil
+}
+
+// diagnoseNoRoute asks every venue for the pair at amountIn and at the
+// probe, and explains each answer
+func (s *RouterService) diagnoseNoRoute(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.RouteDiagnostic, error) {
+	prices, err := s.priceService.GetPrices(ctx, tokenIn, tokenOut, amountIn)
+	if err != nil {
+		return nil, err
+	}
+
+	diagnostic := &entities.RouteDiagnostic{Venues: make([]entities.VenueDiagnostic, 0, len(prices))}
+	probes := make(map[entities.DEXType]PriceResult)
+	if probe := new(big.Int).Div(amountIn, big.NewInt(fallbackProbeDivisor)); probe.Sign() > 0 {
+		diagnostic.ProbeAmount = probe
+		// A failed probe only loses the distinction between empty and
+		// overdrawn pools
+		probePrices, _ := s.priceService.GetPrices(ctx, tokenIn, tokenOut, probe)
+		for _, p := range probePrices {
+			probes[p.DEX] = p
+		}
+	}
+
+	for _, p := range prices {
+		venue := entities.VenueDiagnostic{DEX: p.DEX}
+		if probe, ok := probes[p.DEX]; ok && isValidPrice(probe) {
+			venue.ProbeOut = probe.AmountOut
+		}
+
+		switch {
+		case errors.Is(p.Error, dex.ErrPoolNotFound):
+			venue.Reason = entities.VenueNoPool
+		case errors.Is(p.Error, ErrBelowMinLiquidity):
+			venue.Reason = entities.VenueBelowMinLiquidity
+		case errors.Is(p.Error, ErrPoolUnverified):
+			venue.Reason = entities.VenueUnverified
+			venue.Detail = p.Error.Error()
+		case errors.Is(p.Error, ErrVenueCircuitOpen):
+			venue.Reason = entities.VenueCircuitOpen
+		case p.Error != nil:
+			venue.Reason = entities.VenueError
+			venue.Detail = p.Error.Error()
+		case !isValidPrice(p) && venue.ProbeOut != nil:
+			venue.Reason = entities.VenueInsufficientLiquidity
+			venue.Detail = "the pool fills the probe but not the amount"
+		case !isValidPrice(p):
+			venue.Reason = entities.VenueNoLiquidity
+		case p.LiquidityScore < MinLiquidityScore:
+			venue.Reason = entities.VenueTooShallow
+			venue.Detail = fmt.Sprintf("the trade is %d bps of the input reserve", 10000-p.LiquidityScore)
+		case lowTVL(p):
+			venue.Reason = entities.VenueLowTVL
+		default:
+			venue.Reason = entities.VenueQuoted
+		}
+		diagnostic.Venues = append(diagnostic.Venues, venue)
+	}
+
+	sort.Slice(diagnostic.Venues, func(i, j int) bool {
+		return diagnostic.Venues[i].DEX < diagnostic.Venues[j].DEX
+	})
+	diagnostic.Cause = noRouteCause(diagnostic.Venues)
+	return diagnostic, nil
+}
+
+// noRouteCause sums up venues: pools that hold the pair beat pools under
+// the liquidity floor, then unverified pools, then venues that failed to
+// answer
+func noRouteCause(venues []entities.VenueDiagnostic) string {
+	var pools, dust, unverified, failed int
+	for _, venue := range venues {
+		switch venue.Reason {
+		case entities.VenueNoPool:
+		case entities.VenueBelowMinLiquidity:
+			dust++
+		case entities.VenueUnverified:
+			unverified++
+		case entities.VenueCircuitOpen, entities.VenueError:
+			failed++
+		default:
+			pools++
+		}
+	}
+
+	switch {
+	case pools > 0:
+		return entities.NoRouteInsufficientLiquidity
+	case dust > 0:
+		return entities.NoRouteBelowMinLiquidity
+	case unverified > 0:
+		return entities.NoRouteUnverified
+	case failed > 0:
+		return entities.NoRouteVenuesFailed
+	}
+	return entities.NoRouteNoPools
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// DefaultStrategy is the routing strategy used when none is requested
+const DefaultStrategy = "greedy"
+
+// ErrUnknownStrategy is returned for a strategy with no registered RouteFinder
+var ErrUnknownStrategy = errors.New("unknown routing strategy")
+
+// poolSplitImpactBps is the price impact above which the greedy finder
+// reads every pool of multi-pool venues. Smaller orders gain nothing from
+// splitting across fee tiers.
+const poolSplitImpactBps = 10
+
+// RouteOptions carries per-request routing preferences
+type RouteOptions struct {
+	SlippageBps uint64
+	OptimizeFor OptimizeFor
+	GasPriceOut *big.Int // tokenOut per unit of gas, 18 decimals; netOutput only
+}
+
+// RouteFinder is a pluggable routing strategy. Returning more than one route
+// splits the order; the routes' AmountIn values sum to amountIn. An empty
+// result with a nil error means no AMM route exists for the trade.
+type RouteFinder interface {
+	Name() string
+	FindRoutes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, opts RouteOptions) ([]*entities.Route, error)
+}
+
+// greedyRouteFinder takes the best single pool, or a two-way split across
+// the two best pools when that yields more output. Stable-to-stable trades
+// also weigh paths through the other stablecoins, split with the pools.
+type greedyRouteFinder struct {
+	priceService *PriceService
+}
+
+func (f *greedyRouteFinder) Name() string {
+	return "greedy"
+}
+
+func (f *greedyRouteFinder) FindRoutes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, opts RouteOptions) ([]*entities.Route, error) {
+	prices, err := f.priceService.GetPrices(ctx, tokenIn, tokenOut, amountIn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	validPrices := filterValidPrices(prices)
+	if len(validPrices) == 0 {
+		return nil, nil
+	}
+	validPrices = f.withPoolPrices(ctx, tokenIn, tokenOut, amountIn, validPrices)
+	splits := trySplitOrder(tokenIn, tokenOut, amountIn, validPrices)
+	candidates := singleRoutes(tokenIn, tokenOut, amountIn, validPrices)
+	if splits != nil {
+		candidates = append(candidates, splits)
+	}
+	if entities.ClassifyPair(tokenIn, tokenOut) == entities.PairClassStable {
+		candidates = append(candidates, f.stableCandidates(ctx, tokenIn, tokenOut, amountIn, validPrices)...)
+	}
+	return chooseRoutes(opts, candidates), nil
+}
+
+// withPoolPrices replaces each multi-pool venue's result with one result
+// per pool, such as every Uniswap V3 fee tier, when the best pool moves the
+// price enough for a split between them to pay
+func (f *greedyRouteFinder) withPoolPrices(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, prices []PriceResult) []PriceResult {
+	best := buildRoute(tokenIn, tokenOut, amountIn, &prices[0])
+	if best.CalculatePriceImpact().Cmp(big.NewInt(poolSplitImpactBps)) <= 0 {
+		return prices
+	}
+
+	pools := f.priceService.GetPoolPrices(ctx, tokenIn, tokenOut, amountIn)
+	if len(pools) == 0 {
+		return prices
+	}
+	covered := make(map[entities.DEXType]bool)
+	for _, pool := range pools {
+		covered[pool.DEX] = true
+	}
+	for _, p := range prices {
+		if !covered[p.DEX] {
+			pools = append(pools, p)
+		}
+	}
+	return filterValidPrices(pools)
+}
+
+// directRouteFinder always takes the single best pool and never splits
+type directRouteFinder struct {
+	priceService *PriceService
+}
+
+func (f *directRouteFinder) Name() string {
+	return "direct"
+}
+
+func (f *directRouteFinder) FindRoutes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, opts RouteOptions) ([]*entities.Route, error) {
+	prices, err := f.priceService.GetPrices(ctx, tokenIn, tokenOut, amountIn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prices: %w", err)
+	}
+
+	validPrices := filterValidPrices(prices)
+	if len(validPrices) == 0 {
+		return nil, nil
+	}
+	return chooseRoutes(opts, singleRoutes(tokenIn, tokenOut, amountIn, validPrices)), nil
+}
+
+// singleRoutes fills the whole trade through each pool of prices in turn,
+// in their order
+func singleRoutes(tokenIn, tokenOut entities.Token, amountIn *big.Int, prices []PriceResult) [][]*entities.Route {
+	candidates := make([][]*entities.Route, len(prices))
+	for i := range prices {
+		candidates[i] = []*entities.Route{buildRoute(tokenIn, tokenOut, amountIn, &prices[i])}
+	}
+	return candidates
+}
+
+// chooseRoutes returns the set of routes opts prefers
+func chooseRoutes(opts RouteOptions, candidates [][]*entities.Route) []*entities.Route {
+	costs := make([]routeCandidate, len(candidates))
+	for i, routes := range candidates {
+		costs[i] = routesCandidate(routes)
+	}
+	return candidates[opts.prefer(costs)]
+}
+
+// trySplitOrder attempts to split the order across the two best pools,
+// returning nil when no split beats the single best pool
+func trySplitOrder(tokenIn, tokenOut entities.Token, amountIn *big.Int, prices []PriceResult) []*entities.Route {
+	if len(prices) < 2 {
+		return nil
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].AmountOut.Cmp(prices[j].AmountOut) > 0
+	})
+
+	bestSplitOutput := big.NewInt(0)
+	var bestSplits []*entities.Route
+
+	singleOutput := prices[0].AmountOut
+
+	// Try splits: 50/50, 60/40, 70/30, 80/20
+	splitRatios := [][]uint64{{50, 50}, {60, 40}, {70, 30}, {80, 20}}
+
+	for _, ratio := range splitRatios {
+		amount1 := new(big.Int).Mul(amountIn, big.NewInt(int64(ratio[0])))
+		amount1.Div(amount1, big.NewInt(100))
+		amount2 := new(big.Int).Sub(amountIn, amount1)
+
+		output1 := prices[0].Pair.GetAmountOut(amount1, tokenIn.Address)
+		output2 := prices[1].Pair.GetAmountOut(amount2, tokenIn.Address)
+
+		totalOutput := new(big.Int).Add(output1, output2)
+
+		// Raw output picks the ratio; the caller weighs its gas
+		if totalOutput.Cmp(bestSplitOutput) > 0 {
+			bestSplitOutput = totalOutput
+
+			route1 := buildRoute(tokenIn, tokenOut, amount1, &prices[0])
+			route1.AmountOut = output1
+			route1.FillHopAmounts()
+			route2 := buildRoute(tokenIn, tokenOut, amount2, &prices[1])
+			route2.AmountOut = output2
+			route2.FillHopAmounts()
+			bestSplits = []*entities.Route{route1, route2}
+		}
+	}
+
+	if bestSplitOutput.Cmp(singleOutput) <= 0 {
+		return nil
+	}
+	return bestSplits
+}
+
+// quoteFromRoutes assembles a quote from a finder's routes. A split quote
+// keeps the first route's pools at the full amount as its BestRoute.
+func quoteFromRoutes(tokenIn, tokenOut entities.Token, amountIn *big.Int, routes []*entities.Route, sources map[entities.DEXType]string) *entities.Quote {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	if len(routes) == 1 {
+		route := routes[0]
+		return &entities.Quote{
+			TokenIn:     tokenIn,
+			TokenOut:    tokenOut,
+			AmountIn:    amountIn,
+			AmountOut:   route.AmountOut,
+			BestRoute:   route,
+			PriceImpact: route.CalculatePriceImpact(),
+			GasEstimate: routesGas(routes),
+			Sources:     sources,
+		}
+	}
+
+	splits := make([]entities.SplitRoute, 0, len(routes))
+	amountOut := big.NewInt(0)
+	for _, route := range routes {
+		percentage := new(big.Int).Mul(route.AmountIn, big.NewInt(100))
+		percentage.Div(percentage, amountIn)
+		splits = append(splits, entities.SplitRoute{
+			Route:      route,
+			Percentage: percentage.Uint64(),
+			AmountIn:   route.AmountIn,
+			AmountOut:  route.AmountOut,
+		})
+		amountOut.Add(amountOut, route.AmountOut)
+	}
+
+	bestRoute := &entities.Route{
+		Hops:     append([]entities.Hop(nil), routes[0].Hops...), // Amounts differ from the split's
+		TokenIn:  tokenIn,
+		TokenOut: tokenOut,
+		AmountIn: amountIn,
+	}
+	bestRoute.AmountOut = bestRoute.CalculateAmountOut()
+	bestRoute.GasEstimate = estimateGas(bestRoute)
+	bestRoute.FillHopAmounts()
+
+	return &entities.Quote{
+		TokenIn:     tokenIn,
+		TokenOut:    tokenOut,
+		AmountIn:    amountIn,
+		AmountOut:   amountOut,
+		BestRoute:   bestRoute,
+		SplitRoutes: splits,
+		PriceImpact: calculateSplitPriceImpact(splits),
+		GasEstimate: routesGas(routes),
+		Sources:     sources,
+	}
+}
+
+// routesGas estimates the gas of a swap filling routes together
+func routesGas(routes []*entities.Route) uint64 {
+	if len(routes) == 1 {
+		return estimateGas(routes[0])
+	}
+	gas := estimateGas(nil) // Extra gas for split
+	for _, route := range routes {
+		gas += route.GasEstimate
+	}
+	return gas
+}
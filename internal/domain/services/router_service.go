@@ -2,177 +2,630 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
-// Default slippage tolerance in basis points (0.5%)
+// Default slippage tolerance in basis points (0.5%) for cross-chain quotes
+// and intent settlement; swaps default by pair class, see DefaultSlippage
 const DefaultSlippageBps = 50
 
 // Price impact warning threshold in basis points (1%)
 const PriceImpactWarningThreshold = 100
 
+// MinLiquidityScore skips pools where the trade is more than 5% of the input
+// reserve, so a dust pool with a stale rate can't win while a deeper pool
+// can take the trade
+const MinLiquidityScore = 9500
+
+// DefaultQuoteDeadline is how long a quote, and the swap built from it,
+// stays valid
+const DefaultQuoteDeadline = 2 * time.Minute
+
+// RFQProvider solicits signed firm quotes from market makers
+type RFQProvider interface {
+	RequestQuotes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) []entities.RFQOrder
+}
+
+// TokenEquivalents lists the tokens a token converts to through pools, such
+// as a bridged token's native variant
+type TokenEquivalents interface {
+	Equivalents(addr common.Address) []entities.Token
+}
+
 type RouterService struct {
-	priceService *PriceService
+	priceService    *PriceService
+	rfqProvider     RFQProvider
+	feeService      *FeeService // Prices gas for netOutput quotes
+	equivalents     TokenEquivalents
+	strategies      map[string]RouteFinder
+	defaultStrategy string
+	intermediates   *IntermediateIndex
+	deadline        time.Duration
+	sizeGuard       TradeSizeGuard
 }
 
 func NewRouterService(priceService *PriceService) *RouterService {
-	return &RouterService{
-		priceService: priceService,
+	s := &RouterService{
+		priceService:    priceService,
+		strategies:      make(map[string]RouteFinder),
+		defaultStrategy: DefaultStrategy,
+		deadline:        DefaultQuoteDeadline,
+		sizeGuard:       DefaultTradeSizeGuard,
+	}
+	s.RegisterStrategy(&greedyRouteFinder{priceService: priceService})
+	s.RegisterStrategy(&directRouteFinder{priceService: priceService})
+	return s
+}
+
+// SetIntermediateIndex supplies multi-hop intermediates to callers of
+// GetMultiHopQuote that pass none
+func (s *RouterService) SetIntermediateIndex(index *IntermediateIndex) {
+	s.intermediates = index
+}
+
+// SetQuoteDeadline sets how long quotes stay valid
+func (s *RouterService) SetQuoteDeadline(deadline time.Duration) {
+	s.deadline = deadline
+}
+
+// RegisterStrategy makes a RouteFinder selectable by name, replacing any
+// strategy already registered under that name
+func (s *RouterService) RegisterStrategy(finder RouteFinder) {
+	s.strategies[finder.Name()] = finder
+}
+
+// SetDefaultStrategy picks the strategy used when a request names none
+func (s *RouterService) SetDefaultStrategy(name string) error {
+	if _, ok := s.strategies[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
 	}
+	s.defaultStrategy = name
+	return nil
+}
+
+// Strategies lists the registered strategy names in sorted order
+func (s *RouterService) Strategies() []string {
+	names := make([]string, 0, len(s.strategies))
+	for name := range s.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTokenEquivalents routes pairs with no route of their own through an
+// equivalent of either token, inserting the conversion as a hop
+func (s *RouterService) SetTokenEquivalents(equivalents TokenEquivalents) {
+	s.equivalents = equivalents
+}
+
+// SetRFQProvider lets market maker quotes compete with AMM routes in
+// GetSmartQuote
+func (s *RouterService) SetRFQProvider(provider RFQProvider) {
+	s.rfqProvider = provider
 }
 
 func (s *RouterService) GetQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.Quote, error) {
+	epoch := s.priceService.ReorgEpoch()
+	quote, err := s.getQuote(ensurePairSnapshot(ctx), tokenIn, tokenOut, amountIn)
+	if err == nil && s.priceService.Orphaned(epoch, quote.QuotedAtBlock) {
+		// A reorg orphaned the reserves mid-quote; quote again on the new
+		// chain, with pools read afresh
+		return s.getQuote(WithPairSnapshot(ctx), tokenIn, tokenOut, amountIn)
+	}
+	return quote, err
+}
+
+func (s *RouterService) getQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.Quote, error) {
 	prices, err := s.priceService.GetPrices(ctx, tokenIn, tokenOut, amountIn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prices: %w", err)
 	}
 
-	var bestResult *PriceResult
-	sources := make(map[entities.DEXType]string)
-
-	for i := range prices {
-		if prices[i].Error != nil {
-			continue
-		}
-		if prices[i].AmountOut == nil || prices[i].AmountOut.Sign() <= 0 {
-			continue
-		}
-
-		sources[prices[i].DEX] = prices[i].AmountOut.String()
-
-		if bestResult == nil || prices[i].AmountOut.Cmp(bestResult.AmountOut) > 0 {
-			bestResult = &prices[i]
-		}
+	validPrices := filterValidPrices(prices)
+	if len(validPrices) == 0 {
+		return nil, noRouteError(prices, tokenIn, amountIn)
 	}
+	bestResult := &validPrices[0]
 
-	if bestResult == nil {
-		return nil, fmt.Errorf("no valid routes found")
+	sources := make(map[entities.DEXType]string)
+	for _, p := range validPrices {
+		sources[p.DEX] = p.AmountOut.String()
 	}
 
-	route := s.buildRoute(tokenIn, tokenOut, amountIn, bestResult)
+	route := buildRoute(tokenIn, tokenOut, amountIn, bestResult)
 
 	priceImpact := route.CalculatePriceImpact()
 
-	return &entities.Quote{
-		TokenIn:     tokenIn,
-		TokenOut:    tokenOut,
-		AmountIn:    amountIn,
-		AmountOut:   bestResult.AmountOut,
-		BestRoute:   route,
-		PriceImpact: priceImpact,
-		GasEstimate: estimateGas(route),
-		Sources:     sources,
-	}, nil
+	quote := &entities.Quote{
+		TokenIn:       tokenIn,
+		TokenOut:      tokenOut,
+		AmountIn:      amountIn,
+		AmountOut:     bestResult.AmountOut,
+		BestRoute:     route,
+		PriceImpact:   priceImpact,
+		GasEstimate:   estimateGas(route),
+		Sources:       sources,
+		SourceDetails: buildSourceDetails(prices),
+	}
+	quote.QuotedAtBlock = quotedAtBlock(quote)
+	quote.VerifiedPools = verifiedPoolsOnly(ctx)
+	s.priceService.verifyHops(ctx, quote)
+	ApplyDeadline(quote, s.deadline)
+	return quote, nil
 }
 
 // buildRoute creates a Route from a price result
-func (s *RouterService) buildRoute(tokenIn, tokenOut entities.Token, amountIn *big.Int, result *PriceResult) *entities.Route {
+func buildRoute(tokenIn, tokenOut entities.Token, amountIn *big.Int, result *PriceResult) *entities.Route {
 	hop := entities.Hop{
 		Pair:     *result.Pair,
 		TokenIn:  tokenIn.Address,
 		TokenOut: tokenOut.Address,
 	}
 
-	return &entities.Route{
-		Hops:        []entities.Hop{hop},
-		TokenIn:     tokenIn,
-		TokenOut:    tokenOut,
-		AmountIn:    amountIn,
-		AmountOut:   result.AmountOut,
-		GasEstimate: estimateGas(nil), // Will be recalculated
+	route := &entities.Route{
+		Hops:      []entities.Hop{hop},
+		TokenIn:   tokenIn,
+		TokenOut:  tokenOut,
+		AmountIn:  amountIn,
+		AmountOut: result.AmountOut,
 	}
+	route.GasEstimate = estimateGas(route)
+	route.FillHopAmounts()
+
+	return route
+}
+
+// Gas per hop by venue, used when no transaction can be simulated and until
+// GasCalibrator has sampled enough of the venue's swaps
+var gasPerHopByDEX = map[entities.DEXType]uint64{
+	entities.DEXUniswapV2: 100000,
+	entities.DEXSushiswap: 100000,
+	entities.DEXUniswapV3: 130000,
+	entities.DEXCurve:     250000,
+	entities.DEXBalancer:  180000,
+	entities.DEXLido:      80000,
+	entities.DEXWrapper:   80000, // sDAI deposits drip the DSR first; WETH wraps for less
+	entities.DEXRFQ:       90000, // Signature check plus two transfers
+	entities.DEXMakerPSM:  80000,
 }
 
-// estimateGas estimates gas for a route
+// defaultGasPerHop applies to venues without a calibrated constant
+const defaultGasPerHop = 100000
+
+// learnedGasPerHop holds GasCalibrator's medians, which take over from
+// gasPerHopByDEX once a venue has enough sampled swaps
+var learnedGasPerHop sync.Map // entities.DEXType -> uint64
+
+// gasPerHop returns the gas one hop through a venue is expected to use
+func gasPerHop(dexType entities.DEXType) uint64 {
+	if learned, ok := learnedGasPerHop.Load(dexType); ok {
+		return learned.(uint64)
+	}
+	if hopGas, ok := gasPerHopByDEX[dexType]; ok {
+		return hopGas
+	}
+	return defaultGasPerHop
+}
+
+// estimateGas estimates gas for a route from per-venue hop costs
 func estimateGas(route *entities.Route) uint64 {
 	if route == nil || len(route.Hops) == 0 {
 		return 150000 // Default single swap estimate
 	}
 
-	baseGas := uint64(21000)
-	gasPerHop := uint64(100000) // Approximate gas for a Uniswap V2 swap
+	gas := uint64(21000)
+	for _, hop := range route.Hops {
+		gas += gasPerHop(hop.Pair.DEX)
+	}
 
-	return baseGas + uint64(len(route.Hops))*gasPerHop
+	return gas
 }
 
-// GetMultiHopQuote finds the best route including multi-hop paths (Phase 3)
+// GetMultiHopQuote finds the best route including multi-hop paths (Phase 3).
+// Nil intermediateTokens uses the intermediate index when one is set, plus
+// the hubs of any routing preset either token belongs to.
 func (s *RouterService) GetMultiHopQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, intermediateTokens []entities.Token) (*entities.Quote, error) {
+	if intermediateTokens == nil {
+		if s.intermediates != nil {
+			intermediateTokens = s.intermediates.Candidates()
+		}
+		intermediateTokens = withPresetHubs(intermediateTokens, tokenIn, tokenOut)
+	}
+	// Hops through different intermediates, and the direct quote, read
+	// their shared pools once
+	ctx = ensurePairSnapshot(ctx)
 	directQuote, directErr := s.GetQuote(ctx, tokenIn, tokenOut, amountIn)
 
 	var bestQuote *entities.Quote
+	best := &multiHopBest{}
 	if directErr == nil {
 		bestQuote = directQuote
+		best.raise(directQuote.AmountOut)
 	}
 
-	for _, intermediate := range intermediateTokens {
+	// Intermediates are priced concurrently, each branch pruning its own
+	// dominated first hops and cut short once another route beats all it
+	// can reach, so adding venues and hubs doesn't add latency
+	branches := make([]hopBranch, len(intermediateTokens))
+	branchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	slots := make(chan struct{}, multiHopConcurrency)
+	var wg sync.WaitGroup
+	for i, intermediate := range intermediateTokens {
 		if intermediate.Address == tokenIn.Address || intermediate.Address == tokenOut.Address {
 			continue
 		}
-
-		hop1Prices, err := s.priceService.GetPrices(ctx, tokenIn, intermediate, amountIn)
-		if err != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-branchCtx.Done():
+				return
+			}
+			defer func() { <-slots }()
+			branches[i] = s.bestThrough(branchCtx, tokenIn, intermediate, tokenOut, amountIn, best)
+			if route := branches[i].route; route != nil {
+				best.raise(route.AmountOut)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// A hop whose venues failed might have routed
+	var hopFailures int
+	var lastHopFailure error
+	for _, branch := range branches {
+		hopFailures += branch.failures
+		if branch.lastFailure != nil {
+			lastHopFailure = branch.lastFailure
+		}
+		// Ties go to the earlier intermediate, as candidates are ranked
+		route := branch.route
+		if route == nil || (bestQuote != nil && route.AmountOut.Cmp(bestQuote.AmountOut) <= 0) {
 			continue
 		}
+		bestQuote = &entities.Quote{
+			TokenIn:     tokenIn,
+			TokenOut:    tokenOut,
+			AmountIn:    amountIn,
+			AmountOut:   route.AmountOut,
+			BestRoute:   route,
+			PriceImpact: route.CalculatePriceImpact(),
+			GasEstimate: route.GasEstimate,
+			Sources:     make(map[entities.DEXType]string),
+		}
+	}
 
-		for _, hop1 := range hop1Prices {
-			if hop1.Error != nil || hop1.AmountOut == nil || hop1.AmountOut.Sign() <= 0 {
-				continue
-			}
+	if bestQuote == nil {
+		// The direct pair's failure, such as an RPC outage, says more than
+		// a bare no-route
+		if apperror.CodeOf(directErr) != apperror.NoRoute {
+			return nil, directErr
+		}
+		if hopFailures > 0 {
+			return nil, apperror.Wrap(apperror.UpstreamFailed, fmt.Errorf("no direct route, and %d venue reads of multi-hop paths failed, last: %w", hopFailures, lastHopFailure))
+		}
+		return nil, apperror.New(apperror.NoRoute, "no direct or multi-hop route")
+	}
+	bestQuote.QuotedAtBlock = quotedAtBlock(bestQuote)
+	bestQuote.VerifiedPools = verifiedPoolsOnly(ctx)
+	s.priceService.verifyHops(ctx, bestQuote)
+	ApplyDeadline(bestQuote, s.deadline)
 
-			hop2Prices, err := s.priceService.GetPrices(ctx, intermediate, tokenOut, hop1.AmountOut)
-			if err != nil {
-				continue
+	return bestQuote, nil
+}
+
+// multiHopConcurrency caps how many intermediates a multi-hop quote prices
+// at once
+const multiHopConcurrency = 8
+
+// multiHopBest is the largest output a multi-hop quote has found so far,
+// shared by its branches. Branches whose bound it passes are cancelled.
+type multiHopBest struct {
+	mu      sync.Mutex
+	amount  *big.Int
+	bounded []boundedBranch
+}
+
+type boundedBranch struct {
+	bound  *big.Int
+	cancel context.CancelFunc
+}
+
+// raise records a route paying amount, cancelling the branches it beats
+func (b *multiHopBest) raise(amount *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.amount != nil && amount.Cmp(b.amount) <= 0 {
+		return
+	}
+	b.amount = amount
+	kept := b.bounded[:0]
+	for _, branch := range b.bounded {
+		if branch.bound.Cmp(amount) < 0 {
+			branch.cancel()
+		} else {
+			kept = append(kept, branch)
+		}
+	}
+	b.bounded = kept
+}
+
+// beaten reports whether a branch that can reach at most bound is already
+// beaten, and otherwise has cancel called once it is. A branch that could
+// tie is kept, as ties go to the earlier intermediate.
+func (b *multiHopBest) beaten(bound *big.Int, cancel context.CancelFunc) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.amount != nil && bound.Cmp(b.amount) < 0 {
+		return true
+	}
+	b.bounded = append(b.bounded, boundedBranch{bound: bound, cancel: cancel})
+	return false
+}
+
+// hopBranch is the best two-hop route through one intermediate, and the
+// venue reads along it that failed
+type hopBranch struct {
+	route       *entities.Route
+	failures    int
+	lastFailure error
+}
+
+// bestThrough finds the best route from tokenIn to tokenOut through
+// intermediate. A venue never pays out less for a larger input, so a first
+// hop that yields less than another can't lead anywhere better: first hops
+// are tried from the largest output down, and the rest are pruned once one
+// reaches tokenOut. When the largest first hop reaches it only through
+// pools that were pruned, what they priced bounds the smaller first hops,
+// and the branch stops once best reaches that bound.
+func (s *RouterService) bestThrough(ctx context.Context, tokenIn, intermediate, tokenOut entities.Token, amountIn *big.Int, best *multiHopBest) hopBranch {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var branch hopBranch
+	countFailures := func(prices []PriceResult) {
+		for _, p := range prices {
+			if venueFailed(p) {
+				branch.failures++
+				branch.lastFailure = p.Error
 			}
+		}
+	}
 
-			for _, hop2 := range hop2Prices {
-				if hop2.Error != nil || hop2.AmountOut == nil || hop2.AmountOut.Sign() <= 0 {
-					continue
-				}
+	hop1Prices, err := s.priceService.GetPrices(ctx, tokenIn, intermediate, amountIn)
+	if err != nil {
+		return branch
+	}
+	countFailures(hop1Prices)
+	var firstHops []PriceResult
+	for _, hop1 := range hop1Prices {
+		if isValidPrice(hop1) {
+			firstHops = append(firstHops, hop1)
+		}
+	}
+	sort.SliceStable(firstHops, func(i, j int) bool {
+		return firstHops[i].AmountOut.Cmp(firstHops[j].AmountOut) > 0
+	})
 
-				if bestQuote == nil || hop2.AmountOut.Cmp(bestQuote.AmountOut) > 0 {
-					route := &entities.Route{
-						Hops: []entities.Hop{
-							{Pair: *hop1.Pair, TokenIn: tokenIn.Address, TokenOut: intermediate.Address},
-							{Pair: *hop2.Pair, TokenIn: intermediate.Address, TokenOut: tokenOut.Address},
-						},
-						TokenIn:     tokenIn,
-						TokenOut:    tokenOut,
-						AmountIn:    amountIn,
-						AmountOut:   hop2.AmountOut,
-						GasEstimate: estimateGas(nil),
-					}
-					route.GasEstimate = estimateGas(route)
-
-					bestQuote = &entities.Quote{
-						TokenIn:     tokenIn,
-						TokenOut:    tokenOut,
-						AmountIn:    amountIn,
-						AmountOut:   hop2.AmountOut,
-						BestRoute:   route,
-						PriceImpact: route.CalculatePriceImpact(),
-						GasEstimate: route.GasEstimate,
-						Sources:     make(map[entities.DEXType]string),
-					}
+	for i, hop1 := range firstHops {
+		if ctx.Err() != nil {
+			return branch
+		}
+		hop2Prices, err := s.priceService.GetPrices(ctx, intermediate, tokenOut, hop1.AmountOut)
+		if ctx.Err() != nil {
+			// Beaten, or the quote is done; reads cut short aren't failures
+			return branch
+		}
+		if err != nil {
+			continue
+		}
+		countFailures(hop2Prices)
+
+		var hop2 *PriceResult
+		for j, p := range hop2Prices {
+			if isValidPrice(p) && (hop2 == nil || p.AmountOut.Cmp(hop2.AmountOut) > 0) {
+				hop2 = &hop2Prices[j]
+			}
+		}
+		if hop2 == nil {
+			if i == 0 {
+				if bound := secondHopBound(hop2Prices); bound != nil && best.beaten(bound, cancel) {
+					return branch
 				}
 			}
+			// A smaller input may still fit a pool this one overdrew
+			continue
+		}
+
+		route := &entities.Route{
+			Hops: []entities.Hop{
+				{Pair: *hop1.Pair, TokenIn: tokenIn.Address, TokenOut: intermediate.Address},
+				{Pair: *hop2.Pair, TokenIn: intermediate.Address, TokenOut: tokenOut.Address},
+			},
+			TokenIn:   tokenIn,
+			TokenOut:  tokenOut,
+			AmountIn:  amountIn,
+			AmountOut: hop2.AmountOut,
 		}
+		route.GasEstimate = estimateGas(route)
+		route.FillHopAmounts()
+		branch.route = route
+		return branch
 	}
+	return branch
+}
 
-	if bestQuote == nil {
-		return nil, fmt.Errorf("no valid routes found (direct or multi-hop)")
+// secondHopBound is the most a smaller first hop can reach through the
+// second hop's pools: the largest amount they priced, including those
+// pruned as too shallow or unverified. It is nil when a venue failed or
+// priced nothing at this size, as it might for a smaller input.
+func secondHopBound(hop2Prices []PriceResult) *big.Int {
+	var bound *big.Int
+	for _, p := range hop2Prices {
+		if errors.Is(p.Error, dex.ErrPoolNotFound) {
+			continue
+		}
+		if venueFailed(p) || p.AmountOut == nil || p.AmountOut.Sign() <= 0 {
+			return nil
+		}
+		if bound == nil || p.AmountOut.Cmp(bound) > 0 {
+			bound = p.AmountOut
+		}
 	}
+	return bound
+}
 
-	return bestQuote, nil
+// withPresetHubs adds the preset hubs for the pair that candidates lack
+func withPresetHubs(candidates []entities.Token, tokenIn, tokenOut entities.Token) []entities.Token {
+	for _, hub := range entities.PresetHubs(tokenIn.Address, tokenOut.Address) {
+		found := false
+		for _, candidate := range candidates {
+			if candidate.Address == hub.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			candidates = append(candidates, hub)
+		}
+	}
+	return candidates
 }
 
 func (s *RouterService) GetSmartQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.Quote, error) {
+	return s.GetStrategyQuote(ctx, "", tokenIn, tokenOut, amountIn, slippageBps)
+}
+
+// GetStrategyQuote is GetSmartQuote with the AMM routes found by the named
+// strategy; an empty name uses the default strategy
+func (s *RouterService) GetStrategyQuote(ctx context.Context, strategy string, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.Quote, error) {
+	if strategy == "" {
+		strategy = s.defaultStrategy
+	}
+	finder, ok := s.strategies[strategy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+	}
+
+	epoch := s.priceService.ReorgEpoch()
+	ctx = ensurePairSnapshot(ctx)
+	quote, err := s.smartQuote(ctx, finder, tokenIn, tokenOut, amountIn, slippageBps)
+	if err == nil && s.priceService.Orphaned(epoch, quote.QuotedAtBlock) {
+		// The retry and the fallbacks below read afresh
+		ctx = WithPairSnapshot(ctx)
+		quote, err = s.smartQuote(ctx, finder, tokenIn, tokenOut, amountIn, slippageBps)
+	}
+	if wrapped := s.wrapQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps); wrapped != nil && (err != nil || wrapped.AmountOut.Cmp(quote.AmountOut) > 0) {
+		return wrapped, nil
+	}
+	if code := apperror.CodeOf(err); code == apperror.NoRoute || code == apperror.InsufficientLiquidity {
+		if converted := s.convertedQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps); converted != nil {
+			return converted, nil
+		}
+	}
+	return quote, err
+}
+
+// wrapQuote routes through a token either side wraps or is wrapped by,
+// converting at the wrapper's rate, e.g. stETH -> wstETH -> USDC where
+// wstETH's pools pay more. Nil when neither token has a wrap that routes.
+func (s *RouterService) wrapQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) *entities.Quote {
+	via := append(entities.WrapCounterparts(tokenIn.Address), entities.WrapCounterparts(tokenOut.Address)...)
+	return s.viaQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps, via)
+}
+
+// convertedQuote routes a pair that has no route through an equivalent of
+// either token, e.g. USDC.e -> USDC -> X when only native USDC trades
+// against X. Nil when neither token has an equivalent that routes.
+func (s *RouterService) convertedQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) *entities.Quote {
+	if s.equivalents == nil {
+		return nil
+	}
+	via := append(s.equivalents.Equivalents(tokenIn.Address), s.equivalents.Equivalents(tokenOut.Address)...)
+	quote := s.viaQuote(ctx, tokenIn, tokenOut, amountIn, slippageBps, via)
+	if quote == nil {
+		return nil
+	}
+	for i := range via {
+		if via[i].Address == quote.BestRoute.Hops[0].TokenOut {
+			quote.ConvertedVia = &via[i]
+		}
+	}
+	return quote
+}
+
+// viaQuote is the best two-hop route through one of via, with slippage
+// protection applied. Nil when via is empty or no route through it beats
+// the direct pair.
+func (s *RouterService) viaQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64, via []entities.Token) *entities.Quote {
+	if len(via) == 0 {
+		return nil
+	}
+	quote, err := s.GetMultiHopQuote(ctx, tokenIn, tokenOut, amountIn, via)
+	if err != nil || len(quote.BestRoute.Hops) != 2 {
+		return nil
+	}
+
+	slippageDefault := DefaultSlippage(tokenIn, tokenOut)
 	if slippageBps == 0 {
-		slippageBps = DefaultSlippageBps
+		slippageBps = slippageDefault.Bps
 	}
+	quote.SlippageDefault = &slippageDefault
+	applySlippageProtection(quote, slippageBps)
+	return quote
+}
+
+// ShadowQuote routes a trade with strategy's AMM routes alone, for
+// comparison with a served quote: no market maker is asked and no slippage
+// or deadline is applied
+func (s *RouterService) ShadowQuote(ctx context.Context, strategy string, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.Quote, error) {
+	finder, ok := s.strategies[strategy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+	}
+	routes, err := finder.FindRoutes(ensurePairSnapshot(ctx), tokenIn, tokenOut, amountIn, RouteOptions{SlippageBps: DefaultSlippage(tokenIn, tokenOut).Bps})
+	if err != nil {
+		return nil, err
+	}
+	quote := quoteFromRoutes(tokenIn, tokenOut, amountIn, routes, make(map[entities.DEXType]string))
+	if quote != nil {
+		quote.Strategy = finder.Name()
+	}
+	return quote, nil
+}
+
+// PlanQuote routes one slice of an execution plan with strategy's AMM
+// routes alone: a market maker's quote is firm for the next trade, not for
+// slices sent minutes apart
+func (s *RouterService) PlanQuote(ctx context.Context, strategy string, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.Quote, error) {
+	if strategy == "" {
+		strategy = s.defaultStrategy
+	}
+	return s.ShadowQuote(ctx, strategy, tokenIn, tokenOut, amountIn)
+}
+
+func (s *RouterService) smartQuote(ctx context.Context, finder RouteFinder, tokenIn, tokenOut entities.Token, amountIn *big.Int, slippageBps uint64) (*entities.Quote, error) {
+	slippageDefault := DefaultSlippage(tokenIn, tokenOut)
+	if slippageBps == 0 {
+		slippageBps = slippageDefault.Bps
+	}
+	timing := QuoteTimingFrom(ctx)
+	start, waited := time.Now(), timing.waitedFor()
 
 	prices, err := s.priceService.GetPrices(ctx, tokenIn, tokenOut, amountIn)
 	if err != nil {
@@ -181,40 +634,79 @@ func (s *RouterService) GetSmartQuote(ctx context.Context, tokenIn, tokenOut ent
 
 	// Filter valid prices and sort by output amount (descending)
 	validPrices := filterValidPrices(prices)
-	if len(validPrices) == 0 {
-		return nil, fmt.Errorf("no valid routes found")
-	}
 
-	var quote *entities.Quote
-	if len(validPrices) >= 2 {
-		splitQuote := s.trySplitOrder(tokenIn, tokenOut, amountIn, validPrices)
-		if splitQuote != nil {
-			quote = splitQuote
-		}
+	sources := make(map[entities.DEXType]string)
+	for _, p := range validPrices {
+		sources[p.DEX] = p.AmountOut.String()
 	}
 
-	if quote == nil {
-		bestResult := &validPrices[0]
-		route := s.buildRoute(tokenIn, tokenOut, amountIn, bestResult)
+	opts := RouteOptions{SlippageBps: slippageBps, OptimizeFor: optimizeForFrom(ctx)}
+	if opts.OptimizeFor == OptimizeNetOutput {
+		opts.GasPriceOut = s.gasPriceOut(ctx, tokenOut)
+	}
 
-		sources := make(map[entities.DEXType]string)
-		for _, p := range validPrices {
-			sources[p.DEX] = p.AmountOut.String()
+	// Pairs fetched above are cached, so the finder reads the same reserves
+	routes, err := finder.FindRoutes(ctx, tokenIn, tokenOut, amountIn, opts)
+	if err != nil {
+		return nil, err
+	}
+	quote := quoteFromRoutes(tokenIn, tokenOut, amountIn, routes, sources)
+	// Concurrent pair reads can wait longer in sum than routing took
+	timing.Add(StageRouting, max(time.Since(start)-(timing.waitedFor()-waited), 0))
+
+	sourceDetails := buildSourceDetails(prices)
+
+	// Firm market maker quotes compete with the AMM result
+	if s.rfqProvider != nil && venueAllowed(ctx, entities.DEXRFQ) && !verifiedPoolsOnly(ctx) {
+		rfqStart := time.Now()
+		rfqQuote, detail := s.bestRFQQuote(ctx, tokenIn, tokenOut, amountIn)
+		timing.Add(StageRFQ, time.Since(rfqStart))
+		sourceDetails = append(sourceDetails, detail)
+		sort.Slice(sourceDetails, func(i, j int) bool {
+			return sourceDetails[i].DEX < sourceDetails[j].DEX
+		})
+		switch {
+		case rfqQuote == nil:
+		case quote == nil || opts.prefer([]routeCandidate{
+			{amountOut: quote.AmountOut, gas: quote.GasEstimate},
+			{amountOut: rfqQuote.AmountOut, gas: rfqQuote.GasEstimate},
+		}) == 1:
+			for _, p := range validPrices {
+				rfqQuote.Sources[p.DEX] = p.AmountOut.String()
+			}
+			quote = rfqQuote
+		default:
+			quote.Sources[entities.DEXRFQ] = rfqQuote.AmountOut.String()
 		}
+	}
 
-		quote = &entities.Quote{
-			TokenIn:     tokenIn,
-			TokenOut:    tokenOut,
-			AmountIn:    amountIn,
-			AmountOut:   bestResult.AmountOut,
-			BestRoute:   route,
-			PriceImpact: route.CalculatePriceImpact(),
-			GasEstimate: estimateGas(route),
-			Sources:     sources,
-		}
+	if quote == nil {
+		return nil, noRouteError(prices, tokenIn, amountIn)
+	}
+	quote.TradeSize = s.sizeGuard.tradeSize(prices, tokenIn, amountIn)
+	// A market maker's order fills regardless of the pools' depth
+	if size := quote.TradeSize; size != nil && size.ExceedsDepth && s.sizeGuard.Reject && quote.RFQOrder == nil {
+		return nil, apperror.New(apperror.AmountTooLarge, fmt.Sprintf("%s %s is more than %.2f%% of the %s the pools hold", amountIn, tokenIn.Symbol, float64(s.sizeGuard.MaxDepthBps)/100, size.AccessibleLiquidity))
 	}
 
-	s.applySlippageProtection(quote, slippageBps)
+	quote.SourceDetails = sourceDetails
+	quote.Savings = quoteSavings(quote.AmountOut, validPrices)
+	quote.Strategy = finder.Name()
+	if opts.OptimizeFor != OptimizeOutput {
+		quote.OptimizeFor = string(opts.OptimizeFor)
+	}
+	quote.Venues = venuesFrom(ctx)
+	quote.PinnedBlock = ethereum.PinnedBlock(ctx)
+	quote.SlippageDefault = &slippageDefault
+	quote.QuotedAtBlock = quotedAtBlock(quote)
+	quote.VerifiedPools = verifiedPoolsOnly(ctx)
+	s.priceService.verifyHops(ctx, quote)
+	ApplyDeadline(quote, s.deadline)
+	applySlippageProtection(quote, slippageBps)
+	if quote.RFQOrder != nil {
+		// A signed order fills at exactly its amount
+		quote.MinAmountOut = quote.RFQOrder.AmountOut
+	}
 
 	if quote.PriceImpact != nil && quote.PriceImpact.Cmp(big.NewInt(PriceImpactWarningThreshold)) > 0 {
 		impactPct := float64(quote.PriceImpact.Int64()) / 100.0
@@ -224,122 +716,98 @@ func (s *RouterService) GetSmartQuote(ctx context.Context, tokenIn, tokenOut ent
 	return quote, nil
 }
 
-// trySplitOrder attempts to split the order across multiple DEXes for better execution
-func (s *RouterService) trySplitOrder(tokenIn, tokenOut entities.Token, amountIn *big.Int, prices []PriceResult) *entities.Quote {
-	if len(prices) < 2 {
-		return nil
+// ApplyDeadline sets the quote to expire deadline from now, or when its
+// market maker order expires if that is sooner
+func ApplyDeadline(quote *entities.Quote, deadline time.Duration) {
+	quote.ExpiresAt = time.Now().Add(deadline).Truncate(time.Second)
+	if quote.RFQOrder != nil && quote.RFQOrder.Expiry != 0 {
+		if expiry := time.Unix(int64(quote.RFQOrder.Expiry), 0); expiry.Before(quote.ExpiresAt) {
+			quote.ExpiresAt = expiry
+		}
 	}
+}
 
-	sort.Slice(prices, func(i, j int) bool {
-		return prices[i].AmountOut.Cmp(prices[j].AmountOut) > 0
-	})
-
-	bestSplitOutput := big.NewInt(0)
-	var bestSplits []entities.SplitRoute
-	bestGas := uint64(0)
-
-	singleOutput := prices[0].AmountOut
-	singleGas := estimateGas(nil)
-
-	// Try splits: 50/50, 60/40, 70/30, 80/20
-	splitRatios := [][]uint64{{50, 50}, {60, 40}, {70, 30}, {80, 20}}
-
-	for _, ratio := range splitRatios {
-		amount1 := new(big.Int).Mul(amountIn, big.NewInt(int64(ratio[0])))
-		amount1.Div(amount1, big.NewInt(100))
-		amount2 := new(big.Int).Sub(amountIn, amount1)
-
-		output1 := prices[0].Pair.GetAmountOut(amount1, tokenIn.Address)
-		output2 := prices[1].Pair.GetAmountOut(amount2, tokenIn.Address)
-
-		totalOutput := new(big.Int).Add(output1, output2)
-		totalGas := estimateGas(nil) * 2 // Two swaps
-
-		// For simplicity, compare raw output (gas optimization would need ETH price)
-		if totalOutput.Cmp(bestSplitOutput) > 0 {
-			bestSplitOutput = totalOutput
-			bestGas = totalGas
-
-			route1 := &entities.Route{
-				Hops: []entities.Hop{{
-					Pair:     *prices[0].Pair,
-					TokenIn:  tokenIn.Address,
-					TokenOut: tokenOut.Address,
-				}},
-				TokenIn:     tokenIn,
-				TokenOut:    tokenOut,
-				AmountIn:    amount1,
-				AmountOut:   output1,
-				GasEstimate: estimateGas(nil),
-			}
-			route2 := &entities.Route{
-				Hops: []entities.Hop{{
-					Pair:     *prices[1].Pair,
-					TokenIn:  tokenIn.Address,
-					TokenOut: tokenOut.Address,
-				}},
-				TokenIn:     tokenIn,
-				TokenOut:    tokenOut,
-				AmountIn:    amount2,
-				AmountOut:   output2,
-				GasEstimate: estimateGas(nil),
-			}
+// quotedAtBlock returns the oldest block any pool on the quote's routes
+// was read at, ignoring venues that don't report one
+func quotedAtBlock(quote *entities.Quote) uint64 {
+	routes := []*entities.Route{quote.BestRoute}
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+	}
 
-			bestSplits = []entities.SplitRoute{
-				{Route: route1, Percentage: ratio[0], AmountIn: amount1, AmountOut: output1},
-				{Route: route2, Percentage: ratio[1], AmountIn: amount2, AmountOut: output2},
+	var oldest uint64
+	for _, route := range routes {
+		if route == nil {
+			continue
+		}
+		for _, hop := range route.Hops {
+			if block := hop.Pair.BlockNumber; block != 0 && (oldest == 0 || block < oldest) {
+				oldest = block
 			}
 		}
 	}
+	return oldest
+}
 
-	if bestSplitOutput.Cmp(singleOutput) <= 0 {
-		return nil
-	}
-
-	sources := make(map[entities.DEXType]string)
-	for _, p := range prices {
-		sources[p.DEX] = p.AmountOut.String()
+// applySlippageProtection calculates minimum output amount based on slippage
+func applySlippageProtection(quote *entities.Quote, slippageBps uint64) {
+	if quote.AmountOut == nil || quote.AmountOut.Sign() <= 0 {
+		return
 	}
 
-	bestRoute := s.buildRoute(tokenIn, tokenOut, amountIn, &prices[0])
+	quote.MinAmountOut = slippageFloor(quote.AmountOut, slippageBps)
+	quote.SlippageBps = slippageBps
+}
 
-	priceImpact := calculateSplitPriceImpact(bestSplits)
+// buildSourceDetails reports every venue's outcome, including failures
+func buildSourceDetails(prices []PriceResult) []entities.SourceDetail {
+	liquid := hasLiquidPool(prices)
+	details := make([]entities.SourceDetail, 0, len(prices))
+	for _, p := range prices {
+		detail := entities.SourceDetail{
+			DEX:            p.DEX,
+			LiquidityScore: p.LiquidityScore,
+			LatencyMs:      p.Latency.Milliseconds(),
+		}
 
-	return &entities.Quote{
-		TokenIn:     tokenIn,
-		TokenOut:    tokenOut,
-		AmountIn:    amountIn,
-		AmountOut:   bestSplitOutput,
-		BestRoute:   bestRoute,
-		SplitRoutes: bestSplits,
-		PriceImpact: priceImpact,
-		GasEstimate: bestGas + singleGas, // Extra gas for split
-		Sources:     sources,
-	}
-}
+		switch {
+		case p.Error != nil:
+			detail.Error = p.Error.Error()
+		case p.AmountOut == nil || p.AmountOut.Sign() <= 0 || p.Pair == nil:
+			detail.Error = "no liquidity for trade size"
+		case liquid && p.LiquidityScore < MinLiquidityScore:
+			detail.AmountOut = p.AmountOut
+			detail.Error = "pool too shallow for trade size"
+		case liquid && lowTVL(p):
+			detail.AmountOut = p.AmountOut
+			detail.Error = "pool TVL below minimum"
+		default:
+			detail.AmountOut = p.AmountOut
+			detail.GasEstimate = estimateGas(&entities.Route{
+				Hops: []entities.Hop{{Pair: *p.Pair}},
+			})
+		}
 
-// applySlippageProtection calculates minimum output amount based on slippage
-func (s *RouterService) applySlippageProtection(quote *entities.Quote, slippageBps uint64) {
-	if quote.AmountOut == nil || quote.AmountOut.Sign() <= 0 {
-		return
+		details = append(details, detail)
 	}
 
-	// minAmountOut = amountOut * (10000 - slippageBps) / 10000
-	multiplier := big.NewInt(10000 - int64(slippageBps))
-	minAmount := new(big.Int).Mul(quote.AmountOut, multiplier)
-	minAmount.Div(minAmount, big.NewInt(10000))
+	sort.Slice(details, func(i, j int) bool {
+		return details[i].DEX < details[j].DEX
+	})
 
-	quote.MinAmountOut = minAmount
-	quote.SlippageBps = slippageBps
+	return details
 }
 
-// filterValidPrices filters and sorts prices by output amount
+// filterValidPrices filters and sorts prices by output amount. Shallow pools
+// are dropped whenever a pool with MinLiquidityScore can take the trade.
 func filterValidPrices(prices []PriceResult) []PriceResult {
+	liquid := hasLiquidPool(prices)
 	var valid []PriceResult
 	for _, p := range prices {
-		if p.Error == nil && p.AmountOut != nil && p.AmountOut.Sign() > 0 && p.Pair != nil {
-			valid = append(valid, p)
+		if !isValidPrice(p) || (liquid && isShallow(p)) {
+			continue
 		}
+		valid = append(valid, p)
 	}
 
 	sort.Slice(valid, func(i, j int) bool {
@@ -349,6 +817,106 @@ func filterValidPrices(prices []PriceResult) []PriceResult {
 	return valid
 }
 
+// noRouteError explains why prices gave no route. Venues that failed to
+// answer come first, since any of them may hold a pool that routes: every
+// venue failing is RPCUnavailable and some failing UpstreamFailed. With
+// every venue answering, pools that hold the pair but can't fill the trade
+// or are dust come next, and NoRoute is left for no pool at all.
+func noRouteError(prices []PriceResult, tokenIn entities.Token, amountIn *big.Int) error {
+	if err := upstreamError(prices); err != nil {
+		return err
+	}
+
+	var pools, overdrawn, dust, unverified int
+	for _, p := range prices {
+		switch {
+		case p.Error == nil && p.Pair != nil:
+			pools++
+			reserveIn := p.Pair.Reserve1
+			if p.Pair.Token0.Address == tokenIn.Address {
+				reserveIn = p.Pair.Reserve0
+			}
+			if reserveIn != nil && reserveIn.Sign() > 0 && amountIn.Cmp(reserveIn) >= 0 {
+				overdrawn++
+			}
+		case errors.Is(p.Error, ErrBelowMinLiquidity):
+			dust++
+		case errors.Is(p.Error, ErrPoolUnverified):
+			unverified++
+		}
+	}
+
+	switch {
+	case pools > 0 && overdrawn == pools:
+		return apperror.New(apperror.AmountTooLarge, fmt.Sprintf("%s %s is more than any pool holds", amountIn, tokenIn.Symbol))
+	case pools > 0:
+		return apperror.New(apperror.InsufficientLiquidity, fmt.Sprintf("%d pools hold the pair but none can fill the trade", pools))
+	case dust > 0:
+		return apperror.New(apperror.InsufficientLiquidity, fmt.Sprintf("%d pools hold the pair but are below the minimum liquidity", dust))
+	case unverified > 0:
+		return apperror.New(apperror.NoRoute, fmt.Sprintf("%d pools hold the pair but none is verified", unverified))
+	}
+	return apperror.New(apperror.NoRoute, "no venue holds the pair")
+}
+
+// upstreamError is RPCUnavailable when every venue failed to answer,
+// UpstreamFailed when some did, and nil when all answered
+func upstreamError(prices []PriceResult) error {
+	var failed int
+	var lastFailure error
+	for _, p := range prices {
+		if venueFailed(p) {
+			failed++
+			lastFailure = p.Error
+		}
+	}
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(prices):
+		return apperror.Wrap(apperror.RPCUnavailable, fmt.Errorf("every venue failed, last: %w", lastFailure))
+	}
+	return apperror.Wrap(apperror.UpstreamFailed, fmt.Errorf("%d of %d venues failed to answer, last: %w", failed, len(prices), lastFailure))
+}
+
+// venueFailed reports whether a venue failed to answer, rather than
+// answering without a pool or with one left out of routing
+func venueFailed(p PriceResult) bool {
+	return p.Error != nil && !errors.Is(p.Error, dex.ErrPoolNotFound) &&
+		!errors.Is(p.Error, ErrBelowMinLiquidity) && !errors.Is(p.Error, ErrPoolUnverified)
+}
+
+// isUpstreamFailure reports whether err failed on a node or venue rather
+// than on the pair
+func isUpstreamFailure(err error) bool {
+	return apperror.CodeOf(err).Retryable()
+}
+
+func isValidPrice(p PriceResult) bool {
+	return p.Error == nil && p.AmountOut != nil && p.AmountOut.Sign() > 0 && p.Pair != nil
+}
+
+// isShallow reports whether a pool is too small for the trade, by reserves
+// or, when the subgraph knows it, by TVL
+func isShallow(p PriceResult) bool {
+	return p.LiquidityScore < MinLiquidityScore || lowTVL(p)
+}
+
+func lowTVL(p PriceResult) bool {
+	return p.Pair.TVLUSD != nil && p.Pair.TVLUSD.Cmp(MinPoolTVLUSD) < 0
+}
+
+// hasLiquidPool reports whether any venue can absorb the trade comfortably
+func hasLiquidPool(prices []PriceResult) bool {
+	for _, p := range prices {
+		if isValidPrice(p) && !isShallow(p) {
+			return true
+		}
+	}
+	return false
+}
+
 // calculateSplitPriceImpact calculates weighted average price impact for split routes
 func calculateSplitPriceImpact(splits []entities.SplitRoute) *big.Int {
 	if len(splits) == 0 {
@@ -373,3 +941,62 @@ func calculateSplitPriceImpact(splits []entities.SplitRoute) *big.Int {
 
 	return new(big.Int).Div(weightedImpact, totalWeight)
 }
+
+// bestRFQQuote turns the best market maker order into a single-hop quote.
+// The maker address stands in for the pair address.
+func (s *RouterService) bestRFQQuote(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) (*entities.Quote, entities.SourceDetail) {
+	start := time.Now()
+	orders := s.rfqProvider.RequestQuotes(ctx, tokenIn, tokenOut, amountIn)
+	detail := entities.SourceDetail{
+		DEX:       entities.DEXRFQ,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+
+	var best *entities.RFQOrder
+	for i := range orders {
+		if best == nil || orders[i].AmountOut.Cmp(best.AmountOut) > 0 {
+			best = &orders[i]
+		}
+	}
+	if best == nil {
+		detail.Error = "no market maker quotes"
+		return nil, detail
+	}
+	// A firm order fills in full regardless of size
+	detail.LiquidityScore = 10000
+
+	route := &entities.Route{
+		Hops: []entities.Hop{{
+			Pair: entities.Pair{
+				Address: best.Maker,
+				Token0:  tokenIn,
+				Token1:  tokenOut,
+				DEX:     entities.DEXRFQ,
+			},
+			TokenIn:  tokenIn.Address,
+			TokenOut: tokenOut.Address,
+		}},
+		TokenIn:     tokenIn,
+		TokenOut:    tokenOut,
+		AmountIn:    amountIn,
+		AmountOut:   best.AmountOut,
+		PriceImpact: big.NewInt(0),
+	}
+	route.FillHopAmounts()
+	route.GasEstimate = estimateGas(route)
+
+	detail.AmountOut = best.AmountOut
+	detail.GasEstimate = route.GasEstimate
+
+	return &entities.Quote{
+		TokenIn:     tokenIn,
+		TokenOut:    tokenOut,
+		AmountIn:    amountIn,
+		AmountOut:   best.AmountOut,
+		BestRoute:   route,
+		PriceImpact: big.NewInt(0), // Firm quotes have no curve to move along
+		GasEstimate: route.GasEstimate,
+		RFQOrder:    best,
+		Sources:     map[entities.DEXType]string{entities.DEXRFQ: best.AmountOut.String()},
+	}, detail
+}
@@ -2,14 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/bimakw/dex-aggregator/internal/apperror"
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/testutil"
 )
 
 // MockDEXClient is a mock implementation of DEXClient for testing
@@ -18,6 +23,7 @@ type MockDEXClient struct {
 	pairs     map[string]*entities.Pair
 	amountOut *big.Int
 	err       error
+	caps      dex.Capabilities
 }
 
 func NewMockDEXClient(dexType entities.DEXType) *MockDEXClient {
@@ -36,6 +42,10 @@ func (m *MockDEXClient) SetAmountOut(amount *big.Int) {
 	m.amountOut = amount
 }
 
+func (m *MockDEXClient) SetCapabilities(caps dex.Capabilities) {
+	m.caps = caps
+}
+
 func (m *MockDEXClient) SetError(err error) {
 	m.err = err
 }
@@ -88,6 +98,10 @@ func (m *MockDEXClient) DEXType() entities.DEXType {
 	return m.dexType
 }
 
+func (m *MockDEXClient) Capabilities() dex.Capabilities {
+	return m.caps
+}
+
 // MockCache is a mock implementation of Cache for testing
 type MockCache struct{}
 
@@ -111,6 +125,10 @@ func (m *MockCache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (m *MockCache) Flush(ctx context.Context) error {
+	return nil
+}
+
 func TestRouterServiceGetQuote(t *testing.T) {
 	token0 := entities.Token{
 		Address:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
@@ -221,6 +239,76 @@ func TestRouterServiceNoValidRoutes(t *testing.T) {
 	}
 }
 
+func TestRouterServiceNoRouteCodes(t *testing.T) {
+	token0 := entities.Token{
+		Address:  common.HexToAddress("0x0000000000000000000000000000000000000001"),
+		Symbol:   "TOKEN0",
+		Decimals: 18,
+	}
+	token1 := entities.Token{
+		Address:  common.HexToAddress("0x0000000000000000000000000000000000000002"),
+		Symbol:   "TOKEN1",
+		Decimals: 18,
+	}
+	pool := func(reserve0, reserve1 *big.Int) *entities.Pair {
+		return &entities.Pair{
+			Address:  common.HexToAddress("0x1111"),
+			Token0:   token0,
+			Token1:   token1,
+			Reserve0: reserve0,
+			Reserve1: reserve1,
+			DEX:      entities.DEXUniswapV2,
+			Fee:      30,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(m *MockDEXClient)
+		want  apperror.Code
+	}{
+		{"no pool", func(m *MockDEXClient) {}, apperror.NoRoute},
+		{"venue down", func(m *MockDEXClient) { m.SetError(context.DeadlineExceeded) }, apperror.RPCUnavailable},
+		{"drained pool", func(m *MockDEXClient) {
+			m.SetPair(token0.Address, token1.Address, pool(new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)), big.NewInt(0)))
+		}, apperror.InsufficientLiquidity},
+		{"trade above reserves", func(m *MockDEXClient) {
+			m.SetPair(token0.Address, token1.Address, pool(big.NewInt(1e17), big.NewInt(1)))
+		}, apperror.AmountTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockDEXClient(entities.DEXUniswapV2)
+			tt.setup(mock)
+			routerService := NewRouterService(NewPriceService([]dex.DEXClient{mock}, &MockCache{}))
+
+			_, err := routerService.GetQuote(context.Background(), token0, token1, big.NewInt(1e18))
+			if got := apperror.CodeOf(err); got != tt.want {
+				t.Errorf("GetQuote error = %v (%s), want %s", err, got, tt.want)
+			}
+			_, err = routerService.GetSmartQuote(context.Background(), token0, token1, big.NewInt(1e18), 0)
+			if got := apperror.CodeOf(err); got != tt.want {
+				t.Errorf("GetSmartQuote error = %v (%s), want %s", err, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("some venues down", func(t *testing.T) {
+		down := NewMockDEXClient(entities.DEXSushiswap)
+		down.SetError(context.DeadlineExceeded)
+		routerService := NewRouterService(NewPriceService([]dex.DEXClient{NewMockDEXClient(entities.DEXUniswapV2), down}, &MockCache{}))
+
+		_, err := routerService.GetQuote(context.Background(), token0, token1, big.NewInt(1e18))
+		if got := apperror.CodeOf(err); got != apperror.UpstreamFailed {
+			t.Errorf("GetQuote error = %v (%s), want %s", err, got, apperror.UpstreamFailed)
+		}
+		if !apperror.CodeOf(err).Retryable() {
+			t.Error("partial upstream failure should be retryable")
+		}
+	})
+}
+
 func TestEstimateGas(t *testing.T) {
 	tests := []struct {
 		name string
@@ -249,3 +337,665 @@ func TestEstimateGas(t *testing.T) {
 		})
 	}
 }
+
+type mockRFQProvider struct {
+	orders []entities.RFQOrder
+}
+
+func (m *mockRFQProvider) RequestQuotes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) []entities.RFQOrder {
+	return m.orders
+}
+
+func TestRouterServiceRFQCompetesWithAMM(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+
+	mockV2 := NewMockDEXClient(entities.DEXUniswapV2)
+	mockV2.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   token0,
+		Token1:   token1,
+		Reserve0: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+
+	amountIn := big.NewInt(1e18)
+	tests := []struct {
+		name      string
+		amountOut *big.Int
+		wantRFQ   bool
+	}{
+		{"maker beats AMM", big.NewInt(1e18), true},
+		{"AMM beats maker", big.NewInt(9e17), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routerService := NewRouterService(NewPriceService([]dex.DEXClient{mockV2}, &MockCache{}))
+			routerService.SetRFQProvider(&mockRFQProvider{orders: []entities.RFQOrder{{
+				Maker:     common.HexToAddress("0xaaaa"),
+				AmountIn:  amountIn,
+				AmountOut: tt.amountOut,
+			}}})
+
+			quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, amountIn, 50)
+			if err != nil {
+				t.Fatalf("GetSmartQuote() error = %v", err)
+			}
+
+			if gotRFQ := quote.RFQOrder != nil; gotRFQ != tt.wantRFQ {
+				t.Fatalf("RFQOrder set = %v, want %v", gotRFQ, tt.wantRFQ)
+			}
+			if tt.wantRFQ && quote.MinAmountOut.Cmp(tt.amountOut) != 0 {
+				t.Errorf("MinAmountOut = %s, want firm %s", quote.MinAmountOut, tt.amountOut)
+			}
+			if _, ok := quote.Sources[entities.DEXRFQ]; !ok {
+				t.Error("Sources missing rfq entry")
+			}
+		})
+	}
+}
+
+func TestRouterServiceSkipsShallowPools(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	deep := NewMockDEXClient(entities.DEXUniswapV2)
+	deep.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(10000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	// A stale dust pool quoting 10x the market rate
+	dust := NewMockDEXClient(entities.DEXSushiswap)
+	dust.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10), Reserve1: ether(100), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{deep, dust}, &MockCache{}))
+
+	tests := []struct {
+		name     string
+		amountIn *big.Int
+		wantDEX  entities.DEXType
+	}{
+		{"trade above 5% of dust reserve", ether(1), entities.DEXUniswapV2},
+		{"trade small enough for dust pool", big.NewInt(1e17 / 4), entities.DEXSushiswap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, tt.amountIn, 50)
+			if err != nil {
+				t.Fatalf("GetSmartQuote() error = %v", err)
+			}
+			if len(quote.SplitRoutes) > 0 {
+				t.Fatalf("unexpected split route")
+			}
+			if got := quote.BestRoute.Hops[0].Pair.DEX; got != tt.wantDEX {
+				t.Errorf("best DEX = %s, want %s", got, tt.wantDEX)
+			}
+			for _, sd := range quote.SourceDetails {
+				if sd.LiquidityScore == 0 {
+					t.Errorf("%s liquidityScore = 0", sd.DEX)
+				}
+			}
+		})
+	}
+}
+
+// mockMultiPoolClient serves one pool per fee tier besides its best pair
+type mockMultiPoolClient struct {
+	*MockDEXClient
+	pools []*entities.Pair
+	reads int
+}
+
+func (m *mockMultiPoolClient) GetPairsByTokens(ctx context.Context, tokenA, tokenB entities.Token) ([]*entities.Pair, error) {
+	m.reads++
+	return m.pools, nil
+}
+
+func TestRouterServiceSplitsAcrossFeeTiers(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	usdc := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e6)) }
+	tier := func(addr string, fee uint64) *entities.Pair {
+		return &entities.Pair{
+			Address: common.HexToAddress(addr), Token0: entities.USDC, Token1: entities.WETH,
+			Reserve0: usdc(200000), Reserve1: ether(100), DEX: entities.DEXUniswapV3, Fee: fee,
+		}
+	}
+	low, high := tier("0x0500", 5), tier("0x3000", 30)
+
+	v3 := &mockMultiPoolClient{MockDEXClient: NewMockDEXClient(entities.DEXUniswapV3), pools: []*entities.Pair{low, high}}
+	v3.SetPair(entities.WETH.Address, entities.USDC.Address, low)
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{v3}, &MockCache{}))
+
+	quote, err := routerService.GetSmartQuote(context.Background(), entities.WETH, entities.USDC, ether(20), 50)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	if len(quote.SplitRoutes) != 2 {
+		t.Fatalf("got %d split routes, want a split across both tiers", len(quote.SplitRoutes))
+	}
+	fees := map[uint64]bool{}
+	for _, split := range quote.SplitRoutes {
+		fees[split.Route.Hops[0].Pair.Fee] = true
+	}
+	if !fees[5] || !fees[30] {
+		t.Errorf("split fee tiers = %v, want 5 and 30", fees)
+	}
+	single := low.GetAmountOut(ether(20), entities.WETH.Address)
+	if quote.AmountOut.Cmp(single) <= 0 {
+		t.Errorf("split output %s does not beat the best tier's %s", quote.AmountOut, single)
+	}
+
+	// A trade too small to move the price doesn't read the other tiers
+	v3.reads = 0
+	if _, err := routerService.GetSmartQuote(context.Background(), entities.WETH, entities.USDC, big.NewInt(1e15), 50); err != nil {
+		t.Fatal(err)
+	}
+	if v3.reads != 0 {
+		t.Errorf("fee tiers read %d times for a small trade", v3.reads)
+	}
+}
+
+type fixedRouteFinder struct {
+	name   string
+	routes []*entities.Route
+}
+
+func (f *fixedRouteFinder) Name() string {
+	return f.name
+}
+
+func (f *fixedRouteFinder) FindRoutes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, opts RouteOptions) ([]*entities.Route, error) {
+	return f.routes, nil
+}
+
+func TestRouterServiceStrategies(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(10000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(10000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{v2, sushi}, &MockCache{}))
+	routerService.RegisterStrategy(&fixedRouteFinder{name: "none"})
+
+	// Large enough that two equal pools beat one
+	amountIn := ether(400)
+	tests := []struct {
+		name       string
+		strategy   string
+		wantSplits int
+		wantErr    error
+	}{
+		{"default is greedy", "", 2, nil},
+		{"greedy splits", "greedy", 2, nil},
+		{"direct never splits", "direct", 0, nil},
+		{"unknown strategy", "exhaustive", 0, ErrUnknownStrategy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := routerService.GetStrategyQuote(context.Background(), tt.strategy, token0, token1, amountIn, 50)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetStrategyQuote() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetStrategyQuote() error = %v", err)
+			}
+			if len(quote.SplitRoutes) != tt.wantSplits {
+				t.Fatalf("split routes = %d, want %d", len(quote.SplitRoutes), tt.wantSplits)
+			}
+			wantStrategy := tt.strategy
+			if wantStrategy == "" {
+				wantStrategy = DefaultStrategy
+			}
+			if quote.Strategy != wantStrategy {
+				t.Errorf("Strategy = %q, want %q", quote.Strategy, wantStrategy)
+			}
+			if tt.wantSplits > 0 {
+				sum := new(big.Int)
+				for _, split := range quote.SplitRoutes {
+					sum.Add(sum, split.AmountOut)
+				}
+				if sum.Cmp(quote.AmountOut) != 0 {
+					t.Errorf("AmountOut = %s, want sum of splits %s", quote.AmountOut, sum)
+				}
+				if quote.SplitRoutes[0].Percentage+quote.SplitRoutes[1].Percentage != 100 {
+					t.Errorf("split percentages = %d/%d", quote.SplitRoutes[0].Percentage, quote.SplitRoutes[1].Percentage)
+				}
+				for i, split := range quote.SplitRoutes {
+					hop := split.Route.Hops[0]
+					if hop.AmountIn.Cmp(split.AmountIn) != 0 || hop.AmountOut.Cmp(split.AmountOut) != 0 {
+						t.Errorf("split %d hop amounts = %s -> %s, want %s -> %s", i, hop.AmountIn, hop.AmountOut, split.AmountIn, split.AmountOut)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := routerService.GetStrategyQuote(context.Background(), "none", token0, token1, amountIn, 50); err == nil {
+		t.Error("expected no route error when the strategy finds nothing")
+	}
+	if err := routerService.SetDefaultStrategy("exhaustive"); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("SetDefaultStrategy() error = %v, want ErrUnknownStrategy", err)
+	}
+	if got := routerService.Strategies(); len(got) != 3 || got[0] != "direct" || got[1] != "greedy" || got[2] != "none" {
+		t.Errorf("Strategies() = %v", got)
+	}
+}
+
+func TestRouterServiceRoutesBTCWrappersThroughPresetHubs(t *testing.T) {
+	// 100 tBTC (18 decimals) against 100 WBTC (8), and 100 WBTC against
+	// 6.5M USDC (6)
+	curve := NewMockDEXClient(entities.DEXCurve)
+	curve.SetPair(entities.TBTC.Address, entities.WBTC.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   entities.TBTC,
+		Token1:   entities.WBTC,
+		Reserve0: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+		Reserve1: big.NewInt(100e8),
+		DEX:      entities.DEXCurve,
+		Fee:      4,
+	})
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WBTC.Address, entities.USDC.Address, &entities.Pair{
+		Address:  common.HexToAddress("0x2222"),
+		Token0:   entities.WBTC,
+		Token1:   entities.USDC,
+		Reserve0: big.NewInt(100e8),
+		Reserve1: big.NewInt(6_500_000e6),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{curve, v2}, &MockCache{}))
+
+	// No intermediate index is set, so only the preset offers WBTC
+	quote, err := router.GetMultiHopQuote(context.Background(), entities.TBTC, entities.USDC, big.NewInt(1e17), nil)
+	if err != nil {
+		t.Fatalf("GetMultiHopQuote() error = %v", err)
+	}
+	hops := quote.BestRoute.Hops
+	if len(hops) != 2 || hops[0].TokenOut != entities.WBTC.Address {
+		t.Fatalf("route = %+v, want tBTC -> WBTC -> USDC", hops)
+	}
+	// 0.1 tBTC is about 6,500 USDC less fees and impact
+	if quote.AmountOut.Cmp(big.NewInt(6_400e6)) < 0 || quote.AmountOut.Cmp(big.NewInt(6_500e6)) > 0 {
+		t.Errorf("AmountOut = %s, want about 6450 USDC", quote.AmountOut)
+	}
+}
+
+func TestRouterServiceReadsEachPoolOncePerRequest(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000a"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000b"), Symbol: "B", Decimals: 18}
+	tokenX := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000c"), Symbol: "X", Decimals: 18}
+	deep := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	fake := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	pools := [][2]entities.Token{{tokenA, tokenB}, {tokenA, tokenX}, {tokenX, tokenB}}
+	for i, pool := range pools {
+		fake.SetPair(&entities.Pair{
+			Address: common.BigToAddress(big.NewInt(int64(i + 1))), Token0: pool[0], Token1: pool[1],
+			Reserve0: deep, Reserve1: deep, DEX: entities.DEXUniswapV2, Fee: 30,
+		})
+	}
+	router := NewRouterService(NewPriceService([]dex.DEXClient{fake}, &MockCache{}))
+	ctx := WithPairSnapshot(context.Background())
+
+	if _, err := router.GetMultiHopQuote(ctx, tokenA, tokenB, big.NewInt(1e18), []entities.Token{tokenX}); err != nil {
+		t.Fatalf("GetMultiHopQuote() error = %v", err)
+	}
+	// The rest of the request, the reverse trade included, reuses the pools
+	if _, err := router.GetSmartQuote(ctx, tokenA, tokenB, big.NewInt(5e18), 0); err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	if _, err := router.GetMultiHopQuote(ctx, tokenB, tokenA, big.NewInt(1e18), []entities.Token{tokenX}); err != nil {
+		t.Fatalf("GetMultiHopQuote(reverse) error = %v", err)
+	}
+	for _, pool := range pools {
+		if got := fake.Reads(pool[0].Address, pool[1].Address); got != 1 {
+			t.Errorf("%s/%s pool read %d times, want once", pool[0].Symbol, pool[1].Symbol, got)
+		}
+	}
+
+	// Without a snapshot of its own, each quote reads the pools afresh
+	before := fake.Reads(tokenA.Address, tokenB.Address)
+	for i := 0; i < 2; i++ {
+		if _, err := router.GetMultiHopQuote(context.Background(), tokenA, tokenB, big.NewInt(1e18), []entities.Token{tokenX}); err != nil {
+			t.Fatalf("GetMultiHopQuote() error = %v", err)
+		}
+	}
+	if got := fake.Reads(tokenA.Address, tokenB.Address) - before; got != 2 {
+		t.Errorf("A/B pool read %d times by two quotes, want once each", got)
+	}
+}
+
+// countingQuoter counts the on-chain quotes a venue is asked for
+type countingQuoter struct {
+	*MockDEXClient
+	quotes atomic.Int32
+}
+
+func (c *countingQuoter) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	c.quotes.Add(1)
+	return c.MockDEXClient.GetAmountOut(ctx, amountIn, tokenIn, tokenOut)
+}
+
+func TestRouterServiceMultiHopPrunesDominatedFirstHops(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000a"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000b"), Symbol: "B", Decimals: 18}
+	tokenX := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000c"), Symbol: "X", Decimals: 18}
+	tokenY := entities.Token{Address: common.HexToAddress("0x000000000000000000000000000000000000000d"), Symbol: "Y", Decimals: 18}
+	pool := func(addr int64, token0, token1 entities.Token, reserve0, reserve1 int64, dexType entities.DEXType) *entities.Pair {
+		return &entities.Pair{
+			Address: common.BigToAddress(big.NewInt(addr)), Token0: token0, Token1: token1,
+			Reserve0: new(big.Int).Mul(big.NewInt(reserve0), big.NewInt(1e18)),
+			Reserve1: new(big.Int).Mul(big.NewInt(reserve1), big.NewInt(1e18)),
+			DEX:      dexType, Fee: 30,
+		}
+	}
+
+	// Both V2 venues lead A into X and Y, Sushiswap at the better rate;
+	// the on-chain quoted venue takes X and Y on to B, Y paying more
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(tokenA.Address, tokenX.Address, pool(1, tokenA, tokenX, 1_000_000, 1_000_000, entities.DEXUniswapV2))
+	v2.SetPair(tokenA.Address, tokenY.Address, pool(2, tokenA, tokenY, 1_000_000, 1_000_000, entities.DEXUniswapV2))
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(tokenA.Address, tokenX.Address, pool(3, tokenA, tokenX, 1_000_000, 1_100_000, entities.DEXSushiswap))
+	sushi.SetPair(tokenA.Address, tokenY.Address, pool(4, tokenA, tokenY, 1_000_000, 1_100_000, entities.DEXSushiswap))
+	quoter := &countingQuoter{MockDEXClient: NewMockDEXClient(entities.DEXCurve)}
+	quoter.SetCapabilities(dex.Capabilities{NeedsOnchainQuote: true})
+	quoter.SetPair(tokenX.Address, tokenB.Address, pool(5, tokenX, tokenB, 1_000_000, 1_000_000, entities.DEXCurve))
+	quoter.SetPair(tokenY.Address, tokenB.Address, pool(6, tokenY, tokenB, 1_000_000, 1_200_000, entities.DEXCurve))
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2, sushi, quoter}, &MockCache{}))
+
+	quote, err := router.GetMultiHopQuote(context.Background(), tokenA, tokenB, big.NewInt(1e18), []entities.Token{tokenX, tokenY})
+	if err != nil {
+		t.Fatalf("GetMultiHopQuote() error = %v", err)
+	}
+	hops := quote.BestRoute.Hops
+	if len(hops) != 2 || hops[0].Pair.DEX != entities.DEXSushiswap || hops[0].TokenOut != tokenY.Address {
+		t.Fatalf("route = %+v, want A -> Y on sushiswap -> B", hops)
+	}
+	// One second-hop quote per intermediate: Uniswap V2's smaller first
+	// hops are dominated by Sushiswap's
+	if got := quoter.quotes.Load(); got != 2 {
+		t.Errorf("second hops quoted %d times, want 2", got)
+	}
+}
+
+func TestRouterServiceMultiHopCutsBeatenBranches(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	pool := func(addr string, token0, token1 entities.Token, reserve0, reserve1 *big.Int, dexType entities.DEXType, factory common.Address) *entities.Pair {
+		return &entities.Pair{
+			Address: common.HexToAddress(addr), Token0: token0, Token1: token1, Reserve0: reserve0, Reserve1: reserve1,
+			DEX: dexType, Fee: 30, Factory: factory, TVLUSD: ether(4_000_000),
+		}
+	}
+	// WETH trades into USDC directly, and into DAI on both V2 venues. The
+	// only DAI/USDC pool comes from an unlisted factory, so verified quotes
+	// prune it, and it pays less than the direct pool anyway.
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, pool("0x1111", entities.USDC, entities.WETH, big.NewInt(2_000_000e6), ether(1_000), entities.DEXUniswapV2, dex.UniswapV2FactoryAddress))
+	v2.SetPair(entities.WETH.Address, entities.DAI.Address, pool("0x2222", entities.DAI, entities.WETH, ether(1_900_000), ether(1_000), entities.DEXUniswapV2, dex.UniswapV2FactoryAddress))
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(entities.WETH.Address, entities.DAI.Address, pool("0x3333", entities.DAI, entities.WETH, ether(2_000_000), ether(1_000), entities.DEXSushiswap, dex.SushiswapFactoryAddress))
+	quoter := &countingQuoter{MockDEXClient: NewMockDEXClient(entities.DEXCurve)}
+	quoter.SetCapabilities(dex.Capabilities{NeedsOnchainQuote: true})
+	quoter.SetPair(entities.DAI.Address, entities.USDC.Address, pool("0x4444", entities.DAI, entities.USDC, ether(2_000_000), big.NewInt(2_000_000e6), entities.DEXCurve, common.HexToAddress("0xbad")))
+	priceService := NewPriceService([]dex.DEXClient{v2, sushi, quoter}, &MockCache{})
+	priceService.SetPoolVerifier(NewPoolVerifier([]common.Address{dex.UniswapV2FactoryAddress, dex.SushiswapFactoryAddress}, ether(1_000_000), entities.DefaultRegistry()))
+	router := NewRouterService(priceService)
+
+	quote, err := router.GetMultiHopQuote(WithVerifiedPools(context.Background()), entities.WETH, entities.USDC, ether(1), []entities.Token{entities.DAI})
+	if err != nil {
+		t.Fatalf("GetMultiHopQuote() error = %v", err)
+	}
+	if len(quote.BestRoute.Hops) != 1 {
+		t.Fatalf("route over %d hops, want the direct pool", len(quote.BestRoute.Hops))
+	}
+	// The larger first hop reaches no more than the direct pool pays, so
+	// the smaller one isn't taken on to USDC
+	if got := quoter.quotes.Load(); got != 1 {
+		t.Errorf("second hops quoted %d times, want 1", got)
+	}
+
+	// A branch still running is cancelled once a route reaches its bound
+	best := &multiHopBest{}
+	best.raise(big.NewInt(100))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if best.beaten(big.NewInt(150), cancel) || !best.beaten(big.NewInt(99), func() {}) {
+		t.Fatal("beaten() misjudged a bound against the best of 100")
+	}
+	// It could still tie a route paying 150, and ties go to the earlier
+	// intermediate
+	best.raise(big.NewInt(150))
+	if ctx.Err() != nil {
+		t.Fatal("branch bounded at 150 cancelled by a route paying 150")
+	}
+	best.raise(big.NewInt(151))
+	if ctx.Err() == nil {
+		t.Error("branch bounded at 150 still running after a route paying 151")
+	}
+}
+
+type fakeEquivalents map[common.Address][]entities.Token
+
+func (f fakeEquivalents) Equivalents(addr common.Address) []entities.Token {
+	return f[addr]
+}
+
+func TestRouterServiceConvertsThroughEquivalents(t *testing.T) {
+	// Bridged USDC only trades into native USDC, which trades into WETH
+	bridged := entities.Token{Address: common.HexToAddress("0x00000000000000000000000000000000000000ee"), Symbol: "USDC.e", Decimals: 6}
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(bridged.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: bridged, Token1: entities.USDC,
+		Reserve0: big.NewInt(10_000_000e6), Reserve1: big.NewInt(10_000_000e6), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	v2.SetPair(entities.USDC.Address, entities.WETH.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: big.NewInt(20_000_000e6), Reserve1: new(big.Int).Mul(big.NewInt(10_000), big.NewInt(1e18)),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2}, &MockCache{}))
+	amountIn := big.NewInt(2000e6)
+
+	if _, err := router.GetSmartQuote(context.Background(), bridged, entities.WETH, amountIn, 0); apperror.CodeOf(err) != apperror.NoRoute {
+		t.Fatalf("without equivalents error = %v, want no route", err)
+	}
+
+	router.SetTokenEquivalents(fakeEquivalents{bridged.Address: {entities.USDC}})
+	quote, err := router.GetSmartQuote(context.Background(), bridged, entities.WETH, amountIn, 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	if hops := quote.BestRoute.Hops; len(hops) != 2 || hops[0].TokenOut != entities.USDC.Address {
+		t.Fatalf("route = %+v, want USDC.e -> USDC -> WETH", hops)
+	}
+	if quote.ConvertedVia == nil || quote.ConvertedVia.Address != entities.USDC.Address {
+		t.Errorf("ConvertedVia = %v, want USDC", quote.ConvertedVia)
+	}
+	if quote.MinAmountOut == nil || quote.MinAmountOut.Cmp(quote.AmountOut) >= 0 {
+		t.Errorf("MinAmountOut = %v for %s out", quote.MinAmountOut, quote.AmountOut)
+	}
+}
+
+func TestRouterServiceComposesWrapRoutes(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// 1.15 stETH per wstETH
+	rate := new(big.Int).Div(ether(115), big.NewInt(100))
+	lido := NewMockDEXClient(entities.DEXLido)
+	lido.SetPair(entities.STETH.Address, entities.WSTETH.Address, &entities.Pair{
+		Address: entities.WSTETH.Address, Token0: entities.WSTETH, Token1: entities.STETH,
+		Reserve0: ether(1e9), Reserve1: new(big.Int).Div(new(big.Int).Mul(ether(1e9), rate), big.NewInt(1e18)),
+		DEX: entities.DEXLido, Wrap: &entities.WrapRate{Wrapped: entities.WSTETH.Address, Rate: rate},
+	})
+	// stETH trades into USDC through a thin pool, wstETH through a deep one
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.STETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.STETH,
+		Reserve0: big.NewInt(2_000_000e6), Reserve1: ether(1000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	v2.SetPair(entities.WSTETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.USDC, Token1: entities.WSTETH,
+		Reserve0: big.NewInt(230_000_000e6), Reserve1: ether(100_000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2, lido}, &MockCache{}))
+
+	amountIn := ether(100)
+	quote, err := router.GetSmartQuote(context.Background(), entities.STETH, entities.USDC, amountIn, 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	hops := quote.BestRoute.Hops
+	if len(hops) != 2 || hops[0].Pair.DEX != entities.DEXLido || hops[0].TokenOut != entities.WSTETH.Address {
+		t.Fatalf("route = %+v, want stETH -wrap-> wstETH -> USDC", hops)
+	}
+	// The wrap converts at the rate exactly, with no slippage
+	if want := new(big.Int).Div(new(big.Int).Mul(amountIn, big.NewInt(1e18)), rate); hops[0].AmountOut.Cmp(want) != 0 {
+		t.Errorf("wrapped %s wstETH, want %s", hops[0].AmountOut, want)
+	}
+	if quote.MinAmountOut == nil || quote.MinAmountOut.Cmp(quote.AmountOut) >= 0 {
+		t.Errorf("MinAmountOut = %v for %s out", quote.MinAmountOut, quote.AmountOut)
+	}
+
+	// Buying stETH unwraps after the deep pool
+	quote, err = router.GetSmartQuote(context.Background(), entities.USDC, entities.STETH, big.NewInt(100_000e6), 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote(USDC, stETH) error = %v", err)
+	}
+	if hops := quote.BestRoute.Hops; len(hops) != 2 || hops[1].Pair.DEX != entities.DEXLido {
+		t.Errorf("route = %+v, want USDC -> wstETH -unwrap-> stETH", hops)
+	}
+}
+
+// reorgingDEX has a reorg detected during its first read, orphaning the
+// quote that read is part of
+type reorgingDEX struct {
+	*MockDEXClient
+	prices *PriceService
+	once   sync.Once
+}
+
+func (d *reorgingDEX) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	d.once.Do(func() { d.prices.Invalidate(ctx, 1) })
+	return d.MockDEXClient.GetPairByTokens(ctx, tokenA, tokenB)
+}
+
+func TestRouterServiceWrapsAfterReorgRetry(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	rate := new(big.Int).Div(ether(115), big.NewInt(100))
+	lido := NewMockDEXClient(entities.DEXLido)
+	lido.SetPair(entities.STETH.Address, entities.WSTETH.Address, &entities.Pair{
+		Address: entities.WSTETH.Address, Token0: entities.WSTETH, Token1: entities.STETH,
+		Reserve0: ether(1e9), Reserve1: new(big.Int).Div(new(big.Int).Mul(ether(1e9), rate), big.NewInt(1e18)),
+		DEX: entities.DEXLido, Wrap: &entities.WrapRate{Wrapped: entities.WSTETH.Address, Rate: rate}, BlockNumber: 100,
+	})
+	v2 := &reorgingDEX{MockDEXClient: NewMockDEXClient(entities.DEXUniswapV2)}
+	v2.SetPair(entities.STETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.STETH,
+		Reserve0: big.NewInt(2_000_000e6), Reserve1: ether(1000), DEX: entities.DEXUniswapV2, Fee: 30, BlockNumber: 100,
+	})
+	v2.SetPair(entities.WSTETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.USDC, Token1: entities.WSTETH,
+		Reserve0: big.NewInt(230_000_000e6), Reserve1: ether(100_000), DEX: entities.DEXUniswapV2, Fee: 30, BlockNumber: 100,
+	})
+	prices := NewPriceService([]dex.DEXClient{v2, lido}, &MockCache{})
+	v2.prices = prices
+	router := NewRouterService(prices)
+
+	// The first read orphans the quote, and the retry must still be
+	// beaten by the wrap through the deep wstETH pool
+	quote, err := router.GetSmartQuote(context.Background(), entities.STETH, entities.USDC, ether(100), 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	if hops := quote.BestRoute.Hops; len(hops) != 2 || hops[0].Pair.DEX != entities.DEXLido {
+		t.Errorf("route = %+v, want stETH -wrap-> wstETH -> USDC", hops)
+	}
+}
+
+func TestRouterServiceFallbackQuote(t *testing.T) {
+	ctx := context.Background()
+	dai := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// A converter holding 500 USDC fills a 1 DAI probe but not 1,000 DAI
+	psm := NewMockDEXClient(entities.DEXMakerPSM)
+	psm.SetPair(entities.DAI.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x3333"), Token0: entities.DAI, Token1: entities.USDC,
+		Reserve0: dai(1_000_000), Reserve1: big.NewInt(500e6), DEX: entities.DEXMakerPSM,
+		Fixed: &entities.FixedRate{Gem: entities.USDC.Address, To18: big.NewInt(1e12), Tin: big.NewInt(0), Tout: big.NewInt(0)},
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{psm, NewMockDEXClient(entities.DEXUniswapV2)}, &MockCache{}))
+
+	_, cause := router.GetSmartQuote(ctx, entities.DAI, entities.USDC, dai(1_000), 0)
+	if cause == nil {
+		t.Fatal("GetSmartQuote() found a route through an overdrawn converter")
+	}
+	_, err := router.FallbackQuote(ctx, entities.DAI, entities.USDC, dai(1_000), 0, cause)
+	var noRoute *NoRouteError
+	if !errors.As(err, &noRoute) {
+		t.Fatalf("FallbackQuote() error = %v, want a NoRouteError", err)
+	}
+	if apperror.CodeOf(err) != apperror.CodeOf(cause) {
+		t.Errorf("code = %s, want the original %s", apperror.CodeOf(err), apperror.CodeOf(cause))
+	}
+	diagnostic := noRoute.Diagnostic
+	if diagnostic.Cause != entities.NoRouteInsufficientLiquidity || diagnostic.ProbeAmount.Cmp(dai(1)) != 0 {
+		t.Errorf("cause %s with probe %s, want insufficient_liquidity with 1 DAI", diagnostic.Cause, diagnostic.ProbeAmount)
+	}
+	want := map[entities.DEXType]string{entities.DEXMakerPSM: entities.VenueInsufficientLiquidity, entities.DEXUniswapV2: entities.VenueNoPool}
+	for _, venue := range diagnostic.Venues {
+		if venue.Reason != want[venue.DEX] {
+			t.Errorf("%s reason = %s, want %s", venue.DEX, venue.Reason, want[venue.DEX])
+		}
+	}
+	if out := diagnostic.Venues[0].ProbeOut; out == nil || out.Int64() != 1e6 {
+		t.Errorf("%s probe out = %v, want 1 USDC", diagnostic.Venues[0].DEX, out)
+	}
+
+	// tBTC has no USDC pool, but the relaxed retry routes through WBTC
+	curve := NewMockDEXClient(entities.DEXCurve)
+	curve.SetPair(entities.TBTC.Address, entities.WBTC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.TBTC, Token1: entities.WBTC,
+		Reserve0: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)), Reserve1: big.NewInt(100e8), DEX: entities.DEXCurve, Fee: 4,
+	})
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WBTC.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.WBTC, Token1: entities.USDC,
+		Reserve0: big.NewInt(100e8), Reserve1: big.NewInt(6_500_000e6), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router = NewRouterService(NewPriceService([]dex.DEXClient{curve, v2}, &MockCache{}))
+
+	_, cause = router.GetSmartQuote(ctx, entities.TBTC, entities.USDC, big.NewInt(1e17), 0)
+	if apperror.CodeOf(cause) != apperror.NoRoute {
+		t.Fatalf("GetSmartQuote() error = %v, want no route", cause)
+	}
+	quote, err := router.FallbackQuote(ctx, entities.TBTC, entities.USDC, big.NewInt(1e17), 0, cause)
+	if err != nil {
+		t.Fatalf("FallbackQuote() error = %v", err)
+	}
+	if hops := quote.BestRoute.Hops; len(hops) != 2 || hops[0].TokenOut != entities.WBTC.Address {
+		t.Fatalf("route = %+v, want tBTC -> WBTC -> USDC", hops)
+	}
+	if quote.Fallback == nil || quote.Fallback.Cause != entities.NoRouteNoPools || len(quote.Fallback.Relaxed) != 1 {
+		t.Errorf("Fallback = %+v, want no_pools relaxed to multi_hop", quote.Fallback)
+	}
+	if quote.MinAmountOut == nil || quote.MinAmountOut.Cmp(quote.AmountOut) >= 0 {
+		t.Errorf("MinAmountOut = %v for %s out", quote.MinAmountOut, quote.AmountOut)
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"math/big"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// quoteSavings compares amountOut with each venue's single-pool output.
+// prices are the routable venues from filterValidPrices, best first.
+func quoteSavings(amountOut *big.Int, prices []PriceResult) *entities.Savings {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	best := prices[0].AmountOut
+	worst := prices[len(prices)-1].AmountOut
+	median := prices[len(prices)/2].AmountOut
+	if len(prices)%2 == 0 {
+		median = new(big.Int).Add(prices[len(prices)/2-1].AmountOut, median)
+		median.Rsh(median, 1)
+	}
+
+	savings := &entities.Savings{
+		VsWorstBps:  deltaBps(amountOut, worst),
+		VsMedianBps: deltaBps(amountOut, median),
+	}
+	if amountOut.Cmp(best) > 0 {
+		vsBest := deltaBps(amountOut, best)
+		savings.VsBestVenueBps = &vsBest
+	}
+	return savings
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestQuoteSavings(t *testing.T) {
+	outputs := func(amounts ...int64) []PriceResult {
+		prices := make([]PriceResult, len(amounts))
+		for i, amount := range amounts {
+			prices[i] = PriceResult{AmountOut: big.NewInt(amount)}
+		}
+		return prices
+	}
+
+	tests := []struct {
+		name       string
+		amountOut  int64
+		prices     []PriceResult
+		wantWorst  int64
+		wantMedian int64
+		wantBest   int64 // 0 means VsBestVenueBps is unset
+	}{
+		{"single venue", 10000, outputs(10000), 0, 0, 0},
+		{"best venue wins", 10000, outputs(10000, 9900, 9800), 204, 101, 0},
+		{"even count median", 10000, outputs(10000, 9950, 9850, 9000), 1111, 101, 0},
+		{"split beats best venue", 10034, outputs(10000, 9000), 1148, 562, 34},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			savings := quoteSavings(big.NewInt(tt.amountOut), tt.prices)
+			if savings.VsWorstBps != tt.wantWorst || savings.VsMedianBps != tt.wantMedian {
+				t.Errorf("vsWorst = %d, vsMedian = %d, want %d, %d", savings.VsWorstBps, savings.VsMedianBps, tt.wantWorst, tt.wantMedian)
+			}
+			switch {
+			case tt.wantBest == 0 && savings.VsBestVenueBps != nil:
+				t.Errorf("VsBestVenueBps = %d, want unset", *savings.VsBestVenueBps)
+			case tt.wantBest != 0 && (savings.VsBestVenueBps == nil || *savings.VsBestVenueBps != tt.wantBest):
+				t.Errorf("VsBestVenueBps = %v, want %d", savings.VsBestVenueBps, tt.wantBest)
+			}
+		})
+	}
+
+	if quoteSavings(big.NewInt(1), nil) != nil {
+		t.Error("quoteSavings() with no venues should be nil")
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// shadowTimeout bounds one shadow run, so a slow strategy can't pile up work
+const shadowTimeout = 5 * time.Second
+
+// ShadowStats tallies one strategy's shadow runs against the served quotes.
+// Deltas are the shadow output's difference from the served output in bps,
+// positive when the shadow strategy would have paid more.
+type ShadowStats struct {
+	Runs          uint64  `json:"runs"`
+	Errors        uint64  `json:"errors"` // The strategy failed or found no route
+	Better        uint64  `json:"better"` // Shadow output above the served output
+	Worse         uint64  `json:"worse"`  // Shadow output below the served output
+	Even          uint64  `json:"even"`   // Same output
+	MeanDeltaBps  float64 `json:"meanDeltaBps"`
+	MaxGainBps    float64 `json:"maxGainBps"`
+	MaxLossBps    float64 `json:"maxLossBps"`
+	MeanLatencyMs float64 `json:"meanLatencyMs"`
+
+	sumDeltaBps float64
+	sumLatency  time.Duration
+}
+
+// shadowJob is a served quote and the strategy that served it
+type shadowJob struct {
+	quote    *entities.Quote
+	strategy string
+}
+
+// ShadowEvaluator re-routes a sample of served quotes with other strategies
+// in the background and tallies how their output compares, so a strategy
+// can be judged on live traffic before it serves anyone. Shadow runs read
+// the same cached pools as the served quote, never ask market makers and
+// never reach the response. Quotes a market maker won are skipped, as the
+// served AMM output isn't known.
+type ShadowEvaluator struct {
+	router     *RouterService
+	strategies []string
+	sampleRate float64
+	queue      chan shadowJob
+
+	mu    sync.Mutex
+	stats map[string]*ShadowStats
+
+	dropped atomic.Uint64
+}
+
+// NewShadowEvaluator shadows sampleRate of served quotes, 0 to 1, with each
+// of strategies, buffering up to queueSize quotes
+func NewShadowEvaluator(router *RouterService, strategies []string, sampleRate float64, queueSize int) *ShadowEvaluator {
+	stats := make(map[string]*ShadowStats, len(strategies))
+	for _, name := range strategies {
+		stats[name] = &ShadowStats{}
+	}
+	return &ShadowEvaluator{
+		router:     router,
+		strategies: strategies,
+		sampleRate: sampleRate,
+		queue:      make(chan shadowJob, queueSize),
+		stats:      stats,
+	}
+}
+
+// Observe queues a served quote for shadowing when it falls in the sample.
+// It never blocks; quotes arriving while the queue is full are dropped.
+func (e *ShadowEvaluator) Observe(quote *entities.Quote) {
+	if quote.RFQOrder != nil || rand.Float64() >= e.sampleRate {
+		return
+	}
+	select {
+	case e.queue <- shadowJob{quote: quote, strategy: quote.Strategy}:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// Run shadows queued quotes with workers goroutines until ctx is cancelled
+func (e *ShadowEvaluator) Run(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-e.queue:
+					e.shadow(ctx, job)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// shadow runs every other strategy on the job's trade
+func (e *ShadowEvaluator) shadow(ctx context.Context, job shadowJob) {
+	served := job.quote
+	for _, name := range e.strategies {
+		if name == job.strategy {
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, shadowTimeout)
+		start := time.Now()
+		quote, err := e.router.ShadowQuote(runCtx, name, served.TokenIn, served.TokenOut, served.AmountIn)
+		latency := time.Since(start)
+		cancel()
+
+		if err != nil || quote == nil {
+			e.record(name, latency, nil)
+			continue
+		}
+		delta := shadowDeltaBps(quote.AmountOut, served.AmountOut)
+		e.record(name, latency, &delta)
+		if delta != 0 {
+			log.Printf("Shadow %s %s %s->%s: %s against %s from %s (%+.1f bps)",
+				name, served.AmountIn, served.TokenIn.Symbol, served.TokenOut.Symbol,
+				quote.AmountOut, served.AmountOut, job.strategy, delta)
+		}
+	}
+}
+
+// record tallies one run; delta is nil when the strategy found no route
+func (e *ShadowEvaluator) record(strategy string, latency time.Duration, delta *float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s := e.stats[strategy]
+	s.Runs++
+	s.sumLatency += latency
+	s.MeanLatencyMs = float64(s.sumLatency.Milliseconds()) / float64(s.Runs)
+	if delta == nil {
+		s.Errors++
+		return
+	}
+
+	switch {
+	case *delta > 0:
+		s.Better++
+		s.MaxGainBps = math.Max(s.MaxGainBps, *delta)
+	case *delta < 0:
+		s.Worse++
+		s.MaxLossBps = math.Max(s.MaxLossBps, -*delta)
+	default:
+		s.Even++
+	}
+	s.sumDeltaBps += *delta
+	s.MeanDeltaBps = s.sumDeltaBps / float64(s.Runs-s.Errors)
+}
+
+// Stats returns each shadowed strategy's tally
+func (e *ShadowEvaluator) Stats() map[string]ShadowStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	stats := make(map[string]ShadowStats, len(e.stats))
+	for name, s := range e.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// Dropped returns how many sampled quotes were discarded because the queue
+// was full
+func (e *ShadowEvaluator) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// shadowDeltaBps is how far shadow is above served in bps of served, kept
+// fractional since strategies often differ by less than a bps
+func shadowDeltaBps(shadow, served *big.Int) float64 {
+	if served == nil || served.Sign() <= 0 {
+		return 0
+	}
+	diff := new(big.Float).SetInt(new(big.Int).Sub(shadow, served))
+	ratio, _ := diff.Quo(diff, new(big.Float).SetInt(served)).Float64()
+	return ratio * 10000
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestShadowEvaluator(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+	reserve := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	// Two equal pools: greedy splits across them, direct takes one
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: tokenA, Token1: tokenB,
+		Reserve0: reserve(1000), Reserve1: reserve(1000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(tokenA.Address, tokenB.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: tokenA, Token1: tokenB,
+		Reserve0: reserve(1000), Reserve1: reserve(1000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2, sushi}, &MockCache{}))
+
+	served, err := router.GetStrategyQuote(context.Background(), "greedy", tokenA, tokenB, reserve(40), 0)
+	if err != nil {
+		t.Fatalf("GetStrategyQuote() error = %v", err)
+	}
+	if len(served.SplitRoutes) != 2 {
+		t.Fatalf("greedy served %d splits, want 2", len(served.SplitRoutes))
+	}
+
+	shadow := NewShadowEvaluator(router, []string{"greedy", "direct"}, 1, 1)
+	shadow.Observe(served)
+	shadow.Observe(served) // The queue holds one
+	shadow.Observe(&entities.Quote{RFQOrder: &entities.RFQOrder{}})
+	if shadow.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", shadow.Dropped())
+	}
+	shadow.shadow(context.Background(), <-shadow.queue)
+
+	stats := shadow.Stats()
+	if greedy := stats["greedy"]; greedy.Runs != 0 {
+		t.Errorf("greedy shadowed itself: %+v", greedy)
+	}
+	direct := stats["direct"]
+	if direct.Runs != 1 || direct.Worse != 1 || direct.MeanDeltaBps >= 0 || direct.MaxLossBps != -direct.MeanDeltaBps {
+		t.Errorf("direct = %+v, want one run below the split", direct)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Default slippage by pair class in basis points. A flat default fails
+// stable swaps on tiny moves and leaves exotic swaps open to sandwiching.
+var slippageByPairClass = map[entities.PairClass]uint64{
+	entities.PairClassStable:   10,
+	entities.PairClassMajor:    50,
+	entities.PairClassLongTail: 100,
+	entities.PairClassExotic:   300,
+}
+
+// DefaultSlippage picks the slippage for a swap the caller gave none for
+func DefaultSlippage(tokenIn, tokenOut entities.Token) entities.SlippageDefault {
+	class := entities.ClassifyPair(tokenIn, tokenOut)
+
+	var reason string
+	switch class {
+	case entities.PairClassStable:
+		reason = fmt.Sprintf("%s and %s are both USD stablecoins", displaySymbol(tokenIn), displaySymbol(tokenOut))
+	case entities.PairClassMajor:
+		reason = fmt.Sprintf("%s and %s are both deep-liquidity majors", displaySymbol(tokenIn), displaySymbol(tokenOut))
+	case entities.PairClassLongTail:
+		longTail := tokenIn
+		if entities.IsMajorToken(tokenIn) {
+			longTail = tokenOut
+		}
+		reason = fmt.Sprintf("%s is a long-tail token", displaySymbol(longTail))
+	default:
+		reason = fmt.Sprintf("neither %s nor %s is a major token", displaySymbol(tokenIn), displaySymbol(tokenOut))
+	}
+
+	return entities.SlippageDefault{
+		Class:  class,
+		Bps:    slippageByPairClass[class],
+		Reason: reason,
+	}
+}
+
+// displaySymbol names a token for messages, falling back to its address
+func displaySymbol(token entities.Token) string {
+	if token.Symbol != "" {
+		return token.Symbol
+	}
+	return token.Address.Hex()
+}
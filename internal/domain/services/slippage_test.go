@@ -0,0 +1,43 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestDefaultSlippage(t *testing.T) {
+	pepe := entities.Token{Address: common.HexToAddress("0x6982508145454Ce325dDbE47a25d4ec3d2311933"), Symbol: "PEPE", Decimals: 18}
+	shib := entities.Token{Address: common.HexToAddress("0x95aD61b0a150d79219dCF64E1E6Cc01f0B64C4cE"), Symbol: "SHIB", Decimals: 18}
+
+	tests := []struct {
+		name       string
+		tokenIn    entities.Token
+		tokenOut   entities.Token
+		wantClass  entities.PairClass
+		wantBps    uint64
+		wantReason string
+	}{
+		{"stable-stable", entities.USDC, entities.DAI, entities.PairClassStable, 10, "USD stablecoins"},
+		{"major-stable", entities.WETH, entities.USDT, entities.PairClassMajor, 50, "majors"},
+		{"major-major", entities.STETH, entities.WETH, entities.PairClassMajor, 50, "majors"},
+		{"long-tail out", entities.WETH, pepe, entities.PairClassLongTail, 100, "PEPE is a long-tail"},
+		{"long-tail in", pepe, entities.USDC, entities.PairClassLongTail, 100, "PEPE is a long-tail"},
+		{"exotic", pepe, shib, entities.PairClassExotic, 300, "neither PEPE nor SHIB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultSlippage(tt.tokenIn, tt.tokenOut)
+			if got.Class != tt.wantClass || got.Bps != tt.wantBps {
+				t.Errorf("DefaultSlippage() = %s/%d, want %s/%d", got.Class, got.Bps, tt.wantClass, tt.wantBps)
+			}
+			if !strings.Contains(got.Reason, tt.wantReason) {
+				t.Errorf("Reason = %q, want it to mention %q", got.Reason, tt.wantReason)
+			}
+		})
+	}
+}
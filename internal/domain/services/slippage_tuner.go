@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var (
+	// slot0()
+	slot0Selector = common.Hex2Bytes("3850c7bd")
+	// liquidity()
+	liquiditySelector = common.Hex2Bytes("1a686502")
+)
+
+const (
+	// DefaultSlippageAutoBlocks is how many past blocks slippage=auto replays
+	DefaultSlippageAutoBlocks = 20
+	// DefaultSlippageAutoFill is the share of block-over-block moves the
+	// tuned slippage must absorb
+	DefaultSlippageAutoFill = 0.95
+)
+
+// StateReader reads contract state as of a past block
+type StateReader interface {
+	CallContractAt(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// errNoHistory marks a pool whose past state can't be read or replayed
+var errNoHistory = errors.New("no readable history")
+
+// SlippageTuner picks the slippage for slippage=auto. It replays the
+// quote's routes against each of the last blocks, and picks the smallest
+// slippage that absorbs the chosen share of the output's block-over-block
+// drops. Pools it can't read history for, such as Curve and Balancer, are
+// held at their current state.
+type SlippageTuner struct {
+	reader          StateReader
+	blocks          int
+	fillProbability float64
+}
+
+func NewSlippageTuner(reader StateReader, blocks int, fillProbability float64) *SlippageTuner {
+	return &SlippageTuner{reader: reader, blocks: blocks, fillProbability: fillProbability}
+}
+
+// Tune sets the quote's slippage from its route's recent output, keeping
+// the pair-class default when there's too little history to measure
+func (t *SlippageTuner) Tune(ctx context.Context, quote *entities.Quote) {
+	if quote.RFQOrder != nil {
+		quote.SlippageAuto = &entities.SlippageAuto{
+			Bps:             quote.SlippageBps,
+			FillProbability: 1,
+			Reason:          "a market maker's firm quote doesn't move with the pools",
+		}
+		return
+	}
+
+	auto, err := t.measure(ctx, quote)
+	if err != nil {
+		auto = &entities.SlippageAuto{Bps: quote.SlippageBps, Reason: "kept the pair-class default: " + err.Error()}
+		if quote.SlippageDefault != nil {
+			auto.Bps = quote.SlippageDefault.Bps
+		}
+	}
+	quote.SlippageAuto = auto
+	applySlippageProtection(quote, auto.Bps)
+}
+
+// routeShare is a route and the part of the order it carries
+type routeShare struct {
+	route    *entities.Route
+	amountIn *big.Int
+}
+
+func (t *SlippageTuner) measure(ctx context.Context, quote *entities.Quote) (*entities.SlippageAuto, error) {
+	var shares []routeShare
+	for _, split := range quote.SplitRoutes {
+		shares = append(shares, routeShare{split.Route, split.AmountIn})
+	}
+	if len(shares) == 0 && quote.BestRoute != nil {
+		shares = append(shares, routeShare{quote.BestRoute, quote.AmountIn})
+	}
+
+	// Each pool is read once per block, even when several routes share it
+	pools := make(map[common.Address]entities.Pair)
+	var held []string
+	for _, share := range shares {
+		for _, hop := range share.route.Hops {
+			if _, ok := pools[hop.Pair.Address]; ok {
+				continue
+			}
+			pools[hop.Pair.Address] = hop.Pair
+			if !replayable(&hop.Pair) {
+				held = append(held, string(hop.Pair.DEX))
+			}
+		}
+	}
+	if len(pools) == 0 || len(held) == len(pools) {
+		return nil, fmt.Errorf("no pool on the route has readable history")
+	}
+
+	head, err := t.reader.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("head block: %w", err)
+	}
+	if head < uint64(t.blocks) {
+		return nil, fmt.Errorf("chain is shorter than %d blocks", t.blocks)
+	}
+
+	// outputs[i] is the order's output at block head-blocks+i, nil when a
+	// pool couldn't be read there
+	outputs := make([]*big.Int, t.blocks+1)
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs[i] = t.outputAt(ctx, shares, pools, head-uint64(t.blocks-i))
+		}(i)
+	}
+	wg.Wait()
+
+	var changes []float64
+	for i := 1; i < len(outputs); i++ {
+		prev, cur := outputs[i-1], outputs[i]
+		if prev == nil || cur == nil || prev.Sign() <= 0 {
+			continue
+		}
+		delta := new(big.Float).SetInt(new(big.Int).Sub(cur, prev))
+		ratio, _ := delta.Quo(delta, new(big.Float).SetInt(prev)).Float64()
+		changes = append(changes, ratio*10000)
+	}
+	if len(changes) < t.blocks/2 || len(changes) == 0 {
+		return nil, fmt.Errorf("only %d of the last %d blocks could be replayed", len(changes), t.blocks)
+	}
+
+	bps, filled, worst := pickSlippage(changes, t.fillProbability)
+	reason := fmt.Sprintf("absorbs %.0f%% of %d block-over-block output changes over the last %d blocks; the largest drop was %.1f bps",
+		filled*100, len(changes), t.blocks, worst)
+	if len(held) > 0 {
+		reason += fmt.Sprintf("; %s pools held at their current state", strings.Join(held, ", "))
+	}
+	return &entities.SlippageAuto{
+		Bps:             bps,
+		Samples:         len(changes),
+		FillProbability: filled,
+		VolatilityBps:   stddev(changes),
+		Reason:          reason,
+	}, nil
+}
+
+// pickSlippage returns the smallest whole bps covering fillProbability of
+// the drops, the share it actually covers and the largest drop. It is at
+// least 1 bps, so rounding between quote and execution can't fail the swap.
+func pickSlippage(changes []float64, fillProbability float64) (uint64, float64, float64) {
+	drops := make([]float64, len(changes))
+	for i, change := range changes {
+		drops[i] = math.Max(0, -change)
+	}
+	sort.Float64s(drops)
+
+	k := int(math.Ceil(fillProbability*float64(len(drops)))) - 1
+	k = max(0, min(k, len(drops)-1))
+	bps := uint64(math.Min(10000, math.Max(1, math.Ceil(drops[k]))))
+
+	covered := sort.SearchFloat64s(drops, math.Nextafter(float64(bps), math.Inf(1)))
+	return bps, float64(covered) / float64(len(drops)), drops[len(drops)-1]
+}
+
+func stddev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
+
+// outputAt replays the routes at block, nil when a pool can't be read there
+func (t *SlippageTuner) outputAt(ctx context.Context, shares []routeShare, pools map[common.Address]entities.Pair, block uint64) *big.Int {
+	past := make(map[common.Address]*entities.Pair, len(pools))
+	for addr, pair := range pools {
+		pair := pair
+		if !replayable(&pair) {
+			past[addr] = &pair
+			continue
+		}
+		replayed, err := t.pairAt(ctx, pair, new(big.Int).SetUint64(block))
+		if err != nil {
+			return nil
+		}
+		past[addr] = replayed
+	}
+
+	total := new(big.Int)
+	for _, share := range shares {
+		amount := share.amountIn
+		for _, hop := range share.route.Hops {
+			amount = past[hop.Pair.Address].GetAmountOut(amount, hop.TokenIn)
+		}
+		total.Add(total, amount)
+	}
+	return total
+}
+
+// replayable reports whether a pool's state at a past block can be read:
+// V2-style reserves, or a V3 price that stays within the ticks already read
+func replayable(pair *entities.Pair) bool {
+	if pair.Stable != nil {
+		return false
+	}
+	if pair.Concentrated != nil {
+		return pair.DEX == entities.DEXUniswapV3
+	}
+	return pair.DEX == entities.DEXUniswapV2 || pair.DEX == entities.DEXSushiswap
+}
+
+// pairAt returns pair with its state as of block
+func (t *SlippageTuner) pairAt(ctx context.Context, pair entities.Pair, block *big.Int) (*entities.Pair, error) {
+	if pair.Concentrated == nil {
+		words, err := t.call(ctx, pair.Address, v2PairContract.PackGetReserves(), block, 2)
+		if err != nil {
+			return nil, err
+		}
+		pair.Reserve0, pair.Reserve1 = words[0], words[1]
+		return &pair, nil
+	}
+
+	slot0, err := t.call(ctx, pair.Address, slot0Selector, block, 2)
+	if err != nil {
+		return nil, err
+	}
+	liquidity, err := t.call(ctx, pair.Address, liquiditySelector, block, 1)
+	if err != nil {
+		return nil, err
+	}
+	state := *pair.Concentrated
+	state.SqrtPriceX96, state.Liquidity = slot0[0], liquidity[0]
+	// tick is an int24, sign-extended to the whole word
+	tick := slot0[1]
+	if tick.Bit(255) == 1 {
+		tick = new(big.Int).Sub(tick, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	if !tick.IsInt64() || tick.Int64() < int64(state.TickLow) || tick.Int64() > int64(state.TickHigh) {
+		return nil, fmt.Errorf("%w: tick %s is outside the ticks read", errNoHistory, tick)
+	}
+	state.Tick = int32(tick.Int64())
+	pair.Concentrated = &state
+	pair.Reserve0, pair.Reserve1 = state.VirtualReserves()
+	return &pair, nil
+}
+
+// call runs a getter at block and returns its first words
+func (t *SlippageTuner) call(ctx context.Context, addr common.Address, selector []byte, block *big.Int, words int) ([]*big.Int, error) {
+	result, err := t.reader.CallContractAt(ctx, ethereum.CallMsg{To: &addr, Data: selector}, block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoHistory, err)
+	}
+	if len(result) < 32*words {
+		return nil, fmt.Errorf("%w: call returned %d bytes", errNoHistory, len(result))
+	}
+	values := make([]*big.Int, words)
+	for i := range values {
+		values[i] = new(big.Int).SetBytes(result[32*i : 32*(i+1)])
+	}
+	return values, nil
+}
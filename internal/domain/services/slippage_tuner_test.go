@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// mockHistory answers getReserves from a per-block history of reserve1,
+// with reserve0 fixed
+type mockHistory struct {
+	head     uint64
+	reserve0 *big.Int
+	reserve1 map[uint64]*big.Int
+}
+
+func (m *mockHistory) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *mockHistory) CallContractAt(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	reserve1, ok := m.reserve1[block.Uint64()]
+	if !ok || !bytes.Equal(msg.Data, v2PairContract.PackGetReserves()) {
+		return nil, errors.New("missing trie node")
+	}
+	data := common.LeftPadBytes(m.reserve0.Bytes(), 32)
+	data = append(data, common.LeftPadBytes(reserve1.Bytes(), 32)...)
+	return append(data, make([]byte, 32)...), nil
+}
+
+func TestSlippageTunerTune(t *testing.T) {
+	tokenA := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "A", Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "B", Decimals: 18}
+	base := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	scaled := func(bps int64) *big.Int {
+		r := new(big.Int).Mul(base, big.NewInt(100000-bps))
+		return r.Div(r, big.NewInt(100000))
+	}
+
+	// Over 20 changes the output drops once by 9.5 bps and once by 30 bps
+	history := &mockHistory{head: 1000, reserve0: base, reserve1: make(map[uint64]*big.Int)}
+	for block := uint64(980); block <= 1000; block++ {
+		history.reserve1[block] = base
+	}
+	history.reserve1[985] = scaled(95)
+	history.reserve1[992] = scaled(300)
+
+	newQuote := func(dex entities.DEXType) *entities.Quote {
+		pair := entities.Pair{Address: common.HexToAddress("0x1111"), Token0: tokenA, Token1: tokenB, Reserve0: base, Reserve1: base, DEX: dex, Fee: 30}
+		amountIn := big.NewInt(1e18)
+		route := &entities.Route{TokenIn: tokenA, TokenOut: tokenB, AmountIn: amountIn,
+			Hops: []entities.Hop{{Pair: pair, TokenIn: tokenA.Address, TokenOut: tokenB.Address}}}
+		route.AmountOut = pair.GetAmountOut(amountIn, tokenA.Address)
+		quote := &entities.Quote{TokenIn: tokenA, TokenOut: tokenB, AmountIn: amountIn, AmountOut: route.AmountOut, BestRoute: route,
+			SlippageDefault: &entities.SlippageDefault{Class: entities.PairClassExotic, Bps: 300}}
+		applySlippageProtection(quote, 300)
+		return quote
+	}
+
+	quote := newQuote(entities.DEXUniswapV2)
+	NewSlippageTuner(history, 20, 0.95).Tune(context.Background(), quote)
+	auto := quote.SlippageAuto
+	if auto == nil || auto.Bps != 10 || auto.Samples != 20 || auto.FillProbability != 0.95 {
+		t.Fatalf("SlippageAuto = %+v, want 10 bps over 20 samples filling 95%%", auto)
+	}
+	if quote.SlippageBps != 10 {
+		t.Errorf("SlippageBps = %d, want the tuned 10", quote.SlippageBps)
+	}
+	if !strings.Contains(auto.Reason, "largest drop was 30.0 bps") {
+		t.Errorf("Reason = %q", auto.Reason)
+	}
+
+	// Every change must be absorbed: the 30 bps drop sets the slippage
+	quote = newQuote(entities.DEXUniswapV2)
+	NewSlippageTuner(history, 20, 1).Tune(context.Background(), quote)
+	if quote.SlippageAuto.Bps != 30 || quote.SlippageAuto.FillProbability != 1 {
+		t.Errorf("SlippageAuto = %+v, want 30 bps filling every change", quote.SlippageAuto)
+	}
+
+	// History the node no longer holds keeps the pair-class default
+	quote = newQuote(entities.DEXUniswapV2)
+	NewSlippageTuner(history, 100, 0.95).Tune(context.Background(), quote)
+	if quote.SlippageAuto.Bps != 300 || quote.SlippageAuto.Samples != 0 || quote.SlippageBps != 300 {
+		t.Errorf("SlippageAuto = %+v, want the 300 bps default", quote.SlippageAuto)
+	}
+
+	// Curve pools have no readable history
+	quote = newQuote(entities.DEXCurve)
+	NewSlippageTuner(history, 20, 0.95).Tune(context.Background(), quote)
+	if quote.SlippageAuto.Samples != 0 || !strings.Contains(quote.SlippageAuto.Reason, "no pool on the route") {
+		t.Errorf("SlippageAuto = %+v, want the default kept", quote.SlippageAuto)
+	}
+}
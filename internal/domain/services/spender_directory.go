@@ -0,0 +1,42 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// SpenderDirectory lists the contracts this deployment's transactions and
+// signed orders spend users' tokens through, so frontends can look up what
+// to approve rather than hardcode router addresses
+type SpenderDirectory struct {
+	chainID  uint64
+	spenders []entities.Spender
+}
+
+func NewSpenderDirectory(chainID uint64) *SpenderDirectory {
+	return &SpenderDirectory{chainID: chainID}
+}
+
+// Add lists a spender
+func (d *SpenderDirectory) Add(spender entities.Spender) {
+	d.spenders = append(d.spenders, spender)
+}
+
+// ChainID is the chain every listed spender is deployed on
+func (d *SpenderDirectory) ChainID() uint64 {
+	return d.chainID
+}
+
+// List returns the spenders in the order they were added. Given a venue,
+// it keeps the spenders that run that venue's swaps and those that aren't
+// tied to one.
+func (d *SpenderDirectory) List(dex entities.DEXType) []entities.Spender {
+	spenders := make([]entities.Spender, 0, len(d.spenders))
+	for _, spender := range d.spenders {
+		if dex == "" || len(spender.DEXes) == 0 || slices.Contains(spender.DEXes, dex) {
+			spenders = append(spenders, spender)
+		}
+	}
+	return spenders
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestSpenderDirectoryList(t *testing.T) {
+	directory := NewSpenderDirectory(1)
+	directory.Add(entities.Spender{Name: "v2", Address: common.HexToAddress("0x01"), DEXes: []entities.DEXType{entities.DEXUniswapV2}})
+	directory.Add(entities.Spender{Name: "v3", Address: common.HexToAddress("0x02"), DEXes: []entities.DEXType{entities.DEXUniswapV3}})
+	directory.Add(entities.Spender{Name: "executor", Address: common.HexToAddress("0x03"), DEXes: []entities.DEXType{entities.DEXUniswapV2, entities.DEXUniswapV3}})
+	directory.Add(entities.Spender{Name: "fee collector", Address: common.HexToAddress("0x04")})
+
+	tests := []struct {
+		dex  entities.DEXType
+		want []string
+	}{
+		{"", []string{"v2", "v3", "executor", "fee collector"}},
+		{entities.DEXUniswapV3, []string{"v3", "executor", "fee collector"}},
+		{entities.DEXCurve, []string{"fee collector"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.dex), func(t *testing.T) {
+			var got []string
+			for _, spender := range directory.List(tt.dex) {
+				got = append(got, spender.Name)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List(%q) = %v, want %v", tt.dex, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("List(%q) = %v, want %v", tt.dex, got, tt.want)
+				}
+			}
+		})
+	}
+}
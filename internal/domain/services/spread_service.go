@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+type spreadKey struct {
+	tokenA, tokenB common.Address
+}
+
+// SpreadService compares every venue's bid and ask for a pair. Spreads are
+// computed once per block and shared by requests until the head moves.
+type SpreadService struct {
+	priceService *PriceService
+	head         HeadProvider
+
+	mu      sync.Mutex
+	spreads map[spreadKey]*entities.Spread
+}
+
+func NewSpreadService(priceService *PriceService, head HeadProvider) *SpreadService {
+	return &SpreadService{
+		priceService: priceService,
+		head:         head,
+		spreads:      make(map[spreadKey]*entities.Spread),
+	}
+}
+
+// GetSpread returns the spread of tokenA in tokenB at the current head.
+// Without a head every call reads the venues again.
+func (s *SpreadService) GetSpread(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Spread, error) {
+	var block uint64
+	if s.head != nil {
+		block, _ = s.head.BlockNumber(ctx)
+	}
+	key := spreadKey{tokenA.Address, tokenB.Address}
+	if block != 0 {
+		s.mu.Lock()
+		cached, ok := s.spreads[key]
+		s.mu.Unlock()
+		if ok && cached.Block == block {
+			return cached, nil
+		}
+	}
+
+	spread, err := s.compute(ctx, tokenA, tokenB)
+	if err != nil {
+		return nil, err
+	}
+	spread.Block = block
+
+	if block != 0 {
+		s.mu.Lock()
+		for k, old := range s.spreads {
+			if old.Block < block {
+				delete(s.spreads, k)
+			}
+		}
+		s.spreads[key] = spread
+		s.mu.Unlock()
+	}
+	return spread, nil
+}
+
+// compute sells one whole tokenA on every venue for the bids, then buys
+// back the best bid's worth of tokenB for the asks, so both sides trade the
+// same size
+func (s *SpreadService) compute(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Spread, error) {
+	one := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(tokenA.Decimals)), nil)
+	sells, err := s.priceService.GetPrices(ctx, tokenA, tokenB, one)
+	if err != nil {
+		return nil, err
+	}
+	var bestBid *big.Int
+	for _, sell := range sells {
+		if quoted(sell) && (bestBid == nil || sell.AmountOut.Cmp(bestBid) > 0) {
+			bestBid = sell.AmountOut
+		}
+	}
+	if bestBid == nil {
+		return nil, fmt.Errorf("no venue quotes %s/%s", tokenA.Symbol, tokenB.Symbol)
+	}
+
+	buys, err := s.priceService.GetPrices(ctx, tokenB, tokenA, bestBid)
+	if err != nil {
+		return nil, err
+	}
+	buyByDEX := make(map[entities.DEXType]PriceResult, len(buys))
+	for _, buy := range buys {
+		if quoted(buy) {
+			buyByDEX[buy.DEX] = buy
+		}
+	}
+
+	spread := &entities.Spread{TokenA: tokenA, TokenB: tokenB, UpdatedAt: time.Now().Unix()}
+	for _, sell := range sells {
+		buy, ok := buyByDEX[sell.DEX]
+		if !quoted(sell) || !ok {
+			continue
+		}
+		// tokenB paid per whole tokenA received, rounded against the buyer
+		ask := new(big.Int).Mul(bestBid, one)
+		ask.Add(ask, new(big.Int).Sub(buy.AmountOut, big.NewInt(1)))
+		ask.Div(ask, buy.AmountOut)
+
+		venue := entities.VenuePrice{DEX: sell.DEX, Pool: sell.Pair.Address, Bid: sell.AmountOut, Ask: ask}
+		spread.Venues = append(spread.Venues, venue)
+		if spread.Bid == nil || venue.Bid.Cmp(spread.Bid) > 0 {
+			spread.Bid, spread.BidDEX = venue.Bid, venue.DEX
+		}
+		if spread.Ask == nil || venue.Ask.Cmp(spread.Ask) < 0 {
+			spread.Ask, spread.AskDEX = venue.Ask, venue.DEX
+		}
+	}
+	if len(spread.Venues) == 0 {
+		return nil, fmt.Errorf("no venue quotes both sides of %s/%s", tokenA.Symbol, tokenB.Symbol)
+	}
+
+	mid := new(big.Int).Add(spread.Bid, spread.Ask)
+	mid.Rsh(mid, 1)
+	spread.SpreadBps = bpsOf(new(big.Int).Sub(spread.Ask, spread.Bid), mid).Int64()
+
+	low, high := spread.Venues[0].Mid(), spread.Venues[0].Mid()
+	for _, venue := range spread.Venues[1:] {
+		if m := venue.Mid(); m.Cmp(low) < 0 {
+			low = m
+		} else if m.Cmp(high) > 0 {
+			high = m
+		}
+	}
+	spread.DivergenceBps = bpsOf(new(big.Int).Sub(high, low), low).Uint64()
+	return spread, nil
+}
+
+// quoted reports whether a venue answered with a usable amount
+func quoted(result PriceResult) bool {
+	return result.Error == nil && result.Pair != nil && result.AmountOut != nil && result.AmountOut.Sign() > 0
+}
+
+// bpsOf is part / whole in basis points, zero when whole is
+func bpsOf(part, whole *big.Int) *big.Int {
+	if whole.Sign() == 0 {
+		return new(big.Int)
+	}
+	bps := new(big.Int).Mul(part, big.NewInt(10000))
+	return bps.Quo(bps, whole)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// spreadTestPair holds wethReserve WETH against usdcPerEth times as many
+// dollars of USDC
+func spreadTestPair(addr string, dexType entities.DEXType, wethReserve, usdcPerEth int64) *entities.Pair {
+	weth := new(big.Int).Mul(big.NewInt(wethReserve), big.NewInt(1e18))
+	return &entities.Pair{
+		Address:  common.HexToAddress(addr),
+		Token0:   entities.USDC,
+		Token1:   entities.WETH,
+		Reserve0: new(big.Int).Mul(big.NewInt(wethReserve*usdcPerEth), big.NewInt(1e6)),
+		Reserve1: weth,
+		DEX:      dexType,
+		Fee:      30,
+	}
+}
+
+func TestSpreadServiceGetSpread(t *testing.T) {
+	uni := NewMockDEXClient(entities.DEXUniswapV2)
+	uni.SetPair(entities.WETH.Address, entities.USDC.Address, spreadTestPair("0x1", entities.DEXUniswapV2, 10000, 2000))
+	sushi := NewMockDEXClient(entities.DEXSushiswap)
+	sushi.SetPair(entities.WETH.Address, entities.USDC.Address, spreadTestPair("0x2", entities.DEXSushiswap, 1000, 2100))
+	missing := NewMockDEXClient(entities.DEXUniswapV3)
+
+	head := &mockLogSource{head: 100}
+	service := NewSpreadService(NewPriceService([]dex.DEXClient{uni, sushi, missing}, nil), head)
+	ctx := context.Background()
+
+	spread, err := service.GetSpread(ctx, entities.WETH, entities.USDC)
+	if err != nil {
+		t.Fatalf("GetSpread() error = %v", err)
+	}
+	if len(spread.Venues) != 2 {
+		t.Fatalf("venues = %+v, want the two pools", spread.Venues)
+	}
+	for _, venue := range spread.Venues {
+		if venue.Bid.Cmp(venue.Ask) >= 0 {
+			t.Errorf("%s bids %s at or above its ask %s", venue.DEX, venue.Bid, venue.Ask)
+		}
+	}
+	// Sushiswap prices ETH 5% higher, so its bid beats Uniswap's ask
+	if spread.BidDEX != entities.DEXSushiswap || spread.AskDEX != entities.DEXUniswapV2 {
+		t.Errorf("best bid on %s, best ask on %s", spread.BidDEX, spread.AskDEX)
+	}
+	if spread.SpreadBps >= 0 {
+		t.Errorf("spreadBps = %d, want crossed venues to be negative", spread.SpreadBps)
+	}
+	if spread.DivergenceBps < 450 || spread.DivergenceBps > 550 {
+		t.Errorf("divergenceBps = %d, want about 500", spread.DivergenceBps)
+	}
+	if spread.Block != 100 {
+		t.Errorf("block = %d, want 100", spread.Block)
+	}
+
+	// The same block serves the same spread
+	sushi.SetPair(entities.WETH.Address, entities.USDC.Address, spreadTestPair("0x2", entities.DEXSushiswap, 1000, 2000))
+	if again, _ := service.GetSpread(ctx, entities.WETH, entities.USDC); again != spread {
+		t.Error("spread recomputed within a block")
+	}
+	head.head = 101
+	next, err := service.GetSpread(ctx, entities.WETH, entities.USDC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Block != 101 || next.DivergenceBps > 10 {
+		t.Errorf("next block: block %d, divergence %d bps, want the venues back in line", next.Block, next.DivergenceBps)
+	}
+}
+
+func TestSpreadServiceNoVenues(t *testing.T) {
+	service := NewSpreadService(NewPriceService([]dex.DEXClient{NewMockDEXClient(entities.DEXUniswapV2)}, nil), nil)
+	if _, err := service.GetSpread(context.Background(), entities.WETH, entities.USDC); err == nil {
+		t.Error("GetSpread() without a pool succeeded")
+	}
+}
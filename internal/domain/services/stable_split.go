@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// stableHubs are the stablecoins a stable-to-stable trade may pass through
+var stableHubs = []entities.Token{entities.DAI, entities.USDC, entities.USDT}
+
+// stableSplitParts is how many equal parts splitAcross hands out
+const stableSplitParts = 20
+
+// maxStableSplitLegs caps the paths one trade is split over, since each
+// adds a swap's gas
+const maxStableSplitLegs = 3
+
+// stableCandidates widens the greedy finder's choice for stable-to-stable
+// trades. The pair's pools and the best path through each other stablecoin
+// are split in one pass, and each path is also a candidate on its own.
+func (f *greedyRouteFinder) stableCandidates(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int, prices []PriceResult) [][]*entities.Route {
+	legs := make([][]entities.Hop, 0, len(prices)+len(stableHubs))
+	for _, p := range prices {
+		legs = append(legs, []entities.Hop{{Pair: *p.Pair, TokenIn: tokenIn.Address, TokenOut: tokenOut.Address}})
+	}
+
+	var candidates [][]*entities.Route
+	for _, hub := range stableHubs {
+		if hub.Address == tokenIn.Address || hub.Address == tokenOut.Address {
+			continue
+		}
+		if leg := f.bestPathThrough(ctx, tokenIn, hub, tokenOut, amountIn); leg != nil {
+			legs = append(legs, leg)
+			candidates = append(candidates, []*entities.Route{legRoute(tokenIn, tokenOut, amountIn, leg)})
+		}
+	}
+	if split := splitAcross(tokenIn, tokenOut, amountIn, legs); split != nil {
+		candidates = append(candidates, split)
+	}
+	return candidates
+}
+
+// bestPathThrough returns the hops of the best pools from tokenIn to hub
+// and on to tokenOut, or nil when either side has none
+func (f *greedyRouteFinder) bestPathThrough(ctx context.Context, tokenIn, hub, tokenOut entities.Token, amountIn *big.Int) []entities.Hop {
+	hop1Prices, err := f.priceService.GetPrices(ctx, tokenIn, hub, amountIn)
+	if err != nil {
+		return nil
+	}
+	hop1 := filterValidPrices(hop1Prices)
+	if len(hop1) == 0 {
+		return nil
+	}
+	hop2Prices, err := f.priceService.GetPrices(ctx, hub, tokenOut, hop1[0].AmountOut)
+	if err != nil {
+		return nil
+	}
+	hop2 := filterValidPrices(hop2Prices)
+	if len(hop2) == 0 {
+		return nil
+	}
+	if hop1[0].Pair.Address == hop2[0].Pair.Address {
+		return nil // In and out of one pool, which the direct leg already prices
+	}
+	return []entities.Hop{
+		{Pair: *hop1[0].Pair, TokenIn: tokenIn.Address, TokenOut: hub.Address},
+		{Pair: *hop2[0].Pair, TokenIn: hub.Address, TokenOut: tokenOut.Address},
+	}
+}
+
+// splitAcross hands amountIn out to legs a part at a time, each part going
+// to the leg it adds the most output on, over at most maxStableSplitLegs
+// legs. Pools pay less per unit the more they take, so this comes within a
+// part of the best split. Each leg is priced against its pools alone, so a
+// leg sharing a pool with one already taken is left out: Curve's 3pool,
+// say, is both a direct leg and the first hop through the third
+// stablecoin. It returns nil when one leg takes everything.
+func splitAcross(tokenIn, tokenOut entities.Token, amountIn *big.Int, legs [][]entities.Hop) []*entities.Route {
+	if len(legs) < 2 {
+		return nil
+	}
+	part := new(big.Int).Div(amountIn, big.NewInt(stableSplitParts))
+	if part.Sign() == 0 {
+		return nil
+	}
+
+	allocated := make([]*big.Int, len(legs))
+	outputs := make([]*big.Int, len(legs))
+	for i := range legs {
+		allocated[i], outputs[i] = big.NewInt(0), big.NewInt(0)
+	}
+	used := 0
+	for n := 0; n < stableSplitParts; n++ {
+		amount := part
+		if n == stableSplitParts-1 {
+			// The last part takes what division left over
+			amount = new(big.Int).Sub(amountIn, new(big.Int).Mul(part, big.NewInt(stableSplitParts-1)))
+		}
+
+		best, bestGain, bestOutput := -1, (*big.Int)(nil), (*big.Int)(nil)
+		for i, leg := range legs {
+			if allocated[i].Sign() == 0 && (used == maxStableSplitLegs || overlapsAllocated(legs, allocated, i)) {
+				continue
+			}
+			output := hopsAmountOut(leg, new(big.Int).Add(allocated[i], amount))
+			gain := new(big.Int).Sub(output, outputs[i])
+			if best < 0 || gain.Cmp(bestGain) > 0 {
+				best, bestGain, bestOutput = i, gain, output
+			}
+		}
+		if allocated[best].Sign() == 0 {
+			used++
+		}
+		allocated[best].Add(allocated[best], amount)
+		outputs[best] = bestOutput
+	}
+	if used < 2 {
+		return nil
+	}
+
+	routes := make([]*entities.Route, 0, used)
+	for i, leg := range legs {
+		if allocated[i].Sign() > 0 {
+			routes = append(routes, legRoute(tokenIn, tokenOut, allocated[i], leg))
+		}
+	}
+	return routes
+}
+
+// overlapsAllocated reports whether legs[i] passes through a pool of a leg
+// already given a part
+func overlapsAllocated(legs [][]entities.Hop, allocated []*big.Int, i int) bool {
+	for j, leg := range legs {
+		if j == i || allocated[j].Sign() == 0 {
+			continue
+		}
+		for _, hop := range leg {
+			for _, other := range legs[i] {
+				if hop.Pair.Address == other.Pair.Address {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// legRoute fills amountIn through hops
+func legRoute(tokenIn, tokenOut entities.Token, amountIn *big.Int, hops []entities.Hop) *entities.Route {
+	route := &entities.Route{
+		Hops:     append([]entities.Hop(nil), hops...),
+		TokenIn:  tokenIn,
+		TokenOut: tokenOut,
+		AmountIn: amountIn,
+	}
+	route.AmountOut = route.CalculateAmountOut()
+	route.GasEstimate = estimateGas(route)
+	route.FillHopAmounts()
+	return route
+}
+
+// hopsAmountOut is what hops pay out for amountIn, zero when any of them
+// can't fill it
+func hopsAmountOut(hops []entities.Hop, amountIn *big.Int) *big.Int {
+	amount := amountIn
+	for _, hop := range hops {
+		amount = hop.Pair.GetAmountOut(amount, hop.TokenIn)
+		if amount.Sign() <= 0 {
+			return big.NewInt(0)
+		}
+	}
+	return amount
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestRouterServiceSplitsStablesThroughHubs(t *testing.T) {
+	units := func(n int64, decimals int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil))
+	}
+	pair := func(addr string, a, b entities.Token, reserve int64, dexType entities.DEXType, fee uint64) *entities.Pair {
+		return &entities.Pair{
+			Address: common.HexToAddress(addr), Token0: a, Token1: b,
+			Reserve0: units(reserve, int64(a.Decimals)), Reserve1: units(reserve, int64(b.Decimals)),
+			DEX: dexType, Fee: fee,
+		}
+	}
+
+	curve := NewMockDEXClient(entities.DEXCurve)
+	curve.SetPair(entities.USDC.Address, entities.USDT.Address, pair("0xc1", entities.USDC, entities.USDT, 1000000, entities.DEXCurve, 4))
+	v3 := NewMockDEXClient(entities.DEXUniswapV3)
+	v3.SetPair(entities.USDC.Address, entities.USDT.Address, pair("0xa1", entities.USDC, entities.USDT, 1000000, entities.DEXUniswapV3, 1))
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.USDC.Address, entities.DAI.Address, pair("0xd1", entities.USDC, entities.DAI, 2000000, entities.DEXUniswapV2, 5))
+	v2.SetPair(entities.DAI.Address, entities.USDT.Address, pair("0xd2", entities.DAI, entities.USDT, 2000000, entities.DEXUniswapV2, 5))
+
+	amountIn := units(300000, 6)
+	quote := func(clients ...dex.DEXClient) *entities.Quote {
+		t.Helper()
+		routerService := NewRouterService(NewPriceService(clients, &MockCache{}))
+		quote, err := routerService.GetSmartQuote(context.Background(), entities.USDC, entities.USDT, amountIn, 5)
+		if err != nil {
+			t.Fatalf("GetSmartQuote() error = %v", err)
+		}
+		return quote
+	}
+
+	direct := quote(curve, v3)
+	mixed := quote(curve, v3, v2)
+	if mixed.AmountOut.Cmp(direct.AmountOut) <= 0 {
+		t.Errorf("split through DAI pays %s, no more than the direct pools' %s", mixed.AmountOut, direct.AmountOut)
+	}
+
+	total := big.NewInt(0)
+	var viaDAI, venues int
+	for _, split := range mixed.SplitRoutes {
+		total.Add(total, split.AmountIn)
+		if len(split.Route.Hops) == 2 && split.Route.Hops[0].TokenOut == entities.DAI.Address {
+			viaDAI++
+		} else {
+			venues++
+		}
+	}
+	if viaDAI != 1 || venues != 2 {
+		t.Fatalf("split has %d direct and %d via-DAI routes, want 2 and 1", venues, viaDAI)
+	}
+	if total.Cmp(amountIn) != 0 {
+		t.Errorf("splits total %s, want %s", total, amountIn)
+	}
+
+	// Pairs that aren't both stablecoins aren't routed through the hubs
+	weth := NewMockDEXClient(entities.DEXSushiswap)
+	weth.SetPair(entities.WETH.Address, entities.USDT.Address, pair("0xe1", entities.WETH, entities.USDT, 1000000, entities.DEXSushiswap, 30))
+	weth.SetPair(entities.WETH.Address, entities.DAI.Address, pair("0xe2", entities.WETH, entities.DAI, 1000000, entities.DEXSushiswap, 30))
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{weth, v2}, &MockCache{}))
+	wethQuote, err := routerService.GetSmartQuote(context.Background(), entities.WETH, entities.USDT, units(1000, 18), 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wethQuote.BestRoute.Hops) != 1 || len(wethQuote.SplitRoutes) != 0 {
+		t.Errorf("WETH quote took %d hops over %d splits, want the direct pool", len(wethQuote.BestRoute.Hops), len(wethQuote.SplitRoutes))
+	}
+}
+
+func TestSplitAcrossCapsLegs(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x01"), Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x02"), Decimals: 18}
+	var legs [][]entities.Hop
+	for i := 0; i < 5; i++ {
+		legs = append(legs, []entities.Hop{{
+			Pair: entities.Pair{
+				Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+				Token0:  token0, Token1: token1,
+				Reserve0: big.NewInt(1e18), Reserve1: big.NewInt(1e18), DEX: entities.DEXUniswapV2,
+			},
+			TokenIn: token0.Address, TokenOut: token1.Address,
+		}})
+	}
+
+	routes := splitAcross(token0, token1, big.NewInt(1e18), legs)
+	if len(routes) != maxStableSplitLegs {
+		t.Fatalf("split over %d legs, want %d", len(routes), maxStableSplitLegs)
+	}
+	total := big.NewInt(0)
+	for _, route := range routes {
+		total.Add(total, route.AmountIn)
+	}
+	if total.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("splits total %s, want 1e18", total)
+	}
+
+	if routes := splitAcross(token0, token1, big.NewInt(1e18), legs[:1]); routes != nil {
+		t.Errorf("one leg was split %d ways", len(routes))
+	}
+}
+
+func TestSplitAcrossSkipsSharedPools(t *testing.T) {
+	pool := func(addr string, a, b entities.Token, reserve int64) entities.Pair {
+		return entities.Pair{
+			Address: common.HexToAddress(addr), Token0: a, Token1: b,
+			Reserve0: big.NewInt(reserve), Reserve1: big.NewInt(reserve), DEX: entities.DEXUniswapV2,
+		}
+	}
+	// A 3pool-like pool quoted as several pairs; its hub path looks deepest
+	usdcDAI := pool("0xc1", entities.USDC, entities.DAI, 2e12)
+	daiUSDT := pool("0xc1", entities.DAI, entities.USDT, 2e12)
+	usdcUSDT := pool("0xc1", entities.USDC, entities.USDT, 1e12)
+	other := pool("0xa1", entities.USDC, entities.USDT, 1e12)
+
+	legs := [][]entities.Hop{
+		{{Pair: usdcUSDT, TokenIn: entities.USDC.Address, TokenOut: entities.USDT.Address}},
+		{
+			{Pair: usdcDAI, TokenIn: entities.USDC.Address, TokenOut: entities.DAI.Address},
+			{Pair: daiUSDT, TokenIn: entities.DAI.Address, TokenOut: entities.USDT.Address},
+		},
+		{{Pair: other, TokenIn: entities.USDC.Address, TokenOut: entities.USDT.Address}},
+	}
+
+	routes := splitAcross(entities.USDC, entities.USDT, big.NewInt(1e12), legs)
+	if len(routes) != 2 {
+		t.Fatalf("split over %d legs, want one through the shared pool and the other pool", len(routes))
+	}
+	pools := make(map[common.Address]int)
+	for i, route := range routes {
+		for _, hop := range route.Hops {
+			if j, ok := pools[hop.Pair.Address]; ok && j != i {
+				t.Errorf("routes %d and %d both swap through %s", j, i, hop.Pair.Address.Hex())
+			}
+			pools[hop.Pair.Address] = i
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Gas estimate sources reported on quotes
+const (
+	GasSourceSimulated  = "simulated"
+	GasSourceCalibrated = "calibrated"
+)
+
+// SwapBuilder encodes executable transactions for routes and flash swaps
+type SwapBuilder interface {
+	Build(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time) (*entities.SwapTransaction, error)
+	BuildNative(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time, nativeIn, nativeOut bool) (*entities.SwapTransaction, error)
+	BuildWithFee(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time, collector common.Address, fee *entities.IntegratorFee) (*entities.SwapTransaction, error)
+	BuildFlashSwap(cycle *entities.ArbitrageCycle, receiver common.Address, minProfit *big.Int) (*entities.FlashSwap, error)
+}
+
+// ExecutionBuilder encodes a whole quote, every split and hop, as one call
+// to the aggregator's executor contract
+type ExecutionBuilder interface {
+	BuildExecution(quote *entities.Quote, recipient common.Address) (*entities.SwapTransaction, error)
+}
+
+// UniversalBuilder encodes a whole quote as one call to a router that
+// pulls the input through Permit2, such as Uniswap's Universal Router
+type UniversalBuilder interface {
+	ExecutionBuilder
+	BuildExecutionWithPermit(quote *entities.Quote, recipient common.Address, permit *entities.Permit2Permit) (*entities.SwapTransaction, error)
+	Address() common.Address
+	Permit2() common.Address
+}
+
+// RouteProcessorBuilder encodes a whole quote as a route for Sushi's
+// RouteProcessor
+type RouteProcessorBuilder interface {
+	BuildRoute(quote *entities.Quote, recipient common.Address) (*entities.ProcessorRoute, error)
+}
+
+// GasEstimator runs eth_estimateGas against the node
+type GasEstimator interface {
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// SwapService turns quotes into transactions and prices their gas
+type SwapService struct {
+	builder      SwapBuilder
+	estimator    GasEstimator
+	feeCollector *common.Address
+	approvals    *ApprovalService
+	executor     ExecutionBuilder
+	universal    UniversalBuilder
+	processor    RouteProcessorBuilder
+}
+
+func NewSwapService(builder SwapBuilder, estimator GasEstimator) *SwapService {
+	return &SwapService{
+		builder:   builder,
+		estimator: estimator,
+	}
+}
+
+// SetFeeCollector enables integrator fees, taken by the fee collector
+// contract at collector
+func (s *SwapService) SetFeeCollector(collector common.Address) {
+	s.feeCollector = &collector
+}
+
+// FeeCollector returns the fee collector, if integrator fees are enabled
+func (s *SwapService) FeeCollector() (common.Address, bool) {
+	if s.feeCollector == nil {
+		return common.Address{}, false
+	}
+	return *s.feeCollector, true
+}
+
+// SetApprovalService plans the approvals each built swap needs and warns
+// when the sender or recipient is frozen by the tokens they move
+func (s *SwapService) SetApprovalService(approvals *ApprovalService) {
+	s.approvals = approvals
+}
+
+// SetExecutor builds split and mixed-venue quotes as one transaction
+// through the aggregator's executor contract
+func (s *SwapService) SetExecutor(executor ExecutionBuilder) {
+	s.executor = executor
+}
+
+// SetUniversalRouter builds every quote the router can take, split,
+// mixed-venue or in the gas token, as one call to it, so the sender
+// approves Permit2 once for every venue
+func (s *SwapService) SetUniversalRouter(universal UniversalBuilder) {
+	s.universal = universal
+}
+
+// SetRouteProcessor lets quotes carry their route encoded for Sushi's
+// RouteProcessor
+func (s *SwapService) SetRouteProcessor(processor RouteProcessorBuilder) {
+	s.processor = processor
+}
+
+// AttachRouteProcessor encodes the quote for Sushi's RouteProcessor, sent
+// by sender and paying recipient. The route is for integrators running it
+// through their own processRoute call, so no gas or approval is planned.
+func (s *SwapService) AttachRouteProcessor(quote *entities.Quote, sender, recipient common.Address) error {
+	if s.processor == nil {
+		return fmt.Errorf("RouteProcessor routes are not enabled")
+	}
+	if quote.IntegratorFee != nil {
+		return fmt.Errorf("the RouteProcessor can't take an integrator fee")
+	}
+	route, err := s.processor.BuildRoute(quote, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to build RouteProcessor route: %w", err)
+	}
+	route.Transaction.From = sender
+	quote.RouteProcessor = route
+	return nil
+}
+
+// AttachTransaction builds the swap for a quote, sent by sender and paying
+// recipient, valid until the quote expires. Quotes with an integrator fee
+// are routed through the fee collector, and split or mixed-venue quotes
+// through the executor when one is set. The calibrated gas estimate is
+// replaced with eth_estimateGas when the simulation succeeds. Split quotes
+// without the executor keep their calibrated estimate since they need one
+// swap per leg. Quotes paying or paid in the gas token go straight to the
+// router, which wraps and unwraps it. A Universal Router, when set, takes
+// every fee-free quote it can encode ahead of all of these.
+func (s *SwapService) AttachTransaction(ctx context.Context, quote *entities.Quote, sender, recipient common.Address) error {
+	quote.GasSource = GasSourceCalibrated
+
+	if s.universal != nil && quote.IntegratorFee == nil {
+		// Quotes touching other venues fall through to the other builders
+		if tx, err := s.universal.BuildExecution(quote, recipient); err == nil {
+			permit2 := s.universal.Permit2()
+			s.attach(ctx, quote, tx, sender, recipient, &permit2)
+			return nil
+		}
+	}
+
+	viaExecutor := s.executor != nil && quote.IntegratorFee == nil &&
+		(len(quote.SplitRoutes) > 0 || mixedVenues(quote.BestRoute))
+	if len(quote.SplitRoutes) > 0 && !viaExecutor {
+		return nil
+	}
+
+	native := quote.NativeIn || quote.NativeOut
+	if native && (viaExecutor || quote.IntegratorFee != nil) {
+		return fmt.Errorf("gas token swaps are only built through the router")
+	}
+	// Through the fee collector, the collector is the caller and recipient
+	if !viaExecutor && quote.IntegratorFee == nil && actsForCaller(quote.BestRoute) && sender != recipient {
+		return fmt.Errorf("%s swaps pay their sender, so the recipient must be the sender", quote.BestRoute.Hops[0].Pair.DEX)
+	}
+
+	var tx *entities.SwapTransaction
+	var err error
+	switch {
+	case native:
+		tx, err = s.builder.BuildNative(quote.BestRoute, quote.MinAmountOut, recipient, quote.ExpiresAt, quote.NativeIn, quote.NativeOut)
+	case viaExecutor:
+		tx, err = s.executor.BuildExecution(quote, recipient)
+	case quote.IntegratorFee != nil:
+		if s.feeCollector == nil {
+			return fmt.Errorf("integrator fees are not enabled")
+		}
+		tx, err = s.builder.BuildWithFee(quote.BestRoute, quote.MinAmountOut, recipient, quote.ExpiresAt, *s.feeCollector, quote.IntegratorFee)
+	default:
+		tx, err = s.builder.Build(quote.BestRoute, quote.MinAmountOut, recipient, quote.ExpiresAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build swap: %w", err)
+	}
+	s.attach(ctx, quote, tx, sender, recipient, nil)
+	return nil
+}
+
+// AttachPermittedTransaction is AttachTransaction through the Universal
+// Router with the sender's signed Permit2 permit run ahead of the swap, in
+// place of Permit2's approval transaction. The permit's token, amount and
+// spender are taken from the quote and the router.
+func (s *SwapService) AttachPermittedTransaction(ctx context.Context, quote *entities.Quote, sender, recipient common.Address, permit *entities.Permit2Permit) error {
+	if s.universal == nil || quote.IntegratorFee != nil {
+		return fmt.Errorf("permits are only sent with fee-free Universal Router swaps")
+	}
+	quote.GasSource = GasSourceCalibrated
+
+	permit.Token, permit.Amount, permit.Spender = quote.TokenIn.Address, quote.AmountIn, s.universal.Address()
+	tx, err := s.universal.BuildExecutionWithPermit(quote, recipient, permit)
+	if err != nil {
+		return fmt.Errorf("failed to build swap: %w", err)
+	}
+	permit2 := s.universal.Permit2()
+	s.attach(ctx, quote, tx, sender, recipient, &permit2)
+	if plan := quote.Approval; plan != nil && plan.Permit != nil {
+		plan.Steps = plan.Steps[:len(plan.Steps)-1]
+		plan.Permit = permit
+	}
+	return nil
+}
+
+// attach sets the quote's transaction with its approvals and simulated gas.
+// permit2 is set when the transaction pulls the input through Permit2.
+func (s *SwapService) attach(ctx context.Context, quote *entities.Quote, tx *entities.SwapTransaction, sender, recipient common.Address, permit2 *common.Address) {
+	tx.From = sender
+	quote.Transaction = tx
+
+	if s.approvals != nil {
+		s.attachApproval(ctx, quote, sender, recipient, permit2)
+	}
+
+	gas, err := s.estimator.EstimateGas(ctx, ethereum.CallMsg{
+		From:  tx.From,
+		To:    &tx.To,
+		Data:  tx.Data,
+		Value: tx.Value,
+	})
+	if err != nil {
+		// Typically a missing allowance or balance; the calibrated number stands
+		return
+	}
+
+	tx.Gas = gas
+	quote.GasEstimate = gas
+	quote.GasSource = GasSourceSimulated
+}
+
+// attachApproval plans the sender's approval of the transaction's target
+// and flags frozen addresses. Approval planning is best-effort; the swap
+// is still returned when the node can't answer. Gas token legs need no
+// approval and can't be frozen. With permit2 the token is approved to
+// Permit2, which allows the transaction's target until the quote expires.
+func (s *SwapService) attachApproval(ctx context.Context, quote *entities.Quote, sender, recipient common.Address, permit2 *common.Address) {
+	tx := quote.Transaction
+	if !quote.NativeIn {
+		var plan *entities.ApprovalPlan
+		var err error
+		if permit2 != nil {
+			expiration := quote.ExpiresAt
+			if expiration.IsZero() {
+				expiration = time.Now().Add(DefaultQuoteDeadline)
+			}
+			plan, err = s.approvals.PlanPermit2(ctx, quote.TokenIn.Address, sender, *permit2, tx.To, quote.AmountIn, expiration)
+		} else {
+			plan, err = s.approvals.Plan(ctx, quote.TokenIn.Address, sender, tx.To, quote.AmountIn)
+		}
+		if err == nil {
+			quote.Approval = plan
+			if plan.Frozen {
+				quote.TokenWarnings = append(quote.TokenWarnings, entities.TokenWarning{
+					Token:   quote.TokenIn.Address,
+					Code:    WarningAddressFrozen,
+					Message: fmt.Sprintf("%s has frozen sender %s", quote.TokenIn.Symbol, sender.Hex()),
+				})
+			}
+		}
+	}
+
+	if !quote.NativeOut && s.approvals.Frozen(ctx, quote.TokenOut.Address, recipient) {
+		quote.TokenWarnings = append(quote.TokenWarnings, entities.TokenWarning{
+			Token:   quote.TokenOut.Address,
+			Code:    WarningAddressFrozen,
+			Message: fmt.Sprintf("%s has frozen recipient %s", quote.TokenOut.Symbol, recipient.Hex()),
+		})
+	}
+}
+
+// mixedVenues reports whether a route's hops need more than one router
+func mixedVenues(route *entities.Route) bool {
+	if route == nil || len(route.Hops) == 0 {
+		return false
+	}
+	for _, hop := range route.Hops[1:] {
+		if hop.Pair.DEX != route.Hops[0].Pair.DEX {
+			return true
+		}
+	}
+	return false
+}
+
+// actsForCaller reports whether a route's swap call moves its caller's
+// funds both ways, as calling a Curve pool or the Balancer Vault does
+func actsForCaller(route *entities.Route) bool {
+	if route == nil || len(route.Hops) == 0 {
+		return false
+	}
+	dex := route.Hops[0].Pair.DEX
+	return dex == entities.DEXCurve || dex == entities.DEXBalancer
+}
+
+// BuildFlashSwap encodes a flash swap for an arbitrage cycle. Gas is filled
+// in when the call simulates from the receiver, which needs the receiver's
+// callback deployed and the cycle still profitable.
+func (s *SwapService) BuildFlashSwap(ctx context.Context, cycle *entities.ArbitrageCycle, receiver common.Address, minProfit *big.Int) (*entities.FlashSwap, error) {
+	flash, err := s.builder.BuildFlashSwap(cycle, receiver, minProfit)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := flash.Transaction
+	gas, err := s.estimator.EstimateGas(ctx, ethereum.CallMsg{
+		From:  tx.From,
+		To:    &tx.To,
+		Data:  tx.Data,
+		Value: tx.Value,
+	})
+	if err == nil {
+		tx.Gas = gas
+	}
+	return flash, nil
+}
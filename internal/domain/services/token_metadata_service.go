@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var (
+	// decimals()
+	decimalsSelector = common.Hex2Bytes("313ce567")
+	// symbol()
+	symbolSelector = common.Hex2Bytes("95d89b41")
+	// name()
+	nameSelector = common.Hex2Bytes("06fdde03")
+)
+
+// TokenMetadataService reads ERC-20 metadata for tokens missing from the
+// token list
+type TokenMetadataService struct {
+	caller ContractCaller
+}
+
+func NewTokenMetadataService(caller ContractCaller) *TokenMetadataService {
+	return &TokenMetadataService{caller: caller}
+}
+
+// ReadToken reads the token's decimals, which it must have, and its symbol
+// and name, which fall back to UNKNOWN and empty
+func (s *TokenMetadataService) ReadToken(ctx context.Context, addr common.Address) (entities.Token, error) {
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: decimalsSelector})
+	if err != nil {
+		return entities.Token{}, fmt.Errorf("decimals() failed: %w", err)
+	}
+	if len(result) < 32 {
+		return entities.Token{}, fmt.Errorf("decimals() returned %d bytes", len(result))
+	}
+	decimals := new(big.Int).SetBytes(result[0:32])
+	if !decimals.IsUint64() || decimals.Uint64() > 77 {
+		// 10^78 overflows uint256, so no real token has more
+		return entities.Token{}, fmt.Errorf("decimals() returned %s", decimals)
+	}
+
+	token := entities.UnknownToken(addr)
+	token.Decimals = uint8(decimals.Uint64())
+	if symbol := s.readString(ctx, addr, symbolSelector); symbol != "" {
+		token.Symbol = symbol
+	}
+	token.Name = s.readString(ctx, addr, nameSelector)
+	return token, nil
+}
+
+// readString calls a string getter, empty when it fails
+func (s *TokenMetadataService) readString(ctx context.Context, addr common.Address, selector []byte) string {
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: selector})
+	if err != nil {
+		return ""
+	}
+	return decodeTokenString(result)
+}
+
+// decodeTokenString decodes an ABI string, or the bytes32 some early tokens
+// such as MKR return instead
+func decodeTokenString(data []byte) string {
+	if len(data) == 32 {
+		return string(bytes.TrimRight(data, "\x00"))
+	}
+	if len(data) < 64 {
+		return ""
+	}
+	offset := new(big.Int).SetBytes(data[0:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return ""
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(data[start-32 : start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(data))-start {
+		return ""
+	}
+	return string(data[start : start+length.Uint64()])
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// mockMetadata answers decimals, and symbol as an ABI string or, like MKR,
+// as bytes32
+type mockMetadata struct {
+	decimals      int64
+	symbol        string
+	symbolBytes32 bool
+}
+
+func (m *mockMetadata) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	switch selector := msg.Data[:4]; {
+	case bytes.Equal(selector, decimalsSelector) && m.decimals >= 0:
+		return common.LeftPadBytes(big.NewInt(m.decimals).Bytes(), 32), nil
+	case bytes.Equal(selector, symbolSelector) && m.symbolBytes32:
+		return common.RightPadBytes([]byte(m.symbol), 32), nil
+	case bytes.Equal(selector, symbolSelector):
+		data := common.LeftPadBytes(big.NewInt(32).Bytes(), 32)
+		data = append(data, common.LeftPadBytes(big.NewInt(int64(len(m.symbol))).Bytes(), 32)...)
+		return append(data, common.RightPadBytes([]byte(m.symbol), 32)...), nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestTokenMetadataServiceReadToken(t *testing.T) {
+	addr := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	tests := []struct {
+		name         string
+		mock         *mockMetadata
+		wantSymbol   string
+		wantDecimals uint8
+		wantErr      bool
+	}{
+		{"8 decimals", &mockMetadata{decimals: 8, symbol: "renBTC"}, "renBTC", 8, false},
+		{"bytes32 symbol", &mockMetadata{decimals: 18, symbol: "MKR", symbolBytes32: true}, "MKR", 18, false},
+		{"no symbol", &mockMetadata{decimals: 6}, "UNKNOWN", 6, false},
+		{"no decimals", &mockMetadata{decimals: -1, symbol: "X"}, "", 0, true},
+		{"absurd decimals", &mockMetadata{decimals: 300, symbol: "X"}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := NewTokenMetadataService(tt.mock).ReadToken(context.Background(), addr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ReadToken() = %+v, want an error", token)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadToken() error = %v", err)
+			}
+			if token.Address != addr || token.Symbol != tt.wantSymbol || token.Decimals != tt.wantDecimals {
+				t.Errorf("ReadToken() = %+v, want %s with %d decimals", token, tt.wantSymbol, tt.wantDecimals)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Maximum round-trip loss (buy then sell) in basis points before a token is
+// flagged. This is the token's buy and sell taxes; pool fees and price
+// impact are not counted.
+const MaxRoundTripLossBps = 1000
+
+// HoneypotSellTaxBps is the sell tax from which a token that can be sold
+// back is still treated as one that can't
+const HoneypotSellTaxBps = 5000
+
+// Token warning codes
+const (
+	WarningHoneypotSuspected = "honeypot_suspected"
+	WarningHighRoundTripLoss = "high_round_trip_loss"
+	WarningTransferReverted  = "transfer_reverted"
+	WarningAddressFrozen     = "address_frozen"
+)
+
+// ErrTokenBlocked is returned when a quote involves a blocklisted token
+type ErrTokenBlocked struct {
+	Token  common.Address
+	Reason string
+}
+
+func (e *ErrTokenBlocked) Error() string {
+	return fmt.Sprintf("token %s is blocked: %s", e.Token.Hex(), e.Reason)
+}
+
+// ContractCaller is the subset of the Ethereum client needed for transfer simulation
+type ContractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+}
+
+// BlocklistEntry represents a blocklisted token from JSON config
+type BlocklistEntry struct {
+	Address string `json:"address"`
+	Reason  string `json:"reason"`
+}
+
+type BlocklistConfig struct {
+	Tokens []BlocklistEntry `json:"tokens"`
+}
+
+type screeningResult struct {
+	warnings  []entities.TokenWarning
+	expiresAt time.Time
+}
+
+// TokenScreeningService checks tokens against a blocklist and probes unknown
+// tokens for honeypot behaviour by simulating a buy followed by a sell
+type TokenScreeningService struct {
+	taxes     *TokenTaxService
+	trusted   map[common.Address]bool
+	resultTTL time.Duration
+	now       func() time.Time
+
+	mu        sync.RWMutex
+	blocklist map[common.Address]string
+	results   map[common.Address]*screeningResult
+}
+
+// NewTokenScreeningService probes tokens with the round trips of taxes,
+// which may be nil to only check the blocklist
+func NewTokenScreeningService(taxes *TokenTaxService, trusted []entities.Token) *TokenScreeningService {
+	trustedSet := make(map[common.Address]bool, len(trusted))
+	for _, t := range trusted {
+		trustedSet[t.Address] = true
+	}
+
+	return &TokenScreeningService{
+		taxes:     taxes,
+		trusted:   trustedSet,
+		resultTTL: time.Hour,
+		now:       time.Now,
+		blocklist: make(map[common.Address]string),
+		results:   make(map[common.Address]*screeningResult),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *TokenScreeningService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// LoadBlocklist replaces the blocklist with entries from a JSON file
+func (s *TokenScreeningService) LoadBlocklist(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	var config BlocklistConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse blocklist: %w", err)
+	}
+
+	blocklist := make(map[common.Address]string, len(config.Tokens))
+	for _, entry := range config.Tokens {
+		if !common.IsHexAddress(entry.Address) {
+			return fmt.Errorf("invalid blocklist address: %s", entry.Address)
+		}
+		blocklist[common.HexToAddress(entry.Address)] = entry.Reason
+	}
+
+	s.mu.Lock()
+	s.blocklist = blocklist
+	s.mu.Unlock()
+	return nil
+}
+
+// Block adds a token to the blocklist at runtime
+func (s *TokenScreeningService) Block(token common.Address, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocklist[token] = reason
+}
+
+// CheckBlocked returns ErrTokenBlocked for the first blocklisted token
+func (s *TokenScreeningService) CheckBlocked(tokens ...entities.Token) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range tokens {
+		if reason, ok := s.blocklist[t.Address]; ok {
+			return &ErrTokenBlocked{Token: t.Address, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// Screen returns warnings for the given tokens. Results are cached per token.
+func (s *TokenScreeningService) Screen(ctx context.Context, tokens ...entities.Token) []entities.TokenWarning {
+	var warnings []entities.TokenWarning
+	for _, t := range tokens {
+		if s.trusted[t.Address] || t.Address == entities.WETH.Address || t.IsNative() {
+			continue
+		}
+		warnings = append(warnings, s.screenToken(ctx, t)...)
+	}
+	return warnings
+}
+
+func (s *TokenScreeningService) screenToken(ctx context.Context, token entities.Token) []entities.TokenWarning {
+	s.mu.RLock()
+	cached, ok := s.results[token.Address]
+	s.mu.RUnlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.warnings
+	}
+
+	warnings := s.simulateRoundTrip(ctx, token)
+
+	s.mu.Lock()
+	s.results[token.Address] = &screeningResult{
+		warnings:  warnings,
+		expiresAt: s.now().Add(s.resultTTL),
+	}
+	s.mu.Unlock()
+
+	return warnings
+}
+
+// simulateRoundTrip buys the token from its WETH pair and sells it back on
+// a simulated copy of the latest state, from a holder tax tokens don't
+// exempt. A token that can be bought but not sold, or only at a loss of
+// most of it, is the classic honeypot pattern.
+func (s *TokenScreeningService) simulateRoundTrip(ctx context.Context, token entities.Token) []entities.TokenWarning {
+	if s.taxes == nil {
+		return nil
+	}
+	tax, err := s.taxes.Detect(ctx, token)
+	switch {
+	case errors.Is(err, ErrSellReverted):
+		return []entities.TokenWarning{{
+			Token:   token.Address,
+			Code:    WarningHoneypotSuspected,
+			Message: fmt.Sprintf("token can be bought but not sold back: %v", err),
+		}}
+	case errors.Is(err, ErrBuyReverted):
+		return []entities.TokenWarning{{
+			Token:   token.Address,
+			Code:    WarningTransferReverted,
+			Message: fmt.Sprintf("transfer out of the pool reverted: %v", err),
+		}}
+	case err != nil:
+		// No V2-style WETH pool or no eth_simulateV1; nothing to report
+		return nil
+	}
+
+	if tax.SellTaxBps >= HoneypotSellTaxBps {
+		return []entities.TokenWarning{{
+			Token:   token.Address,
+			Code:    WarningHoneypotSuspected,
+			Message: fmt.Sprintf("selling back loses %.2f%% to the token's sell tax", float64(tax.SellTaxBps)/100.0),
+		}}
+	}
+	if loss := tax.BuyTaxBps + tax.SellTaxBps; loss > MaxRoundTripLossBps {
+		return []entities.TokenWarning{{
+			Token:   token.Address,
+			Code:    WarningHighRoundTripLoss,
+			Message: fmt.Sprintf("buying and selling loses %.2f%% to the token's taxes", float64(loss)/100.0),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestTokenScreeningCheckBlocked(t *testing.T) {
+	scam := entities.Token{Address: common.HexToAddress("0x00000000000000000000000000000000000000bd")}
+
+	screener := NewTokenScreeningService(nil, nil)
+	if err := screener.CheckBlocked(entities.WETH, scam); err != nil {
+		t.Fatalf("CheckBlocked() before blocking = %v, want nil", err)
+	}
+
+	screener.Block(scam.Address, "rug pull")
+
+	err := screener.CheckBlocked(entities.WETH, scam)
+	var blocked *ErrTokenBlocked
+	if !errors.As(err, &blocked) {
+		t.Fatalf("CheckBlocked() = %v, want ErrTokenBlocked", err)
+	}
+	if blocked.Token != scam.Address {
+		t.Errorf("blocked token = %s, want %s", blocked.Token.Hex(), scam.Address.Hex())
+	}
+}
+
+func TestTokenScreeningRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		chain    mockTaxChain
+		wantCode string
+	}{
+		{"plain token", mockTaxChain{}, ""},
+		{"sell reverts", mockTaxChain{revertSell: true}, WarningHoneypotSuspected},
+		{"sell taxed away", mockTaxChain{sellBps: 9000}, WarningHoneypotSuspected},
+		{"taxed both ways", mockTaxChain{buyBps: 500, sellBps: 700}, WarningHighRoundTripLoss},
+		{"buy reverts", mockTaxChain{revertSwap: true}, WarningTransferReverted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := tt.chain
+			chain.pair = taxTestPair()
+			screener := NewTokenScreeningService(newTaxTestService(entities.DEXUniswapV2, &chain), nil)
+
+			warnings := screener.Screen(context.Background(), taxToken)
+			if tt.wantCode == "" {
+				if len(warnings) != 0 {
+					t.Errorf("Screen() = %v, want no warnings", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || warnings[0].Code != tt.wantCode {
+				t.Errorf("Screen() = %v, want single %s warning", warnings, tt.wantCode)
+			}
+		})
+	}
+}
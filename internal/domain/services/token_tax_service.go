@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
+)
+
+var (
+	erc20Contract  = bindings.NewERC20()
+	v2PairContract = bindings.NewUniswapV2Pair()
+	wethContract   = bindings.NewWETH9()
+)
+
+// taxProbe is the simulated trader. It is a fresh address rather than one
+// tax tokens commonly exempt from fees and limits, such as 0xdead.
+var taxProbe = common.HexToAddress("0x7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a7a")
+
+// ErrBuyReverted is returned when the pair could not send the token out
+var ErrBuyReverted = errors.New("simulated buy reverted")
+
+// ErrSellReverted is returned when the token was bought but could not be
+// sent back to the pair, the mark of a honeypot
+var ErrSellReverted = errors.New("simulated sell reverted")
+
+// maxTxSearchSteps bounds the bisection for a token's transaction cap
+const maxTxSearchSteps = 16
+
+// CallSimulator runs dependent calls on top of the latest state without
+// sending anything; it needs a node that serves eth_simulateV1
+type CallSimulator interface {
+	SimulateV1(ctx context.Context, opts ethclient.SimulateOptions) ([]ethclient.SimulateBlockResult, error)
+}
+
+type taxResult struct {
+	tax       *entities.TokenTax
+	err       error
+	expiresAt time.Time
+}
+
+// taxCall is one step of a simulated round trip, named for errors
+type taxCall struct {
+	name string
+	msg  ethereum.CallMsg
+}
+
+// callReverted is a step of a simulated round trip that did not succeed
+type callReverted struct {
+	call   string
+	reason string
+}
+
+func (e *callReverted) Error() string {
+	return e.call + " failed: " + e.reason
+}
+
+// reverted reports whether err is the named step reverting
+func reverted(err error, call string) bool {
+	var revert *callReverted
+	return errors.As(err, &revert) && revert.call == call
+}
+
+// TokenTaxService measures the buy and sell taxes and the transaction cap
+// of a token by buying it from its WETH pair and selling it back on a
+// simulated copy of the latest state. Results are cached per token.
+type TokenTaxService struct {
+	priceService *PriceService
+	simulator    CallSimulator
+	caller       ContractCaller
+	probeAmount  *big.Int // WETH spent on the simulated buy
+	resultTTL    time.Duration
+	now          func() time.Time
+
+	mu      sync.Mutex
+	results map[common.Address]*taxResult
+}
+
+func NewTokenTaxService(priceService *PriceService, simulator CallSimulator, caller ContractCaller) *TokenTaxService {
+	return &TokenTaxService{
+		priceService: priceService,
+		simulator:    simulator,
+		caller:       caller,
+		probeAmount:  big.NewInt(1e17), // 0.1 WETH
+		resultTTL:    time.Hour,
+		now:          time.Now,
+		results:      make(map[common.Address]*taxResult),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (s *TokenTaxService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// Detect returns token's taxes, simulating a round trip when there is no
+// unexpired result for it
+func (s *TokenTaxService) Detect(ctx context.Context, token entities.Token) (*entities.TokenTax, error) {
+	s.mu.Lock()
+	cached, ok := s.results[token.Address]
+	s.mu.Unlock()
+	if ok && s.now().Before(cached.expiresAt) {
+		return cached.tax, cached.err
+	}
+
+	tax, err := s.simulate(ctx, token)
+	if ctx.Err() != nil {
+		// Cut short by the caller rather than an answer about the token
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.results[token.Address] = &taxResult{
+		tax:       tax,
+		err:       err,
+		expiresAt: s.now().Add(s.resultTTL),
+	}
+	s.mu.Unlock()
+	return tax, err
+}
+
+// simulate buys token with probeAmount WETH through the pair and sells what
+// arrived back into it. The buy tax is what the pair sent but the probe
+// did not receive; the sell tax is what the probe sent but the pair was not
+// credited, measured against its reserves the way fee-on-transfer router
+// swaps do.
+func (s *TokenTaxService) simulate(ctx context.Context, token entities.Token) (*entities.TokenTax, error) {
+	if token.Address == entities.WETH.Address {
+		return nil, errors.New("WETH is the probe's quote token")
+	}
+	pair, err := s.wethPair(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	expected := pair.GetAmountOut(s.probeAmount, entities.WETH.Address)
+	if expected == nil || expected.Sign() <= 0 {
+		return nil, fmt.Errorf("pool %s has no liquidity", pair.Address.Hex())
+	}
+
+	buy := s.buyCalls(pair, token.Address, expected)
+	results, err := s.run(ctx, append(buy, balanceOfCall(token.Address, taxProbe)))
+	if reverted(err, "buy swap") {
+		return nil, fmt.Errorf("%w: %v", ErrBuyReverted, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	received, err := erc20Contract.UnpackBalanceOf(results[len(buy)])
+	if err != nil {
+		return nil, fmt.Errorf("invalid balanceOf response: %w", err)
+	}
+	if received.Sign() == 0 {
+		return nil, errors.New("buy delivered no tokens")
+	}
+
+	sell := append(buy,
+		taxCall{"sell transfer", ethereum.CallMsg{From: taxProbe, To: &token.Address, Data: erc20Contract.PackTransfer(pair.Address, received)}},
+		taxCall{"getReserves", ethereum.CallMsg{From: taxProbe, To: &pair.Address, Data: v2PairContract.PackGetReserves()}},
+		balanceOfCall(token.Address, pair.Address),
+	)
+	results, err = s.run(ctx, sell)
+	if reverted(err, "sell transfer") {
+		return nil, fmt.Errorf("%w: %v", ErrSellReverted, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reserves, err := v2PairContract.UnpackGetReserves(results[len(buy)+1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid getReserves response: %w", err)
+	}
+	balance, err := erc20Contract.UnpackBalanceOf(results[len(buy)+2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid balanceOf response: %w", err)
+	}
+	reserve := reserves.Reserve1
+	if pair.Token0.Address == token.Address {
+		reserve = reserves.Reserve0
+	}
+	credited := new(big.Int).Sub(balance, reserve)
+
+	return &entities.TokenTax{
+		Pool:           pair.Address,
+		BuyTaxBps:      lossBps(expected, received),
+		SellTaxBps:     lossBps(received, credited),
+		MaxTransaction: s.maxTransaction(ctx, pair, token.Address, expected),
+		CheckedAt:      s.now().Unix(),
+	}, nil
+}
+
+// wethPair picks the deepest V2-style pool between WETH and token, which is
+// where tax tokens list and where a swap is a transfer into the pair and a
+// call to it
+func (s *TokenTaxService) wethPair(ctx context.Context, token entities.Token) (*entities.Pair, error) {
+	prices, err := s.priceService.GetPrices(ctx, entities.WETH, token, s.probeAmount)
+	if err != nil {
+		return nil, err
+	}
+	var best *PriceResult
+	for i := range prices {
+		price := &prices[i]
+		if price.Error != nil || price.Pair == nil || price.AmountOut == nil || !constantProduct(price.Pair) {
+			continue
+		}
+		if best == nil || price.AmountOut.Cmp(best.AmountOut) > 0 {
+			best = price
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no Uniswap V2-style WETH pool for %s", token.Address.Hex())
+	}
+	return best.Pair, nil
+}
+
+// buyCalls wraps probeAmount ETH, pays it into the pair and swaps it for
+// amountOut of token, sent to the probe
+func (s *TokenTaxService) buyCalls(pair *entities.Pair, token common.Address, amountOut *big.Int) []taxCall {
+	amount0Out, amount1Out := new(big.Int), amountOut
+	if pair.Token0.Address == token {
+		amount0Out, amount1Out = amountOut, new(big.Int)
+	}
+	weth := entities.WETH.Address
+
+	return []taxCall{
+		{"wrap", ethereum.CallMsg{From: taxProbe, To: &weth, Value: s.probeAmount, Data: wethContract.PackDeposit()}},
+		{"buy transfer", ethereum.CallMsg{From: taxProbe, To: &weth, Data: erc20Contract.PackTransfer(pair.Address, s.probeAmount)}},
+		{"buy swap", ethereum.CallMsg{From: taxProbe, To: &pair.Address, Data: v2PairContract.PackSwap(amount0Out, amount1Out, taxProbe, nil)}},
+	}
+}
+
+// run simulates calls in order from the probe, which is given the ETH the
+// buy wraps, and returns their return data. A failed call fails the run.
+func (s *TokenTaxService) run(ctx context.Context, calls []taxCall) ([][]byte, error) {
+	msgs := make([]ethereum.CallMsg, len(calls))
+	for i, call := range calls {
+		msgs[i] = call.msg
+	}
+	blocks, err := s.simulator.SimulateV1(ctx, ethclient.SimulateOptions{
+		BlockStateCalls: []ethclient.SimulateBlock{{
+			StateOverrides: map[common.Address]ethereum.OverrideAccount{
+				taxProbe: {Balance: new(big.Int).Mul(s.probeAmount, big.NewInt(2))},
+			},
+			Calls: msgs,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("simulation failed: %w", err)
+	}
+	if len(blocks) != 1 || len(blocks[0].Calls) != len(calls) {
+		return nil, fmt.Errorf("simulation returned %d blocks for 1", len(blocks))
+	}
+
+	results := make([][]byte, len(calls))
+	for i, result := range blocks[0].Calls {
+		if result.Status != types.ReceiptStatusSuccessful {
+			reason := "reverted"
+			if result.Error != nil {
+				reason = result.Error.Message
+			}
+			return nil, &callReverted{call: calls[i].name, reason: reason}
+		}
+		results[i] = result.ReturnValue
+	}
+	return results, nil
+}
+
+// maxTransaction bisects for the largest amount the pair can transfer out
+// at once, up to half its reserve of token. It returns nil when that much
+// transfers, meaning the token has no cap a realistic buy would hit.
+func (s *TokenTaxService) maxTransaction(ctx context.Context, pair *entities.Pair, token common.Address, bought *big.Int) *big.Int {
+	if s.caller == nil {
+		return nil
+	}
+	reserve := pair.Reserve1
+	if pair.Token0.Address == token {
+		reserve = pair.Reserve0
+	}
+	hi := new(big.Int).Rsh(reserve, 1)
+	if hi.Cmp(bought) <= 0 || s.transfers(ctx, token, pair.Address, hi) {
+		return nil
+	}
+
+	// The simulated buy already moved bought
+	lo := new(big.Int).Set(bought)
+	one := big.NewInt(1)
+	for i := 0; i < maxTxSearchSteps && new(big.Int).Add(lo, one).Cmp(hi) < 0; i++ {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		if s.transfers(ctx, token, pair.Address, mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// transfers reports whether from can transfer amount of token to the probe.
+// Tokens that return nothing from transfer succeed by not reverting.
+func (s *TokenTaxService) transfers(ctx context.Context, token, from common.Address, amount *big.Int) bool {
+	result, err := s.caller.CallContract(ctx, ethereum.CallMsg{
+		From: from,
+		To:   &token,
+		Data: erc20Contract.PackTransfer(taxProbe, amount),
+	})
+	if err != nil {
+		return false
+	}
+	if len(result) == 0 {
+		return true
+	}
+	ok, err := erc20Contract.UnpackTransfer(result)
+	return err == nil && ok
+}
+
+func balanceOfCall(token, owner common.Address) taxCall {
+	return taxCall{"balanceOf", ethereum.CallMsg{From: taxProbe, To: &token, Data: erc20Contract.PackBalanceOf(owner)}}
+}
+
+// constantProduct reports whether pair is a V2-style pool
+func constantProduct(pair *entities.Pair) bool {
+	return pair.Stable == nil && pair.Concentrated == nil &&
+		(pair.DEX == entities.DEXUniswapV2 || pair.DEX == entities.DEXSushiswap)
+}
+
+// lossBps is how much of expected did not arrive, in basis points
+func lossBps(expected, actual *big.Int) uint64 {
+	if actual.Cmp(expected) >= 0 {
+		return 0
+	}
+	loss := new(big.Int).Sub(expected, actual)
+	loss.Mul(loss, big.NewInt(10000))
+	return loss.Div(loss, expected).Uint64()
+}
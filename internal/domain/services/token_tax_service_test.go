@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+var taxToken = entities.Token{
+	Address:  common.HexToAddress("0x00000000000000000000000000000000000007a5"),
+	Symbol:   "TAX",
+	Decimals: 18,
+}
+
+// taxABI decodes the calls the probe makes: its token, pair and WETH calls
+var taxABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"deposit","inputs":[]},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}]},
+		{"type":"function","name":"balanceOf","inputs":[{"name":"account","type":"address"}]},
+		{"type":"function","name":"getReserves","inputs":[]},
+		{"type":"function","name":"swap","inputs":[
+			{"name":"amount0Out","type":"uint256"},{"name":"amount1Out","type":"uint256"},
+			{"name":"to","type":"address"},{"name":"data","type":"bytes"}]}
+	]`))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// mockTaxChain plays a token that keeps buyBps of what the pair sends and
+// sellBps of what is sent to the pair, and caps single transfers at limit.
+// With revertSell, holders can't send it to the pair at all.
+type mockTaxChain struct {
+	pair       *entities.Pair
+	buyBps     int64
+	sellBps    int64
+	limit      *big.Int
+	revertSwap bool
+	revertSell bool
+	runs       int
+}
+
+func (m *mockTaxChain) SimulateV1(ctx context.Context, opts ethclient.SimulateOptions) ([]ethclient.SimulateBlockResult, error) {
+	m.runs++
+	keep := func(amount *big.Int, bps int64) *big.Int {
+		out := new(big.Int).Mul(amount, big.NewInt(10000-bps))
+		return out.Div(out, big.NewInt(10000))
+	}
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+
+	received, credited := new(big.Int), new(big.Int)
+	var results []ethclient.SimulateCallResult
+	for _, call := range opts.BlockStateCalls[0].Calls {
+		result := ethclient.SimulateCallResult{Status: types.ReceiptStatusSuccessful}
+		method, err := taxABI.MethodById(call.Data)
+		if err != nil {
+			return nil, err
+		}
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case method.Name == "swap":
+			if m.revertSwap {
+				result = ethclient.SimulateCallResult{Error: &ethclient.CallError{Message: "execution reverted: TRANSFER_FAILED"}}
+			}
+			if args[2].(common.Address) != taxProbe {
+				return nil, errors.New("swap pays someone other than the probe")
+			}
+			received = keep(args[0].(*big.Int), m.buyBps)
+		case method.Name == "transfer" && *call.To == taxToken.Address:
+			if m.revertSell {
+				result = ethclient.SimulateCallResult{Error: &ethclient.CallError{Message: "execution reverted: TRADING_DISABLED"}}
+			}
+			credited = keep(args[1].(*big.Int), m.sellBps)
+		case method.Name == "balanceOf" && args[0].(common.Address) == taxProbe:
+			result.ReturnValue = word(received)
+		case method.Name == "balanceOf":
+			result.ReturnValue = word(new(big.Int).Add(m.pair.Reserve0, credited))
+		case method.Name == "getReserves":
+			result.ReturnValue = append(append(word(m.pair.Reserve0), word(m.pair.Reserve1)...), make([]byte, 32)...)
+		}
+		results = append(results, result)
+	}
+	return []ethclient.SimulateBlockResult{{Calls: results}}, nil
+}
+
+func (m *mockTaxChain) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	args, err := taxABI.Methods["transfer"].Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	if m.limit != nil && args[1].(*big.Int).Cmp(m.limit) > 0 {
+		return nil, errors.New("execution reverted: exceeds max transaction")
+	}
+	return common.LeftPadBytes([]byte{1}, 32), nil
+}
+
+func newTaxTestService(dexType entities.DEXType, chain *mockTaxChain) *TokenTaxService {
+	client := NewMockDEXClient(dexType)
+	client.SetPair(entities.WETH.Address, taxToken.Address, chain.pair)
+	return NewTokenTaxService(NewPriceService([]dex.DEXClient{client}, nil), chain, chain)
+}
+
+func taxTestPair() *entities.Pair {
+	return &entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   taxToken,
+		Token1:   entities.WETH,
+		Reserve0: new(big.Int).Mul(big.NewInt(1e6), big.NewInt(1e18)),
+		Reserve1: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	}
+}
+
+func TestTokenTaxServiceDetect(t *testing.T) {
+	limit := new(big.Int).Mul(big.NewInt(2000), big.NewInt(1e18))
+	tests := []struct {
+		name              string
+		buyBps, sellBps   int64
+		limit             *big.Int
+		wantBuy, wantSell uint64
+	}{
+		{"plain token", 0, 0, nil, 0, 0},
+		{"taxed with a cap", 500, 1000, limit, 500, 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &mockTaxChain{pair: taxTestPair(), buyBps: tt.buyBps, sellBps: tt.sellBps, limit: tt.limit}
+			service := newTaxTestService(entities.DEXUniswapV2, chain)
+
+			tax, err := service.Detect(context.Background(), taxToken)
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if tax.BuyTaxBps != tt.wantBuy || tax.SellTaxBps != tt.wantSell {
+				t.Errorf("taxes = %d/%d bps, want %d/%d", tax.BuyTaxBps, tax.SellTaxBps, tt.wantBuy, tt.wantSell)
+			}
+			if tax.Pool != chain.pair.Address {
+				t.Errorf("pool = %s", tax.Pool.Hex())
+			}
+
+			switch {
+			case tt.limit == nil && tax.MaxTransaction != nil:
+				t.Errorf("maxTransaction = %s, want none", tax.MaxTransaction)
+			case tt.limit != nil:
+				// Within the bisection's resolution below the cap
+				floor := new(big.Int).Mul(tt.limit, big.NewInt(99))
+				floor.Div(floor, big.NewInt(100))
+				if tax.MaxTransaction == nil || tax.MaxTransaction.Cmp(tt.limit) > 0 || tax.MaxTransaction.Cmp(floor) < 0 {
+					t.Errorf("maxTransaction = %v, want just under %s", tax.MaxTransaction, tt.limit)
+				}
+			}
+
+			if _, err := service.Detect(context.Background(), taxToken); err != nil || chain.runs != 2 {
+				t.Errorf("second Detect() simulated again (%d runs, err %v)", chain.runs, err)
+			}
+		})
+	}
+}
+
+func TestTokenTaxServiceFailures(t *testing.T) {
+	ctx := context.Background()
+
+	chain := &mockTaxChain{pair: taxTestPair(), revertSwap: true}
+	if _, err := newTaxTestService(entities.DEXUniswapV2, chain).Detect(ctx, taxToken); !errors.Is(err, ErrBuyReverted) {
+		t.Errorf("Detect() with a reverting buy = %v, want ErrBuyReverted", err)
+	}
+
+	chain = &mockTaxChain{pair: taxTestPair(), revertSell: true}
+	if _, err := newTaxTestService(entities.DEXUniswapV2, chain).Detect(ctx, taxToken); !errors.Is(err, ErrSellReverted) {
+		t.Errorf("Detect() with a reverting sell = %v, want ErrSellReverted", err)
+	}
+
+	concentrated := taxTestPair()
+	concentrated.DEX = entities.DEXUniswapV3
+	chain = &mockTaxChain{pair: concentrated}
+	if _, err := newTaxTestService(entities.DEXUniswapV3, chain).Detect(ctx, taxToken); err == nil || chain.runs != 0 {
+		t.Errorf("Detect() without a V2-style pool: err = %v after %d runs", err, chain.runs)
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// TradeSizeGuard relates trades to the depth of the pair's pools
+type TradeSizeGuard struct {
+	// ImpactCeilingBps is the price impact maxRecommendedAmountIn keeps
+	// each pool within
+	ImpactCeilingBps uint64
+	// MaxDepthBps flags trades over this share of the pools' input
+	// reserves; 0 flags none
+	MaxDepthBps uint64
+	Reject      bool // Fail flagged trades with AmountTooLarge instead
+}
+
+// DefaultTradeSizeGuard recommends trades within 1% impact and flags those
+// larger than the pools' whole input reserves, which cost half the output
+// on a constant product pool
+var DefaultTradeSizeGuard = TradeSizeGuard{ImpactCeilingBps: 100, MaxDepthBps: 10000}
+
+// SetTradeSizeGuard sets the impact ceiling of recommended trade sizes and
+// which trades are flagged, or rejected, as too large for the pools
+func (s *RouterService) SetTradeSizeGuard(guard TradeSizeGuard) {
+	s.sizeGuard = guard
+}
+
+// tradeSize sizes amountIn against the pools that quoted it. Nil when none
+// did.
+func (g TradeSizeGuard) tradeSize(prices []PriceResult, tokenIn entities.Token, amountIn *big.Int) *entities.TradeSize {
+	size := &entities.TradeSize{
+		MaxRecommendedAmountIn: big.NewInt(0),
+		ImpactCeilingBps:       g.ImpactCeilingBps,
+		AccessibleLiquidity:    big.NewInt(0),
+	}
+	for _, p := range prices {
+		reserveIn := reserveOf(p.Pair, tokenIn.Address)
+		if !isValidPrice(p) || reserveIn == nil || reserveIn.Sign() <= 0 {
+			continue
+		}
+		size.AccessibleLiquidity.Add(size.AccessibleLiquidity, reserveIn)
+		size.MaxRecommendedAmountIn.Add(size.MaxRecommendedAmountIn, maxAmountWithinImpact(p.Pair, tokenIn.Address, reserveIn, g.ImpactCeilingBps))
+	}
+
+	if size.AccessibleLiquidity.Sign() == 0 {
+		return nil
+	}
+	if g.MaxDepthBps > 0 {
+		limit := new(big.Int).Mul(size.AccessibleLiquidity, new(big.Int).SetUint64(g.MaxDepthBps))
+		size.ExceedsDepth = new(big.Int).Mul(amountIn, big.NewInt(10000)).Cmp(limit) > 0
+	}
+	return size
+}
+
+// maxAmountWithinImpact searches for the largest input of up to reserveIn
+// the pool takes within ceilingBps of price impact, to a thousandth of
+// itself. Impact is measured against a millionth of the reserve, so it
+// holds for tokens of any decimals.
+func maxAmountWithinImpact(pair *entities.Pair, tokenIn common.Address, reserveIn *big.Int, ceilingBps uint64) *big.Int {
+	spotIn := new(big.Int).Div(reserveIn, big.NewInt(1_000_000))
+	if spotIn.Sign() == 0 {
+		spotIn.SetInt64(1)
+	}
+	spotOut := pair.GetAmountOut(spotIn, tokenIn)
+	if spotOut.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	// out/amount >= (1 - ceiling) * spotOut/spotIn
+	floor := new(big.Int).Mul(spotOut, new(big.Int).SetUint64(10000-min(ceilingBps, 10000)))
+	withinCeiling := func(amount *big.Int) bool {
+		out := pair.GetAmountOut(amount, tokenIn)
+		got := new(big.Int).Mul(new(big.Int).Mul(out, spotIn), big.NewInt(10000))
+		return got.Cmp(new(big.Int).Mul(amount, floor)) >= 0
+	}
+	if withinCeiling(reserveIn) {
+		return new(big.Int).Set(reserveIn)
+	}
+
+	lo, hi := big.NewInt(0), new(big.Int).Set(reserveIn)
+	for new(big.Int).Mul(new(big.Int).Sub(hi, lo), big.NewInt(1000)).Cmp(hi) > 0 {
+		mid := new(big.Int).Rsh(new(big.Int).Add(lo, hi), 1)
+		if withinCeiling(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+func reserveOf(pair *entities.Pair, token common.Address) *big.Int {
+	switch {
+	case pair == nil:
+		return nil
+	case pair.Token0.Address == token:
+		return pair.Reserve0
+	case pair.Token1.Address == token:
+		return pair.Reserve1
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestTradeSizeGuard(t *testing.T) {
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+	// 1,000 WETH against 2,000,000 USDC
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.USDC.Address, entities.WETH.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: big.NewInt(2_000_000e6), Reserve1: ether(1_000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2}, &MockCache{}))
+
+	quote, err := router.GetSmartQuote(context.Background(), entities.USDC, entities.WETH, big.NewInt(10_000e6), 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	size := quote.TradeSize
+	if size == nil || size.AccessibleLiquidity.Int64() != 2_000_000e6 || size.ExceedsDepth {
+		t.Fatalf("TradeSize = %+v, want 2,000,000 USDC accessible and within depth", size)
+	}
+	// 1% impact on a constant product pool is a trade of 1/99 of the reserve,
+	// after the fee
+	if got := size.MaxRecommendedAmountIn.Int64(); got < 20_200e6 || got > 20_300e6 {
+		t.Errorf("MaxRecommendedAmountIn = %d, want about 20,260 USDC", got)
+	}
+
+	// Three times the reserve pays 75% less than spot
+	quote, err = router.GetSmartQuote(context.Background(), entities.USDC, entities.WETH, big.NewInt(6_000_000e6), 0)
+	if err != nil {
+		t.Fatalf("GetSmartQuote(oversized) error = %v", err)
+	}
+	if !quote.TradeSize.ExceedsDepth {
+		t.Error("a trade of three times the reserve is not flagged")
+	}
+
+	router.SetTradeSizeGuard(TradeSizeGuard{ImpactCeilingBps: 100, MaxDepthBps: 10000, Reject: true})
+	if _, err := router.GetSmartQuote(context.Background(), entities.USDC, entities.WETH, big.NewInt(6_000_000e6), 0); apperror.CodeOf(err) != apperror.AmountTooLarge {
+		t.Errorf("rejecting guard error = %v, want amount too large", err)
+	}
+	if _, err := router.GetSmartQuote(context.Background(), entities.USDC, entities.WETH, big.NewInt(10_000e6), 0); err != nil {
+		t.Errorf("rejecting guard failed a trade within depth: %v", err)
+	}
+}
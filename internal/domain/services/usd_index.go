@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// USDIndexLeg is a token's USD price through one stablecoin of the index
+type USDIndexLeg struct {
+	Stable       entities.Token
+	PriceUSD     *big.Int // 18 decimals; nil when Error is set
+	DeviationBps int64    // From the index price
+	Median       bool     // The index price was taken from this leg
+	Error        error
+}
+
+// USDIndexPrice is a token's USD price as the median of its price through
+// each of USDC, USDT and DAI, so no single stablecoin sets it
+type USDIndexPrice struct {
+	PriceUSD *big.Int // 18 decimals
+	Legs     []USDIndexLeg
+}
+
+// GetTokenPriceIndex prices token in each index stablecoin and takes the
+// median. With every leg priced the median outvotes one stablecoin off peg;
+// with fewer, each leg is converted at its stablecoin's peg price.
+func (s *PriceService) GetTokenPriceIndex(ctx context.Context, token entities.Token) (*USDIndexPrice, error) {
+	legs := make([]USDIndexLeg, len(monitoredStablecoins))
+	var wg sync.WaitGroup
+	for i, stable := range monitoredStablecoins {
+		wg.Add(1)
+		go func(i int, stable entities.Token) {
+			defer wg.Done()
+			legs[i].Stable = stable
+			legs[i].PriceUSD, legs[i].Error = s.GetTokenPriceIn(ctx, token, stable)
+		}(i, stable)
+	}
+	wg.Wait()
+
+	priced := make(map[common.Address]*big.Int, len(legs))
+	var lastErr error
+	for _, leg := range legs {
+		if leg.Error != nil {
+			// A leg whose venues failed explains more than a missing pool
+			if lastErr == nil || !isUpstreamFailure(lastErr) {
+				lastErr = leg.Error
+			}
+			continue
+		}
+		if leg.PriceUSD.Sign() > 0 {
+			priced[leg.Stable.Address] = leg.PriceUSD
+		}
+	}
+	if len(priced) == 0 {
+		return nil, fmt.Errorf("no stablecoin leg priced %s: %w", token.Symbol, lastErr)
+	}
+	if len(priced) < len(monitoredStablecoins) {
+		one := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+		for i := range legs {
+			if price := priced[legs[i].Stable.Address]; price != nil {
+				price.Mul(price, s.pegPrice(legs[i].Stable))
+				price.Div(price, one)
+			}
+		}
+	}
+
+	index := &USDIndexPrice{PriceUSD: medianBigInt(slices.Collect(maps.Values(priced))), Legs: legs}
+	for i := range index.Legs {
+		leg := &index.Legs[i]
+		if leg.PriceUSD == nil {
+			continue
+		}
+		diff := new(big.Int).Sub(leg.PriceUSD, index.PriceUSD)
+		leg.DeviationBps = bpsOf(diff, index.PriceUSD).Int64()
+		leg.Median = isMedianLeg(leg.PriceUSD, priced)
+	}
+	return index, nil
+}
+
+// isMedianLeg reports whether price is the median of priced, or one of the
+// two middle values averaged into it
+func isMedianLeg(price *big.Int, priced map[common.Address]*big.Int) bool {
+	below, above := 0, 0
+	for _, other := range priced {
+		switch other.Cmp(price) {
+		case -1:
+			below++
+		case 1:
+			above++
+		}
+	}
+	// At most half the other legs sit on either side of a middle value
+	return 2*below <= len(priced) && 2*above <= len(priced)
+}
+
+// pegPrice is a stablecoin's USD value with 18 decimals: $1 unless the
+// stablecoin pegs say otherwise
+func (s *PriceService) pegPrice(stable entities.Token) *big.Int {
+	if s.pegs != nil {
+		price, _ := s.pegs.PegPrice(stable.Address)
+		return price
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+const (
+	// venueStatsBuckets is how many slices the window slides by
+	venueStatsBuckets = 30
+
+	// VenueBreakerMinQuotes is how many quotes a venue needs in the window
+	// before its error rate can open its circuit
+	VenueBreakerMinQuotes = 20
+	// VenueBreakerErrorRate is the share of a venue's quotes that must
+	// error to open its circuit
+	VenueBreakerErrorRate = 0.5
+	// VenueBreakerOpenFor is how long an open circuit leaves the venue out
+	// before one quote is let through to probe it
+	VenueBreakerOpenFor = 30 * time.Second
+)
+
+// ErrVenueCircuitOpen is a venue's result while its circuit is open
+var ErrVenueCircuitOpen = errors.New("venue circuit open")
+
+// Venue circuit states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open" // One probe quote is in flight
+)
+
+type quoteOutcome int
+
+const (
+	outcomeSuccess quoteOutcome = iota
+	outcomeError
+	outcomeNoLiquidity
+)
+
+type venuePairKey struct {
+	dex            entities.DEXType
+	token0, token1 entities.Token
+}
+
+type venueBucket struct {
+	start  time.Time // Zero until first used
+	counts map[venuePairKey]*entities.QuoteOutcomes
+}
+
+type venueCircuit struct {
+	state    string
+	openedAt time.Time
+	closedAt time.Time // Quotes before are left out of the error rate
+	probing  bool
+}
+
+// VenueStats counts each venue's and pair's quote outcomes over a sliding
+// window, for ops dashboards, and opens a venue's circuit when most of its
+// recent quotes error, so quotes stop waiting on a venue that is down.
+// Missing or shallow pools are outcomes of their own and never open it.
+type VenueStats struct {
+	bucket  time.Duration
+	breaker bool
+	now     func() time.Time
+
+	mu       sync.Mutex
+	buckets  []venueBucket
+	circuits map[entities.DEXType]*venueCircuit
+}
+
+// NewVenueStats counts outcomes over the last window, with the circuit
+// breaker on
+func NewVenueStats(window time.Duration) *VenueStats {
+	return &VenueStats{
+		bucket:   window / venueStatsBuckets,
+		breaker:  true,
+		now:      time.Now,
+		buckets:  make([]venueBucket, venueStatsBuckets),
+		circuits: make(map[entities.DEXType]*venueCircuit),
+	}
+}
+
+// Window is how far back outcomes are counted
+func (s *VenueStats) Window() time.Duration {
+	return s.bucket * venueStatsBuckets
+}
+
+// SetClock replaces the time source, for tests
+func (s *VenueStats) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// SetBreaker turns the circuit breaker on or off; outcomes are counted
+// either way
+func (s *VenueStats) SetBreaker(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breaker = enabled
+}
+
+// Allow reports whether a quote may ask venue. An open circuit refuses
+// until VenueBreakerOpenFor has passed, then lets a single probe through.
+func (s *VenueStats) Allow(venue entities.DEXType) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.circuit(venue)
+	if c.state == CircuitClosed {
+		return true
+	}
+	if c.probing || s.now().Sub(c.openedAt) < VenueBreakerOpenFor {
+		return false
+	}
+	c.probing = true
+	c.state = CircuitHalfOpen
+	return true
+}
+
+// record counts one venue's result of a quote made with ctx
+func (s *VenueStats) record(ctx context.Context, tokenIn, tokenOut entities.Token, result PriceResult) {
+	var outcome quoteOutcome
+	switch {
+	case errors.Is(result.Error, ErrVenueCircuitOpen):
+		return
+	case result.Error != nil && ctx.Err() != nil:
+		// The caller gave up, which says nothing about the venue
+		s.release(result.DEX)
+		return
+	case errors.Is(result.Error, dex.ErrPoolNotFound), errors.Is(result.Error, ErrBelowMinLiquidity):
+		outcome = outcomeNoLiquidity
+	case result.Error != nil:
+		outcome = outcomeError
+	case !isValidPrice(result):
+		outcome = outcomeNoLiquidity
+	}
+	s.count(result.DEX, tokenIn, tokenOut, outcome)
+}
+
+// count adds a quote of tokenA and tokenB through venue and moves the
+// venue's circuit on its outcome
+func (s *VenueStats) count(venue entities.DEXType, tokenA, tokenB entities.Token, outcome quoteOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	token0, token1 := tokenA, tokenB
+	if token1.Address.Hex() < token0.Address.Hex() {
+		token0, token1 = token1, token0
+	}
+	key := venuePairKey{dex: venue, token0: token0, token1: token1}
+	bucket := s.current(now)
+	counts := bucket.counts[key]
+	if counts == nil {
+		counts = &entities.QuoteOutcomes{}
+		bucket.counts[key] = counts
+	}
+	addOutcome(counts, outcome)
+
+	c := s.circuit(venue)
+	if c.probing {
+		c.probing = false
+		if outcome == outcomeError {
+			c.state, c.openedAt = CircuitOpen, now
+		} else {
+			c.state, c.closedAt = CircuitClosed, now
+		}
+		return
+	}
+	if outcome != outcomeError || c.state != CircuitClosed || !s.breaker {
+		return
+	}
+	recent := s.total(now, func(k venuePairKey) bool { return k.dex == venue }, c.closedAt)
+	if recent.Quotes >= VenueBreakerMinQuotes && float64(recent.Errors) >= VenueBreakerErrorRate*float64(recent.Quotes) {
+		c.state, c.openedAt = CircuitOpen, now
+	}
+}
+
+// release frees venue's probe without an outcome, so the next quote probes
+// it instead
+func (s *VenueStats) release(venue entities.DEXType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.circuit(venue); c.probing {
+		c.probing = false
+		c.state = CircuitOpen
+	}
+}
+
+func addOutcome(counts *entities.QuoteOutcomes, outcome quoteOutcome) {
+	counts.Quotes++
+	switch outcome {
+	case outcomeSuccess:
+		counts.Successes++
+	case outcomeError:
+		counts.Errors++
+	case outcomeNoLiquidity:
+		counts.NoLiquidity++
+	}
+}
+
+// current returns the bucket now falls in, emptying it if it last held an
+// earlier slice of time. Callers hold s.mu.
+func (s *VenueStats) current(now time.Time) *venueBucket {
+	start := now.Truncate(s.bucket)
+	b := &s.buckets[int(start.UnixNano()/int64(s.bucket))%len(s.buckets)]
+	if !b.start.Equal(start) {
+		b.start = start
+		b.counts = make(map[venuePairKey]*entities.QuoteOutcomes)
+	}
+	return b
+}
+
+// live reports whether bucket b is inside the window ending at now and
+// started at or after since
+func (s *VenueStats) live(b *venueBucket, now, since time.Time) bool {
+	return !b.start.IsZero() && now.Sub(b.start) < s.Window() && !b.start.Before(since)
+}
+
+// total sums the window's counts of the keys match accepts, leaving out
+// buckets started before since. Callers hold s.mu.
+func (s *VenueStats) total(now time.Time, match func(venuePairKey) bool, since time.Time) entities.QuoteOutcomes {
+	var sum entities.QuoteOutcomes
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if !s.live(b, now, since) {
+			continue
+		}
+		for key, counts := range b.counts {
+			if match(key) {
+				addOutcomes(&sum, counts)
+			}
+		}
+	}
+	return sum
+}
+
+// circuit returns venue's circuit, creating it closed. Callers hold s.mu.
+func (s *VenueStats) circuit(venue entities.DEXType) *venueCircuit {
+	c, ok := s.circuits[venue]
+	if !ok {
+		c = &venueCircuit{state: CircuitClosed}
+		s.circuits[venue] = c
+	}
+	return c
+}
+
+// Stats returns each venue's outcomes over the window, venues by name and
+// their pairs most quoted first. Venues whose circuit has opened are
+// listed even with no quotes left in the window.
+func (s *VenueStats) Stats() []entities.VenueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	venues := make(map[entities.DEXType]*entities.VenueStats)
+	pairs := make(map[venuePairKey]*entities.VenuePairStats)
+	for i := range s.buckets {
+		b := &s.buckets[i]
+		if !s.live(b, now, time.Time{}) {
+			continue
+		}
+		for key, counts := range b.counts {
+			venue, ok := venues[key.dex]
+			if !ok {
+				venue = &entities.VenueStats{DEX: key.dex}
+				venues[key.dex] = venue
+			}
+			pair, ok := pairs[key]
+			if !ok {
+				pair = &entities.VenuePairStats{Token0: key.token0, Token1: key.token1}
+				pairs[key] = pair
+			}
+			addOutcomes(&venue.QuoteOutcomes, counts)
+			addOutcomes(&pair.QuoteOutcomes, counts)
+		}
+	}
+	for venue, c := range s.circuits {
+		if _, ok := venues[venue]; !ok && c.state != CircuitClosed {
+			venues[venue] = &entities.VenueStats{DEX: venue}
+		}
+	}
+	for key, pair := range pairs {
+		venue := venues[key.dex]
+		setSuccessRate(&pair.QuoteOutcomes)
+		venue.Pairs = append(venue.Pairs, *pair)
+	}
+
+	stats := make([]entities.VenueStats, 0, len(venues))
+	for venue, v := range venues {
+		setSuccessRate(&v.QuoteOutcomes)
+		v.Circuit = s.circuit(venue).state
+		sort.Slice(v.Pairs, func(i, j int) bool {
+			if v.Pairs[i].Quotes != v.Pairs[j].Quotes {
+				return v.Pairs[i].Quotes > v.Pairs[j].Quotes
+			}
+			return v.Pairs[i].Token0.Address.Hex()+v.Pairs[i].Token1.Address.Hex() < v.Pairs[j].Token0.Address.Hex()+v.Pairs[j].Token1.Address.Hex()
+		})
+		stats = append(stats, *v)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].DEX < stats[j].DEX })
+	return stats
+}
+
+func addOutcomes(sum, counts *entities.QuoteOutcomes) {
+	sum.Quotes += counts.Quotes
+	sum.Successes += counts.Successes
+	sum.Errors += counts.Errors
+	sum.NoLiquidity += counts.NoLiquidity
+}
+
+func setSuccessRate(counts *entities.QuoteOutcomes) {
+	if counts.Quotes > 0 {
+		counts.SuccessRate = float64(counts.Successes) / float64(counts.Quotes)
+	}
+}
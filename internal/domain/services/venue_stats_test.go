@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestVenueStatsBreaker(t *testing.T) {
+	ctx := context.Background()
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	stats := NewVenueStats(5 * time.Minute)
+	stats.SetClock(clock.Now)
+	failed := PriceResult{DEX: entities.DEXCurve, Error: errors.New("rpc down")}
+	ok := PriceResult{DEX: entities.DEXCurve, AmountOut: big.NewInt(1), Pair: &entities.Pair{}}
+	missing := PriceResult{DEX: entities.DEXCurve, Error: dex.ErrPoolNotFound}
+
+	// Missing pools are answers, so they neither trip nor count as errors
+	for i := 0; i < VenueBreakerMinQuotes; i++ {
+		stats.record(ctx, entities.WETH, entities.USDC, missing)
+	}
+	for i := 0; i < VenueBreakerMinQuotes-1; i++ {
+		stats.record(ctx, entities.WETH, entities.USDC, failed)
+	}
+	if !stats.Allow(entities.DEXCurve) {
+		t.Fatal("circuit opened with errors under half the quotes")
+	}
+	for i := 0; i < 2; i++ {
+		stats.record(ctx, entities.USDC, entities.WETH, failed)
+	}
+	if stats.Allow(entities.DEXCurve) {
+		t.Fatal("circuit still closed with most quotes failing")
+	}
+
+	// A failed probe opens it again; a successful one closes it
+	clock.Advance(VenueBreakerOpenFor)
+	if !stats.Allow(entities.DEXCurve) || stats.Allow(entities.DEXCurve) {
+		t.Fatal("want exactly one probe once the circuit has been open long enough")
+	}
+	stats.record(ctx, entities.WETH, entities.USDC, failed)
+	if stats.Allow(entities.DEXCurve) {
+		t.Fatal("circuit closed after a failed probe")
+	}
+	clock.Advance(VenueBreakerOpenFor)
+	if !stats.Allow(entities.DEXCurve) {
+		t.Fatal("no probe after the second wait")
+	}
+	stats.record(ctx, entities.WETH, entities.USDC, ok)
+	// Errors from before the circuit closed don't trip it again
+	stats.record(ctx, entities.WETH, entities.USDC, failed)
+	if !stats.Allow(entities.DEXCurve) {
+		t.Fatal("circuit reopened on the errors that opened it")
+	}
+
+	venues := stats.Stats()
+	if len(venues) != 1 {
+		t.Fatalf("Stats() = %d venues, want 1", len(venues))
+	}
+	want := entities.QuoteOutcomes{Quotes: 44, Successes: 1, Errors: 23, NoLiquidity: 20, SuccessRate: 1.0 / 44}
+	if got := venues[0]; got.QuoteOutcomes != want || got.Circuit != CircuitClosed || len(got.Pairs) != 1 {
+		t.Errorf("Stats() = %+v, want %+v in one pair with a closed circuit", got, want)
+	}
+	if pair := venues[0].Pairs[0]; pair.Token0.Address != entities.USDC.Address || pair.Quotes != 44 {
+		t.Errorf("pair = %s/%s with %d quotes", pair.Token0.Symbol, pair.Token1.Symbol, pair.Quotes)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if venues := stats.Stats(); len(venues) != 0 {
+		t.Errorf("Stats() after the window = %+v, want none", venues)
+	}
+}
+
+func TestPriceServiceVenueStats(t *testing.T) {
+	down := testutil.NewFakeDEX(entities.DEXCurve)
+	down.SetError(errors.New("rpc down"))
+	up := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	up.SetPair(&entities.Pair{
+		Token0: entities.USDC, Token1: entities.WETH, DEX: entities.DEXUniswapV2, Fee: 30,
+		Reserve0: big.NewInt(3e12), Reserve1: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+	})
+	stats := NewVenueStats(5 * time.Minute)
+	priceService := NewPriceService([]dex.DEXClient{down, up}, nil)
+	priceService.SetVenueStats(stats)
+
+	for i := 0; i < VenueBreakerMinQuotes; i++ {
+		if _, err := priceService.GetPrices(context.Background(), entities.WETH, entities.USDC, big.NewInt(1e18)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := down.Calls()
+	results, _ := priceService.GetPrices(context.Background(), entities.WETH, entities.USDC, big.NewInt(1e18))
+	for _, result := range results {
+		switch result.DEX {
+		case entities.DEXCurve:
+			if !errors.Is(result.Error, ErrVenueCircuitOpen) || down.Calls() != calls {
+				t.Errorf("failing venue result = %v after %d more calls, want it left out", result.Error, down.Calls()-calls)
+			}
+		case entities.DEXUniswapV2:
+			if result.Error != nil {
+				t.Errorf("healthy venue error = %v", result.Error)
+			}
+		}
+	}
+
+	byVenue := make(map[entities.DEXType]entities.QuoteOutcomes)
+	for _, venue := range stats.Stats() {
+		byVenue[venue.DEX] = venue.QuoteOutcomes
+	}
+	if got := byVenue[entities.DEXCurve]; got.Quotes != VenueBreakerMinQuotes || got.Errors != got.Quotes {
+		t.Errorf("failing venue stats = %+v, want %d errors", got, VenueBreakerMinQuotes)
+	}
+	if got := byVenue[entities.DEXUniswapV2]; got.Quotes != VenueBreakerMinQuotes+1 || got.SuccessRate != 1 {
+		t.Errorf("healthy venue stats = %+v, want %d successes", got, VenueBreakerMinQuotes+1)
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+type venuesKey struct{}
+
+// WithVenues limits the quotes made with ctx to the given venues. Empty
+// leaves every venue in.
+func WithVenues(ctx context.Context, venues []entities.DEXType) context.Context {
+	return context.WithValue(ctx, venuesKey{}, venues)
+}
+
+// venuesFrom returns the venues ctx limits quotes to, nil for all
+func venuesFrom(ctx context.Context) []entities.DEXType {
+	venues, _ := ctx.Value(venuesKey{}).([]entities.DEXType)
+	return venues
+}
+
+// venueAllowed reports whether the request made with ctx may route
+// through venue
+func venueAllowed(ctx context.Context, venue entities.DEXType) bool {
+	venues := venuesFrom(ctx)
+	return len(venues) == 0 || slices.Contains(venues, venue)
+}
+
+// Venues lists the venues quotes are routed across, in adapter order
+func (s *PriceService) Venues() []entities.DEXType {
+	venues := make([]entities.DEXType, len(s.dexClients))
+	for i, client := range s.dexClients {
+		venues[i] = client.DEXType()
+	}
+	return venues
+}
+
+// clientsFor returns the adapters the request made with ctx may use
+func (s *PriceService) clientsFor(ctx context.Context) []dex.DEXClient {
+	if len(venuesFrom(ctx)) == 0 {
+		return s.dexClients
+	}
+	var clients []dex.DEXClient
+	for _, client := range s.dexClients {
+		if venueAllowed(ctx, client.DEXType()) {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// Venues lists the venues a quote may be limited to: every adapter, and
+// rfq when market makers are asked
+func (s *RouterService) Venues() []entities.DEXType {
+	venues := s.priceService.Venues()
+	if s.rfqProvider != nil {
+		venues = append(venues, entities.DEXRFQ)
+	}
+	return venues
+}
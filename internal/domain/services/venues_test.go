@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestRouterServiceWithVenues(t *testing.T) {
+	token0 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000001"), Symbol: "TOKEN0", Decimals: 18}
+	token1 := entities.Token{Address: common.HexToAddress("0x0000000000000000000000000000000000000002"), Symbol: "TOKEN1", Decimals: 18}
+	ether := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18)) }
+
+	// Sushiswap pays more, so it wins unless the quote is limited
+	uniswap := NewMockDEXClient(entities.DEXUniswapV2)
+	uniswap.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(10000), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	sushiswap := NewMockDEXClient(entities.DEXSushiswap)
+	sushiswap.SetPair(token0.Address, token1.Address, &entities.Pair{
+		Token0: token0, Token1: token1, Reserve0: ether(10000), Reserve1: ether(11000), DEX: entities.DEXSushiswap, Fee: 30,
+	})
+	routerService := NewRouterService(NewPriceService([]dex.DEXClient{uniswap, sushiswap}, &MockCache{}))
+
+	quote, err := routerService.GetSmartQuote(context.Background(), token0, token1, ether(1), 50)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() error = %v", err)
+	}
+	if quote.BestRoute.Hops[0].Pair.DEX != entities.DEXSushiswap || quote.Venues != nil {
+		t.Fatalf("unlimited quote went through %s, venues %v", quote.BestRoute.Hops[0].Pair.DEX, quote.Venues)
+	}
+
+	ctx := WithVenues(context.Background(), []entities.DEXType{entities.DEXUniswapV2})
+	quote, err = routerService.GetSmartQuote(ctx, token0, token1, ether(1), 50)
+	if err != nil {
+		t.Fatalf("GetSmartQuote() limited error = %v", err)
+	}
+	if quote.BestRoute.Hops[0].Pair.DEX != entities.DEXUniswapV2 || len(quote.SplitRoutes) > 0 {
+		t.Errorf("limited quote went through %s", quote.BestRoute.Hops[0].Pair.DEX)
+	}
+	if _, ok := quote.Sources[entities.DEXSushiswap]; ok {
+		t.Errorf("sources = %v, want no sushiswap", quote.Sources)
+	}
+	if len(quote.Venues) != 1 || quote.Venues[0] != entities.DEXUniswapV2 {
+		t.Errorf("venues = %v", quote.Venues)
+	}
+}
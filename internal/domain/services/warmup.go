@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// WarmupPair is a hot pair quoted on startup
+type WarmupPair struct {
+	TokenIn  entities.Token
+	TokenOut entities.Token
+	AmountIn *big.Int
+}
+
+func (p WarmupPair) String() string {
+	return p.TokenIn.Symbol + "/" + p.TokenOut.Symbol
+}
+
+// WarmupStatus is how far the startup warm-up has got
+type WarmupStatus struct {
+	Ready   bool     `json:"ready"`
+	Warmed  int      `json:"warmed"`
+	Total   int      `json:"total"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// Warmup quotes a hot list of pairs on startup, so their pools, fee tiers
+// and token metadata are cached before traffic arrives. The instance is
+// ready once every pair has quoted, or once the deadline passes with some
+// still failing, and stays ready from then on so it doesn't flap behind a
+// load balancer.
+type Warmup struct {
+	router *RouterService
+	pairs  []WarmupPair
+
+	mu     sync.RWMutex
+	warmed []bool
+	ready  bool
+}
+
+func NewWarmup(router *RouterService, pairs []WarmupPair) *Warmup {
+	return &Warmup{
+		router: router,
+		pairs:  pairs,
+		warmed: make([]bool, len(pairs)),
+		ready:  len(pairs) == 0,
+	}
+}
+
+// Run quotes every pair that hasn't quoted yet, retrying each interval
+// until all have or deadline has passed
+func (w *Warmup) Run(ctx context.Context, interval, deadline time.Duration) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if w.round(ctx) {
+			log.Printf("Warm-up quoted %d hot pairs in %s", len(w.pairs), time.Since(start).Round(time.Millisecond))
+			return
+		}
+		if time.Since(start) >= deadline {
+			status := w.Status()
+			log.Printf("Warning: warm-up gave up after %s on %v; serving anyway", deadline, status.Pending)
+			w.markReady()
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// round quotes the pairs not yet warmed concurrently, reporting whether
+// every pair has now quoted
+func (w *Warmup) round(ctx context.Context) bool {
+	var wg sync.WaitGroup
+	for i, pair := range w.pairs {
+		w.mu.RLock()
+		done := w.warmed[i]
+		w.mu.RUnlock()
+		if done {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, pair WarmupPair) {
+			defer wg.Done()
+			if _, err := w.router.GetSmartQuote(ctx, pair.TokenIn, pair.TokenOut, pair.AmountIn, 0); err != nil {
+				log.Printf("Warm-up quote %s failed: %v", pair, err)
+				return
+			}
+			w.mu.Lock()
+			w.warmed[i] = true
+			w.mu.Unlock()
+		}(i, pair)
+	}
+	wg.Wait()
+
+	if len(w.Status().Pending) > 0 {
+		return false
+	}
+	w.markReady()
+	return true
+}
+
+func (w *Warmup) markReady() {
+	w.mu.Lock()
+	w.ready = true
+	w.mu.Unlock()
+}
+
+// Ready reports whether the instance should take traffic
+func (w *Warmup) Ready() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.ready
+}
+
+// Status returns the warm-up's progress
+func (w *Warmup) Status() WarmupStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	status := WarmupStatus{Ready: w.ready, Total: len(w.pairs)}
+	for i, pair := range w.pairs {
+		if w.warmed[i] {
+			status.Warmed++
+		} else {
+			status.Pending = append(status.Pending, pair.String())
+		}
+	}
+	return status
+}
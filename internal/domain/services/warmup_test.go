@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestWarmupReadiness(t *testing.T) {
+	v2 := NewMockDEXClient(entities.DEXUniswapV2)
+	v2.SetPair(entities.WETH.Address, entities.USDC.Address, &entities.Pair{
+		Address: common.HexToAddress("0x1111"), Token0: entities.USDC, Token1: entities.WETH,
+		Reserve0: big.NewInt(2e12), Reserve1: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	router := NewRouterService(NewPriceService([]dex.DEXClient{v2}, &MockCache{}))
+	oneWETH := big.NewInt(1e18)
+	warmup := NewWarmup(router, []WarmupPair{
+		{TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: oneWETH},
+		{TokenIn: entities.WETH, TokenOut: entities.DAI, AmountIn: oneWETH},
+	})
+
+	if warmup.round(context.Background()) || warmup.Ready() {
+		t.Fatal("ready with WETH/DAI unquoted")
+	}
+	if status := warmup.Status(); status.Warmed != 1 || len(status.Pending) != 1 || status.Pending[0] != "WETH/DAI" {
+		t.Errorf("Status() = %+v", status)
+	}
+
+	// Only the failed pair is retried, and the instance is ready once it quotes
+	v2.SetPair(entities.WETH.Address, entities.DAI.Address, &entities.Pair{
+		Address: common.HexToAddress("0x2222"), Token0: entities.DAI, Token1: entities.WETH,
+		Reserve0: new(big.Int).Mul(big.NewInt(2_000_000), big.NewInt(1e18)), Reserve1: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+	warmup.Run(context.Background(), time.Millisecond, time.Minute)
+	if !warmup.Ready() || warmup.Status().Warmed != 2 {
+		t.Errorf("Status() = %+v after Run", warmup.Status())
+	}
+}
+
+func TestWarmupGivesUpAtDeadline(t *testing.T) {
+	router := NewRouterService(NewPriceService([]dex.DEXClient{NewMockDEXClient(entities.DEXUniswapV2)}, &MockCache{}))
+	warmup := NewWarmup(router, []WarmupPair{{TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1e18)}})
+	warmup.Run(context.Background(), time.Millisecond, 0)
+	if status := warmup.Status(); !status.Ready || status.Warmed != 0 {
+		t.Errorf("Status() = %+v, want ready with nothing warmed", status)
+	}
+
+	if !NewWarmup(router, nil).Ready() {
+		t.Error("an empty hot list isn't ready")
+	}
+}
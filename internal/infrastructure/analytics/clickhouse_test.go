@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestClickHouseSink(t *testing.T) {
+	var queries, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("database") != "analytics" {
+			t.Errorf("endpoint params dropped: %s", r.URL.RawQuery)
+		}
+		body, _ := io.ReadAll(r.Body)
+		queries = append(queries, r.URL.Query().Get("query"))
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	sink, err := NewClickHouseSink(server.URL+"/?database=analytics", "quotes", 30)
+	if err != nil {
+		t.Fatalf("NewClickHouseSink() error = %v", err)
+	}
+	if err := sink.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[0], "CREATE TABLE IF NOT EXISTS quotes") || !strings.Contains(queries[1], "MODIFY TTL toDateTime(ts) + INTERVAL 30 DAY") {
+		t.Fatalf("migration queries = %q", queries)
+	}
+
+	records := []entities.QuoteRecord{{
+		Time:      time.Date(2024, 5, 1, 12, 0, 0, 500e6, time.UTC),
+		TokenIn:   common.HexToAddress("0x01"),
+		TokenOut:  common.HexToAddress("0x02"),
+		AmountIn:  big.NewInt(1000),
+		AmountOut: big.NewInt(990),
+		Venues:    []entities.DEXType{entities.DEXUniswapV2},
+		Strategy:  "greedy",
+		Sources: []entities.SourceDetail{
+			{DEX: entities.DEXUniswapV2, AmountOut: big.NewInt(990), LiquidityScore: 9990},
+			{DEX: entities.DEXCurve, Error: "no pool"},
+		},
+		LatencyMs: 12,
+	}}
+	if err := sink.InsertQuotes(context.Background(), records); err != nil {
+		t.Fatalf("InsertQuotes() error = %v", err)
+	}
+	if queries[2] != "INSERT INTO quotes FORMAT JSONEachRow" {
+		t.Errorf("insert query = %q", queries[2])
+	}
+
+	var row map[string]any
+	if err := json.Unmarshal([]byte(bodies[2]), &row); err != nil {
+		t.Fatalf("insert body %q: %v", bodies[2], err)
+	}
+	if row["ts"] != "2024-05-01 12:00:00.500" || row["amount_in"] != "1000" || row["latency_ms"] != float64(12) {
+		t.Errorf("row = %v", row)
+	}
+	amounts, _ := row["sources.amount_out"].([]any)
+	if len(amounts) != 2 || amounts[0] != "990" || amounts[1] != nil {
+		t.Errorf("sources.amount_out = %v, want [990 null]", row["sources.amount_out"])
+	}
+}
+
+func TestClickHouseSinkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Code: 60. DB::Exception: Table default.quotes does not exist", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	sink, err := NewClickHouseSink(server.URL, "quotes", 30)
+	if err != nil {
+		t.Fatalf("NewClickHouseSink() error = %v", err)
+	}
+	err = sink.InsertQuotes(context.Background(), []entities.QuoteRecord{{}})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("InsertQuotes() error = %v, want the server message", err)
+	}
+
+	if _, err := NewClickHouseSink(server.URL, "quotes; DROP TABLE x", 30); err == nil {
+		t.Error("NewClickHouseSink() accepted an unsafe table name")
+	}
+	if _, err := NewClickHouseSink(server.URL, "quotes", 0); err == nil {
+		t.Error("NewClickHouseSink() accepted zero retention")
+	}
+}
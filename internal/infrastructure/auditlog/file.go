@@ -0,0 +1,93 @@
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// maxEntrySize bounds one JSON line; a ladder quote with pool states for
+// every rung runs to a few hundred KB
+const maxEntrySize = 16 << 20
+
+// FileStore appends audit log entries to a file as JSON lines. The file is
+// only ever appended to, so it can be shipped or rotated by external tools.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileStore opens path for appending, creating it if needed
+func NewFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Append writes entries as one write and syncs them to disk
+func (s *FileStore) Append(ctx context.Context, entries []entities.AuditLogEntry) error {
+	var buf []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit log entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Search scans the whole file, returning matching entries newest first
+func (s *FileStore) Search(ctx context.Context, filter entities.AuditLogFilter) ([]entities.AuditLogEntry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var matches []entities.AuditLogEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var entry entities.AuditLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line cut short by a crash mid-write
+			continue
+		}
+		if filter.Matches(entry) {
+			matches = append(matches, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, nil
+}
+
+// Close closes the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,77 @@
+package auditlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+	at := func(minute int) time.Time { return time.Date(2024, 5, 1, 14, minute, 0, 0, time.UTC) }
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	err = store.Append(ctx, []entities.AuditLogEntry{
+		{Time: at(1), APIKeyID: "k1", Sender: "0x00000000000000000000000000000000000000aA", ResponseHash: "a"},
+		{Time: at(2), APIKeyID: "k2", ClientIP: "10.0.0.1", ResponseHash: "b"},
+	})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	store.Close()
+
+	// Reopening appends after what is there, past a torn last line
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"time":"2024-05-01T14:0`)
+	f.WriteString("\n")
+	f.Close()
+	store, err = NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer store.Close()
+	if err := store.Append(ctx, []entities.AuditLogEntry{{Time: at(3), APIKeyID: "k1", Recipient: "0x00000000000000000000000000000000000000AA", ResponseHash: "c"}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter entities.AuditLogFilter
+		want   []string
+	}{
+		{"all, newest first", entities.AuditLogFilter{}, []string{"c", "b", "a"}},
+		{"api key", entities.AuditLogFilter{APIKeyID: "k1"}, []string{"c", "a"}},
+		{"client ip", entities.AuditLogFilter{ClientIP: "10.0.0.1"}, []string{"b"}},
+		{"sender or recipient", entities.AuditLogFilter{Address: "0x00000000000000000000000000000000000000aa"}, []string{"c", "a"}},
+		{"time range", entities.AuditLogFilter{From: at(2), To: at(2)}, []string{"b"}},
+		{"no match", entities.AuditLogFilter{APIKeyID: "k3"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.Search(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.ResponseHash)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
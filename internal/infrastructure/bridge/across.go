@@ -0,0 +1,76 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+)
+
+const AcrossAPIURL = "https://app.across.to/api"
+
+// AcrossAdapter quotes Across transfers via the suggested-fees API. Relayers
+// front the output on the destination chain, so fills take seconds.
+type AcrossAdapter struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewAcrossAdapter(baseURL string) *AcrossAdapter {
+	return &AcrossAdapter{
+		baseURL: baseURL,
+		client:  httpclient.New(10 * time.Second),
+	}
+}
+
+func (a *AcrossAdapter) Name() string {
+	return "across"
+}
+
+type acrossFees struct {
+	TotalRelayFee struct {
+		Total string `json:"total"`
+	} `json:"totalRelayFee"`
+	IsAmountTooLow       bool   `json:"isAmountTooLow"`
+	EstimatedFillTimeSec uint64 `json:"estimatedFillTimeSec"`
+}
+
+func (a *AcrossAdapter) Quote(ctx context.Context, req Request) (*entities.BridgeQuote, error) {
+	query := url.Values{}
+	query.Set("inputToken", req.TokenIn.Address.Hex())
+	query.Set("outputToken", req.TokenOut.Address.Hex())
+	query.Set("originChainId", strconv.FormatUint(req.SrcChainID, 10))
+	query.Set("destinationChainId", strconv.FormatUint(req.DstChainID, 10))
+	query.Set("amount", req.AmountIn.String())
+
+	var fees acrossFees
+	if err := httpclient.GetJSON(ctx, a.client, a.baseURL+"/suggested-fees?"+query.Encode(), nil, &fees); err != nil {
+		return nil, fmt.Errorf("across: %w", err)
+	}
+	if fees.IsAmountTooLow {
+		return nil, fmt.Errorf("across: amount too low to cover relay fees")
+	}
+
+	fee, err := httpclient.ParseAmount(fees.TotalRelayFee.Total)
+	if err != nil {
+		return nil, fmt.Errorf("across: %w", err)
+	}
+
+	return &entities.BridgeQuote{
+		Bridge:           a.Name(),
+		SrcChainID:       req.SrcChainID,
+		DstChainID:       req.DstChainID,
+		TokenIn:          req.TokenIn,
+		TokenOut:         req.TokenOut,
+		AmountIn:         req.AmountIn,
+		AmountOut:        new(big.Int).Sub(req.AmountIn, fee),
+		Fee:              fee,
+		EstimatedTimeSec: fees.EstimatedFillTimeSec,
+	}, nil
+}
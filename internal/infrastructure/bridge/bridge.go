@@ -0,0 +1,30 @@
+package bridge
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Request asks a bridge to move AmountIn of TokenIn on the source chain to
+// TokenOut on the destination chain
+type Request struct {
+	SrcChainID uint64
+	DstChainID uint64
+	TokenIn    entities.Token
+	TokenOut   entities.Token
+	AmountIn   *big.Int
+	Recipient  common.Address // Optional; some APIs need an address to simulate
+}
+
+// Adapter quotes transfers through one bridge protocol
+type Adapter interface {
+	Name() string
+	Quote(ctx context.Context, req Request) (*entities.BridgeQuote, error)
+}
+
+// quoteAddress stands in for the recipient when the caller has none yet
+var quoteAddress = common.HexToAddress("0x0000000000000000000000000000000000000001")
@@ -0,0 +1,129 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+)
+
+const StargateAPIURL = "https://stargate.finance/api/v1"
+
+// stargateChainKeys maps chain IDs to Stargate's chain identifiers
+var stargateChainKeys = map[uint64]string{
+	entities.ChainEthereum: "ethereum",
+	entities.ChainOptimism: "optimism",
+	entities.ChainPolygon:  "polygon",
+	entities.ChainBase:     "base",
+	entities.ChainArbitrum: "arbitrum",
+}
+
+// StargateAdapter quotes Stargate v2 transfers. The LayerZero messaging fee
+// is reported as NativeFee.
+type StargateAdapter struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewStargateAdapter(baseURL string) *StargateAdapter {
+	return &StargateAdapter{
+		baseURL: baseURL,
+		client:  httpclient.New(10 * time.Second),
+	}
+}
+
+func (a *StargateAdapter) Name() string {
+	return "stargate"
+}
+
+type stargateQuotes struct {
+	Quotes []struct {
+		Route     string  `json:"route"`
+		Error     *string `json:"error"`
+		DstAmount string  `json:"dstAmount"`
+		Duration  struct {
+			Estimated float64 `json:"estimated"`
+		} `json:"duration"`
+		Fees []struct {
+			Amount string `json:"amount"`
+			Type   string `json:"type"`
+		} `json:"fees"`
+	} `json:"quotes"`
+}
+
+func (a *StargateAdapter) Quote(ctx context.Context, req Request) (*entities.BridgeQuote, error) {
+	srcKey, ok := stargateChainKeys[req.SrcChainID]
+	if !ok {
+		return nil, fmt.Errorf("stargate: unsupported chain %d", req.SrcChainID)
+	}
+	dstKey, ok := stargateChainKeys[req.DstChainID]
+	if !ok {
+		return nil, fmt.Errorf("stargate: unsupported chain %d", req.DstChainID)
+	}
+
+	recipient := req.Recipient
+	if recipient == (common.Address{}) {
+		recipient = quoteAddress
+	}
+
+	query := url.Values{}
+	query.Set("srcToken", req.TokenIn.Address.Hex())
+	query.Set("dstToken", req.TokenOut.Address.Hex())
+	query.Set("srcAddress", recipient.Hex())
+	query.Set("dstAddress", recipient.Hex())
+	query.Set("srcChainKey", srcKey)
+	query.Set("dstChainKey", dstKey)
+	query.Set("srcAmount", req.AmountIn.String())
+	query.Set("dstAmountMin", "0")
+
+	var resp stargateQuotes
+	if err := httpclient.GetJSON(ctx, a.client, a.baseURL+"/quotes?"+query.Encode(), nil, &resp); err != nil {
+		return nil, fmt.Errorf("stargate: %w", err)
+	}
+
+	// Several routes (taxi, bus) may be offered; take the best output
+	var best *entities.BridgeQuote
+	for _, q := range resp.Quotes {
+		if q.Error != nil {
+			continue
+		}
+		amountOut, err := httpclient.ParseAmount(q.DstAmount)
+		if err != nil {
+			continue
+		}
+
+		nativeFee := new(big.Int)
+		for _, fee := range q.Fees {
+			if amount, err := httpclient.ParseAmount(fee.Amount); err == nil {
+				nativeFee.Add(nativeFee, amount)
+			}
+		}
+
+		if best == nil || amountOut.Cmp(best.AmountOut) > 0 {
+			best = &entities.BridgeQuote{
+				Bridge:           a.Name(),
+				SrcChainID:       req.SrcChainID,
+				DstChainID:       req.DstChainID,
+				TokenIn:          req.TokenIn,
+				TokenOut:         req.TokenOut,
+				AmountIn:         req.AmountIn,
+				AmountOut:        amountOut,
+				Fee:              new(big.Int).Sub(req.AmountIn, amountOut),
+				NativeFee:        nativeFee,
+				EstimatedTimeSec: uint64(q.Duration.Estimated),
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("stargate: no route available")
+	}
+	return best, nil
+}
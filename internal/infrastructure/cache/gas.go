@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// gasStatsKey holds the learned gas medians. It is outside the pair: and
+// price: prefixes so a reorg flush keeps it.
+const gasStatsKey = "gas_stats"
+
+func (c *RedisCache) LoadGasStats(ctx context.Context) ([]entities.GasStats, error) {
+	data, err := c.client.Get(ctx, gasStatsKey).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var stats []entities.GasStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *RedisCache) SaveGasStats(ctx context.Context, stats []entities.GasStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, gasStatsKey, data, 0).Err()
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockKeyPrefix namespaces locks outside the pair: and price: prefixes, so
+// a reorg flush keeps them
+const lockKeyPrefix = "lock:"
+
+// Only the owner may extend or release a lock; another instance may have
+// taken it since it expired
+var (
+	extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLocker holds named locks that expire unless extended, shared by
+// every instance connected to the same Redis. A lock is a key holding its
+// owner's ID.
+type RedisLocker struct {
+	client *redis.Client
+}
+
+func NewRedisLocker(client *redis.Client) *RedisLocker {
+	return &RedisLocker{client: client}
+}
+
+// Acquire takes the lock for owner when no one holds it
+func (l *RedisLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return l.client.SetNX(ctx, lockKeyPrefix+name, owner, ttl).Result()
+}
+
+// Extend renews the lock for ttl, if owner still holds it
+func (l *RedisLocker) Extend(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	renewed, err := extendLockScript.Run(ctx, l.client, []string{lockKeyPrefix + name}, owner, ttl.Milliseconds()).Int()
+	return renewed == 1, err
+}
+
+// Release drops the lock, if owner still holds it
+func (l *RedisLocker) Release(ctx context.Context, name, owner string) error {
+	return releaseLockScript.Run(ctx, l.client, []string{lockKeyPrefix + name}, owner).Err()
+}
@@ -17,6 +17,8 @@ type Cache interface {
 	GetPrice(ctx context.Context, key string) (string, error)
 	SetPrice(ctx context.Context, key string, price string, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
+	// Flush drops every cached pair and price, e.g. after a reorg
+	Flush(ctx context.Context) error
 }
 
 type RedisCache struct {
@@ -40,6 +42,11 @@ func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
 	return &RedisCache{client: client}, nil
 }
 
+// Client exposes the connection for other Redis-backed stores
+func (c *RedisCache) Client() *redis.Client {
+	return c.client
+}
+
 func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
@@ -91,6 +98,21 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
 
+func (c *RedisCache) Flush(ctx context.Context) error {
+	for _, pattern := range []string{"pair:*", "price:*"} {
+		iter := c.client.Scan(ctx, 0, pattern, 1000).Iterator()
+		for iter.Next(ctx) {
+			if err := c.client.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func PairCacheKey(dex entities.DEXType, token0, token1 string) string {
 	return fmt.Sprintf("pair:%s:%s:%s", dex, token0, token1)
 }
@@ -163,3 +185,9 @@ func (c *InMemoryCache) Delete(ctx context.Context, key string) error {
 	delete(c.prices, key)
 	return nil
 }
+
+func (c *InMemoryCache) Flush(ctx context.Context) error {
+	c.pairs = make(map[string]*cachedPair)
+	c.prices = make(map[string]*cachedPrice)
+	return nil
+}
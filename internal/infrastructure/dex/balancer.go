@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
 	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
@@ -18,19 +19,39 @@ var (
 	BalancerVaultAddress = common.HexToAddress("0xBA12222222228d8Ba445958a75a0704d566BF2C8")
 )
 
+// Rates, fees and targets read from pools are 18-decimal fixed point;
+// targets are in main token units
 var (
-	// getPoolTokens(bytes32 poolId) returns (address[] tokens, uint256[] balances, uint256 lastChangeBlock)
-	getPoolTokensSelector = common.Hex2Bytes("f94d4668")
-	// queryBatchSwap(uint8 kind, SwapStep[] swaps, address[] assets, FundManagement funds)
+	balancerVault = bindings.NewBalancerVault()
+	balancerPool  = bindings.NewBalancerPool()
+)
+
+// BalancerPoolType selects the math a pool is priced with
+type BalancerPoolType int
+
+const (
+	BalancerWeighted BalancerPoolType = iota
+	// BalancerStable is a StablePool or MetaStablePool
+	BalancerStable
+	// BalancerBoosted is a phantom stable pool of linear pool BPTs, which
+	// lists its own BPT among its tokens
+	BalancerBoosted
+	// BalancerLinear pairs a main token with its yield-bearing wrapper and is
+	// only routed through as part of a boosted pool
+	BalancerLinear
 )
 
 type BalancerPool struct {
-	PoolID  [32]byte
-	Address common.Address
-	Tokens  []common.Address
-	Weights []uint64 // Weights in basis points (e.g., 5000 = 50%)
-	SwapFee uint64   // Fee in basis points
-	Name    string
+	PoolID   [32]byte
+	Address  common.Address
+	Type     BalancerPoolType
+	Tokens   []common.Address // Vault order
+	Weights  []uint64         // Weighted pools: weights in basis points (e.g., 5000 = 50%)
+	Decimals []uint8          // Stable pools: token decimals in Tokens order
+	SwapFee  uint64           // Fee in basis points, used until the pool's own is read
+	Name     string
+	Main     common.Address // Linear pools: the underlying token
+	Linear   []BalancerPool // Boosted pools: the linear pools behind their BPTs
 }
 
 var balancerPools = []BalancerPool{
@@ -58,12 +79,100 @@ var balancerPools = []BalancerPool{
 		SwapFee: 30,                   // 0.3%
 		Name:    "WETH/USDC 50/50",
 	},
+	{
+		// staBAL3 DAI/USDC/USDT stable pool
+		PoolID:  hexToBytes32("0x06df3b2bbb68adc8b0e302443692037ed9f91b42000000000000000000000063"),
+		Address: common.HexToAddress("0x06Df3b2bbB68adc8B0e302443692037ED9f91b42"),
+		Type:    BalancerStable,
+		Tokens: []common.Address{
+			entities.DAI.Address,
+			entities.USDC.Address,
+			entities.USDT.Address,
+		},
+		Decimals: []uint8{18, 6, 6},
+		SwapFee:  1, // 0.01%
+		Name:     "staBAL3",
+	},
+	{
+		// bb-a-USD: Aave boosted DAI/USDC/USDT (example)
+		PoolID:  hexToBytes32("0x7b50775383d3d6f0215a8f290f2c9e2eebbeceb20000000000000000000000fe"),
+		Address: common.HexToAddress("0x7B50775383d3D6f0215A8F290f2C9e2eEBBEceb2"),
+		Type:    BalancerBoosted,
+		Tokens: []common.Address{
+			common.HexToAddress("0x2BBf681cC4eb09218BEe85EA2a5d3D13Fa40fC0C"), // bb-a-USDT
+			common.HexToAddress("0x7B50775383d3D6f0215A8F290f2C9e2eEBBEceb2"), // bb-a-USD
+			common.HexToAddress("0x804CdB9116a10bB78768D3252355a1b18067bF8f"), // bb-a-DAI
+			common.HexToAddress("0x9210F1204b5a24742Eba12f710636D76240dF3d0"), // bb-a-USDC
+		},
+		SwapFee: 0, // 0.001%, below basis point resolution
+		Name:    "bb-a-USD",
+		Linear: []BalancerPool{
+			{
+				PoolID:  hexToBytes32("0x2bbf681cc4eb09218bee85ea2a5d3d13fa40fc0c0000000000000000000000fd"),
+				Address: common.HexToAddress("0x2BBf681cC4eb09218BEe85EA2a5d3D13Fa40fC0C"),
+				Type:    BalancerLinear,
+				Tokens: []common.Address{
+					common.HexToAddress("0x2BBf681cC4eb09218BEe85EA2a5d3D13Fa40fC0C"),
+					entities.USDT.Address,
+					common.HexToAddress("0xf8Fd466F12e236f4c96F7Cce6c79EAdB819abF58"), // waUSDT
+				},
+				Main: entities.USDT.Address,
+				Name: "bb-a-USDT",
+			},
+			{
+				PoolID:  hexToBytes32("0x804cdb9116a10bb78768d3252355a1b18067bf8f0000000000000000000000fb"),
+				Address: common.HexToAddress("0x804CdB9116a10bB78768D3252355a1b18067bF8f"),
+				Type:    BalancerLinear,
+				Tokens: []common.Address{
+					common.HexToAddress("0x02d60b84491589974263d922D9cC7a3152618Ef6"), // waDAI
+					entities.DAI.Address,
+					common.HexToAddress("0x804CdB9116a10bB78768D3252355a1b18067bF8f"),
+				},
+				Main: entities.DAI.Address,
+				Name: "bb-a-DAI",
+			},
+			{
+				PoolID:  hexToBytes32("0x9210f1204b5a24742eba12f710636d76240df3d00000000000000000000000fc"),
+				Address: common.HexToAddress("0x9210F1204b5a24742Eba12f710636D76240dF3d0"),
+				Type:    BalancerLinear,
+				Tokens: []common.Address{
+					common.HexToAddress("0x9210F1204b5a24742Eba12f710636D76240dF3d0"),
+					entities.USDC.Address,
+					common.HexToAddress("0xd093fA4Fb80D09bB30817FDcd442d4d02eD3E5de"), // waUSDC
+				},
+				Main: entities.USDC.Address,
+				Name: "bb-a-USDC",
+			},
+		},
+	},
+}
+
+// index locates token in the pool. Tokens of a boosted pool are also found
+// through the linear pool whose main token they are, which is returned.
+func (p *BalancerPool) index(token common.Address) (int, *BalancerPool) {
+	for i, t := range p.Tokens {
+		if t == token && !(p.Type == BalancerBoosted && t == p.Address) {
+			return i, nil
+		}
+	}
+	for i := range p.Linear {
+		if p.Linear[i].Main != token {
+			continue
+		}
+		for j, t := range p.Tokens {
+			if t == p.Linear[i].Address {
+				return j, &p.Linear[i]
+			}
+		}
+	}
+	return -1, nil
 }
 
 type BalancerClient struct {
 	ethClient *ethclient.Client
 	vault     common.Address
 	pools     []BalancerPool
+	fees      feeCache
 }
 
 func NewBalancerClient(ethClient *ethclient.Client) *BalancerClient {
@@ -74,133 +183,289 @@ func NewBalancerClient(ethClient *ethclient.Client) *BalancerClient {
 	}
 }
 
-func (c *BalancerClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
-	for _, pool := range c.pools {
-		hasA, hasB := false, false
-		for _, token := range pool.Tokens {
-			if token == tokenA {
-				hasA = true
-			}
-			if token == tokenB {
-				hasB = true
-			}
-		}
-		if hasA && hasB {
-			return pool.Address, nil
+// findPools returns the pools that can swap tokenA for tokenB
+func (c *BalancerClient) findPools(tokenA, tokenB common.Address) []*BalancerPool {
+	var pools []*BalancerPool
+	for i := range c.pools {
+		idxA, _ := c.pools[i].index(tokenA)
+		idxB, _ := c.pools[i].index(tokenB)
+		if idxA != -1 && idxB != -1 && idxA != idxB {
+			pools = append(pools, &c.pools[i])
 		}
 	}
-	return common.Address{}, fmt.Errorf("no Balancer pool found for token pair")
+	return pools
 }
 
-func (c *BalancerClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
-	var pool *BalancerPool
-	for i := range c.pools {
-		hasA, hasB := false, false
-		for _, token := range c.pools[i].Tokens {
-			if token == tokenA.Address {
-				hasA = true
-			}
-			if token == tokenB.Address {
-				hasB = true
-			}
-		}
-		if hasA && hasB {
-			pool = &c.pools[i]
-			break
-		}
+func (c *BalancerClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
+	pools := c.findPools(tokenA, tokenB)
+	if len(pools) == 0 {
+		return common.Address{}, fmt.Errorf("%w: no Balancer pool for token pair", ErrPoolNotFound)
 	}
-	if pool == nil {
-		return nil, fmt.Errorf("no Balancer pool found for token pair")
+	return pools[0].Address, nil
+}
+
+// GetPairByTokens reads every pool holding the pair and returns the one
+// with the deepest token0 reserve
+func (c *BalancerClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	pools := c.findPools(tokenA.Address, tokenB.Address)
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("%w: no Balancer pool for token pair", ErrPoolNotFound)
 	}
 
-	balances, err := c.getPoolTokens(ctx, pool.PoolID)
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pool tokens: %w", err)
+		return nil, fmt.Errorf("failed to get block number: %w", err)
 	}
 
-	idxA, idxB := -1, -1
-	for i, token := range pool.Tokens {
-		if token == tokenA.Address {
-			idxA = i
+	token0, token1 := tokenA, tokenB
+	if tokenB.Address.Hex() < tokenA.Address.Hex() {
+		token0, token1 = tokenB, tokenA
+	}
+
+	var best *entities.Pair
+	var lastErr error
+	for _, pool := range pools {
+		pair, err := c.readPair(ctx, pool, token0, token1, blockNumber)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", pool.Name, err)
+			continue
 		}
-		if token == tokenB.Address {
-			idxB = i
+		if best == nil || pair.Reserve0.Cmp(best.Reserve0) > 0 {
+			best = pair
 		}
 	}
-	if idxA == -1 || idxB == -1 || idxA >= len(balances) || idxB >= len(balances) {
-		return nil, fmt.Errorf("token not found in pool")
+	if best == nil {
+		return nil, lastErr
 	}
+	best.BlockNumber = blockNumber
+	return best, nil
+}
 
-	var token0, token1 entities.Token
-	var reserve0, reserve1 *big.Int
-	if tokenA.Address.Hex() < tokenB.Address.Hex() {
-		token0, token1 = tokenA, tokenB
-		reserve0, reserve1 = balances[idxA], balances[idxB]
-	} else {
-		token0, token1 = tokenB, tokenA
-		reserve0, reserve1 = balances[idxB], balances[idxA]
+// readPair reads one pool's balances and swap fee, and for stable pools the
+// parameters of their invariant
+func (c *BalancerClient) readPair(ctx context.Context, pool *BalancerPool, token0, token1 entities.Token, blockNumber uint64) (*entities.Pair, error) {
+	balances, err := c.getPoolTokens(ctx, pool.PoolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool tokens: %w", err)
+	}
+	if len(balances) != len(pool.Tokens) {
+		return nil, fmt.Errorf("pool has %d balances, expected %d", len(balances), len(pool.Tokens))
 	}
 
-	return &entities.Pair{
+	idx0, linear0 := pool.index(token0.Address)
+	idx1, linear1 := pool.index(token1.Address)
+	pair := &entities.Pair{
 		Address:   pool.Address,
 		Token0:    token0,
 		Token1:    token1,
-		Reserve0:  reserve0,
-		Reserve1:  reserve1,
+		Reserve0:  balances[idx0],
+		Reserve1:  balances[idx1],
 		DEX:       entities.DEXBalancer,
-		Fee:       pool.SwapFee,
+		Fee:       c.swapFee(ctx, pool, blockNumber),
 		UpdatedAt: time.Now().Unix(),
-	}, nil
+		BalancerSwap: &entities.BalancerSwap{
+			PoolID:  pool.PoolID,
+			Linear0: linearStep(linear0),
+			Linear1: linearStep(linear1),
+		},
+	}
+	if pool.Type == BalancerWeighted {
+		return pair, nil
+	}
+
+	curve, reserve0, reserve1, err := c.stableCurve(ctx, pool, balances, token0, token1)
+	if err != nil {
+		return nil, err
+	}
+	pair.Stable = curve
+	pair.Reserve0, pair.Reserve1 = reserve0, reserve1
+	return pair, nil
 }
 
-// Uses the weighted math formula: outAmount = balanceOut * (1 - (balanceIn / (balanceIn + amountIn))^(weightIn/weightOut))
-func (c *BalancerClient) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
-	var pool *BalancerPool
-	for i := range c.pools {
-		hasIn, hasOut := false, false
-		for _, token := range c.pools[i].Tokens {
-			if token == tokenIn.Address {
-				hasIn = true
+// linearStep is the Vault swap step through a linear pool, if any
+func linearStep(pool *BalancerPool) *entities.BalancerLinearStep {
+	if pool == nil {
+		return nil
+	}
+	return &entities.BalancerLinearStep{PoolID: pool.PoolID, BPT: pool.Address}
+}
+
+// stableCurve assembles the StableSwap parameters for a stable or boosted
+// pool. The returned reserves are in token units, counting a linear pool's
+// wrapped balance at its rate.
+func (c *BalancerClient) stableCurve(ctx context.Context, pool *BalancerPool, balances []*big.Int, token0, token1 entities.Token) (*entities.StableCurve, *big.Int, *big.Int, error) {
+	amp, err := c.getAmp(ctx, pool.Address)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Upscale every balance; a boosted pool's own BPT is not part of the
+	// invariant and is dropped
+	scales := make([]*big.Int, len(pool.Tokens))
+	upscaled := make([]*big.Int, 0, len(pool.Tokens))
+	positions := make([]int, len(pool.Tokens))
+	for i, token := range pool.Tokens {
+		positions[i] = -1
+		switch {
+		case pool.Type == BalancerBoosted && token == pool.Address:
+			continue
+		case pool.Type == BalancerBoosted:
+			// Linear BPTs have 18 decimals and are scaled by their rate
+			rate, err := callView(ctx, c.ethClient, token, balancerPool.PackGetRate(), balancerPool.UnpackGetRate)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to get BPT rate: %w", err)
 			}
-			if token == tokenOut.Address {
-				hasOut = true
+			scales[i] = rate
+		default:
+			if i >= len(pool.Decimals) {
+				return nil, nil, nil, fmt.Errorf("missing decimals for token %d", i)
 			}
+			scales[i] = decimalScale(pool.Decimals[i])
 		}
-		if hasIn && hasOut {
-			pool = &c.pools[i]
-			break
-		}
+		positions[i] = len(upscaled)
+		upscaled = append(upscaled, mulDown(balances[i], scales[i]))
 	}
-	if pool == nil {
-		return nil, fmt.Errorf("no Balancer pool found")
+
+	curve := &entities.StableCurve{Amp: amp, Balances: upscaled}
+	reserves := [2]*big.Int{}
+	for side, token := range []entities.Token{token0, token1} {
+		idx, linear := pool.index(token.Address)
+		scale := scales[idx]
+		reserve := balances[idx]
+
+		var leg *entities.LinearLeg
+		if linear != nil {
+			leg, err = c.linearLeg(ctx, linear, token)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("%s: %w", linear.Name, err)
+			}
+			reserve = divDown(new(big.Int).Add(leg.MainBalance, leg.WrappedBalance), leg.MainScale)
+		}
+
+		if side == 0 {
+			curve.Index0, curve.Scale0, curve.Linear0 = positions[idx], scale, leg
+		} else {
+			curve.Index1, curve.Scale1, curve.Linear1 = positions[idx], scale, leg
+		}
+		reserves[side] = reserve
 	}
+	return curve, reserves[0], reserves[1], nil
+}
 
+// linearLeg reads a linear pool whose main token is main. The wrapped
+// token is assumed to share the main token's decimals, as Aave's static
+// aToken wrappers do.
+func (c *BalancerClient) linearLeg(ctx context.Context, pool *BalancerPool, main entities.Token) (*entities.LinearLeg, error) {
 	balances, err := c.getPoolTokens(ctx, pool.PoolID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get pool tokens: %w", err)
 	}
-
-	idxIn, idxOut := -1, -1
+	if len(balances) != len(pool.Tokens) {
+		return nil, fmt.Errorf("pool has %d balances, expected %d", len(balances), len(pool.Tokens))
+	}
+	mainIdx, wrappedIdx := -1, -1
 	for i, token := range pool.Tokens {
-		if token == tokenIn.Address {
-			idxIn = i
+		switch token {
+		case pool.Main:
+			mainIdx = i
+		case pool.Address:
+		default:
+			wrappedIdx = i
+		}
+	}
+	if mainIdx == -1 || wrappedIdx == -1 {
+		return nil, fmt.Errorf("linear pool is missing its main or wrapped token")
+	}
+
+	targets, err := callView(ctx, c.ethClient, pool.Address, balancerPool.PackGetTargets(), balancerPool.UnpackGetTargets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get targets: %w", err)
+	}
+	supply, err := callView(ctx, c.ethClient, pool.Address, balancerPool.PackGetVirtualSupply(), balancerPool.UnpackGetVirtualSupply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get virtual supply: %w", err)
+	}
+	wrappedRate, err := callView(ctx, c.ethClient, pool.Address, balancerPool.PackGetWrappedTokenRate(), balancerPool.UnpackGetWrappedTokenRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wrapped token rate: %w", err)
+	}
+	fee, err := callView(ctx, c.ethClient, pool.Address, balancerPool.PackGetSwapFeePercentage(), balancerPool.UnpackGetSwapFeePercentage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get swap fee: %w", err)
+	}
+
+	mainScale := decimalScale(main.Decimals)
+	return &entities.LinearLeg{
+		MainBalance:    mulDown(balances[mainIdx], mainScale),
+		WrappedBalance: mulDown(balances[wrappedIdx], mulDown(mainScale, wrappedRate)),
+		VirtualSupply:  supply,
+		LowerTarget:    mulDown(targets.LowerTarget, mainScale),
+		UpperTarget:    mulDown(targets.UpperTarget, mainScale),
+		Fee:            fee,
+		MainScale:      mainScale,
+	}, nil
+}
+
+// swapFee returns the pool's swap fee in basis points at blockNumber. The
+// pool owner can change it at any time, so it is read rather than taken
+// from the pool's configuration.
+func (c *BalancerClient) swapFee(ctx context.Context, pool *BalancerPool, blockNumber uint64) uint64 {
+	return c.fees.fee(pool.Address, blockNumber, pool.SwapFee, func() (uint64, error) {
+		fee, err := callView(ctx, c.ethClient, pool.Address, balancerPool.PackGetSwapFeePercentage(), balancerPool.UnpackGetSwapFeePercentage)
+		if err != nil {
+			return 0, err
 		}
-		if token == tokenOut.Address {
-			idxOut = i
+		// 18-decimal fraction to basis points
+		return new(big.Int).Div(fee, big.NewInt(1e14)).Uint64(), nil
+	})
+}
+
+// getAmp returns the amplification at entities.AmpPrecision
+func (c *BalancerClient) getAmp(ctx context.Context, pool common.Address) (*big.Int, error) {
+	result, err := callView(ctx, c.ethClient, pool, balancerPool.PackGetAmplificationParameter(), balancerPool.UnpackGetAmplificationParameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amplification: %w", err)
+	}
+	amp, precision := new(big.Int).Set(result.Value), result.Precision
+	if precision.Sign() == 0 {
+		return nil, fmt.Errorf("invalid amplification precision")
+	}
+	amp.Mul(amp, big.NewInt(entities.AmpPrecision))
+	return amp.Div(amp, precision), nil
+}
+
+// GetAmountOut prices amountIn against the pool GetPairByTokens selects.
+// Weighted pools use the weighted math formula: outAmount = balanceOut * (1 - (balanceIn / (balanceIn + amountIn))^(weightIn/weightOut))
+func (c *BalancerClient) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	pair, err := c.GetPairByTokens(ctx, tokenIn, tokenOut)
+	if err != nil {
+		return nil, err
+	}
+	if pair.Stable != nil {
+		return pair.GetAmountOut(amountIn, tokenIn.Address), nil
+	}
+
+	var pool *BalancerPool
+	for i := range c.pools {
+		if c.pools[i].Address == pair.Address {
+			pool = &c.pools[i]
+			break
 		}
 	}
-	if idxIn == -1 || idxOut == -1 {
-		return nil, fmt.Errorf("token not found in pool")
+	if pool == nil {
+		return nil, fmt.Errorf("%w: no Balancer pool for token pair", ErrPoolNotFound)
 	}
+	idxIn, _ := pool.index(tokenIn.Address)
+	idxOut, _ := pool.index(tokenOut.Address)
 
-	balanceIn := balances[idxIn]
-	balanceOut := balances[idxOut]
-	weightIn := pool.Weights[idxIn]
-	weightOut := pool.Weights[idxOut]
+	balanceIn, balanceOut := pair.Reserve0, pair.Reserve1
+	if tokenIn.Address != pair.Token0.Address {
+		balanceIn, balanceOut = balanceOut, balanceIn
+	}
 
 	// For weighted pools: amountOut = balanceOut * (1 - (balanceIn / (balanceIn + amountIn * (1 - fee)))^(wIn/wOut))
 	// Simplified for equal weights: amountOut ≈ balanceOut * amountIn * (1 - fee) / (balanceIn + amountIn * (1 - fee))
-	return c.calcOutGivenIn(balanceIn, balanceOut, amountIn, weightIn, weightOut, pool.SwapFee), nil
+	return c.calcOutGivenIn(balanceIn, balanceOut, amountIn, pool.Weights[idxIn], pool.Weights[idxOut], pair.Fee), nil
 }
 
 // calcOutGivenIn calculates output amount using weighted math
@@ -241,44 +506,51 @@ func (c *BalancerClient) DEXType() entities.DEXType {
 	return entities.DEXBalancer
 }
 
+// Capabilities returns what the Balancer vault supports. Pairs hold
+// balances without the pool weights, so GetAmountOut prices them.
+func (c *BalancerClient) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsExactOut:       true, // GIVEN_OUT swaps
+		SupportsMultiHopNative: true, // batchSwap
+		FeeModel:               FeeDynamic,
+		NeedsOnchainQuote:      true,
+	}
+}
+
 // getPoolTokens fetches token balances from the vault
 func (c *BalancerClient) getPoolTokens(ctx context.Context, poolID [32]byte) ([]*big.Int, error) {
-	// Encode getPoolTokens(poolId)
-	data := make([]byte, 36)
-	copy(data[0:4], getPoolTokensSelector)
-	copy(data[4:36], poolID[:])
-
 	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
 		To:   &c.vault,
-		Data: data,
+		Data: balancerVault.PackGetPoolTokens(poolID),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("getPoolTokens call failed: %w", err)
 	}
 
-	// Parse result - returns (address[] tokens, uint256[] balances, uint256 lastChangeBlock)
-	if len(result) < 192 {
-		return nil, fmt.Errorf("invalid getPoolTokens response length")
+	tokens, err := balancerVault.UnpackGetPoolTokens(result)
+	if err != nil {
+		return nil, fmt.Errorf("invalid getPoolTokens response: %w", err)
 	}
+	return tokens.Balances, nil
+}
 
-	// Skip to balances array (offset at position 32-64)
-	balancesOffset := new(big.Int).SetBytes(result[32:64]).Uint64()
-	if balancesOffset+32 > uint64(len(result)) {
-		return nil, fmt.Errorf("invalid balances offset")
+// decimalScale upscales a token with the given decimals to 18, as an
+// 18-decimal fixed-point factor
+func decimalScale(decimals uint8) *big.Int {
+	if decimals >= 18 {
+		return big.NewInt(1e18)
 	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(36-decimals)), nil)
+}
 
-	balancesLen := new(big.Int).SetBytes(result[balancesOffset : balancesOffset+32]).Uint64()
-
-	balances := make([]*big.Int, balancesLen)
-	for i := uint64(0); i < balancesLen; i++ {
-		start := balancesOffset + 32 + i*32
-		if start+32 > uint64(len(result)) {
-			return nil, fmt.Errorf("invalid balance data")
-		}
-		balances[i] = new(big.Int).SetBytes(result[start : start+32])
-	}
+func mulDown(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, b)
+	return out.Div(out, big.NewInt(1e18))
+}
 
-	return balances, nil
+func divDown(a, b *big.Int) *big.Int {
+	out := new(big.Int).Mul(a, big.NewInt(1e18))
+	return out.Div(out, b)
 }
 
 // hexToBytes32 converts a hex string to [32]byte
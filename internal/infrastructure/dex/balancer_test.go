@@ -0,0 +1,53 @@
+package dex
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestBalancerFindPools(t *testing.T) {
+	c := NewBalancerClient(nil)
+	bbaUSD := common.HexToAddress("0x7B50775383d3D6f0215A8F290f2C9e2eEBBEceb2")
+
+	tests := []struct {
+		name string
+		a, b common.Address
+		want []string
+	}{
+		{"weighted", entities.WETH.Address, entities.DAI.Address, []string{"WETH/DAI 60/40"}},
+		{"stable and boosted", entities.USDC.Address, entities.DAI.Address, []string{"staBAL3", "bb-a-USD"}},
+		{"boosted pool BPT is not tradable", entities.USDC.Address, bbaUSD, nil},
+		{"no pool", entities.WETH.Address, entities.USDT.Address, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pools := c.findPools(tt.a, tt.b)
+			if len(pools) != len(tt.want) {
+				t.Fatalf("findPools() returned %d pools, want %v", len(pools), tt.want)
+			}
+			for i, pool := range pools {
+				if pool.Name != tt.want[i] {
+					t.Errorf("pool %d = %s, want %s", i, pool.Name, tt.want[i])
+				}
+			}
+		})
+	}
+
+	boosted := c.findPools(entities.USDC.Address, entities.DAI.Address)[1]
+	idx, linear := boosted.index(entities.USDC.Address)
+	if linear == nil || linear.Name != "bb-a-USDC" || boosted.Tokens[idx] != linear.Address {
+		t.Errorf("index(USDC) = %d, %v, want the bb-a-USDC linear pool", idx, linear)
+	}
+}
+
+func TestDecimalScale(t *testing.T) {
+	if got := decimalScale(6).String(); got != "1000000000000000000000000000000" {
+		t.Errorf("decimalScale(6) = %s, want 1e30", got)
+	}
+	if got := decimalScale(18).String(); got != "1000000000000000000" {
+		t.Errorf("decimalScale(18) = %s, want 1e18", got)
+	}
+}
@@ -0,0 +1,281 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// BalancerPoolMetaData contains all meta data concerning the BalancerPool contract.
+var BalancerPoolMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"getAmplificationParameter\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"value\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isUpdating\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"precision\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getRate\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getTargets\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"lowerTarget\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"upperTarget\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getVirtualSupply\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getWrappedTokenRate\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getSwapFeePercentage\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "BalancerPool",
+}
+
+// BalancerPool is an auto generated Go binding around an Ethereum contract.
+type BalancerPool struct {
+	abi abi.ABI
+}
+
+// NewBalancerPool creates a new instance of BalancerPool.
+func NewBalancerPool() *BalancerPool {
+	parsed, err := BalancerPoolMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &BalancerPool{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *BalancerPool) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGetAmplificationParameter is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x6daccffa.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (balancerPool *BalancerPool) PackGetAmplificationParameter() []byte {
+	enc, err := balancerPool.abi.Pack("getAmplificationParameter")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetAmplificationParameter is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x6daccffa.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (balancerPool *BalancerPool) TryPackGetAmplificationParameter() ([]byte, error) {
+	return balancerPool.abi.Pack("getAmplificationParameter")
+}
+
+// GetAmplificationParameterOutput serves as a container for the return parameters of contract
+// method GetAmplificationParameter.
+type GetAmplificationParameterOutput struct {
+	Value      *big.Int
+	IsUpdating bool
+	Precision  *big.Int
+}
+
+// UnpackGetAmplificationParameter is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x6daccffa.
+//
+// Solidity: function getAmplificationParameter() view returns(uint256 value, bool isUpdating, uint256 precision)
+func (balancerPool *BalancerPool) UnpackGetAmplificationParameter(data []byte) (GetAmplificationParameterOutput, error) {
+	out, err := balancerPool.abi.Unpack("getAmplificationParameter", data)
+	outstruct := new(GetAmplificationParameterOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.Value = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	outstruct.IsUpdating = *abi.ConvertType(out[1], new(bool)).(*bool)
+	outstruct.Precision = abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+	return *outstruct, nil
+}
+
+// PackGetRate is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x679aefce.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getRate() view returns(uint256)
+func (balancerPool *BalancerPool) PackGetRate() []byte {
+	enc, err := balancerPool.abi.Pack("getRate")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetRate is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x679aefce.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getRate() view returns(uint256)
+func (balancerPool *BalancerPool) TryPackGetRate() ([]byte, error) {
+	return balancerPool.abi.Pack("getRate")
+}
+
+// UnpackGetRate is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x679aefce.
+//
+// Solidity: function getRate() view returns(uint256)
+func (balancerPool *BalancerPool) UnpackGetRate(data []byte) (*big.Int, error) {
+	out, err := balancerPool.abi.Unpack("getRate", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetSwapFeePercentage is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x55c67628.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (balancerPool *BalancerPool) PackGetSwapFeePercentage() []byte {
+	enc, err := balancerPool.abi.Pack("getSwapFeePercentage")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetSwapFeePercentage is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x55c67628.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (balancerPool *BalancerPool) TryPackGetSwapFeePercentage() ([]byte, error) {
+	return balancerPool.abi.Pack("getSwapFeePercentage")
+}
+
+// UnpackGetSwapFeePercentage is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x55c67628.
+//
+// Solidity: function getSwapFeePercentage() view returns(uint256)
+func (balancerPool *BalancerPool) UnpackGetSwapFeePercentage(data []byte) (*big.Int, error) {
+	out, err := balancerPool.abi.Unpack("getSwapFeePercentage", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetTargets is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x63fe3b56.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getTargets() view returns(uint256 lowerTarget, uint256 upperTarget)
+func (balancerPool *BalancerPool) PackGetTargets() []byte {
+	enc, err := balancerPool.abi.Pack("getTargets")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetTargets is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x63fe3b56.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getTargets() view returns(uint256 lowerTarget, uint256 upperTarget)
+func (balancerPool *BalancerPool) TryPackGetTargets() ([]byte, error) {
+	return balancerPool.abi.Pack("getTargets")
+}
+
+// GetTargetsOutput serves as a container for the return parameters of contract
+// method GetTargets.
+type GetTargetsOutput struct {
+	LowerTarget *big.Int
+	UpperTarget *big.Int
+}
+
+// UnpackGetTargets is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x63fe3b56.
+//
+// Solidity: function getTargets() view returns(uint256 lowerTarget, uint256 upperTarget)
+func (balancerPool *BalancerPool) UnpackGetTargets(data []byte) (GetTargetsOutput, error) {
+	out, err := balancerPool.abi.Unpack("getTargets", data)
+	outstruct := new(GetTargetsOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.LowerTarget = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	outstruct.UpperTarget = abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	return *outstruct, nil
+}
+
+// PackGetVirtualSupply is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xde82cd34.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getVirtualSupply() view returns(uint256)
+func (balancerPool *BalancerPool) PackGetVirtualSupply() []byte {
+	enc, err := balancerPool.abi.Pack("getVirtualSupply")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetVirtualSupply is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xde82cd34.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getVirtualSupply() view returns(uint256)
+func (balancerPool *BalancerPool) TryPackGetVirtualSupply() ([]byte, error) {
+	return balancerPool.abi.Pack("getVirtualSupply")
+}
+
+// UnpackGetVirtualSupply is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xde82cd34.
+//
+// Solidity: function getVirtualSupply() view returns(uint256)
+func (balancerPool *BalancerPool) UnpackGetVirtualSupply(data []byte) (*big.Int, error) {
+	out, err := balancerPool.abi.Unpack("getVirtualSupply", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetWrappedTokenRate is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf5431aa8.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getWrappedTokenRate() view returns(uint256)
+func (balancerPool *BalancerPool) PackGetWrappedTokenRate() []byte {
+	enc, err := balancerPool.abi.Pack("getWrappedTokenRate")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetWrappedTokenRate is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf5431aa8.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getWrappedTokenRate() view returns(uint256)
+func (balancerPool *BalancerPool) TryPackGetWrappedTokenRate() ([]byte, error) {
+	return balancerPool.abi.Pack("getWrappedTokenRate")
+}
+
+// UnpackGetWrappedTokenRate is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf5431aa8.
+//
+// Solidity: function getWrappedTokenRate() view returns(uint256)
+func (balancerPool *BalancerPool) UnpackGetWrappedTokenRate(data []byte) (*big.Int, error) {
+	out, err := balancerPool.abi.Unpack("getWrappedTokenRate", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
@@ -0,0 +1,97 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// BalancerVaultMetaData contains all meta data concerning the BalancerVault contract.
+var BalancerVaultMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"getPoolTokens\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"poolId\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"tokens\",\"type\":\"address[]\",\"internalType\":\"contractIERC20[]\"},{\"name\":\"balances\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"},{\"name\":\"lastChangeBlock\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "BalancerVault",
+}
+
+// BalancerVault is an auto generated Go binding around an Ethereum contract.
+type BalancerVault struct {
+	abi abi.ABI
+}
+
+// NewBalancerVault creates a new instance of BalancerVault.
+func NewBalancerVault() *BalancerVault {
+	parsed, err := BalancerVaultMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &BalancerVault{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *BalancerVault) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGetPoolTokens is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf94d4668.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getPoolTokens(bytes32 poolId) view returns(address[] tokens, uint256[] balances, uint256 lastChangeBlock)
+func (balancerVault *BalancerVault) PackGetPoolTokens(poolId [32]byte) []byte {
+	enc, err := balancerVault.abi.Pack("getPoolTokens", poolId)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetPoolTokens is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf94d4668.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getPoolTokens(bytes32 poolId) view returns(address[] tokens, uint256[] balances, uint256 lastChangeBlock)
+func (balancerVault *BalancerVault) TryPackGetPoolTokens(poolId [32]byte) ([]byte, error) {
+	return balancerVault.abi.Pack("getPoolTokens", poolId)
+}
+
+// GetPoolTokensOutput serves as a container for the return parameters of contract
+// method GetPoolTokens.
+type GetPoolTokensOutput struct {
+	Tokens          []common.Address
+	Balances        []*big.Int
+	LastChangeBlock *big.Int
+}
+
+// UnpackGetPoolTokens is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf94d4668.
+//
+// Solidity: function getPoolTokens(bytes32 poolId) view returns(address[] tokens, uint256[] balances, uint256 lastChangeBlock)
+func (balancerVault *BalancerVault) UnpackGetPoolTokens(data []byte) (GetPoolTokensOutput, error) {
+	out, err := balancerVault.abi.Unpack("getPoolTokens", data)
+	outstruct := new(GetPoolTokensOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.Tokens = *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+	outstruct.Balances = *abi.ConvertType(out[1], new([]*big.Int)).(*[]*big.Int)
+	outstruct.LastChangeBlock = abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+	return *outstruct, nil
+}
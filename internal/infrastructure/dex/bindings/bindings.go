@@ -0,0 +1,19 @@
+// Package bindings holds abigen bindings for the pool, factory, quoter, vault
+// and token contracts the DEX clients read and the tax probe simulates. Only
+// the functions called are in the .abi files; add to them and run go
+// generate to call more.
+package bindings
+
+//go:generate abigen --v2 --abi UniswapV2Factory.abi --pkg bindings --type UniswapV2Factory --out uniswap_v2_factory.go
+//go:generate abigen --v2 --abi UniswapV2Pair.abi --pkg bindings --type UniswapV2Pair --out uniswap_v2_pair.go
+//go:generate abigen --v2 --abi UniswapV3Factory.abi --pkg bindings --type UniswapV3Factory --out uniswap_v3_factory.go
+//go:generate abigen --v2 --abi UniswapV3Pool.abi --pkg bindings --type UniswapV3Pool --out uniswap_v3_pool.go
+//go:generate abigen --v2 --abi QuoterV2.abi --pkg bindings --type QuoterV2 --out quoter_v2.go
+//go:generate abigen --v2 --abi CurvePool.abi --pkg bindings --type CurvePool --out curve_pool.go
+//go:generate abigen --v2 --abi BalancerVault.abi --pkg bindings --type BalancerVault --out balancer_vault.go
+//go:generate abigen --v2 --abi BalancerPool.abi --pkg bindings --type BalancerPool --out balancer_pool.go
+//go:generate abigen --v2 --abi WstETH.abi --pkg bindings --type WstETH --out wsteth.go
+//go:generate abigen --v2 --abi ERC4626.abi --pkg bindings --type ERC4626 --out erc4626.go
+//go:generate abigen --v2 --abi MakerPSM.abi --pkg bindings --type MakerPSM --out maker_psm.go
+//go:generate abigen --v2 --abi ERC20.abi --pkg bindings --type ERC20 --out erc20.go
+//go:generate abigen --v2 --abi WETH9.abi --pkg bindings --type WETH9 --out weth9.go
@@ -0,0 +1,104 @@
+package bindings
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestSelectors(t *testing.T) {
+	v2Factory, v2Pair := NewUniswapV2Factory(), NewUniswapV2Pair()
+	v3Factory, v3Pool, quoter := NewUniswapV3Factory(), NewUniswapV3Pool(), NewQuoterV2()
+	curve, vault, pool, wstETH := NewCurvePool(), NewBalancerVault(), NewBalancerPool(), NewWstETH()
+	vault4626, psm, erc20, weth := NewERC4626(), NewMakerPSM(), NewERC20(), NewWETH9()
+	one := big.NewInt(1)
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"getPair", v2Factory.PackGetPair(common.Address{}, common.Address{}), "e6a43905"},
+		{"getReserves", v2Pair.PackGetReserves(), "0902f1ac"},
+		{"token0", v2Pair.PackToken0(), "0dfe1681"},
+		{"token1", v2Pair.PackToken1(), "d21220a7"},
+		{"swap", v2Pair.PackSwap(one, one, common.Address{}, nil), "022c0d9f"},
+		{"getPool", v3Factory.PackGetPool(common.Address{}, common.Address{}, one), "1698ee82"},
+		{"quoteExactInputSingle", quoter.PackQuoteExactInputSingle(IQuoterV2QuoteExactInputSingleParams{
+			AmountIn: one, Fee: one, SqrtPriceLimitX96: one,
+		}), "c6a5026a"},
+		{"slot0", v3Pool.PackSlot0(), "3850c7bd"},
+		{"liquidity", v3Pool.PackLiquidity(), "1a686502"},
+		{"tickSpacing", v3Pool.PackTickSpacing(), "d0c93a7c"},
+		{"tickBitmap", v3Pool.PackTickBitmap(-1), "5339c296"},
+		{"ticks", v3Pool.PackTicks(one), "f30dba93"},
+		{"get_dy", curve.PackGetDy(one, one, one), "5e0d443f"},
+		{"coins", curve.PackCoins(one), "c6610657"},
+		{"balances", curve.PackBalances(one), "4903b0d1"},
+		{"get_dy uint256", curve.PackGetDy0(one, one, one), "556d6e9f"},
+		{"coins int128", curve.PackCoins0(one), "23746eb8"},
+		{"balances int128", curve.PackBalances0(one), "065a80d8"},
+		{"fee", curve.PackFee(), "ddca3f43"},
+		{"A", curve.PackA(), "f446c1d0"},
+		{"gamma", curve.PackGamma(), "b1373929"},
+		{"D", curve.PackD(), "0f529ba2"},
+		{"price_scale", curve.PackPriceScale(one), "a3f7cdd5"},
+		{"price_scale two-coin", curve.PackPriceScale0(), "b9e8c9fd"},
+		{"mid_fee", curve.PackMidFee(), "92526c0c"},
+		{"out_fee", curve.PackOutFee(), "ee8de675"},
+		{"fee_gamma", curve.PackFeeGamma(), "72d4f0e2"},
+		{"getPoolTokens", vault.PackGetPoolTokens([32]byte{}), "f94d4668"},
+		{"getAmplificationParameter", pool.PackGetAmplificationParameter(), "6daccffa"},
+		{"getRate", pool.PackGetRate(), "679aefce"},
+		{"getTargets", pool.PackGetTargets(), "63fe3b56"},
+		{"getVirtualSupply", pool.PackGetVirtualSupply(), "de82cd34"},
+		{"getWrappedTokenRate", pool.PackGetWrappedTokenRate(), "f5431aa8"},
+		{"getSwapFeePercentage", pool.PackGetSwapFeePercentage(), "55c67628"},
+		{"stEthPerToken", wstETH.PackStEthPerToken(), "035faf82"},
+		{"getWstETHByStETH", wstETH.PackGetWstETHByStETH(one), "b0e38900"},
+		{"getStETHByWstETH", wstETH.PackGetStETHByWstETH(one), "bb2952fc"},
+		{"convertToAssets", vault4626.PackConvertToAssets(one), "07a2d13a"},
+		{"previewDeposit", vault4626.PackPreviewDeposit(one), "ef8b30f7"},
+		{"previewRedeem", vault4626.PackPreviewRedeem(one), "4cdad506"},
+		{"tin", psm.PackTin(), "568d4b6f"},
+		{"tout", psm.PackTout(), "fae036d5"},
+		{"pocket", psm.PackPocket(), "cccef9e2"},
+		{"balanceOf", erc20.PackBalanceOf(common.Address{}), "70a08231"},
+		{"transfer", erc20.PackTransfer(common.Address{}, one), "a9059cbb"},
+		{"deposit", weth.PackDeposit(), "d0e30db0"},
+	}
+	for _, tt := range tests {
+		if got := common.Bytes2Hex(tt.data[:4]); got != tt.want {
+			t.Errorf("%s selector = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnpackGetPoolTokens(t *testing.T) {
+	// Two tokens and balances, as the vault returns them: two offsets, the
+	// last change block, then each array's length and items
+	words := []int64{0x60, 0xc0, 99, 2, 0xa, 0xb, 2, 1000, 2000}
+	var result []byte
+	for _, w := range words {
+		result = append(result, common.LeftPadBytes(big.NewInt(w).Bytes(), 32)...)
+	}
+
+	out, err := NewBalancerVault().UnpackGetPoolTokens(result)
+	if err != nil {
+		t.Fatalf("UnpackGetPoolTokens() error = %v", err)
+	}
+	if len(out.Tokens) != 2 || out.Tokens[1] != common.BigToAddress(big.NewInt(0xb)) {
+		t.Errorf("tokens = %v", out.Tokens)
+	}
+	if len(out.Balances) != 2 || out.Balances[0].Int64() != 1000 || out.Balances[1].Int64() != 2000 {
+		t.Errorf("balances = %v", out.Balances)
+	}
+	if out.LastChangeBlock.Int64() != 99 {
+		t.Errorf("lastChangeBlock = %s, want 99", out.LastChangeBlock)
+	}
+
+	if _, err := NewBalancerVault().UnpackGetPoolTokens(result[:64]); err == nil {
+		t.Error("UnpackGetPoolTokens() accepted a truncated response")
+	}
+}
@@ -0,0 +1,611 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// CurvePoolMetaData contains all meta data concerning the CurvePool contract.
+var CurvePoolMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"get_dy\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"i\",\"type\":\"int128\",\"internalType\":\"int128\"},{\"name\":\"j\",\"type\":\"int128\",\"internalType\":\"int128\"},{\"name\":\"dx\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"coins\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"arg0\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"function\",\"name\":\"balances\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"arg0\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"fee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"get_dy\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"i\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"j\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"dx\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"coins\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"arg0\",\"type\":\"int128\",\"internalType\":\"int128\"}],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"function\",\"name\":\"balances\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"arg0\",\"type\":\"int128\",\"internalType\":\"int128\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"A\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"gamma\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"D\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"mid_fee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"out_fee\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"fee_gamma\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"price_scale\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"k\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"price_scale\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"get_dy_underlying\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"i\",\"type\":\"int128\",\"internalType\":\"int128\"},{\"name\":\"j\",\"type\":\"int128\",\"internalType\":\"int128\"},{\"name\":\"dx\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "CurvePool",
+}
+
+// CurvePool is an auto generated Go binding around an Ethereum contract.
+type CurvePool struct {
+	abi abi.ABI
+}
+
+// NewCurvePool creates a new instance of CurvePool.
+func NewCurvePool() *CurvePool {
+	parsed, err := CurvePoolMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &CurvePool{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *CurvePool) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackA is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf446c1d0.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function A() view returns(uint256)
+func (curvePool *CurvePool) PackA() []byte {
+	enc, err := curvePool.abi.Pack("A")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackA is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf446c1d0.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function A() view returns(uint256)
+func (curvePool *CurvePool) TryPackA() ([]byte, error) {
+	return curvePool.abi.Pack("A")
+}
+
+// UnpackA is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf446c1d0.
+//
+// Solidity: function A() view returns(uint256)
+func (curvePool *CurvePool) UnpackA(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("A", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackD is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0f529ba2.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function D() view returns(uint256)
+func (curvePool *CurvePool) PackD() []byte {
+	enc, err := curvePool.abi.Pack("D")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackD is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0f529ba2.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function D() view returns(uint256)
+func (curvePool *CurvePool) TryPackD() ([]byte, error) {
+	return curvePool.abi.Pack("D")
+}
+
+// UnpackD is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x0f529ba2.
+//
+// Solidity: function D() view returns(uint256)
+func (curvePool *CurvePool) UnpackD(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("D", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackBalances is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4903b0d1.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function balances(uint256 arg0) view returns(uint256)
+func (curvePool *CurvePool) PackBalances(arg0 *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("balances", arg0)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBalances is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4903b0d1.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function balances(uint256 arg0) view returns(uint256)
+func (curvePool *CurvePool) TryPackBalances(arg0 *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("balances", arg0)
+}
+
+// UnpackBalances is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x4903b0d1.
+//
+// Solidity: function balances(uint256 arg0) view returns(uint256)
+func (curvePool *CurvePool) UnpackBalances(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("balances", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackBalances0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x065a80d8.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function balances(int128 arg0) view returns(uint256)
+func (curvePool *CurvePool) PackBalances0(arg0 *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("balances0", arg0)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackBalances0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x065a80d8.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function balances(int128 arg0) view returns(uint256)
+func (curvePool *CurvePool) TryPackBalances0(arg0 *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("balances0", arg0)
+}
+
+// UnpackBalances0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x065a80d8.
+//
+// Solidity: function balances(int128 arg0) view returns(uint256)
+func (curvePool *CurvePool) UnpackBalances0(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("balances0", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackCoins is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc6610657.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function coins(uint256 arg0) view returns(address)
+func (curvePool *CurvePool) PackCoins(arg0 *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("coins", arg0)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackCoins is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xc6610657.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function coins(uint256 arg0) view returns(address)
+func (curvePool *CurvePool) TryPackCoins(arg0 *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("coins", arg0)
+}
+
+// UnpackCoins is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xc6610657.
+//
+// Solidity: function coins(uint256 arg0) view returns(address)
+func (curvePool *CurvePool) UnpackCoins(data []byte) (common.Address, error) {
+	out, err := curvePool.abi.Unpack("coins", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
+
+// PackCoins0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x23746eb8.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (curvePool *CurvePool) PackCoins0(arg0 *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("coins0", arg0)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackCoins0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x23746eb8.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (curvePool *CurvePool) TryPackCoins0(arg0 *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("coins0", arg0)
+}
+
+// UnpackCoins0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x23746eb8.
+//
+// Solidity: function coins(int128 arg0) view returns(address)
+func (curvePool *CurvePool) UnpackCoins0(data []byte) (common.Address, error) {
+	out, err := curvePool.abi.Unpack("coins0", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
+
+// PackFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xddca3f43.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function fee() view returns(uint256)
+func (curvePool *CurvePool) PackFee() []byte {
+	enc, err := curvePool.abi.Pack("fee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xddca3f43.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function fee() view returns(uint256)
+func (curvePool *CurvePool) TryPackFee() ([]byte, error) {
+	return curvePool.abi.Pack("fee")
+}
+
+// UnpackFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xddca3f43.
+//
+// Solidity: function fee() view returns(uint256)
+func (curvePool *CurvePool) UnpackFee(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("fee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackFeeGamma is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x72d4f0e2.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function fee_gamma() view returns(uint256)
+func (curvePool *CurvePool) PackFeeGamma() []byte {
+	enc, err := curvePool.abi.Pack("fee_gamma")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackFeeGamma is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x72d4f0e2.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function fee_gamma() view returns(uint256)
+func (curvePool *CurvePool) TryPackFeeGamma() ([]byte, error) {
+	return curvePool.abi.Pack("fee_gamma")
+}
+
+// UnpackFeeGamma is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x72d4f0e2.
+//
+// Solidity: function fee_gamma() view returns(uint256)
+func (curvePool *CurvePool) UnpackFeeGamma(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("fee_gamma", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGamma is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb1373929.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function gamma() view returns(uint256)
+func (curvePool *CurvePool) PackGamma() []byte {
+	enc, err := curvePool.abi.Pack("gamma")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGamma is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb1373929.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function gamma() view returns(uint256)
+func (curvePool *CurvePool) TryPackGamma() ([]byte, error) {
+	return curvePool.abi.Pack("gamma")
+}
+
+// UnpackGamma is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xb1373929.
+//
+// Solidity: function gamma() view returns(uint256)
+func (curvePool *CurvePool) UnpackGamma(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("gamma", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetDy is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x5e0d443f.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) PackGetDy(i *big.Int, j *big.Int, dx *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("get_dy", i, j, dx)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetDy is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x5e0d443f.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) TryPackGetDy(i *big.Int, j *big.Int, dx *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("get_dy", i, j, dx)
+}
+
+// UnpackGetDy is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x5e0d443f.
+//
+// Solidity: function get_dy(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) UnpackGetDy(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("get_dy", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetDy0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x556d6e9f.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function get_dy(uint256 i, uint256 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) PackGetDy0(i *big.Int, j *big.Int, dx *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("get_dy0", i, j, dx)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetDy0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x556d6e9f.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function get_dy(uint256 i, uint256 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) TryPackGetDy0(i *big.Int, j *big.Int, dx *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("get_dy0", i, j, dx)
+}
+
+// UnpackGetDy0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x556d6e9f.
+//
+// Solidity: function get_dy(uint256 i, uint256 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) UnpackGetDy0(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("get_dy0", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetDyUnderlying is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x07211ef7.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) PackGetDyUnderlying(i *big.Int, j *big.Int, dx *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("get_dy_underlying", i, j, dx)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetDyUnderlying is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x07211ef7.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) TryPackGetDyUnderlying(i *big.Int, j *big.Int, dx *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("get_dy_underlying", i, j, dx)
+}
+
+// UnpackGetDyUnderlying is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x07211ef7.
+//
+// Solidity: function get_dy_underlying(int128 i, int128 j, uint256 dx) view returns(uint256)
+func (curvePool *CurvePool) UnpackGetDyUnderlying(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("get_dy_underlying", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackMidFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x92526c0c.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function mid_fee() view returns(uint256)
+func (curvePool *CurvePool) PackMidFee() []byte {
+	enc, err := curvePool.abi.Pack("mid_fee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackMidFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x92526c0c.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function mid_fee() view returns(uint256)
+func (curvePool *CurvePool) TryPackMidFee() ([]byte, error) {
+	return curvePool.abi.Pack("mid_fee")
+}
+
+// UnpackMidFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x92526c0c.
+//
+// Solidity: function mid_fee() view returns(uint256)
+func (curvePool *CurvePool) UnpackMidFee(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("mid_fee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackOutFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xee8de675.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function out_fee() view returns(uint256)
+func (curvePool *CurvePool) PackOutFee() []byte {
+	enc, err := curvePool.abi.Pack("out_fee")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackOutFee is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xee8de675.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function out_fee() view returns(uint256)
+func (curvePool *CurvePool) TryPackOutFee() ([]byte, error) {
+	return curvePool.abi.Pack("out_fee")
+}
+
+// UnpackOutFee is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xee8de675.
+//
+// Solidity: function out_fee() view returns(uint256)
+func (curvePool *CurvePool) UnpackOutFee(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("out_fee", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackPriceScale is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xa3f7cdd5.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function price_scale(uint256 k) view returns(uint256)
+func (curvePool *CurvePool) PackPriceScale(k *big.Int) []byte {
+	enc, err := curvePool.abi.Pack("price_scale", k)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackPriceScale is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xa3f7cdd5.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function price_scale(uint256 k) view returns(uint256)
+func (curvePool *CurvePool) TryPackPriceScale(k *big.Int) ([]byte, error) {
+	return curvePool.abi.Pack("price_scale", k)
+}
+
+// UnpackPriceScale is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xa3f7cdd5.
+//
+// Solidity: function price_scale(uint256 k) view returns(uint256)
+func (curvePool *CurvePool) UnpackPriceScale(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("price_scale", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackPriceScale0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb9e8c9fd.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function price_scale() view returns(uint256)
+func (curvePool *CurvePool) PackPriceScale0() []byte {
+	enc, err := curvePool.abi.Pack("price_scale0")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackPriceScale0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb9e8c9fd.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function price_scale() view returns(uint256)
+func (curvePool *CurvePool) TryPackPriceScale0() ([]byte, error) {
+	return curvePool.abi.Pack("price_scale0")
+}
+
+// UnpackPriceScale0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xb9e8c9fd.
+//
+// Solidity: function price_scale() view returns(uint256)
+func (curvePool *CurvePool) UnpackPriceScale0(data []byte) (*big.Int, error) {
+	out, err := curvePool.abi.Unpack("price_scale0", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
@@ -0,0 +1,156 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// ERC4626MetaData contains all meta data concerning the ERC4626 contract.
+var ERC4626MetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"convertToAssets\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"shares\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"previewDeposit\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"assets\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"previewRedeem\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"shares\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "ERC4626",
+}
+
+// ERC4626 is an auto generated Go binding around an Ethereum contract.
+type ERC4626 struct {
+	abi abi.ABI
+}
+
+// NewERC4626 creates a new instance of ERC4626.
+func NewERC4626() *ERC4626 {
+	parsed, err := ERC4626MetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &ERC4626{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *ERC4626) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackConvertToAssets is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x07a2d13a.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function convertToAssets(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) PackConvertToAssets(shares *big.Int) []byte {
+	enc, err := eRC4626.abi.Pack("convertToAssets", shares)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackConvertToAssets is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x07a2d13a.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function convertToAssets(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) TryPackConvertToAssets(shares *big.Int) ([]byte, error) {
+	return eRC4626.abi.Pack("convertToAssets", shares)
+}
+
+// UnpackConvertToAssets is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x07a2d13a.
+//
+// Solidity: function convertToAssets(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) UnpackConvertToAssets(data []byte) (*big.Int, error) {
+	out, err := eRC4626.abi.Unpack("convertToAssets", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackPreviewDeposit is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xef8b30f7.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function previewDeposit(uint256 assets) view returns(uint256)
+func (eRC4626 *ERC4626) PackPreviewDeposit(assets *big.Int) []byte {
+	enc, err := eRC4626.abi.Pack("previewDeposit", assets)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackPreviewDeposit is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xef8b30f7.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function previewDeposit(uint256 assets) view returns(uint256)
+func (eRC4626 *ERC4626) TryPackPreviewDeposit(assets *big.Int) ([]byte, error) {
+	return eRC4626.abi.Pack("previewDeposit", assets)
+}
+
+// UnpackPreviewDeposit is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xef8b30f7.
+//
+// Solidity: function previewDeposit(uint256 assets) view returns(uint256)
+func (eRC4626 *ERC4626) UnpackPreviewDeposit(data []byte) (*big.Int, error) {
+	out, err := eRC4626.abi.Unpack("previewDeposit", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackPreviewRedeem is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4cdad506.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function previewRedeem(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) PackPreviewRedeem(shares *big.Int) []byte {
+	enc, err := eRC4626.abi.Pack("previewRedeem", shares)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackPreviewRedeem is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x4cdad506.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function previewRedeem(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) TryPackPreviewRedeem(shares *big.Int) ([]byte, error) {
+	return eRC4626.abi.Pack("previewRedeem", shares)
+}
+
+// UnpackPreviewRedeem is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x4cdad506.
+//
+// Solidity: function previewRedeem(uint256 shares) view returns(uint256)
+func (eRC4626 *ERC4626) UnpackPreviewRedeem(data []byte) (*big.Int, error) {
+	out, err := eRC4626.abi.Unpack("previewRedeem", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
@@ -0,0 +1,156 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// MakerPSMMetaData contains all meta data concerning the MakerPSM contract.
+var MakerPSMMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"tin\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"tout\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"pocket\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}]}]",
+	ID:  "MakerPSM",
+}
+
+// MakerPSM is an auto generated Go binding around an Ethereum contract.
+type MakerPSM struct {
+	abi abi.ABI
+}
+
+// NewMakerPSM creates a new instance of MakerPSM.
+func NewMakerPSM() *MakerPSM {
+	parsed, err := MakerPSMMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &MakerPSM{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *MakerPSM) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackPocket is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xcccef9e2.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function pocket() view returns(address)
+func (makerPSM *MakerPSM) PackPocket() []byte {
+	enc, err := makerPSM.abi.Pack("pocket")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackPocket is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xcccef9e2.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function pocket() view returns(address)
+func (makerPSM *MakerPSM) TryPackPocket() ([]byte, error) {
+	return makerPSM.abi.Pack("pocket")
+}
+
+// UnpackPocket is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xcccef9e2.
+//
+// Solidity: function pocket() view returns(address)
+func (makerPSM *MakerPSM) UnpackPocket(data []byte) (common.Address, error) {
+	out, err := makerPSM.abi.Unpack("pocket", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
+
+// PackTin is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x568d4b6f.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function tin() view returns(uint256)
+func (makerPSM *MakerPSM) PackTin() []byte {
+	enc, err := makerPSM.abi.Pack("tin")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackTin is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x568d4b6f.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function tin() view returns(uint256)
+func (makerPSM *MakerPSM) TryPackTin() ([]byte, error) {
+	return makerPSM.abi.Pack("tin")
+}
+
+// UnpackTin is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x568d4b6f.
+//
+// Solidity: function tin() view returns(uint256)
+func (makerPSM *MakerPSM) UnpackTin(data []byte) (*big.Int, error) {
+	out, err := makerPSM.abi.Unpack("tin", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackTout is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfae036d5.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function tout() view returns(uint256)
+func (makerPSM *MakerPSM) PackTout() []byte {
+	enc, err := makerPSM.abi.Pack("tout")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackTout is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfae036d5.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function tout() view returns(uint256)
+func (makerPSM *MakerPSM) TryPackTout() ([]byte, error) {
+	return makerPSM.abi.Pack("tout")
+}
+
+// UnpackTout is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xfae036d5.
+//
+// Solidity: function tout() view returns(uint256)
+func (makerPSM *MakerPSM) UnpackTout(data []byte) (*big.Int, error) {
+	out, err := makerPSM.abi.Unpack("tout", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
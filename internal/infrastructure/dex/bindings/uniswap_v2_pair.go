@@ -0,0 +1,189 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// UniswapV2PairMetaData contains all meta data concerning the UniswapV2Pair contract.
+var UniswapV2PairMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"getReserves\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"reserve0\",\"type\":\"uint112\",\"internalType\":\"uint112\"},{\"name\":\"reserve1\",\"type\":\"uint112\",\"internalType\":\"uint112\"},{\"name\":\"blockTimestampLast\",\"type\":\"uint32\",\"internalType\":\"uint32\"}]},{\"type\":\"function\",\"name\":\"token0\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"function\",\"name\":\"token1\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"function\",\"name\":\"swap\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"amount0Out\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amount1Out\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"to\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[]}]",
+	ID:  "UniswapV2Pair",
+}
+
+// UniswapV2Pair is an auto generated Go binding around an Ethereum contract.
+type UniswapV2Pair struct {
+	abi abi.ABI
+}
+
+// NewUniswapV2Pair creates a new instance of UniswapV2Pair.
+func NewUniswapV2Pair() *UniswapV2Pair {
+	parsed, err := UniswapV2PairMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &UniswapV2Pair{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *UniswapV2Pair) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGetReserves is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0902f1ac.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getReserves() view returns(uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
+func (uniswapV2Pair *UniswapV2Pair) PackGetReserves() []byte {
+	enc, err := uniswapV2Pair.abi.Pack("getReserves")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetReserves is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0902f1ac.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getReserves() view returns(uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
+func (uniswapV2Pair *UniswapV2Pair) TryPackGetReserves() ([]byte, error) {
+	return uniswapV2Pair.abi.Pack("getReserves")
+}
+
+// GetReservesOutput serves as a container for the return parameters of contract
+// method GetReserves.
+type GetReservesOutput struct {
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+}
+
+// UnpackGetReserves is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x0902f1ac.
+//
+// Solidity: function getReserves() view returns(uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
+func (uniswapV2Pair *UniswapV2Pair) UnpackGetReserves(data []byte) (GetReservesOutput, error) {
+	out, err := uniswapV2Pair.abi.Unpack("getReserves", data)
+	outstruct := new(GetReservesOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.Reserve0 = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	outstruct.Reserve1 = abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	outstruct.BlockTimestampLast = *abi.ConvertType(out[2], new(uint32)).(*uint32)
+	return *outstruct, nil
+}
+
+// PackSwap is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x022c0d9f.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function swap(uint256 amount0Out, uint256 amount1Out, address to, bytes data) returns()
+func (uniswapV2Pair *UniswapV2Pair) PackSwap(amount0Out *big.Int, amount1Out *big.Int, to common.Address, data []byte) []byte {
+	enc, err := uniswapV2Pair.abi.Pack("swap", amount0Out, amount1Out, to, data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackSwap is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x022c0d9f.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function swap(uint256 amount0Out, uint256 amount1Out, address to, bytes data) returns()
+func (uniswapV2Pair *UniswapV2Pair) TryPackSwap(amount0Out *big.Int, amount1Out *big.Int, to common.Address, data []byte) ([]byte, error) {
+	return uniswapV2Pair.abi.Pack("swap", amount0Out, amount1Out, to, data)
+}
+
+// PackToken0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0dfe1681.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function token0() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) PackToken0() []byte {
+	enc, err := uniswapV2Pair.abi.Pack("token0")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackToken0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x0dfe1681.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function token0() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) TryPackToken0() ([]byte, error) {
+	return uniswapV2Pair.abi.Pack("token0")
+}
+
+// UnpackToken0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x0dfe1681.
+//
+// Solidity: function token0() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) UnpackToken0(data []byte) (common.Address, error) {
+	out, err := uniswapV2Pair.abi.Unpack("token0", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
+
+// PackToken1 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd21220a7.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function token1() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) PackToken1() []byte {
+	enc, err := uniswapV2Pair.abi.Pack("token1")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackToken1 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd21220a7.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function token1() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) TryPackToken1() ([]byte, error) {
+	return uniswapV2Pair.abi.Pack("token1")
+}
+
+// UnpackToken1 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xd21220a7.
+//
+// Solidity: function token1() view returns(address)
+func (uniswapV2Pair *UniswapV2Pair) UnpackToken1(data []byte) (common.Address, error) {
+	out, err := uniswapV2Pair.abi.Unpack("token1", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
@@ -0,0 +1,86 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// UniswapV3FactoryMetaData contains all meta data concerning the UniswapV3Factory contract.
+var UniswapV3FactoryMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"getPool\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"tokenA\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"tokenB\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"fee\",\"type\":\"uint24\",\"internalType\":\"uint24\"}],\"outputs\":[{\"name\":\"pool\",\"type\":\"address\",\"internalType\":\"address\"}]}]",
+	ID:  "UniswapV3Factory",
+}
+
+// UniswapV3Factory is an auto generated Go binding around an Ethereum contract.
+type UniswapV3Factory struct {
+	abi abi.ABI
+}
+
+// NewUniswapV3Factory creates a new instance of UniswapV3Factory.
+func NewUniswapV3Factory() *UniswapV3Factory {
+	parsed, err := UniswapV3FactoryMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &UniswapV3Factory{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *UniswapV3Factory) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGetPool is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x1698ee82.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getPool(address tokenA, address tokenB, uint24 fee) view returns(address pool)
+func (uniswapV3Factory *UniswapV3Factory) PackGetPool(tokenA common.Address, tokenB common.Address, fee *big.Int) []byte {
+	enc, err := uniswapV3Factory.abi.Pack("getPool", tokenA, tokenB, fee)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetPool is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x1698ee82.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getPool(address tokenA, address tokenB, uint24 fee) view returns(address pool)
+func (uniswapV3Factory *UniswapV3Factory) TryPackGetPool(tokenA common.Address, tokenB common.Address, fee *big.Int) ([]byte, error) {
+	return uniswapV3Factory.abi.Pack("getPool", tokenA, tokenB, fee)
+}
+
+// UnpackGetPool is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x1698ee82.
+//
+// Solidity: function getPool(address tokenA, address tokenB, uint24 fee) view returns(address pool)
+func (uniswapV3Factory *UniswapV3Factory) UnpackGetPool(data []byte) (common.Address, error) {
+	out, err := uniswapV3Factory.abi.Unpack("getPool", data)
+	if err != nil {
+		return *new(common.Address), err
+	}
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+	return out0, nil
+}
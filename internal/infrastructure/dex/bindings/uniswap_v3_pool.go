@@ -0,0 +1,266 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// UniswapV3PoolMetaData contains all meta data concerning the UniswapV3Pool contract.
+var UniswapV3PoolMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"slot0\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"sqrtPriceX96\",\"type\":\"uint160\",\"internalType\":\"uint160\"},{\"name\":\"tick\",\"type\":\"int24\",\"internalType\":\"int24\"},{\"name\":\"observationIndex\",\"type\":\"uint16\",\"internalType\":\"uint16\"},{\"name\":\"observationCardinality\",\"type\":\"uint16\",\"internalType\":\"uint16\"},{\"name\":\"observationCardinalityNext\",\"type\":\"uint16\",\"internalType\":\"uint16\"},{\"name\":\"feeProtocol\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"unlocked\",\"type\":\"bool\",\"internalType\":\"bool\"}]},{\"type\":\"function\",\"name\":\"liquidity\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint128\",\"internalType\":\"uint128\"}]},{\"type\":\"function\",\"name\":\"tickSpacing\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"int24\",\"internalType\":\"int24\"}]},{\"type\":\"function\",\"name\":\"tickBitmap\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"wordPosition\",\"type\":\"int16\",\"internalType\":\"int16\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"ticks\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"tick\",\"type\":\"int24\",\"internalType\":\"int24\"}],\"outputs\":[{\"name\":\"liquidityGross\",\"type\":\"uint128\",\"internalType\":\"uint128\"},{\"name\":\"liquidityNet\",\"type\":\"int128\",\"internalType\":\"int128\"},{\"name\":\"feeGrowthOutside0X128\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"feeGrowthOutside1X128\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"tickCumulativeOutside\",\"type\":\"int56\",\"internalType\":\"int56\"},{\"name\":\"secondsPerLiquidityOutsideX128\",\"type\":\"uint160\",\"internalType\":\"uint160\"},{\"name\":\"secondsOutside\",\"type\":\"uint32\",\"internalType\":\"uint32\"},{\"name\":\"initialized\",\"type\":\"bool\",\"internalType\":\"bool\"}]}]",
+	ID:  "UniswapV3Pool",
+}
+
+// UniswapV3Pool is an auto generated Go binding around an Ethereum contract.
+type UniswapV3Pool struct {
+	abi abi.ABI
+}
+
+// NewUniswapV3Pool creates a new instance of UniswapV3Pool.
+func NewUniswapV3Pool() *UniswapV3Pool {
+	parsed, err := UniswapV3PoolMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &UniswapV3Pool{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *UniswapV3Pool) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackLiquidity is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x1a686502.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function liquidity() view returns(uint128)
+func (uniswapV3Pool *UniswapV3Pool) PackLiquidity() []byte {
+	enc, err := uniswapV3Pool.abi.Pack("liquidity")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackLiquidity is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x1a686502.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function liquidity() view returns(uint128)
+func (uniswapV3Pool *UniswapV3Pool) TryPackLiquidity() ([]byte, error) {
+	return uniswapV3Pool.abi.Pack("liquidity")
+}
+
+// UnpackLiquidity is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x1a686502.
+//
+// Solidity: function liquidity() view returns(uint128)
+func (uniswapV3Pool *UniswapV3Pool) UnpackLiquidity(data []byte) (*big.Int, error) {
+	out, err := uniswapV3Pool.abi.Unpack("liquidity", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackSlot0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x3850c7bd.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function slot0() view returns(uint160 sqrtPriceX96, int24 tick, uint16 observationIndex, uint16 observationCardinality, uint16 observationCardinalityNext, uint8 feeProtocol, bool unlocked)
+func (uniswapV3Pool *UniswapV3Pool) PackSlot0() []byte {
+	enc, err := uniswapV3Pool.abi.Pack("slot0")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackSlot0 is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x3850c7bd.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function slot0() view returns(uint160 sqrtPriceX96, int24 tick, uint16 observationIndex, uint16 observationCardinality, uint16 observationCardinalityNext, uint8 feeProtocol, bool unlocked)
+func (uniswapV3Pool *UniswapV3Pool) TryPackSlot0() ([]byte, error) {
+	return uniswapV3Pool.abi.Pack("slot0")
+}
+
+// Slot0Output serves as a container for the return parameters of contract
+// method Slot0.
+type Slot0Output struct {
+	SqrtPriceX96               *big.Int
+	Tick                       *big.Int
+	ObservationIndex           uint16
+	ObservationCardinality     uint16
+	ObservationCardinalityNext uint16
+	FeeProtocol                uint8
+	Unlocked                   bool
+}
+
+// UnpackSlot0 is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x3850c7bd.
+//
+// Solidity: function slot0() view returns(uint160 sqrtPriceX96, int24 tick, uint16 observationIndex, uint16 observationCardinality, uint16 observationCardinalityNext, uint8 feeProtocol, bool unlocked)
+func (uniswapV3Pool *UniswapV3Pool) UnpackSlot0(data []byte) (Slot0Output, error) {
+	out, err := uniswapV3Pool.abi.Unpack("slot0", data)
+	outstruct := new(Slot0Output)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.SqrtPriceX96 = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	outstruct.Tick = abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	outstruct.ObservationIndex = *abi.ConvertType(out[2], new(uint16)).(*uint16)
+	outstruct.ObservationCardinality = *abi.ConvertType(out[3], new(uint16)).(*uint16)
+	outstruct.ObservationCardinalityNext = *abi.ConvertType(out[4], new(uint16)).(*uint16)
+	outstruct.FeeProtocol = *abi.ConvertType(out[5], new(uint8)).(*uint8)
+	outstruct.Unlocked = *abi.ConvertType(out[6], new(bool)).(*bool)
+	return *outstruct, nil
+}
+
+// PackTickBitmap is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x5339c296.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function tickBitmap(int16 wordPosition) view returns(uint256)
+func (uniswapV3Pool *UniswapV3Pool) PackTickBitmap(wordPosition int16) []byte {
+	enc, err := uniswapV3Pool.abi.Pack("tickBitmap", wordPosition)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackTickBitmap is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x5339c296.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function tickBitmap(int16 wordPosition) view returns(uint256)
+func (uniswapV3Pool *UniswapV3Pool) TryPackTickBitmap(wordPosition int16) ([]byte, error) {
+	return uniswapV3Pool.abi.Pack("tickBitmap", wordPosition)
+}
+
+// UnpackTickBitmap is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x5339c296.
+//
+// Solidity: function tickBitmap(int16 wordPosition) view returns(uint256)
+func (uniswapV3Pool *UniswapV3Pool) UnpackTickBitmap(data []byte) (*big.Int, error) {
+	out, err := uniswapV3Pool.abi.Unpack("tickBitmap", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackTickSpacing is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd0c93a7c.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function tickSpacing() view returns(int24)
+func (uniswapV3Pool *UniswapV3Pool) PackTickSpacing() []byte {
+	enc, err := uniswapV3Pool.abi.Pack("tickSpacing")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackTickSpacing is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd0c93a7c.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function tickSpacing() view returns(int24)
+func (uniswapV3Pool *UniswapV3Pool) TryPackTickSpacing() ([]byte, error) {
+	return uniswapV3Pool.abi.Pack("tickSpacing")
+}
+
+// UnpackTickSpacing is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xd0c93a7c.
+//
+// Solidity: function tickSpacing() view returns(int24)
+func (uniswapV3Pool *UniswapV3Pool) UnpackTickSpacing(data []byte) (*big.Int, error) {
+	out, err := uniswapV3Pool.abi.Unpack("tickSpacing", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackTicks is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf30dba93.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function ticks(int24 tick) view returns(uint128 liquidityGross, int128 liquidityNet, uint256 feeGrowthOutside0X128, uint256 feeGrowthOutside1X128, int56 tickCumulativeOutside, uint160 secondsPerLiquidityOutsideX128, uint32 secondsOutside, bool initialized)
+func (uniswapV3Pool *UniswapV3Pool) PackTicks(tick *big.Int) []byte {
+	enc, err := uniswapV3Pool.abi.Pack("ticks", tick)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackTicks is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xf30dba93.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function ticks(int24 tick) view returns(uint128 liquidityGross, int128 liquidityNet, uint256 feeGrowthOutside0X128, uint256 feeGrowthOutside1X128, int56 tickCumulativeOutside, uint160 secondsPerLiquidityOutsideX128, uint32 secondsOutside, bool initialized)
+func (uniswapV3Pool *UniswapV3Pool) TryPackTicks(tick *big.Int) ([]byte, error) {
+	return uniswapV3Pool.abi.Pack("ticks", tick)
+}
+
+// TicksOutput serves as a container for the return parameters of contract
+// method Ticks.
+type TicksOutput struct {
+	LiquidityGross                 *big.Int
+	LiquidityNet                   *big.Int
+	FeeGrowthOutside0X128          *big.Int
+	FeeGrowthOutside1X128          *big.Int
+	TickCumulativeOutside          *big.Int
+	SecondsPerLiquidityOutsideX128 *big.Int
+	SecondsOutside                 uint32
+	Initialized                    bool
+}
+
+// UnpackTicks is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xf30dba93.
+//
+// Solidity: function ticks(int24 tick) view returns(uint128 liquidityGross, int128 liquidityNet, uint256 feeGrowthOutside0X128, uint256 feeGrowthOutside1X128, int56 tickCumulativeOutside, uint160 secondsPerLiquidityOutsideX128, uint32 secondsOutside, bool initialized)
+func (uniswapV3Pool *UniswapV3Pool) UnpackTicks(data []byte) (TicksOutput, error) {
+	out, err := uniswapV3Pool.abi.Unpack("ticks", data)
+	outstruct := new(TicksOutput)
+	if err != nil {
+		return *outstruct, err
+	}
+	outstruct.LiquidityGross = abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	outstruct.LiquidityNet = abi.ConvertType(out[1], new(big.Int)).(*big.Int)
+	outstruct.FeeGrowthOutside0X128 = abi.ConvertType(out[2], new(big.Int)).(*big.Int)
+	outstruct.FeeGrowthOutside1X128 = abi.ConvertType(out[3], new(big.Int)).(*big.Int)
+	outstruct.TickCumulativeOutside = abi.ConvertType(out[4], new(big.Int)).(*big.Int)
+	outstruct.SecondsPerLiquidityOutsideX128 = abi.ConvertType(out[5], new(big.Int)).(*big.Int)
+	outstruct.SecondsOutside = *abi.ConvertType(out[6], new(uint32)).(*uint32)
+	outstruct.Initialized = *abi.ConvertType(out[7], new(bool)).(*bool)
+	return *outstruct, nil
+}
@@ -0,0 +1,73 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// WETH9MetaData contains all meta data concerning the WETH9 contract.
+var WETH9MetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"deposit\",\"stateMutability\":\"payable\",\"inputs\":[],\"outputs\":[]}]",
+	ID:  "WETH9",
+}
+
+// WETH9 is an auto generated Go binding around an Ethereum contract.
+type WETH9 struct {
+	abi abi.ABI
+}
+
+// NewWETH9 creates a new instance of WETH9.
+func NewWETH9() *WETH9 {
+	parsed, err := WETH9MetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &WETH9{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *WETH9) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackDeposit is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd0e30db0.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function deposit() payable returns()
+func (wETH9 *WETH9) PackDeposit() []byte {
+	enc, err := wETH9.abi.Pack("deposit")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackDeposit is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xd0e30db0.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function deposit() payable returns()
+func (wETH9 *WETH9) TryPackDeposit() ([]byte, error) {
+	return wETH9.abi.Pack("deposit")
+}
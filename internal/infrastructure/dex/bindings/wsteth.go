@@ -0,0 +1,156 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package bindings
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// WstETHMetaData contains all meta data concerning the WstETH contract.
+var WstETHMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"stEthPerToken\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getWstETHByStETH\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"_stETHAmount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"getStETHByWstETH\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"_wstETHAmount\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]}]",
+	ID:  "WstETH",
+}
+
+// WstETH is an auto generated Go binding around an Ethereum contract.
+type WstETH struct {
+	abi abi.ABI
+}
+
+// NewWstETH creates a new instance of WstETH.
+func NewWstETH() *WstETH {
+	parsed, err := WstETHMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &WstETH{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *WstETH) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackGetStETHByWstETH is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xbb2952fc.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getStETHByWstETH(uint256 _wstETHAmount) view returns(uint256)
+func (wstETH *WstETH) PackGetStETHByWstETH(wstETHAmount *big.Int) []byte {
+	enc, err := wstETH.abi.Pack("getStETHByWstETH", wstETHAmount)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetStETHByWstETH is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xbb2952fc.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getStETHByWstETH(uint256 _wstETHAmount) view returns(uint256)
+func (wstETH *WstETH) TryPackGetStETHByWstETH(wstETHAmount *big.Int) ([]byte, error) {
+	return wstETH.abi.Pack("getStETHByWstETH", wstETHAmount)
+}
+
+// UnpackGetStETHByWstETH is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xbb2952fc.
+//
+// Solidity: function getStETHByWstETH(uint256 _wstETHAmount) view returns(uint256)
+func (wstETH *WstETH) UnpackGetStETHByWstETH(data []byte) (*big.Int, error) {
+	out, err := wstETH.abi.Unpack("getStETHByWstETH", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackGetWstETHByStETH is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb0e38900.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function getWstETHByStETH(uint256 _stETHAmount) view returns(uint256)
+func (wstETH *WstETH) PackGetWstETHByStETH(stETHAmount *big.Int) []byte {
+	enc, err := wstETH.abi.Pack("getWstETHByStETH", stETHAmount)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackGetWstETHByStETH is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xb0e38900.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function getWstETHByStETH(uint256 _stETHAmount) view returns(uint256)
+func (wstETH *WstETH) TryPackGetWstETHByStETH(stETHAmount *big.Int) ([]byte, error) {
+	return wstETH.abi.Pack("getWstETHByStETH", stETHAmount)
+}
+
+// UnpackGetWstETHByStETH is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0xb0e38900.
+//
+// Solidity: function getWstETHByStETH(uint256 _stETHAmount) view returns(uint256)
+func (wstETH *WstETH) UnpackGetWstETHByStETH(data []byte) (*big.Int, error) {
+	out, err := wstETH.abi.Unpack("getWstETHByStETH", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackStEthPerToken is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x035faf82.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function stEthPerToken() view returns(uint256)
+func (wstETH *WstETH) PackStEthPerToken() []byte {
+	enc, err := wstETH.abi.Pack("stEthPerToken")
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackStEthPerToken is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x035faf82.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function stEthPerToken() view returns(uint256)
+func (wstETH *WstETH) TryPackStEthPerToken() ([]byte, error) {
+	return wstETH.abi.Pack("stEthPerToken")
+}
+
+// UnpackStEthPerToken is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x035faf82.
+//
+// Solidity: function stEthPerToken() view returns(uint256)
+func (wstETH *WstETH) UnpackStEthPerToken(data []byte) (*big.Int, error) {
+	out, err := wstETH.abi.Unpack("stEthPerToken", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
@@ -0,0 +1,24 @@
+package dex
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// contractCaller is the part of the node client bindings are called through
+type contractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+}
+
+// callView calls a view function with calldata packed by a binding and
+// decodes the result with the binding's matching Unpack method
+func callView[T any](ctx context.Context, client contractCaller, to common.Address, data []byte, unpack func([]byte) (T, error)) (T, error) {
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return unpack(result)
+}
@@ -4,25 +4,18 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
 	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
-var (
-	// get_dy(int128 i, int128 j, uint256 dx) returns (uint256)
-	getDySelector = common.Hex2Bytes("5e0d443f")
-	// coins(uint256) returns (address)
-	coinsSelector = common.Hex2Bytes("c6610657")
-	// balances(uint256) returns (uint256)
-	balancesSelector = common.Hex2Bytes("4903b0d1")
-	// fee() returns (uint256) - fee in 1e10 format
-	feeSelector = common.Hex2Bytes("ddca3f43")
-)
+var curvePool = bindings.NewCurvePool()
 
 // Curve stablecoin pool addresses (Ethereum mainnet)
 var (
@@ -30,12 +23,54 @@ var (
 	Curve3PoolAddress = common.HexToAddress("0xbEbc44782C7dB0a1A60Cb6fe97d0b483032FF1C7")
 	// stETH/ETH pool
 	CurveStETHAddress = common.HexToAddress("0xDC24316b9AE028F1497c275EB9192a3Ea0f67022")
+	// tBTC/WBTC factory pool
+	CurveTBTCAddress = common.HexToAddress("0xB7ECB2AA52AA64a717180E030241bC75Cd946726")
+	// tricrypto2 (USDT/WBTC/WETH)
+	CurveTricrypto2Address = common.HexToAddress("0xD51a44d3FaE010294C616388b506AcdA1bfAAE46")
+)
+
+// CurveABI is the calling convention of a Curve pool's index arguments,
+// which changed between pool generations behind the same function names
+type CurveABI int
+
+const (
+	// CurveABIUnknown pools are probed on first use
+	CurveABIUnknown CurveABI = iota
+	// CurveABIStable takes get_dy(int128,int128,uint256) with uint256 coins
+	// and balances indexes, as in 3pool and steth
+	CurveABIStable
+	// CurveABILegacy takes int128 indexes everywhere, as in the compound,
+	// usdt and y pools
+	CurveABILegacy
+	// CurveABICrypto takes uint256 indexes everywhere, as in tricrypto and
+	// the -ng factory pools
+	CurveABICrypto
+)
+
+// CurvePoolType selects the math a pool is priced with
+type CurvePoolType int
+
+const (
+	// CurveStableSwap pools hold pegged coins on the StableSwap invariant
+	CurveStableSwap CurvePoolType = iota
+	// CurveCryptoSwap pools, such as tricrypto, hold volatile coins on the
+	// CryptoSwap invariant around a price scale that follows the pool's
+	// internal oracle, with a fee that rises as the balances drift from it
+	CurveCryptoSwap
 )
 
 type CurvePool struct {
-	Address common.Address
-	Coins   []common.Address
-	Name    string
+	Address  common.Address
+	Coins    []common.Address
+	Decimals []uint8 // Of each coin; CryptoSwap pools need them
+	Name     string
+	Type     CurvePoolType
+	ABI      CurveABI // Left unknown, it is probed on first use
+	// Underlying pools list the coins they lend out, which are quoted by
+	// get_dy_underlying and swapped by exchange_underlying
+	Underlying bool
+	// ETH pools hold native ETH where Coins lists WETH
+	ETH bool
 }
 
 var curvePools = []CurvePool{
@@ -47,12 +82,47 @@ var curvePools = []CurvePool{
 			entities.USDT.Address,
 		},
 		Name: "3pool",
+		ABI:  CurveABIStable,
+	},
+	{
+		// The pool holds native ETH at index 0; WETH is quoted 1:1 against it
+		Address: CurveStETHAddress,
+		Coins: []common.Address{
+			entities.WETH.Address,
+			entities.STETH.Address,
+		},
+		Name: "steth",
+		ABI:  CurveABIStable,
+		ETH:  true,
+	},
+	{
+		// tBTC has 18 decimals and WBTC 8; get_dy scales between them
+		Address: CurveTBTCAddress,
+		Coins: []common.Address{
+			entities.TBTC.Address,
+			entities.WBTC.Address,
+		},
+		Name: "tbtc",
+	},
+	{
+		Address: CurveTricrypto2Address,
+		Coins: []common.Address{
+			entities.USDT.Address,
+			entities.WBTC.Address,
+			entities.WETH.Address,
+		},
+		Decimals: []uint8{6, 8, 18},
+		Name:     "tricrypto2",
+		Type:     CurveCryptoSwap,
+		ABI:      CurveABICrypto,
 	},
 }
 
 type CurveClient struct {
 	ethClient *ethclient.Client
 	pools     []CurvePool
+	abis      sync.Map // Probed CurveABI by pool address
+	fees      feeCache
 }
 
 func NewCurveClient(ethClient *ethclient.Client) *CurveClient {
@@ -81,6 +151,11 @@ func (c *CurveClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.
 }
 
 func (c *CurveClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
 	poolAddress, err := c.GetPairAddress(ctx, tokenA.Address, tokenB.Address)
 	if err != nil {
 		return nil, err
@@ -94,7 +169,7 @@ func (c *CurveClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entiti
 		}
 	}
 	if pool == nil {
-		return nil, fmt.Errorf("pool configuration not found")
+		return nil, fmt.Errorf("%w: pool configuration not found", ErrPoolNotFound)
 	}
 
 	idxA, idxB := -1, -1
@@ -107,24 +182,42 @@ func (c *CurveClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entiti
 		}
 	}
 	if idxA == -1 || idxB == -1 {
-		return nil, fmt.Errorf("token not found in pool")
+		return nil, fmt.Errorf("%w: token not in Curve pool", ErrPoolNotFound)
 	}
 
-	balanceA, err := c.getBalance(ctx, poolAddress, idxA)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get balance A: %w", err)
-	}
-	balanceB, err := c.getBalance(ctx, poolAddress, idxB)
+	version, err := c.abi(ctx, pool)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get balance B: %w", err)
+		return nil, err
 	}
 
-	// Get fee (Curve uses 1e10 format, we want basis points)
-	fee, err := c.getFee(ctx, poolAddress)
-	if err != nil {
-		fee = 4
+	// CryptoSwap pools are priced off every balance, so all are read
+	var crypto *entities.CryptoCurve
+	var balanceA, balanceB *big.Int
+	if pool.Type == CurveCryptoSwap {
+		crypto, err = readCryptoCurve(ctx, c.ethClient, pool, version)
+		if err != nil {
+			c.forget(poolAddress)
+			return nil, fmt.Errorf("failed to read %s: %w", pool.Name, err)
+		}
+		balanceA, balanceB = crypto.Balances[idxA], crypto.Balances[idxB]
+	} else {
+		balanceA, err = c.getBalance(ctx, poolAddress, version, idxA)
+		if err != nil {
+			c.forget(poolAddress)
+			return nil, fmt.Errorf("failed to get balance A: %w", err)
+		}
+		balanceB, err = c.getBalance(ctx, poolAddress, version, idxB)
+		if err != nil {
+			c.forget(poolAddress)
+			return nil, fmt.Errorf("failed to get balance B: %w", err)
+		}
 	}
 
+	// Cryptopools move their fee with the balances, so it is read per block
+	fee := c.fees.fee(poolAddress, blockNumber, 4, func() (uint64, error) {
+		return c.getFee(ctx, poolAddress)
+	})
+
 	var token0, token1 entities.Token
 	var reserve0, reserve1 *big.Int
 	if tokenA.Address.Hex() < tokenB.Address.Hex() {
@@ -133,17 +226,30 @@ func (c *CurveClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entiti
 	} else {
 		token0, token1 = tokenB, tokenA
 		reserve0, reserve1 = balanceB, balanceA
+		idxA, idxB = idxB, idxA
+	}
+	if crypto != nil {
+		crypto.Index0, crypto.Index1 = idxA, idxB
 	}
 
 	return &entities.Pair{
-		Address:   poolAddress,
-		Token0:    token0,
-		Token1:    token1,
-		Reserve0:  reserve0,
-		Reserve1:  reserve1,
-		DEX:       entities.DEXCurve,
-		Fee:       fee,
-		UpdatedAt: time.Now().Unix(),
+		Address:     poolAddress,
+		Token0:      token0,
+		Token1:      token1,
+		Reserve0:    reserve0,
+		Reserve1:    reserve1,
+		DEX:         entities.DEXCurve,
+		Fee:         fee,
+		UpdatedAt:   time.Now().Unix(),
+		BlockNumber: blockNumber,
+		Crypto:      crypto,
+		CurveSwap: &entities.CurveSwap{
+			Index0:     idxA,
+			Index1:     idxB,
+			Uint256:    version == CurveABICrypto,
+			Underlying: pool.Underlying,
+			ETH:        pool.ETH,
+		},
 	}, nil
 }
 
@@ -161,7 +267,7 @@ func (c *CurveClient) GetAmountOut(ctx context.Context, amountIn *big.Int, token
 		}
 	}
 	if pool == nil {
-		return nil, fmt.Errorf("pool not found")
+		return nil, fmt.Errorf("%w: no Curve pool for token pair", ErrPoolNotFound)
 	}
 
 	idxIn, idxOut := -1, -1
@@ -174,32 +280,23 @@ func (c *CurveClient) GetAmountOut(ctx context.Context, amountIn *big.Int, token
 		}
 	}
 	if idxIn == -1 || idxOut == -1 {
-		return nil, fmt.Errorf("token not found in pool")
+		return nil, fmt.Errorf("%w: token not in Curve pool", ErrPoolNotFound)
 	}
 
-	// Call get_dy(i, j, dx)
-	data := make([]byte, 100)
-	copy(data[0:4], getDySelector)
-	// i (int128) - padded to 32 bytes
-	big.NewInt(int64(idxIn)).FillBytes(data[4:36])
-	// j (int128) - padded to 32 bytes
-	big.NewInt(int64(idxOut)).FillBytes(data[36:68])
-	// dx (uint256) - padded to 32 bytes
-	amountIn.FillBytes(data[68:100])
-
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &poolAddress,
-		Data: data,
-	})
+	version, err := c.abi(ctx, pool)
 	if err != nil {
-		return nil, fmt.Errorf("get_dy call failed: %w", err)
+		return nil, err
 	}
-
-	if len(result) < 32 {
-		return nil, fmt.Errorf("invalid get_dy response")
+	call, unpack := version.packGetDy(idxIn, idxOut, amountIn), curvePool.UnpackGetDy
+	if pool.Underlying {
+		call, unpack = curvePool.PackGetDyUnderlying(big.NewInt(int64(idxIn)), big.NewInt(int64(idxOut)), amountIn), curvePool.UnpackGetDyUnderlying
 	}
-
-	return new(big.Int).SetBytes(result[0:32]), nil
+	amountOut, err := callView(ctx, c.ethClient, poolAddress, call, unpack)
+	if err != nil {
+		c.forget(poolAddress)
+		return nil, fmt.Errorf("get_dy call failed: %w", err)
+	}
+	return amountOut, nil
 }
 
 // DEXType returns the DEX type
@@ -207,44 +304,153 @@ func (c *CurveClient) DEXType() entities.DEXType {
 	return entities.DEXCurve
 }
 
+// Capabilities returns what Curve pools support. StableSwap pairs hold
+// balances without the amplification, so only get_dy prices them;
+// CryptoSwap pairs carry their curve for price impact, but are still
+// quoted by get_dy.
+func (c *CurveClient) Capabilities() Capabilities {
+	return Capabilities{
+		FeeModel:          FeeDynamic,
+		NeedsOnchainQuote: true,
+	}
+}
+
 // getBalance fetches the balance of a token at a given index
-func (c *CurveClient) getBalance(ctx context.Context, pool common.Address, idx int) (*big.Int, error) {
-	data := make([]byte, 36)
-	copy(data[0:4], balancesSelector)
-	big.NewInt(int64(idx)).FillBytes(data[4:36])
+func (c *CurveClient) getBalance(ctx context.Context, pool common.Address, version CurveABI, idx int) (*big.Int, error) {
+	return callView(ctx, c.ethClient, pool, version.packBalances(idx), curvePool.UnpackBalances)
+}
 
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &pool,
-		Data: data,
-	})
+// readCryptoCurve reads a CryptoSwap pool's balances and the parameters of
+// its invariant, price scale and dynamic fee
+func readCryptoCurve(ctx context.Context, caller contractCaller, pool *CurvePool, version CurveABI) (*entities.CryptoCurve, error) {
+	n := len(pool.Coins)
+	if len(pool.Decimals) != n {
+		return nil, fmt.Errorf("pool config has %d decimals for %d coins", len(pool.Decimals), n)
+	}
+	curve := &entities.CryptoCurve{
+		Balances:   make([]*big.Int, n),
+		Precisions: make([]*big.Int, n),
+		PriceScale: make([]*big.Int, n-1),
+	}
+	for i := 0; i < n; i++ {
+		balance, err := callView(ctx, caller, pool.Address, version.packBalances(i), curvePool.UnpackBalances)
+		if err != nil {
+			return nil, fmt.Errorf("balances(%d): %w", i, err)
+		}
+		curve.Balances[i] = balance
+		curve.Precisions[i] = new(big.Int).Exp(big.NewInt(10), big.NewInt(18-int64(pool.Decimals[i])), nil)
+	}
+	// Two-coin pools have a single price_scale()
+	for k := 0; k < n-1; k++ {
+		data := curvePool.PackPriceScale(big.NewInt(int64(k)))
+		if n == 2 {
+			data = curvePool.PackPriceScale0()
+		}
+		scale, err := callView(ctx, caller, pool.Address, data, curvePool.UnpackPriceScale)
+		if err != nil {
+			return nil, fmt.Errorf("price_scale(%d): %w", k, err)
+		}
+		curve.PriceScale[k] = scale
+	}
+
+	params := []struct {
+		name   string
+		data   []byte
+		unpack func([]byte) (*big.Int, error)
+		into   **big.Int
+	}{
+		{"A", curvePool.PackA(), curvePool.UnpackA, &curve.A},
+		{"gamma", curvePool.PackGamma(), curvePool.UnpackGamma, &curve.Gamma},
+		{"D", curvePool.PackD(), curvePool.UnpackD, &curve.D},
+		{"mid_fee", curvePool.PackMidFee(), curvePool.UnpackMidFee, &curve.MidFee},
+		{"out_fee", curvePool.PackOutFee(), curvePool.UnpackOutFee, &curve.OutFee},
+		{"fee_gamma", curvePool.PackFeeGamma(), curvePool.UnpackFeeGamma, &curve.FeeGamma},
+	}
+	for _, param := range params {
+		value, err := callView(ctx, caller, pool.Address, param.data, param.unpack)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", param.name, err)
+		}
+		*param.into = value
+	}
+	return curve, nil
+}
+
+// abi returns the ABI generation pool speaks, probing and remembering it
+// when the pool's config leaves it out
+func (c *CurveClient) abi(ctx context.Context, pool *CurvePool) (CurveABI, error) {
+	if pool.ABI != CurveABIUnknown {
+		return pool.ABI, nil
+	}
+	if version, ok := c.abis.Load(pool.Address); ok {
+		return version.(CurveABI), nil
+	}
+	version, err := probeCurveABI(ctx, c.ethClient, pool.Address)
 	if err != nil {
-		return nil, err
+		return CurveABIUnknown, fmt.Errorf("failed to probe %s pool: %w", pool.Name, err)
 	}
+	c.abis.Store(pool.Address, version)
+	return version, nil
+}
 
-	if len(result) < 32 {
-		return nil, fmt.Errorf("invalid balance response")
+// forget drops a probed ABI after a failed call, so a proxy upgraded to a
+// newer implementation is probed again
+func (c *CurveClient) forget(pool common.Address) {
+	c.abis.Delete(pool)
+}
+
+// probeCurveABI works out which generation of the Curve ABI pool speaks.
+// Vyper pools revert on selectors they don't define, so the first variant
+// that answers is the one.
+func probeCurveABI(ctx context.Context, caller contractCaller, pool common.Address) (CurveABI, error) {
+	zero, one := big.NewInt(0), big.NewInt(1)
+	if _, err := callView(ctx, caller, pool, curvePool.PackCoins(zero), curvePool.UnpackCoins); err != nil {
+		if _, err := callView(ctx, caller, pool, curvePool.PackCoins0(zero), curvePool.UnpackCoins0); err != nil {
+			return CurveABIUnknown, fmt.Errorf("neither coins(uint256) nor coins(int128) answered: %w", err)
+		}
+		return CurveABILegacy, nil
+	}
+	if _, err := callView(ctx, caller, pool, curvePool.PackGetDy(zero, one, one), curvePool.UnpackGetDy); err == nil {
+		return CurveABIStable, nil
+	}
+	if _, err := callView(ctx, caller, pool, curvePool.PackGetDy0(zero, one, one), curvePool.UnpackGetDy0); err != nil {
+		return CurveABIUnknown, fmt.Errorf("neither get_dy(int128) nor get_dy(uint256) answered: %w", err)
 	}
+	return CurveABICrypto, nil
+}
 
-	return new(big.Int).SetBytes(result[0:32]), nil
+// packGetDy encodes get_dy(i, j, dx). GetDy0 is the uint256 overload.
+func (a CurveABI) packGetDy(i, j int, dx *big.Int) []byte {
+	if a == CurveABICrypto {
+		return curvePool.PackGetDy0(big.NewInt(int64(i)), big.NewInt(int64(j)), dx)
+	}
+	return curvePool.PackGetDy(big.NewInt(int64(i)), big.NewInt(int64(j)), dx)
+}
+
+// packBalances encodes balances(i). Balances0 is the int128 overload.
+func (a CurveABI) packBalances(i int) []byte {
+	if a == CurveABILegacy {
+		return curvePool.PackBalances0(big.NewInt(int64(i)))
+	}
+	return curvePool.PackBalances(big.NewInt(int64(i)))
 }
 
 // getFee fetches the pool fee and converts to basis points
 func (c *CurveClient) getFee(ctx context.Context, pool common.Address) (uint64, error) {
 	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
 		To:   &pool,
-		Data: feeSelector,
+		Data: curvePool.PackFee(),
 	})
 	if err != nil {
 		return 0, err
 	}
 
-	if len(result) < 32 {
-		return 0, fmt.Errorf("invalid fee response")
-	}
-
 	// Curve fee is in 1e10 format (e.g., 4000000 = 0.04%)
 	// Convert to basis points (1 bp = 0.01%)
-	fee := new(big.Int).SetBytes(result[0:32])
+	fee, err := curvePool.UnpackFee(result)
+	if err != nil {
+		return 0, err
+	}
 	// fee_bps = fee / 1e6
 	feeBps := new(big.Int).Div(fee, big.NewInt(1e6))
 	return feeBps.Uint64(), nil
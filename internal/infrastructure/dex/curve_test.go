@@ -0,0 +1,153 @@
+package dex
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// selectorCaller answers the calls whose selector it holds and reverts the rest
+type selectorCaller map[string][]byte
+
+func (m selectorCaller) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	if result, ok := m[common.Bytes2Hex(msg.Data[:4])]; ok {
+		return result, nil
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func TestProbeCurveABI(t *testing.T) {
+	word := common.LeftPadBytes([]byte{1}, 32)
+	zero := big.NewInt(0)
+	coinsUint := common.Bytes2Hex(curvePool.PackCoins(zero)[:4])
+	coinsInt := common.Bytes2Hex(curvePool.PackCoins0(zero)[:4])
+	getDyInt := common.Bytes2Hex(CurveABIStable.packGetDy(0, 1, zero)[:4])
+	getDyUint := common.Bytes2Hex(CurveABICrypto.packGetDy(0, 1, zero)[:4])
+
+	tests := []struct {
+		name    string
+		caller  selectorCaller
+		want    CurveABI
+		wantErr bool
+	}{
+		{"stable", selectorCaller{coinsUint: word, getDyInt: word}, CurveABIStable, false},
+		{"legacy", selectorCaller{coinsInt: word, getDyInt: word}, CurveABILegacy, false},
+		{"crypto", selectorCaller{coinsUint: word, getDyUint: word}, CurveABICrypto, false},
+		{"no coins", selectorCaller{getDyInt: word}, CurveABIUnknown, true},
+		{"no get_dy", selectorCaller{coinsUint: word}, CurveABIUnknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := probeCurveABI(context.Background(), tt.caller, Curve3PoolAddress)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeCurveABI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("probeCurveABI() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurveABIPacksIndexes(t *testing.T) {
+	tests := []struct {
+		abi             CurveABI
+		getDy, balances string
+	}{
+		{CurveABIStable, "5e0d443f", "4903b0d1"},
+		{CurveABILegacy, "5e0d443f", "065a80d8"},
+		{CurveABICrypto, "556d6e9f", "4903b0d1"},
+	}
+	for _, tt := range tests {
+		if got := tt.abi.packGetDy(0, 1, big.NewInt(1)); !bytes.Equal(got[:4], common.Hex2Bytes(tt.getDy)) {
+			t.Errorf("ABI %d get_dy selector = %x, want %s", tt.abi, got[:4], tt.getDy)
+		}
+		if got := tt.abi.packBalances(1); !bytes.Equal(got[:4], common.Hex2Bytes(tt.balances)) {
+			t.Errorf("ABI %d balances selector = %x, want %s", tt.abi, got[:4], tt.balances)
+		}
+	}
+}
+
+func TestReadCryptoCurve(t *testing.T) {
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	selector := func(data []byte) string { return common.Bytes2Hex(data[:4]) }
+	one := big.NewInt(1)
+	caller := selectorCaller{
+		selector(curvePool.PackBalances(one)):   word(1000),
+		selector(curvePool.PackPriceScale(one)): word(7),
+		selector(curvePool.PackA()):             word(1),
+		selector(curvePool.PackGamma()):         word(2),
+		selector(curvePool.PackD()):             word(3),
+		selector(curvePool.PackMidFee()):        word(4),
+		selector(curvePool.PackOutFee()):        word(5),
+		selector(curvePool.PackFeeGamma()):      word(6),
+	}
+
+	pool := curvePools[3]
+	curve, err := readCryptoCurve(context.Background(), caller, &pool, CurveABICrypto)
+	if err != nil {
+		t.Fatalf("readCryptoCurve() error = %v", err)
+	}
+	if len(curve.Balances) != 3 || len(curve.PriceScale) != 2 || curve.PriceScale[1].Int64() != 7 {
+		t.Errorf("balances %v, price scale %v", curve.Balances, curve.PriceScale)
+	}
+	if curve.Precisions[0].Cmp(big.NewInt(1e12)) != 0 || curve.Precisions[2].Int64() != 1 {
+		t.Errorf("precisions = %v, want 1e12 for USDT and 1 for WETH", curve.Precisions)
+	}
+	if curve.A.Int64() != 1 || curve.Gamma.Int64() != 2 || curve.D.Int64() != 3 ||
+		curve.MidFee.Int64() != 4 || curve.OutFee.Int64() != 5 || curve.FeeGamma.Int64() != 6 {
+		t.Errorf("params = %+v", curve)
+	}
+
+	// Two-coin pools only answer price_scale()
+	pair := CurvePool{Address: pool.Address, Coins: pool.Coins[1:], Decimals: pool.Decimals[1:], Type: CurveCryptoSwap}
+	if _, err := readCryptoCurve(context.Background(), caller, &pair, CurveABICrypto); err == nil {
+		t.Error("readCryptoCurve() on a two-coin pool called price_scale(uint256)")
+	}
+	caller[selector(curvePool.PackPriceScale0())] = word(7)
+	delete(caller, selector(curvePool.PackPriceScale(one)))
+	if curve, err := readCryptoCurve(context.Background(), caller, &pair, CurveABICrypto); err != nil || len(curve.PriceScale) != 1 {
+		t.Errorf("readCryptoCurve() on a two-coin pool = %v, %v", curve, err)
+	}
+}
+
+func TestCurveStETHPool(t *testing.T) {
+	clients, err := Build(nil, []entities.DEXType{entities.DEXCurve})
+	if err != nil {
+		t.Fatalf("Build(curve) error = %v", err)
+	}
+	curve := clients[0].(*CurveClient)
+
+	tests := []struct {
+		name           string
+		tokenA, tokenB entities.Token
+	}{
+		{"WETH to stETH", entities.WETH, entities.STETH},
+		{"stETH to WETH", entities.STETH, entities.WETH},
+	}
+	for _, tt := range tests {
+		got, err := curve.GetPairAddress(context.Background(), tt.tokenA.Address, tt.tokenB.Address)
+		if err != nil || got != CurveStETHAddress {
+			t.Errorf("%s: GetPairAddress() = %s, %v, want the steth pool", tt.name, got.Hex(), err)
+		}
+	}
+
+	// The pool holds native ETH at coin 0, quoted and swapped as WETH
+	for _, pool := range curve.pools {
+		if pool.Address != CurveStETHAddress {
+			continue
+		}
+		if pool.Coins[0] != entities.WETH.Address || pool.Coins[1] != entities.STETH.Address || !pool.ETH {
+			t.Errorf("steth pool = %+v, want WETH at coin 0 held as ETH", pool)
+		}
+		return
+	}
+	t.Error("steth pool not registered")
+}
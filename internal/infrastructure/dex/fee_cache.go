@@ -0,0 +1,51 @@
+package dex
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// feeCache remembers the fee each pool charged at the block it was read.
+// Pools with dynamic fees, such as Curve cryptopools and Balancer pools
+// whose owner sets the fee, can change it between blocks, so a fee is only
+// reused within its block. When a read fails the last fee read stands in.
+type feeCache struct {
+	mu   sync.Mutex
+	fees map[common.Address]cachedFee
+}
+
+type cachedFee struct {
+	bps   uint64
+	block uint64
+}
+
+// fee returns the pool's fee in basis points at block, calling read unless
+// it was already read at that block. A failed read returns the last fee
+// read, or fallback if there is none.
+func (c *feeCache) fee(pool common.Address, block, fallback uint64, read func() (uint64, error)) uint64 {
+	c.mu.Lock()
+	cached, ok := c.fees[pool]
+	c.mu.Unlock()
+	if ok && block != 0 && cached.block == block {
+		return cached.bps
+	}
+
+	bps, err := read()
+	if err != nil {
+		if ok {
+			return cached.bps
+		}
+		return fallback
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fees == nil {
+		c.fees = make(map[common.Address]cachedFee)
+	}
+	if current, ok := c.fees[pool]; !ok || current.block <= block {
+		c.fees[pool] = cachedFee{bps: bps, block: block}
+	}
+	return bps
+}
@@ -0,0 +1,45 @@
+package dex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFeeCache(t *testing.T) {
+	var cache feeCache
+	pool := common.HexToAddress("0x1111")
+	reads := 0
+	reader := func(bps uint64, err error) func() (uint64, error) {
+		return func() (uint64, error) {
+			reads++
+			return bps, err
+		}
+	}
+
+	tests := []struct {
+		name      string
+		block     uint64
+		read      func() (uint64, error)
+		want      uint64
+		wantReads int
+	}{
+		{"no fee read yet falls back", 100, reader(0, errors.New("reverted")), 4, 1},
+		{"first read", 100, reader(30, nil), 30, 2},
+		{"same block is cached", 100, reader(45, nil), 30, 2},
+		{"next block reads again", 101, reader(45, nil), 45, 3},
+		{"failed read keeps the last fee", 102, reader(0, errors.New("timeout")), 45, 4},
+		{"unknown block always reads", 0, reader(50, nil), 50, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cache.fee(pool, tt.block, 4, tt.read); got != tt.want {
+				t.Errorf("fee() = %d, want %d", got, tt.want)
+			}
+			if reads != tt.wantReads {
+				t.Errorf("reads = %d, want %d", reads, tt.wantReads)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package dex
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -9,6 +10,10 @@ import (
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 )
 
+// ErrPoolNotFound is wrapped by GetPairByTokens and GetAmountOut when the
+// venue has no pool for the token pair, as opposed to failing to read one
+var ErrPoolNotFound = errors.New("pool not found")
+
 // DEXClient defines the interface for interacting with a DEX
 type DEXClient interface {
 	GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error)
@@ -19,4 +24,44 @@ type DEXClient interface {
 
 	// DEXType returns the type of DEX
 	DEXType() entities.DEXType
+
+	// Capabilities describes what the venue supports, so callers can
+	// decide by feature rather than by DEXType
+	Capabilities() Capabilities
+}
+
+// FeeModel is how a venue charges its swap fee
+type FeeModel string
+
+const (
+	// FeeFixed is one fee for every pool, e.g. Uniswap V2's 0.3%
+	FeeFixed FeeModel = "fixed"
+	// FeeTiered is one of a fixed set of fees per pool, e.g. V3 fee tiers
+	FeeTiered FeeModel = "tiered"
+	// FeeDynamic is a per-pool fee its governance can change at any time
+	FeeDynamic FeeModel = "dynamic"
+	// FeeNone charges nothing, e.g. wrapping at a contract rate
+	FeeNone FeeModel = "none"
+)
+
+// Capabilities are the features a venue supports
+type Capabilities struct {
+	// SupportsExactOut is set when the venue can swap for an exact output
+	SupportsExactOut bool
+	// SupportsMultiHopNative is set when the venue's router executes a
+	// multi-pool path itself in one call
+	SupportsMultiHopNative bool
+	FeeModel               FeeModel
+	// NeedsOnchainQuote is set when the pair a venue returns can't price a
+	// trade by itself, so amounts must come from GetAmountOut
+	NeedsOnchainQuote bool
+}
+
+// MultiPoolClient is implemented by venues that hold several pools for one
+// pair, such as Uniswap V3's fee tiers, so an order can be split across them
+type MultiPoolClient interface {
+	DEXClient
+
+	// GetPairsByTokens returns every pool holding the pair
+	GetPairsByTokens(ctx context.Context, tokenA, tokenB entities.Token) ([]*entities.Pair, error)
 }
@@ -0,0 +1,108 @@
+package dex
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+var wstETH = bindings.NewWstETH()
+
+// lidoWrap is the wrap LidoClient quotes
+var lidoWrap = entities.WrapRoute{Underlying: entities.STETH, Wrapped: entities.WSTETH, DEX: entities.DEXLido}
+
+// LidoClient quotes stETH <-> wstETH wrap/unwrap at the wstETH contract rate
+type LidoClient struct {
+	ethClient *ethclient.Client
+	wstETH    common.Address
+	stETH     common.Address
+}
+
+func NewLidoClient(ethClient *ethclient.Client) *LidoClient {
+	return &LidoClient{
+		ethClient: ethClient,
+		wstETH:    entities.WSTETH.Address,
+		stETH:     entities.STETH.Address,
+	}
+}
+
+func (c *LidoClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
+	if !c.supports(tokenA, tokenB) {
+		return common.Address{}, fmt.Errorf("%w: lido wrapper only supports stETH/wstETH", ErrPoolNotFound)
+	}
+	return c.wstETH, nil
+}
+
+func (c *LidoClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	if !c.supports(tokenA.Address, tokenB.Address) {
+		return nil, fmt.Errorf("%w: lido wrapper only supports stETH/wstETH", ErrPoolNotFound)
+	}
+
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	rate, err := readStEthPerToken(ctx, c.ethClient, c.wstETH)
+	if err != nil {
+		return nil, err
+	}
+	return newWrapPair(lidoWrap, rate, blockNumber), nil
+}
+
+func (c *LidoClient) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	if !c.supports(tokenIn.Address, tokenOut.Address) {
+		return nil, fmt.Errorf("%w: lido wrapper only supports stETH/wstETH", ErrPoolNotFound)
+	}
+	if amountIn == nil || amountIn.Sign() <= 0 {
+		return big.NewInt(0), nil
+	}
+
+	return convertWstETH(ctx, c.ethClient, c.wstETH, amountIn, tokenIn.Address == c.wstETH)
+}
+
+// DEXType returns the DEX type
+func (c *LidoClient) DEXType() entities.DEXType {
+	return entities.DEXLido
+}
+
+// Capabilities returns what the wstETH wrapper supports
+func (c *LidoClient) Capabilities() Capabilities {
+	return Capabilities{FeeModel: FeeNone}
+}
+
+// readStEthPerToken fetches the amount of stETH backing one wstETH (18 decimals)
+func readStEthPerToken(ctx context.Context, caller contractCaller, wrapper common.Address) (*big.Int, error) {
+	rate, err := callView(ctx, caller, wrapper, wstETH.PackStEthPerToken(), wstETH.UnpackStEthPerToken)
+	if err != nil {
+		return nil, fmt.Errorf("stEthPerToken call failed: %w", err)
+	}
+	if rate.Sign() == 0 {
+		return nil, fmt.Errorf("wstETH rate is zero")
+	}
+	return rate, nil
+}
+
+// convertWstETH quotes a wrap of stETH, or an unwrap of wstETH, through the
+// wrapper's own conversion, which rounds as the contract does
+func convertWstETH(ctx context.Context, caller contractCaller, wrapper common.Address, amountIn *big.Int, unwrap bool) (*big.Int, error) {
+	data, unpack := wstETH.PackGetWstETHByStETH(amountIn), wstETH.UnpackGetWstETHByStETH
+	if unwrap {
+		data, unpack = wstETH.PackGetStETHByWstETH(amountIn), wstETH.UnpackGetStETHByWstETH
+	}
+	amountOut, err := callView(ctx, caller, wrapper, data, unpack)
+	if err != nil {
+		return nil, fmt.Errorf("wstETH conversion call failed: %w", err)
+	}
+	return amountOut, nil
+}
+
+func (c *LidoClient) supports(tokenA, tokenB common.Address) bool {
+	return (tokenA == c.stETH && tokenB == c.wstETH) || (tokenA == c.wstETH && tokenB == c.stETH)
+}
@@ -0,0 +1,78 @@
+package dex
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestLidoWrapRate(t *testing.T) {
+	selector := func(data []byte) string { return common.Bytes2Hex(data[:4]) }
+	ether := func(milli int64) *big.Int { return new(big.Int).Mul(big.NewInt(milli), big.NewInt(1e15)) }
+	ctx := context.Background()
+	// 1.15 stETH backs each wstETH
+	caller := selectorCaller{selector(wstETH.PackStEthPerToken()): common.LeftPadBytes(ether(1150).Bytes(), 32)}
+
+	rate, err := readStEthPerToken(ctx, caller, entities.WSTETH.Address)
+	if err != nil {
+		t.Fatalf("readStEthPerToken() error = %v", err)
+	}
+	pair := newWrapPair(lidoWrap, rate, 100)
+	if pair.Address != entities.WSTETH.Address || pair.DEX != entities.DEXLido || pair.BlockNumber != 100 {
+		t.Errorf("pair = %s on %s at block %d, want the wstETH wrapper", pair.Address.Hex(), pair.DEX, pair.BlockNumber)
+	}
+
+	tests := []struct {
+		name     string
+		tokenIn  entities.Token
+		amountIn *big.Int
+		want     *big.Int
+	}{
+		{"unwrap", entities.WSTETH, ether(2000), ether(2300)},
+		{"wrap", entities.STETH, ether(2300), ether(2000)},
+		// Wrapping rounds down, as the contract does
+		{"wrap dust", entities.STETH, big.NewInt(2), big.NewInt(1)},
+	}
+	for _, tt := range tests {
+		if got := pair.GetAmountOut(tt.amountIn, tt.tokenIn.Address); got.Cmp(tt.want) != 0 {
+			t.Errorf("%s: GetAmountOut(%s %s) = %s, want %s", tt.name, tt.amountIn, tt.tokenIn.Symbol, got, tt.want)
+		}
+	}
+
+	caller[selector(wstETH.PackStEthPerToken())] = make([]byte, 32)
+	if _, err := readStEthPerToken(ctx, caller, entities.WSTETH.Address); err == nil {
+		t.Error("readStEthPerToken() accepted a zero rate")
+	}
+}
+
+func TestConvertWstETH(t *testing.T) {
+	selector := func(data []byte) string { return common.Bytes2Hex(data[:4]) }
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	one := big.NewInt(1)
+	caller := selectorCaller{
+		selector(wstETH.PackGetWstETHByStETH(one)): word(869),
+		selector(wstETH.PackGetStETHByWstETH(one)): word(1150),
+	}
+
+	tests := []struct {
+		name   string
+		unwrap bool
+		want   int64
+	}{
+		{"wrap", false, 869},
+		{"unwrap", true, 1150},
+	}
+	for _, tt := range tests {
+		got, err := convertWstETH(context.Background(), caller, entities.WSTETH.Address, big.NewInt(1000), tt.unwrap)
+		if err != nil {
+			t.Fatalf("%s: convertWstETH() error = %v", tt.name, err)
+		}
+		if got.Int64() != tt.want {
+			t.Errorf("%s: convertWstETH() = %s, want %d", tt.name, got, tt.want)
+		}
+	}
+}
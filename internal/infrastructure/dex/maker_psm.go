@@ -0,0 +1,160 @@
+package dex
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+var (
+	makerPSM = bindings.NewMakerPSM()
+	erc20    = bindings.NewERC20()
+)
+
+// MakerLitePSMAddress is Maker's Lite PSM for USDC, swapping it with DAI at par
+var MakerLitePSMAddress = common.HexToAddress("0xf6e72Db5454dd049d0788e411b06CfAF16853042")
+
+// DaiUsdsAddress is Sky's converter between DAI and USDS, 1:1 both ways
+var DaiUsdsAddress = common.HexToAddress("0x3225737a9Bbb6473CB4a45b7244ACa2BeFdB276A")
+
+// psmConverter is a contract converting gem into dai and back at the
+// ratio of their decimals
+type psmConverter struct {
+	address common.Address
+	gem     entities.Token
+	dai     entities.Token
+	// lite converters charge tin and tout and pay gems out of their
+	// pocket; the others mint either side for free
+	lite bool
+}
+
+var psmConverters = []psmConverter{
+	{address: MakerLitePSMAddress, gem: entities.USDC, dai: entities.DAI, lite: true},
+	// DaiUsds burns one token to mint the other, so it quotes as a PSM
+	// without fees or limits
+	{address: DaiUsdsAddress, gem: entities.DAI, dai: entities.USDS},
+}
+
+// MakerPSMClient quotes Maker's fixed-rate converters: the Lite PSM for
+// USDC↔DAI and DaiUsds for DAI↔USDS. They swap without slippage, so for
+// stablecoin trades they often beat every pool.
+type MakerPSMClient struct {
+	ethClient  *ethclient.Client
+	converters []psmConverter
+}
+
+func NewMakerPSMClient(ethClient *ethclient.Client) *MakerPSMClient {
+	return &MakerPSMClient{ethClient: ethClient, converters: psmConverters}
+}
+
+func (c *MakerPSMClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
+	converter, ok := c.converterFor(tokenA, tokenB)
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: no maker converter for the pair", ErrPoolNotFound)
+	}
+	return converter.address, nil
+}
+
+func (c *MakerPSMClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	converter, ok := c.converterFor(tokenA.Address, tokenB.Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: no maker converter for the pair", ErrPoolNotFound)
+	}
+
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	pair, err := readPSMPair(ctx, c.ethClient, converter)
+	if err != nil {
+		return nil, err
+	}
+	pair.BlockNumber = blockNumber
+	return pair, nil
+}
+
+func (c *MakerPSMClient) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	if amountIn == nil || amountIn.Sign() <= 0 {
+		return big.NewInt(0), nil
+	}
+	pair, err := c.GetPairByTokens(ctx, tokenIn, tokenOut)
+	if err != nil {
+		return nil, err
+	}
+	return pair.GetAmountOut(amountIn, tokenIn.Address), nil
+}
+
+// DEXType returns the DEX type
+func (c *MakerPSMClient) DEXType() entities.DEXType {
+	return entities.DEXMakerPSM
+}
+
+// Capabilities returns what the converters support. Governance sets the
+// PSM's fees, which are read with the pair.
+func (c *MakerPSMClient) Capabilities() Capabilities {
+	return Capabilities{SupportsExactOut: true, FeeModel: FeeDynamic}
+}
+
+func (c *MakerPSMClient) converterFor(tokenA, tokenB common.Address) (psmConverter, bool) {
+	for _, converter := range c.converters {
+		if (tokenA == converter.gem.Address && tokenB == converter.dai.Address) ||
+			(tokenA == converter.dai.Address && tokenB == converter.gem.Address) {
+			return converter, true
+		}
+	}
+	return psmConverter{}, false
+}
+
+// readPSMPair reads a converter's fees and what it can pay out of each
+// side: gems held by a Lite PSM's pocket and the DAI it holds itself
+func readPSMPair(ctx context.Context, caller contractCaller, converter psmConverter) (*entities.Pair, error) {
+	to18 := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(converter.dai.Decimals)-int64(converter.gem.Decimals)), nil)
+	fixed := &entities.FixedRate{Gem: converter.gem.Address, To18: to18, Tin: big.NewInt(0), Tout: big.NewInt(0)}
+	reserveGem, reserveDai := new(big.Int).Set(wrapperVirtualDepth), new(big.Int).Set(wrapperVirtualDepth)
+
+	if converter.lite {
+		var err error
+		if fixed.Tin, err = callView(ctx, caller, converter.address, makerPSM.PackTin(), makerPSM.UnpackTin); err != nil {
+			return nil, fmt.Errorf("psm tin call failed: %w", err)
+		}
+		if fixed.Tout, err = callView(ctx, caller, converter.address, makerPSM.PackTout(), makerPSM.UnpackTout); err != nil {
+			return nil, fmt.Errorf("psm tout call failed: %w", err)
+		}
+		pocket, err := callView(ctx, caller, converter.address, makerPSM.PackPocket(), makerPSM.UnpackPocket)
+		if err != nil {
+			return nil, fmt.Errorf("psm pocket call failed: %w", err)
+		}
+		if reserveGem, err = callView(ctx, caller, converter.gem.Address, erc20.PackBalanceOf(pocket), erc20.UnpackBalanceOf); err != nil {
+			return nil, fmt.Errorf("%s balanceOf call failed: %w", converter.gem.Symbol, err)
+		}
+		if reserveDai, err = callView(ctx, caller, converter.dai.Address, erc20.PackBalanceOf(converter.address), erc20.UnpackBalanceOf); err != nil {
+			return nil, fmt.Errorf("%s balanceOf call failed: %w", converter.dai.Symbol, err)
+		}
+	}
+
+	token0, token1 := converter.gem, converter.dai
+	reserve0, reserve1 := reserveGem, reserveDai
+	if token1.Address.Hex() < token0.Address.Hex() {
+		token0, token1 = token1, token0
+		reserve0, reserve1 = reserve1, reserve0
+	}
+
+	return &entities.Pair{
+		Address:   converter.address,
+		Token0:    token0,
+		Token1:    token1,
+		Reserve0:  reserve0,
+		Reserve1:  reserve1,
+		DEX:       entities.DEXMakerPSM,
+		Fee:       0,
+		UpdatedAt: time.Now().Unix(),
+		Fixed:     fixed,
+	}, nil
+}
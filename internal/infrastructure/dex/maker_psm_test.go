@@ -0,0 +1,51 @@
+package dex
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestReadPSMPair(t *testing.T) {
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	selector := func(data []byte) string { return common.Bytes2Hex(data[:4]) }
+	caller := selectorCaller{
+		selector(makerPSM.PackTin()):                    word(0),
+		selector(makerPSM.PackTout()):                   word(1e15),
+		selector(makerPSM.PackPocket()):                 common.LeftPadBytes([]byte{0xaa}, 32),
+		selector(erc20.PackBalanceOf(common.Address{})): word(5_000e6),
+	}
+
+	pair, err := readPSMPair(context.Background(), caller, psmConverters[0])
+	if err != nil {
+		t.Fatalf("readPSMPair() error = %v", err)
+	}
+	if pair.Token0.Address != entities.DAI.Address || pair.Token1.Address != entities.USDC.Address || pair.Address != MakerLitePSMAddress {
+		t.Errorf("pair = %s/%s at %s, want DAI/USDC at the PSM", pair.Token0.Symbol, pair.Token1.Symbol, pair.Address.Hex())
+	}
+	if pair.Fixed.To18.Cmp(big.NewInt(1e12)) != 0 || pair.Fixed.Tout.Int64() != 1e15 || pair.Reserve1.Int64() != 5_000e6 {
+		t.Errorf("fixed rate %+v, USDC reserve %s", pair.Fixed, pair.Reserve1)
+	}
+	// 0.1% on buying USDC: 1,001 DAI for 1,000 USDC
+	if out := pair.GetAmountOut(new(big.Int).Mul(big.NewInt(1_001), big.NewInt(1e18)), entities.DAI.Address); out.Int64() != 1_000e6 {
+		t.Errorf("GetAmountOut(1001 DAI) = %s, want 1000 USDC", out)
+	}
+
+	delete(caller, selector(makerPSM.PackPocket()))
+	if _, err := readPSMPair(context.Background(), caller, psmConverters[0]); err == nil {
+		t.Error("readPSMPair() without a pocket succeeded")
+	}
+
+	// DaiUsds makes no calls and converts 1:1
+	pair, err = readPSMPair(context.Background(), selectorCaller{}, psmConverters[1])
+	if err != nil {
+		t.Fatalf("readPSMPair(DaiUsds) error = %v", err)
+	}
+	if out := pair.GetAmountOut(big.NewInt(1e18), entities.USDS.Address); out.Int64() != 1e18 {
+		t.Errorf("GetAmountOut(1 USDS) = %s, want 1 DAI", out)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !no_curve
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXCurve, func(ethClient *ethclient.Client) DEXClient {
+		return NewCurveClient(ethClient)
+	})
+}
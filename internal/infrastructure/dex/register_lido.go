@@ -0,0 +1,14 @@
+//go:build !no_lido
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXLido, func(ethClient *ethclient.Client) DEXClient {
+		return NewLidoClient(ethClient)
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !no_maker_psm
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXMakerPSM, func(ethClient *ethclient.Client) DEXClient {
+		return NewMakerPSMClient(ethClient)
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !no_sushiswap
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXSushiswap, func(ethClient *ethclient.Client) DEXClient {
+		return NewSushiswapClient(ethClient)
+	})
+}
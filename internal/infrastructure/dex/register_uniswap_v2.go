@@ -0,0 +1,14 @@
+//go:build !no_uniswap_v2
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXUniswapV2, func(ethClient *ethclient.Client) DEXClient {
+		return NewUniswapV2Client(ethClient)
+	})
+}
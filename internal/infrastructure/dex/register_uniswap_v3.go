@@ -0,0 +1,14 @@
+//go:build !no_uniswap_v3
+
+package dex
+
+import (
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+func init() {
+	Register(entities.DEXUniswapV3, func(ethClient *ethclient.Client) DEXClient {
+		return NewUniswapV3Client(ethClient)
+	})
+}
@@ -0,0 +1,78 @@
+package dex
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+// Factory builds an adapter on top of the shared node client
+type Factory func(ethClient *ethclient.Client) DEXClient
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[entities.DEXType]Factory)
+)
+
+// Register makes an adapter available to Build. Adapters call it from init
+// so a deployment can drop one with a build tag, and third-party packages
+// can add one with a blank import. Registering a name twice panics.
+func Register(dexType entities.DEXType, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("dex: Register factory is nil for " + string(dexType))
+	}
+	if _, dup := factories[dexType]; dup {
+		panic("dex: Register called twice for " + string(dexType))
+	}
+	factories[dexType] = factory
+}
+
+// Registered returns the names of all compiled-in adapters, sorted
+func Registered() []entities.DEXType {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	return registeredLocked()
+}
+
+// Build instantiates the named adapters, or every registered adapter when
+// names is empty. Unknown names are an error so typos don't silently
+// disable a venue.
+func Build(ethClient *ethclient.Client, names []entities.DEXType) ([]DEXClient, error) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	if len(names) == 0 {
+		names = registeredLocked()
+	}
+
+	clients := make([]DEXClient, 0, len(names))
+	seen := make(map[entities.DEXType]bool, len(names))
+	for _, name := range names {
+		factory, ok := factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown dex %q (compiled in: %v)", name, registeredLocked())
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		clients = append(clients, factory(ethClient))
+	}
+	return clients, nil
+}
+
+// registeredLocked lists adapter names. Callers hold factoriesMu.
+func registeredLocked() []entities.DEXType {
+	names := make([]entities.DEXType, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,29 @@
+package dex
+
+import (
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestBuild(t *testing.T) {
+	all, err := Build(nil, nil)
+	if err != nil {
+		t.Fatalf("Build(all) error = %v", err)
+	}
+	if len(all) != len(Registered()) {
+		t.Errorf("Build(all) returned %d adapters, want %d", len(all), len(Registered()))
+	}
+
+	some, err := Build(nil, []entities.DEXType{entities.DEXCurve, entities.DEXUniswapV2, entities.DEXCurve})
+	if err != nil {
+		t.Fatalf("Build(subset) error = %v", err)
+	}
+	if len(some) != 2 || some[0].DEXType() != entities.DEXCurve || some[1].DEXType() != entities.DEXUniswapV2 {
+		t.Errorf("Build(subset) = %v, want [curve uniswap_v2]", some)
+	}
+
+	if _, err := Build(nil, []entities.DEXType{"uniswap_v9"}); err == nil {
+		t.Error("Build() accepted an unknown dex")
+	}
+}
@@ -10,19 +10,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
 	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
-// UniswapV2 ABI function signatures (keccak256 hash of function signature)
 var (
-	// getReserves() returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
-	getReservesSelector = common.Hex2Bytes("0902f1ac")
-	// token0() returns (address)
-	token0Selector = common.Hex2Bytes("0dfe1681")
-	// token1() returns (address)
-	token1Selector = common.Hex2Bytes("d21220a7")
-	// getPair(address,address) returns (address)
-	getPairSelector = common.Hex2Bytes("e6a43905")
+	v2Factory = bindings.NewUniswapV2Factory()
+	v2Pair    = bindings.NewUniswapV2Pair()
 )
 
 var (
@@ -60,43 +54,43 @@ func (c *UniswapV2Client) GetPairAddress(ctx context.Context, tokenA, tokenB com
 	// Sort tokens (Uniswap V2 convention)
 	token0, token1 := sortTokens(tokenA, tokenB)
 
-	// Encode getPair(token0, token1)
-	data := make([]byte, 68)
-	copy(data[0:4], getPairSelector)
-	copy(data[16:36], token0.Bytes())
-	copy(data[48:68], token1.Bytes())
-
 	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
 		To:   &c.factory,
-		Data: data,
+		Data: v2Factory.PackGetPair(token0, token1),
 	})
 	if err != nil {
 		return common.Address{}, fmt.Errorf("failed to get pair address: %w", err)
 	}
 
-	if len(result) < 32 {
-		return common.Address{}, fmt.Errorf("invalid response length")
+	pairAddress, err := v2Factory.UnpackGetPair(result)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid getPair response: %w", err)
 	}
-
-	pairAddress := common.BytesToAddress(result[12:32])
 	return pairAddress, nil
 }
 
 func (c *UniswapV2Client) GetPair(ctx context.Context, pairAddress common.Address, token0, token1 entities.Token) (*entities.Pair, error) {
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
 	reserves, err := c.getReserves(ctx, pairAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	return &entities.Pair{
-		Address:   pairAddress,
-		Token0:    token0,
-		Token1:    token1,
-		Reserve0:  reserves[0],
-		Reserve1:  reserves[1],
-		DEX:       c.dexType,
-		Fee:       c.fee,
-		UpdatedAt: time.Now().Unix(),
+		Address:     pairAddress,
+		Token0:      token0,
+		Token1:      token1,
+		Reserve0:    reserves[0],
+		Reserve1:    reserves[1],
+		DEX:         c.dexType,
+		Fee:         c.fee,
+		UpdatedAt:   time.Now().Unix(),
+		BlockNumber: blockNumber,
+		Factory:     c.factory,
 	}, nil
 }
 
@@ -115,7 +109,7 @@ func (c *UniswapV2Client) GetPairByTokens(ctx context.Context, tokenA, tokenB en
 	}
 
 	if pairAddress == ethclient.ZeroAddress {
-		return nil, fmt.Errorf("pair does not exist")
+		return nil, fmt.Errorf("%w: pair does not exist", ErrPoolNotFound)
 	}
 
 	return c.GetPair(ctx, pairAddress, token0, token1)
@@ -125,20 +119,18 @@ func (c *UniswapV2Client) GetPairByTokens(ctx context.Context, tokenA, tokenB en
 func (c *UniswapV2Client) getReserves(ctx context.Context, pairAddress common.Address) ([2]*big.Int, error) {
 	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
 		To:   &pairAddress,
-		Data: getReservesSelector,
+		Data: v2Pair.PackGetReserves(),
 	})
 	if err != nil {
 		return [2]*big.Int{}, fmt.Errorf("failed to get reserves: %w", err)
 	}
 
-	if len(result) < 64 {
-		return [2]*big.Int{}, fmt.Errorf("invalid reserves response length")
+	reserves, err := v2Pair.UnpackGetReserves(result)
+	if err != nil {
+		return [2]*big.Int{}, fmt.Errorf("invalid reserves response: %w", err)
 	}
 
-	reserve0 := new(big.Int).SetBytes(result[0:32])
-	reserve1 := new(big.Int).SetBytes(result[32:64])
-
-	return [2]*big.Int{reserve0, reserve1}, nil
+	return [2]*big.Int{reserves.Reserve0, reserves.Reserve1}, nil
 }
 
 func (c *UniswapV2Client) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
@@ -155,6 +147,15 @@ func (c *UniswapV2Client) DEXType() entities.DEXType {
 	return c.dexType
 }
 
+// Capabilities returns what V2-style routers support
+func (c *UniswapV2Client) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsExactOut:       true, // swapTokensForExactTokens
+		SupportsMultiHopNative: true, // Router paths
+		FeeModel:               FeeFixed,
+	}
+}
+
 // sortTokens sorts two addresses in ascending order (Uniswap V2 convention)
 func sortTokens(tokenA, tokenB common.Address) (common.Address, common.Address) {
 	if tokenA.Hex() < tokenB.Hex() {
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
 	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
 )
 
@@ -28,17 +29,22 @@ var V3FeeTiers = []uint32{
 }
 
 var (
-	// getPool(address,address,uint24) returns (address)
-	getPoolSelector = common.Hex2Bytes("1698ee82")
-	// quoteExactInputSingle((address,address,uint256,uint24,uint160)) returns (uint256,uint160,uint32,uint256)
-	quoteExactInputSingleSelector = common.Hex2Bytes("c6a5026a")
+	v3Factory      = bindings.NewUniswapV3Factory()
+	v3PoolContract = bindings.NewUniswapV3Pool()
+	v3Quoter       = bindings.NewQuoterV2()
 )
 
+// v3BitmapWords is how many tick bitmap words are read on each side of the
+// current price. A word spans 256 tick spacings, about ±8% of price for
+// the 0.01% tier and far more for the others.
+const v3BitmapWords = 3
+
 // UniswapV3Client fetches price data from Uniswap V3
 type UniswapV3Client struct {
 	ethClient *ethclient.Client
 	factory   common.Address
 	quoter    common.Address
+	tiers     *v3TierIndex
 }
 
 func NewUniswapV3Client(ethClient *ethclient.Client) *UniswapV3Client {
@@ -46,103 +52,255 @@ func NewUniswapV3Client(ethClient *ethclient.Client) *UniswapV3Client {
 		ethClient: ethClient,
 		factory:   UniswapV3FactoryAddress,
 		quoter:    UniswapV3QuoterV2,
+		tiers:     newV3TierIndex(),
 	}
 }
 
 func (c *UniswapV3Client) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
 	token0, token1 := sortTokens(tokenA, tokenB)
-
-	for _, fee := range V3FeeTiers {
-		poolAddr, err := c.getPool(ctx, token0, token1, fee)
-		if err != nil {
-			continue
-		}
-		if poolAddr != ethclient.ZeroAddress {
-			return poolAddr, nil
-		}
+	if pools := c.poolTiers(ctx, token0, token1); len(pools) > 0 {
+		return pools[0].address, nil
 	}
+	return common.Address{}, fmt.Errorf("%w: no V3 pool for token pair", ErrPoolNotFound)
+}
 
-	return common.Address{}, fmt.Errorf("no V3 pool found for token pair")
+// poolTiers returns the pool of each fee tier deployed for the sorted pair,
+// asking the factory about every tier concurrently when the index doesn't
+// have the pair yet
+func (c *UniswapV3Client) poolTiers(ctx context.Context, token0, token1 common.Address) []v3TierPool {
+	return c.tiers.tiers(token0, token1, func() ([]v3TierPool, error) {
+		addrs := make([]common.Address, len(V3FeeTiers))
+		errs := make([]error, len(V3FeeTiers))
+		concurrently(len(V3FeeTiers), func(i int) {
+			addrs[i], errs[i] = c.getPool(ctx, token0, token1, V3FeeTiers[i])
+		})
+
+		var pools []v3TierPool
+		var failed error
+		for i, addr := range addrs {
+			if errs[i] != nil {
+				failed = errs[i]
+				continue
+			}
+			if addr != ethclient.ZeroAddress {
+				pools = append(pools, v3TierPool{fee: V3FeeTiers[i], address: addr})
+			}
+		}
+		return pools, failed
+	})
 }
 
 // getPool calls factory.getPool to get pool address for specific fee tier
 func (c *UniswapV3Client) getPool(ctx context.Context, token0, token1 common.Address, fee uint32) (common.Address, error) {
-	// Encode: getPool(address,address,uint24)
-	data := make([]byte, 100)
-	copy(data[0:4], getPoolSelector)
-	copy(data[16:36], token0.Bytes())
-	copy(data[48:68], token1.Bytes())
-	// fee is uint24, put in last 3 bytes of the 32-byte slot
-	feeBig := big.NewInt(int64(fee))
-	feeBytes := feeBig.Bytes()
-	copy(data[100-len(feeBytes):100], feeBytes)
-
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &c.factory,
-		Data: data,
-	})
+	data := v3Factory.PackGetPool(token0, token1, big.NewInt(int64(fee)))
+	return callView(ctx, c.ethClient, c.factory, data, v3Factory.UnpackGetPool)
+}
+
+// v3Pool is a pool found for one fee tier
+type v3Pool struct {
+	address   common.Address
+	fee       uint32
+	liquidity *big.Int
+}
+
+// GetPairByTokens picks the fee tier with the most in-range liquidity and
+// reads its ticks around the current price, so swaps can be simulated
+// locally with entities.Pair.GetAmountOut
+func (c *UniswapV3Client) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
 	if err != nil {
-		return common.Address{}, err
+		return nil, fmt.Errorf("failed to get block number: %w", err)
 	}
 
-	if len(result) < 32 {
-		return common.Address{}, fmt.Errorf("invalid response length")
+	token0, token1 := sortPairTokens(tokenA, tokenB)
+	pools := c.findPools(ctx, token0.Address, token1.Address)
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("%w: no V3 pool for token pair", ErrPoolNotFound)
 	}
 
-	return common.BytesToAddress(result[12:32]), nil
+	best := pools[0]
+	for _, pool := range pools[1:] {
+		if pool.liquidity.Cmp(best.liquidity) > 0 {
+			best = pool
+		}
+	}
+	return c.readPair(ctx, best, token0, token1, blockNumber)
 }
 
-func (c *UniswapV3Client) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
-	token0, token1 := tokenA, tokenB
-	if tokenA.Address.Hex() > tokenB.Address.Hex() {
-		token0, token1 = tokenB, tokenA
+// GetPairsByTokens reads the pool of every fee tier holding the pair, so an
+// order can be split across tiers. Tiers without in-range liquidity are
+// skipped.
+func (c *UniswapV3Client) GetPairsByTokens(ctx context.Context, tokenA, tokenB entities.Token) ([]*entities.Pair, error) {
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
 	}
 
-	var bestPool common.Address
-	var bestFee uint32
-
-	for _, fee := range V3FeeTiers {
-		poolAddr, err := c.getPool(ctx, token0.Address, token1.Address, fee)
-		if err != nil || poolAddr == ethclient.ZeroAddress {
+	token0, token1 := sortPairTokens(tokenA, tokenB)
+	var pairs []*entities.Pair
+	for _, pool := range c.findPools(ctx, token0.Address, token1.Address) {
+		if pool.liquidity.Sign() == 0 {
 			continue
 		}
-		// Use first found pool (typically 0.3% has most liquidity)
-		bestPool = poolAddr
-		bestFee = fee
-		break
+		pair, err := c.readPair(ctx, pool, token0, token1, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("%w: no V3 pool for token pair", ErrPoolNotFound)
 	}
+	return pairs, nil
+}
 
-	if bestPool == ethclient.ZeroAddress {
-		return nil, fmt.Errorf("no V3 pool found for token pair")
+// findPools returns the deployed pool of each fee tier with its in-range
+// liquidity
+func (c *UniswapV3Client) findPools(ctx context.Context, token0, token1 common.Address) []v3Pool {
+	tiers := c.poolTiers(ctx, token0, token1)
+	liquidity := make([]*big.Int, len(tiers))
+	concurrently(len(tiers), func(i int) {
+		if l, err := callView(ctx, c.ethClient, tiers[i].address, v3PoolContract.PackLiquidity(), v3PoolContract.UnpackLiquidity); err == nil {
+			liquidity[i] = l
+		}
+	})
+
+	var pools []v3Pool
+	for i, tier := range tiers {
+		if liquidity[i] != nil {
+			pools = append(pools, v3Pool{address: tier.address, fee: tier.fee, liquidity: liquidity[i]})
+		}
 	}
+	return pools
+}
+
+// readPair reads a pool's ticks into a pair stamped with blockNumber
+func (c *UniswapV3Client) readPair(ctx context.Context, pool v3Pool, token0, token1 entities.Token, blockNumber uint64) (*entities.Pair, error) {
+	state, err := c.readLiquidity(ctx, pool.address, pool.liquidity)
+	if err != nil {
+		return nil, err
+	}
+	reserve0, reserve1 := state.VirtualReserves()
 
-	// V3 doesn't use reserves like V2, but we create a Pair struct for compatibility
 	return &entities.Pair{
-		Address:   bestPool,
-		Token0:    token0,
-		Token1:    token1,
-		Reserve0:  big.NewInt(0), // V3 uses concentrated liquidity, not reserves
-		Reserve1:  big.NewInt(0),
-		DEX:       entities.DEXUniswapV3,
-		Fee:       uint64(bestFee), // Fee in hundredths of a bip
-		UpdatedAt: time.Now().Unix(),
+		Address:      pool.address,
+		Token0:       token0,
+		Token1:       token1,
+		Reserve0:     reserve0,
+		Reserve1:     reserve1,
+		DEX:          entities.DEXUniswapV3,
+		Fee:          uint64(pool.fee), // Fee in hundredths of a bip
+		UpdatedAt:    time.Now().Unix(),
+		BlockNumber:  blockNumber,
+		Factory:      c.factory,
+		Concentrated: state,
 	}, nil
 }
 
+// sortPairTokens orders two tokens the way pools store them
+func sortPairTokens(tokenA, tokenB entities.Token) (entities.Token, entities.Token) {
+	if tokenA.Address.Hex() > tokenB.Address.Hex() {
+		return tokenB, tokenA
+	}
+	return tokenA, tokenB
+}
+
+// readLiquidity reads the pool's price and every initialized tick within
+// v3BitmapWords bitmap words of it
+func (c *UniswapV3Client) readLiquidity(ctx context.Context, pool common.Address, liquidity *big.Int) (*entities.ConcentratedLiquidity, error) {
+	slot0, err := callView(ctx, c.ethClient, pool, v3PoolContract.PackSlot0(), v3PoolContract.UnpackSlot0)
+	if err != nil {
+		return nil, fmt.Errorf("slot0 call failed: %w", err)
+	}
+	spacingWord, err := callView(ctx, c.ethClient, pool, v3PoolContract.PackTickSpacing(), v3PoolContract.UnpackTickSpacing)
+	if err != nil {
+		return nil, fmt.Errorf("tickSpacing call failed: %w", err)
+	}
+	spacing := int32(spacingWord.Int64())
+	if spacing <= 0 {
+		return nil, fmt.Errorf("invalid tick spacing %d", spacing)
+	}
+
+	state := &entities.ConcentratedLiquidity{
+		SqrtPriceX96: slot0.SqrtPriceX96,
+		Tick:         int32(slot0.Tick.Int64()),
+		Liquidity:    liquidity,
+	}
+
+	// Compressed ticks round towards negative infinity, as in TickBitmap
+	compressed := state.Tick / spacing
+	if state.Tick < 0 && state.Tick%spacing != 0 {
+		compressed--
+	}
+	word := int16(compressed >> 8)
+	firstWord, lastWord := int32(word)-v3BitmapWords, int32(word)+v3BitmapWords
+	state.TickLow = max(firstWord*256*spacing, entities.MinTick)
+	state.TickHigh = min((lastWord*256+255)*spacing, entities.MaxTick)
+
+	calls := make([]ethereum.CallMsg, 0, lastWord-firstWord+1)
+	for w := firstWord; w <= lastWord; w++ {
+		calls = append(calls, ethereum.CallMsg{To: &pool, Data: v3PoolContract.PackTickBitmap(int16(w))})
+	}
+	bitmaps, err := c.ethClient.Multicall(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("tickBitmap call failed: %w", err)
+	}
+
+	var indexes []int32
+	for i, bitmap := range bitmaps {
+		bits, err := v3PoolContract.UnpackTickBitmap(bitmap)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tickBitmap response: %w", err)
+		}
+		for bit := 0; bit < 256; bit++ {
+			if bits.Bit(bit) == 1 {
+				indexes = append(indexes, ((firstWord+int32(i))*256+int32(bit))*spacing)
+			}
+		}
+	}
+
+	calls = calls[:0]
+	for _, index := range indexes {
+		calls = append(calls, ethereum.CallMsg{To: &pool, Data: v3PoolContract.PackTicks(big.NewInt(int64(index)))})
+	}
+	ticks, err := c.ethClient.Multicall(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("ticks call failed: %w", err)
+	}
+	for i, result := range ticks {
+		tick, err := v3PoolContract.UnpackTicks(result)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ticks response: %w", err)
+		}
+		state.Ticks = append(state.Ticks, entities.TickData{
+			Index:        indexes[i],
+			LiquidityNet: tick.LiquidityNet,
+		})
+	}
+	return state, nil
+}
+
 func (c *UniswapV3Client) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
 	if amountIn == nil || amountIn.Sign() <= 0 {
 		return big.NewInt(0), nil
 	}
 
-	var bestAmountOut *big.Int
-
-	for _, fee := range V3FeeTiers {
-		amountOut, err := c.quoteExactInputSingle(ctx, tokenIn.Address, tokenOut.Address, amountIn, fee)
-		if err != nil {
-			continue
+	// Only tiers with a pool are quoted, all at once
+	token0, token1 := sortTokens(tokenIn.Address, tokenOut.Address)
+	tiers := c.poolTiers(ctx, token0, token1)
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("%w: no V3 pool for token pair", ErrPoolNotFound)
+	}
+	amounts := make([]*big.Int, len(tiers))
+	concurrently(len(tiers), func(i int) {
+		if amountOut, err := c.quoteExactInputSingle(ctx, tokenIn.Address, tokenOut.Address, amountIn, tiers[i].fee); err == nil {
+			amounts[i] = amountOut
 		}
+	})
 
-		if bestAmountOut == nil || amountOut.Cmp(bestAmountOut) > 0 {
+	var bestAmountOut *big.Int
+	for _, amountOut := range amounts {
+		if amountOut != nil && (bestAmountOut == nil || amountOut.Cmp(bestAmountOut) > 0) {
 			bestAmountOut = amountOut
 		}
 	}
@@ -155,53 +313,32 @@ func (c *UniswapV3Client) GetAmountOut(ctx context.Context, amountIn *big.Int, t
 }
 
 // quoteExactInputSingle calls QuoterV2 to get exact output amount
-// Struct params: (tokenIn, tokenOut, amountIn, fee, sqrtPriceLimitX96)
 func (c *UniswapV3Client) quoteExactInputSingle(ctx context.Context, tokenIn, tokenOut common.Address, amountIn *big.Int, fee uint32) (*big.Int, error) {
-	// QuoteExactInputSingleParams struct:
-	// - tokenIn (address): 32 bytes
-	// - tokenOut (address): 32 bytes
-	// - amountIn (uint256): 32 bytes
-	// - fee (uint24): 32 bytes
-	// - sqrtPriceLimitX96 (uint160): 32 bytes
-
-	data := make([]byte, 4+32*5) // selector + 5 params
-	copy(data[0:4], quoteExactInputSingleSelector)
-
-	// tokenIn at offset 4
-	copy(data[4+12:4+32], tokenIn.Bytes())
-
-	// tokenOut at offset 36
-	copy(data[36+12:36+32], tokenOut.Bytes())
-
-	// amountIn at offset 68
-	amountInBytes := amountIn.Bytes()
-	copy(data[68+32-len(amountInBytes):68+32], amountInBytes)
-
-	// fee at offset 100
-	feeBig := big.NewInt(int64(fee))
-	feeBytes := feeBig.Bytes()
-	copy(data[100+32-len(feeBytes):100+32], feeBytes)
-
-	// sqrtPriceLimitX96 at offset 132 - set to 0 for no limit
-
-	result, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
-		To:   &c.quoter,
-		Data: data,
+	data := v3Quoter.PackQuoteExactInputSingle(bindings.IQuoterV2QuoteExactInputSingleParams{
+		TokenIn:           tokenIn,
+		TokenOut:          tokenOut,
+		AmountIn:          amountIn,
+		Fee:               big.NewInt(int64(fee)),
+		SqrtPriceLimitX96: new(big.Int), // No limit
 	})
+	quote, err := callView(ctx, c.ethClient, c.quoter, data, v3Quoter.UnpackQuoteExactInputSingle)
 	if err != nil {
 		return nil, fmt.Errorf("quoter call failed: %w", err)
 	}
-
-	// Response: (amountOut uint256, sqrtPriceX96After uint160, initializedTicksCrossed uint32, gasEstimate uint256)
-	if len(result) < 32 {
-		return nil, fmt.Errorf("invalid quoter response length: %d", len(result))
-	}
-
-	amountOut := new(big.Int).SetBytes(result[0:32])
-	return amountOut, nil
+	return quote.AmountOut, nil
 }
 
 // DEXType returns the DEX type identifier
 func (c *UniswapV3Client) DEXType() entities.DEXType {
 	return entities.DEXUniswapV3
 }
+
+// Capabilities returns what the V3 router supports. Pools carry their
+// tick state, so pair math quotes them without the quoter.
+func (c *UniswapV3Client) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsExactOut:       true, // exactOutput
+		SupportsMultiHopNative: true, // Encoded paths
+		FeeModel:               FeeTiered,
+	}
+}
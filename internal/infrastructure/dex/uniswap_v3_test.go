@@ -0,0 +1,40 @@
+package dex
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTickCallsAreSigned(t *testing.T) {
+	tests := []struct {
+		v    int64
+		want string
+	}{
+		{5, "0000000000000000000000000000000000000000000000000000000000000005"},
+		{-1, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+		{-887220, "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffff2764c"},
+	}
+	for _, tt := range tests {
+		data := v3PoolContract.PackTicks(big.NewInt(tt.v))
+		if !bytes.Equal(data[:4], common.Hex2Bytes("f30dba93")) {
+			t.Errorf("PackTicks(%d) selector = %x", tt.v, data[:4])
+		}
+		if got := common.Bytes2Hex(data[4:]); got != tt.want {
+			t.Errorf("PackTicks(%d) = %s, want %s", tt.v, got, tt.want)
+		}
+
+		// ticks returns liquidityNet as its second word
+		result := make([]byte, 32*8)
+		copy(result[32:64], data[4:])
+		tick, err := v3PoolContract.UnpackTicks(result)
+		if err != nil {
+			t.Fatalf("UnpackTicks() error = %v", err)
+		}
+		if tick.LiquidityNet.Int64() != tt.v {
+			t.Errorf("liquidityNet = %s, want %d", tick.LiquidityNet, tt.v)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package dex
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// v3MaxConcurrentCalls bounds the node calls one V3 lookup makes at once
+const v3MaxConcurrentCalls = 4
+
+// v3TierTTL is how long a pair's set of deployed tiers is reused. Pools
+// are never removed, but a pool can be deployed for another tier.
+const v3TierTTL = time.Hour
+
+// v3TierPool is the pool deployed for one fee tier of a pair
+type v3TierPool struct {
+	fee     uint32
+	address common.Address
+}
+
+// v3TierIndex remembers which fee tiers have a pool for each pair, so
+// lookups skip the tiers without one instead of calling the factory and
+// quoter for them every time
+type v3TierIndex struct {
+	mu    sync.Mutex
+	pairs map[[2]common.Address]v3Tiers
+	now   func() time.Time
+}
+
+type v3Tiers struct {
+	pools []v3TierPool
+	found time.Time
+}
+
+func newV3TierIndex() *v3TierIndex {
+	return &v3TierIndex{
+		pairs: make(map[[2]common.Address]v3Tiers),
+		now:   time.Now,
+	}
+}
+
+// tiers returns the pools of the sorted pair token0/token1, calling lookup
+// unless they were found within v3TierTTL. A lookup that failed for some
+// tier returns what it found without caching it.
+func (x *v3TierIndex) tiers(token0, token1 common.Address, lookup func() ([]v3TierPool, error)) []v3TierPool {
+	key := [2]common.Address{token0, token1}
+	x.mu.Lock()
+	cached, ok := x.pairs[key]
+	x.mu.Unlock()
+	if ok && x.now().Sub(cached.found) < v3TierTTL {
+		return cached.pools
+	}
+
+	pools, err := lookup()
+	if err != nil {
+		return pools
+	}
+	x.mu.Lock()
+	x.pairs[key] = v3Tiers{pools: pools, found: x.now()}
+	x.mu.Unlock()
+	return pools
+}
+
+// concurrently runs fn for 0..n-1, at most v3MaxConcurrentCalls at a time
+func concurrently(n int, fn func(i int)) {
+	sem := make(chan struct{}, v3MaxConcurrentCalls)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package dex
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestV3TierIndex(t *testing.T) {
+	index := newV3TierIndex()
+	now := time.Unix(1700000000, 0)
+	index.now = func() time.Time { return now }
+	token0, token1 := common.HexToAddress("0x1111"), common.HexToAddress("0x2222")
+	pool := v3TierPool{fee: 500, address: common.HexToAddress("0x3333")}
+
+	lookups := 0
+	lookup := func(pools []v3TierPool, err error) func() ([]v3TierPool, error) {
+		return func() ([]v3TierPool, error) {
+			lookups++
+			return pools, err
+		}
+	}
+
+	tests := []struct {
+		name        string
+		after       time.Duration
+		lookup      func() ([]v3TierPool, error)
+		wantPools   int
+		wantLookups int
+	}{
+		{"failed lookup isn't cached", 0, lookup([]v3TierPool{pool}, errors.New("timeout")), 1, 1},
+		{"first lookup", 0, lookup([]v3TierPool{pool}, nil), 1, 2},
+		{"reused within the TTL", 30 * time.Minute, lookup(nil, nil), 1, 2},
+		{"looked up again after the TTL", time.Hour, lookup(nil, nil), 0, 3},
+		{"no pool is cached too", 0, lookup([]v3TierPool{pool}, nil), 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.after)
+			if got := index.tiers(token0, token1, tt.lookup); len(got) != tt.wantPools {
+				t.Errorf("tiers() = %v, want %d pools", got, tt.wantPools)
+			}
+			if lookups != tt.wantLookups {
+				t.Errorf("lookups = %d, want %d", lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestConcurrentlyIsBounded(t *testing.T) {
+	var running, peak, done atomic.Int32
+	concurrently(12, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		done.Add(1)
+	})
+	if done.Load() != 12 {
+		t.Errorf("%d calls ran, want 12", done.Load())
+	}
+	if p := peak.Load(); p > v3MaxConcurrentCalls || p < 2 {
+		t.Errorf("peak concurrency = %d, want 2..%d", p, v3MaxConcurrentCalls)
+	}
+}
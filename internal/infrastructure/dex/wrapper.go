@@ -0,0 +1,141 @@
+package dex
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex/bindings"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+var erc4626 = bindings.NewERC4626()
+
+// wrapperVirtualDepth is the wrapped-side reserve of a wrap's virtual pool.
+// It is deep enough that the pool's spot price and liquidity score read as
+// slippage-free for any realistic trade size; amounts come from the
+// pair's WrapRate.
+var wrapperVirtualDepth = new(big.Int).Exp(big.NewInt(10), big.NewInt(36), nil)
+
+// WrapperClient quotes the wraps whose contract sets the rate: WETH 1:1
+// with the gas token, and ERC-4626 vaults such as sDAI at the vault's rate
+type WrapperClient struct {
+	ethClient *ethclient.Client
+	wraps     []entities.WrapRoute
+}
+
+func NewWrapperClient(ethClient *ethclient.Client) *WrapperClient {
+	var wraps []entities.WrapRoute
+	for _, wrap := range entities.WrapRoutes {
+		if wrap.DEX == entities.DEXWrapper {
+			wraps = append(wraps, wrap)
+		}
+	}
+	return &WrapperClient{ethClient: ethClient, wraps: wraps}
+}
+
+func (c *WrapperClient) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
+	wrap, ok := c.wrapFor(tokenA, tokenB)
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: not a wrap", ErrPoolNotFound)
+	}
+	return wrap.Wrapped.Address, nil
+}
+
+func (c *WrapperClient) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	wrap, ok := c.wrapFor(tokenA.Address, tokenB.Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: not a wrap", ErrPoolNotFound)
+	}
+	if wrap.Underlying.IsNative() {
+		return newWrapPair(wrap, big.NewInt(1e18), 0), nil
+	}
+
+	blockNumber, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	rate, err := callView(ctx, c.ethClient, wrap.Wrapped.Address, erc4626.PackConvertToAssets(big.NewInt(1e18)), erc4626.UnpackConvertToAssets)
+	if err != nil {
+		return nil, fmt.Errorf("%s convertToAssets call failed: %w", wrap.Wrapped.Symbol, err)
+	}
+	if rate.Sign() == 0 {
+		return nil, fmt.Errorf("%s rate is zero", wrap.Wrapped.Symbol)
+	}
+	return newWrapPair(wrap, rate, blockNumber), nil
+}
+
+func (c *WrapperClient) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	wrap, ok := c.wrapFor(tokenIn.Address, tokenOut.Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: not a wrap", ErrPoolNotFound)
+	}
+	if amountIn == nil || amountIn.Sign() <= 0 {
+		return big.NewInt(0), nil
+	}
+	if wrap.Underlying.IsNative() {
+		return new(big.Int).Set(amountIn), nil
+	}
+
+	data, unpack := erc4626.PackPreviewDeposit(amountIn), erc4626.UnpackPreviewDeposit
+	if tokenIn.Address == wrap.Wrapped.Address {
+		data, unpack = erc4626.PackPreviewRedeem(amountIn), erc4626.UnpackPreviewRedeem
+	}
+	amountOut, err := callView(ctx, c.ethClient, wrap.Wrapped.Address, data, unpack)
+	if err != nil {
+		return nil, fmt.Errorf("%s preview call failed: %w", wrap.Wrapped.Symbol, err)
+	}
+	return amountOut, nil
+}
+
+// DEXType returns the DEX type
+func (c *WrapperClient) DEXType() entities.DEXType {
+	return entities.DEXWrapper
+}
+
+// Capabilities returns what the wrappers support
+func (c *WrapperClient) Capabilities() Capabilities {
+	return Capabilities{SupportsExactOut: true, FeeModel: FeeNone}
+}
+
+func (c *WrapperClient) wrapFor(tokenA, tokenB common.Address) (entities.WrapRoute, bool) {
+	for _, wrap := range c.wraps {
+		if (tokenA == wrap.Underlying.Address && tokenB == wrap.Wrapped.Address) ||
+			(tokenA == wrap.Wrapped.Address && tokenB == wrap.Underlying.Address) {
+			return wrap, true
+		}
+	}
+	return entities.WrapRoute{}, false
+}
+
+// newWrapPair is the virtual pool of a wrap converting at rate, underlying
+// per wrapped token with 18 decimals. Its address is the wrapper's.
+func newWrapPair(wrap entities.WrapRoute, rate *big.Int, blockNumber uint64) *entities.Pair {
+	reserveWrapped := new(big.Int).Set(wrapperVirtualDepth)
+	reserveUnderlying := new(big.Int).Mul(wrapperVirtualDepth, rate)
+	reserveUnderlying.Div(reserveUnderlying, big.NewInt(1e18))
+
+	token0, token1 := wrap.Underlying, wrap.Wrapped
+	reserve0, reserve1 := reserveUnderlying, reserveWrapped
+	if token1.Address.Hex() < token0.Address.Hex() {
+		token0, token1 = token1, token0
+		reserve0, reserve1 = reserve1, reserve0
+	}
+
+	return &entities.Pair{
+		Address:     wrap.Wrapped.Address,
+		Token0:      token0,
+		Token1:      token1,
+		Reserve0:    reserve0,
+		Reserve1:    reserve1,
+		DEX:         wrap.DEX,
+		Fee:         0,
+		UpdatedAt:   time.Now().Unix(),
+		BlockNumber: blockNumber,
+		Wrap:        &entities.WrapRate{Wrapped: wrap.Wrapped.Address, Rate: rate},
+	}
+}
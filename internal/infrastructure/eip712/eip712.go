@@ -0,0 +1,114 @@
+package eip712
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	domainTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	// Without a version, as Permit2 signs
+	unversionedTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,uint256 chainId,address verifyingContract)"))
+)
+
+// Domain separates signatures by protocol, chain and verifying contract
+type Domain struct {
+	Name              string
+	Version           string // Left out of the domain when empty
+	ChainID           *big.Int
+	VerifyingContract common.Address
+}
+
+// Separator returns the EIP-712 domain separator
+func (d Domain) Separator() []byte {
+	if d.Version == "" {
+		return crypto.Keccak256(
+			unversionedTypeHash,
+			crypto.Keccak256([]byte(d.Name)),
+			Uint(d.ChainID),
+			Address(d.VerifyingContract),
+		)
+	}
+	return crypto.Keccak256(
+		domainTypeHash,
+		crypto.Keccak256([]byte(d.Name)),
+		crypto.Keccak256([]byte(d.Version)),
+		Uint(d.ChainID),
+		Address(d.VerifyingContract),
+	)
+}
+
+// Field is a member of a struct type, as typed data lists it
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData is a struct to sign, as eth_signTypedData_v4 takes it.
+// Integers in Message are decimal strings; the chain ID is a number.
+type TypedData struct {
+	Types       map[string][]Field     `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      map[string]interface{} `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// TypedData returns message, a primaryType struct, for signing in this
+// domain. types lists primaryType and the struct types it refers to; the
+// domain's own type is added to them.
+func (d Domain) TypedData(primaryType string, types map[string][]Field, message map[string]interface{}) TypedData {
+	domainFields := []Field{{"name", "string"}}
+	domain := map[string]interface{}{"name": d.Name}
+	if d.Version != "" {
+		domainFields = append(domainFields, Field{"version", "string"})
+		domain["version"] = d.Version
+	}
+	domainFields = append(domainFields, Field{"chainId", "uint256"}, Field{"verifyingContract", "address"})
+	domain["chainId"] = d.ChainID
+	domain["verifyingContract"] = d.VerifyingContract.Hex()
+
+	all := map[string][]Field{"EIP712Domain": domainFields}
+	for name, fields := range types {
+		all[name] = fields
+	}
+	return TypedData{Types: all, PrimaryType: primaryType, Domain: domain, Message: message}
+}
+
+// Digest returns the hash a wallet signs for structHash in this domain
+func (d Domain) Digest(structHash []byte) common.Hash {
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, d.Separator(), structHash)
+}
+
+// Address encodes an address struct member
+func Address(addr common.Address) []byte {
+	return common.LeftPadBytes(addr.Bytes(), 32)
+}
+
+// Uint encodes a uint256 struct member
+func Uint(v *big.Int) []byte {
+	return math.U256Bytes(new(big.Int).Set(v))
+}
+
+// Recover returns the address that produced sig over digest. Both the
+// wallet (v = 27/28) and raw (v = 0/1) recovery id forms are accepted.
+func Recover(digest common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature must be %d bytes", crypto.SignatureLength)
+	}
+
+	normalized := make([]byte, crypto.SignatureLength)
+	copy(normalized, sig)
+	if normalized[64] >= 27 {
+		normalized[64] -= 27
+	}
+
+	pub, err := crypto.SigToPub(digest.Bytes(), normalized)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
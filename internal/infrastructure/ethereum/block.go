@@ -0,0 +1,29 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+)
+
+type blockKey struct{}
+
+// WithBlock pins the calls made with ctx to block: CallContract reads the
+// state at that block and BlockNumber reports it, so reads made for one
+// request agree with each other. Zero unpins them.
+func WithBlock(ctx context.Context, block uint64) context.Context {
+	return context.WithValue(ctx, blockKey{}, block)
+}
+
+// PinnedBlock returns the block ctx pins calls to, 0 for the latest
+func PinnedBlock(ctx context.Context) uint64 {
+	block, _ := ctx.Value(blockKey{}).(uint64)
+	return block
+}
+
+// blockTag is the block number CallContract passes for ctx, nil for latest
+func blockTag(ctx context.Context) *big.Int {
+	if block := PinnedBlock(ctx); block != 0 {
+		return new(big.Int).SetUint64(block)
+	}
+	return nil
+}
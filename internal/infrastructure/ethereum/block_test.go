@@ -0,0 +1,27 @@
+package ethereum
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithBlock(t *testing.T) {
+	ctx := context.Background()
+	if tag := blockTag(ctx); tag != nil {
+		t.Errorf("blockTag() unpinned = %v, want nil for latest", tag)
+	}
+
+	pinned := WithBlock(ctx, 21000000)
+	if tag := blockTag(pinned); tag == nil || tag.Uint64() != 21000000 {
+		t.Errorf("blockTag() pinned = %v, want 21000000", tag)
+	}
+	// A pinned head is answered without asking the node
+	var c Client
+	if block, err := c.BlockNumber(pinned); err != nil || block != 21000000 {
+		t.Errorf("BlockNumber() pinned = %d, %v, want 21000000", block, err)
+	}
+
+	if block := PinnedBlock(WithBlock(pinned, 0)); block != 0 {
+		t.Errorf("PinnedBlock() after unpinning = %d, want 0", block)
+	}
+}
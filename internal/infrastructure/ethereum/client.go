@@ -8,6 +8,8 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -16,6 +18,9 @@ type Client struct {
 	rpcURL  string
 	chainID *big.Int
 	mu      sync.RWMutex
+
+	callsMu sync.Mutex
+	calls   map[string]uint64
 }
 
 func NewClient(rpcURL string) (*Client, error) {
@@ -37,6 +42,7 @@ func NewClient(rpcURL string) (*Client, error) {
 		client:  client,
 		rpcURL:  rpcURL,
 		chainID: chainID,
+		calls:   make(map[string]uint64),
 	}, nil
 }
 
@@ -51,30 +57,154 @@ func (c *Client) ChainID() *big.Int {
 	return c.chainID
 }
 
+// Calls returns how many requests have been sent for each JSON-RPC method
+func (c *Client) Calls() map[string]uint64 {
+	c.callsMu.Lock()
+	defer c.callsMu.Unlock()
+	calls := make(map[string]uint64, len(c.calls))
+	for method, n := range c.calls {
+		calls[method] = n
+	}
+	return calls
+}
+
+func (c *Client) count(method string) {
+	c.callsMu.Lock()
+	c.calls[method]++
+	c.callsMu.Unlock()
+}
+
+// CallContract runs a call against the latest state, or the block ctx is
+// pinned to. A revert comes back as a *RevertError with its reason decoded.
 func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.client.CallContract(ctx, msg, nil)
+	c.count("eth_call")
+	result, err := c.client.CallContract(ctx, msg, blockTag(ctx))
+	return result, revertError(err)
 }
 
+// CallContractAt runs a call against the state at a past block, which the
+// node must still hold
+func (c *Client) CallContractAt(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_call")
+	result, err := c.client.CallContract(ctx, msg, block)
+	return result, revertError(err)
+}
+
+// BlockNumber returns the chain head, or the block ctx is pinned to
 func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	if block := PinnedBlock(ctx); block != 0 {
+		return block, nil
+	}
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	c.count("eth_blockNumber")
 	return c.client.BlockNumber(ctx)
 }
 
 func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.client.EstimateGas(ctx, msg)
+	c.count("eth_estimateGas")
+	gas, err := c.client.EstimateGas(ctx, msg)
+	return gas, revertError(err)
 }
 
 func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	c.count("eth_gasPrice")
 	return c.client.SuggestGasPrice(ctx)
 }
 
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_getTransactionCount")
+	return c.client.PendingNonceAt(ctx, account)
+}
+
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_sendRawTransaction")
+	return c.client.SendTransaction(ctx, tx)
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_getTransactionReceipt")
+	return c.client.TransactionReceipt(ctx, txHash)
+}
+
+// HeaderByNumber returns a block header; nil number means the latest block
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_getBlockByNumber")
+	return c.client.HeaderByNumber(ctx, number)
+}
+
+// SubscribeNewHead streams new chain heads; it needs a websocket or IPC endpoint
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_subscribe")
+	return c.client.SubscribeNewHead(ctx, ch)
+}
+
+// FeeHistory returns base fees and priority fee percentiles for recent blocks
+func (c *Client) FeeHistory(ctx context.Context, blockCount uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_feeHistory")
+	return c.client.FeeHistory(ctx, blockCount, nil, rewardPercentiles)
+}
+
+// SimulateV1 runs blocks of calls in order on top of the latest state with
+// eth_simulateV1, so each call sees what the ones before it did. Reverted
+// calls carry their decoded reason in Error.Message.
+func (c *Client) SimulateV1(ctx context.Context, opts ethclient.SimulateOptions) ([]ethclient.SimulateBlockResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_simulateV1")
+	blocks, err := c.client.SimulateV1(ctx, opts, nil)
+	for _, block := range blocks {
+		for _, call := range block.Calls {
+			if call.Error == nil {
+				continue
+			}
+			if data, err := hexutil.Decode(call.Error.Data); err == nil {
+				if reason := DecodeRevert(data); reason != "" {
+					call.Error.Message = "execution reverted: " + reason
+				}
+			}
+		}
+	}
+	return blocks, err
+}
+
+// FilterLogs returns the logs matching query
+func (c *Client) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_getLogs")
+	return c.client.FilterLogs(ctx, query)
+}
+
+// SubscribeFilterLogs streams the logs matching query; it needs a websocket
+// or IPC endpoint
+func (c *Client) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.count("eth_subscribe")
+	return c.client.SubscribeFilterLogs(ctx, query, ch)
+}
+
 func (c *Client) Multicall(ctx context.Context, calls []ethereum.CallMsg) ([][]byte, error) {
 	results := make([][]byte, len(calls))
 	errs := make([]error, len(calls))
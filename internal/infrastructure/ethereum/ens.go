@@ -0,0 +1,158 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress is the ENS registry, deployed at the same address on
+// mainnet and the public testnets
+var ENSRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ErrNameNotFound is returned for names without a resolver or address record
+var ErrNameNotFound = errors.New("ENS name not found")
+
+const (
+	ensResolverSelector = "0178b8bf" // resolver(bytes32)
+	ensAddrSelector     = "3b3b57de" // addr(bytes32)
+)
+
+// maxENSEntries bounds the resolver cache. Once full, new results are
+// served uncached until expired entries are pruned.
+const maxENSEntries = 10000
+
+type contractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+}
+
+type ensEntry struct {
+	addr      common.Address
+	err       error
+	expiresAt time.Time
+}
+
+// ENSResolver resolves ENS names to addresses through the registry, caching
+// results (including misses) for ttl
+type ENSResolver struct {
+	caller contractCaller
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu         sync.Mutex
+	cache      map[string]ensEntry
+	lastPruned time.Time
+}
+
+func NewENSResolver(client *Client) *ENSResolver {
+	return &ENSResolver{
+		caller:     client,
+		ttl:        10 * time.Minute,
+		now:        time.Now,
+		cache:      make(map[string]ensEntry),
+		lastPruned: time.Now(),
+	}
+}
+
+// IsENSName reports whether s looks like an ENS name rather than an address
+func IsENSName(s string) bool {
+	return strings.Contains(s, ".") && !strings.HasPrefix(s, "0x")
+}
+
+// Resolve returns the address record for name. Names are lowercased but
+// not otherwise normalized.
+func (r *ENSResolver) Resolve(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	r.mu.Lock()
+	entry, ok := r.cache[name]
+	r.mu.Unlock()
+	if ok && r.now().Before(entry.expiresAt) {
+		return entry.addr, entry.err
+	}
+
+	addr, err := r.lookup(ctx, name)
+	if err != nil && !errors.Is(err, ErrNameNotFound) {
+		// Don't cache RPC failures
+		return common.Address{}, err
+	}
+
+	r.mu.Lock()
+	if r.now().Sub(r.lastPruned) > r.ttl {
+		r.prune()
+	}
+	if _, ok := r.cache[name]; ok || len(r.cache) < maxENSEntries {
+		r.cache[name] = ensEntry{addr: addr, err: err, expiresAt: r.now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+
+	return addr, err
+}
+
+// prune drops expired entries. Callers hold r.mu.
+func (r *ENSResolver) prune() {
+	now := r.now()
+	for name, entry := range r.cache {
+		if !now.Before(entry.expiresAt) {
+			delete(r.cache, name)
+		}
+	}
+	r.lastPruned = now
+}
+
+func (r *ENSResolver) lookup(ctx context.Context, name string) (common.Address, error) {
+	node := Namehash(name)
+
+	resolver, err := r.callAddress(ctx, ENSRegistryAddress, ensResolverSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get resolver: %w", err)
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s has no resolver", ErrNameNotFound, name)
+	}
+
+	addr, err := r.callAddress(ctx, resolver, ensAddrSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to resolve address: %w", err)
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s has no address record", ErrNameNotFound, name)
+	}
+
+	return addr, nil
+}
+
+// callAddress calls a (bytes32) -> address view function
+func (r *ENSResolver) callAddress(ctx context.Context, to common.Address, selector string, node common.Hash) (common.Address, error) {
+	data := append(common.Hex2Bytes(selector), node.Bytes()...)
+	result, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data})
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(result) < 32 {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(result[12:32]), nil
+}
+
+// Namehash implements the ENS namehash algorithm (EIP-137)
+func Namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
@@ -0,0 +1,97 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNamehash(t *testing.T) {
+	// Vectors from EIP-137
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+
+	for _, tt := range tests {
+		if got := Namehash(tt.name).Hex(); got != tt.want {
+			t.Errorf("Namehash(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsENSName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"vitalik.eth", true},
+		{"pay.vitalik.eth", true},
+		{"vitalik", false},
+		{"0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", false},
+		{"0x12.eth", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsENSName(tt.input); got != tt.want {
+			t.Errorf("IsENSName(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// emptyRegistry answers every call with no resolver, so each name is a miss
+type emptyRegistry struct{ calls int }
+
+func (r *emptyRegistry) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	r.calls++
+	return common.Hash{}.Bytes(), nil
+}
+
+func TestENSResolverPrunesExpiredMisses(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	registry := &emptyRegistry{}
+	r := &ENSResolver{caller: registry, ttl: time.Minute, now: func() time.Time { return now }, cache: make(map[string]ensEntry), lastPruned: now}
+
+	ctx := context.Background()
+	for _, name := range []string{"a.eth", "b.eth", "c.eth"} {
+		if _, err := r.Resolve(ctx, name); !errors.Is(err, ErrNameNotFound) {
+			t.Fatalf("Resolve(%q) error = %v, want ErrNameNotFound", name, err)
+		}
+	}
+	if _, err := r.Resolve(ctx, "a.eth"); !errors.Is(err, ErrNameNotFound) || registry.calls != 3 {
+		t.Fatalf("cached miss: error = %v after %d calls, want ErrNameNotFound from the cache", err, registry.calls)
+	}
+
+	// Past the TTL, the next insert sweeps the expired misses
+	now = now.Add(2 * time.Minute)
+	if _, err := r.Resolve(ctx, "d.eth"); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("Resolve(d.eth) error = %v", err)
+	}
+	if len(r.cache) != 1 {
+		t.Errorf("cache holds %d entries, want only d.eth", len(r.cache))
+	}
+}
+
+func TestENSResolverBoundsCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := &ENSResolver{caller: &emptyRegistry{}, ttl: time.Minute, now: func() time.Time { return now }, cache: make(map[string]ensEntry), lastPruned: now}
+	for i := range maxENSEntries {
+		r.cache[strconv.Itoa(i)+".eth"] = ensEntry{err: ErrNameNotFound, expiresAt: now.Add(time.Minute)}
+	}
+
+	if _, err := r.Resolve(context.Background(), "new.eth"); !errors.Is(err, ErrNameNotFound) {
+		t.Fatalf("Resolve() error = %v, want ErrNameNotFound", err)
+	}
+	if _, ok := r.cache["new.eth"]; ok || len(r.cache) != maxENSEntries {
+		t.Errorf("cache holds %d entries, want the full cache left as is", len(r.cache))
+	}
+}
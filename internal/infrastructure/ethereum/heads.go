@@ -0,0 +1,192 @@
+package ethereum
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reorg describes blocks that left the canonical chain
+type Reorg struct {
+	FromBlock uint64 // First orphaned block
+	ToBlock   uint64 // Head before the reorg
+}
+
+type headSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// HeadWatcher follows chain heads and records recent block hashes so it can
+// tell when blocks it has seen are replaced
+type HeadWatcher struct {
+	source headSource
+	depth  uint64
+
+	mu     sync.Mutex
+	hashes map[uint64]common.Hash
+	head   uint64
+	oldest uint64
+	reorgs uint64
+	headAt time.Time
+}
+
+// NewHeadWatcher tracks the last depth blocks; deeper reorgs are reported
+// as starting at the oldest tracked block
+func NewHeadWatcher(client *Client, depth uint64) *HeadWatcher {
+	return newHeadWatcher(client, depth)
+}
+
+func newHeadWatcher(source headSource, depth uint64) *HeadWatcher {
+	return &HeadWatcher{
+		source: source,
+		depth:  depth,
+		hashes: make(map[uint64]common.Hash),
+	}
+}
+
+// Reorgs returns how many reorgs have been detected
+func (w *HeadWatcher) Reorgs() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reorgs
+}
+
+// Head returns the latest block seen and its timestamp, zero before the
+// first one
+func (w *HeadWatcher) Head() (uint64, time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.head, w.headAt
+}
+
+// Run follows newHeads until ctx is cancelled, calling onReorg for each
+// reorg. Endpoints that can't subscribe (plain HTTP) are polled instead.
+func (w *HeadWatcher) Run(ctx context.Context, pollInterval time.Duration, onReorg func(Reorg)) {
+	heads := make(chan *types.Header, 16)
+	sub, err := w.source.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		log.Printf("head watcher: subscription unavailable (%v), polling every %s", err, pollInterval)
+		w.poll(ctx, pollInterval, onReorg)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-sub.Err():
+			log.Printf("head watcher: subscription dropped (%v), polling every %s", err, pollInterval)
+			w.poll(ctx, pollInterval, onReorg)
+			return
+		case header := <-heads:
+			w.handle(ctx, header, onReorg)
+		}
+	}
+}
+
+func (w *HeadWatcher) poll(ctx context.Context, interval time.Duration, onReorg func(Reorg)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			header, err := w.source.HeaderByNumber(ctx, nil)
+			if err != nil {
+				log.Printf("head watcher: failed to get head: %v", err)
+				continue
+			}
+			w.handle(ctx, header, onReorg)
+		}
+	}
+}
+
+func (w *HeadWatcher) handle(ctx context.Context, header *types.Header, onReorg func(Reorg)) {
+	reorg, err := w.Observe(ctx, header)
+	if err != nil {
+		log.Printf("head watcher: %v", err)
+		return
+	}
+	if reorg != nil && onReorg != nil {
+		onReorg(*reorg)
+	}
+}
+
+// Observe records a new head and returns the reorg it reveals, if any
+func (w *HeadWatcher) Observe(ctx context.Context, header *types.Header) (*Reorg, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	num := header.Number.Uint64()
+	var reorg *Reorg
+	if w.head != 0 {
+		var from uint64
+		var err error
+		if num > w.head {
+			// Check that the chain we knew still leads to this head
+			from, err = w.findFork(ctx, num-1, header.ParentHash)
+		} else {
+			// Same height or lower: whatever sits above the fork is gone
+			from, err = w.findFork(ctx, num, header.Hash())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if from <= w.head {
+			reorg = &Reorg{FromBlock: from, ToBlock: w.head}
+			w.reorgs++
+		}
+	}
+
+	for n := range w.hashes {
+		if n > num || n+w.depth < num {
+			delete(w.hashes, n)
+		}
+	}
+	w.hashes[num] = header.Hash()
+	w.head = num
+	w.headAt = time.Unix(int64(header.Time), 0)
+	w.oldest = num
+	for n := range w.hashes {
+		if n < w.oldest {
+			w.oldest = n
+		}
+	}
+
+	return reorg, nil
+}
+
+// findFork walks back from block n, whose canonical hash is hash, until a
+// recorded hash matches the canonical chain, and returns the first orphaned
+// block. Recorded hashes are corrected along the way. Callers hold w.mu.
+func (w *HeadWatcher) findFork(ctx context.Context, n uint64, hash common.Hash) (uint64, error) {
+	for {
+		stored, ok := w.hashes[n]
+		if (ok && stored == hash) || n < w.oldest {
+			return n + 1, nil
+		}
+		if ok {
+			w.hashes[n] = hash
+		}
+		if n == 0 || n == w.oldest {
+			return n, nil
+		}
+
+		n--
+		header, err := w.source.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return 0, err
+		}
+		hash = header.Hash()
+	}
+}
@@ -0,0 +1,155 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChain serves the canonical headers of a test chain
+type fakeChain map[uint64]*types.Header
+
+func (c fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if h, ok := c[number.Uint64()]; ok {
+		return h, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c fakeChain) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return nil, errors.New("not supported")
+}
+
+// extend builds blocks from..to on top of chain, tagged so forks get
+// distinct hashes
+func (c fakeChain) extend(from, to uint64, tag byte) {
+	for n := from; n <= to; n++ {
+		var parent common.Hash
+		if p, ok := c[n-1]; ok {
+			parent = p.Hash()
+		}
+		c[n] = &types.Header{Number: new(big.Int).SetUint64(n), ParentHash: parent, Extra: []byte{tag}}
+	}
+	for n := range c {
+		if n > to {
+			delete(c, n)
+		}
+	}
+}
+
+func TestHeadWatcherObserve(t *testing.T) {
+	tests := []struct {
+		name    string
+		fork    func(c fakeChain) // Rewrites the canonical chain
+		observe []uint64          // Heads seen after the fork, in order
+		want    *Reorg
+	}{
+		{
+			name:    "linear extension",
+			fork:    func(c fakeChain) { c.extend(6, 6, 0) },
+			observe: []uint64{6},
+		},
+		{
+			name:    "head replaced at same height",
+			fork:    func(c fakeChain) { c.extend(5, 5, 1) },
+			observe: []uint64{5},
+			want:    &Reorg{FromBlock: 5, ToBlock: 5},
+		},
+		{
+			name:    "new head on a sibling of the old head",
+			fork:    func(c fakeChain) { c.extend(5, 6, 1) },
+			observe: []uint64{6},
+			want:    &Reorg{FromBlock: 5, ToBlock: 5},
+		},
+		{
+			name:    "three block reorg",
+			fork:    func(c fakeChain) { c.extend(3, 6, 1) },
+			observe: []uint64{6},
+			want:    &Reorg{FromBlock: 3, ToBlock: 5},
+		},
+		{
+			name:    "chain shrinks to a seen block",
+			fork:    func(c fakeChain) { c.extend(4, 4, 0) },
+			observe: []uint64{4},
+			want:    &Reorg{FromBlock: 5, ToBlock: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := fakeChain{}
+			chain.extend(1, 5, 0)
+			w := newHeadWatcher(chain, 64)
+			for n := uint64(1); n <= 5; n++ {
+				if reorg, err := w.Observe(context.Background(), chain[n]); err != nil || reorg != nil {
+					t.Fatalf("Observe(%d) = %v, %v on a linear chain", n, reorg, err)
+				}
+			}
+
+			tt.fork(chain)
+			var got *Reorg
+			for _, n := range tt.observe {
+				reorg, err := w.Observe(context.Background(), chain[n])
+				if err != nil {
+					t.Fatalf("Observe(%d) error = %v", n, err)
+				}
+				if reorg != nil {
+					got = reorg
+				}
+			}
+
+			switch {
+			case tt.want == nil && got != nil:
+				t.Errorf("unexpected reorg %+v", *got)
+			case tt.want != nil && (got == nil || *got != *tt.want):
+				t.Errorf("reorg = %v, want %+v", got, *tt.want)
+			}
+			if tt.want != nil && w.Reorgs() != 1 {
+				t.Errorf("Reorgs() = %d, want 1", w.Reorgs())
+			}
+		})
+	}
+}
+
+func TestHeadWatcherPollingGap(t *testing.T) {
+	chain := fakeChain{}
+	chain.extend(1, 3, 0)
+	w := newHeadWatcher(chain, 64)
+	for n := uint64(1); n <= 3; n++ {
+		if _, err := w.Observe(context.Background(), chain[n]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Block 3 is replaced and the next poll already sees block 6
+	chain.extend(3, 6, 1)
+	reorg, err := w.Observe(context.Background(), chain[6])
+	if err != nil {
+		t.Fatalf("Observe() error = %v", err)
+	}
+	if reorg == nil || *reorg != (Reorg{FromBlock: 3, ToBlock: 3}) {
+		t.Errorf("reorg = %v, want {3 3}", reorg)
+	}
+}
+
+func TestHeadWatcherHead(t *testing.T) {
+	chain := fakeChain{}
+	chain.extend(1, 2, 0)
+	w := newHeadWatcher(chain, 64)
+	if n, _ := w.Head(); n != 0 {
+		t.Fatalf("Head() = %d before any block", n)
+	}
+
+	chain[2].Time = 1700000000
+	if _, err := w.Observe(context.Background(), chain[2]); err != nil {
+		t.Fatal(err)
+	}
+	if n, at := w.Head(); n != 2 || at.Unix() != 1700000000 {
+		t.Errorf("Head() = %d at %d, want 2 at 1700000000", n, at.Unix())
+	}
+}
@@ -0,0 +1,168 @@
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// Error(string)
+	errorSelector = common.Hex2Bytes("08c379a0")
+	// Panic(uint256)
+	panicSelector = common.Hex2Bytes("4e487b71")
+)
+
+// revertCodes spell out the terse Error(string) reasons venues revert with
+var revertCodes = map[string]string{
+	// Uniswap V3 pools, libraries and periphery
+	"SPL": "price limit already reached",
+	"AS":  "zero amount specified",
+	"LOK": "pool locked",
+	"IIA": "insufficient input amount",
+	"STF": "token transferFrom failed",
+	"TF":  "token transfer failed",
+	"T":   "tick out of range",
+	"R":   "price out of range",
+	// Uniswap V2 pairs
+	"UniswapV2: K":      "constant product check failed",
+	"UniswapV2: LOCKED": "pair locked",
+	// Balancer vault and pools
+	"BAL#000": "addition overflow",
+	"BAL#001": "subtraction overflow",
+	"BAL#004": "division by zero",
+	"BAL#304": "trade exceeds the pool's max in ratio",
+	"BAL#305": "trade exceeds the pool's max out ratio",
+	"BAL#507": "swap limit exceeded",
+	"BAL#508": "swap deadline passed",
+}
+
+// customErrors are the Solidity custom errors of contracts swaps go through
+var customErrors = func() map[[4]byte]string {
+	signatures := []string{
+		// Universal Router
+		"V2TooLittleReceived()",
+		"V2TooMuchRequested()",
+		"V2InvalidPath()",
+		"V3TooLittleReceived()",
+		"V3TooMuchRequested()",
+		"V3InvalidSwap()",
+		"TransactionDeadlinePassed()",
+		"InsufficientToken()",
+		"InsufficientETH()",
+		// Permit2
+		"AllowanceExpired(uint256)",
+		"InsufficientAllowance(uint256)",
+		"SignatureExpired(uint256)",
+		"InvalidNonce()",
+		"InvalidSignature()",
+		// ERC-6093 tokens
+		"ERC20InsufficientBalance(address,uint256,uint256)",
+		"ERC20InsufficientAllowance(address,uint256,uint256)",
+	}
+	errs := make(map[[4]byte]string, len(signatures))
+	for _, signature := range signatures {
+		errs[[4]byte(crypto.Keccak256([]byte(signature))[:4])] = signature
+	}
+	return errs
+}()
+
+// RevertError is a call that reverted, with the reason decoded from its
+// revert data
+type RevertError struct {
+	Reason string // Empty when the contract reverted without one
+	Data   []byte
+	err    error
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return e.err.Error()
+	}
+	return "execution reverted: " + e.Reason
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+// revertError decodes the revert data a node attaches to a failed call;
+// other errors are returned as they are
+func revertError(err error) error {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	encoded, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(encoded)
+	if decodeErr != nil {
+		return err
+	}
+	return &RevertError{Reason: DecodeRevert(data), Data: data, err: err}
+}
+
+// DecodeRevert reads revert data as Error(string), Panic(uint256) or a
+// known custom error. Unknown custom errors are named by selector, and
+// empty data has no reason.
+func DecodeRevert(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	switch selector := data[:4]; {
+	case bytes.Equal(selector, errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "malformed Error(string)"
+		}
+		if meaning, ok := revertCodes[reason]; ok {
+			return fmt.Sprintf("%s (%s)", reason, meaning)
+		}
+		return reason
+	case bytes.Equal(selector, panicSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "malformed Panic(uint256)"
+		}
+		return "panic: " + reason
+	}
+
+	signature, ok := customErrors[[4]byte(data[:4])]
+	if !ok {
+		return fmt.Sprintf("custom error 0x%x", data[:4])
+	}
+	return formatCustomError(signature, data[4:])
+}
+
+// formatCustomError fills a custom error's static arguments into its
+// signature, e.g. InsufficientAllowance(5000)
+func formatCustomError(signature string, args []byte) string {
+	name, params, _ := strings.Cut(strings.TrimSuffix(signature, ")"), "(")
+	if params == "" {
+		return name
+	}
+	types := strings.Split(params, ",")
+	if len(args) < 32*len(types) {
+		return signature
+	}
+	values := make([]string, len(types))
+	for i, typ := range types {
+		word := args[32*i : 32*(i+1)]
+		if typ == "address" {
+			values[i] = common.BytesToAddress(word).Hex()
+		} else {
+			values[i] = new(big.Int).SetBytes(word).String()
+		}
+	}
+	return name + "(" + strings.Join(values, ", ") + ")"
+}
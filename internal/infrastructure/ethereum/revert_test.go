@@ -0,0 +1,88 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func word(v *big.Int) []byte {
+	return common.LeftPadBytes(v.Bytes(), 32)
+}
+
+// errorString encodes Error(reason)
+func errorString(reason string) []byte {
+	data := append(common.CopyBytes(errorSelector), word(big.NewInt(32))...)
+	data = append(data, word(big.NewInt(int64(len(reason))))...)
+	return append(data, common.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+}
+
+func customError(signature string, args ...[]byte) []byte {
+	data := crypto.Keccak256([]byte(signature))[:4]
+	for _, arg := range args {
+		data = append(data, arg...)
+	}
+	return data
+}
+
+func TestDecodeRevert(t *testing.T) {
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{"V3 code", errorString("SPL"), "SPL (price limit already reached)"},
+		{"Balancer code", errorString("BAL#507"), "BAL#507 (swap limit exceeded)"},
+		{"plain reason", errorString("Too little received"), "Too little received"},
+		{"overflow", append(common.CopyBytes(panicSelector), word(big.NewInt(0x11))...), "panic: arithmetic underflow or overflow"},
+		{"custom error", customError("V3TooLittleReceived()"), "V3TooLittleReceived"},
+		{"custom error with args", customError("ERC20InsufficientBalance(address,uint256,uint256)", common.LeftPadBytes(owner.Bytes(), 32), word(big.NewInt(5)), word(big.NewInt(9))),
+			"ERC20InsufficientBalance(0x1111111111111111111111111111111111111111, 5, 9)"},
+		{"truncated args", customError("InsufficientAllowance(uint256)"), "InsufficientAllowance(uint256)"},
+		{"unknown selector", common.Hex2Bytes("deadbeef"), "custom error 0xdeadbeef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DecodeRevert(tt.data); got != tt.want {
+				t.Errorf("DecodeRevert() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// dataError is a JSON-RPC error with revert data, as the node returns it
+type dataError struct {
+	data interface{}
+}
+
+func (e dataError) Error() string          { return "execution reverted" }
+func (e dataError) ErrorData() interface{} { return e.data }
+
+func TestRevertError(t *testing.T) {
+	err := revertError(dataError{hexutil.Encode(errorString("STF"))})
+	var reverted *RevertError
+	if !errors.As(err, &reverted) || err.Error() != "execution reverted: STF (token transferFrom failed)" {
+		t.Fatalf("revertError() = %v", err)
+	}
+	if !errors.As(err, &dataError{}) {
+		t.Error("RevertError doesn't unwrap to the node's error")
+	}
+
+	// No reason keeps the node's message; other errors pass through
+	if err := revertError(dataError{"0x"}); err.Error() != "execution reverted" {
+		t.Errorf("revertError(0x) = %v", err)
+	}
+	plain := errors.New("connection refused")
+	if err := revertError(plain); err != plain {
+		t.Errorf("revertError(plain) = %v", err)
+	}
+	if revertError(nil) != nil {
+		t.Error("revertError(nil) != nil")
+	}
+}
@@ -0,0 +1,294 @@
+// Code generated via abigen V2 - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/v2"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+// ExecutorHop is an auto generated low-level Go binding around an user-defined struct.
+type ExecutorHop struct {
+	Pool     common.Address
+	Venue    uint8
+	TokenIn  common.Address
+	TokenOut common.Address
+	Fee      *big.Int
+}
+
+// ExecutorOrder is an auto generated low-level Go binding around an user-defined struct.
+type ExecutorOrder struct {
+	TokenIn      common.Address
+	TokenOut     common.Address
+	AmountIn     *big.Int
+	MinAmountOut *big.Int
+	Recipient    common.Address
+	Deadline     *big.Int
+}
+
+// ExecutorSplit is an auto generated low-level Go binding around an user-defined struct.
+type ExecutorSplit struct {
+	AmountIn *big.Int
+	Hops     []ExecutorHop
+}
+
+// ExecutorMetaData contains all meta data concerning the Executor contract.
+var ExecutorMetaData = bind.MetaData{
+	ABI: "[{\"type\":\"constructor\",\"inputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"execute\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"order\",\"type\":\"tuple\",\"internalType\":\"structExecutor.Order\",\"components\":[{\"name\":\"tokenIn\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"tokenOut\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"amountIn\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"minAmountOut\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"recipient\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"deadline\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"name\":\"splits\",\"type\":\"tuple[]\",\"internalType\":\"structExecutor.Split[]\",\"components\":[{\"name\":\"amountIn\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"hops\",\"type\":\"tuple[]\",\"internalType\":\"structExecutor.Hop[]\",\"components\":[{\"name\":\"pool\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"venue\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"tokenIn\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"tokenOut\",\"type\":\"address\",\"internalType\":\"address\"},{\"name\":\"fee\",\"type\":\"uint24\",\"internalType\":\"uint24\"}]}]}],\"outputs\":[{\"name\":\"amountOut\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"uniswapV3SwapCallback\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"amount0Delta\",\"type\":\"int256\",\"internalType\":\"int256\"},{\"name\":\"amount1Delta\",\"type\":\"int256\",\"internalType\":\"int256\"},{\"name\":\"data\",\"type\":\"bytes\",\"internalType\":\"bytes\"}],\"outputs\":[]},{\"type\":\"event\",\"name\":\"Executed\",\"anonymous\":false,\"inputs\":[{\"name\":\"sender\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"recipient\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"tokenIn\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"tokenOut\",\"type\":\"address\",\"indexed\":false,\"internalType\":\"address\"},{\"name\":\"amountIn\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"amountOut\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"Expired\",\"inputs\":[]},{\"type\":\"error\",\"name\":\"InsufficientOutput\",\"inputs\":[{\"name\":\"amountOut\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"minAmountOut\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"SplitMismatch\",\"inputs\":[{\"name\":\"splitTotal\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"amountIn\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"type\":\"error\",\"name\":\"UnknownVenue\",\"inputs\":[{\"name\":\"venue\",\"type\":\"uint8\",\"internalType\":\"uint8\"}]}]",
+	ID:  "Executor",
+}
+
+// Executor is an auto generated Go binding around an Ethereum contract.
+type Executor struct {
+	abi abi.ABI
+}
+
+// NewExecutor creates a new instance of Executor.
+func NewExecutor() *Executor {
+	parsed, err := ExecutorMetaData.ParseABI()
+	if err != nil {
+		panic(errors.New("invalid ABI: " + err.Error()))
+	}
+	return &Executor{abi: *parsed}
+}
+
+// Instance creates a wrapper for a deployed contract instance at the given address.
+// Use this to create the instance object passed to abigen v2 library functions Call, Transact, etc.
+func (c *Executor) Instance(backend bind.ContractBackend, addr common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(addr, c.abi, backend, backend, backend)
+}
+
+// PackExecute is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x8c514566.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function execute((address,address,uint256,uint256,address,uint256) order, (uint256,(address,uint8,address,address,uint24)[])[] splits) returns(uint256 amountOut)
+func (executor *Executor) PackExecute(order ExecutorOrder, splits []ExecutorSplit) []byte {
+	enc, err := executor.abi.Pack("execute", order, splits)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackExecute is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0x8c514566.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function execute((address,address,uint256,uint256,address,uint256) order, (uint256,(address,uint8,address,address,uint24)[])[] splits) returns(uint256 amountOut)
+func (executor *Executor) TryPackExecute(order ExecutorOrder, splits []ExecutorSplit) ([]byte, error) {
+	return executor.abi.Pack("execute", order, splits)
+}
+
+// UnpackExecute is the Go binding that unpacks the parameters returned
+// from invoking the contract method with ID 0x8c514566.
+//
+// Solidity: function execute((address,address,uint256,uint256,address,uint256) order, (uint256,(address,uint8,address,address,uint24)[])[] splits) returns(uint256 amountOut)
+func (executor *Executor) UnpackExecute(data []byte) (*big.Int, error) {
+	out, err := executor.abi.Unpack("execute", data)
+	if err != nil {
+		return new(big.Int), err
+	}
+	out0 := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+	return out0, nil
+}
+
+// PackUniswapV3SwapCallback is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfa461e33.  This method will panic if any
+// invalid/nil inputs are passed.
+//
+// Solidity: function uniswapV3SwapCallback(int256 amount0Delta, int256 amount1Delta, bytes data) returns()
+func (executor *Executor) PackUniswapV3SwapCallback(amount0Delta *big.Int, amount1Delta *big.Int, data []byte) []byte {
+	enc, err := executor.abi.Pack("uniswapV3SwapCallback", amount0Delta, amount1Delta, data)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+// TryPackUniswapV3SwapCallback is the Go binding used to pack the parameters required for calling
+// the contract method with ID 0xfa461e33.  This method will return an error
+// if any inputs are invalid/nil.
+//
+// Solidity: function uniswapV3SwapCallback(int256 amount0Delta, int256 amount1Delta, bytes data) returns()
+func (executor *Executor) TryPackUniswapV3SwapCallback(amount0Delta *big.Int, amount1Delta *big.Int, data []byte) ([]byte, error) {
+	return executor.abi.Pack("uniswapV3SwapCallback", amount0Delta, amount1Delta, data)
+}
+
+// ExecutorExecuted represents a Executed event raised by the Executor contract.
+type ExecutorExecuted struct {
+	Sender    common.Address
+	Recipient common.Address
+	TokenIn   common.Address
+	TokenOut  common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	Raw       *types.Log // Blockchain specific contextual infos
+}
+
+const ExecutorExecutedEventName = "Executed"
+
+// ContractEventName returns the user-defined event name.
+func (ExecutorExecuted) ContractEventName() string {
+	return ExecutorExecutedEventName
+}
+
+// UnpackExecutedEvent is the Go binding that unpacks the event data emitted
+// by contract.
+//
+// Solidity: event Executed(address indexed sender, address indexed recipient, address indexed tokenIn, address tokenOut, uint256 amountIn, uint256 amountOut)
+func (executor *Executor) UnpackExecutedEvent(log *types.Log) (*ExecutorExecuted, error) {
+	event := "Executed"
+	if len(log.Topics) == 0 || log.Topics[0] != executor.abi.Events[event].ID {
+		return nil, errors.New("event signature mismatch")
+	}
+	out := new(ExecutorExecuted)
+	if len(log.Data) > 0 {
+		if err := executor.abi.UnpackIntoInterface(out, event, log.Data); err != nil {
+			return nil, err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range executor.abi.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopics(out, indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+	out.Raw = log
+	return out, nil
+}
+
+// UnpackError attempts to decode the provided error data using user-defined
+// error definitions.
+func (executor *Executor) UnpackError(raw []byte) (any, error) {
+	if bytes.Equal(raw[:4], executor.abi.Errors["Expired"].ID.Bytes()[:4]) {
+		return executor.UnpackExpiredError(raw[4:])
+	}
+	if bytes.Equal(raw[:4], executor.abi.Errors["InsufficientOutput"].ID.Bytes()[:4]) {
+		return executor.UnpackInsufficientOutputError(raw[4:])
+	}
+	if bytes.Equal(raw[:4], executor.abi.Errors["SplitMismatch"].ID.Bytes()[:4]) {
+		return executor.UnpackSplitMismatchError(raw[4:])
+	}
+	if bytes.Equal(raw[:4], executor.abi.Errors["UnknownVenue"].ID.Bytes()[:4]) {
+		return executor.UnpackUnknownVenueError(raw[4:])
+	}
+	return nil, errors.New("Unknown error")
+}
+
+// ExecutorExpired represents a Expired error raised by the Executor contract.
+type ExecutorExpired struct {
+}
+
+// ErrorID returns the hash of canonical representation of the error's signature.
+//
+// Solidity: error Expired()
+func ExecutorExpiredErrorID() common.Hash {
+	return common.HexToHash("0x203d82d8d99f63bfecc8335216735e0271df4249ea752b030f9ab305b94e5afe")
+}
+
+// UnpackExpiredError is the Go binding used to decode the provided
+// error data into the corresponding Go error struct.
+//
+// Solidity: error Expired()
+func (executor *Executor) UnpackExpiredError(raw []byte) (*ExecutorExpired, error) {
+	out := new(ExecutorExpired)
+	if err := executor.abi.UnpackIntoInterface(out, "Expired", raw); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExecutorInsufficientOutput represents a InsufficientOutput error raised by the Executor contract.
+type ExecutorInsufficientOutput struct {
+	AmountOut    *big.Int
+	MinAmountOut *big.Int
+}
+
+// ErrorID returns the hash of canonical representation of the error's signature.
+//
+// Solidity: error InsufficientOutput(uint256 amountOut, uint256 minAmountOut)
+func ExecutorInsufficientOutputErrorID() common.Hash {
+	return common.HexToHash("0x2c19b8b87dc30ba95595ffd66c13e323be53c04224764b08e36f6411939cc30e")
+}
+
+// UnpackInsufficientOutputError is the Go binding used to decode the provided
+// error data into the corresponding Go error struct.
+//
+// Solidity: error InsufficientOutput(uint256 amountOut, uint256 minAmountOut)
+func (executor *Executor) UnpackInsufficientOutputError(raw []byte) (*ExecutorInsufficientOutput, error) {
+	out := new(ExecutorInsufficientOutput)
+	if err := executor.abi.UnpackIntoInterface(out, "InsufficientOutput", raw); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExecutorSplitMismatch represents a SplitMismatch error raised by the Executor contract.
+type ExecutorSplitMismatch struct {
+	SplitTotal *big.Int
+	AmountIn   *big.Int
+}
+
+// ErrorID returns the hash of canonical representation of the error's signature.
+//
+// Solidity: error SplitMismatch(uint256 splitTotal, uint256 amountIn)
+func ExecutorSplitMismatchErrorID() common.Hash {
+	return common.HexToHash("0xd2c7b38824ba278033416c860ef558f1873f4fea2ac9f193fc3401a31ecec524")
+}
+
+// UnpackSplitMismatchError is the Go binding used to decode the provided
+// error data into the corresponding Go error struct.
+//
+// Solidity: error SplitMismatch(uint256 splitTotal, uint256 amountIn)
+func (executor *Executor) UnpackSplitMismatchError(raw []byte) (*ExecutorSplitMismatch, error) {
+	out := new(ExecutorSplitMismatch)
+	if err := executor.abi.UnpackIntoInterface(out, "SplitMismatch", raw); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExecutorUnknownVenue represents a UnknownVenue error raised by the Executor contract.
+type ExecutorUnknownVenue struct {
+	Venue uint8
+}
+
+// ErrorID returns the hash of canonical representation of the error's signature.
+//
+// Solidity: error UnknownVenue(uint8 venue)
+func ExecutorUnknownVenueErrorID() common.Hash {
+	return common.HexToHash("0x8e612d4b4eb5e883ed2612b5076e60ba19390cf1726b86efe97ee44fb0b2769b")
+}
+
+// UnpackUnknownVenueError is the Go binding used to decode the provided
+// error data into the corresponding Go error struct.
+//
+// Solidity: error UnknownVenue(uint8 venue)
+func (executor *Executor) UnpackUnknownVenueError(raw []byte) (*ExecutorUnknownVenue, error) {
+	out := new(ExecutorUnknownVenue)
+	if err := executor.abi.UnpackIntoInterface(out, "UnknownVenue", raw); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
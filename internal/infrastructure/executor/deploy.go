@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeployBackend is the node access a deployment dry run needs
+type DeployBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// Deployment describes the creation transaction a deployer would send
+type Deployment struct {
+	Deployer    common.Address
+	Nonce       uint64
+	Address     common.Address // Where the executor will live
+	Data        []byte         // Creation bytecode and constructor arguments
+	Gas         uint64
+	RuntimeSize int // Bytes of code the constructor leaves behind
+}
+
+// DryRunDeploy simulates deploying the executor from deployer with the
+// compiled creation bytecode. Nothing is sent: the constructor is run with
+// eth_call and eth_estimateGas, and the address is derived from the
+// deployer's next nonce.
+func DryRunDeploy(ctx context.Context, backend DeployBackend, deployer common.Address, bytecode []byte) (*Deployment, error) {
+	if len(bytecode) == 0 {
+		return nil, fmt.Errorf("no creation bytecode")
+	}
+	parsed, err := ExecutorMetaData.ParseABI()
+	if err != nil {
+		return nil, err
+	}
+	args, err := parsed.Pack("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode constructor: %w", err)
+	}
+	data := append(append([]byte{}, bytecode...), args...)
+
+	nonce, err := backend.PendingNonceAt(ctx, deployer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployer nonce: %w", err)
+	}
+
+	msg := ethereum.CallMsg{From: deployer, Data: data}
+	runtime, err := backend.CallContract(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("constructor reverted: %w", err)
+	}
+	if len(runtime) == 0 {
+		return nil, fmt.Errorf("constructor left no code")
+	}
+	gas, err := backend.EstimateGas(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate deployment gas: %w", err)
+	}
+
+	return &Deployment{
+		Deployer:    deployer,
+		Nonce:       nonce,
+		Address:     crypto.CreateAddress(deployer, nonce),
+		Data:        data,
+		Gas:         gas,
+		RuntimeSize: len(runtime),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+type mockDeployBackend struct {
+	nonce   uint64
+	runtime []byte
+	err     error
+	msg     ethereum.CallMsg
+}
+
+func (m *mockDeployBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return m.nonce, nil
+}
+
+func (m *mockDeployBackend) CallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	m.msg = msg
+	return m.runtime, m.err
+}
+
+func (m *mockDeployBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 1200000, nil
+}
+
+func TestDryRunDeploy(t *testing.T) {
+	deployer := common.HexToAddress("0x00000000000000000000000000000000000000d0")
+	bytecode := common.FromHex("0x6080604052")
+
+	backend := &mockDeployBackend{nonce: 7, runtime: common.FromHex("0x60806040")}
+	deployment, err := DryRunDeploy(context.Background(), backend, deployer, bytecode)
+	if err != nil {
+		t.Fatalf("DryRunDeploy() error = %v", err)
+	}
+	if deployment.Address != crypto.CreateAddress(deployer, 7) {
+		t.Errorf("address = %s, want the CREATE address for nonce 7", deployment.Address.Hex())
+	}
+	if backend.msg.To != nil || backend.msg.From != deployer || string(backend.msg.Data) != string(bytecode) {
+		t.Errorf("simulated %+v, want a creation from the deployer", backend.msg)
+	}
+	if deployment.Gas != 1200000 || deployment.RuntimeSize != 4 {
+		t.Errorf("gas %d, runtime %d bytes", deployment.Gas, deployment.RuntimeSize)
+	}
+
+	backend.err = errors.New("execution reverted")
+	if _, err := DryRunDeploy(context.Background(), backend, deployer, bytecode); err == nil {
+		t.Error("reverting constructor was accepted")
+	}
+	backend.err, backend.runtime = nil, nil
+	if _, err := DryRunDeploy(context.Background(), backend, deployer, bytecode); err == nil {
+		t.Error("constructor leaving no code was accepted")
+	}
+}
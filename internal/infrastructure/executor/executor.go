@@ -0,0 +1,159 @@
+// Package executor encodes swaps through the aggregator's executor
+// contract, which runs every split and hop of a quote in one transaction
+// so the sender approves and pays the base cost once. Bindings are
+// generated from Executor.abi.
+package executor
+
+//go:generate abigen --v2 --abi Executor.abi --pkg executor --type Executor --out bindings.go
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+)
+
+// Venue codes the executor dispatches hops on. V2-style hops send the input
+// to the pair and call swap with the amount the reserves give; V3 hops call
+// the pool and pay in uniswapV3SwapCallback.
+const (
+	VenueV2 uint8 = 0
+	VenueV3 uint8 = 1
+)
+
+// DEXes are the venues the executor can swap on
+var DEXes = []entities.DEXType{entities.DEXUniswapV2, entities.DEXSushiswap, entities.DEXUniswapV3}
+
+// Encoder builds transactions for the executor deployed at address
+type Encoder struct {
+	address  common.Address
+	contract *Executor
+}
+
+func NewEncoder(address common.Address) *Encoder {
+	return &Encoder{
+		address:  address,
+		contract: NewExecutor(),
+	}
+}
+
+// Address is the executor, and so the spender the sender approves
+func (e *Encoder) Address() common.Address {
+	return e.address
+}
+
+// BuildExecution encodes execute(order, splits) for a quote. Each split
+// route becomes one leg, or BestRoute the only leg when the quote isn't
+// split. The executor pulls quote.AmountIn from the sender, reverts after
+// quote.ExpiresAt and sends at least quote.MinAmountOut to recipient.
+func (e *Encoder) BuildExecution(quote *entities.Quote, recipient common.Address) (*entities.SwapTransaction, error) {
+	routes := make([]*entities.Route, 0, len(quote.SplitRoutes))
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+	}
+	if len(routes) == 0 && quote.BestRoute != nil {
+		routes = append(routes, quote.BestRoute)
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("quote has no route")
+	}
+
+	splits := make([]ExecutorSplit, 0, len(routes))
+	total := new(big.Int)
+	for i, route := range routes {
+		split, err := encodeSplit(route, quote.TokenIn.Address, quote.TokenOut.Address)
+		if err != nil {
+			return nil, fmt.Errorf("split %d: %w", i, err)
+		}
+		splits = append(splits, split)
+		total.Add(total, split.AmountIn)
+	}
+	if total.Cmp(quote.AmountIn) != 0 {
+		return nil, fmt.Errorf("splits total %s, quote is for %s", total, quote.AmountIn)
+	}
+
+	minAmountOut := quote.MinAmountOut
+	if minAmountOut == nil {
+		minAmountOut = big.NewInt(0)
+	}
+	deadline := quote.ExpiresAt
+	if deadline.IsZero() {
+		deadline = time.Now().Add(swap.DefaultDeadline)
+	}
+
+	data, err := e.contract.TryPackExecute(ExecutorOrder{
+		TokenIn:      quote.TokenIn.Address,
+		TokenOut:     quote.TokenOut.Address,
+		AmountIn:     quote.AmountIn,
+		MinAmountOut: minAmountOut,
+		Recipient:    recipient,
+		Deadline:     big.NewInt(deadline.Unix()),
+	}, splits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode execution: %w", err)
+	}
+
+	return &entities.SwapTransaction{
+		From:  recipient,
+		To:    e.address,
+		Data:  data,
+		Value: big.NewInt(0),
+	}, nil
+}
+
+// encodeSplit checks a route runs from tokenIn to tokenOut through pools
+// the executor can call
+func encodeSplit(route *entities.Route, tokenIn, tokenOut common.Address) (ExecutorSplit, error) {
+	if route == nil || len(route.Hops) == 0 {
+		return ExecutorSplit{}, fmt.Errorf("route has no hops")
+	}
+	if route.AmountIn == nil || route.AmountIn.Sign() <= 0 {
+		return ExecutorSplit{}, fmt.Errorf("route amountIn must be positive")
+	}
+
+	hops := make([]ExecutorHop, 0, len(route.Hops))
+	next := tokenIn
+	for i, hop := range route.Hops {
+		if hop.TokenIn != next {
+			return ExecutorSplit{}, fmt.Errorf("hop %d starts from %s, want %s", i, hop.TokenIn.Hex(), next.Hex())
+		}
+		if hop.Pair.Address == (common.Address{}) {
+			return ExecutorSplit{}, fmt.Errorf("hop %d has no pool address", i)
+		}
+		if hop.Pair.Stable != nil {
+			return ExecutorSplit{}, fmt.Errorf("hop %d is a stable pool, which the executor can't price", i)
+		}
+		venue, err := executorVenue(hop.Pair.DEX)
+		if err != nil {
+			return ExecutorSplit{}, fmt.Errorf("hop %d: %w", i, err)
+		}
+		hops = append(hops, ExecutorHop{
+			Pool:     hop.Pair.Address,
+			Venue:    venue,
+			TokenIn:  hop.TokenIn,
+			TokenOut: hop.TokenOut,
+			Fee:      new(big.Int).SetUint64(hop.Pair.Fee),
+		})
+		next = hop.TokenOut
+	}
+	if next != tokenOut {
+		return ExecutorSplit{}, fmt.Errorf("route ends at %s, want %s", next.Hex(), tokenOut.Hex())
+	}
+
+	return ExecutorSplit{AmountIn: route.AmountIn, Hops: hops}, nil
+}
+
+func executorVenue(dexType entities.DEXType) (uint8, error) {
+	switch dexType {
+	case entities.DEXUniswapV2, entities.DEXSushiswap:
+		return VenueV2, nil
+	case entities.DEXUniswapV3:
+		return VenueV3, nil
+	default:
+		return 0, fmt.Errorf("the executor does not support %s", dexType)
+	}
+}
@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var (
+	executorAddress = common.HexToAddress("0x00000000000000000000000000000000000000e0")
+	recipient       = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	v2Pool          = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	v3Pool          = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	sushiPool       = common.HexToAddress("0x0000000000000000000000000000000000000005")
+)
+
+func testHop(pool common.Address, dexType entities.DEXType, fee uint64, tokenIn, tokenOut entities.Token) entities.Hop {
+	return entities.Hop{
+		Pair:     entities.Pair{Address: pool, Token0: tokenIn, Token1: tokenOut, DEX: dexType, Fee: fee},
+		TokenIn:  tokenIn.Address,
+		TokenOut: tokenOut.Address,
+	}
+}
+
+func splitQuote() *entities.Quote {
+	direct := &entities.Route{
+		Hops:     []entities.Hop{testHop(v3Pool, entities.DEXUniswapV3, 500, entities.WETH, entities.USDC)},
+		AmountIn: big.NewInt(600),
+	}
+	// Through DAI on Sushiswap, then Uniswap V2
+	viaDAI := &entities.Route{
+		Hops: []entities.Hop{
+			testHop(sushiPool, entities.DEXSushiswap, 30, entities.WETH, entities.DAI),
+			testHop(v2Pool, entities.DEXUniswapV2, 30, entities.DAI, entities.USDC),
+		},
+		AmountIn: big.NewInt(400),
+	}
+	return &entities.Quote{
+		TokenIn:      entities.WETH,
+		TokenOut:     entities.USDC,
+		AmountIn:     big.NewInt(1000),
+		MinAmountOut: big.NewInt(1990000),
+		ExpiresAt:    time.Unix(1700000000, 0),
+		BestRoute:    direct,
+		SplitRoutes:  []entities.SplitRoute{{Route: direct}, {Route: viaDAI}},
+	}
+}
+
+func TestBuildExecution(t *testing.T) {
+	tx, err := NewEncoder(executorAddress).BuildExecution(splitQuote(), recipient)
+	if err != nil {
+		t.Fatalf("BuildExecution() error = %v", err)
+	}
+	if tx.To != executorAddress || tx.Value.Sign() != 0 {
+		t.Errorf("transaction to %s with value %s", tx.To.Hex(), tx.Value)
+	}
+
+	parsed, err := ExecutorMetaData.ParseABI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	method, err := parsed.MethodById(tx.Data[:4])
+	if err != nil || method.Name != "execute" {
+		t.Fatalf("selector %x is not execute", tx.Data[:4])
+	}
+	args, err := method.Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("calldata does not decode: %v", err)
+	}
+
+	order := args[0].(struct {
+		TokenIn      common.Address `json:"tokenIn"`
+		TokenOut     common.Address `json:"tokenOut"`
+		AmountIn     *big.Int       `json:"amountIn"`
+		MinAmountOut *big.Int       `json:"minAmountOut"`
+		Recipient    common.Address `json:"recipient"`
+		Deadline     *big.Int       `json:"deadline"`
+	})
+	if order.TokenIn != entities.WETH.Address || order.TokenOut != entities.USDC.Address || order.Recipient != recipient {
+		t.Errorf("order = %+v", order)
+	}
+	if order.AmountIn.Int64() != 1000 || order.MinAmountOut.Int64() != 1990000 || order.Deadline.Int64() != 1700000000 {
+		t.Errorf("order amounts = %s/%s, deadline %s", order.AmountIn, order.MinAmountOut, order.Deadline)
+	}
+
+	splits := args[1].([]struct {
+		AmountIn *big.Int `json:"amountIn"`
+		Hops     []struct {
+			Pool     common.Address `json:"pool"`
+			Venue    uint8          `json:"venue"`
+			TokenIn  common.Address `json:"tokenIn"`
+			TokenOut common.Address `json:"tokenOut"`
+			Fee      *big.Int       `json:"fee"`
+		} `json:"hops"`
+	})
+	if len(splits) != 2 || splits[0].AmountIn.Int64() != 600 || splits[1].AmountIn.Int64() != 400 {
+		t.Fatalf("splits = %+v", splits)
+	}
+	if hop := splits[0].Hops[0]; hop.Pool != v3Pool || hop.Venue != VenueV3 || hop.Fee.Int64() != 500 {
+		t.Errorf("V3 hop = %+v", hop)
+	}
+	if len(splits[1].Hops) != 2 {
+		t.Fatalf("got %d hops on the DAI leg", len(splits[1].Hops))
+	}
+	for i, pool := range []common.Address{sushiPool, v2Pool} {
+		if hop := splits[1].Hops[i]; hop.Pool != pool || hop.Venue != VenueV2 || hop.Fee.Int64() != 30 {
+			t.Errorf("DAI leg hop %d = %+v", i, hop)
+		}
+	}
+}
+
+func TestBuildExecutionRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*entities.Quote)
+		want   string
+	}{
+		{
+			name:   "splits short of the order",
+			modify: func(q *entities.Quote) { q.AmountIn = big.NewInt(1001) },
+			want:   "splits total 1000",
+		},
+		{
+			name: "unsupported venue",
+			modify: func(q *entities.Quote) {
+				q.SplitRoutes[0].Route.Hops[0].Pair.DEX = entities.DEXCurve
+			},
+			want: "does not support curve",
+		},
+		{
+			name: "disconnected hops",
+			modify: func(q *entities.Quote) {
+				q.SplitRoutes[1].Route.Hops[1].TokenIn = entities.USDT.Address
+			},
+			want: "hop 1 starts from",
+		},
+		{
+			name: "wrong output token",
+			modify: func(q *entities.Quote) {
+				q.SplitRoutes[0].Route.Hops[0].TokenOut = entities.USDT.Address
+			},
+			want: "route ends at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := splitQuote()
+			tt.modify(quote)
+			_, err := NewEncoder(executorAddress).BuildExecution(quote, recipient)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
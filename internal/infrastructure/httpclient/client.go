@@ -0,0 +1,209 @@
+// Package httpclient is the one outbound HTTP client for external APIs:
+// subgraphs, reference aggregators, bridges and analytics. Every client it
+// returns shares a pooled transport, a circuit breaker per host and the
+// per-host counters published at /debug/vars, so a failing API is cut off
+// quickly instead of holding a connection for each request's full timeout.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// FailureThreshold is how many failures in a row open a host's circuit
+	FailureThreshold = 5
+	// OpenFor is how long an open circuit rejects requests before one
+	// probe is let through
+	OpenFor = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without a request being sent while a host's
+// circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Circuit states, as reported in HostStats
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open" // One probe is in flight
+)
+
+// HostStats counts one host's outbound traffic since start
+type HostStats struct {
+	Requests      uint64  `json:"requests"` // Sent, rejected ones excluded
+	Failures      uint64  `json:"failures"` // Transport errors, 429s and 5xxs
+	Rejected      uint64  `json:"rejected"` // Refused by the open circuit
+	Opens         uint64  `json:"opens"`
+	State         string  `json:"state"`
+	MeanLatencyMs float64 `json:"meanLatencyMs"`
+}
+
+type hostState struct {
+	stats    HostStats
+	latency  time.Duration // Sum over stats.Requests
+	failures int           // In a row
+	openedAt time.Time
+	probing  bool
+}
+
+// Transport wraps a RoundTripper with a circuit breaker and counters per
+// host. It is safe for concurrent use.
+type Transport struct {
+	base http.RoundTripper
+	now  func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// NewTransport wraps base, sending every host's requests until it fails
+// FailureThreshold times in a row
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{
+		base:  base,
+		now:   time.Now,
+		hosts: make(map[string]*hostState),
+	}
+}
+
+// SetClock replaces the time source, for tests
+func (t *Transport) SetClock(now func() time.Time) {
+	t.now = now
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := t.admit(host); err != nil {
+		return nil, err
+	}
+
+	start := t.now()
+	resp, err := t.base.RoundTrip(req)
+	failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	// A caller that gave up says nothing about the host
+	if err != nil && req.Context().Err() != nil {
+		t.release(host, t.now().Sub(start))
+		return resp, err
+	}
+	t.record(host, t.now().Sub(start), failed)
+	return resp, err
+}
+
+// admit lets a request through to host, or refuses it while the host's
+// circuit is open or its probe is in flight
+func (t *Transport) admit(host string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.host(host)
+	if h.stats.State == StateClosed {
+		return nil
+	}
+	if h.probing || t.now().Sub(h.openedAt) < OpenFor {
+		h.stats.Rejected++
+		return fmt.Errorf("%s: %w", host, ErrCircuitOpen)
+	}
+	h.probing = true
+	h.stats.State = StateHalfOpen
+	return nil
+}
+
+// record counts a finished request and moves host's circuit on its outcome
+func (t *Transport) record(host string, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.host(host)
+	h.count(latency)
+	probe := h.probing
+	h.probing = false
+	if !failed {
+		h.failures = 0
+		h.stats.State = StateClosed
+		return
+	}
+	h.stats.Failures++
+	h.failures++
+	if probe || h.failures >= FailureThreshold {
+		h.stats.State = StateOpen
+		h.stats.Opens++
+		h.openedAt = t.now()
+	}
+}
+
+// release counts a request the caller abandoned, leaving the circuit as
+// it was apart from freeing a probe
+func (t *Transport) release(host string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.host(host)
+	h.count(latency)
+	if h.probing {
+		h.probing = false
+		h.stats.State = StateOpen
+	}
+}
+
+func (h *hostState) count(latency time.Duration) {
+	h.stats.Requests++
+	h.latency += latency
+}
+
+// host returns host's state, creating it closed. Callers hold t.mu.
+func (t *Transport) host(host string) *hostState {
+	h, ok := t.hosts[host]
+	if !ok {
+		h = &hostState{stats: HostStats{State: StateClosed}}
+		t.hosts[host] = h
+	}
+	return h
+}
+
+// Stats returns each host's counters, keyed by host
+func (t *Transport) Stats() map[string]HostStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[string]HostStats, len(t.hosts))
+	for host, h := range t.hosts {
+		s := h.stats
+		if s.Requests > 0 {
+			s.MeanLatencyMs = float64(h.latency.Microseconds()) / float64(s.Requests) / 1000
+		}
+		stats[host] = s
+	}
+	return stats
+}
+
+// pooled keeps idle connections to each host for reuse, with dial and TLS
+// timeouts well inside any caller's overall timeout
+func pooled() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   16,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+var shared = NewTransport(pooled())
+
+// New returns a client on the shared transport whose requests, including
+// reading the body, give up after timeout
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: shared}
+}
+
+// Stats returns the shared transport's counters by host
+func Stats() map[string]HostStats {
+	return shared.Stats()
+}
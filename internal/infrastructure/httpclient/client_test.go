@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestTransportCircuitBreaker(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+	host := mustHost(t, server.URL)
+
+	clock := testutil.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	transport := NewTransport(http.DefaultTransport)
+	transport.SetClock(clock.Now)
+	client := &http.Client{Transport: transport}
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < FailureThreshold; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d error = %v", i+1, err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("request past the threshold error = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed probe opens the circuit again at once
+	clock.Advance(OpenFor)
+	if err := get(); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("request after a failed probe error = %v, want ErrCircuitOpen", err)
+	}
+
+	status.Store(http.StatusOK)
+	clock.Advance(OpenFor)
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d after recovery error = %v", i+1, err)
+		}
+	}
+
+	stats := transport.Stats()[host]
+	want := HostStats{Requests: 8, Failures: 6, Rejected: 2, Opens: 2, State: StateClosed}
+	stats.MeanLatencyMs = 0
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestTransportIgnoresCancelledRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	transport := NewTransport(http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+	for i := 0; i < FailureThreshold; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if _, err := client.Do(req); err == nil {
+			t.Fatal("request to a stalled server succeeded")
+		}
+		cancel()
+	}
+
+	stats := transport.Stats()[mustHost(t, server.URL)]
+	if stats.Failures != 0 || stats.State != StateClosed {
+		t.Errorf("stats = %+v, want no failures and a closed circuit", stats)
+	}
+}
+
+func mustHost(t *testing.T, raw string) string {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
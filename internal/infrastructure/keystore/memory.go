@@ -0,0 +1,150 @@
+package keystore
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// MemoryStore keeps API keys in process, for development without Redis.
+// Keys, usage and bookmarks are lost on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	keys      map[string]entities.APIKey
+	total     map[string]*entities.APIKeyUsage
+	daily     map[string]*entities.APIKeyUsage // Keyed by id:day
+	bookmarks map[string]map[string]entities.RouteBookmark
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:      make(map[string]entities.APIKey),
+		total:     make(map[string]*entities.APIKeyUsage),
+		daily:     make(map[string]*entities.APIKeyUsage),
+		bookmarks: make(map[string]map[string]entities.RouteBookmark),
+	}
+}
+
+func (s *MemoryStore) SaveKey(ctx context.Context, key entities.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.ID] = key
+	return nil
+}
+
+func (s *MemoryStore) GetKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (s *MemoryStore) ListKeys(ctx context.Context) ([]entities.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]entities.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+func (s *MemoryStore) DeleteKey(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, id)
+	delete(s.total, id)
+	delete(s.bookmarks, id)
+	return nil
+}
+
+func (s *MemoryStore) IncrRequests(ctx context.Context, id, day string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total, today := s.counters(id, day)
+	total.Requests++
+	today.Requests++
+	return today.Requests, nil
+}
+
+func (s *MemoryStore) IncrQuotes(ctx context.Context, id, day string, volumeUSDCents int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total, today := s.counters(id, day)
+	total.Quotes++
+	total.VolumeUSDCents += volumeUSDCents
+	today.Quotes++
+	today.VolumeUSDCents += volumeUSDCents
+	return nil
+}
+
+func (s *MemoryStore) Usage(ctx context.Context, id, day string) (entities.APIKeyUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total, today := s.counters(id, day)
+	usage := *total
+	usage.RequestsToday = today.Requests
+	usage.QuotesToday = today.Quotes
+	usage.VolumeUSDCentsToday = today.VolumeUSDCents
+	return usage, nil
+}
+
+func (s *MemoryStore) SaveBookmark(ctx context.Context, bookmark entities.RouteBookmark) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bookmarks[bookmark.KeyID] == nil {
+		s.bookmarks[bookmark.KeyID] = make(map[string]entities.RouteBookmark)
+	}
+	s.bookmarks[bookmark.KeyID][bookmark.Name] = bookmark
+	return nil
+}
+
+func (s *MemoryStore) GetBookmark(ctx context.Context, keyID, name string) (*entities.RouteBookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bookmark, ok := s.bookmarks[keyID][name]
+	if !ok {
+		return nil, nil
+	}
+	return &bookmark, nil
+}
+
+func (s *MemoryStore) ListBookmarks(ctx context.Context, keyID string) ([]entities.RouteBookmark, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bookmarks := make([]entities.RouteBookmark, 0, len(s.bookmarks[keyID]))
+	for _, bookmark := range s.bookmarks[keyID] {
+		bookmarks = append(bookmarks, bookmark)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool { return bookmarks[i].Name < bookmarks[j].Name })
+	return bookmarks, nil
+}
+
+func (s *MemoryStore) DeleteBookmark(ctx context.Context, keyID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bookmarks[keyID], name)
+	return nil
+}
+
+// counters returns the all-time and daily counters, creating them as
+// needed. Callers hold s.mu.
+func (s *MemoryStore) counters(id, day string) (*entities.APIKeyUsage, *entities.APIKeyUsage) {
+	total, ok := s.total[id]
+	if !ok {
+		total = &entities.APIKeyUsage{}
+		s.total[id] = total
+	}
+	today, ok := s.daily[id+":"+day]
+	if !ok {
+		today = &entities.APIKeyUsage{}
+		s.daily[id+":"+day] = today
+	}
+	return total, today
+}
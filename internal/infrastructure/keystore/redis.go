@@ -0,0 +1,206 @@
+package keystore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// dailyUsageTTL keeps a day's counters a little past the day itself
+const dailyUsageTTL = 48 * time.Hour
+
+// Usage hash fields
+const (
+	fieldRequests = "requests"
+	fieldQuotes   = "quotes"
+	fieldVolume   = "volume_usd_cents"
+)
+
+// RedisStore keeps API keys as JSON under apikey:<id>, their IDs in the
+// apikeys set, and usage counters in apikey_usage:<id> hashes with a
+// per-day copy under apikey_usage:<id>:<day>. A key's route bookmarks are
+// JSON fields of the apikey_bookmarks:<id> hash, by name.
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func keyKey(id string) string {
+	return "apikey:" + id
+}
+
+func usageKey(id string) string {
+	return "apikey_usage:" + id
+}
+
+func dailyUsageKey(id, day string) string {
+	return "apikey_usage:" + id + ":" + day
+}
+
+func bookmarksKey(id string) string {
+	return "apikey_bookmarks:" + id
+}
+
+func (s *RedisStore) SaveKey(ctx context.Context, key entities.APIKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, keyKey(key.ID), data, 0)
+		pipe.SAdd(ctx, "apikeys", key.ID)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) GetKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	data, err := s.client.Get(ctx, keyKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var key entities.APIKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("corrupt api key %s: %w", id, err)
+	}
+	return &key, nil
+}
+
+func (s *RedisStore) ListKeys(ctx context.Context) ([]entities.APIKey, error) {
+	ids, err := s.client.SMembers(ctx, "apikeys").Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	keys := make([]entities.APIKey, 0, len(ids))
+	for _, id := range ids {
+		key, err := s.GetKey(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+// DeleteKey removes the key; its daily counters expire on their own
+func (s *RedisStore) DeleteKey(ctx context.Context, id string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keyKey(id), usageKey(id), bookmarksKey(id))
+		pipe.SRem(ctx, "apikeys", id)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) IncrRequests(ctx context.Context, id, day string) (int64, error) {
+	var today *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, usageKey(id), fieldRequests, 1)
+		today = pipe.HIncrBy(ctx, dailyUsageKey(id, day), fieldRequests, 1)
+		pipe.Expire(ctx, dailyUsageKey(id, day), dailyUsageTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return today.Val(), nil
+}
+
+func (s *RedisStore) IncrQuotes(ctx context.Context, id, day string, volumeUSDCents int64) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range []string{usageKey(id), dailyUsageKey(id, day)} {
+			pipe.HIncrBy(ctx, key, fieldQuotes, 1)
+			pipe.HIncrBy(ctx, key, fieldVolume, volumeUSDCents)
+		}
+		pipe.Expire(ctx, dailyUsageKey(id, day), dailyUsageTTL)
+		return nil
+	})
+	return err
+}
+
+func (s *RedisStore) Usage(ctx context.Context, id, day string) (entities.APIKeyUsage, error) {
+	var total, today *redis.MapStringStringCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		total = pipe.HGetAll(ctx, usageKey(id))
+		today = pipe.HGetAll(ctx, dailyUsageKey(id, day))
+		return nil
+	})
+	if err != nil {
+		return entities.APIKeyUsage{}, err
+	}
+
+	var usage entities.APIKeyUsage
+	var scanErr error
+	parse := func(fields map[string]string, name string, dst *int64) {
+		if v, ok := fields[name]; ok && scanErr == nil {
+			_, scanErr = fmt.Sscan(v, dst)
+		}
+	}
+	parse(total.Val(), fieldRequests, &usage.Requests)
+	parse(total.Val(), fieldQuotes, &usage.Quotes)
+	parse(total.Val(), fieldVolume, &usage.VolumeUSDCents)
+	parse(today.Val(), fieldRequests, &usage.RequestsToday)
+	parse(today.Val(), fieldQuotes, &usage.QuotesToday)
+	parse(today.Val(), fieldVolume, &usage.VolumeUSDCentsToday)
+	return usage, scanErr
+}
+
+func (s *RedisStore) SaveBookmark(ctx context.Context, bookmark entities.RouteBookmark) error {
+	data, err := json.Marshal(bookmark)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, bookmarksKey(bookmark.KeyID), bookmark.Name, data).Err()
+}
+
+func (s *RedisStore) GetBookmark(ctx context.Context, keyID, name string) (*entities.RouteBookmark, error) {
+	data, err := s.client.HGet(ctx, bookmarksKey(keyID), name).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bookmark entities.RouteBookmark
+	if err := json.Unmarshal(data, &bookmark); err != nil {
+		return nil, fmt.Errorf("corrupt route bookmark %s/%s: %w", keyID, name, err)
+	}
+	return &bookmark, nil
+}
+
+func (s *RedisStore) ListBookmarks(ctx context.Context, keyID string) ([]entities.RouteBookmark, error) {
+	fields, err := s.client.HGetAll(ctx, bookmarksKey(keyID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	bookmarks := make([]entities.RouteBookmark, 0, len(fields))
+	for name, data := range fields {
+		var bookmark entities.RouteBookmark
+		if err := json.Unmarshal([]byte(data), &bookmark); err != nil {
+			return nil, fmt.Errorf("corrupt route bookmark %s/%s: %w", keyID, name, err)
+		}
+		bookmarks = append(bookmarks, bookmark)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool { return bookmarks[i].Name < bookmarks[j].Name })
+	return bookmarks, nil
+}
+
+func (s *RedisStore) DeleteBookmark(ctx context.Context, keyID, name string) error {
+	return s.client.HDel(ctx, bookmarksKey(keyID), name).Err()
+}
@@ -0,0 +1,88 @@
+package liquidity
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// FileStore appends liquidity snapshots to a file as JSON lines, oldest
+// first. The file is only ever appended to, so it can be shipped or rotated
+// by external tools.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileStore opens path for appending, creating it if needed
+func NewFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open liquidity snapshots: %w", err)
+	}
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Append writes snapshots as one write
+func (s *FileStore) Append(ctx context.Context, snapshots []entities.LiquiditySnapshot) error {
+	var buf []byte
+	for _, snapshot := range snapshots {
+		line, err := json.Marshal(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to encode liquidity snapshot: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write liquidity snapshots: %w", err)
+	}
+	return nil
+}
+
+// Scan reads the file from the start, calling fn with each matching
+// snapshot as it is read
+func (s *FileStore) Scan(ctx context.Context, filter entities.LiquiditySnapshotFilter, fn func(entities.LiquiditySnapshot) error) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open liquidity snapshots: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var snapshot entities.LiquiditySnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			// A line cut short by a crash mid-write
+			continue
+		}
+		if !filter.Matches(snapshot) {
+			continue
+		}
+		if err := fn(snapshot); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read liquidity snapshots: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,95 @@
+package liquidity
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "liquidity.jsonl")
+	ctx := context.Background()
+	at := func(minute int) time.Time { return time.Date(2024, 5, 1, 14, minute, 0, 0, time.UTC) }
+	snapshot := func(minute int, dex entities.DEXType, token0, token1 entities.Token, pool string) entities.LiquiditySnapshot {
+		return entities.LiquiditySnapshot{
+			Time: at(minute), Block: uint64(19_000_000 + minute), DEX: dex, Pool: common.HexToAddress(pool),
+			Token0: token0, Token1: token1, Reserve0: big.NewInt(int64(minute)), Reserve1: new(big.Int).Lsh(big.NewInt(1), 100), Fee: 30,
+		}
+	}
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer store.Close()
+	err = store.Append(ctx, []entities.LiquiditySnapshot{
+		snapshot(1, entities.DEXUniswapV2, entities.USDC, entities.WETH, "0x01"),
+		snapshot(1, entities.DEXUniswapV3, entities.USDC, entities.WETH, "0x02"),
+		snapshot(1, entities.DEXUniswapV2, entities.WBTC, entities.WETH, "0x03"),
+	})
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := store.Append(ctx, []entities.LiquiditySnapshot{snapshot(2, entities.DEXUniswapV2, entities.USDC, entities.WETH, "0x01")}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter entities.LiquiditySnapshotFilter
+		want   int
+	}{
+		{"all", entities.LiquiditySnapshotFilter{}, 4},
+		{"pair in either order", entities.LiquiditySnapshotFilter{TokenA: entities.WETH.Address, TokenB: entities.USDC.Address}, 3},
+		{"one token", entities.LiquiditySnapshotFilter{TokenA: entities.WETH.Address}, 4},
+		{"venue", entities.LiquiditySnapshotFilter{DEX: entities.DEXUniswapV3}, 1},
+		{"window", entities.LiquiditySnapshotFilter{From: at(2), To: at(3)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []entities.LiquiditySnapshot
+			err := store.Scan(ctx, tt.filter, func(s entities.LiquiditySnapshot) error {
+				got = append(got, s)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("Scan() returned %d snapshots, want %d", len(got), tt.want)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].Time.Before(got[i-1].Time) {
+					t.Error("snapshots are not oldest first")
+				}
+			}
+		})
+	}
+
+	var last entities.LiquiditySnapshot
+	store.Scan(ctx, entities.LiquiditySnapshotFilter{}, func(s entities.LiquiditySnapshot) error {
+		last = s
+		return nil
+	})
+	if last.Reserve1.Cmp(new(big.Int).Lsh(big.NewInt(1), 100)) != 0 || last.Token0.Symbol != "USDC" || last.Block != 19_000_002 {
+		t.Errorf("snapshot read back as %+v", last)
+	}
+
+	// fn's error ends the scan
+	stop := errors.New("client went away")
+	calls := 0
+	err = store.Scan(ctx, entities.LiquiditySnapshotFilter{}, func(entities.LiquiditySnapshot) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Scan() = %v after %d calls, want fn's error after 1", err, calls)
+	}
+}
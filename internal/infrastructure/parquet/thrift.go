@@ -0,0 +1,90 @@
+package parquet
+
+import "encoding/binary"
+
+// Thrift compact protocol type IDs
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftEncoder writes Parquet's metadata structs in the Thrift compact
+// protocol. The encoder starts inside the top-level struct; each struct,
+// including that one, is closed with endStruct.
+type thriftEncoder struct {
+	buf  []byte
+	last []int16 // Last field ID written in each open struct
+}
+
+func (e *thriftEncoder) fieldHeader(id int16, typ byte) {
+	if len(e.last) == 0 {
+		e.last = append(e.last, 0)
+	}
+	top := &e.last[len(e.last)-1]
+	if delta := id - *top; delta > 0 && delta <= 15 {
+		e.buf = append(e.buf, byte(delta)<<4|typ)
+	} else {
+		e.buf = append(e.buf, typ)
+		e.buf = binary.AppendVarint(e.buf, int64(id))
+	}
+	*top = id
+}
+
+func (e *thriftEncoder) i32(v int32) {
+	e.buf = binary.AppendVarint(e.buf, int64(v))
+}
+
+func (e *thriftEncoder) binary(s string) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *thriftEncoder) i32Field(id int16, v int32) {
+	e.fieldHeader(id, thriftI32)
+	e.i32(v)
+}
+
+func (e *thriftEncoder) i64Field(id int16, v int64) {
+	e.fieldHeader(id, thriftI64)
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *thriftEncoder) binaryField(id int16, s string) {
+	e.fieldHeader(id, thriftBinary)
+	e.binary(s)
+}
+
+// listField starts a list of n elements of elemType; the caller writes them
+func (e *thriftEncoder) listField(id int16, elemType byte, n int) {
+	e.fieldHeader(id, thriftList)
+	if n < 15 {
+		e.buf = append(e.buf, byte(n)<<4|elemType)
+		return
+	}
+	e.buf = append(e.buf, 0xf0|elemType)
+	e.buf = binary.AppendUvarint(e.buf, uint64(n))
+}
+
+// structField starts a struct-valued field; close it with endStruct
+func (e *thriftEncoder) structField(id int16) {
+	e.fieldHeader(id, thriftStruct)
+	e.beginStruct()
+}
+
+// beginStruct starts a struct in a list
+func (e *thriftEncoder) beginStruct() {
+	if len(e.last) == 0 {
+		e.last = append(e.last, 0)
+	}
+	e.last = append(e.last, 0)
+}
+
+func (e *thriftEncoder) endStruct() {
+	e.buf = append(e.buf, 0)
+	if len(e.last) > 0 {
+		e.last = e.last[:len(e.last)-1]
+	}
+}
@@ -0,0 +1,286 @@
+// Package parquet writes flat tables as Apache Parquet files: required
+// string and int64 columns, PLAIN encoded and uncompressed, one data page
+// per column per row group. That is enough for pandas, DuckDB, Spark and
+// Arrow to read, without pulling in a full Parquet implementation.
+package parquet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Kind is a column's type
+type Kind int
+
+const (
+	String          Kind = iota // UTF-8 byte array
+	Int64                       // Signed 64-bit integer
+	TimestampMillis             // int64 milliseconds since the Unix epoch, UTC
+)
+
+// Column is one column of a table
+type Column struct {
+	Name string
+	Kind Kind
+}
+
+// DefaultRowGroupSize is how many rows a Writer buffers before writing them
+// out as a row group
+const DefaultRowGroupSize = 10000
+
+var magic = []byte("PAR1")
+
+// Parquet's Thrift enums, as used here
+const (
+	typeInt64     = 2
+	typeByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageData           = 0
+)
+
+// Writer streams rows to w as a Parquet file. Rows are buffered per row
+// group; Close writes the last group and the footer.
+type Writer struct {
+	w            *countingWriter
+	columns      []Column
+	rowGroupSize int
+
+	values    [][]byte // Each column's PLAIN-encoded values so far
+	rows      int
+	rowGroups []rowGroup
+	started   bool
+	closed    bool
+}
+
+type rowGroup struct {
+	rows    int64
+	size    int64
+	columns []columnChunk
+}
+
+type columnChunk struct {
+	offset int64 // Of the data page header
+	size   int64 // Header and values
+}
+
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		w:            &countingWriter{w: bufio.NewWriter(w)},
+		columns:      columns,
+		rowGroupSize: DefaultRowGroupSize,
+		values:       make([][]byte, len(columns)),
+	}
+}
+
+// SetRowGroupSize sets how many rows go in each row group
+func (w *Writer) SetRowGroupSize(rows int) {
+	if rows > 0 {
+		w.rowGroupSize = rows
+	}
+}
+
+// Write appends a row. Values go in column order: a string for String
+// columns and an int64 for Int64 and TimestampMillis columns.
+func (w *Writer) Write(row ...any) error {
+	if w.closed {
+		return errors.New("parquet: write after close")
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values for %d columns", len(row), len(w.columns))
+	}
+	// Check the whole row first, so a bad value leaves the columns aligned
+	for i, column := range w.columns {
+		switch row[i].(type) {
+		case string:
+			if column.Kind != String {
+				return fmt.Errorf("parquet: column %s takes an int64, got a string", column.Name)
+			}
+		case int64:
+			if column.Kind == String {
+				return fmt.Errorf("parquet: column %s takes a string, got an int64", column.Name)
+			}
+		default:
+			return fmt.Errorf("parquet: column %s got unsupported %T", column.Name, row[i])
+		}
+	}
+	for i := range w.columns {
+		switch v := row[i].(type) {
+		case string:
+			w.values[i] = binary.LittleEndian.AppendUint32(w.values[i], uint32(len(v)))
+			w.values[i] = append(w.values[i], v...)
+		case int64:
+			w.values[i] = binary.LittleEndian.AppendUint64(w.values[i], uint64(v))
+		}
+	}
+	w.rows++
+	if w.rows >= w.rowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// Close writes the buffered rows and the footer. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	footer := w.fileMetaData()
+	if _, err := w.w.Write(footer); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer)))); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(magic); err != nil {
+		return err
+	}
+	return w.w.w.Flush()
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.Write(magic)
+	return err
+}
+
+func (w *Writer) flushRowGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	group := rowGroup{rows: int64(w.rows), columns: make([]columnChunk, len(w.columns))}
+	for i := range w.columns {
+		header := pageHeader(len(w.values[i]), w.rows)
+		chunk := columnChunk{offset: w.w.n, size: int64(len(header) + len(w.values[i]))}
+		if _, err := w.w.Write(header); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(w.values[i]); err != nil {
+			return err
+		}
+		group.columns[i] = chunk
+		group.size += chunk.size
+		w.values[i] = w.values[i][:0]
+	}
+	w.rowGroups = append(w.rowGroups, group)
+	w.rows = 0
+	return w.w.w.Flush()
+}
+
+// pageHeader is a DATA_PAGE header for size bytes of PLAIN values. Required
+// columns carry no repetition or definition levels.
+func pageHeader(size, rows int) []byte {
+	var e thriftEncoder
+	e.i32Field(1, pageData)
+	e.i32Field(2, int32(size))
+	e.i32Field(3, int32(size))
+	e.structField(5)
+	e.i32Field(1, int32(rows))
+	e.i32Field(2, encodingPlain)
+	e.i32Field(3, encodingRLE)
+	e.i32Field(4, encodingRLE)
+	e.endStruct()
+	e.endStruct()
+	return e.buf
+}
+
+func (w *Writer) fileMetaData() []byte {
+	var numRows int64
+	for _, group := range w.rowGroups {
+		numRows += group.rows
+	}
+
+	var e thriftEncoder
+	e.i32Field(1, 1)
+	e.listField(2, thriftStruct, len(w.columns)+1)
+	e.beginStruct()
+	e.binaryField(4, "schema")
+	e.i32Field(5, int32(len(w.columns)))
+	e.endStruct()
+	for _, column := range w.columns {
+		e.beginStruct()
+		typ, converted := parquetType(column.Kind)
+		e.i32Field(1, typ)
+		e.i32Field(3, repetitionRequired)
+		e.binaryField(4, column.Name)
+		if converted >= 0 {
+			e.i32Field(6, converted)
+		}
+		e.endStruct()
+	}
+	e.i64Field(3, numRows)
+	e.listField(4, thriftStruct, len(w.rowGroups))
+	for _, group := range w.rowGroups {
+		e.beginStruct()
+		e.listField(1, thriftStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			e.beginStruct()
+			e.i64Field(2, chunk.offset)
+			e.structField(3)
+			typ, _ := parquetType(w.columns[i].Kind)
+			e.i32Field(1, typ)
+			e.listField(2, thriftI32, 1)
+			e.i32(encodingPlain)
+			e.listField(3, thriftBinary, 1)
+			e.binary(w.columns[i].Name)
+			e.i32Field(4, codecUncompressed)
+			e.i64Field(5, group.rows)
+			e.i64Field(6, chunk.size)
+			e.i64Field(7, chunk.size)
+			e.i64Field(9, chunk.offset)
+			e.endStruct()
+			e.endStruct()
+		}
+		e.i64Field(2, group.size)
+		e.i64Field(3, group.rows)
+		e.endStruct()
+	}
+	e.binaryField(6, "dex-aggregator")
+	e.endStruct()
+	return e.buf
+}
+
+func parquetType(kind Kind) (typ, converted int32) {
+	switch kind {
+	case Int64:
+		return typeInt64, -1
+	case TimestampMillis:
+		return typeInt64, convertedTimestampMillis
+	}
+	return typeByteArray, convertedUTF8
+}
+
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
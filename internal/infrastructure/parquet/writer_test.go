@@ -0,0 +1,165 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// thriftDecoder reads the compact protocol into maps of field ID to value,
+// enough to check what the writer produced
+type thriftDecoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *thriftDecoder) varint() int64 {
+	v, n := binary.Varint(d.buf[d.pos:])
+	d.pos += n
+	return v
+}
+
+func (d *thriftDecoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf[d.pos:])
+	d.pos += n
+	return v
+}
+
+func (d *thriftDecoder) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return d.varint()
+	case thriftBinary:
+		n := int(d.uvarint())
+		s := string(d.buf[d.pos : d.pos+n])
+		d.pos += n
+		return s
+	case thriftList:
+		header := d.buf[d.pos]
+		d.pos++
+		n, elemType := int(header>>4), header&0x0f
+		if n == 15 {
+			n = int(d.uvarint())
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = d.value(elemType)
+		}
+		return list
+	case thriftStruct:
+		return d.structValue()
+	}
+	panic("unexpected thrift type")
+}
+
+func (d *thriftDecoder) structValue() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header := d.buf[d.pos]
+		d.pos++
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(d.varint())
+		}
+		fields[id] = d.value(header & 0x0f)
+		last = id
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	columns := []Column{{"pool", String}, {"block", Int64}, {"time", TimestampMillis}}
+	var buf bytes.Buffer
+	w := NewWriter(&buf, columns)
+	w.SetRowGroupSize(2)
+	rows := [][]any{
+		{"0xabc", int64(100), int64(1700000000000)},
+		{"0xdef", int64(101), int64(1700000012000)},
+		{"", int64(-1), int64(0)},
+	}
+	for _, row := range rows {
+		if err := w.Write(row...); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Write("0x1", "100", int64(0)); err == nil {
+		t.Error("Write() accepted a string for an int64 column")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, magic) || !bytes.HasSuffix(file, magic) {
+		t.Fatal("file is not framed by PAR1")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := file[len(file)-8-footerLen : len(file)-8]
+	meta := (&thriftDecoder{buf: footer}).structValue()
+
+	if meta[3] != int64(3) {
+		t.Errorf("num_rows = %v, want 3", meta[3])
+	}
+	schema := meta[2].([]any)
+	if len(schema) != 4 || schema[0].(map[int16]any)[5] != int64(3) {
+		t.Fatalf("schema = %v, want a root with 3 columns", schema)
+	}
+	for i, column := range columns {
+		if got := schema[i+1].(map[int16]any)[4]; got != column.Name {
+			t.Errorf("schema[%d] name = %v, want %s", i+1, got, column.Name)
+		}
+	}
+
+	// Read the values back through each chunk's page
+	groups := meta[4].([]any)
+	if len(groups) != 2 {
+		t.Fatalf("got %d row groups, want 2", len(groups))
+	}
+	var pools []string
+	var blocks, times []int64
+	for _, group := range groups {
+		chunks := group.(map[int16]any)[1].([]any)
+		for i, chunk := range chunks {
+			columnMeta := chunk.(map[int16]any)[3].(map[int16]any)
+			d := &thriftDecoder{buf: file, pos: int(columnMeta[9].(int64))}
+			page := d.structValue()
+			n := int(page[5].(map[int16]any)[1].(int64))
+			values := file[d.pos : d.pos+int(page[2].(int64))]
+			if d.pos-int(columnMeta[9].(int64))+len(values) != int(columnMeta[7].(int64)) {
+				t.Errorf("column %s chunk size = %v, want its page", columns[i].Name, columnMeta[7])
+			}
+			for j := 0; j < n; j++ {
+				switch columns[i].Kind {
+				case String:
+					size := int(binary.LittleEndian.Uint32(values))
+					pools = append(pools, string(values[4:4+size]))
+					values = values[4+size:]
+				case Int64:
+					blocks = append(blocks, int64(binary.LittleEndian.Uint64(values)))
+					values = values[8:]
+				case TimestampMillis:
+					times = append(times, int64(binary.LittleEndian.Uint64(values)))
+					values = values[8:]
+				}
+			}
+			if len(values) != 0 {
+				t.Errorf("column %s page has %d bytes past its %d values", columns[i].Name, len(values), n)
+			}
+		}
+	}
+	if len(pools) != 3 || pools[1] != "0xdef" || pools[2] != "" {
+		t.Errorf("pools = %q", pools)
+	}
+	if len(blocks) != 3 || blocks[0] != 100 || blocks[2] != -1 {
+		t.Errorf("blocks = %v", blocks)
+	}
+	if len(times) != 3 || times[1] != 1700000012000 {
+		t.Errorf("times = %v", times)
+	}
+	if converted := schema[3].(map[int16]any)[6]; converted != int64(convertedTimestampMillis) {
+		t.Errorf("time converted type = %v, want TIMESTAMP_MILLIS", converted)
+	}
+}
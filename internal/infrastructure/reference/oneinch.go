@@ -0,0 +1,59 @@
+package reference
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+)
+
+const OneInchAPIURL = "https://api.1inch.dev/swap/v6.0"
+
+// OneInchQuoter reads quotes from the 1inch Swap API v6
+type OneInchQuoter struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewOneInchQuoter(baseURL, apiKey string) *OneInchQuoter {
+	return &OneInchQuoter{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  httpclient.New(10 * time.Second),
+	}
+}
+
+func (q *OneInchQuoter) Name() string {
+	return "1inch"
+}
+
+type oneInchQuote struct {
+	DstAmount string `json:"dstAmount"`
+}
+
+func (q *OneInchQuoter) Quote(ctx context.Context, chainID uint64, tokenIn, tokenOut common.Address, amountIn *big.Int) (*big.Int, error) {
+	query := url.Values{}
+	query.Set("src", tokenIn.Hex())
+	query.Set("dst", tokenOut.Hex())
+	query.Set("amount", amountIn.String())
+
+	endpoint := fmt.Sprintf("%s/%d/quote?%s", q.baseURL, chainID, query.Encode())
+	headers := map[string]string{"Authorization": "Bearer " + q.apiKey}
+	var quote oneInchQuote
+	if err := httpclient.GetJSON(ctx, q.client, endpoint, headers, &quote); err != nil {
+		return nil, fmt.Errorf("1inch: %w", err)
+	}
+
+	amountOut, err := httpclient.ParseAmount(quote.DstAmount)
+	if err != nil {
+		return nil, fmt.Errorf("1inch: %w", err)
+	}
+	return amountOut, nil
+}
@@ -0,0 +1,54 @@
+package reference
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestOneInchQuoter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"quote", http.StatusOK, `{"dstAmount":"3456789012"}`, "3456789012", ""},
+		{"missing amount", http.StatusOK, `{}`, "", "invalid amount"},
+		{"not json", http.StatusOK, `<html>`, "", "invalid character"},
+		{"unauthorized", http.StatusUnauthorized, `{"error":"Unauthorized"}`, "", "status 401"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/1/quote" || r.Header.Get("Authorization") != "Bearer key" {
+					t.Errorf("request %s with headers %v", r.URL, r.Header)
+				}
+				if query.Get("src") != entities.WETH.Address.Hex() || query.Get("dst") != entities.USDC.Address.Hex() || query.Get("amount") != "1000000000000000000" {
+					t.Errorf("query = %v", query)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			amount, err := NewOneInchQuoter(server.URL, "key").Quote(context.Background(), 1, entities.WETH.Address, entities.USDC.Address, big.NewInt(1e18))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "1inch: ") {
+					t.Fatalf("Quote() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || amount.String() != tt.want {
+				t.Errorf("Quote() = %v, %v, want %s", amount, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package reference
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Quoter returns another aggregator's output amount for a swap
+type Quoter interface {
+	Name() string
+	Quote(ctx context.Context, chainID uint64, tokenIn, tokenOut common.Address, amountIn *big.Int) (*big.Int, error)
+}
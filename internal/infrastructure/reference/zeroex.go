@@ -0,0 +1,64 @@
+package reference
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+)
+
+const ZeroExAPIURL = "https://api.0x.org"
+
+// ZeroExQuoter reads indicative prices from the 0x Swap API v2
+type ZeroExQuoter struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewZeroExQuoter(baseURL, apiKey string) *ZeroExQuoter {
+	return &ZeroExQuoter{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  httpclient.New(10 * time.Second),
+	}
+}
+
+func (q *ZeroExQuoter) Name() string {
+	return "0x"
+}
+
+type zeroExPrice struct {
+	BuyAmount          string `json:"buyAmount"`
+	LiquidityAvailable bool   `json:"liquidityAvailable"`
+}
+
+func (q *ZeroExQuoter) Quote(ctx context.Context, chainID uint64, tokenIn, tokenOut common.Address, amountIn *big.Int) (*big.Int, error) {
+	query := url.Values{}
+	query.Set("chainId", strconv.FormatUint(chainID, 10))
+	query.Set("sellToken", tokenIn.Hex())
+	query.Set("buyToken", tokenOut.Hex())
+	query.Set("sellAmount", amountIn.String())
+
+	headers := map[string]string{"0x-api-key": q.apiKey, "0x-version": "v2"}
+	var price zeroExPrice
+	if err := httpclient.GetJSON(ctx, q.client, q.baseURL+"/swap/permit2/price?"+query.Encode(), headers, &price); err != nil {
+		return nil, fmt.Errorf("0x: %w", err)
+	}
+	if !price.LiquidityAvailable {
+		return nil, fmt.Errorf("0x: no liquidity")
+	}
+
+	amountOut, err := httpclient.ParseAmount(price.BuyAmount)
+	if err != nil {
+		return nil, fmt.Errorf("0x: %w", err)
+	}
+	return amountOut, nil
+}
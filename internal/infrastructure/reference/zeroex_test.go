@@ -0,0 +1,54 @@
+package reference
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestZeroExQuoter(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"price", http.StatusOK, `{"buyAmount":"3456789012","liquidityAvailable":true,"sellAmount":"1000000000000000000"}`, "3456789012", ""},
+		{"no liquidity", http.StatusOK, `{"liquidityAvailable":false}`, "", "no liquidity"},
+		{"bad amount", http.StatusOK, `{"buyAmount":"1.5e9","liquidityAvailable":true}`, "", "invalid amount"},
+		{"rejected", http.StatusBadRequest, `{"name":"INPUT_INVALID"}`, "", "status 400: {\"name\":\"INPUT_INVALID\"}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/swap/permit2/price" || r.Header.Get("0x-api-key") != "key" || r.Header.Get("0x-version") != "v2" {
+					t.Errorf("request %s with headers %v", r.URL, r.Header)
+				}
+				if query.Get("chainId") != "1" || query.Get("sellToken") != entities.WETH.Address.Hex() || query.Get("sellAmount") != "1000000000000000000" {
+					t.Errorf("query = %v", query)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			amount, err := NewZeroExQuoter(server.URL, "key").Quote(context.Background(), 1, entities.WETH.Address, entities.USDC.Address, big.NewInt(1e18))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "0x: ") {
+					t.Fatalf("Quote() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || amount.String() != tt.want {
+				t.Errorf("Quote() = %v, %v, want %s", amount, err, tt.want)
+			}
+		})
+	}
+}
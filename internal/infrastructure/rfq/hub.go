@@ -0,0 +1,315 @@
+package rfq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/websocket"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+)
+
+// Message types exchanged with market makers
+const (
+	msgQuoteRequest = "quote_request"
+	msgQuote        = "quote"
+	msgError        = "error"
+)
+
+// Maker is a registered market maker. APIKey authenticates its WebSocket
+// connection; Address must sign every order it returns.
+type Maker struct {
+	Name    string         `json:"name"`
+	Address common.Address `json:"address"`
+	APIKey  string         `json:"apiKey"`
+}
+
+// LoadMakers reads {"makers": [...]} from a JSON file
+func LoadMakers(path string) ([]Maker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Makers []Maker `json:"makers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse makers: %w", err)
+	}
+	return config.Makers, nil
+}
+
+type message struct {
+	Type    string          `json:"type"`
+	Request *requestMessage `json:"request,omitempty"`
+	Quote   *quoteMessage   `json:"quote,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+type requestMessage struct {
+	ID        string `json:"id"`
+	ChainID   uint64 `json:"chainId"`
+	TokenIn   string `json:"tokenIn"`
+	TokenOut  string `json:"tokenOut"`
+	AmountIn  string `json:"amountIn"`
+	Taker     string `json:"taker"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+type quoteMessage struct {
+	RequestID string        `json:"requestId"`
+	AmountOut string        `json:"amountOut"`
+	Expiry    uint64        `json:"expiry"`
+	Nonce     string        `json:"nonce"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+type makerConn struct {
+	maker   Maker
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *makerConn) send(msg message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return c.ws.WriteJSON(msg)
+}
+
+type pendingRequest struct {
+	request entities.RFQRequest
+	orders  chan *entities.RFQOrder
+
+	mu        sync.Mutex
+	responded map[*makerConn]bool // One quote per maker per request
+}
+
+// Hub holds market maker WebSocket connections, fans out quote requests and
+// collects signed firm quotes until the response window closes
+type Hub struct {
+	makers   map[string]Maker // By API key
+	domain   eip712.Domain
+	timeout  time.Duration
+	upgrader websocket.Upgrader
+
+	mu      sync.RWMutex
+	conns   map[*makerConn]struct{}
+	pending map[string]*pendingRequest
+}
+
+func NewHub(makers []Maker, domain eip712.Domain, timeout time.Duration) *Hub {
+	byKey := make(map[string]Maker, len(makers))
+	for _, m := range makers {
+		byKey[m.APIKey] = m
+	}
+
+	return &Hub{
+		makers:  byKey,
+		domain:  domain,
+		timeout: timeout,
+		upgrader: websocket.Upgrader{
+			// Makers are servers, not browsers; the API key is the access control
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		conns:   make(map[*makerConn]struct{}),
+		pending: make(map[string]*pendingRequest),
+	}
+}
+
+// ServeHTTP authenticates a maker by bearer API key and upgrades to WebSocket.
+// The connection is served in the background so request middleware
+// deadlines do not apply to it.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	apiKey := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	maker, ok := h.makers[apiKey]
+	if apiKey == "" || !ok {
+		http.Error(w, "unknown market maker", http.StatusUnauthorized)
+		return
+	}
+
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	conn := &makerConn{maker: maker, ws: ws}
+	h.mu.Lock()
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+	log.Printf("RFQ maker %s connected", maker.Name)
+
+	go h.readLoop(conn)
+}
+
+// Connected returns the number of connected makers
+func (h *Hub) Connected() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+func (h *Hub) readLoop(conn *makerConn) {
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		conn.ws.Close()
+		log.Printf("RFQ maker %s disconnected", conn.maker.Name)
+	}()
+
+	for {
+		var msg message
+		if err := conn.ws.ReadJSON(&msg); err != nil {
+			return
+		}
+		if msg.Type != msgQuote || msg.Quote == nil {
+			continue
+		}
+		if err := h.handleQuote(conn, msg.Quote); err != nil {
+			_ = conn.send(message{Type: msgError, Error: err.Error()})
+		}
+	}
+}
+
+// handleQuote verifies a maker's response against its open request
+func (h *Hub) handleQuote(conn *makerConn, quote *quoteMessage) error {
+	h.mu.RLock()
+	pending, ok := h.pending[quote.RequestID]
+	h.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("request %s is closed", quote.RequestID)
+	}
+
+	amountOut, ok := new(big.Int).SetString(quote.AmountOut, 10)
+	if !ok || amountOut.Sign() <= 0 {
+		return fmt.Errorf("amountOut must be a positive integer")
+	}
+	nonce, ok := new(big.Int).SetString(quote.Nonce, 10)
+	if !ok {
+		return fmt.Errorf("nonce must be an integer")
+	}
+
+	req := pending.request
+	order := &entities.RFQOrder{
+		MakerName: conn.maker.Name,
+		Maker:     conn.maker.Address,
+		Taker:     req.Taker,
+		TokenIn:   req.TokenIn.Address,
+		TokenOut:  req.TokenOut.Address,
+		AmountIn:  req.AmountIn,
+		AmountOut: amountOut,
+		Expiry:    quote.Expiry,
+		Nonce:     nonce,
+		Signature: quote.Signature,
+	}
+	if err := VerifyOrder(h.domain, order); err != nil {
+		return err
+	}
+
+	pending.mu.Lock()
+	defer pending.mu.Unlock()
+	if pending.responded[conn] {
+		return fmt.Errorf("duplicate quote for request %s", quote.RequestID)
+	}
+	pending.responded[conn] = true
+
+	select {
+	case pending.orders <- order:
+	default:
+		// Only makers that received the request have buffer space
+		return fmt.Errorf("request %s was not sent to this maker", quote.RequestID)
+	}
+	return nil
+}
+
+// RequestQuotes asks every connected maker to quote and returns the valid
+// signed orders received within the response window
+func (h *Hub) RequestQuotes(ctx context.Context, tokenIn, tokenOut entities.Token, amountIn *big.Int) []entities.RFQOrder {
+	h.mu.Lock()
+	if len(h.conns) == 0 {
+		h.mu.Unlock()
+		return nil
+	}
+	conns := make([]*makerConn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+
+	deadline := time.Now().Add(h.timeout)
+	pending := &pendingRequest{
+		request: entities.RFQRequest{
+			ID:        newRequestID(),
+			ChainID:   h.domain.ChainID.Uint64(),
+			TokenIn:   tokenIn,
+			TokenOut:  tokenOut,
+			AmountIn:  amountIn,
+			ExpiresAt: deadline.UnixMilli(),
+		},
+		orders:    make(chan *entities.RFQOrder, len(conns)),
+		responded: make(map[*makerConn]bool),
+	}
+	h.pending[pending.request.ID] = pending
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.pending, pending.request.ID)
+		h.mu.Unlock()
+	}()
+
+	req := pending.request
+	msg := message{Type: msgQuoteRequest, Request: &requestMessage{
+		ID:        req.ID,
+		ChainID:   req.ChainID,
+		TokenIn:   req.TokenIn.Address.Hex(),
+		TokenOut:  req.TokenOut.Address.Hex(),
+		AmountIn:  req.AmountIn.String(),
+		Taker:     req.Taker.Hex(),
+		ExpiresAt: req.ExpiresAt,
+	}}
+	for _, c := range conns {
+		if err := c.send(msg); err != nil {
+			log.Printf("RFQ request to %s failed: %v", c.maker.Name, err)
+		}
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	var orders []entities.RFQOrder
+	for len(orders) < len(conns) {
+		select {
+		case order := <-pending.orders:
+			// An order that expires before it can be settled is not firm
+			if order.Expiry > uint64(time.Now().Unix()) {
+				orders = append(orders, *order)
+			}
+		case <-timer.C:
+			return orders
+		case <-ctx.Done():
+			return orders
+		}
+	}
+	return orders
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,76 @@
+package rfq
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/websocket"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestHubRequestQuotes(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	maker := Maker{Name: "mm1", Address: crypto.PubkeyToAddress(key.PublicKey), APIKey: "secret"}
+	hub := NewHub([]Maker{maker}, testDomain, 2*time.Second)
+
+	server := httptest.NewServer(hub)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial without API key: err = %v, want 401", err)
+	}
+
+	ws, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bearer secret"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer ws.Close()
+
+	for hub.Connected() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The maker answers each request with a signed order for 3000 USDC
+	go func() {
+		var msg message
+		if err := ws.ReadJSON(&msg); err != nil || msg.Request == nil {
+			return
+		}
+		amountIn, _ := new(big.Int).SetString(msg.Request.AmountIn, 10)
+		order := &entities.RFQOrder{
+			Maker:     maker.Address,
+			Taker:     common.HexToAddress(msg.Request.Taker),
+			TokenIn:   common.HexToAddress(msg.Request.TokenIn),
+			TokenOut:  common.HexToAddress(msg.Request.TokenOut),
+			AmountIn:  amountIn,
+			AmountOut: big.NewInt(3000e6),
+			Expiry:    uint64(time.Now().Add(time.Minute).Unix()),
+			Nonce:     big.NewInt(1),
+		}
+		sig, _ := crypto.Sign(OrderHash(testDomain, order).Bytes(), key)
+		ws.WriteJSON(message{Type: msgQuote, Quote: &quoteMessage{
+			RequestID: msg.Request.ID,
+			AmountOut: order.AmountOut.String(),
+			Expiry:    order.Expiry,
+			Nonce:     "1",
+			Signature: sig,
+		}})
+	}()
+
+	orders := hub.RequestQuotes(context.Background(), entities.WETH, entities.USDC, big.NewInt(1e18))
+	if len(orders) != 1 {
+		t.Fatalf("RequestQuotes() returned %d orders, want 1", len(orders))
+	}
+	if orders[0].MakerName != "mm1" || orders[0].AmountOut.Int64() != 3000e6 {
+		t.Errorf("order = %+v, want mm1 quoting 3000 USDC", orders[0])
+	}
+}
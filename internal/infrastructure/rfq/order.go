@@ -0,0 +1,51 @@
+package rfq
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+)
+
+var orderTypeHash = crypto.Keccak256([]byte("Order(address maker,address taker,address tokenIn,address tokenOut,uint256 amountIn,uint256 amountOut,uint256 expiry,uint256 nonce)"))
+
+// NewDomain returns the EIP-712 domain makers sign orders in
+func NewDomain(chainID *big.Int, settlement common.Address) eip712.Domain {
+	return eip712.Domain{
+		Name:              "DEX Aggregator RFQ",
+		Version:           "1",
+		ChainID:           chainID,
+		VerifyingContract: settlement,
+	}
+}
+
+// OrderHash returns the EIP-712 digest a maker signs for order
+func OrderHash(domain eip712.Domain, order *entities.RFQOrder) common.Hash {
+	return domain.Digest(crypto.Keccak256(
+		orderTypeHash,
+		eip712.Address(order.Maker),
+		eip712.Address(order.Taker),
+		eip712.Address(order.TokenIn),
+		eip712.Address(order.TokenOut),
+		eip712.Uint(order.AmountIn),
+		eip712.Uint(order.AmountOut),
+		eip712.Uint(new(big.Int).SetUint64(order.Expiry)),
+		eip712.Uint(order.Nonce),
+	))
+}
+
+// VerifyOrder checks that order carries a valid signature from order.Maker
+func VerifyOrder(domain eip712.Domain, order *entities.RFQOrder) error {
+	signer, err := eip712.Recover(OrderHash(domain, order), order.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != order.Maker {
+		return fmt.Errorf("order signed by %s, not maker %s", signer.Hex(), order.Maker.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package rfq
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var testDomain = NewDomain(big.NewInt(1), common.HexToAddress("0x00000000000000000000000000000000000000dd"))
+
+func testOrder(maker common.Address) *entities.RFQOrder {
+	return &entities.RFQOrder{
+		Maker:     maker,
+		TokenIn:   entities.WETH.Address,
+		TokenOut:  entities.USDC.Address,
+		AmountIn:  big.NewInt(1e18),
+		AmountOut: big.NewInt(3000e6),
+		Expiry:    1900000000,
+		Nonce:     big.NewInt(42),
+	}
+}
+
+func TestOrderHashMatchesEIP712(t *testing.T) {
+	order := testOrder(common.HexToAddress("0x00000000000000000000000000000000000000aa"))
+
+	typed := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Order": {
+				{Name: "maker", Type: "address"},
+				{Name: "taker", Type: "address"},
+				{Name: "tokenIn", Type: "address"},
+				{Name: "tokenOut", Type: "address"},
+				{Name: "amountIn", Type: "uint256"},
+				{Name: "amountOut", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Order",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "DEX Aggregator RFQ",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(testDomain.ChainID),
+			VerifyingContract: testDomain.VerifyingContract.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"maker":     order.Maker.Hex(),
+			"taker":     order.Taker.Hex(),
+			"tokenIn":   order.TokenIn.Hex(),
+			"tokenOut":  order.TokenOut.Hex(),
+			"amountIn":  order.AmountIn.String(),
+			"amountOut": order.AmountOut.String(),
+			"expiry":    "1900000000",
+			"nonce":     order.Nonce.String(),
+		},
+	}
+
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := OrderHash(testDomain, order); !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("OrderHash() = %x, want %x", got, want)
+	}
+}
+
+func TestVerifyOrder(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	order := testOrder(crypto.PubkeyToAddress(key.PublicKey))
+
+	sig, err := crypto.Sign(OrderHash(testDomain, order).Bytes(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] += 27 // Wallet-style v
+	order.Signature = sig
+
+	if err := VerifyOrder(testDomain, order); err != nil {
+		t.Errorf("VerifyOrder() error = %v", err)
+	}
+
+	order.AmountOut = big.NewInt(3001e6)
+	if err := VerifyOrder(testDomain, order); err == nil {
+		t.Error("VerifyOrder() accepted a tampered order")
+	}
+}
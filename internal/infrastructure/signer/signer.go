@@ -0,0 +1,184 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Signer signs transactions on behalf of a single account
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// KeySigner signs with an in-process private key
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewPrivateKeySigner creates a signer from a hex-encoded private key
+func NewPrivateKeySigner(hexKey string) (*KeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return newKeySigner(key), nil
+}
+
+// NewKeystoreSigner decrypts a go-ethereum keystore file
+func NewKeystoreSigner(path, passphrase string) (*KeySigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return newKeySigner(key.PrivateKey), nil
+}
+
+func newKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// ExternalSigner delegates to a remote signer speaking eth_signTransaction
+// (web3signer, Clef, or a KMS-backed signing proxy such as one fronting AWS KMS),
+// so the key never enters this process
+type ExternalSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+func NewExternalSigner(ctx context.Context, url string, address common.Address) (*ExternalSigner, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer: %w", err)
+	}
+	return &ExternalSigner{client: client, address: address}, nil
+}
+
+func (s *ExternalSigner) Address() common.Address {
+	return s.address
+}
+
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+func (s *ExternalSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:                 s.address,
+		To:                   tx.To(),
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                (*hexutil.Big)(tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Data:                 tx.Data(),
+		ChainID:              (*hexutil.Big)(chainID),
+	}
+
+	var result json.RawMessage
+	if err := s.client.CallContext(ctx, &result, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("external signer failed: %w", err)
+	}
+
+	raw, err := decodeSignResult(result)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+	if err := checkSigned(tx, signed, chainID, s.address); err != nil {
+		return nil, fmt.Errorf("external signer returned a different transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// checkSigned rejects a signed transaction that isn't the requested one
+// signed by from, so a compromised or misconfigured signer can't send
+// something else from the hot wallet
+func checkSigned(want, got *types.Transaction, chainID *big.Int, from common.Address) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), got)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if sender != from {
+		return fmt.Errorf("signed by %s, not %s", sender.Hex(), from.Hex())
+	}
+
+	wantTo, gotTo := want.To(), got.To()
+	switch {
+	case got.Type() != want.Type():
+		return fmt.Errorf("type %d, not %d", got.Type(), want.Type())
+	case got.Nonce() != want.Nonce():
+		return fmt.Errorf("nonce %d, not %d", got.Nonce(), want.Nonce())
+	case (gotTo == nil) != (wantTo == nil) || (gotTo != nil && *gotTo != *wantTo):
+		return fmt.Errorf("to %v, not %v", gotTo, wantTo)
+	case !bytes.Equal(got.Data(), want.Data()):
+		return errors.New("calldata differs")
+	case got.Value().Cmp(want.Value()) != 0:
+		return fmt.Errorf("value %s, not %s", got.Value(), want.Value())
+	case got.Gas() != want.Gas():
+		return fmt.Errorf("gas %d, not %d", got.Gas(), want.Gas())
+	case got.GasFeeCap().Cmp(want.GasFeeCap()) != 0 || got.GasTipCap().Cmp(want.GasTipCap()) != 0:
+		return fmt.Errorf("fees %s/%s, not %s/%s", got.GasFeeCap(), got.GasTipCap(), want.GasFeeCap(), want.GasTipCap())
+	}
+	return nil
+}
+
+// decodeSignResult accepts both the bare raw-hex result (web3signer) and
+// geth's {raw, tx} object
+func decodeSignResult(result json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(result, &raw); err == nil {
+		return raw, nil
+	}
+
+	var wrapped struct {
+		Raw hexutil.Bytes `json:"raw"`
+	}
+	if err := json.Unmarshal(result, &wrapped); err != nil || len(wrapped.Raw) == 0 {
+		return nil, fmt.Errorf("unrecognised eth_signTransaction result")
+	}
+	return wrapped.Raw, nil
+}
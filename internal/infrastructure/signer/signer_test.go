@@ -0,0 +1,95 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// remoteSigner serves eth_signTransaction, optionally tampering with what it
+// was asked to sign
+type remoteSigner struct {
+	key    *ecdsa.PrivateKey
+	tamper func(tx *types.DynamicFeeTx)
+}
+
+func (s *remoteSigner) SignTransaction(args signTxArgs) (hexutil.Bytes, error) {
+	inner := &types.DynamicFeeTx{
+		ChainID:   args.ChainID.ToInt(),
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+		GasFeeCap: args.MaxFeePerGas.ToInt(),
+		Gas:       uint64(args.Gas),
+		To:        args.To,
+		Value:     args.Value.ToInt(),
+		Data:      args.Data,
+	}
+	if s.tamper != nil {
+		s.tamper(inner)
+	}
+	signed, err := types.SignNewTx(s.key, types.LatestSignerForChainID(inner.ChainID), inner)
+	if err != nil {
+		return nil, err
+	}
+	return signed.MarshalBinary()
+}
+
+func TestExternalSignerChecksResult(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	attacker := common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	chainID := big.NewInt(1)
+
+	tests := []struct {
+		name    string
+		key     *ecdsa.PrivateKey
+		tamper  func(tx *types.DynamicFeeTx)
+		wantErr string
+	}{
+		{"honest", key, nil, ""},
+		{"other key", other, nil, "signed by"},
+		{"nonce", key, func(tx *types.DynamicFeeTx) { tx.Nonce++ }, "nonce"},
+		{"recipient", key, func(tx *types.DynamicFeeTx) { tx.To = &attacker }, "to"},
+		{"calldata", key, func(tx *types.DynamicFeeTx) { tx.Data = []byte{0xde, 0xad} }, "calldata"},
+		{"value", key, func(tx *types.DynamicFeeTx) { tx.Value = big.NewInt(1e18) }, "value"},
+		{"fees", key, func(tx *types.DynamicFeeTx) { tx.GasFeeCap = big.NewInt(1e12) }, "fees"},
+		{"chain", key, func(tx *types.DynamicFeeTx) { tx.ChainID = big.NewInt(5) }, "invalid signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			if err := server.RegisterName("eth", &remoteSigner{key: tt.key, tamper: tt.tamper}); err != nil {
+				t.Fatal(err)
+			}
+			defer server.Stop()
+			signer := &ExternalSigner{client: rpc.DialInProc(server), address: crypto.PubkeyToAddress(key.PublicKey)}
+
+			tx := types.NewTx(&types.DynamicFeeTx{
+				ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(2e9), GasFeeCap: big.NewInt(30e9),
+				Gas: 200000, To: &to, Value: big.NewInt(0), Data: []byte{0x01, 0x02},
+			})
+			signed, err := signer.SignTx(context.Background(), tx, chainID)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("SignTx() error = %v", err)
+				}
+				if signed.Nonce() != 7 || *signed.To() != to {
+					t.Errorf("signed nonce %d to %s", signed.Nonce(), signed.To().Hex())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SignTx() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package sqlite persists the state a single instance otherwise keeps in
+// Redis or in memory, for the embedded mode: API keys and their usage,
+// route bookmarks, learned gas, limit orders and the fee ledger
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "modernc.org/sqlite"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// schema is applied on every open; each statement is idempotent. Records
+// are kept as JSON beside the columns they are looked up by.
+const schema = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+-- day is a UTC date, or '' for the all-time counters
+CREATE TABLE IF NOT EXISTS api_key_usage (
+	id           TEXT NOT NULL,
+	day          TEXT NOT NULL,
+	requests     INTEGER NOT NULL DEFAULT 0,
+	quotes       INTEGER NOT NULL DEFAULT 0,
+	volume_cents INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (id, day)
+);
+CREATE TABLE IF NOT EXISTS bookmarks (
+	key_id TEXT NOT NULL,
+	name   TEXT NOT NULL,
+	data   TEXT NOT NULL,
+	PRIMARY KEY (key_id, name)
+);
+CREATE TABLE IF NOT EXISTS gas_stats (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS orders (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS fee_ledgers (
+	collector  TEXT PRIMARY KEY,
+	next_block INTEGER NOT NULL,
+	accruals   TEXT NOT NULL
+);`
+
+// Store is one SQLite database file
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it and its tables as needed
+func Open(path string) (*Store, error) {
+	// One connection serialises writers, which SQLite does anyway
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(on)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables in %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) SaveKey(ctx context.Context, key entities.APIKey) error {
+	return s.put(ctx, `INSERT INTO api_keys (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, key, key.ID)
+}
+
+func (s *Store) GetKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	if found, err := s.get(ctx, &key, `SELECT data FROM api_keys WHERE id = ?`, id); err != nil || !found {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Store) ListKeys(ctx context.Context) ([]entities.APIKey, error) {
+	keys := []entities.APIKey{}
+	err := s.list(ctx, func(data []byte) error {
+		var key entities.APIKey
+		if err := json.Unmarshal(data, &key); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		return nil
+	}, `SELECT data FROM api_keys ORDER BY id`)
+	return keys, err
+}
+
+func (s *Store) DeleteKey(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, query := range []string{
+		`DELETE FROM api_keys WHERE id = ?`,
+		`DELETE FROM api_key_usage WHERE id = ?`,
+		`DELETE FROM bookmarks WHERE key_id = ?`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) IncrRequests(ctx context.Context, id, day string) (int64, error) {
+	if err := s.incr(ctx, id, day, 1, 0, 0); err != nil {
+		return 0, err
+	}
+	var requests int64
+	err := s.db.QueryRowContext(ctx, `SELECT requests FROM api_key_usage WHERE id = ? AND day = ?`, id, day).Scan(&requests)
+	return requests, err
+}
+
+func (s *Store) IncrQuotes(ctx context.Context, id, day string, volumeUSDCents int64) error {
+	return s.incr(ctx, id, day, 0, 1, volumeUSDCents)
+}
+
+func (s *Store) Usage(ctx context.Context, id, day string) (entities.APIKeyUsage, error) {
+	var usage entities.APIKeyUsage
+	rows, err := s.db.QueryContext(ctx, `SELECT day, requests, quotes, volume_cents FROM api_key_usage WHERE id = ? AND day IN ('', ?)`, id, day)
+	if err != nil {
+		return usage, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var rowDay string
+		var requests, quotes, volume int64
+		if err := rows.Scan(&rowDay, &requests, &quotes, &volume); err != nil {
+			return usage, err
+		}
+		if rowDay == "" {
+			usage.Requests, usage.Quotes, usage.VolumeUSDCents = requests, quotes, volume
+		} else {
+			usage.RequestsToday, usage.QuotesToday, usage.VolumeUSDCentsToday = requests, quotes, volume
+		}
+	}
+	return usage, rows.Err()
+}
+
+// incr adds to the day's counters and the all-time ones together
+func (s *Store) incr(ctx context.Context, id, day string, requests, quotes, volume int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, d := range []string{"", day} {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO api_key_usage (id, day, requests, quotes, volume_cents) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (id, day) DO UPDATE SET requests = requests + excluded.requests,
+				quotes = quotes + excluded.quotes, volume_cents = volume_cents + excluded.volume_cents`,
+			id, d, requests, quotes, volume); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) SaveBookmark(ctx context.Context, bookmark entities.RouteBookmark) error {
+	return s.put(ctx, `INSERT INTO bookmarks (key_id, name, data) VALUES (?, ?, ?)
+		ON CONFLICT (key_id, name) DO UPDATE SET data = excluded.data`, bookmark, bookmark.KeyID, bookmark.Name)
+}
+
+func (s *Store) GetBookmark(ctx context.Context, keyID, name string) (*entities.RouteBookmark, error) {
+	var bookmark entities.RouteBookmark
+	if found, err := s.get(ctx, &bookmark, `SELECT data FROM bookmarks WHERE key_id = ? AND name = ?`, keyID, name); err != nil || !found {
+		return nil, err
+	}
+	return &bookmark, nil
+}
+
+func (s *Store) ListBookmarks(ctx context.Context, keyID string) ([]entities.RouteBookmark, error) {
+	bookmarks := []entities.RouteBookmark{}
+	err := s.list(ctx, func(data []byte) error {
+		var bookmark entities.RouteBookmark
+		if err := json.Unmarshal(data, &bookmark); err != nil {
+			return err
+		}
+		bookmarks = append(bookmarks, bookmark)
+		return nil
+	}, `SELECT data FROM bookmarks WHERE key_id = ? ORDER BY name`, keyID)
+	return bookmarks, err
+}
+
+func (s *Store) DeleteBookmark(ctx context.Context, keyID, name string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM bookmarks WHERE key_id = ? AND name = ?`, keyID, name)
+	return err
+}
+
+func (s *Store) LoadGasStats(ctx context.Context) ([]entities.GasStats, error) {
+	var stats []entities.GasStats
+	if _, err := s.get(ctx, &stats, `SELECT data FROM gas_stats WHERE id = 1`); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *Store) SaveGasStats(ctx context.Context, stats []entities.GasStats) error {
+	return s.put(ctx, `INSERT INTO gas_stats (id, data) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, stats)
+}
+
+func (s *Store) SaveOrder(ctx context.Context, order *entities.Order) error {
+	return s.put(ctx, `INSERT INTO orders (id, data) VALUES (?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, order, order.ID.Hex())
+}
+
+func (s *Store) ListOrders(ctx context.Context) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	err := s.list(ctx, func(data []byte) error {
+		var order entities.Order
+		if err := json.Unmarshal(data, &order); err != nil {
+			return err
+		}
+		orders = append(orders, &order)
+		return nil
+	}, `SELECT data FROM orders`)
+	return orders, err
+}
+
+func (s *Store) LoadFeeLedger(ctx context.Context, collector common.Address) ([]entities.FeeAccrual, uint64, error) {
+	var next uint64
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT next_block, accruals FROM fee_ledgers WHERE collector = ?`, collector.Hex()).Scan(&next, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var accruals []entities.FeeAccrual
+	if err := json.Unmarshal(data, &accruals); err != nil {
+		return nil, 0, err
+	}
+	return accruals, next, nil
+}
+
+func (s *Store) SaveFeeLedger(ctx context.Context, collector common.Address, accruals []entities.FeeAccrual, next uint64) error {
+	data, err := json.Marshal(accruals)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO fee_ledgers (collector, next_block, accruals) VALUES (?, ?, ?)
+		ON CONFLICT (collector) DO UPDATE SET next_block = excluded.next_block, accruals = excluded.accruals`,
+		collector.Hex(), next, data)
+	return err
+}
+
+// put stores v as JSON, passed after args as the last parameter
+func (s *Store) put(ctx context.Context, query string, v any, args ...any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, query, append(args, data)...)
+	return err
+}
+
+// get decodes the JSON of the row query selects into v, reporting whether
+// there was one
+func (s *Store) get(ctx context.Context, v any, query string, args ...any) (bool, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// list hands each row's JSON to decode
+func (s *Store) list(ctx context.Context, decode func(data []byte) error, query string, args ...any) error {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := decode(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestStorePersistsAcrossOpens(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "embedded.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if err := store.SaveKey(ctx, entities.APIKey{ID: "k1", Name: "partner", DailyQuota: 100}); err != nil {
+		t.Fatalf("SaveKey() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := store.IncrRequests(ctx, "k1", "2026-10-15"); err != nil {
+			t.Fatalf("IncrRequests() error = %v", err)
+		}
+	}
+	if n, _ := store.IncrRequests(ctx, "k1", "2026-10-16"); n != 1 {
+		t.Errorf("IncrRequests() on a new day = %d, want 1", n)
+	}
+	if err := store.IncrQuotes(ctx, "k1", "2026-10-16", 250); err != nil {
+		t.Fatalf("IncrQuotes() error = %v", err)
+	}
+	if err := store.SaveBookmark(ctx, entities.RouteBookmark{KeyID: "k1", Name: "eth-usdc", TokenIn: entities.WETH.Address}); err != nil {
+		t.Fatalf("SaveBookmark() error = %v", err)
+	}
+	order := &entities.Order{ID: common.HexToHash("0x01"), Owner: common.HexToAddress("0xaa"), AmountIn: big.NewInt(5), EndTime: 100, Status: entities.OrderOpen}
+	if err := store.SaveOrder(ctx, order); err != nil {
+		t.Fatalf("SaveOrder() error = %v", err)
+	}
+	collector := common.HexToAddress("0xfee")
+	accrual := entities.FeeAccrual{Recipient: common.HexToAddress("0xbb"), Token: entities.USDC.Address, Amount: big.NewInt(300), Swaps: 2, Claimed: big.NewInt(100), Claims: 1}
+	if err := store.SaveFeeLedger(ctx, collector, []entities.FeeAccrual{accrual}, 19_000_001); err != nil {
+		t.Fatalf("SaveFeeLedger() error = %v", err)
+	}
+	if err := store.SaveGasStats(ctx, []entities.GasStats{{DEX: entities.DEXUniswapV2, MedianPerHop: 90000, Samples: 40}}); err != nil {
+		t.Fatalf("SaveGasStats() error = %v", err)
+	}
+	store.Close()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	defer store.Close()
+
+	if key, err := store.GetKey(ctx, "k1"); err != nil || key == nil || key.Name != "partner" {
+		t.Errorf("GetKey() = %+v, %v", key, err)
+	}
+	usage, err := store.Usage(ctx, "k1", "2026-10-16")
+	if err != nil {
+		t.Fatalf("Usage() error = %v", err)
+	}
+	want := entities.APIKeyUsage{Requests: 4, Quotes: 1, VolumeUSDCents: 250, RequestsToday: 1, QuotesToday: 1, VolumeUSDCentsToday: 250}
+	if usage != want {
+		t.Errorf("Usage() = %+v, want %+v", usage, want)
+	}
+	if bookmarks, _ := store.ListBookmarks(ctx, "k1"); len(bookmarks) != 1 || bookmarks[0].TokenIn != entities.WETH.Address {
+		t.Errorf("ListBookmarks() = %+v", bookmarks)
+	}
+	if orders, _ := store.ListOrders(ctx); len(orders) != 1 || orders[0].ID != order.ID || orders[0].AmountIn.Int64() != 5 {
+		t.Errorf("ListOrders() = %+v", orders)
+	}
+	accruals, next, err := store.LoadFeeLedger(ctx, collector)
+	if err != nil || next != 19_000_001 || len(accruals) != 1 || accruals[0].Claimable().Int64() != 200 {
+		t.Errorf("LoadFeeLedger() = %+v, %d, %v", accruals, next, err)
+	}
+	if stats, _ := store.LoadGasStats(ctx); len(stats) != 1 || stats[0].MedianPerHop != 90000 {
+		t.Errorf("LoadGasStats() = %+v", stats)
+	}
+
+	// Deleting a key takes its usage and bookmarks with it
+	if err := store.DeleteKey(ctx, "k1"); err != nil {
+		t.Fatalf("DeleteKey() error = %v", err)
+	}
+	if key, _ := store.GetKey(ctx, "k1"); key != nil {
+		t.Errorf("GetKey() after delete = %+v", key)
+	}
+	if bookmark, _ := store.GetBookmark(ctx, "k1", "eth-usdc"); bookmark != nil {
+		t.Errorf("GetBookmark() after delete = %+v", bookmark)
+	}
+	if usage, _ := store.Usage(ctx, "k1", "2026-10-16"); usage != (entities.APIKeyUsage{}) {
+		t.Errorf("Usage() after delete = %+v", usage)
+	}
+}
@@ -0,0 +1,293 @@
+package subgraph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/httpclient"
+)
+
+// Client reads pool TVL and volume from Uniswap V2-style, Uniswap V3 and
+// Balancer V2 subgraphs, one GraphQL endpoint per venue
+type Client struct {
+	endpoints map[entities.DEXType]string
+	client    *http.Client
+}
+
+func NewClient(endpoints map[entities.DEXType]string) *Client {
+	return &Client{
+		endpoints: endpoints,
+		client:    httpclient.New(15 * time.Second),
+	}
+}
+
+// DEXes lists the venues with a configured subgraph in sorted order
+func (c *Client) DEXes() []entities.DEXType {
+	dexes := make([]entities.DEXType, 0, len(c.endpoints))
+	for dexType := range c.endpoints {
+		dexes = append(dexes, dexType)
+	}
+	sort.Slice(dexes, func(i, j int) bool { return dexes[i] < dexes[j] })
+	return dexes
+}
+
+// TopPools returns the first pools on dexType by TVL, with volume over the
+// last complete UTC day
+func (c *Client) TopPools(ctx context.Context, dexType entities.DEXType, first int) ([]entities.PoolStats, error) {
+	endpoint, ok := c.endpoints[dexType]
+	if !ok {
+		return nil, fmt.Errorf("no subgraph configured for %s", dexType)
+	}
+
+	// Day data is keyed by the UTC midnight it starts at
+	yesterday := time.Now().UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour).Unix()
+
+	switch dexType {
+	case entities.DEXUniswapV2, entities.DEXSushiswap:
+		return c.v2Pools(ctx, endpoint, dexType, first, yesterday)
+	case entities.DEXUniswapV3:
+		return c.v3Pools(ctx, endpoint, first, yesterday)
+	case entities.DEXBalancer:
+		return c.balancerPools(ctx, endpoint, first)
+	default:
+		return nil, fmt.Errorf("subgraph schema for %s is not supported", dexType)
+	}
+}
+
+type subgraphToken struct {
+	ID       string      `json:"id"`
+	Address  string      `json:"address"` // Balancer names the field differently
+	Symbol   string      `json:"symbol"`
+	Decimals json.Number `json:"decimals"`
+}
+
+func (t subgraphToken) token() entities.Token {
+	addr := t.ID
+	if t.Address != "" {
+		addr = t.Address
+	}
+	decimals, _ := strconv.ParseUint(t.Decimals.String(), 10, 8)
+	return entities.Token{Address: common.HexToAddress(addr), Symbol: t.Symbol, Decimals: uint8(decimals)}
+}
+
+const v2PairsQuery = `query($first: Int!) {
+  pairs(first: $first, orderBy: reserveUSD, orderDirection: desc) {
+    id
+    token0 { id symbol decimals }
+    token1 { id symbol decimals }
+    reserveUSD
+  }
+}`
+
+const v2DayDataQuery = `query($pairs: [Bytes!]!, $date: Int!) {
+  pairDayDatas(first: 1000, where: { pairAddress_in: $pairs, date: $date }) {
+    pairAddress
+    dailyVolumeUSD
+  }
+}`
+
+func (c *Client) v2Pools(ctx context.Context, endpoint string, dexType entities.DEXType, first int, day int64) ([]entities.PoolStats, error) {
+	var pairs struct {
+		Pairs []struct {
+			ID         string        `json:"id"`
+			Token0     subgraphToken `json:"token0"`
+			Token1     subgraphToken `json:"token1"`
+			ReserveUSD string        `json:"reserveUSD"`
+		} `json:"pairs"`
+	}
+	if err := c.query(ctx, endpoint, v2PairsQuery, map[string]interface{}{"first": first}, &pairs); err != nil {
+		return nil, fmt.Errorf("%s subgraph: %w", dexType, err)
+	}
+
+	ids := make([]string, 0, len(pairs.Pairs))
+	for _, p := range pairs.Pairs {
+		ids = append(ids, p.ID)
+	}
+	var dayData struct {
+		PairDayDatas []struct {
+			PairAddress    string `json:"pairAddress"`
+			DailyVolumeUSD string `json:"dailyVolumeUSD"`
+		} `json:"pairDayDatas"`
+	}
+	if err := c.query(ctx, endpoint, v2DayDataQuery, map[string]interface{}{"pairs": ids, "date": day}, &dayData); err != nil {
+		return nil, fmt.Errorf("%s subgraph: %w", dexType, err)
+	}
+	volumes := make(map[common.Address]*big.Int, len(dayData.PairDayDatas))
+	for _, d := range dayData.PairDayDatas {
+		volumes[common.HexToAddress(d.PairAddress)] = parseUSD(d.DailyVolumeUSD)
+	}
+
+	stats := make([]entities.PoolStats, 0, len(pairs.Pairs))
+	for _, p := range pairs.Pairs {
+		addr := common.HexToAddress(p.ID)
+		stats = append(stats, entities.PoolStats{
+			DEX:          dexType,
+			Address:      addr,
+			Tokens:       []entities.Token{p.Token0.token(), p.Token1.token()},
+			TVLUSD:       parseUSD(p.ReserveUSD),
+			Volume24hUSD: volumeOrZero(volumes[addr]),
+		})
+	}
+	return stats, nil
+}
+
+const v3PoolsQuery = `query($first: Int!, $date: Int!) {
+  pools(first: $first, orderBy: totalValueLockedUSD, orderDirection: desc) {
+    id
+    feeTier
+    token0 { id symbol decimals }
+    token1 { id symbol decimals }
+    totalValueLockedUSD
+    poolDayData(where: { date: $date }) { volumeUSD }
+  }
+}`
+
+func (c *Client) v3Pools(ctx context.Context, endpoint string, first int, day int64) ([]entities.PoolStats, error) {
+	var result struct {
+		Pools []struct {
+			ID                  string        `json:"id"`
+			FeeTier             string        `json:"feeTier"`
+			Token0              subgraphToken `json:"token0"`
+			Token1              subgraphToken `json:"token1"`
+			TotalValueLockedUSD string        `json:"totalValueLockedUSD"`
+			PoolDayData         []struct {
+				VolumeUSD string `json:"volumeUSD"`
+			} `json:"poolDayData"`
+		} `json:"pools"`
+	}
+	if err := c.query(ctx, endpoint, v3PoolsQuery, map[string]interface{}{"first": first, "date": day}, &result); err != nil {
+		return nil, fmt.Errorf("uniswap_v3 subgraph: %w", err)
+	}
+
+	stats := make([]entities.PoolStats, 0, len(result.Pools))
+	for _, p := range result.Pools {
+		feeTier, _ := strconv.ParseUint(p.FeeTier, 10, 64)
+		volume := big.NewInt(0)
+		if len(p.PoolDayData) > 0 {
+			volume = parseUSD(p.PoolDayData[0].VolumeUSD)
+		}
+		stats = append(stats, entities.PoolStats{
+			DEX:          entities.DEXUniswapV3,
+			Address:      common.HexToAddress(p.ID),
+			Tokens:       []entities.Token{p.Token0.token(), p.Token1.token()},
+			FeeTier:      feeTier,
+			TVLUSD:       parseUSD(p.TotalValueLockedUSD),
+			Volume24hUSD: volume,
+		})
+	}
+	return stats, nil
+}
+
+// Balancer snapshots hold cumulative volume once a day, so the last two
+// differ by a day's volume
+const balancerPoolsQuery = `query($first: Int!) {
+  pools(first: $first, orderBy: totalLiquidity, orderDirection: desc) {
+    address
+    totalLiquidity
+    tokens { address symbol decimals }
+    snapshots(first: 2, orderBy: timestamp, orderDirection: desc) { swapVolume }
+  }
+}`
+
+func (c *Client) balancerPools(ctx context.Context, endpoint string, first int) ([]entities.PoolStats, error) {
+	var result struct {
+		Pools []struct {
+			Address        string          `json:"address"`
+			TotalLiquidity string          `json:"totalLiquidity"`
+			Tokens         []subgraphToken `json:"tokens"`
+			Snapshots      []struct {
+				SwapVolume string `json:"swapVolume"`
+			} `json:"snapshots"`
+		} `json:"pools"`
+	}
+	if err := c.query(ctx, endpoint, balancerPoolsQuery, map[string]interface{}{"first": first}, &result); err != nil {
+		return nil, fmt.Errorf("balancer subgraph: %w", err)
+	}
+
+	stats := make([]entities.PoolStats, 0, len(result.Pools))
+	for _, p := range result.Pools {
+		tokens := make([]entities.Token, 0, len(p.Tokens))
+		for _, t := range p.Tokens {
+			tokens = append(tokens, t.token())
+		}
+		volume := big.NewInt(0)
+		if len(p.Snapshots) == 2 {
+			volume = new(big.Int).Sub(parseUSD(p.Snapshots[0].SwapVolume), parseUSD(p.Snapshots[1].SwapVolume))
+		}
+		stats = append(stats, entities.PoolStats{
+			DEX:          entities.DEXBalancer,
+			Address:      common.HexToAddress(p.Address),
+			Tokens:       tokens,
+			TVLUSD:       parseUSD(p.TotalLiquidity),
+			Volume24hUSD: volume,
+		})
+	}
+	return stats, nil
+}
+
+// query posts a GraphQL request and decodes its data into out
+func (c *Client) query(ctx context.Context, endpoint, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Errors) > 0 {
+		return fmt.Errorf("%s", envelope.Errors[0].Message)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// parseUSD converts a subgraph BigDecimal string to 18 decimals, treating
+// unparseable values as zero
+func parseUSD(s string) *big.Int {
+	value, ok := new(big.Float).SetPrec(256).SetString(s)
+	if !ok {
+		return big.NewInt(0)
+	}
+	scaled, _ := value.Mul(value, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))).Int(nil)
+	return scaled
+}
+
+func volumeOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return v
+}
@@ -0,0 +1,90 @@
+package subgraph
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func usd(units int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(units), new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+}
+
+func TestClientTopPools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		switch {
+		case strings.Contains(req.Query, "pairDayDatas"):
+			w.Write([]byte(`{"data":{"pairDayDatas":[{"pairAddress":"0x00000000000000000000000000000000000000a1","dailyVolumeUSD":"2500.5"}]}}`))
+		case strings.Contains(req.Query, "pairs("):
+			w.Write([]byte(`{"data":{"pairs":[
+				{"id":"0x00000000000000000000000000000000000000a1","token0":{"id":"0x01","symbol":"A","decimals":"18"},"token1":{"id":"0x02","symbol":"B","decimals":"6"},"reserveUSD":"1000000.25"},
+				{"id":"0x00000000000000000000000000000000000000a2","token0":{"id":"0x01","symbol":"A","decimals":"18"},"token1":{"id":"0x03","symbol":"C","decimals":"18"},"reserveUSD":"5000"}]}}`))
+		case strings.Contains(req.Query, "totalValueLockedUSD"):
+			w.Write([]byte(`{"data":{"pools":[{"id":"0x00000000000000000000000000000000000000b1","feeTier":"500",
+				"token0":{"id":"0x01","symbol":"A","decimals":"18"},"token1":{"id":"0x02","symbol":"B","decimals":"6"},
+				"totalValueLockedUSD":"300","poolDayData":[{"volumeUSD":"42"}]}]}}`))
+		case strings.Contains(req.Query, "totalLiquidity"):
+			w.Write([]byte(`{"data":{"pools":[{"address":"0x00000000000000000000000000000000000000c1","totalLiquidity":"900",
+				"tokens":[{"address":"0x01","symbol":"A","decimals":18},{"address":"0x02","symbol":"B","decimals":6},{"address":"0x03","symbol":"C","decimals":18}],
+				"snapshots":[{"swapVolume":"1500"},{"swapVolume":"1000"}]}]}}`))
+		default:
+			w.Write([]byte(`{"errors":[{"message":"unknown query"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(map[entities.DEXType]string{
+		entities.DEXUniswapV2: server.URL,
+		entities.DEXUniswapV3: server.URL,
+		entities.DEXBalancer:  server.URL,
+		entities.DEXCurve:     server.URL,
+	})
+
+	v2, err := client.TopPools(context.Background(), entities.DEXUniswapV2, 2)
+	if err != nil {
+		t.Fatalf("TopPools(v2) error = %v", err)
+	}
+	if len(v2) != 2 || v2[0].TVLUSD.Cmp(new(big.Int).Add(usd(1000000), big.NewInt(25e16))) != 0 {
+		t.Fatalf("v2 pools = %+v", v2)
+	}
+	if v2[0].Volume24hUSD.Cmp(new(big.Int).Add(usd(2500), big.NewInt(5e17))) != 0 || v2[1].Volume24hUSD.Sign() != 0 {
+		t.Errorf("v2 volumes = %s, %s", v2[0].Volume24hUSD, v2[1].Volume24hUSD)
+	}
+	if v2[0].Tokens[1].Decimals != 6 || v2[0].Tokens[1].Address != common.HexToAddress("0x02") {
+		t.Errorf("v2 token1 = %+v", v2[0].Tokens[1])
+	}
+
+	v3, err := client.TopPools(context.Background(), entities.DEXUniswapV3, 1)
+	if err != nil {
+		t.Fatalf("TopPools(v3) error = %v", err)
+	}
+	if v3[0].FeeTier != 500 || v3[0].TVLUSD.Cmp(usd(300)) != 0 || v3[0].Volume24hUSD.Cmp(usd(42)) != 0 {
+		t.Errorf("v3 pool = %+v", v3[0])
+	}
+
+	balancer, err := client.TopPools(context.Background(), entities.DEXBalancer, 1)
+	if err != nil {
+		t.Fatalf("TopPools(balancer) error = %v", err)
+	}
+	if len(balancer[0].Tokens) != 3 || balancer[0].Volume24hUSD.Cmp(usd(500)) != 0 {
+		t.Errorf("balancer pool = %+v", balancer[0])
+	}
+
+	if _, err := client.TopPools(context.Background(), entities.DEXCurve, 1); err == nil {
+		t.Error("TopPools(curve) error = nil, want unsupported schema")
+	}
+}
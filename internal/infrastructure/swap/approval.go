@@ -0,0 +1,67 @@
+package swap
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// approve(address,uint256)
+var approveSelector = common.Hex2Bytes("095ea7b3")
+
+// BuildApprovals encodes the approvals owner sends so spender can pull
+// amount of token, following SafeERC20's forceApprove: nothing when the
+// allowance already covers amount, and an approve(spender, 0) first when
+// the token rejects replacing a non-zero allowance. Tokens that return no
+// bool need no special calldata; callers only have to accept an empty
+// return when simulating.
+func (b *Builder) BuildApprovals(owner, token, spender common.Address, allowance, amount *big.Int, quirks entities.TokenQuirks) []*entities.SwapTransaction {
+	if allowance != nil && allowance.Cmp(amount) >= 0 {
+		return nil
+	}
+
+	var steps []*entities.SwapTransaction
+	if quirks.ApproveResetRequired && allowance != nil && allowance.Sign() > 0 {
+		steps = append(steps, buildApprove(owner, token, spender, new(big.Int)))
+	}
+	return append(steps, buildApprove(owner, token, spender, amount))
+}
+
+// buildApprove encodes approve(spender, amount) on token
+func buildApprove(owner, token, spender common.Address, amount *big.Int) *entities.SwapTransaction {
+	data := make([]byte, 4+32*2)
+	copy(data[0:4], approveSelector)
+	putAddress(data[4:36], spender)
+	putUint(data[36:68], amount)
+
+	return &entities.SwapTransaction{
+		From:  owner,
+		To:    token,
+		Data:  data,
+		Value: big.NewInt(0),
+	}
+}
+
+// approve(address token, address spender, uint160 amount, uint48 expiration)
+var permit2ApproveSelector = crypto.Keccak256([]byte("approve(address,address,uint160,uint48)"))[:4]
+
+// BuildPermit2Approval encodes the Permit2 approval letting spender pull up
+// to amount of owner's token until expiration, a Unix time
+func (b *Builder) BuildPermit2Approval(owner, permit2, token, spender common.Address, amount *big.Int, expiration uint64) *entities.SwapTransaction {
+	data := make([]byte, 4+32*4)
+	copy(data[0:4], permit2ApproveSelector)
+	putAddress(data[4:36], token)
+	putAddress(data[36:68], spender)
+	putUint(data[68:100], amount)
+	putUint(data[100:132], new(big.Int).SetUint64(expiration))
+
+	return &entities.SwapTransaction{
+		From:  owner,
+		To:    permit2,
+		Data:  data,
+		Value: big.NewInt(0),
+	}
+}
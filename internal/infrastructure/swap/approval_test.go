@@ -0,0 +1,59 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const erc20ABI = `[
+	{"name":"approve","type":"function","inputs":[
+		{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}]}
+]`
+
+func TestBuildApprovals(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := big.NewInt(1e18)
+	reset := entities.TokenQuirks{ApproveResetRequired: true}
+
+	tests := []struct {
+		name      string
+		allowance *big.Int
+		quirks    entities.TokenQuirks
+		want      []*big.Int
+	}{
+		{"no allowance", big.NewInt(0), reset, []*big.Int{amount}},
+		{"covered", amount, reset, nil},
+		{"raise", big.NewInt(5), entities.TokenQuirks{}, []*big.Int{amount}},
+		{"reset then raise", big.NewInt(5), reset, []*big.Int{big.NewInt(0), amount}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := NewBuilder().BuildApprovals(testRecipient, entities.USDT.Address, SwapRouter02Address, tt.allowance, amount, tt.quirks)
+			if len(steps) != len(tt.want) {
+				t.Fatalf("got %d steps, want %d", len(steps), len(tt.want))
+			}
+			for i, step := range steps {
+				want, err := parsed.Pack("approve", SwapRouter02Address, tt.want[i])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(step.Data, want) {
+					t.Errorf("step %d data = %x, want %x", i, step.Data, want)
+				}
+				if step.To != entities.USDT.Address || step.From != testRecipient {
+					t.Errorf("step %d sends %s -> %s", i, step.From.Hex(), step.To.Hex())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// BalancerVaultAddress is the Balancer V2 Vault, which swaps for every
+// Balancer pool (Ethereum mainnet)
+var BalancerVaultAddress = common.HexToAddress("0xBA12222222228d8Ba445958a75a0704d566BF2C8")
+
+// balancerGivenIn is batchSwap's SwapKind for exact-input swaps
+const balancerGivenIn = 0
+
+// batchSwap(uint8 kind, (bytes32 poolId, uint256 assetInIndex,
+// uint256 assetOutIndex, uint256 amount, bytes userData)[] swaps,
+// address[] assets, (address sender, bool fromInternalBalance,
+// address recipient, bool toInternalBalance) funds, int256[] limits,
+// uint256 deadline)
+var (
+	balancerBatchSwapSelector = crypto.Keccak256([]byte("batchSwap(uint8,(bytes32,uint256,uint256,uint256,bytes)[],address[],(address,bool,address,bool),int256[],uint256)"))[:4]
+	balancerBatchSwapArgs     = func() abi.Arguments {
+		stepType, err := abi.NewType("tuple[]", "", []abi.ArgumentMarshaling{
+			{Name: "poolId", Type: "bytes32"},
+			{Name: "assetInIndex", Type: "uint256"},
+			{Name: "assetOutIndex", Type: "uint256"},
+			{Name: "amount", Type: "uint256"},
+			{Name: "userData", Type: "bytes"},
+		})
+		if err != nil {
+			panic(err)
+		}
+		fundsType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+			{Name: "sender", Type: "address"},
+			{Name: "fromInternalBalance", Type: "bool"},
+			{Name: "recipient", Type: "address"},
+			{Name: "toInternalBalance", Type: "bool"},
+		})
+		if err != nil {
+			panic(err)
+		}
+		args := newArgs("uint8", "address[]", "int256[]", "uint256")
+		return abi.Arguments{args[0], {Type: stepType}, args[1], {Type: fundsType}, args[2], args[3]}
+	}()
+)
+
+// balancerStep mirrors batchSwap's BatchSwapStep for abi packing
+type balancerStep struct {
+	PoolId        [32]byte
+	AssetInIndex  *big.Int
+	AssetOutIndex *big.Int
+	Amount        *big.Int
+	UserData      []byte
+}
+
+// balancerFunds mirrors batchSwap's FundManagement for abi packing
+type balancerFunds struct {
+	Sender              common.Address
+	FromInternalBalance bool
+	Recipient           common.Address
+	ToInternalBalance   bool
+}
+
+// encodeBalancerSwap encodes the route as one Vault batchSwap. A hop into
+// or out of a boosted pool adds a step through the token's linear pool;
+// every step after the first swaps the whole output of the one before.
+// The limits cap the input at route.AmountIn and hold the output to
+// minAmountOut. The Vault pulls the input from its caller, so sender must
+// send the transaction; native legs are ETH, which the Vault wraps.
+func encodeBalancerSwap(route *entities.Route, minAmountOut *big.Int, sender, recipient common.Address, deadline *big.Int, nativeIn, nativeOut bool) ([]byte, error) {
+	var steps []balancerStep
+	var assets []common.Address
+	index := func(token common.Address) *big.Int {
+		for i, asset := range assets {
+			if asset == token {
+				return big.NewInt(int64(i))
+			}
+		}
+		assets = append(assets, token)
+		return big.NewInt(int64(len(assets) - 1))
+	}
+	step := func(poolID common.Hash, tokenIn, tokenOut common.Address) {
+		// An amount of zero swaps what the previous step paid out
+		amount := big.NewInt(0)
+		if len(steps) == 0 {
+			amount = route.AmountIn
+		}
+		steps = append(steps, balancerStep{
+			PoolId:        poolID,
+			AssetInIndex:  index(tokenIn),
+			AssetOutIndex: index(tokenOut),
+			Amount:        amount,
+			UserData:      []byte{},
+		})
+	}
+
+	for i, hop := range route.Hops {
+		swap := hop.Pair.BalancerSwap
+		if swap == nil {
+			return nil, fmt.Errorf("hop %d: Balancer pool %s has no pool ID", i, hop.Pair.Address.Hex())
+		}
+		linearIn, linearOut := swap.Linear0, swap.Linear1
+		if hop.TokenIn != hop.Pair.Token0.Address {
+			linearIn, linearOut = linearOut, linearIn
+		}
+
+		tokenIn, tokenOut := hop.TokenIn, hop.TokenOut
+		if linearIn != nil {
+			step(linearIn.PoolID, tokenIn, linearIn.BPT)
+			tokenIn = linearIn.BPT
+		}
+		if linearOut != nil {
+			tokenOut = linearOut.BPT
+		}
+		step(swap.PoolID, tokenIn, tokenOut)
+		if linearOut != nil {
+			step(linearOut.PoolID, linearOut.BPT, hop.TokenOut)
+		}
+	}
+
+	// Positive limits are the most the Vault may take, negative ones the
+	// least it must pay
+	limits := make([]*big.Int, len(assets))
+	for i := range limits {
+		limits[i] = big.NewInt(0)
+	}
+	in, out := index(route.Hops[0].TokenIn), index(route.Hops[len(route.Hops)-1].TokenOut)
+	limits[in.Int64()] = new(big.Int).Set(route.AmountIn)
+	limits[out.Int64()] = new(big.Int).Neg(minAmountOut)
+	// The zero address is ETH to the Vault
+	if nativeIn {
+		assets[in.Int64()] = common.Address{}
+	}
+	if nativeOut {
+		assets[out.Int64()] = common.Address{}
+	}
+
+	args, err := balancerBatchSwapArgs.Pack(
+		uint8(balancerGivenIn), steps, assets,
+		balancerFunds{Sender: sender, Recipient: recipient},
+		limits, deadline,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batchSwap: %w", err)
+	}
+	return append(append([]byte{}, balancerBatchSwapSelector...), args...), nil
+}
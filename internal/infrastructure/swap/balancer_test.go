@@ -0,0 +1,132 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const vaultABI = `[
+	{"name":"batchSwap","type":"function","inputs":[
+		{"name":"kind","type":"uint8"},
+		{"name":"swaps","type":"tuple[]","components":[
+			{"name":"poolId","type":"bytes32"},{"name":"assetInIndex","type":"uint256"},
+			{"name":"assetOutIndex","type":"uint256"},{"name":"amount","type":"uint256"},
+			{"name":"userData","type":"bytes"}]},
+		{"name":"assets","type":"address[]"},
+		{"name":"funds","type":"tuple","components":[
+			{"name":"sender","type":"address"},{"name":"fromInternalBalance","type":"bool"},
+			{"name":"recipient","type":"address"},{"name":"toInternalBalance","type":"bool"}]},
+		{"name":"limits","type":"int256[]"},
+		{"name":"deadline","type":"uint256"}]}
+]`
+
+func TestBuildBalancer(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(vaultABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := parsed.Methods["batchSwap"]
+	if !bytes.Equal(method.ID, common.Hex2Bytes("945bcec9")) {
+		t.Fatalf("batchSwap selector = %x, want 945bcec9", method.ID)
+	}
+
+	weighted := common.HexToHash("0x01")
+	boosted := common.HexToHash("0x02")
+	linearDAI := &entities.BalancerLinearStep{PoolID: common.HexToHash("0x03"), BPT: common.HexToAddress("0xb1")}
+	linearUSDC := &entities.BalancerLinearStep{PoolID: common.HexToHash("0x04"), BPT: common.HexToAddress("0xb2")}
+	// WETH -> DAI on a weighted pool, then DAI -> USDC on bb-a-USD through
+	// both tokens' linear pools
+	route := &entities.Route{
+		AmountIn: big.NewInt(1e18),
+		Hops: []entities.Hop{
+			{
+				Pair: entities.Pair{
+					DEX: entities.DEXBalancer, Token0: entities.DAI, Token1: entities.WETH,
+					BalancerSwap: &entities.BalancerSwap{PoolID: weighted},
+				},
+				TokenIn: entities.WETH.Address, TokenOut: entities.DAI.Address,
+			},
+			{
+				Pair: entities.Pair{
+					DEX: entities.DEXBalancer, Token0: entities.DAI, Token1: entities.USDC,
+					BalancerSwap: &entities.BalancerSwap{PoolID: boosted, Linear0: linearDAI, Linear1: linearUSDC},
+				},
+				TokenIn: entities.DAI.Address, TokenOut: entities.USDC.Address,
+			},
+		},
+	}
+
+	tx, err := NewBuilder().BuildNative(route, big.NewInt(1800e6), testRecipient, testDeadline, true, false)
+	if err != nil {
+		t.Fatalf("BuildNative() error = %v", err)
+	}
+	if tx.To != BalancerVaultAddress || tx.Value.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("To = %s, value = %s, want the Vault paid 1 ETH", tx.To.Hex(), tx.Value)
+	}
+	if !bytes.Equal(tx.Data[:4], method.ID) {
+		t.Fatalf("selector = %x, want batchSwap", tx.Data[:4])
+	}
+	args, err := method.Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+
+	assets := args[2].([]common.Address)
+	wantAssets := []common.Address{{}, entities.DAI.Address, linearDAI.BPT, linearUSDC.BPT, entities.USDC.Address}
+	if !reflect.DeepEqual(assets, wantAssets) {
+		t.Fatalf("assets = %v, want ETH, DAI, bb-a-DAI, bb-a-USDC, USDC", assets)
+	}
+
+	steps := reflect.ValueOf(args[1])
+	wantSteps := []struct {
+		pool    common.Hash
+		in, out int64
+	}{
+		{weighted, 0, 1},
+		{linearDAI.PoolID, 1, 2},
+		{boosted, 2, 3},
+		{linearUSDC.PoolID, 3, 4},
+	}
+	if steps.Len() != len(wantSteps) {
+		t.Fatalf("%d steps, want %d", steps.Len(), len(wantSteps))
+	}
+	for i, want := range wantSteps {
+		step := steps.Index(i)
+		pool := common.Hash(step.FieldByName("PoolId").Interface().([32]byte))
+		in := step.FieldByName("AssetInIndex").Interface().(*big.Int).Int64()
+		out := step.FieldByName("AssetOutIndex").Interface().(*big.Int).Int64()
+		amount := step.FieldByName("Amount").Interface().(*big.Int)
+		if pool != want.pool || in != want.in || out != want.out {
+			t.Errorf("step %d = %s %d -> %d, want %s %d -> %d", i, pool.Hex(), in, out, want.pool.Hex(), want.in, want.out)
+		}
+		// Later steps swap the whole output of the one before
+		if (i == 0 && amount.Cmp(big.NewInt(1e18)) != 0) || (i > 0 && amount.Sign() != 0) {
+			t.Errorf("step %d amount = %s", i, amount)
+		}
+	}
+
+	funds := reflect.ValueOf(args[3])
+	if funds.FieldByName("Sender").Interface().(common.Address) != testRecipient || funds.FieldByName("Recipient").Interface().(common.Address) != testRecipient {
+		t.Errorf("funds = %+v, want the recipient both ways", args[3])
+	}
+	limits := args[4].([]*big.Int)
+	if limits[0].Cmp(big.NewInt(1e18)) != 0 || limits[4].Cmp(big.NewInt(-1800e6)) != 0 || limits[2].Sign() != 0 {
+		t.Errorf("limits = %v, want 1 ETH in and at least 1800 USDC out", limits)
+	}
+	if args[5].(*big.Int).Int64() != testDeadline.Unix() {
+		t.Errorf("deadline = %v, want %d", args[5], testDeadline.Unix())
+	}
+
+	route.Hops[0].Pair.BalancerSwap = nil
+	if _, err := NewBuilder().Build(route, nil, testRecipient, testDeadline); err == nil {
+		t.Error("Build() accepted a Balancer pair without its pool ID")
+	}
+}
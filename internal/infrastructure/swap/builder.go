@@ -0,0 +1,285 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Router contract addresses (Ethereum mainnet)
+var (
+	UniswapV2RouterAddress = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	SushiswapRouterAddress = common.HexToAddress("0xd9e1cE17f2641f24aE83637ab66a2cca9C378B9F")
+	SwapRouter02Address    = common.HexToAddress("0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45")
+)
+
+// Routers are the spenders of the swaps Build encodes, one per venue family
+func Routers() []entities.Spender {
+	return []entities.Spender{
+		{
+			Name:    "uniswap_v2_router",
+			Address: UniswapV2RouterAddress,
+			DEXes:   []entities.DEXType{entities.DEXUniswapV2},
+			Purpose: "Uniswap V2 swaps",
+		},
+		{
+			Name:    "sushiswap_router",
+			Address: SushiswapRouterAddress,
+			DEXes:   []entities.DEXType{entities.DEXSushiswap},
+			Purpose: "Sushiswap swaps",
+		},
+		{
+			Name:    "swap_router_02",
+			Address: SwapRouter02Address,
+			DEXes:   []entities.DEXType{entities.DEXUniswapV3},
+			Purpose: "Uniswap V3 swaps",
+		},
+		{
+			Name:    "balancer_vault",
+			Address: BalancerVaultAddress,
+			DEXes:   []entities.DEXType{entities.DEXBalancer},
+			Purpose: "Balancer swaps",
+		},
+	}
+}
+
+var (
+	// swapExactTokensForTokens(uint256,uint256,address[],address,uint256)
+	swapExactTokensForTokensSelector = common.Hex2Bytes("38ed1739")
+	// swapExactETHForTokens(uint256,address[],address,uint256)
+	swapExactETHForTokensSelector = common.Hex2Bytes("7ff36ab5")
+	// swapExactTokensForETH(uint256,uint256,address[],address,uint256)
+	swapExactTokensForETHSelector = common.Hex2Bytes("18cbafe5")
+	// exactInputSingle((address,address,uint24,address,uint256,uint256,uint160))
+	exactInputSingleSelector = common.Hex2Bytes("04e45aaf")
+	// exactInput((bytes,address,uint256,uint256))
+	exactInputSelector = common.Hex2Bytes("b858183f")
+	// multicall(uint256,bytes[])
+	multicallDeadlineSelector = common.Hex2Bytes("5ae401dc")
+	// unwrapWETH9(uint256,address)
+	unwrapWETH9Selector = common.Hex2Bytes("49404b7c")
+)
+
+// swapRouterSelf is the recipient SwapRouter02 reads as itself, used to keep
+// WETH in the router until it is unwrapped
+var swapRouterSelf = common.BigToAddress(big.NewInt(2))
+
+// DefaultDeadline is how long a built swap stays valid when no deadline is
+// given
+const DefaultDeadline = 20 * time.Minute
+
+// Builder encodes router calldata for routes produced by RouterService
+type Builder struct{}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Build encodes a transaction that swaps route.AmountIn for at least
+// minAmountOut and sends the proceeds to recipient. The router reverts the
+// swap after deadline. All hops must be on the same venue family so the
+// route executes through one router call. Curve and Balancer swaps act for
+// their caller, so recipient must also send them; Curve pools have no
+// deadline.
+func (b *Builder) Build(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time) (*entities.SwapTransaction, error) {
+	return b.BuildNative(route, minAmountOut, recipient, deadline, false, false)
+}
+
+// BuildNative is Build for routes that start or end in the chain's gas
+// token. The route trades the wrapper: with nativeIn the sender pays
+// route.AmountIn as the transaction's value and the router wraps it, with
+// nativeOut the router unwraps the output before paying recipient.
+func (b *Builder) BuildNative(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time, nativeIn, nativeOut bool) (*entities.SwapTransaction, error) {
+	if route == nil || len(route.Hops) == 0 {
+		return nil, fmt.Errorf("route has no hops")
+	}
+	if nativeIn && nativeOut {
+		return nil, fmt.Errorf("a route can't both start and end in the gas token")
+	}
+	if minAmountOut == nil {
+		minAmountOut = big.NewInt(0)
+	}
+	if deadline.IsZero() {
+		deadline = time.Now().Add(DefaultDeadline)
+	}
+	deadlineUnix := big.NewInt(deadline.Unix())
+
+	dexType := route.Hops[0].Pair.DEX
+	for _, hop := range route.Hops[1:] {
+		if hop.Pair.DEX != dexType {
+			return nil, fmt.Errorf("mixed-venue routes are not supported by the swap builder")
+		}
+	}
+
+	var to common.Address
+	var data []byte
+	var err error
+	switch dexType {
+	case entities.DEXUniswapV2:
+		to, data = UniswapV2RouterAddress, b.encodeV2Swap(route, minAmountOut, recipient, deadlineUnix, nativeIn, nativeOut)
+	case entities.DEXSushiswap:
+		to, data = SushiswapRouterAddress, b.encodeV2Swap(route, minAmountOut, recipient, deadlineUnix, nativeIn, nativeOut)
+	case entities.DEXUniswapV3:
+		// SwapRouter02's swap structs have no deadline; its multicall checks one.
+		// It wraps value it holds when paying WETH, so only unwrapping needs a call.
+		if nativeOut {
+			to, data = SwapRouter02Address, encodeMulticall(deadlineUnix,
+				b.encodeV3Swap(route, minAmountOut, swapRouterSelf),
+				encodeUnwrapWETH9(minAmountOut, recipient))
+		} else {
+			to, data = SwapRouter02Address, encodeMulticall(deadlineUnix, b.encodeV3Swap(route, minAmountOut, recipient))
+		}
+	case entities.DEXCurve:
+		// Curve pools are called directly: they take no deadline, and pay
+		// their caller
+		to = route.Hops[0].Pair.Address
+		data, err = encodeCurveSwap(route, minAmountOut, nativeIn, nativeOut)
+	case entities.DEXBalancer:
+		to = BalancerVaultAddress
+		data, err = encodeBalancerSwap(route, minAmountOut, recipient, recipient, deadlineUnix, nativeIn, nativeOut)
+	default:
+		return nil, fmt.Errorf("swap building is not supported for %s", dexType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value := big.NewInt(0)
+	if nativeIn {
+		value = new(big.Int).Set(route.AmountIn)
+	}
+	return &entities.SwapTransaction{
+		From:  recipient,
+		To:    to,
+		Data:  data,
+		Value: value,
+	}, nil
+}
+
+// encodeV2Swap encodes swapExactTokensForTokens(amountIn, amountOutMin,
+// path, to, deadline), or its ETH variants for native legs.
+// swapExactETHForTokens takes amountIn as the call's value.
+func (b *Builder) encodeV2Swap(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline *big.Int, nativeIn, nativeOut bool) []byte {
+	path := make([]common.Address, 0, len(route.Hops)+1)
+	path = append(path, route.Hops[0].TokenIn)
+	for _, hop := range route.Hops {
+		path = append(path, hop.TokenOut)
+	}
+
+	selector, amounts := swapExactTokensForTokensSelector, []*big.Int{route.AmountIn, minAmountOut}
+	switch {
+	case nativeIn:
+		selector, amounts = swapExactETHForTokensSelector, []*big.Int{minAmountOut}
+	case nativeOut:
+		selector = swapExactTokensForETHSelector
+	}
+
+	// Amounts, path offset, to and deadline, then the path array (length + elements)
+	heads := len(amounts) + 3
+	data := make([]byte, 4+32*heads+32*(1+len(path)))
+	copy(data[0:4], selector)
+	for i, amount := range amounts {
+		putUint(data[4+32*i:], amount)
+	}
+	at := 4 + 32*len(amounts)
+	putUint(data[at:at+32], big.NewInt(int64(32*heads))) // offset of path
+	putAddress(data[at+32:at+64], recipient)
+	putUint(data[at+64:at+96], deadline)
+	putUint(data[at+96:at+128], big.NewInt(int64(len(path))))
+	for i, addr := range path {
+		start := at + 128 + 32*i
+		putAddress(data[start:start+32], addr)
+	}
+
+	return data
+}
+
+// encodeV3Swap encodes exactInputSingle for one hop and exactInput otherwise
+func (b *Builder) encodeV3Swap(route *entities.Route, minAmountOut *big.Int, recipient common.Address) []byte {
+	if len(route.Hops) == 1 {
+		hop := route.Hops[0]
+		data := make([]byte, 4+32*7)
+		copy(data[0:4], exactInputSingleSelector)
+		putAddress(data[4:36], hop.TokenIn)
+		putAddress(data[36:68], hop.TokenOut)
+		putUint(data[68:100], new(big.Int).SetUint64(hop.Pair.Fee))
+		putAddress(data[100:132], recipient)
+		putUint(data[132:164], route.AmountIn)
+		putUint(data[164:196], minAmountOut)
+		// sqrtPriceLimitX96 left as 0 (no limit)
+		return data
+	}
+
+	path := encodeV3Path(route.Hops)
+	paddedLen := (len(path) + 31) / 32 * 32
+
+	// selector, tuple offset, 4 tuple head slots, bytes length, bytes data
+	data := make([]byte, 4+32+32*4+32+paddedLen)
+	copy(data[0:4], exactInputSelector)
+	putUint(data[4:36], big.NewInt(32)) // offset of the tuple
+	putUint(data[36:68], big.NewInt(32*4))
+	putAddress(data[68:100], recipient)
+	putUint(data[100:132], route.AmountIn)
+	putUint(data[132:164], minAmountOut)
+	putUint(data[164:196], big.NewInt(int64(len(path))))
+	copy(data[196:], path)
+
+	return data
+}
+
+// encodeMulticall wraps calls in multicall(deadline, calls)
+func encodeMulticall(deadline *big.Int, calls ...[]byte) []byte {
+	// selector, deadline, array offset, array length, element offsets, then
+	// each element's length and padded data
+	data := make([]byte, 4+32*(3+len(calls)))
+	copy(data[0:4], multicallDeadlineSelector)
+	putUint(data[4:36], deadline)
+	putUint(data[36:68], big.NewInt(64)) // offset of the array
+	putUint(data[68:100], big.NewInt(int64(len(calls))))
+
+	offset := 32 * len(calls)
+	for i, call := range calls {
+		putUint(data[100+32*i:], big.NewInt(int64(offset)))
+		element := make([]byte, 32+(len(call)+31)/32*32)
+		putUint(element[0:32], big.NewInt(int64(len(call))))
+		copy(element[32:], call)
+		data = append(data, element...)
+		offset += len(element)
+	}
+
+	return data
+}
+
+// encodeUnwrapWETH9 encodes unwrapWETH9(amountMinimum, recipient), which
+// pays the router's WETH to recipient as ETH
+func encodeUnwrapWETH9(amountMinimum *big.Int, recipient common.Address) []byte {
+	data := make([]byte, 4+32*2)
+	copy(data[0:4], unwrapWETH9Selector)
+	putUint(data[4:36], amountMinimum)
+	putAddress(data[36:68], recipient)
+	return data
+}
+
+// encodeV3Path packs tokenIn, fee (uint24), tokenOut, fee, ... tokenOut
+func encodeV3Path(hops []entities.Hop) []byte {
+	path := make([]byte, 0, 20+23*len(hops))
+	path = append(path, hops[0].TokenIn.Bytes()...)
+	for _, hop := range hops {
+		fee := hop.Pair.Fee
+		path = append(path, byte(fee>>16), byte(fee>>8), byte(fee))
+		path = append(path, hop.TokenOut.Bytes()...)
+	}
+	return path
+}
+
+func putUint(slot []byte, v *big.Int) {
+	v.FillBytes(slot[:32])
+}
+
+func putAddress(slot []byte, addr common.Address) {
+	copy(slot[12:32], addr.Bytes())
+}
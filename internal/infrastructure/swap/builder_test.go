@@ -0,0 +1,239 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const routerABI = `[
+	{"name":"swapExactTokensForTokens","type":"function","inputs":[
+		{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},
+		{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}]},
+	{"name":"exactInput","type":"function","inputs":[
+		{"name":"params","type":"tuple","components":[
+			{"name":"path","type":"bytes"},{"name":"recipient","type":"address"},
+			{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"}]}]},
+	{"name":"multicall","type":"function","inputs":[
+		{"name":"deadline","type":"uint256"},{"name":"data","type":"bytes[]"}]},
+	{"name":"swapExactETHForTokens","type":"function","inputs":[
+		{"name":"amountOutMin","type":"uint256"},{"name":"path","type":"address[]"},
+		{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}]},
+	{"name":"swapExactTokensForETH","type":"function","inputs":[
+		{"name":"amountIn","type":"uint256"},{"name":"amountOutMin","type":"uint256"},
+		{"name":"path","type":"address[]"},{"name":"to","type":"address"},{"name":"deadline","type":"uint256"}]},
+	{"name":"unwrapWETH9","type":"function","inputs":[
+		{"name":"amountMinimum","type":"uint256"},{"name":"recipient","type":"address"}]}
+]`
+
+var (
+	testRecipient = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	testMid       = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	testDeadline  = time.Unix(1700000000, 0)
+)
+
+func testRoute(dex entities.DEXType, fee uint64, hops int) *entities.Route {
+	tokens := []common.Address{entities.WETH.Address, testMid, entities.USDC.Address}
+	route := &entities.Route{AmountIn: big.NewInt(1e18)}
+	for i := 0; i < hops; i++ {
+		route.Hops = append(route.Hops, entities.Hop{
+			Pair:     entities.Pair{DEX: dex, Fee: fee},
+			TokenIn:  tokens[i],
+			TokenOut: tokens[i+1],
+		})
+	}
+	return route
+}
+
+func TestBuildV2MultiHop(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := NewBuilder().Build(testRoute(entities.DEXUniswapV2, 30, 2), big.NewInt(1000), testRecipient, testDeadline)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if tx.To != UniswapV2RouterAddress {
+		t.Errorf("To = %s, want V2 router", tx.To.Hex())
+	}
+
+	method := parsed.Methods["swapExactTokensForTokens"]
+	if !bytes.Equal(tx.Data[:4], method.ID) {
+		t.Fatalf("selector = %x, want %x", tx.Data[:4], method.ID)
+	}
+
+	args, err := method.Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	path := args[2].([]common.Address)
+	if len(path) != 3 || path[0] != entities.WETH.Address || path[1] != testMid || path[2] != entities.USDC.Address {
+		t.Errorf("path = %v, want WETH -> mid -> USDC", path)
+	}
+	if args[1].(*big.Int).Int64() != 1000 {
+		t.Errorf("amountOutMin = %v, want 1000", args[1])
+	}
+	if args[3].(common.Address) != testRecipient {
+		t.Errorf("to = %s, want recipient", args[3].(common.Address).Hex())
+	}
+	if args[4].(*big.Int).Int64() != testDeadline.Unix() {
+		t.Errorf("deadline = %v, want %d", args[4], testDeadline.Unix())
+	}
+}
+
+func TestBuildV3MultiHop(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := NewBuilder().Build(testRoute(entities.DEXUniswapV3, 500, 2), big.NewInt(1000), testRecipient, testDeadline)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	multicall := parsed.Methods["multicall"]
+	if !bytes.Equal(tx.Data[:4], multicall.ID) {
+		t.Fatalf("selector = %x, want multicall %x", tx.Data[:4], multicall.ID)
+	}
+	wrapped, err := multicall.Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack(multicall) error = %v", err)
+	}
+	if wrapped[0].(*big.Int).Int64() != testDeadline.Unix() {
+		t.Errorf("deadline = %v, want %d", wrapped[0], testDeadline.Unix())
+	}
+	calls := wrapped[1].([][]byte)
+	if len(calls) != 1 {
+		t.Fatalf("multicall wraps %d calls, want 1", len(calls))
+	}
+
+	method := parsed.Methods["exactInput"]
+	if !bytes.Equal(calls[0][:4], method.ID) {
+		t.Fatalf("selector = %x, want %x", calls[0][:4], method.ID)
+	}
+
+	args, err := method.Inputs.Unpack(calls[0][4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	params := args[0].(struct {
+		Path             []byte         `json:"path"`
+		Recipient        common.Address `json:"recipient"`
+		AmountIn         *big.Int       `json:"amountIn"`
+		AmountOutMinimum *big.Int       `json:"amountOutMinimum"`
+	})
+
+	if len(params.Path) != 20+23*2 {
+		t.Errorf("path length = %d, want %d", len(params.Path), 20+23*2)
+	}
+	if !bytes.Equal(params.Path[20:23], []byte{0x00, 0x01, 0xf4}) {
+		t.Errorf("first fee = %x, want 0001f4", params.Path[20:23])
+	}
+	if params.Recipient != testRecipient || params.AmountIn.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("params = %+v, unexpected recipient or amount", params)
+	}
+}
+
+func TestBuildNativeV2(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                string
+		nativeIn, nativeOut bool
+		method              string
+		value               int64
+		path                int // Index of the path argument
+	}{
+		{"native in", true, false, "swapExactETHForTokens", 1e18, 1},
+		{"native out", false, true, "swapExactTokensForETH", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewBuilder().BuildNative(testRoute(entities.DEXUniswapV2, 30, 2), big.NewInt(1000), testRecipient, testDeadline, tt.nativeIn, tt.nativeOut)
+			if err != nil {
+				t.Fatalf("BuildNative() error = %v", err)
+			}
+			method := parsed.Methods[tt.method]
+			if !bytes.Equal(tx.Data[:4], method.ID) {
+				t.Fatalf("selector = %x, want %s", tx.Data[:4], tt.method)
+			}
+			if tx.Value.Int64() != tt.value {
+				t.Errorf("value = %s, want %d", tx.Value, tt.value)
+			}
+			args, err := method.Inputs.Unpack(tx.Data[4:])
+			if err != nil {
+				t.Fatalf("Unpack() error = %v", err)
+			}
+			if path := args[tt.path].([]common.Address); len(path) != 3 || path[2] != entities.USDC.Address {
+				t.Errorf("path = %v", path)
+			}
+			if args[tt.path+1].(common.Address) != testRecipient {
+				t.Errorf("to = %s, want recipient", args[tt.path+1].(common.Address).Hex())
+			}
+		})
+	}
+
+	if _, err := NewBuilder().BuildNative(testRoute(entities.DEXUniswapV2, 30, 1), nil, testRecipient, testDeadline, true, true); err == nil {
+		t.Error("BuildNative() accepted a native-to-native route")
+	}
+}
+
+func TestBuildNativeOutV3(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := NewBuilder().BuildNative(testRoute(entities.DEXUniswapV3, 500, 2), big.NewInt(1000), testRecipient, testDeadline, false, true)
+	if err != nil {
+		t.Fatalf("BuildNative() error = %v", err)
+	}
+	wrapped, err := parsed.Methods["multicall"].Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack(multicall) error = %v", err)
+	}
+	calls := wrapped[1].([][]byte)
+	if len(calls) != 2 {
+		t.Fatalf("multicall wraps %d calls, want swap and unwrap", len(calls))
+	}
+
+	args, err := parsed.Methods["exactInput"].Inputs.Unpack(calls[0][4:])
+	if err != nil {
+		t.Fatalf("Unpack(exactInput) error = %v", err)
+	}
+	if recipient := reflect.ValueOf(args[0]).FieldByName("Recipient").Interface().(common.Address); recipient != swapRouterSelf {
+		t.Errorf("swap recipient = %s, want the router", recipient.Hex())
+	}
+
+	unwrap := parsed.Methods["unwrapWETH9"]
+	if !bytes.Equal(calls[1][:4], unwrap.ID) {
+		t.Fatalf("second call = %x, want unwrapWETH9", calls[1][:4])
+	}
+	args, err = unwrap.Inputs.Unpack(calls[1][4:])
+	if err != nil {
+		t.Fatalf("Unpack(unwrapWETH9) error = %v", err)
+	}
+	if args[0].(*big.Int).Int64() != 1000 || args[1].(common.Address) != testRecipient {
+		t.Errorf("unwrapWETH9(%v, %s), want (1000, recipient)", args[0], args[1].(common.Address).Hex())
+	}
+}
+
+func TestBuildUnsupportedVenue(t *testing.T) {
+	if _, err := NewBuilder().Build(testRoute(entities.DEXLido, 0, 1), nil, testRecipient, testDeadline); err == nil {
+		t.Error("Build() for Lido route succeeded, want error")
+	}
+}
@@ -0,0 +1,69 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Curve pools swap through one of these, by the pool's generation; all
+// pay the output to the caller
+var (
+	curveExchangeSelector           = crypto.Keccak256([]byte("exchange(int128,int128,uint256,uint256)"))[:4]
+	curveExchangeUint256Selector    = crypto.Keccak256([]byte("exchange(uint256,uint256,uint256,uint256)"))[:4]
+	curveExchangeUnderlyingSelector = crypto.Keccak256([]byte("exchange_underlying(int128,int128,uint256,uint256)"))[:4]
+)
+
+// encodeCurveSwap encodes exchange(i, j, dx, min_dy), or
+// exchange_underlying for pools swapping their lent-out coins. Pools that
+// hold native ETH only take it as the call's value and pay it out as ETH,
+// so their WETH legs must be native.
+func encodeCurveSwap(route *entities.Route, minAmountOut *big.Int, nativeIn, nativeOut bool) ([]byte, error) {
+	if len(route.Hops) != 1 {
+		return nil, fmt.Errorf("Curve swaps are built one pool at a time, route has %d hops", len(route.Hops))
+	}
+	hop := route.Hops[0]
+	swap := hop.Pair.CurveSwap
+	if swap == nil {
+		return nil, fmt.Errorf("Curve pool %s has no coin indexes", hop.Pair.Address.Hex())
+	}
+
+	i, j := swap.Index0, swap.Index1
+	if hop.TokenIn != hop.Pair.Token0.Address {
+		i, j = j, i
+	}
+	ethIn := swap.ETH && hop.TokenIn == entities.WETH.Address
+	ethOut := swap.ETH && hop.TokenOut == entities.WETH.Address
+	switch {
+	case ethIn != nativeIn:
+		return nil, fmt.Errorf("Curve pool %s takes %s, not %s", hop.Pair.Address.Hex(), nativeName(ethIn), nativeName(nativeIn))
+	case ethOut != nativeOut:
+		return nil, fmt.Errorf("Curve pool %s pays %s, not %s", hop.Pair.Address.Hex(), nativeName(ethOut), nativeName(nativeOut))
+	}
+
+	selector := curveExchangeSelector
+	switch {
+	case swap.Underlying:
+		selector = curveExchangeUnderlyingSelector
+	case swap.Uint256:
+		selector = curveExchangeUint256Selector
+	}
+	data := make([]byte, 4+32*4)
+	copy(data[0:4], selector)
+	// int128 indexes are never negative, so they encode as uint256 does
+	putUint(data[4:36], big.NewInt(int64(i)))
+	putUint(data[36:68], big.NewInt(int64(j)))
+	putUint(data[68:100], route.AmountIn)
+	putUint(data[100:132], minAmountOut)
+	return data, nil
+}
+
+func nativeName(native bool) string {
+	if native {
+		return "ETH"
+	}
+	return "WETH"
+}
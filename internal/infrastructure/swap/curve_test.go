@@ -0,0 +1,116 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const curveABI = `[
+	{"name":"exchange","type":"function","inputs":[
+		{"name":"i","type":"int128"},{"name":"j","type":"int128"},
+		{"name":"dx","type":"uint256"},{"name":"min_dy","type":"uint256"}]},
+	{"name":"exchange_underlying","type":"function","inputs":[
+		{"name":"i","type":"int128"},{"name":"j","type":"int128"},
+		{"name":"dx","type":"uint256"},{"name":"min_dy","type":"uint256"}]}
+]`
+
+func TestBuildCurve(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(curveABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := common.HexToAddress("0xDC24316b9AE028F1497c275EB9192a3Ea0f67022")
+	// Token0 is WETH at coin 0, Token1 stETH at coin 1
+	route := func(swap entities.CurveSwap) *entities.Route {
+		return &entities.Route{
+			AmountIn: big.NewInt(1e18),
+			Hops: []entities.Hop{{
+				Pair: entities.Pair{
+					Address: pool, DEX: entities.DEXCurve,
+					Token0: entities.WETH, Token1: entities.STETH,
+					CurveSwap: &swap,
+				},
+				TokenIn:  entities.STETH.Address,
+				TokenOut: entities.WETH.Address,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		swap      entities.CurveSwap
+		nativeOut bool
+		method    string
+		selector  []byte
+		wantErr   bool
+	}{
+		{"exchange", entities.CurveSwap{Index0: 0, Index1: 1}, false, "exchange", nil, false},
+		{"underlying", entities.CurveSwap{Index0: 0, Index1: 1, Underlying: true}, false, "exchange_underlying", nil, false},
+		{"uint256 indexes", entities.CurveSwap{Index0: 0, Index1: 1, Uint256: true}, false, "exchange", curveExchangeUint256Selector, false},
+		{"ETH paid out", entities.CurveSwap{Index0: 0, Index1: 1, ETH: true}, true, "exchange", nil, false},
+		{"ETH pool asked for WETH", entities.CurveSwap{Index0: 0, Index1: 1, ETH: true}, false, "", nil, true},
+		{"WETH pool asked for ETH", entities.CurveSwap{Index0: 0, Index1: 1}, true, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewBuilder().BuildNative(route(tt.swap), big.NewInt(99e16), testRecipient, testDeadline, false, tt.nativeOut)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("BuildNative() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildNative() error = %v", err)
+			}
+			if tx.To != pool {
+				t.Errorf("To = %s, want the pool", tx.To.Hex())
+			}
+			method := parsed.Methods[tt.method]
+			selector := tt.selector
+			if selector == nil {
+				selector = method.ID
+			}
+			if !bytes.Equal(tx.Data[:4], selector) {
+				t.Fatalf("selector = %x, want %x", tx.Data[:4], selector)
+			}
+			// The uint256 overload encodes the same words
+			args, err := method.Inputs.Unpack(tx.Data[4:])
+			if err != nil {
+				t.Fatalf("Unpack() error = %v", err)
+			}
+			if args[0].(*big.Int).Int64() != 1 || args[1].(*big.Int).Int64() != 0 {
+				t.Errorf("exchange(%v, %v), want stETH coin 1 for WETH coin 0", args[0], args[1])
+			}
+			if args[2].(*big.Int).Cmp(big.NewInt(1e18)) != 0 || args[3].(*big.Int).Cmp(big.NewInt(99e16)) != 0 {
+				t.Errorf("dx, min_dy = %v, %v", args[2], args[3])
+			}
+		})
+	}
+
+	// WETH goes in as ETH at coin 0
+	in := route(entities.CurveSwap{Index0: 0, Index1: 1, ETH: true})
+	in.Hops[0].TokenIn, in.Hops[0].TokenOut = entities.WETH.Address, entities.STETH.Address
+	tx, err := NewBuilder().BuildNative(in, big.NewInt(99e16), testRecipient, testDeadline, true, false)
+	if err != nil {
+		t.Fatalf("BuildNative(ETH in) error = %v", err)
+	}
+	args, err := parsed.Methods["exchange"].Inputs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[0].(*big.Int).Int64() != 0 || args[1].(*big.Int).Int64() != 1 || tx.Value.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("exchange(%v, %v) with value %s, want ETH coin 0 for stETH coin 1 paid as value", args[0], args[1], tx.Value)
+	}
+
+	if _, err := NewBuilder().Build(testRoute(entities.DEXCurve, 4, 2), nil, testRecipient, testDeadline); err == nil {
+		t.Error("Build() accepted a two-pool Curve route")
+	}
+}
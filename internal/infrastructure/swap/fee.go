@@ -0,0 +1,111 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// FeeCollectedEvent is emitted by the fee collector for every fee it pays:
+// FeeCollected(address indexed feeRecipient, address indexed token, uint256 amount)
+const FeeCollectedEvent = "FeeCollected(address,address,uint256)"
+
+// FeeClaimedEvent is emitted by the fee collector for every withdrawal:
+// FeeClaimed(address indexed feeRecipient, address indexed token, uint256 amount)
+const FeeClaimedEvent = "FeeClaimed(address,address,uint256)"
+
+// swapWithFee(address router, bytes data, address tokenIn, uint256 amountIn,
+// address tokenOut, uint256 minAmountOut, address recipient,
+// address feeRecipient, uint256 feeBps)
+var swapWithFeeSelector = crypto.Keccak256([]byte("swapWithFee(address,bytes,address,uint256,address,uint256,address,address,uint256)"))[:4]
+
+var swapWithFeeArgs = func() abi.Arguments {
+	addressType, _ := abi.NewType("address", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	uintType, _ := abi.NewType("uint256", "", nil)
+	return abi.Arguments{
+		{Type: addressType}, {Type: bytesType}, {Type: addressType}, {Type: uintType},
+		{Type: addressType}, {Type: uintType}, {Type: addressType}, {Type: addressType}, {Type: uintType},
+	}
+}()
+
+// claimFees(address token, address to) pays to everything credited to the
+// caller in token; accruedFees(address feeRecipient, address token) is what
+// that would pay feeRecipient
+var (
+	claimFeesSelector   = crypto.Keccak256([]byte("claimFees(address,address)"))[:4]
+	accruedFeesSelector = crypto.Keccak256([]byte("accruedFees(address,address)"))[:4]
+	feeTokenArgs        = newArgs("address", "address")
+)
+
+// BuildWithFee encodes a swap through the fee collector at collector. The
+// collector pulls route.AmountIn from the sender, runs the router call with
+// itself as recipient, pays fee.Bps of the output to fee.Recipient and sends
+// the rest to recipient, reverting when that is below minAmountOut.
+func (b *Builder) BuildWithFee(route *entities.Route, minAmountOut *big.Int, recipient common.Address, deadline time.Time, collector common.Address, fee *entities.IntegratorFee) (*entities.SwapTransaction, error) {
+	if fee.Bps == 0 || fee.Bps >= 10000 {
+		return nil, fmt.Errorf("fee must be 1-9999 basis points, got %d", fee.Bps)
+	}
+	if minAmountOut == nil {
+		minAmountOut = big.NewInt(0)
+	}
+
+	// The router must return enough that the net amount still meets the minimum
+	routerMin := new(big.Int).Mul(minAmountOut, big.NewInt(10000))
+	keep := big.NewInt(int64(10000 - fee.Bps))
+	routerMin.Add(routerMin, new(big.Int).Sub(keep, big.NewInt(1)))
+	routerMin.Div(routerMin, keep)
+
+	inner, err := b.Build(route, routerMin, collector, deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := swapWithFeeArgs.Pack(
+		inner.To, inner.Data,
+		route.Hops[0].TokenIn, route.AmountIn,
+		route.Hops[len(route.Hops)-1].TokenOut, minAmountOut,
+		recipient, fee.Recipient, new(big.Int).SetUint64(fee.Bps),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fee collector call: %w", err)
+	}
+
+	return &entities.SwapTransaction{
+		From:  recipient,
+		To:    collector,
+		Data:  append(append([]byte{}, swapWithFeeSelector...), args...),
+		Value: big.NewInt(0),
+	}, nil
+}
+
+// BuildFeeClaim encodes feeRecipient's withdrawal from the fee collector at
+// collector of every fee credited to it in token, paid to to
+func BuildFeeClaim(collector, feeRecipient, token, to common.Address) *entities.SwapTransaction {
+	return &entities.SwapTransaction{
+		From:  feeRecipient,
+		To:    collector,
+		Data:  packFeeCall(claimFeesSelector, token, to),
+		Value: big.NewInt(0),
+	}
+}
+
+// PackAccruedFees encodes the collector's accruedFees(feeRecipient, token)
+// view, the amount a claim would pay out
+func PackAccruedFees(feeRecipient, token common.Address) []byte {
+	return packFeeCall(accruedFeesSelector, feeRecipient, token)
+}
+
+func packFeeCall(selector []byte, a, b common.Address) []byte {
+	args, err := feeTokenArgs.Pack(a, b)
+	if err != nil {
+		panic(err) // Two addresses always pack
+	}
+	return append(append([]byte{}, selector...), args...)
+}
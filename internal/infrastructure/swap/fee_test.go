@@ -0,0 +1,85 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestBuildWithFee(t *testing.T) {
+	collector := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	feeTo := common.HexToAddress("0x00000000000000000000000000000000000000f0")
+	fee := &entities.IntegratorFee{Bps: 30, Recipient: feeTo}
+
+	tx, err := NewBuilder().BuildWithFee(testRoute(entities.DEXUniswapV2, 30, 2), big.NewInt(9970), testRecipient, testDeadline, collector, fee)
+	if err != nil {
+		t.Fatalf("BuildWithFee() error = %v", err)
+	}
+	if tx.To != collector || !bytes.Equal(tx.Data[:4], swapWithFeeSelector) {
+		t.Fatalf("tx to %s selector %x, want the fee collector", tx.To.Hex(), tx.Data[:4])
+	}
+
+	args, err := swapWithFeeArgs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[0].(common.Address) != UniswapV2RouterAddress {
+		t.Errorf("router = %s, want V2 router", args[0].(common.Address).Hex())
+	}
+	if args[2].(common.Address) != entities.WETH.Address || args[4].(common.Address) != entities.USDC.Address {
+		t.Errorf("tokens = %s -> %s, want WETH -> USDC", args[2].(common.Address).Hex(), args[4].(common.Address).Hex())
+	}
+	if args[5].(*big.Int).Int64() != 9970 || args[6].(common.Address) != testRecipient || args[7].(common.Address) != feeTo || args[8].(*big.Int).Int64() != 30 {
+		t.Errorf("fee args = %v", args[5:])
+	}
+
+	// The router pays the collector, with a minimum that covers the fee
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := parsed.Methods["swapExactTokensForTokens"].Inputs.Unpack(args[1].([]byte)[4:])
+	if err != nil {
+		t.Fatalf("Unpack(router call) error = %v", err)
+	}
+	if inner[3].(common.Address) != collector {
+		t.Errorf("router recipient = %s, want the collector", inner[3].(common.Address).Hex())
+	}
+	if inner[1].(*big.Int).Int64() != 10000 {
+		t.Errorf("router amountOutMin = %v, want 10000 (9970 net after 30 bps)", inner[1])
+	}
+
+	if _, err := NewBuilder().BuildWithFee(testRoute(entities.DEXUniswapV2, 30, 1), nil, testRecipient, testDeadline, collector, &entities.IntegratorFee{}); err == nil {
+		t.Error("BuildWithFee() accepted a zero fee")
+	}
+}
+
+func TestBuildFeeClaim(t *testing.T) {
+	collector := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	feeTo := common.HexToAddress("0x00000000000000000000000000000000000000f0")
+
+	tx := BuildFeeClaim(collector, feeTo, entities.USDC.Address, testRecipient)
+	if tx.From != feeTo || tx.To != collector || tx.Value.Sign() != 0 {
+		t.Errorf("claim from %s to %s with value %s, want the fee recipient calling the collector", tx.From.Hex(), tx.To.Hex(), tx.Value)
+	}
+	if got := common.Bytes2Hex(tx.Data[:4]); got != common.Bytes2Hex(claimFeesSelector) {
+		t.Fatalf("selector = %s, want claimFees", got)
+	}
+	args, err := feeTokenArgs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[0].(common.Address) != entities.USDC.Address || args[1].(common.Address) != testRecipient {
+		t.Errorf("claimFees args = %v, want USDC paid to the recipient", args)
+	}
+
+	if data := PackAccruedFees(feeTo, entities.USDC.Address); !bytes.Equal(data[:4], accruedFeesSelector) || len(data) != 4+64 {
+		t.Errorf("accruedFees call = %x", data)
+	}
+}
@@ -0,0 +1,215 @@
+package swap
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+var (
+	// swap(uint256,uint256,address,bytes) on a Uniswap V2 pair
+	v2PairSwapSelector = common.Hex2Bytes("022c0d9f")
+	// swap(address,bool,int256,uint160,bytes) on a Uniswap V3 pool
+	v3PoolSwapSelector = common.Hex2Bytes("128acb08")
+)
+
+// Callbacks invoked on the receiver during a flash swap
+const (
+	V2FlashCallback = "uniswapV2Call(address,uint256,uint256,bytes)"
+	V3FlashCallback = "uniswapV3SwapCallback(int256,int256,bytes)"
+)
+
+// Venue codes in the callback payload, telling the receiver how to call a pool
+const (
+	venueV2 uint8 = 0
+	venueV3 uint8 = 1
+)
+
+// Uniswap V3 price limits one step inside TickMath bounds, i.e. no limit
+var (
+	minSqrtRatioPlusOne, _  = new(big.Int).SetString("4295128740", 10)
+	maxSqrtRatioMinusOne, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970341", 10)
+)
+
+// flashPayloadArgs is the callback payload:
+// abi.encode(address repayToken, uint256 repayAmount, uint256 minProfit,
+// (address pool, uint8 venue, address tokenIn, address tokenOut, uint24 fee, uint256 amountOut)[] hops)
+var flashPayloadArgs = func() abi.Arguments {
+	hopType, err := abi.NewType("tuple[]", "", []abi.ArgumentMarshaling{
+		{Name: "pool", Type: "address"},
+		{Name: "venue", Type: "uint8"},
+		{Name: "tokenIn", Type: "address"},
+		{Name: "tokenOut", Type: "address"},
+		{Name: "fee", Type: "uint24"},
+		{Name: "amountOut", Type: "uint256"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	addressType, _ := abi.NewType("address", "", nil)
+	uintType, _ := abi.NewType("uint256", "", nil)
+	return abi.Arguments{{Type: addressType}, {Type: uintType}, {Type: uintType}, {Type: hopType}}
+}()
+
+// flashHop mirrors the payload tuple for abi packing
+type flashHop struct {
+	Pool      common.Address
+	Venue     uint8
+	TokenIn   common.Address
+	TokenOut  common.Address
+	Fee       *big.Int
+	AmountOut *big.Int
+}
+
+// BuildFlashSwap encodes a flash swap for cycle: the first leg's pool sends
+// its output to receiver before being paid, the callback payload lists the
+// remaining legs, and the pool is repaid cycle.AmountIn of the first tokenIn.
+func (b *Builder) BuildFlashSwap(cycle *entities.ArbitrageCycle, receiver common.Address, minProfit *big.Int) (*entities.FlashSwap, error) {
+	if err := validateCycle(cycle); err != nil {
+		return nil, err
+	}
+	if minProfit == nil {
+		minProfit = big.NewInt(0)
+	}
+
+	first := cycle.Hops[0]
+	profit := new(big.Int).Sub(cycle.Hops[len(cycle.Hops)-1].AmountOut, cycle.AmountIn)
+	if profit.Cmp(minProfit) < 0 {
+		return nil, fmt.Errorf("cycle returns %s over the repayment, below minProfit %s", profit, minProfit)
+	}
+
+	hops := make([]flashHop, 0, len(cycle.Hops)-1)
+	for _, hop := range cycle.Hops[1:] {
+		venue, err := flashVenue(hop.DEX)
+		if err != nil {
+			return nil, err
+		}
+		hops = append(hops, flashHop{
+			Pool:      hop.Pool,
+			Venue:     venue,
+			TokenIn:   hop.TokenIn,
+			TokenOut:  hop.TokenOut,
+			Fee:       new(big.Int).SetUint64(hop.Fee),
+			AmountOut: hop.AmountOut,
+		})
+	}
+	payload, err := flashPayloadArgs.Pack(first.TokenIn, cycle.AmountIn, minProfit, hops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode callback payload: %w", err)
+	}
+
+	// token0 is the lower address in both V2 pairs and V3 pools
+	zeroForOne := bytes.Compare(first.TokenIn.Bytes(), first.TokenOut.Bytes()) < 0
+
+	var data []byte
+	var callback string
+	switch first.DEX {
+	case entities.DEXUniswapV2, entities.DEXSushiswap:
+		data, callback = encodeV2FlashSwap(first.AmountOut, zeroForOne, receiver, payload), V2FlashCallback
+	case entities.DEXUniswapV3:
+		data, callback = encodeV3FlashSwap(cycle.AmountIn, zeroForOne, receiver, payload), V3FlashCallback
+	default:
+		return nil, fmt.Errorf("flash swaps are not supported for %s", first.DEX)
+	}
+
+	return &entities.FlashSwap{
+		Transaction: &entities.SwapTransaction{
+			From:  receiver,
+			To:    first.Pool,
+			Data:  data,
+			Value: big.NewInt(0),
+		},
+		Callback:       callback,
+		CallbackData:   payload,
+		RepayToken:     first.TokenIn,
+		RepayAmount:    cycle.AmountIn,
+		ExpectedProfit: profit,
+	}, nil
+}
+
+// validateCycle checks the legs connect and return to the starting token
+func validateCycle(cycle *entities.ArbitrageCycle) error {
+	if cycle == nil || len(cycle.Hops) < 2 {
+		return fmt.Errorf("cycle needs at least two hops")
+	}
+	if cycle.AmountIn == nil || cycle.AmountIn.Sign() <= 0 {
+		return fmt.Errorf("cycle amountIn must be positive")
+	}
+	for i, hop := range cycle.Hops {
+		if hop.AmountOut == nil || hop.AmountOut.Sign() <= 0 {
+			return fmt.Errorf("hop %d amountOut must be positive", i)
+		}
+		if hop.Pool == (common.Address{}) {
+			return fmt.Errorf("hop %d has no pool", i)
+		}
+		if i > 0 && hop.TokenIn != cycle.Hops[i-1].TokenOut {
+			return fmt.Errorf("hop %d tokenIn does not match hop %d tokenOut", i, i-1)
+		}
+	}
+	if cycle.Hops[len(cycle.Hops)-1].TokenOut != cycle.Hops[0].TokenIn {
+		return fmt.Errorf("cycle does not return to its starting token")
+	}
+	return nil
+}
+
+func flashVenue(dexType entities.DEXType) (uint8, error) {
+	switch dexType {
+	case entities.DEXUniswapV2, entities.DEXSushiswap:
+		return venueV2, nil
+	case entities.DEXUniswapV3:
+		return venueV3, nil
+	default:
+		return 0, fmt.Errorf("flash swap legs are not supported for %s", dexType)
+	}
+}
+
+// encodeV2FlashSwap encodes pair.swap(amount0Out, amount1Out, to, data)
+func encodeV2FlashSwap(amountOut *big.Int, zeroForOne bool, to common.Address, payload []byte) []byte {
+	paddedLen := (len(payload) + 31) / 32 * 32
+
+	// 4 head slots, then the bytes length and data
+	data := make([]byte, 4+32*4+32+paddedLen)
+	copy(data[0:4], v2PairSwapSelector)
+	if zeroForOne {
+		putUint(data[36:68], amountOut) // amount1Out
+	} else {
+		putUint(data[4:36], amountOut) // amount0Out
+	}
+	putAddress(data[68:100], to)
+	putUint(data[100:132], big.NewInt(32*4)) // offset of data
+	putUint(data[132:164], big.NewInt(int64(len(payload))))
+	copy(data[164:], payload)
+
+	return data
+}
+
+// encodeV3FlashSwap encodes pool.swap(recipient, zeroForOne, amountIn,
+// sqrtPriceLimitX96, data) for an exact input paid in the callback
+func encodeV3FlashSwap(amountIn *big.Int, zeroForOne bool, recipient common.Address, payload []byte) []byte {
+	paddedLen := (len(payload) + 31) / 32 * 32
+
+	priceLimit := maxSqrtRatioMinusOne
+	if zeroForOne {
+		priceLimit = minSqrtRatioPlusOne
+	}
+
+	// 5 head slots, then the bytes length and data
+	data := make([]byte, 4+32*5+32+paddedLen)
+	copy(data[0:4], v3PoolSwapSelector)
+	putAddress(data[4:36], recipient)
+	if zeroForOne {
+		data[67] = 1
+	}
+	putUint(data[68:100], amountIn) // Positive amountSpecified is exact input
+	putUint(data[100:132], priceLimit)
+	putUint(data[132:164], big.NewInt(32*5)) // offset of data
+	putUint(data[164:196], big.NewInt(int64(len(payload))))
+	copy(data[196:], payload)
+
+	return data
+}
@@ -0,0 +1,119 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const poolABI = `[
+	{"name":"swapV2","type":"function","inputs":[
+		{"name":"amount0Out","type":"uint256"},{"name":"amount1Out","type":"uint256"},
+		{"name":"to","type":"address"},{"name":"data","type":"bytes"}]},
+	{"name":"swapV3","type":"function","inputs":[
+		{"name":"recipient","type":"address"},{"name":"zeroForOne","type":"bool"},
+		{"name":"amountSpecified","type":"int256"},{"name":"sqrtPriceLimitX96","type":"uint160"},
+		{"name":"data","type":"bytes"}]}
+]`
+
+// testCycle is WETH -> USDC on firstDEX, then USDC -> WETH on a V3 pool
+func testCycle(firstDEX entities.DEXType) *entities.ArbitrageCycle {
+	return &entities.ArbitrageCycle{
+		AmountIn: big.NewInt(1e18),
+		Hops: []entities.CycleHop{
+			{DEX: firstDEX, Pool: common.HexToAddress("0xaa"), TokenIn: entities.WETH.Address, TokenOut: entities.USDC.Address, Fee: 500, AmountOut: big.NewInt(3000e6)},
+			{DEX: entities.DEXUniswapV3, Pool: common.HexToAddress("0xbb"), TokenIn: entities.USDC.Address, TokenOut: entities.WETH.Address, Fee: 3000, AmountOut: big.NewInt(1.01e18)},
+		},
+	}
+}
+
+func TestBuildFlashSwap(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(poolABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("v2 pair", func(t *testing.T) {
+		flash, err := NewBuilder().BuildFlashSwap(testCycle(entities.DEXUniswapV2), testRecipient, big.NewInt(1e16))
+		if err != nil {
+			t.Fatalf("BuildFlashSwap() error = %v", err)
+		}
+		if !bytes.Equal(flash.Transaction.Data[:4], v2PairSwapSelector) || flash.Callback != V2FlashCallback {
+			t.Fatalf("selector = %x, callback = %s", flash.Transaction.Data[:4], flash.Callback)
+		}
+		args, err := parsed.Methods["swapV2"].Inputs.Unpack(flash.Transaction.Data[4:])
+		if err != nil {
+			t.Fatalf("Unpack() error = %v", err)
+		}
+		// USDC sorts below WETH, so USDC is token0 and leaves as amount0Out
+		if args[0].(*big.Int).Cmp(big.NewInt(3000e6)) != 0 || args[1].(*big.Int).Sign() != 0 {
+			t.Errorf("amounts out = %v/%v, want 3000e6/0", args[0], args[1])
+		}
+		if args[2].(common.Address) != testRecipient {
+			t.Errorf("to = %s, want receiver", args[2].(common.Address).Hex())
+		}
+		if !bytes.Equal(args[3].([]byte), flash.CallbackData) {
+			t.Error("calldata payload differs from CallbackData")
+		}
+		if flash.ExpectedProfit.Cmp(big.NewInt(1e16)) != 0 {
+			t.Errorf("ExpectedProfit = %s, want 1e16", flash.ExpectedProfit)
+		}
+
+		payload, err := flashPayloadArgs.Unpack(flash.CallbackData)
+		if err != nil {
+			t.Fatalf("payload Unpack() error = %v", err)
+		}
+		if payload[0].(common.Address) != entities.WETH.Address || payload[1].(*big.Int).Cmp(big.NewInt(1e18)) != 0 {
+			t.Errorf("repay = %v %v, want 1e18 WETH", payload[1], payload[0])
+		}
+	})
+
+	t.Run("v3 pool", func(t *testing.T) {
+		flash, err := NewBuilder().BuildFlashSwap(testCycle(entities.DEXUniswapV3), testRecipient, nil)
+		if err != nil {
+			t.Fatalf("BuildFlashSwap() error = %v", err)
+		}
+		args, err := parsed.Methods["swapV3"].Inputs.Unpack(flash.Transaction.Data[4:])
+		if err != nil {
+			t.Fatalf("Unpack() error = %v", err)
+		}
+		if args[1].(bool) {
+			t.Error("zeroForOne = true, want false for WETH -> USDC")
+		}
+		if args[2].(*big.Int).Cmp(big.NewInt(1e18)) != 0 {
+			t.Errorf("amountSpecified = %v, want exact input 1e18", args[2])
+		}
+		if args[3].(*big.Int).Cmp(maxSqrtRatioMinusOne) != 0 {
+			t.Errorf("sqrtPriceLimitX96 = %v, want max", args[3])
+		}
+	})
+}
+
+func TestBuildFlashSwapRejectsBadCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(*entities.ArbitrageCycle)
+		minProfit *big.Int
+	}{
+		{"open cycle", func(c *entities.ArbitrageCycle) { c.Hops[1].TokenOut = testMid }, nil},
+		{"disconnected legs", func(c *entities.ArbitrageCycle) { c.Hops[1].TokenIn = testMid }, nil},
+		{"unsupported venue", func(c *entities.ArbitrageCycle) { c.Hops[1].DEX = entities.DEXCurve }, nil},
+		{"below min profit", func(c *entities.ArbitrageCycle) {}, big.NewInt(1e17)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle := testCycle(entities.DEXUniswapV2)
+			tt.mutate(cycle)
+			if _, err := NewBuilder().BuildFlashSwap(cycle, testRecipient, tt.minProfit); err == nil {
+				t.Error("BuildFlashSwap() error = nil")
+			}
+		})
+	}
+}
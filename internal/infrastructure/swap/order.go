@@ -0,0 +1,55 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// fillDutch(address owner, address tokenIn, address tokenOut,
+// uint256 amountIn, uint256 startAmountOut, uint256 endAmountOut,
+// uint256 startTime, uint256 endTime, uint256 nonce, bytes signature,
+// address router, bytes data)
+var fillDutchSelector = crypto.Keccak256([]byte("fillDutch(address,address,address,uint256,uint256,uint256,uint256,uint256,uint256,bytes,address,bytes)"))[:4]
+
+var fillDutchArgs = newArgs(
+	"address", "address", "address", "uint256", "uint256", "uint256",
+	"uint256", "uint256", "uint256", "bytes", "address", "bytes",
+)
+
+// BuildDutchFill encodes a fill of a signed Dutch order through the
+// settlement contract at settlement, the order's EIP-712 verifying
+// contract. The settlement checks the owner's signature and spends the
+// nonce, pulls AmountIn from the owner, runs the router call with itself
+// as recipient, and pays the owner the order's limit at the block's
+// timestamp, reverting below it. Output above the limit goes to the filler,
+// whoever sends the transaction. limit is the router call's minimum, the
+// order's limit when the fill is built.
+func (b *Builder) BuildDutchFill(route *entities.Route, order *entities.Order, limit *big.Int, settlement common.Address) (*entities.SwapTransaction, error) {
+	inner, err := b.Build(route, limit, settlement, time.Unix(int64(order.EndTime), 0))
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := fillDutchArgs.Pack(
+		order.Owner, order.TokenIn.Address, order.TokenOut.Address,
+		order.AmountIn, order.StartAmountOut, order.EndAmountOut,
+		new(big.Int).SetUint64(order.StartTime), new(big.Int).SetUint64(order.EndTime),
+		order.Nonce, order.Signature,
+		inner.To, inner.Data,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode order fill: %w", err)
+	}
+
+	return &entities.SwapTransaction{
+		To:    settlement,
+		Data:  append(append([]byte{}, fillDutchSelector...), args...),
+		Value: big.NewInt(0),
+	}, nil
+}
@@ -0,0 +1,64 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestBuildDutchFill(t *testing.T) {
+	settlement := common.HexToAddress("0x00000000000000000000000000000000000000dd")
+	order := &entities.Order{
+		Owner:          testRecipient,
+		TokenIn:        entities.WETH,
+		TokenOut:       entities.USDC,
+		AmountIn:       big.NewInt(10000),
+		StartAmountOut: big.NewInt(9990),
+		EndAmountOut:   big.NewInt(9000),
+		StartTime:      1700000000,
+		EndTime:        1700000600,
+		Nonce:          big.NewInt(3),
+		Signature:      bytes.Repeat([]byte{0xab}, 65),
+	}
+
+	tx, err := NewBuilder().BuildDutchFill(testRoute(entities.DEXUniswapV2, 30, 2), order, big.NewInt(9500), settlement)
+	if err != nil {
+		t.Fatalf("BuildDutchFill() error = %v", err)
+	}
+	if tx.To != settlement || !bytes.Equal(tx.Data[:4], fillDutchSelector) || tx.Value.Sign() != 0 {
+		t.Fatalf("tx to %s selector %x, want the settlement's fillDutch", tx.To.Hex(), tx.Data[:4])
+	}
+
+	args, err := fillDutchArgs.Unpack(tx.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[0].(common.Address) != order.Owner || args[1].(common.Address) != entities.WETH.Address || args[2].(common.Address) != entities.USDC.Address {
+		t.Errorf("order parties = %v, want the owner swapping WETH for USDC", args[:3])
+	}
+	if args[7].(*big.Int).Uint64() != order.EndTime || args[8].(*big.Int).Int64() != 3 || !bytes.Equal(args[9].([]byte), order.Signature) {
+		t.Errorf("order terms = %v, want the signed end time, nonce and signature", args[7:10])
+	}
+	if args[10].(common.Address) != UniswapV2RouterAddress {
+		t.Errorf("router = %s, want V2 router", args[10].(common.Address).Hex())
+	}
+
+	// The router pays the settlement, at least the limit, by the order's end
+	parsed, err := abi.JSON(strings.NewReader(routerABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := parsed.Methods["swapExactTokensForTokens"].Inputs.Unpack(args[11].([]byte)[4:])
+	if err != nil {
+		t.Fatalf("Unpack(router call) error = %v", err)
+	}
+	if inner[1].(*big.Int).Int64() != 9500 || inner[3].(common.Address) != settlement || inner[4].(*big.Int).Uint64() != order.EndTime {
+		t.Errorf("router call min %v to %s by %v, want 9500 to the settlement by the order's end", inner[1], inner[3].(common.Address).Hex(), inner[4])
+	}
+}
@@ -0,0 +1,74 @@
+package swap
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+)
+
+var (
+	permitDetailsTypeHash = crypto.Keccak256([]byte("PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"))
+	permitSingleTypeHash  = crypto.Keccak256([]byte("PermitSingle(PermitDetails details,address spender,uint256 sigDeadline)PermitDetails(address token,uint160 amount,uint48 expiration,uint48 nonce)"))
+)
+
+// permitTypes are PermitSingle and the struct it nests, as typed data
+var permitTypes = map[string][]eip712.Field{
+	"PermitSingle": {
+		{Name: "details", Type: "PermitDetails"},
+		{Name: "spender", Type: "address"},
+		{Name: "sigDeadline", Type: "uint256"},
+	},
+	"PermitDetails": {
+		{Name: "token", Type: "address"},
+		{Name: "amount", Type: "uint160"},
+		{Name: "expiration", Type: "uint48"},
+		{Name: "nonce", Type: "uint48"},
+	},
+}
+
+// NewPermit2Domain returns the EIP-712 domain Permit2 checks permits in.
+// Permit2 has no version.
+func NewPermit2Domain(chainID *big.Int, permit2 common.Address) eip712.Domain {
+	return eip712.Domain{
+		Name:              "Permit2",
+		ChainID:           chainID,
+		VerifyingContract: permit2,
+	}
+}
+
+// PermitHash returns the EIP-712 digest an owner signs for permit
+func PermitHash(domain eip712.Domain, permit *entities.Permit2Permit) common.Hash {
+	details := crypto.Keccak256(
+		permitDetailsTypeHash,
+		eip712.Address(permit.Token),
+		eip712.Uint(permit.Amount),
+		eip712.Uint(new(big.Int).SetUint64(permit.Expiration)),
+		eip712.Uint(new(big.Int).SetUint64(permit.Nonce)),
+	)
+	return domain.Digest(crypto.Keccak256(
+		permitSingleTypeHash,
+		details,
+		eip712.Address(permit.Spender),
+		eip712.Uint(new(big.Int).SetUint64(permit.SigDeadline)),
+	))
+}
+
+// PermitTypedData returns permit as the typed data an owner signs with
+// eth_signTypedData_v4
+func PermitTypedData(domain eip712.Domain, permit *entities.Permit2Permit) eip712.TypedData {
+	return domain.TypedData("PermitSingle", permitTypes, map[string]interface{}{
+		"details": map[string]interface{}{
+			"token":      permit.Token.Hex(),
+			"amount":     permit.Amount.String(),
+			"expiration": strconv.FormatUint(permit.Expiration, 10),
+			"nonce":      strconv.FormatUint(permit.Nonce, 10),
+		},
+		"spender":     permit.Spender.Hex(),
+		"sigDeadline": strconv.FormatUint(permit.SigDeadline, 10),
+	})
+}
@@ -0,0 +1,41 @@
+package swap
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestPermitTypedDataMatchesHash(t *testing.T) {
+	domain := NewPermit2Domain(big.NewInt(1), Permit2Address)
+	permit := &entities.Permit2Permit{
+		Token: entities.WETH.Address, Amount: big.NewInt(1e18), Expiration: 1700000600, Nonce: 3,
+		Spender: UniversalRouterAddress, SigDeadline: 1700000000,
+	}
+
+	// Round-trip through JSON as a wallet would receive it
+	data, err := json.Marshal(PermitTypedData(domain, permit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var typed apitypes.TypedData
+	if err := json.Unmarshal(data, &typed); err != nil {
+		t.Fatalf("typed data %s: %v", data, err)
+	}
+	if _, ok := typed.Types["EIP712Domain"]; !ok || typed.Domain.Version != "" {
+		t.Errorf("domain = %+v, want Permit2's, without a version", typed.Domain)
+	}
+
+	want, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := PermitHash(domain, permit); !bytes.Equal(got.Bytes(), want) {
+		t.Errorf("PermitHash() = %x, want %x", got, want)
+	}
+}
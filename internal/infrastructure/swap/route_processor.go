@@ -0,0 +1,273 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// RouteProcessorAddress is Sushi's RouteProcessor4 (Ethereum mainnet)
+var RouteProcessorAddress = common.HexToAddress("0xe43ca1Dee3F0fc1e2df73A0745674545F11A59F5")
+
+// RouteProcessor route commands, each followed by the token it distributes
+const (
+	rpProcessMyERC20   = 1 // The processor's own balance, less one wei
+	rpProcessUserERC20 = 2 // amountIn, pulled from the sender
+	rpProcessNative    = 3 // The processor's gas token balance
+)
+
+// RouteProcessor pool types
+const (
+	rpPoolUniV2 = 0
+	rpPoolUniV3 = 1
+	rpPoolWrap  = 2
+)
+
+// rpNative stands for the gas token in processRoute's token arguments
+var rpNative = common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+
+// processRoute(address tokenIn, uint256 amountIn, address tokenOut,
+// uint256 amountOutMin, address to, bytes route)
+var (
+	rpProcessRouteSelector = crypto.Keccak256([]byte("processRoute(address,uint256,address,uint256,address,bytes)"))[:4]
+	rpProcessRouteArgs     = newArgs("address", "uint256", "address", "uint256", "address", "bytes")
+)
+
+// RouteProcessor encodes quotes on Uniswap V2, Sushiswap and Uniswap V3 as
+// Sushi RouteProcessor routes, so integrators already executing through
+// Sushi's contract can run our routes unchanged. Every intermediate token
+// passes through the processor, which splits it between the hops leaving
+// it by the shares the quote expects; final hops pay the recipient.
+type RouteProcessor struct {
+	address common.Address
+}
+
+func NewRouteProcessor(address common.Address) *RouteProcessor {
+	return &RouteProcessor{address: address}
+}
+
+// Address is the RouteProcessor, which the sender approves for the input
+func (p *RouteProcessor) Address() common.Address {
+	return p.address
+}
+
+// rpHop is one hop with the input the quote expects it to take
+type rpHop struct {
+	hop      entities.Hop
+	amountIn *big.Int
+	last     bool // Ends its route, paying the quote's output
+}
+
+// BuildRoute encodes the quote as a route paying recipient, and the
+// processRoute call that runs it with the quote's minimum output
+func (p *RouteProcessor) BuildRoute(quote *entities.Quote, recipient common.Address) (*entities.ProcessorRoute, error) {
+	routes := make([]*entities.Route, 0, len(quote.SplitRoutes))
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+	}
+	if len(routes) == 0 && quote.BestRoute != nil {
+		routes = append(routes, quote.BestRoute)
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("quote has no route")
+	}
+
+	var hops []rpHop
+	total := new(big.Int)
+	for i, route := range routes {
+		routeHops, err := rpRouteHops(route, quote)
+		if err != nil {
+			return nil, fmt.Errorf("split %d: %w", i, err)
+		}
+		hops = append(hops, routeHops...)
+		total.Add(total, route.AmountIn)
+	}
+	if total.Cmp(quote.AmountIn) != 0 {
+		return nil, fmt.Errorf("splits total %s, quote is for %s", total, quote.AmountIn)
+	}
+
+	var stream []byte
+	if quote.NativeIn {
+		// Wrap msg.value into the processor, then spend it as its own
+		stream = append(stream, rpProcessNative, 1, 0xff, 0xff, rpPoolWrap, 1)
+		stream = append(stream, p.address.Bytes()...)
+		stream = append(stream, quote.TokenIn.Address.Bytes()...)
+	}
+
+	// Tokens go out once every hop bringing them in has run
+	done := map[common.Address]bool{quote.TokenIn.Address: true}
+	order := []common.Address{quote.TokenIn.Address}
+	for len(order) > 0 {
+		token := order[0]
+		order = order[1:]
+		command := byte(rpProcessMyERC20)
+		if token == quote.TokenIn.Address && !quote.NativeIn {
+			command = rpProcessUserERC20
+		}
+		var err error
+		if stream, err = p.distribute(stream, command, token, hops, recipient, quote.NativeOut); err != nil {
+			return nil, err
+		}
+		for _, h := range hops {
+			next := h.hop.TokenOut
+			if h.last || done[next] || !rpReady(next, hops, done) {
+				continue
+			}
+			done[next] = true
+			order = append(order, next)
+		}
+	}
+	for _, h := range hops {
+		if !done[h.hop.TokenIn] {
+			return nil, fmt.Errorf("hops through %s form a cycle", h.hop.TokenIn.Hex())
+		}
+	}
+
+	if quote.NativeOut {
+		// Final hops paid the processor, which unwraps to the recipient
+		stream = append(stream, rpProcessMyERC20)
+		stream = append(stream, quote.TokenOut.Address.Bytes()...)
+		stream = append(stream, 1, 0xff, 0xff, rpPoolWrap, 0)
+		stream = append(stream, recipient.Bytes()...)
+	}
+
+	tokenIn, tokenOut, value := quote.TokenIn.Address, quote.TokenOut.Address, big.NewInt(0)
+	if quote.NativeIn {
+		tokenIn, value = rpNative, new(big.Int).Set(quote.AmountIn)
+	}
+	if quote.NativeOut {
+		tokenOut = rpNative
+	}
+	minAmountOut := quote.MinAmountOut
+	if minAmountOut == nil {
+		minAmountOut = big.NewInt(0)
+	}
+	args, err := rpProcessRouteArgs.Pack(tokenIn, quote.AmountIn, tokenOut, minAmountOut, recipient, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode route: %w", err)
+	}
+	return &entities.ProcessorRoute{
+		Address: p.address,
+		Route:   stream,
+		Transaction: &entities.SwapTransaction{
+			From:  recipient,
+			To:    p.address,
+			Data:  append(append([]byte{}, rpProcessRouteSelector...), args...),
+			Value: value,
+		},
+	}, nil
+}
+
+// distribute appends the command splitting token between the hops that
+// leave it. Each share is the hop's fraction of what the earlier hops
+// left, in 1/65535ths, so the last hop takes the remainder.
+func (p *RouteProcessor) distribute(stream []byte, command byte, token common.Address, hops []rpHop, recipient common.Address, nativeOut bool) ([]byte, error) {
+	var out []rpHop
+	remaining := new(big.Int)
+	for _, h := range hops {
+		if h.hop.TokenIn == token {
+			out = append(out, h)
+			remaining.Add(remaining, h.amountIn)
+		}
+	}
+	if len(out) > 255 {
+		return nil, fmt.Errorf("%d hops leave %s, the processor takes 255", len(out), token.Hex())
+	}
+
+	stream = append(stream, command)
+	stream = append(stream, token.Bytes()...)
+	stream = append(stream, byte(len(out)))
+	for i, h := range out {
+		share := uint64(65535)
+		if i < len(out)-1 && remaining.Sign() > 0 {
+			share = new(big.Int).Div(new(big.Int).Mul(h.amountIn, big.NewInt(65535)), remaining).Uint64()
+		}
+		remaining.Sub(remaining, h.amountIn)
+		stream = append(stream, byte(share>>8), byte(share))
+
+		to := p.address
+		if h.last && !nativeOut {
+			to = recipient
+		}
+		pair := h.hop.Pair
+		zeroForOne := byte(0)
+		if h.hop.TokenIn == pair.Token0.Address {
+			zeroForOne = 1
+		}
+		if pair.DEX == entities.DEXUniswapV3 {
+			stream = append(stream, rpPoolUniV3)
+			stream = append(stream, pair.Address.Bytes()...)
+			stream = append(stream, zeroForOne)
+			stream = append(stream, to.Bytes()...)
+			continue
+		}
+		// V2 fees are in basis points; the processor takes millionths
+		fee := pair.Fee * 100
+		stream = append(stream, rpPoolUniV2)
+		stream = append(stream, pair.Address.Bytes()...)
+		stream = append(stream, zeroForOne)
+		stream = append(stream, to.Bytes()...)
+		stream = append(stream, byte(fee>>16), byte(fee>>8), byte(fee))
+	}
+	return stream, nil
+}
+
+// rpReady reports whether every hop bringing token in starts from a token
+// queued ahead of it
+func rpReady(token common.Address, hops []rpHop, done map[common.Address]bool) bool {
+	for _, h := range hops {
+		if h.hop.TokenOut == token && !done[h.hop.TokenIn] {
+			return false
+		}
+	}
+	return true
+}
+
+// rpRouteHops checks a route runs from the quote's input to its output on
+// pools the processor can swap, and pairs each hop with its expected input
+func rpRouteHops(route *entities.Route, quote *entities.Quote) ([]rpHop, error) {
+	if route == nil || len(route.Hops) == 0 {
+		return nil, fmt.Errorf("route has no hops")
+	}
+	if route.AmountIn == nil || route.AmountIn.Sign() <= 0 {
+		return nil, fmt.Errorf("route amountIn must be positive")
+	}
+
+	hops := make([]rpHop, 0, len(route.Hops))
+	next, amount := quote.TokenIn.Address, route.AmountIn
+	for i, hop := range route.Hops {
+		if hop.TokenIn != next {
+			return nil, fmt.Errorf("hop %d starts from %s, want %s", i, hop.TokenIn.Hex(), next.Hex())
+		}
+		switch hop.Pair.DEX {
+		case entities.DEXUniswapV2, entities.DEXSushiswap, entities.DEXUniswapV3:
+		default:
+			return nil, fmt.Errorf("hop %d: the RouteProcessor route only covers Uniswap and Sushiswap pools, not %s", i, hop.Pair.DEX)
+		}
+		if hop.AmountIn != nil {
+			amount = hop.AmountIn
+		}
+		last := i == len(route.Hops)-1
+		hops = append(hops, rpHop{hop: hop, amountIn: amount, last: last})
+
+		next = hop.TokenOut
+		// The processor spends its whole balance of a token at once, so a
+		// route may not pass back through either end of the quote
+		if !last && (next == quote.TokenIn.Address || next == quote.TokenOut.Address) {
+			return nil, fmt.Errorf("hop %d passes through the quote's own tokens", i)
+		}
+		if hop.AmountOut != nil {
+			amount = hop.AmountOut
+		} else {
+			amount = hop.Pair.GetAmountOut(amount, hop.TokenIn)
+		}
+	}
+	if next != quote.TokenOut.Address {
+		return nil, fmt.Errorf("route ends at %s, want %s", next.Hex(), quote.TokenOut.Address.Hex())
+	}
+	return hops, nil
+}
@@ -0,0 +1,174 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// rpStream reads a RouteProcessor route back for assertions
+type rpStream struct {
+	t    *testing.T
+	data []byte
+}
+
+func (s *rpStream) read(n int) []byte {
+	s.t.Helper()
+	if len(s.data) < n {
+		s.t.Fatalf("route ends %d bytes early", n-len(s.data))
+	}
+	out := s.data[:n]
+	s.data = s.data[n:]
+	return out
+}
+
+func (s *rpStream) byte() byte { return s.read(1)[0] }
+
+func (s *rpStream) uint(n int) uint64 {
+	return new(big.Int).SetBytes(s.read(n)).Uint64()
+}
+
+func (s *rpStream) address() common.Address {
+	return common.BytesToAddress(s.read(20))
+}
+
+func TestRouteProcessorBuildRoute(t *testing.T) {
+	processor := NewRouteProcessor(RouteProcessorAddress)
+	sushiPool := common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	uniPool := common.HexToAddress("0x00000000000000000000000000000000000000a2")
+	v3Pool := common.HexToAddress("0x00000000000000000000000000000000000000a3")
+
+	// Two splits meet at mid, then share one V3 pool to USDC
+	split := func(dex entities.DEXType, pool common.Address, in, mid int64) *entities.Route {
+		route := testRoute(dex, 30, 2)
+		route.AmountIn = big.NewInt(in)
+		route.Hops[0].Pair.Address = pool
+		route.Hops[0].Pair.Token0 = entities.WETH
+		route.Hops[0].AmountIn, route.Hops[0].AmountOut = big.NewInt(in), big.NewInt(mid)
+		route.Hops[1].Pair = entities.Pair{DEX: entities.DEXUniswapV3, Address: v3Pool, Token0: entities.USDC, Fee: 500}
+		route.Hops[1].AmountIn = big.NewInt(mid)
+		return route
+	}
+	quote := &entities.Quote{
+		TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1000),
+		MinAmountOut: big.NewInt(900), ExpiresAt: testDeadline,
+		SplitRoutes: []entities.SplitRoute{
+			{Route: split(entities.DEXSushiswap, sushiPool, 600, 300)},
+			{Route: split(entities.DEXUniswapV2, uniPool, 400, 100)},
+		},
+	}
+
+	rp, err := processor.BuildRoute(quote, testRecipient)
+	if err != nil {
+		t.Fatalf("BuildRoute() error = %v", err)
+	}
+	if rp.Address != RouteProcessorAddress || rp.Transaction.To != RouteProcessorAddress || rp.Transaction.Value.Sign() != 0 {
+		t.Errorf("route processor %s, tx to %s with value %s", rp.Address.Hex(), rp.Transaction.To.Hex(), rp.Transaction.Value)
+	}
+
+	if !bytes.Equal(rp.Transaction.Data[:4], rpProcessRouteSelector) {
+		t.Fatalf("selector = %x, want processRoute", rp.Transaction.Data[:4])
+	}
+	args, err := rpProcessRouteArgs.Unpack(rp.Transaction.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[0].(common.Address) != entities.WETH.Address || args[1].(*big.Int).Cmp(quote.AmountIn) != 0 ||
+		args[2].(common.Address) != entities.USDC.Address || args[3].(*big.Int).Cmp(quote.MinAmountOut) != 0 ||
+		args[4].(common.Address) != testRecipient || !bytes.Equal(args[5].([]byte), rp.Route) {
+		t.Errorf("processRoute args = %v", args)
+	}
+
+	s := &rpStream{t: t, data: rp.Route}
+	// The sender's WETH splits 600/400 between the V2 pools, which pay the processor
+	if s.byte() != rpProcessUserERC20 || s.address() != entities.WETH.Address || s.byte() != 2 {
+		t.Fatal("route doesn't start by splitting the sender's WETH in two")
+	}
+	for _, want := range []struct {
+		share uint64
+		pool  common.Address
+	}{{39321, sushiPool}, {65535, uniPool}} {
+		if share, poolType, pool := s.uint(2), s.byte(), s.address(); share != want.share || poolType != rpPoolUniV2 || pool != want.pool {
+			t.Errorf("WETH leg = share %d, type %d, pool %s", share, poolType, pool.Hex())
+		}
+		if zeroForOne, to, fee := s.byte(), s.address(), s.uint(3); zeroForOne != 1 || to != RouteProcessorAddress || fee != 3000 {
+			t.Errorf("WETH leg = direction %d, to %s, fee %d", zeroForOne, to.Hex(), fee)
+		}
+	}
+	// Both splits' mid goes through the V3 pool in two legs, 300/100, to the recipient
+	if s.byte() != rpProcessMyERC20 || s.address() != testMid || s.byte() != 2 {
+		t.Fatal("route doesn't then split the processor's mid in two")
+	}
+	for _, share := range []uint64{49151, 65535} {
+		if got, poolType, pool, zeroForOne, to := s.uint(2), s.byte(), s.address(), s.byte(), s.address(); got != share ||
+			poolType != rpPoolUniV3 || pool != v3Pool || zeroForOne != 0 || to != testRecipient {
+			t.Errorf("mid leg = share %d, type %d, pool %s, direction %d, to %s", got, poolType, pool.Hex(), zeroForOne, to.Hex())
+		}
+	}
+	if len(s.data) > 0 {
+		t.Errorf("%d bytes left over", len(s.data))
+	}
+}
+
+func TestRouteProcessorNative(t *testing.T) {
+	processor := NewRouteProcessor(RouteProcessorAddress)
+
+	// USDC -> WETH on one V3 pool, paid out as ETH
+	route := &entities.Route{AmountIn: big.NewInt(1e6), Hops: []entities.Hop{{
+		Pair:    entities.Pair{DEX: entities.DEXUniswapV3, Token0: entities.USDC, Fee: 500},
+		TokenIn: entities.USDC.Address, TokenOut: entities.WETH.Address,
+	}}}
+	quote := &entities.Quote{
+		TokenIn: entities.USDC, TokenOut: entities.WETH, AmountIn: big.NewInt(1e6),
+		NativeOut: true, BestRoute: route,
+	}
+	rp, err := processor.BuildRoute(quote, testRecipient)
+	if err != nil {
+		t.Fatalf("BuildRoute() error = %v", err)
+	}
+	args, err := rpProcessRouteArgs.Unpack(rp.Transaction.Data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if args[2].(common.Address) != rpNative || args[3].(*big.Int).Sign() != 0 {
+		t.Errorf("tokenOut = %s, amountOutMin %s", args[2].(common.Address).Hex(), args[3])
+	}
+
+	s := &rpStream{t: t, data: rp.Route}
+	s.read(1 + 20 + 1 + 2 + 1 + 20 + 1)
+	if to := s.address(); to != RouteProcessorAddress {
+		t.Errorf("swap pays %s, want the processor", to.Hex())
+	}
+	// The processor unwraps its WETH to the recipient
+	if s.byte() != rpProcessMyERC20 || s.address() != entities.WETH.Address || s.byte() != 1 || s.uint(2) != 65535 ||
+		s.byte() != rpPoolWrap || s.byte() != 0 || s.address() != testRecipient {
+		t.Error("route doesn't end by unwrapping WETH to the recipient")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(q *entities.Quote)
+		want   string
+	}{
+		{"other venue", func(q *entities.Quote) { q.BestRoute.Hops[0].Pair.DEX = entities.DEXCurve }, "only covers"},
+		{"wrong end", func(q *entities.Quote) { q.TokenOut = entities.DAI }, "route ends at"},
+		{"short split", func(q *entities.Quote) { q.AmountIn = big.NewInt(2e6) }, "splits total"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := *quote
+			r := *route
+			r.Hops = append([]entities.Hop(nil), route.Hops...)
+			q.BestRoute = &r
+			tt.modify(&q)
+			if _, err := processor.BuildRoute(&q, testRecipient); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("BuildRoute() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
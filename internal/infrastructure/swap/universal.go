@@ -0,0 +1,262 @@
+package swap
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// Uniswap's Universal Router and the Permit2 contract it pulls tokens
+// through (Ethereum mainnet)
+var (
+	UniversalRouterAddress = common.HexToAddress("0x66a9893cC07D91D95644AEDD05D03f95e1dBA8Af")
+	Permit2Address         = common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+)
+
+// Universal Router commands, one byte each in execute's commands
+const (
+	urV3SwapExactIn = 0x00
+	urSweep         = 0x04
+	urV2SwapExactIn = 0x08
+	urPermit2Permit = 0x0a
+	urWrapETH       = 0x0b
+	urUnwrapWETH    = 0x0c
+)
+
+var (
+	// urAddressThis is the recipient the Universal Router reads as itself
+	urAddressThis = common.BigToAddress(big.NewInt(2))
+	// urContractBalance as an amountIn swaps the router's whole balance of
+	// the input, which is how one segment's output feeds the next
+	urContractBalance = new(big.Int).Lsh(big.NewInt(1), 255)
+)
+
+// execute(bytes commands, bytes[] inputs, uint256 deadline)
+var urExecuteSelector = crypto.Keccak256([]byte("execute(bytes,bytes[],uint256)"))[:4]
+
+// Inputs of each command, ABI-encoded as their own arguments
+var (
+	urExecuteArgs = newArgs("bytes", "bytes[]", "uint256")
+	urV2SwapArgs  = newArgs("address", "uint256", "uint256", "address[]", "bool")
+	urV3SwapArgs  = newArgs("address", "uint256", "uint256", "bytes", "bool")
+	urSweepArgs   = newArgs("address", "address", "uint256")
+	urWrapArgs    = newArgs("address", "uint256")
+	// (PermitSingle, bytes): the permit is a static tuple, so its fields
+	// pack flat ahead of the signature
+	urPermitArgs = newArgs("address", "uint160", "uint48", "uint48", "address", "uint256", "bytes")
+)
+
+func newArgs(types ...string) abi.Arguments {
+	args := make(abi.Arguments, len(types))
+	for i, name := range types {
+		t, err := abi.NewType(name, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		args[i] = abi.Argument{Type: t}
+	}
+	return args
+}
+
+// UniversalRouter builds quotes whose hops are all on Uniswap V2 and V3 as
+// one execute call on the Universal Router. Each run of hops on one venue
+// is one swap command; splits and venue changes pass through the router's
+// own balance, and the router checks the total output once at the end. It
+// wraps and unwraps the gas token itself, and pulls the input through
+// Permit2, so one approval of Permit2 covers every venue.
+type UniversalRouter struct {
+	address common.Address
+	permit2 common.Address
+}
+
+func NewUniversalRouter(address, permit2 common.Address) *UniversalRouter {
+	return &UniversalRouter{address: address, permit2: permit2}
+}
+
+// Address is the Universal Router, which the sender allows on Permit2
+func (u *UniversalRouter) Address() common.Address {
+	return u.address
+}
+
+// Permit2 is the contract the sender approves to pull the input
+func (u *UniversalRouter) Permit2() common.Address {
+	return u.permit2
+}
+
+// urCommands collects commands and their inputs in order
+type urCommands struct {
+	commands []byte
+	inputs   [][]byte
+}
+
+func (c *urCommands) add(command byte, args abi.Arguments, values ...interface{}) error {
+	input, err := args.Pack(values...)
+	if err != nil {
+		return fmt.Errorf("command %#x: %w", command, err)
+	}
+	c.commands = append(c.commands, command)
+	c.inputs = append(c.inputs, input)
+	return nil
+}
+
+// BuildExecution encodes the quote as one execute call, pulling the input
+// through an existing Permit2 allowance
+func (u *UniversalRouter) BuildExecution(quote *entities.Quote, recipient common.Address) (*entities.SwapTransaction, error) {
+	return u.BuildExecutionWithPermit(quote, recipient, nil)
+}
+
+// BuildExecutionWithPermit is BuildExecution with a signed Permit2
+// allowance run ahead of the swaps, sparing the sender Permit2's approval
+// transaction. permit may be nil.
+func (u *UniversalRouter) BuildExecutionWithPermit(quote *entities.Quote, recipient common.Address, permit *entities.Permit2Permit) (*entities.SwapTransaction, error) {
+	routes := make([]*entities.Route, 0, len(quote.SplitRoutes))
+	for _, split := range quote.SplitRoutes {
+		routes = append(routes, split.Route)
+	}
+	if len(routes) == 0 && quote.BestRoute != nil {
+		routes = append(routes, quote.BestRoute)
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("quote has no route")
+	}
+	if quote.NativeIn && quote.NativeOut {
+		return nil, fmt.Errorf("a quote can't both start and end in the gas token")
+	}
+	if quote.NativeIn && permit != nil {
+		return nil, fmt.Errorf("a gas token input needs no permit")
+	}
+
+	minAmountOut := quote.MinAmountOut
+	if minAmountOut == nil {
+		minAmountOut = big.NewInt(0)
+	}
+	deadline := quote.ExpiresAt
+	if deadline.IsZero() {
+		deadline = time.Now().Add(DefaultDeadline)
+	}
+
+	var c urCommands
+	if permit != nil {
+		err := c.add(urPermit2Permit, urPermitArgs, permit.Token, permit.Amount, new(big.Int).SetUint64(permit.Expiration),
+			new(big.Int).SetUint64(permit.Nonce), permit.Spender, new(big.Int).SetUint64(permit.SigDeadline), permit.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode permit: %w", err)
+		}
+	}
+	if quote.NativeIn {
+		if err := c.add(urWrapETH, urWrapArgs, urAddressThis, quote.AmountIn); err != nil {
+			return nil, err
+		}
+	}
+
+	// A single route pays recipient directly; splits and gas token outputs
+	// gather in the router, which checks the total once
+	direct := len(routes) == 1 && !quote.NativeOut
+	total := new(big.Int)
+	for i, route := range routes {
+		if err := u.encodeRoute(&c, route, quote, recipient, minAmountOut, direct); err != nil {
+			return nil, fmt.Errorf("split %d: %w", i, err)
+		}
+		total.Add(total, route.AmountIn)
+	}
+	if total.Cmp(quote.AmountIn) != 0 {
+		return nil, fmt.Errorf("splits total %s, quote is for %s", total, quote.AmountIn)
+	}
+
+	switch {
+	case quote.NativeOut:
+		if err := c.add(urUnwrapWETH, urWrapArgs, recipient, minAmountOut); err != nil {
+			return nil, err
+		}
+	case !direct:
+		if err := c.add(urSweep, urSweepArgs, quote.TokenOut.Address, recipient, minAmountOut); err != nil {
+			return nil, err
+		}
+	}
+
+	args, err := urExecuteArgs.Pack(c.commands, c.inputs, big.NewInt(deadline.Unix()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode execution: %w", err)
+	}
+	value := big.NewInt(0)
+	if quote.NativeIn {
+		value = new(big.Int).Set(quote.AmountIn)
+	}
+	return &entities.SwapTransaction{
+		From:  recipient,
+		To:    u.address,
+		Data:  append(append([]byte{}, urExecuteSelector...), args...),
+		Value: value,
+	}, nil
+}
+
+// encodeRoute adds one swap command per run of hops on the same venue. The
+// first pulls route.AmountIn from the sender, or from the router when it
+// wrapped the gas token; later ones swap what the previous one left in the
+// router.
+func (u *UniversalRouter) encodeRoute(c *urCommands, route *entities.Route, quote *entities.Quote, recipient common.Address, minAmountOut *big.Int, direct bool) error {
+	if route == nil || len(route.Hops) == 0 {
+		return fmt.Errorf("route has no hops")
+	}
+	if route.AmountIn == nil || route.AmountIn.Sign() <= 0 {
+		return fmt.Errorf("route amountIn must be positive")
+	}
+
+	next := quote.TokenIn.Address
+	for i, hop := range route.Hops {
+		if hop.TokenIn != next {
+			return fmt.Errorf("hop %d starts from %s, want %s", i, hop.TokenIn.Hex(), next.Hex())
+		}
+		if hop.Pair.DEX != entities.DEXUniswapV2 && hop.Pair.DEX != entities.DEXUniswapV3 {
+			return fmt.Errorf("hop %d: the Universal Router only swaps on Uniswap pools, not %s", i, hop.Pair.DEX)
+		}
+		next = hop.TokenOut
+		// Balances between segments are the router's whole holding, so a
+		// route may not pass through either end of the quote
+		if i < len(route.Hops)-1 && (next == quote.TokenIn.Address || next == quote.TokenOut.Address) {
+			return fmt.Errorf("hop %d passes through the quote's own tokens", i)
+		}
+	}
+	if next != quote.TokenOut.Address {
+		return fmt.Errorf("route ends at %s, want %s", next.Hex(), quote.TokenOut.Address.Hex())
+	}
+
+	for start := 0; start < len(route.Hops); {
+		end := start + 1
+		for end < len(route.Hops) && route.Hops[end].Pair.DEX == route.Hops[start].Pair.DEX {
+			end++
+		}
+		segment := route.Hops[start:end]
+
+		amountIn, payerIsUser := urContractBalance, false
+		if start == 0 {
+			amountIn, payerIsUser = route.AmountIn, !quote.NativeIn
+		}
+		to, amountOutMin := urAddressThis, big.NewInt(0)
+		if end == len(route.Hops) && direct {
+			to, amountOutMin = recipient, minAmountOut
+		}
+
+		var err error
+		if segment[0].Pair.DEX == entities.DEXUniswapV2 {
+			path := []common.Address{segment[0].TokenIn}
+			for _, hop := range segment {
+				path = append(path, hop.TokenOut)
+			}
+			err = c.add(urV2SwapExactIn, urV2SwapArgs, to, amountIn, amountOutMin, path, payerIsUser)
+		} else {
+			err = c.add(urV3SwapExactIn, urV3SwapArgs, to, amountIn, amountOutMin, encodeV3Path(segment), payerIsUser)
+		}
+		if err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
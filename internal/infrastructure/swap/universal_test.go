@@ -0,0 +1,150 @@
+package swap
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// decodeExecute splits an execute call into its commands, their decoded
+// inputs and the deadline
+func decodeExecute(t *testing.T, data []byte) ([]byte, [][]interface{}, int64) {
+	t.Helper()
+	if !bytes.Equal(data[:4], urExecuteSelector) {
+		t.Fatalf("selector = %x, want execute", data[:4])
+	}
+	args, err := urExecuteArgs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	commands, inputs := args[0].([]byte), args[1].([][]byte)
+	if len(commands) != len(inputs) {
+		t.Fatalf("%d commands with %d inputs", len(commands), len(inputs))
+	}
+
+	decoded := make([][]interface{}, len(commands))
+	for i, command := range commands {
+		argsFor := map[byte]func() ([]interface{}, error){
+			urV2SwapExactIn: func() ([]interface{}, error) { return urV2SwapArgs.Unpack(inputs[i]) },
+			urV3SwapExactIn: func() ([]interface{}, error) { return urV3SwapArgs.Unpack(inputs[i]) },
+			urSweep:         func() ([]interface{}, error) { return urSweepArgs.Unpack(inputs[i]) },
+			urWrapETH:       func() ([]interface{}, error) { return urWrapArgs.Unpack(inputs[i]) },
+			urUnwrapWETH:    func() ([]interface{}, error) { return urWrapArgs.Unpack(inputs[i]) },
+			urPermit2Permit: func() ([]interface{}, error) { return urPermitArgs.Unpack(inputs[i]) },
+		}[command]
+		if argsFor == nil {
+			t.Fatalf("unexpected command %#x", command)
+		}
+		if decoded[i], err = argsFor(); err != nil {
+			t.Fatalf("command %d: %v", i, err)
+		}
+	}
+	return commands, decoded, args[2].(*big.Int).Int64()
+}
+
+func TestUniversalRouterBuildExecution(t *testing.T) {
+	router := NewUniversalRouter(UniversalRouterAddress, Permit2Address)
+
+	// WETH -> mid on V2, then mid -> USDC on V3
+	mixed := testRoute(entities.DEXUniswapV2, 30, 2)
+	mixed.Hops[1].Pair = entities.Pair{DEX: entities.DEXUniswapV3, Fee: 500}
+	quote := &entities.Quote{
+		TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1e18),
+		MinAmountOut: big.NewInt(1000), BestRoute: mixed, ExpiresAt: testDeadline,
+	}
+
+	tx, err := router.BuildExecution(quote, testRecipient)
+	if err != nil {
+		t.Fatalf("BuildExecution() error = %v", err)
+	}
+	if tx.To != UniversalRouterAddress || tx.Value.Sign() != 0 {
+		t.Errorf("tx to %s with value %s", tx.To.Hex(), tx.Value)
+	}
+	commands, inputs, deadline := decodeExecute(t, tx.Data)
+	if !bytes.Equal(commands, []byte{urV2SwapExactIn, urV3SwapExactIn}) || deadline != testDeadline.Unix() {
+		t.Fatalf("commands = %x, deadline %d", commands, deadline)
+	}
+	// The V2 leg pulls from the sender and leaves mid in the router
+	v2 := inputs[0]
+	if v2[0].(common.Address) != urAddressThis || v2[1].(*big.Int).Cmp(quote.AmountIn) != 0 || !v2[4].(bool) {
+		t.Errorf("V2 leg = %v", v2)
+	}
+	if path := v2[3].([]common.Address); len(path) != 2 || path[1] != testMid {
+		t.Errorf("V2 path = %v", path)
+	}
+	// The V3 leg swaps the router's mid and pays the recipient at least the minimum
+	v3 := inputs[1]
+	if v3[0].(common.Address) != testRecipient || v3[1].(*big.Int).Cmp(urContractBalance) != 0 ||
+		v3[2].(*big.Int).Int64() != 1000 || v3[4].(bool) {
+		t.Errorf("V3 leg = %v", v3)
+	}
+	if path := v3[3].([]byte); !bytes.Equal(path, encodeV3Path(mixed.Hops[1:])) {
+		t.Errorf("V3 path = %x", path)
+	}
+
+	// Splits paid in ETH: wrap, both legs spend the router's WETH, sweep the total
+	half := big.NewInt(5e17)
+	v2Leg, v3Leg := testRoute(entities.DEXUniswapV2, 30, 2), testRoute(entities.DEXUniswapV3, 3000, 2)
+	v2Leg.AmountIn, v3Leg.AmountIn = half, half
+	split := &entities.Quote{
+		TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1e18), MinAmountOut: big.NewInt(2000),
+		SplitRoutes: []entities.SplitRoute{{Route: v2Leg, AmountIn: half}, {Route: v3Leg, AmountIn: half}},
+		NativeIn:    true, ExpiresAt: testDeadline,
+	}
+	tx, err = router.BuildExecution(split, testRecipient)
+	if err != nil {
+		t.Fatalf("split BuildExecution() error = %v", err)
+	}
+	if tx.Value.Cmp(split.AmountIn) != 0 {
+		t.Errorf("value = %s, want the whole input", tx.Value)
+	}
+	commands, inputs, _ = decodeExecute(t, tx.Data)
+	if !bytes.Equal(commands, []byte{urWrapETH, urV2SwapExactIn, urV3SwapExactIn, urSweep}) {
+		t.Fatalf("split commands = %x", commands)
+	}
+	if inputs[1][4].(bool) || inputs[2][4].(bool) || inputs[1][1].(*big.Int).Cmp(half) != 0 {
+		t.Errorf("split legs must spend the wrapped ETH: %v, %v", inputs[1], inputs[2])
+	}
+	if sweep := inputs[3]; sweep[0].(common.Address) != entities.USDC.Address || sweep[1].(common.Address) != testRecipient || sweep[2].(*big.Int).Int64() != 2000 {
+		t.Errorf("sweep = %v", sweep)
+	}
+
+	// A signed permit runs first
+	permit := &entities.Permit2Permit{
+		Token: entities.WETH.Address, Amount: big.NewInt(1e18), Expiration: 1700000600, Nonce: 3,
+		Spender: UniversalRouterAddress, SigDeadline: 1700000000, Signature: bytes.Repeat([]byte{7}, 65),
+	}
+	tx, err = router.BuildExecutionWithPermit(quote, testRecipient, permit)
+	if err != nil {
+		t.Fatalf("BuildExecutionWithPermit() error = %v", err)
+	}
+	commands, inputs, _ = decodeExecute(t, tx.Data)
+	if commands[0] != urPermit2Permit || inputs[0][4].(common.Address) != UniversalRouterAddress || !bytes.Equal(inputs[0][6].([]byte), permit.Signature) {
+		t.Errorf("permit = %x %v", commands, inputs[0])
+	}
+}
+
+func TestUniversalRouterRejects(t *testing.T) {
+	router := NewUniversalRouter(UniversalRouterAddress, Permit2Address)
+	tests := []struct {
+		name  string
+		route *entities.Route
+		want  string
+	}{
+		{"sushiswap hop", testRoute(entities.DEXSushiswap, 30, 2), "only swaps on Uniswap pools"},
+		{"route ends early", testRoute(entities.DEXUniswapV2, 30, 1), "route ends at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := &entities.Quote{TokenIn: entities.WETH, TokenOut: entities.USDC, AmountIn: big.NewInt(1e18), BestRoute: tt.route}
+			if _, err := router.BuildExecution(quote, testRecipient); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("BuildExecution() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
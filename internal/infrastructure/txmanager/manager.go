@@ -0,0 +1,383 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// ErrUnknownTx is returned for hashes not sent through this manager
+var ErrUnknownTx = errors.New("transaction not managed")
+
+// Backend is the node access needed to send and track transactions
+type Backend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Signer signs transactions for the managed account
+type Signer interface {
+	Address() common.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// Config controls replacement and confirmation behaviour
+type Config struct {
+	Confirmations    uint64        // Blocks after inclusion before a tx is final
+	MaxResubmissions int           // Fee bumps after an underpriced rejection
+	FeeBumpPercent   int64         // Multiplier for bumped fees; nodes require >= 110
+	PollInterval     time.Duration // Receipt polling period for Run
+}
+
+// DefaultConfig waits three blocks and bumps fees by 25% up to three times
+func DefaultConfig() Config {
+	return Config{
+		Confirmations:    3,
+		MaxResubmissions: 3,
+		FeeBumpPercent:   125,
+		PollInterval:     12 * time.Second,
+	}
+}
+
+// Request describes a transaction to send; the manager assigns the nonce
+type Request struct {
+	To        common.Address
+	Data      []byte
+	Value     *big.Int
+	Gas       uint64
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Tx is the manager's view of one nonce. Hash is the latest broadcast
+// attempt; Replaced holds earlier attempts the node accepted for the same
+// nonce.
+type Tx struct {
+	Nonce         uint64
+	Hash          common.Hash
+	Replaced      []common.Hash
+	Status        entities.TxStatus
+	BlockNumber   uint64
+	BlockHash     common.Hash
+	Confirmations uint64
+	GasUsed       uint64
+	SubmittedAt   time.Time
+
+	request Request
+}
+
+// Manager allocates nonces, replaces underpriced or stuck transactions,
+// tracks confirmations and detects reorgs for a single account
+type Manager struct {
+	backend Backend
+	signer  Signer
+	chainID *big.Int
+	config  Config
+
+	mu        sync.Mutex
+	nextNonce *uint64
+	txs       map[uint64]*Tx
+	byHash    map[common.Hash]uint64
+	reorgs    uint64
+}
+
+func New(backend Backend, signer Signer, chainID *big.Int, config Config) *Manager {
+	return &Manager{
+		backend: backend,
+		signer:  signer,
+		chainID: chainID,
+		config:  config,
+		txs:     make(map[uint64]*Tx),
+		byHash:  make(map[common.Hash]uint64),
+	}
+}
+
+// Address returns the managed account
+func (m *Manager) Address() common.Address {
+	return m.signer.Address()
+}
+
+// Send allocates a nonce and broadcasts the request, bumping fees and
+// resubmitting when the node rejects the transaction as underpriced
+func (m *Manager) Send(ctx context.Context, req Request) (*Tx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonce, err := m.allocateNonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &Tx{
+		Nonce:   nonce,
+		Status:  entities.TxPending,
+		request: req,
+	}
+
+	if err := m.broadcast(ctx, tx); err != nil {
+		if isNonceTooLow(err) {
+			// Someone else used the nonce; resync from the node next time
+			m.nextNonce = nil
+		}
+		return nil, err
+	}
+
+	next := nonce + 1
+	m.nextNonce = &next
+	m.txs[nonce] = tx
+
+	snapshot := *tx
+	return &snapshot, nil
+}
+
+// Bump replaces a pending transaction with the same nonce at higher fees
+func (m *Manager) Bump(ctx context.Context, hash common.Hash) (*Tx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, ok := m.lookup(hash)
+	if !ok {
+		return nil, ErrUnknownTx
+	}
+	if tx.Status != entities.TxPending {
+		return nil, fmt.Errorf("transaction is %s, only pending transactions can be replaced", tx.Status)
+	}
+
+	previous := *tx
+	tx.request.GasTipCap = m.bumpFee(tx.request.GasTipCap)
+	tx.request.GasFeeCap = m.bumpFee(tx.request.GasFeeCap)
+	if err := m.broadcast(ctx, tx); err != nil {
+		// Keep tracking the attempt that is still in the mempool
+		tx.Hash, tx.request, tx.Replaced = previous.Hash, previous.request, previous.Replaced
+		return nil, err
+	}
+	tx.Replaced = append(tx.Replaced, previous.Hash)
+
+	snapshot := *tx
+	return &snapshot, nil
+}
+
+// Get returns the tracked state for any attempt hash of a managed nonce
+func (m *Manager) Get(hash common.Hash) (*Tx, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, ok := m.lookup(hash)
+	if !ok {
+		return nil, ErrUnknownTx
+	}
+	snapshot := *tx
+	return &snapshot, nil
+}
+
+// Reorgs returns how many times a mined transaction left the canonical chain
+func (m *Manager) Reorgs() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reorgs
+}
+
+// Run polls receipts until ctx is cancelled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Poll(ctx); err != nil {
+				log.Printf("txmanager: poll failed: %v", err)
+			}
+		}
+	}
+}
+
+// pollTarget is a non-final nonce and its attempt hashes, latest first
+type pollTarget struct {
+	nonce  uint64
+	hashes []common.Hash
+}
+
+// Poll refreshes every non-final transaction. A mined transaction whose
+// receipt disappears or moves to a different block was reorged out and
+// returns to pending. Receipts are fetched without holding m.mu, and a
+// failed lookup only skips its own nonce.
+func (m *Manager) Poll(ctx context.Context) error {
+	head, err := m.backend.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	m.mu.Lock()
+	var targets []pollTarget
+	for nonce, tx := range m.txs {
+		if !tx.final() {
+			targets = append(targets, pollTarget{nonce: nonce, hashes: append([]common.Hash{tx.Hash}, tx.Replaced...)})
+		}
+	}
+	m.mu.Unlock()
+
+	var errs []error
+	receipts := make(map[uint64]*types.Receipt, len(targets))
+	for _, target := range targets {
+		receipt, err := m.findReceipt(ctx, target.hashes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("nonce %d: %w", target.nonce, err))
+			continue
+		}
+		receipts[target.nonce] = receipt
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for nonce, receipt := range receipts {
+		if tx, ok := m.txs[nonce]; ok && !tx.final() {
+			m.apply(tx, receipt, head)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply moves tx to the state its receipt, or lack of one, shows at head.
+// Callers hold m.mu.
+func (m *Manager) apply(tx *Tx, receipt *types.Receipt, head uint64) {
+	if receipt == nil {
+		if tx.Status == entities.TxMined {
+			m.reorgs++
+			tx.Status = entities.TxPending
+			tx.BlockNumber, tx.BlockHash, tx.Confirmations = 0, common.Hash{}, 0
+		}
+		return
+	}
+
+	if tx.Status == entities.TxMined && receipt.BlockHash != tx.BlockHash {
+		m.reorgs++
+	}
+
+	// The receipt may belong to an earlier attempt that won the race
+	tx.Hash = receipt.TxHash
+	tx.BlockNumber = receipt.BlockNumber.Uint64()
+	tx.BlockHash = receipt.BlockHash
+	tx.GasUsed = receipt.GasUsed
+	tx.Confirmations = 0
+	if head >= tx.BlockNumber {
+		tx.Confirmations = head - tx.BlockNumber + 1
+	}
+
+	switch {
+	case receipt.Status != types.ReceiptStatusSuccessful:
+		tx.Status = entities.TxFailed
+	case tx.Confirmations >= m.config.Confirmations:
+		tx.Status = entities.TxConfirmed
+	default:
+		tx.Status = entities.TxMined
+	}
+}
+
+// final reports whether tx no longer needs polling
+func (tx *Tx) final() bool {
+	return tx.Status == entities.TxConfirmed || tx.Status == entities.TxFailed
+}
+
+// findReceipt checks the attempts in order, returning nil when none is mined
+func (m *Manager) findReceipt(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
+	for _, hash := range hashes {
+		receipt, err := m.backend.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("failed to get receipt: %w", err)
+		}
+	}
+	return nil, nil
+}
+
+// broadcast signs and sends tx.request at tx.Nonce, setting tx.Hash to the
+// attempt the node accepted. Underpriced attempts never reached the mempool,
+// so they aren't recorded. Callers hold m.mu.
+func (m *Manager) broadcast(ctx context.Context, tx *Tx) error {
+	for attempt := 0; ; attempt++ {
+		to := tx.request.To
+		signed, err := m.signer.SignTx(ctx, types.NewTx(&types.DynamicFeeTx{
+			ChainID:   m.chainID,
+			Nonce:     tx.Nonce,
+			GasTipCap: tx.request.GasTipCap,
+			GasFeeCap: tx.request.GasFeeCap,
+			Gas:       tx.request.Gas,
+			To:        &to,
+			Value:     tx.request.Value,
+			Data:      tx.request.Data,
+		}), m.chainID)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
+
+		err = m.backend.SendTransaction(ctx, signed)
+		if err == nil {
+			tx.Hash = signed.Hash()
+			tx.SubmittedAt = time.Now()
+			m.byHash[tx.Hash] = tx.Nonce
+			return nil
+		}
+
+		if !isUnderpriced(err) || attempt >= m.config.MaxResubmissions {
+			return fmt.Errorf("failed to broadcast transaction: %w", err)
+		}
+
+		tx.request.GasTipCap = m.bumpFee(tx.request.GasTipCap)
+		tx.request.GasFeeCap = m.bumpFee(tx.request.GasFeeCap)
+	}
+}
+
+// allocateNonce returns the next nonce, syncing with the node's pending
+// nonce whenever it is ahead of the local counter. Callers hold m.mu.
+func (m *Manager) allocateNonce(ctx context.Context) (uint64, error) {
+	pending, err := m.backend.PendingNonceAt(ctx, m.signer.Address())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	if m.nextNonce != nil && *m.nextNonce > pending {
+		return *m.nextNonce, nil
+	}
+	return pending, nil
+}
+
+// lookup resolves any attempt hash to its nonce entry. Callers hold m.mu.
+func (m *Manager) lookup(hash common.Hash) (*Tx, bool) {
+	nonce, ok := m.byHash[hash]
+	if !ok {
+		return nil, false
+	}
+	tx, ok := m.txs[nonce]
+	return tx, ok
+}
+
+func (m *Manager) bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(m.config.FeeBumpPercent))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+func isUnderpriced(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "underpriced") || strings.Contains(msg, "less than block base fee")
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
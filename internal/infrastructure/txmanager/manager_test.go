@@ -0,0 +1,233 @@
+package txmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+type fakeSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *fakeSigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *fakeSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+type fakeBackend struct {
+	nonce       uint64
+	rejections  int
+	sent        []*types.Transaction
+	receipts    map[common.Hash]*types.Receipt
+	receiptErrs map[common.Hash]error
+	onReceipt   func() // Called on every receipt lookup
+	blockNumber uint64
+}
+
+func (b *fakeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return b.nonce, nil
+}
+
+func (b *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.rejections > 0 {
+		b.rejections--
+		return errors.New("replacement transaction underpriced")
+	}
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *fakeBackend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if b.onReceipt != nil {
+		b.onReceipt()
+	}
+	if err, ok := b.receiptErrs[hash]; ok {
+		return nil, err
+	}
+	if receipt, ok := b.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *fakeBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.blockNumber, nil
+}
+
+func newTestManager(t *testing.T, backend *fakeBackend) *Manager {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(backend, &fakeSigner{key: key}, big.NewInt(1), DefaultConfig())
+}
+
+func testRequest() Request {
+	return Request{
+		To:        common.HexToAddress("0x00000000000000000000000000000000000000aa"),
+		Value:     big.NewInt(0),
+		Gas:       100000,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+	}
+}
+
+func TestSendBumpsUnderpriced(t *testing.T) {
+	backend := &fakeBackend{nonce: 7, rejections: 1}
+	m := newTestManager(t, backend)
+
+	tx, err := m.Send(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if tx.Nonce != 7 || len(tx.Replaced) != 0 {
+		t.Errorf("nonce = %d, replaced = %d, want 7 and 0", tx.Nonce, len(tx.Replaced))
+	}
+	if got := backend.sent[0].GasTipCap().Int64(); got != 125 {
+		t.Errorf("tip = %d, want 125", got)
+	}
+
+	// The next send uses the local counter even though the node lags
+	next, err := m.Send(context.Background(), testRequest())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if next.Nonce != 8 {
+		t.Errorf("second nonce = %d, want 8", next.Nonce)
+	}
+
+	// The rejected attempt never reached the mempool, so it isn't tracked
+	to := testRequest().To
+	rejected, _ := m.signer.SignTx(context.Background(), types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 7, GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000),
+		Gas: 100000, To: &to, Value: big.NewInt(0),
+	}), big.NewInt(1))
+	if _, err := m.Get(rejected.Hash()); !errors.Is(err, ErrUnknownTx) {
+		t.Errorf("Get(rejected) error = %v, want ErrUnknownTx", err)
+	}
+}
+
+func TestBump(t *testing.T) {
+	backend := &fakeBackend{}
+	m := newTestManager(t, backend)
+	ctx := context.Background()
+
+	tx, err := m.Send(ctx, testRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed replacement leaves the live attempt as it was
+	backend.rejections = DefaultConfig().MaxResubmissions + 1
+	if _, err := m.Bump(ctx, tx.Hash); err == nil {
+		t.Fatal("Bump() succeeded with every attempt rejected")
+	}
+	got, err := m.Get(tx.Hash)
+	if err != nil || got.Hash != tx.Hash || len(got.Replaced) != 0 {
+		t.Fatalf("after failed Bump: %+v, %v; want the original attempt and nothing replaced", got, err)
+	}
+
+	bumped, err := m.Bump(ctx, tx.Hash)
+	if err != nil {
+		t.Fatalf("Bump() error = %v", err)
+	}
+	if bumped.Hash == tx.Hash || len(bumped.Replaced) != 1 || bumped.Replaced[0] != tx.Hash {
+		t.Errorf("bumped = %s replacing %v, want a new hash replacing %s", bumped.Hash.Hex(), bumped.Replaced, tx.Hash.Hex())
+	}
+	if got, err := m.Get(tx.Hash); err != nil || got.Hash != bumped.Hash {
+		t.Errorf("Get(replaced) = %v, %v, want the latest attempt", got, err)
+	}
+}
+
+func TestPollSkipsFailedLookups(t *testing.T) {
+	backend := &fakeBackend{receipts: make(map[common.Hash]*types.Receipt), receiptErrs: make(map[common.Hash]error), blockNumber: 100}
+	m := newTestManager(t, backend)
+	ctx := context.Background()
+
+	first, _ := m.Send(ctx, testRequest())
+	second, _ := m.Send(ctx, testRequest())
+	backend.receiptErrs[first.Hash] = errors.New("connection reset")
+	backend.receipts[second.Hash] = &types.Receipt{
+		TxHash:      second.Hash,
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(100),
+		BlockHash:   common.HexToHash("0x01"),
+	}
+
+	// Lookups run unlocked; Get would deadlock otherwise
+	backend.onReceipt = func() { m.Get(first.Hash) }
+	if err := m.Poll(ctx); err == nil {
+		t.Error("Poll() hid the failed lookup")
+	}
+	if got, _ := m.Get(second.Hash); got.Status != entities.TxMined {
+		t.Errorf("second status = %s, want mined despite the first's lookup failing", got.Status)
+	}
+	if got, _ := m.Get(first.Hash); got.Status != entities.TxPending {
+		t.Errorf("first status = %s, want pending", got.Status)
+	}
+}
+
+func TestPollConfirmationsAndReorg(t *testing.T) {
+	backend := &fakeBackend{receipts: make(map[common.Hash]*types.Receipt)}
+	m := newTestManager(t, backend)
+	ctx := context.Background()
+
+	tx, err := m.Send(ctx, testRequest())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	backend.receipts[tx.Hash] = &types.Receipt{
+		TxHash:      tx.Hash,
+		Status:      types.ReceiptStatusSuccessful,
+		BlockNumber: big.NewInt(100),
+		BlockHash:   common.HexToHash("0x01"),
+		GasUsed:     90000,
+	}
+	backend.blockNumber = 100
+
+	steps := []struct {
+		name     string
+		mutate   func()
+		status   entities.TxStatus
+		confirms uint64
+		reorgs   uint64
+	}{
+		{"mined", func() {}, entities.TxMined, 1, 0},
+		{"reorged out", func() { delete(backend.receipts, tx.Hash) }, entities.TxPending, 0, 1},
+		{"re-mined", func() {
+			backend.receipts[tx.Hash] = &types.Receipt{
+				TxHash:      tx.Hash,
+				Status:      types.ReceiptStatusSuccessful,
+				BlockNumber: big.NewInt(101),
+				BlockHash:   common.HexToHash("0x02"),
+			}
+			backend.blockNumber = 103
+		}, entities.TxConfirmed, 3, 1},
+	}
+
+	for _, step := range steps {
+		step.mutate()
+		if err := m.Poll(ctx); err != nil {
+			t.Fatalf("%s: Poll() error = %v", step.name, err)
+		}
+		got, _ := m.Get(tx.Hash)
+		if got.Status != step.status || got.Confirmations != step.confirms || m.Reorgs() != step.reorgs {
+			t.Errorf("%s: status = %s, confirmations = %d, reorgs = %d; want %s, %d, %d",
+				step.name, got.Status, got.Confirmations, m.Reorgs(), step.status, step.confirms, step.reorgs)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/executor"
+)
+
+// Anvil's first default account
+var deployer = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+// executorBytecode reads the creation code at EXECUTOR_BYTECODE, skipping
+// the test when it is unset
+func executorBytecode(t *testing.T) []byte {
+	t.Helper()
+	path := os.Getenv("EXECUTOR_BYTECODE")
+	if path == "" {
+		t.Skip("EXECUTOR_BYTECODE not set")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return common.FromHex(strings.TrimSpace(string(raw)))
+}
+
+// TestForkExecutorDeploys checks the dry run against the deployment it
+// describes: the executor lands at the predicted address with the code the
+// constructor returned
+func TestForkExecutorDeploys(t *testing.T) {
+	bytecode := executorBytecode(t)
+	fork := StartFork(t)
+	ctx := context.Background()
+
+	deployment, err := executor.DryRunDeploy(ctx, fork.Client, deployer, bytecode)
+	if err != nil {
+		t.Fatalf("DryRunDeploy() error = %v", err)
+	}
+	if deployment.Gas == 0 || deployment.RuntimeSize == 0 {
+		t.Errorf("deployment = %+v", deployment)
+	}
+
+	address := fork.Deploy(t, deployer, deployment.Data)
+	if address != deployment.Address {
+		t.Errorf("deployed at %s, dry run predicted %s", address.Hex(), deployment.Address.Hex())
+	}
+	var code hexutil.Bytes
+	err = fork.rpc.CallContext(ctx, &code, "eth_getCode", address, "latest")
+	if err != nil || len(code) != deployment.RuntimeSize {
+		t.Errorf("deployed %d bytes of code, dry run predicted %d: %v", len(code), deployment.RuntimeSize, err)
+	}
+}
+
+// TestForkExecutorDecodes calls a freshly deployed executor with an expired
+// order. Expiry is checked before any transfer, so the revert proves the
+// contract decoded the calldata without needing balances.
+func TestForkExecutorDecodes(t *testing.T) {
+	bytecode := executorBytecode(t)
+	fork := StartFork(t)
+	deployment, err := executor.DryRunDeploy(context.Background(), fork.Client, deployer, bytecode)
+	if err != nil {
+		t.Fatalf("DryRunDeploy() error = %v", err)
+	}
+	address := fork.Deploy(t, deployer, deployment.Data)
+
+	hop := func(pool string, dexType entities.DEXType, fee uint64, tokenIn, tokenOut entities.Token) entities.Hop {
+		return entities.Hop{
+			Pair:     entities.Pair{Address: common.HexToAddress(pool), Token0: tokenIn, Token1: tokenOut, DEX: dexType, Fee: fee},
+			TokenIn:  tokenIn.Address,
+			TokenOut: tokenOut.Address,
+		}
+	}
+	direct := &entities.Route{
+		Hops:     []entities.Hop{hop("0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640", entities.DEXUniswapV3, 500, entities.WETH, entities.USDC)},
+		AmountIn: big.NewInt(600),
+	}
+	viaDAI := &entities.Route{
+		Hops: []entities.Hop{
+			hop("0xC3D03e4F041Fd4cD388c549Ee2A29a9E5075882f", entities.DEXSushiswap, 30, entities.WETH, entities.DAI),
+			hop("0xAE461cA67B15dc8dc81CE7615e0320dA1A9aB8D5", entities.DEXUniswapV2, 30, entities.DAI, entities.USDC),
+		},
+		AmountIn: big.NewInt(400),
+	}
+	quote := &entities.Quote{
+		TokenIn:      entities.WETH,
+		TokenOut:     entities.USDC,
+		AmountIn:     big.NewInt(1000),
+		MinAmountOut: big.NewInt(1),
+		ExpiresAt:    time.Unix(1, 0),
+		BestRoute:    direct,
+		SplitRoutes:  []entities.SplitRoute{{Route: direct}, {Route: viaDAI}},
+	}
+	tx, err := executor.NewEncoder(address).BuildExecution(quote, deployer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fork.Client.CallContract(context.Background(), ethereum.CallMsg{From: deployer, To: &tx.To, Data: tx.Data})
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("call error = %v, want a revert with data", err)
+	}
+	revert, _ := dataErr.ErrorData().(string)
+	if data := common.FromHex(revert); len(data) < 4 {
+		t.Fatalf("revert data %q has no selector", revert)
+	} else if decoded, _ := executor.NewExecutor().UnpackError(data); decoded == nil {
+		t.Errorf("revert %s is not an executor error", revert)
+	} else if _, ok := decoded.(*executor.ExecutorExpired); !ok {
+		t.Errorf("reverted with %T, want Expired()", decoded)
+	}
+}
@@ -0,0 +1,185 @@
+//go:build integration
+
+// Package integration runs the aggregator against a mainnet fork served by
+// anvil, so quotes read real pool state and the calldata they build is
+// executed by the real contracts. It is built with -tags integration and
+// needs anvil on PATH and FORK_URL set to a mainnet RPC endpoint. The
+// executor tests also need EXECUTOR_BYTECODE, a file with the contract's
+// compiled creation code.
+package integration
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+var (
+	// balanceOf(address)
+	balanceOfSelector = common.Hex2Bytes("70a08231")
+	// deposit() on WETH
+	depositSelector = common.Hex2Bytes("d0e30db0")
+)
+
+// Fork is a running anvil fork. Every account can send transactions, since
+// anvil impersonates senders.
+type Fork struct {
+	Client *ethclient.Client
+	rpc    *rpc.Client
+}
+
+// StartFork forks mainnet at FORK_BLOCK, or the latest block when unset,
+// and stops anvil when the test ends. The test is skipped without anvil or
+// FORK_URL.
+func StartFork(t *testing.T) *Fork {
+	t.Helper()
+
+	forkURL := os.Getenv("FORK_URL")
+	if forkURL == "" {
+		t.Skip("FORK_URL not set")
+	}
+	anvil, err := exec.LookPath("anvil")
+	if err != nil {
+		t.Skip("anvil not found on PATH")
+	}
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatalf("failed to pick a port: %v", err)
+	}
+	args := []string{"--fork-url", forkURL, "--port", strconv.Itoa(port), "--auto-impersonate", "--silent"}
+	if block := os.Getenv("FORK_BLOCK"); block != "" {
+		args = append(args, "--fork-block-number", block)
+	}
+	cmd := exec.Command(anvil, args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start anvil: %v", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	deadline := time.Now().Add(time.Minute)
+	for {
+		client, err := ethclient.NewClient(url)
+		if err == nil {
+			raw, err := rpc.Dial(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				raw.Close()
+				client.Close()
+			})
+			return &Fork{Client: client, rpc: raw}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("anvil did not come up at %s: %v", url, err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Fund sets account's ether balance
+func (f *Fork) Fund(t *testing.T, account common.Address, wei *big.Int) {
+	t.Helper()
+	if err := f.rpc.Call(nil, "anvil_setBalance", account, (*hexutil.Big)(wei)); err != nil {
+		t.Fatalf("failed to fund %s: %v", account.Hex(), err)
+	}
+}
+
+// WrapETH turns wei of account's ether into WETH
+func (f *Fork) WrapETH(t *testing.T, account common.Address, wei *big.Int) {
+	t.Helper()
+	f.Send(t, &entities.SwapTransaction{From: account, To: entities.WETH.Address, Data: depositSelector, Value: wei})
+}
+
+// BalanceOf reads account's balance of an ERC-20
+func (f *Fork) BalanceOf(t *testing.T, token, account common.Address) *big.Int {
+	t.Helper()
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(account.Bytes(), 32)...)
+	result, err := f.Client.CallContract(context.Background(), ethereum.CallMsg{To: &token, Data: data})
+	if err != nil || len(result) < 32 {
+		t.Fatalf("balanceOf(%s) on %s failed: %v", account.Hex(), token.Hex(), err)
+	}
+	return new(big.Int).SetBytes(result[:32])
+}
+
+// Send mines tx from tx.From and fails the test unless it succeeds
+func (f *Fork) Send(t *testing.T, tx *entities.SwapTransaction) *types.Receipt {
+	t.Helper()
+	call := map[string]any{
+		"from": tx.From,
+		"to":   tx.To,
+		"data": hexutil.Bytes(tx.Data),
+	}
+	if tx.Value != nil {
+		call["value"] = (*hexutil.Big)(tx.Value)
+	}
+	if tx.Gas != 0 {
+		call["gas"] = hexutil.Uint64(tx.Gas * 12 / 10)
+	}
+	return f.mine(t, call, "to "+tx.To.Hex())
+}
+
+// Deploy mines a contract creation from deployer and returns the new
+// contract's address
+func (f *Fork) Deploy(t *testing.T, deployer common.Address, data []byte) common.Address {
+	t.Helper()
+	receipt := f.mine(t, map[string]any{"from": deployer, "data": hexutil.Bytes(data)}, "creating a contract")
+	return receipt.ContractAddress
+}
+
+// mine sends call with eth_sendTransaction and waits for its receipt. what
+// names the transaction in failures.
+func (f *Fork) mine(t *testing.T, call map[string]any, what string) *types.Receipt {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var hash common.Hash
+	if err := f.rpc.CallContext(ctx, &hash, "eth_sendTransaction", call); err != nil {
+		t.Fatalf("transaction %s rejected: %v", what, err)
+	}
+	for {
+		receipt, err := f.Client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				t.Fatalf("transaction %s %s reverted", hash.Hex(), what)
+			}
+			return receipt
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("transaction %s was not mined", hash.Hex())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
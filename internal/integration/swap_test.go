@@ -0,0 +1,105 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+)
+
+var ether = big.NewInt(1e18)
+
+// TestForkQuotesEveryVenue reads each adapter's pools from forked state, so
+// a bad selector or misdecoded return fails here rather than in production
+func TestForkQuotesEveryVenue(t *testing.T) {
+	fork := StartFork(t)
+	clients, err := dex.Build(fork.Client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A pair each venue is known to hold
+	pairs := map[entities.DEXType][2]entities.Token{
+		entities.DEXUniswapV2: {entities.WETH, entities.USDC},
+		entities.DEXSushiswap: {entities.WETH, entities.USDC},
+		entities.DEXUniswapV3: {entities.WETH, entities.USDC},
+		entities.DEXCurve:     {entities.USDC, entities.USDT},
+		entities.DEXBalancer:  {entities.WETH, entities.DAI},
+		entities.DEXLido:      {entities.STETH, entities.WSTETH},
+	}
+	for _, client := range clients {
+		pair, ok := pairs[client.DEXType()]
+		if !ok {
+			continue
+		}
+		t.Run(string(client.DEXType()), func(t *testing.T) {
+			amountIn := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(pair[0].Decimals)), nil)
+			amountOut, err := client.GetAmountOut(context.Background(), amountIn, pair[0], pair[1])
+			if err != nil {
+				t.Fatalf("GetAmountOut(%s -> %s) error = %v", pair[0].Symbol, pair[1].Symbol, err)
+			}
+			if amountOut.Sign() <= 0 {
+				t.Errorf("quoted %s for one %s", amountOut, pair[0].Symbol)
+			}
+		})
+	}
+}
+
+// TestForkSwapsRealizeMinimum quotes WETH sells through each venue the swap
+// builder can encode, executes the transaction and its approvals, and
+// checks the trader receives at least the quoted minimum
+func TestForkSwapsRealizeMinimum(t *testing.T) {
+	fork := StartFork(t)
+	trader := common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	fork.Fund(t, trader, new(big.Int).Mul(big.NewInt(100), ether))
+	fork.WrapETH(t, trader, new(big.Int).Mul(big.NewInt(50), ether))
+
+	builder := swap.NewBuilder()
+	for _, venue := range []entities.DEXType{entities.DEXUniswapV2, entities.DEXSushiswap, entities.DEXUniswapV3} {
+		for _, tokenOut := range []entities.Token{entities.USDC, entities.DAI} {
+			t.Run(string(venue)+"/"+tokenOut.Symbol, func(t *testing.T) {
+				ctx := context.Background()
+				clients, err := dex.Build(fork.Client, []entities.DEXType{venue})
+				if err != nil {
+					t.Fatal(err)
+				}
+				router := services.NewRouterService(services.NewPriceService(clients, cache.NewInMemoryCache()))
+				swapService := services.NewSwapService(builder, fork.Client)
+				swapService.SetApprovalService(services.NewApprovalService(builder, fork.Client))
+
+				quote, err := router.GetStrategyQuote(ctx, "direct", entities.WETH, tokenOut, ether, 50)
+				if err != nil {
+					t.Fatalf("quote error = %v", err)
+				}
+				if err := swapService.AttachTransaction(ctx, quote, trader, trader); err != nil {
+					t.Fatalf("AttachTransaction() error = %v", err)
+				}
+				if quote.Transaction == nil {
+					t.Fatal("quote has no transaction")
+				}
+				if quote.Approval != nil {
+					for _, step := range quote.Approval.Steps {
+						fork.Send(t, step)
+					}
+				}
+
+				before := fork.BalanceOf(t, tokenOut.Address, trader)
+				fork.Send(t, quote.Transaction)
+				received := new(big.Int).Sub(fork.BalanceOf(t, tokenOut.Address, trader), before)
+
+				if received.Cmp(quote.MinAmountOut) < 0 {
+					t.Errorf("received %s %s, below the quoted minimum %s (quoted %s)", received, tokenOut.Symbol, quote.MinAmountOut, quote.AmountOut)
+				}
+			})
+		}
+	}
+}
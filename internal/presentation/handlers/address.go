@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	ethclient "github.com/bimakw/dex-aggregator/internal/infrastructure/ethereum"
+)
+
+// NameResolver resolves ENS names to addresses
+type NameResolver interface {
+	Resolve(ctx context.Context, name string) (common.Address, error)
+}
+
+// parseAddress accepts a hex address, or an ENS name when resolver is set
+func parseAddress(ctx context.Context, resolver NameResolver, s string) (common.Address, error) {
+	if common.IsHexAddress(s) {
+		return common.HexToAddress(s), nil
+	}
+	if resolver != nil && ethclient.IsENSName(s) {
+		addr, err := resolver.Resolve(ctx, s)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("could not resolve %q: %w", s, err)
+		}
+		return addr, nil
+	}
+	return common.Address{}, fmt.Errorf("%q is not a valid address or ENS name", s)
+}
+
+// parseAddresses parses each value with parseAddress, stopping at the first failure
+func parseAddresses(ctx context.Context, resolver NameResolver, values ...string) ([]common.Address, error) {
+	addrs := make([]common.Address, len(values))
+	for i, v := range values {
+		addr, err := parseAddress(ctx, resolver, v)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type stubResolver map[string]common.Address
+
+func (s stubResolver) Resolve(ctx context.Context, name string) (common.Address, error) {
+	if addr, ok := s[name]; ok {
+		return addr, nil
+	}
+	return common.Address{}, errors.New("name not found")
+}
+
+func TestParseAddress(t *testing.T) {
+	vitalik := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	resolver := stubResolver{"vitalik.eth": vitalik}
+
+	tests := []struct {
+		name     string
+		resolver NameResolver
+		input    string
+		want     common.Address
+		wantErr  bool
+	}{
+		{"hex address", resolver, "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045", vitalik, false},
+		{"ens name", resolver, "vitalik.eth", vitalik, false},
+		{"unknown name", resolver, "nobody.eth", common.Address{}, true},
+		{"ens without resolver", nil, "vitalik.eth", common.Address{}, true},
+		{"garbage", resolver, "not-an-address", common.Address{}, true},
+		{"malformed hex with dot", stubResolver{"0x12.eth": vitalik}, "0x12.eth", common.Address{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAddress(context.Background(), tt.resolver, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAddress(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAddress(%q) = %s, want %s", tt.input, got.Hex(), tt.want.Hex())
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// AdmissionMiddleware holds requests until the controller admits them, by
+// the tier of the API key that authenticated them. It has to run after the
+// API key middleware.
+func AdmissionMiddleware(admission *services.AdmissionController) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			release, err := admission.Acquire(r.Context(), requestTier(r))
+			if err != nil {
+				w.Header().Set("Retry-After", "1")
+				WriteError(w, r, apperror.Wrap(apperror.Overloaded, err))
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requestTier(r *http.Request) services.Tier {
+	key := apiKeyFromContext(r.Context())
+	switch {
+	case key == nil:
+		return services.TierAnonymous
+	case key.Priority:
+		return services.TierPriority
+	default:
+		return services.TierKey
+	}
+}
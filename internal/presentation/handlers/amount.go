@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// errAmountOverflow is returned for amounts that don't fit in uint256
+var errAmountOverflow = errors.New("does not fit in uint256")
+
+var amountPattern = regexp.MustCompile(`^(\d+)(?:\.(\d*))?(?:[eE]([+-]?\d{1,3}))?$`)
+
+// unitExponents are the ether denominations accepted as amount suffixes
+var unitExponents = map[string]int{
+	"wei":   0,
+	"gwei":  9,
+	"ether": 18,
+}
+
+// Amount is the v2 representation of a token amount: the raw integer in the
+// token's smallest unit alongside its decimal-adjusted value
+type Amount struct {
+	Raw     string `json:"raw"`
+	Decimal string `json:"decimal"`
+}
+
+func newAmount(value *big.Int, decimals uint8) Amount {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	return Amount{
+		Raw:     value.String(),
+		Decimal: formatUnits(value, decimals),
+	}
+}
+
+// formatUnits renders value / 10^decimals without losing precision,
+// trimming trailing zeros from the fractional part
+func formatUnits(value *big.Int, decimals uint8) string {
+	negative := value.Sign() < 0
+	digits := new(big.Int).Abs(value).String()
+
+	if decimals > 0 {
+		if len(digits) <= int(decimals) {
+			digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+		}
+		pos := len(digits) - int(decimals)
+		whole, frac := digits[:pos], strings.TrimRight(digits[pos:], "0")
+		digits = whole
+		if frac != "" {
+			digits += "." + frac
+		}
+	}
+
+	if negative {
+		return "-" + digits
+	}
+	return digits
+}
+
+// parseAmount converts a request amount into the token's smallest unit.
+// Plain integers are already raw ("1500000"); decimals are whole tokens
+// ("1.5"); an exponent ("1.5e18") or a unit suffix ("1500000000 gwei",
+// "1.5 ether", "100 USDC") sets the scale explicitly.
+func parseAmount(s string, token entities.Token) (*big.Int, error) {
+	number, unit, _ := strings.Cut(strings.TrimSpace(s), " ")
+	unit = strings.TrimSpace(unit)
+
+	m := amountPattern.FindStringSubmatch(number)
+	if m == nil {
+		return nil, fmt.Errorf("%q is not a number", number)
+	}
+	whole, frac, exponent := m[1], m[2], m[3]
+
+	scale := 0
+	switch {
+	case unit != "":
+		if exp, ok := unitExponents[strings.ToLower(unit)]; ok {
+			scale = exp
+		} else if strings.EqualFold(unit, token.Symbol) && token.Symbol != "UNKNOWN" {
+			scale = int(token.Decimals)
+		} else {
+			return nil, fmt.Errorf("unknown unit %q", unit)
+		}
+	case strings.Contains(number, ".") && exponent == "":
+		scale = int(token.Decimals)
+	}
+	if exponent != "" {
+		exp, _ := strconv.Atoi(exponent)
+		scale += exp
+	}
+
+	value, _ := new(big.Rat).SetString(whole + "." + frac + "0")
+	factor := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(scale))), nil))
+	if scale >= 0 {
+		value.Mul(value, factor)
+	} else {
+		value.Quo(value, factor)
+	}
+
+	if !value.IsInt() {
+		return nil, fmt.Errorf("%s has more precision than %s's %d decimals", s, token.Symbol, token.Decimals)
+	}
+	amount := value.Num()
+	if amount.BitLen() > 256 {
+		return nil, fmt.Errorf("%s %w", s, errAmountOverflow)
+	}
+	return amount, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
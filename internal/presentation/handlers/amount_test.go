@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		decimals uint8
+		want     string
+	}{
+		{"one ether", "1000000000000000000", 18, "1"},
+		{"fractional usdc", "1500000", 6, "1.5"},
+		{"sub-unit", "1", 18, "0.000000000000000001"},
+		{"zero", "0", 6, "0"},
+		{"no decimals", "42", 0, "42"},
+		{"negative", "-2500000", 6, "-2.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := new(big.Int).SetString(tt.value, 10)
+			if got := formatUnits(value, tt.decimals); got != tt.want {
+				t.Errorf("formatUnits(%s, %d) = %s, want %s", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		token   entities.Token
+		want    string
+		wantErr bool
+	}{
+		{"raw integer", "1500000", entities.USDC, "1500000", false},
+		{"decimal usdc", "1.5", entities.USDC, "1500000", false},
+		{"decimal weth", "0.25", entities.WETH, "250000000000000000", false},
+		{"scientific raw", "1.5e18", entities.WETH, "1500000000000000000", false},
+		{"gwei", "1500000000 gwei", entities.WETH, "1500000000000000000", false},
+		{"ether", "2 ether", entities.WETH, "2000000000000000000", false},
+		{"token symbol unit", "100 usdc", entities.USDC, "100000000", false},
+		{"too precise", "1.0000001", entities.USDC, "", true},
+		{"fractional wei", "1.5 wei", entities.WETH, "", true},
+		{"unknown unit", "1 btc", entities.WETH, "", true},
+		{"not a number", "abc", entities.WETH, "", true},
+		{"negative", "-1", entities.WETH, "", true},
+		{"overflow", "1e100", entities.WETH, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAmount(tt.input, tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAmount(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("parseAmount(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
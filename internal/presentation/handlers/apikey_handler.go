@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// APIKeyHeader carries an integrator's API key
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+// apiKeyFromContext returns the key that authenticated the request, if any
+func apiKeyFromContext(ctx context.Context) *entities.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*entities.APIKey)
+	return key
+}
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type APIKeyRequest struct {
+	Name       *string `json:"name"`
+	DailyQuota *int64  `json:"dailyQuota"`
+	Disabled   *bool   `json:"disabled"`
+	Priority   *bool   `json:"priority"`
+}
+
+type APIKeyResp struct {
+	ID         string        `json:"id"`
+	Key        string        `json:"key,omitempty"` // Only returned when the key is created
+	Name       string        `json:"name"`
+	DailyQuota int64         `json:"dailyQuota"`
+	Disabled   bool          `json:"disabled"`
+	Priority   bool          `json:"priority"`
+	CreatedAt  int64         `json:"createdAt"`
+	Usage      *APIUsageResp `json:"usage,omitempty"`
+}
+
+type APIUsageResp struct {
+	Requests       int64  `json:"requests"`
+	Quotes         int64  `json:"quotes"`
+	VolumeUSD      string `json:"volumeUsd"`
+	RequestsToday  int64  `json:"requestsToday"`
+	QuotesToday    int64  `json:"quotesToday"`
+	VolumeUSDToday string `json:"volumeUsdToday"`
+}
+
+func newAPIKeyResp(key *entities.APIKey) APIKeyResp {
+	return APIKeyResp{
+		ID:         key.ID,
+		Name:       key.Name,
+		DailyQuota: key.DailyQuota,
+		Disabled:   key.Disabled,
+		Priority:   key.Priority,
+		CreatedAt:  key.CreatedAt.Unix(),
+	}
+}
+
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// Middleware authenticates the X-API-Key header and enforces the key's daily
+// quota. Requests without a key pass unmetered unless required is set.
+func (h *APIKeyHandler) Middleware(required bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			presented := r.Header.Get(APIKeyHeader)
+			if presented == "" {
+				if required {
+					WriteError(w, r, apperror.New(apperror.MissingAPIKey, "an API key is required in the "+APIKeyHeader+" header"))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := h.apiKeyService.Authenticate(r.Context(), presented)
+			switch {
+			case errors.Is(err, services.ErrInvalidAPIKey):
+				WriteError(w, r, apperror.New(apperror.InvalidAPIKey, "API key is invalid or disabled"))
+				return
+			case errors.Is(err, services.ErrQuotaExceeded):
+				w.Header().Set("Retry-After", fmt.Sprint(int(time.Until(nextUTCMidnight()).Seconds())+1))
+				WriteError(w, r, apperror.New(apperror.QuotaExceeded, fmt.Sprintf("daily quota of %d requests exceeded", key.DailyQuota)))
+				return
+			case err != nil:
+				WriteError(w, r, apperror.Wrap(apperror.APIKeysUnavailable, err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key)))
+		})
+	}
+}
+
+func nextUTCMidnight() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
+
+// Create handles POST /api/v1/admin/keys
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+	if req.Name == nil || *req.Name == "" {
+		WriteError(w, r, apperror.New(apperror.InvalidName, "name is required"))
+		return
+	}
+	var quota int64
+	if req.DailyQuota != nil {
+		quota = *req.DailyQuota
+	}
+
+	key, secret, err := h.apiKeyService.Issue(r.Context(), *req.Name, quota)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidKey, err))
+		return
+	}
+	if req.Priority != nil && *req.Priority {
+		if key, err = h.apiKeyService.Update(r.Context(), key.ID, services.APIKeyUpdate{Priority: req.Priority}); err != nil {
+			WriteError(w, r, apperror.Wrap(apperror.InvalidKey, err))
+			return
+		}
+	}
+	resp := newAPIKeyResp(key)
+	resp.Key = secret
+	h.writeJSON(w, http.StatusCreated, resp)
+}
+
+// List handles GET /api/v1/admin/keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.List(r.Context())
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+	resp := make([]APIKeyResp, 0, len(keys))
+	for i := range keys {
+		resp = append(resp, newAPIKeyResp(&keys[i]))
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Get handles GET /api/v1/admin/keys/{id}, including usage statistics
+func (h *APIKeyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	key, err := h.apiKeyService.Get(r.Context(), id)
+	if err != nil {
+		h.writeKeyError(w, r, err)
+		return
+	}
+	usage, err := h.apiKeyService.Usage(r.Context(), id)
+	if err != nil {
+		h.writeKeyError(w, r, err)
+		return
+	}
+
+	resp := newAPIKeyResp(key)
+	resp.Usage = &APIUsageResp{
+		Requests:       usage.Requests,
+		Quotes:         usage.Quotes,
+		VolumeUSD:      formatCents(usage.VolumeUSDCents),
+		RequestsToday:  usage.RequestsToday,
+		QuotesToday:    usage.QuotesToday,
+		VolumeUSDToday: formatCents(usage.VolumeUSDCentsToday),
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// Update handles PATCH /api/v1/admin/keys/{id}
+func (h *APIKeyHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+
+	key, err := h.apiKeyService.Update(r.Context(), chi.URLParam(r, "id"), services.APIKeyUpdate{
+		Name:       req.Name,
+		DailyQuota: req.DailyQuota,
+		Disabled:   req.Disabled,
+		Priority:   req.Priority,
+	})
+	if err != nil {
+		h.writeKeyError(w, r, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, newAPIKeyResp(key))
+}
+
+// Delete handles DELETE /api/v1/admin/keys/{id}
+func (h *APIKeyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.apiKeyService.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.writeKeyError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) writeKeyError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		WriteError(w, r, apperror.Wrap(apperror.KeyNotFound, err))
+		return
+	}
+	WriteError(w, r, apperror.Wrap(apperror.InvalidKey, err))
+}
+
+func (h *APIKeyHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
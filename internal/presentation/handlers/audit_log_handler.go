@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type AuditLogHandler struct {
+	auditLog *services.AuditLog
+}
+
+func NewAuditLogHandler(auditLog *services.AuditLog) *AuditLogHandler {
+	return &AuditLogHandler{auditLog: auditLog}
+}
+
+// Search handles GET /api/v1/admin/audit, listing the sampled quote
+// requests matching apiKeyId, clientIp, address and a from/to RFC 3339
+// time range, newest first
+func (h *AuditLogHandler) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, reqErr := parsePage(q)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	filter, reqErr := parseAuditLogFilter(q)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	entries, err := h.auditLog.Search(r.Context(), filter)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+	var data []entities.AuditLogEntry
+	if page.Offset < len(entries) {
+		data = entries[page.Offset:min(page.Offset+page.Limit, len(entries))]
+	}
+	h.writeJSON(w, http.StatusOK, newPage(data, page, len(entries)))
+}
+
+// parseAuditLogFilter reads the audit log search filters
+func parseAuditLogFilter(q url.Values) (entities.AuditLogFilter, *apperror.Error) {
+	filter := entities.AuditLogFilter{
+		APIKeyID: q.Get("apiKeyId"),
+		ClientIP: q.Get("clientIp"),
+	}
+	if s := q.Get("address"); s != "" {
+		if !common.IsHexAddress(s) {
+			return filter, apperror.New(apperror.InvalidFilter, "address must be a hex address")
+		}
+		filter.Address = common.HexToAddress(s).Hex()
+	}
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := q.Get(bound.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, apperror.New(apperror.InvalidFilter, bound.name+" must be an RFC 3339 time")
+		}
+		*bound.t = t
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return filter, apperror.New(apperror.InvalidFilter, "to is before from")
+	}
+	return filter, nil
+}
+
+// newAuditLogEntry records a quote request with the quotes it served and
+// a hash of the body sent, which a client's copy can be checked against
+func newAuditLogEntry(r *http.Request, params *quoteParams, quotes []*entities.Quote, body []byte) entities.AuditLogEntry {
+	hash := sha256.Sum256(body)
+	entry := entities.AuditLogEntry{
+		Time:         time.Now().UTC(),
+		RequestID:    middleware.GetReqID(r.Context()),
+		ClientIP:     r.RemoteAddr,
+		Path:         r.URL.Path,
+		Params:       make(map[string]string),
+		Quotes:       make([]entities.AuditLogQuote, len(quotes)),
+		ResponseHash: hex.EncodeToString(hash[:]),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.ClientIP = host
+	}
+	if key := apiKeyFromContext(r.Context()); key != nil {
+		entry.APIKeyID = key.ID
+	}
+	if params.sender != nil {
+		entry.Sender = params.sender.Hex()
+	}
+	if params.recipient != nil {
+		entry.Recipient = params.recipient.Hex()
+	}
+	for name, values := range r.URL.Query() {
+		entry.Params[name] = strings.Join(values, ",")
+	}
+	for i, quote := range quotes {
+		entry.Quotes[i] = services.NewAuditLogQuote(quote)
+	}
+	return entry
+}
+
+func (h *AuditLogHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+)
+
+func TestParseAuditLogFilter(t *testing.T) {
+	filter, reqErr := parseAuditLogFilter(url.Values{
+		"apiKeyId": {"k1"},
+		"address":  {"0x00000000000000000000000000000000000000aa"},
+		"from":     {"2024-05-01T14:00:00Z"},
+		"to":       {"2024-05-01T15:00:00+01:00"},
+	})
+	if reqErr != nil {
+		t.Fatalf("parseAuditLogFilter() error = %v", reqErr)
+	}
+	if filter.APIKeyID != "k1" || filter.Address != "0x00000000000000000000000000000000000000AA" {
+		t.Errorf("parseAuditLogFilter() = %+v", filter)
+	}
+	if !filter.From.Equal(filter.To) || !filter.From.Equal(time.Date(2024, 5, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("from, to = %v, %v", filter.From, filter.To)
+	}
+
+	for _, q := range []url.Values{
+		{"address": {"alice"}},
+		{"from": {"2024-05-01"}},
+		{"from": {"2024-05-01T15:00:00Z"}, "to": {"2024-05-01T14:00:00Z"}},
+	} {
+		if _, reqErr := parseAuditLogFilter(q); reqErr == nil || reqErr.Code != apperror.InvalidFilter {
+			t.Errorf("parseAuditLogFilter(%v) error = %v, want %s", q, reqErr, apperror.InvalidFilter)
+		}
+	}
+}
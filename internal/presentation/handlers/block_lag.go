@@ -0,0 +1,23 @@
+package handlers
+
+import "time"
+
+// ChainHead reports the latest block the node has served
+type ChainHead interface {
+	Head() (number uint64, timestamp time.Time)
+}
+
+// blockLag returns the head block and how many seconds ago it was mined, so
+// clients can tell a lagging node or a stalled chain. Both are unset without
+// a head.
+func blockLag(head ChainHead, now time.Time) (uint64, *int64) {
+	if head == nil {
+		return 0, nil
+	}
+	number, timestamp := head.Head()
+	if number == 0 {
+		return 0, nil
+	}
+	age := max(0, int64(now.Sub(timestamp)/time.Second)) // A block stamped ahead of our clock is new
+	return number, &age
+}
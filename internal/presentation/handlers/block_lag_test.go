@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedHead struct {
+	number uint64
+	at     time.Time
+}
+
+func (h fixedHead) Head() (uint64, time.Time) {
+	return h.number, h.at
+}
+
+func TestBlockLag(t *testing.T) {
+	now := time.Unix(1700000100, 0)
+	tests := []struct {
+		name       string
+		head       ChainHead
+		wantNumber uint64
+		wantAge    int64 // -1 for unset
+	}{
+		{"no watcher", nil, 0, -1},
+		{"no head yet", fixedHead{}, 0, -1},
+		{"fresh head", fixedHead{100, time.Unix(1700000088, 0)}, 100, 12},
+		{"stalled chain", fixedHead{100, time.Unix(1699999500, 0)}, 100, 600},
+		{"head ahead of our clock", fixedHead{101, time.Unix(1700000101, 0)}, 101, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, age := blockLag(tt.head, now)
+			if number != tt.wantNumber {
+				t.Errorf("number = %d, want %d", number, tt.wantNumber)
+			}
+			if (age == nil) != (tt.wantAge < 0) || (age != nil && *age != tt.wantAge) {
+				t.Errorf("age = %v, want %d", age, tt.wantAge)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig is the cross-origin policy of a deployment
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as https://app.example.com,
+	// https://*.example.com for any subdomain, or * for any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool // Lets browsers send cookies; not with *
+	MaxAge           int  // Seconds a preflight may be cached, zero leaves it to the browser
+}
+
+// DefaultCORSConfig allows any origin without credentials
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type", "Authorization", APIKeyHeader, "Accept-Language"},
+		ExposedHeaders: []string{"Content-Language", "Retry-After", "X-Request-Id"},
+	}
+}
+
+// Validate rejects policies browsers would refuse or that are unsafe
+func (c CORSConfig) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("no allowed origins")
+	}
+	for _, origin := range c.AllowedOrigins {
+		switch {
+		case origin == "*":
+			if c.AllowCredentials {
+				return fmt.Errorf("credentials can't be allowed for any origin")
+			}
+		case !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://"):
+			return fmt.Errorf("origin %q has no http:// or https:// scheme", origin)
+		case strings.HasSuffix(origin, "/"):
+			return fmt.Errorf("origin %q has a trailing slash", origin)
+		case strings.Contains(origin, "*") && !strings.Contains(origin, "://*."):
+			return fmt.Errorf("origin %q may only wildcard a leading subdomain", origin)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("negative max age")
+	}
+	return nil
+}
+
+// allowOrigin returns the Access-Control-Allow-Origin value for origin,
+// empty when it isn't allowed
+func (c CORSConfig) allowOrigin(origin string) string {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin == "" {
+			continue
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			rest, found := strings.CutPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://")
+			if found && strings.HasSuffix(rest, "."+strings.ToLower(domain)) {
+				return origin
+			}
+		}
+	}
+	return ""
+}
+
+// CORSMiddleware applies the policy and answers preflight requests
+func CORSMiddleware(config CORSConfig) func(http.Handler) http.Handler {
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+	exposed := strings.Join(config.ExposedHeaders, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed := config.allowOrigin(r.Header.Get("Origin"))
+			if allowed != "*" {
+				// The answer depends on the origin, so caches must key on it
+				w.Header().Add("Vary", "Origin")
+			}
+			if allowed != "" {
+				w.Header().Set("Access-Control-Allow-Origin", allowed)
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				if exposed != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
+				}
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if config.MaxAge > 0 && r.Method == http.MethodOptions {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+				}
+			}
+
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SecurityHeadersMiddleware sets the headers security reviews expect of a
+// JSON API. hstsMaxAge, in seconds, adds Strict-Transport-Security and
+// belongs only on deployments served over HTTPS.
+func SecurityHeadersMiddleware(hstsMaxAge int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("X-Frame-Options", "DENY")
+			h.Set("Referrer-Policy", "no-referrer")
+			h.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+			h.Set("Cross-Origin-Resource-Policy", "cross-origin")
+			if hstsMaxAge > 0 {
+				h.Set("Strict-Transport-Security", "max-age="+strconv.Itoa(hstsMaxAge)+"; includeSubDomains")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://app.example.com", "https://*.partner.io"}
+	config.AllowCredentials = true
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	handler := CORSMiddleware(config)(next)
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		wantOrigin string
+		wantStatus int
+	}{
+		{"exact origin", http.MethodGet, "https://app.example.com", "https://app.example.com", http.StatusTeapot},
+		{"subdomain", http.MethodGet, "https://eu.partner.io", "https://eu.partner.io", http.StatusTeapot},
+		{"bare wildcard domain", http.MethodGet, "https://partner.io", "", http.StatusTeapot},
+		{"lookalike domain", http.MethodGet, "https://evilpartner.io", "", http.StatusTeapot},
+		{"other scheme", http.MethodGet, "http://app.example.com", "", http.StatusTeapot},
+		{"unlisted", http.MethodGet, "https://evil.com", "", http.StatusTeapot},
+		{"preflight", http.MethodOptions, "https://app.example.com", "https://app.example.com", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/quote", nil)
+			req.Header.Set("Origin", tt.origin)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			wantCredentials := ""
+			if tt.wantOrigin != "" {
+				wantCredentials = "true"
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, wantCredentials)
+			}
+			if rec.Header().Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", rec.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSConfigValidate(t *testing.T) {
+	invalid := map[string]CORSConfig{
+		"credentials with any origin": {AllowedOrigins: []string{"*"}, AllowCredentials: true},
+		"no origins":                  {},
+		"no scheme":                   {AllowedOrigins: []string{"app.example.com"}},
+		"trailing slash":              {AllowedOrigins: []string{"https://app.example.com/"}},
+		"inner wildcard":              {AllowedOrigins: []string{"https://app.*.com"}},
+	}
+	for name, config := range invalid {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+	if err := DefaultCORSConfig().Validate(); err != nil {
+		t.Errorf("DefaultCORSConfig().Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type CrossChainHandler struct {
+	crossChainService *services.CrossChainService
+	nameResolver      NameResolver
+	tokenRegistry     *entities.TokenRegistry
+}
+
+func NewCrossChainHandler(crossChainService *services.CrossChainService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *CrossChainHandler {
+	return &CrossChainHandler{
+		crossChainService: crossChainService,
+		nameResolver:      nameResolver,
+		tokenRegistry:     tokenRegistry,
+	}
+}
+
+type CrossChainQuoteResponse struct {
+	SrcChainID       uint64          `json:"srcChainId"`
+	DstChainID       uint64          `json:"dstChainId"`
+	TokenIn          string          `json:"tokenIn"`
+	TokenOut         string          `json:"tokenOut"`
+	AmountIn         string          `json:"amountIn"`
+	AmountOut        string          `json:"amountOut"`
+	MinAmountOut     string          `json:"minAmountOut"`
+	SlippageBps      uint64          `json:"slippageBps"`
+	Legs             []CrossChainLeg `json:"legs"`
+	EstimatedTimeSec uint64          `json:"estimatedTimeSec"`
+	TotalFeeUSD      string          `json:"totalFeeUsd,omitempty"`
+}
+
+// CrossChainLeg is one step of a cross-chain quote: "swap" or "bridge"
+type CrossChainLeg struct {
+	Type             string     `json:"type"`
+	ChainID          uint64     `json:"chainId"`
+	DstChainID       uint64     `json:"dstChainId,omitempty"` // Bridge legs only
+	Bridge           string     `json:"bridge,omitempty"`
+	TokenIn          string     `json:"tokenIn"`
+	TokenOut         string     `json:"tokenOut"`
+	AmountIn         string     `json:"amountIn"`
+	AmountOut        string     `json:"amountOut"`
+	Fee              string     `json:"fee,omitempty"`
+	NativeFee        string     `json:"nativeFee,omitempty"`
+	Route            []RouteHop `json:"route,omitempty"`
+	GasEstimate      uint64     `json:"gasEstimate,omitempty"`
+	EstimatedTimeSec uint64     `json:"estimatedTimeSec,omitempty"`
+}
+
+// GetQuote handles GET /api/v1/crosschain/quote
+func (h *CrossChainHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	srcChainID, err := strconv.ParseUint(query.Get("srcChainId"), 10, 64)
+	if err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidChain, "srcChainId must be a chain ID"))
+		return
+	}
+	dstChainID, err := strconv.ParseUint(query.Get("dstChainId"), 10, 64)
+	if err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidChain, "dstChainId must be a chain ID"))
+		return
+	}
+
+	// ENS lives on mainnet, so names only resolve for mainnet legs
+	tokenInAddr, err := parseAddress(r.Context(), h.resolverFor(srcChainID), query.Get("tokenIn"))
+	if err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidToken, "tokenIn: "+err.Error()))
+		return
+	}
+	tokenOutAddr, err := parseAddress(r.Context(), h.resolverFor(dstChainID), query.Get("tokenOut"))
+	if err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidToken, "tokenOut: "+err.Error()))
+		return
+	}
+
+	amountIn, ok := new(big.Int).SetString(query.Get("amountIn"), 10)
+	if !ok || amountIn.Sign() <= 0 {
+		WriteError(w, r, apperror.New(apperror.InvalidAmount, "amountIn must be a positive integer"))
+		return
+	}
+
+	var slippageBps uint64
+	if slippageStr := query.Get("slippage"); slippageStr != "" {
+		slippageBps, err = strconv.ParseUint(slippageStr, 10, 64)
+		if err != nil || slippageBps > 10000 {
+			WriteError(w, r, apperror.New(apperror.InvalidSlippage, "slippage must be 0-10000 basis points"))
+			return
+		}
+	}
+
+	tokenIn := h.resolveToken(r.Context(), srcChainID, tokenInAddr)
+	tokenOut := h.resolveToken(r.Context(), dstChainID, tokenOutAddr)
+
+	quote, err := h.crossChainService.GetQuote(r.Context(), srcChainID, tokenIn, dstChainID, tokenOut, amountIn, slippageBps)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.NoRoute))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, buildCrossChainResponse(quote))
+}
+
+// resolverFor returns the name resolver for chains where ENS names apply
+func (h *CrossChainHandler) resolverFor(chainID uint64) NameResolver {
+	if chainID != entities.ChainEthereum {
+		return nil
+	}
+	return h.nameResolver
+}
+
+// resolveToken looks up tokens on the registry's chain in the registry,
+// reading unlisted ones from the chain, and bridge assets on other chains
+func (h *CrossChainHandler) resolveToken(ctx context.Context, chainID uint64, addr common.Address) entities.Token {
+	if chainID == h.tokenRegistry.ChainID() {
+		return h.tokenRegistry.Resolve(ctx, addr)
+	}
+	if token, ok := entities.FindBridgeToken(chainID, addr); ok {
+		return token
+	}
+	return entities.UnknownToken(addr)
+}
+
+func buildCrossChainResponse(quote *entities.CrossChainQuote) CrossChainQuoteResponse {
+	var legs []CrossChainLeg
+	if quote.SourceSwap != nil {
+		legs = append(legs, newSwapLeg(quote.SrcChainID, quote.SourceSwap))
+	}
+
+	bridge := quote.Bridge
+	bridgeLeg := CrossChainLeg{
+		Type:             "bridge",
+		ChainID:          bridge.SrcChainID,
+		DstChainID:       bridge.DstChainID,
+		Bridge:           bridge.Bridge,
+		TokenIn:          bridge.TokenIn.Address.Hex(),
+		TokenOut:         bridge.TokenOut.Address.Hex(),
+		AmountIn:         bridge.AmountIn.String(),
+		AmountOut:        bridge.AmountOut.String(),
+		Fee:              bridge.Fee.String(),
+		EstimatedTimeSec: bridge.EstimatedTimeSec,
+	}
+	if bridge.NativeFee != nil {
+		bridgeLeg.NativeFee = bridge.NativeFee.String()
+	}
+	legs = append(legs, bridgeLeg)
+
+	if quote.DestinationSwap != nil {
+		legs = append(legs, newSwapLeg(quote.DstChainID, quote.DestinationSwap))
+	}
+
+	response := CrossChainQuoteResponse{
+		SrcChainID:       quote.SrcChainID,
+		DstChainID:       quote.DstChainID,
+		TokenIn:          quote.TokenIn.Address.Hex(),
+		TokenOut:         quote.TokenOut.Address.Hex(),
+		AmountIn:         quote.AmountIn.String(),
+		AmountOut:        quote.AmountOut.String(),
+		MinAmountOut:     quote.MinAmountOut.String(),
+		SlippageBps:      quote.SlippageBps,
+		Legs:             legs,
+		EstimatedTimeSec: quote.EstimatedTimeSec,
+	}
+	if quote.TotalFeeUSD != nil {
+		response.TotalFeeUSD = usdFormat.format(quote.TotalFeeUSD, priceDecimals)
+	}
+	return response
+}
+
+func newSwapLeg(chainID uint64, quote *entities.Quote) CrossChainLeg {
+	route := newRouteHops(quote.BestRoute)
+
+	return CrossChainLeg{
+		Type:        "swap",
+		ChainID:     chainID,
+		TokenIn:     quote.TokenIn.Address.Hex(),
+		TokenOut:    quote.TokenOut.Address.Hex(),
+		AmountIn:    quote.AmountIn.String(),
+		AmountOut:   quote.AmountOut.String(),
+		Route:       route,
+		GasEstimate: quote.GasEstimate,
+	}
+}
+
+func (h *CrossChainHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
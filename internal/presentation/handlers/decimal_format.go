@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// minPlainExponent is the smallest power of ten shown in plain notation
+// when a format allows scientific: values under 1e-6 become e.g. 1.5e-9
+const minPlainExponent = -6
+
+// maxDisplayDecimals caps the fraction digits shown for a token, since
+// nobody reads the 18th decimal of an ether amount
+const maxDisplayDecimals = 8
+
+// decimalFormat is how a fixed-point value is shown to people. Unlike
+// formatUnits, which is exact, it rounds half away from zero and trims
+// trailing zeros.
+type decimalFormat struct {
+	sigDigits   int  // Significant digits kept, 0 for all; whole digits are never rounded off
+	maxDecimals int  // Fraction digits shown at most, 0 for all
+	scientific  bool // Values under 1e-6 as a mantissa and exponent
+}
+
+// usdFormat shows USD prices and values to six significant digits, so
+// $3456.79 and $0.0000123457 keep the same precision
+var usdFormat = decimalFormat{sigDigits: 6}
+
+// tokenFormat shows a value in token to six significant digits and no more
+// decimals than the token's display decimals
+func tokenFormat(token entities.Token) decimalFormat {
+	return decimalFormat{sigDigits: 6, maxDecimals: min(int(token.Decimals), maxDisplayDecimals)}
+}
+
+// format renders value / 10^decimals
+func (f decimalFormat) format(value *big.Int, decimals uint8) string {
+	if value == nil || value.Sign() == 0 {
+		return "0"
+	}
+	sign := ""
+	if value.Sign() < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(value)
+	digits := len(abs.String())
+	exp := digits - 1 - int(decimals) // Power of ten of the leading digit
+
+	if f.scientific && exp < minPlainExponent {
+		keep := digits
+		if f.sigDigits > 0 {
+			keep = min(keep, f.sigDigits)
+		}
+		mantissa := roundOff(abs, digits-keep)
+		if len(mantissa.String()) > keep {
+			exp++ // Rounded up to the next power of ten
+		}
+		return sign + formatUnits(mantissa, uint8(len(mantissa.String())-1)) + "e" + strconv.Itoa(exp)
+	}
+
+	keep := int(decimals)
+	if f.sigDigits > 0 {
+		keep = min(keep, max(0, f.sigDigits-1-exp))
+	}
+	if f.maxDecimals > 0 {
+		keep = min(keep, f.maxDecimals)
+	}
+	rounded := roundOff(abs, int(decimals)-keep)
+	if rounded.Sign() == 0 {
+		return "0"
+	}
+	return sign + formatUnits(rounded, uint8(keep))
+}
+
+// roundOff drops the last n digits of a non-negative value, rounding half up
+func roundOff(value *big.Int, n int) *big.Int {
+	if n <= 0 {
+		return value
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	rounded := new(big.Int).Add(value, new(big.Int).Rsh(unit, 1))
+	return rounded.Quo(rounded, unit)
+}
+
+// parseNotation applies the request's notation to f: plain, the default,
+// or scientific for values under 1e-6
+func parseNotation(r *http.Request, f decimalFormat) (decimalFormat, *apperror.Error) {
+	switch r.URL.Query().Get("notation") {
+	case "", "plain":
+		return f, nil
+	case "scientific":
+		f.scientific = true
+		return f, nil
+	}
+	return f, apperror.New(apperror.InvalidFormat, "notation must be plain or scientific")
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestDecimalFormat(t *testing.T) {
+	scientific := usdFormat
+	scientific.scientific = true
+
+	tests := []struct {
+		name     string
+		format   decimalFormat
+		value    string
+		decimals uint8
+		want     string
+	}{
+		{"usd price", usdFormat, "3456789012345678901234", 18, "3456.79"},
+		{"past two decimals", usdFormat, "1000123000000000000", 18, "1.00012"},
+		{"whole digits kept", usdFormat, "123456789123000000000000000", 18, "123456789"},
+		{"tiny price", usdFormat, "12345678", 18, "0.0000000000123457"},
+		{"smallest unit", usdFormat, "1", 18, "0.000000000000000001"},
+		{"rounds up a digit", usdFormat, "9999999000000000000", 18, "10"},
+		{"negative", usdFormat, "-1234567890000000000", 18, "-1.23457"},
+		{"zero", usdFormat, "0", 18, "0"},
+		{"scientific", scientific, "12345678", 18, "1.23457e-11"},
+		{"scientific smallest unit", scientific, "1", 18, "1e-18"},
+		{"scientific carries", scientific, "999999999", 18, "1e-9"},
+		{"scientific leaves 1e-6", scientific, "1000000000000", 18, "0.000001"},
+		{"token decimals", tokenFormat(entities.WETH), "1234567891234", 18, "0.00000123"},
+		{"token decimals to zero", tokenFormat(entities.WETH), "4", 18, "0"},
+		{"stablecoin", tokenFormat(entities.USDC), "3456789012", 6, "3456.79"},
+		{"no decimals", tokenFormat(entities.Token{}), "42", 0, "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := new(big.Int).SetString(tt.value, 10)
+			if got := tt.format.format(value, tt.decimals); got != tt.want {
+				t.Errorf("format(%s, %d) = %s, want %s", tt.value, tt.decimals, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	for query, scientific := range map[string]bool{"": false, "?notation=plain": false, "?notation=scientific": true} {
+		f, reqErr := parseNotation(httptest.NewRequest("GET", "/api/v1/price/WETH"+query, nil), usdFormat)
+		if reqErr != nil || f.scientific != scientific {
+			t.Errorf("%q: scientific = %v, err = %v; want %v", query, f.scientific, reqErr, scientific)
+		}
+	}
+	if _, reqErr := parseNotation(httptest.NewRequest("GET", "/api/v1/price/WETH?notation=engineering", nil), usdFormat); reqErr == nil {
+		t.Error("notation=engineering was accepted")
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// ErrorResponse is the v1 error body
+type ErrorResponse struct {
+	Error   string `json:"error"` // Lower-case code, kept for older clients
+	Code    string `json:"code"`
+	Message string `json:"message"` // In the client's Accept-Language
+	Detail  string `json:"detail,omitempty"`
+	// Diagnostic explains a quote without a route, with fallback=true
+	Diagnostic *RouteDiagnosticResp `json:"diagnostic,omitempty"`
+	// Retryable errors failed on a node or venue, not on the request
+	Retryable bool `json:"retryable,omitempty"`
+}
+
+// WriteError writes err as a v1 error body with its code's status. Errors
+// without a code are reported as internal errors.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := apperror.As(err, apperror.Internal)
+	lang := apperror.Language(r.Header.Get("Accept-Language"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", lang)
+	setRetryAfter(w, apiErr.Code)
+	w.WriteHeader(apiErr.Code.Status())
+	json.NewEncoder(w).Encode(newErrorResponse(apiErr, lang))
+}
+
+func newErrorResponse(apiErr *apperror.Error, lang string) ErrorResponse {
+	return ErrorResponse{
+		Error:   strings.ToLower(string(apiErr.Code)),
+		Code:    string(apiErr.Code),
+		Message: apiErr.Code.Message(lang),
+		Detail:  apiErr.Detail,
+
+		Diagnostic: diagnosticOf(apiErr),
+		Retryable:  apiErr.Code.Retryable(),
+	}
+}
+
+// upstreamRetryAfter is how long, in seconds, a client should wait before
+// retrying a request a node or venue failed
+const upstreamRetryAfter = "2"
+
+// setRetryAfter tells clients when to retry errors that failed upstream
+func setRetryAfter(w http.ResponseWriter, code apperror.Code) {
+	if code.Retryable() {
+		w.Header().Set("Retry-After", upstreamRetryAfter)
+	}
+}
+
+// diagnosticOf is the diagnostic of a fallback quote that found no route,
+// nil for any other error
+func diagnosticOf(err error) *RouteDiagnosticResp {
+	var noRoute *services.NoRouteError
+	if errors.As(err, &noRoute) {
+		return newRouteDiagnosticResp(noRoute.Diagnostic)
+	}
+	return nil
+}
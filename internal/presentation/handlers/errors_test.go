@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		acceptLanguage string
+		wantStatus     int
+		want           ErrorResponse
+	}{
+		{
+			name:       "coded",
+			err:        apperror.New(apperror.InvalidAmount, "amountIn must be positive"),
+			wantStatus: http.StatusBadRequest,
+			want: ErrorResponse{
+				Error:   "invalid_amount",
+				Code:    "INVALID_AMOUNT",
+				Message: "The amount is invalid.",
+				Detail:  "amountIn must be positive",
+			},
+		},
+		{
+			name:           "localized",
+			err:            apperror.New(apperror.NoRoute, "no valid routes found"),
+			acceptLanguage: "id-ID,id;q=0.9",
+			wantStatus:     http.StatusNotFound,
+			want: ErrorResponse{
+				Error:   "no_route",
+				Code:    "NO_ROUTE",
+				Message: "Tidak ditemukan rute antara token ini.",
+				Detail:  "no valid routes found",
+			},
+		},
+		{
+			name:       "wrapped code",
+			err:        fmt.Errorf("failed to route remainder: %w", apperror.New(apperror.RPCUnavailable, "every venue failed")),
+			wantStatus: http.StatusServiceUnavailable,
+			want: ErrorResponse{
+				Error:     "rpc_unavailable",
+				Code:      "RPC_UNAVAILABLE",
+				Message:   "The blockchain node is unavailable. Try again shortly.",
+				Detail:    "failed to route remainder: every venue failed",
+				Retryable: true,
+			},
+		},
+		{
+			name:       "partial upstream failure",
+			err:        apperror.New(apperror.UpstreamFailed, "1 of 3 venues failed to answer"),
+			wantStatus: http.StatusBadGateway,
+			want: ErrorResponse{
+				Error:     "upstream_failed",
+				Code:      "UPSTREAM_FAILED",
+				Message:   "Some liquidity sources failed to answer. Try again shortly.",
+				Detail:    "1 of 3 venues failed to answer",
+				Retryable: true,
+			},
+		},
+		{
+			name:       "uncoded",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			want: ErrorResponse{
+				Error:   "internal_error",
+				Code:    "INTERNAL_ERROR",
+				Message: "An internal error occurred.",
+				Detail:  "boom",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/quote", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			WriteError(rec, req, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if retryAfter := rec.Header().Get("Retry-After"); (retryAfter != "") != tt.want.Retryable {
+				t.Errorf("Retry-After = %q, want one only on retryable errors", retryAfter)
+			}
+			var got ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("body = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmountOverflow(t *testing.T) {
+	_, err := parseAmount("1e78", entities.WETH)
+	if !errors.Is(err, errAmountOverflow) {
+		t.Errorf("parseAmount(1e78) error = %v, want errAmountOverflow", err)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type ExecutionHandler struct {
+	executionService *services.ExecutionService
+	nameResolver     NameResolver
+	tokenRegistry    *entities.TokenRegistry
+}
+
+func NewExecutionHandler(executionService *services.ExecutionService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *ExecutionHandler {
+	return &ExecutionHandler{
+		executionService: executionService,
+		nameResolver:     nameResolver,
+		tokenRegistry:    tokenRegistry,
+	}
+}
+
+type ExecuteRequest struct {
+	QuoteRequest
+	SlippageBps uint64 `json:"slippageBps"`
+}
+
+type ExecutionResponse struct {
+	Hash          string   `json:"hash"`
+	From          string   `json:"from"`
+	Nonce         uint64   `json:"nonce"`
+	TokenIn       string   `json:"tokenIn"`
+	TokenOut      string   `json:"tokenOut"`
+	AmountIn      string   `json:"amountIn"`
+	MinAmountOut  string   `json:"minAmountOut"`
+	Status        string   `json:"status"`
+	Replaced      []string `json:"replaced,omitempty"`
+	BlockNumber   uint64   `json:"blockNumber,omitempty"`
+	Confirmations uint64   `json:"confirmations,omitempty"`
+	GasUsed       uint64   `json:"gasUsed,omitempty"`
+	SubmittedAt   int64    `json:"submittedAt"`
+}
+
+// Execute handles POST /api/v1/swap/execute
+func (h *ExecutionHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	var req ExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+
+	tokens, err := parseAddresses(r.Context(), h.nameResolver, req.TokenIn, req.TokenOut)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidToken, err))
+		return
+	}
+
+	amountIn, ok := new(big.Int).SetString(req.AmountIn, 10)
+	if !ok || amountIn.Sign() <= 0 {
+		WriteError(w, r, apperror.New(apperror.InvalidAmount, "amountIn must be a positive integer"))
+		return
+	}
+	if req.SlippageBps > 10000 {
+		WriteError(w, r, apperror.New(apperror.InvalidSlippage, "slippageBps must be 0-10000 basis points"))
+		return
+	}
+
+	record, err := h.executionService.Execute(r.Context(), h.tokenRegistry.Resolve(r.Context(), tokens[0]), h.tokenRegistry.Resolve(r.Context(), tokens[1]), amountIn, req.SlippageBps)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.ExecutionFailed))
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, newExecutionResponse(record))
+}
+
+// GetTransaction handles GET /api/v1/tx/{hash}
+func (h *ExecutionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/")
+	hashStr := parts[len(parts)-1]
+
+	hashBytes := common.FromHex(hashStr)
+	if len(hashBytes) != common.HashLength {
+		WriteError(w, r, apperror.New(apperror.InvalidHash, "invalid transaction hash"))
+		return
+	}
+
+	record, err := h.executionService.GetTransaction(r.Context(), common.BytesToHash(hashBytes))
+	if err != nil {
+		if errors.Is(err, services.ErrTxNotFound) {
+			WriteError(w, r, apperror.Wrap(apperror.TxNotFound, err))
+			return
+		}
+		WriteError(w, r, apperror.Wrap(apperror.RPCUnavailable, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, newExecutionResponse(record))
+}
+
+func newExecutionResponse(record *entities.ExecutionRecord) ExecutionResponse {
+	var replaced []string
+	for _, hash := range record.Replaced {
+		replaced = append(replaced, hash.Hex())
+	}
+
+	minAmountOut := ""
+	if record.MinAmountOut != nil {
+		minAmountOut = record.MinAmountOut.String()
+	}
+
+	return ExecutionResponse{
+		Hash:          record.Hash.Hex(),
+		From:          record.From.Hex(),
+		Nonce:         record.Nonce,
+		TokenIn:       record.TokenIn.Address.Hex(),
+		TokenOut:      record.TokenOut.Address.Hex(),
+		AmountIn:      record.AmountIn.String(),
+		MinAmountOut:  minAmountOut,
+		Status:        string(record.Status),
+		Replaced:      replaced,
+		BlockNumber:   record.BlockNumber,
+		Confirmations: record.Confirmations,
+		GasUsed:       record.GasUsed,
+		SubmittedAt:   record.SubmittedAt,
+	}
+}
+
+func (h *ExecutionHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/signer"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/txmanager"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestExecutionHandlerGetTransaction(t *testing.T) {
+	venue := testutil.NewFakeDEX(entities.DEXUniswapV2)
+	venue.SetPair(&entities.Pair{
+		Address:  common.HexToAddress("0x1111"),
+		Token0:   entities.USDC,
+		Token1:   entities.WETH,
+		Reserve0: big.NewInt(30_000_000e6),
+		Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+		DEX:      entities.DEXUniswapV2,
+		Fee:      30,
+	})
+	chain := testutil.NewFakeChain(150000, big.NewInt(10e9), big.NewInt(1e9))
+	key, _ := crypto.GenerateKey()
+	wallet, err := signer.NewPrivateKeySigner(common.Bytes2Hex(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := testutil.NewFakeClock(time.Now())
+	executionService := services.NewExecutionService(
+		services.NewRouterService(services.NewPriceService([]dex.DEXClient{venue}, testutil.NewFakeCache(clock.Now))),
+		services.NewSwapService(swap.NewBuilder(), chain),
+		services.NewFeeService(chain, nil),
+		txmanager.New(chain, wallet, big.NewInt(1), txmanager.DefaultConfig()),
+	)
+	h := NewExecutionHandler(executionService, entities.DefaultRegistry(), nil)
+
+	chain.RejectUnderpriced(1)
+	record, err := executionService.Execute(context.Background(), entities.WETH, entities.USDC, big.NewInt(1e18), 50)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hash       string
+		wantStatus int
+	}{
+		{"executed", record.Hash.Hex(), http.StatusOK},
+		{"unknown", common.HexToHash("0xdead").Hex(), http.StatusNotFound},
+		{"short", "0xdead", http.StatusBadRequest},
+		{"not hex", "swap", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetTransaction(w, httptest.NewRequest("GET", "/api/v1/tx/"+tt.hash, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp ExecutionResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Hash != record.Hash.Hex() || resp.From != wallet.Address().Hex() || resp.Status != string(entities.TxPending) {
+				t.Errorf("response = %+v, want the pending swap from the hot wallet", resp)
+			}
+			if resp.TokenIn != entities.WETH.Address.Hex() || resp.AmountIn != "1000000000000000000" || resp.MinAmountOut == "" {
+				t.Errorf("response = %+v, want 1 WETH in with a minimum out", resp)
+			}
+		})
+	}
+}
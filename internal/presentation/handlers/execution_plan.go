@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// Execution plan interval bounds, in seconds: about a block to a day
+const (
+	minPlanInterval = 12
+	maxPlanInterval = 86400
+)
+
+// planParams are the plan= options of a quote request
+type planParams struct {
+	slices    int // Zero leaves the count to the planner
+	interval  time.Duration
+	impactBps int64 // Plans are made for quotes over it, sized to keep slices under it
+}
+
+// ExecutionPlanResp is the TWAP schedule for a trade too large to send at once
+type ExecutionPlanResp struct {
+	AmountIn          string          `json:"amountIn"`
+	ExpectedAmountOut string          `json:"expectedAmountOut"`
+	SingleAmountOut   string          `json:"singleAmountOut"`
+	ImprovementBps    int64           `json:"improvementBps"`
+	TargetImpactBps   int64           `json:"targetImpactBps"`
+	IntervalSeconds   int64           `json:"intervalSeconds"`
+	Slices            []PlanSliceResp `json:"slices"`
+}
+
+type PlanSliceResp struct {
+	AmountIn    string `json:"amountIn"`
+	AmountOut   string `json:"amountOut"`
+	PriceImpact string `json:"priceImpact"`
+	ExecuteAt   int64  `json:"executeAt"` // Unix seconds
+}
+
+type ExecutionPlanRespV2 struct {
+	AmountIn          Amount            `json:"amountIn"`
+	ExpectedAmountOut Amount            `json:"expectedAmountOut"`
+	SingleAmountOut   Amount            `json:"singleAmountOut"`
+	ImprovementBps    int64             `json:"improvementBps"`
+	TargetImpactBps   int64             `json:"targetImpactBps"`
+	IntervalSeconds   int64             `json:"intervalSeconds"`
+	Slices            []PlanSliceRespV2 `json:"slices"`
+}
+
+type PlanSliceRespV2 struct {
+	AmountIn    Amount `json:"amountIn"`
+	AmountOut   Amount `json:"amountOut"`
+	PriceImpact string `json:"priceImpact"`
+	ExecuteAt   int64  `json:"executeAt"`
+}
+
+// PlanResponse is GET /api/v1/quote/plan's answer
+type PlanResponse struct {
+	TokenIn  string            `json:"tokenIn"`
+	TokenOut string            `json:"tokenOut"`
+	Plan     ExecutionPlanResp `json:"plan"`
+}
+
+// parsePlanParams reads planSlices, planInterval (seconds) and
+// planImpactBps
+func parsePlanParams(query url.Values) (*planParams, *apperror.Error) {
+	params := &planParams{
+		interval:  services.DefaultPlanInterval,
+		impactBps: services.PriceImpactWarningThreshold,
+	}
+	if s := query.Get("planSlices"); s != "" {
+		slices, err := strconv.Atoi(s)
+		if err != nil || slices < 1 || slices > services.MaxPlanSlices {
+			return nil, apperror.New(apperror.InvalidPlan, fmt.Sprintf("planSlices must be 1-%d", services.MaxPlanSlices))
+		}
+		params.slices = slices
+	}
+	if s := query.Get("planInterval"); s != "" {
+		seconds, err := strconv.Atoi(s)
+		if err != nil || seconds < minPlanInterval || seconds > maxPlanInterval {
+			return nil, apperror.New(apperror.InvalidPlan, fmt.Sprintf("planInterval must be %d-%d seconds", minPlanInterval, maxPlanInterval))
+		}
+		params.interval = time.Duration(seconds) * time.Second
+	}
+	if s := query.Get("planImpactBps"); s != "" {
+		bps, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || bps < 1 || bps > 10000 {
+			return nil, apperror.New(apperror.InvalidPlan, "planImpactBps must be 1-10000 basis points")
+		}
+		params.impactBps = bps
+	}
+	return params, nil
+}
+
+// sliceQuoter quotes slices of the request's trade, net of its
+// integrator fee
+func (h *QuoteHandler) sliceQuoter(params *quoteParams) services.SliceQuoter {
+	return func(ctx context.Context, amountIn *big.Int) (*entities.Quote, error) {
+		quote, err := h.routerService.PlanQuote(ctx, params.strategy, params.tokenIn, params.tokenOut, amountIn)
+		if err != nil {
+			return nil, err
+		}
+		if quote == nil {
+			return nil, fmt.Errorf("no route for %s", amountIn)
+		}
+		if params.feeBps > 0 {
+			services.ApplyIntegratorFee(quote, params.feeBps, params.feeTo)
+		}
+		return quote, nil
+	}
+}
+
+// attachPlan plans quotes whose price impact is over the request's
+// threshold. A market maker's fill has no impact to spread out.
+func (h *QuoteHandler) attachPlan(ctx context.Context, params *quoteParams, quote *entities.Quote) {
+	plan := params.plan
+	if quote.RFQOrder != nil || quote.PriceImpact == nil || quote.PriceImpact.Cmp(big.NewInt(plan.impactBps)) <= 0 {
+		return
+	}
+	// The quote is served without a plan rather than failed
+	quote.ExecutionPlan, _ = services.PlanExecution(ctx, h.sliceQuoter(params), quote, plan.slices, plan.impactBps, plan.interval)
+}
+
+// GetPlan handles GET /api/v1/quote/plan. It takes the quote parameters
+// with amountIn as what is left to trade, and plans it afresh whatever its
+// impact, so a caller re-plans after each slice fills.
+func (h *QuoteHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("amounts") != "" {
+		WriteError(w, r, apperror.New(apperror.InvalidAmount, "amounts can't be planned"))
+		return
+	}
+	params, reqErr := h.parseQuoteValues(r.Context(), query)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	if params.plan == nil {
+		if params.plan, reqErr = parsePlanParams(query); reqErr != nil {
+			WriteError(w, r, reqErr)
+			return
+		}
+	}
+	if reqErr := h.checkBlocked(params); reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	ctx := services.WithPairSnapshot(r.Context())
+	if params.minLiqUSD != nil {
+		ctx = services.WithMinPoolLiquidity(ctx, params.minLiqUSD)
+	}
+	quoteSlice := h.sliceQuoter(params)
+	single, err := quoteSlice(ctx, params.amountIn)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.NoRoute))
+		return
+	}
+	plan, err := services.PlanExecution(ctx, quoteSlice, single, params.plan.slices, params.plan.impactBps, params.plan.interval)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.NoRoute))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, PlanResponse{
+		TokenIn:  params.tokenIn.Address.Hex(),
+		TokenOut: params.tokenOut.Address.Hex(),
+		Plan:     *newExecutionPlanResp(plan),
+	})
+}
+
+func newExecutionPlanResp(plan *entities.ExecutionPlan) *ExecutionPlanResp {
+	if plan == nil {
+		return nil
+	}
+	resp := &ExecutionPlanResp{
+		AmountIn:          plan.AmountIn.String(),
+		ExpectedAmountOut: plan.ExpectedAmountOut.String(),
+		SingleAmountOut:   plan.SingleAmountOut.String(),
+		ImprovementBps:    plan.ImprovementBps,
+		TargetImpactBps:   plan.TargetImpactBps,
+		IntervalSeconds:   int64(plan.Interval / time.Second),
+		Slices:            make([]PlanSliceResp, len(plan.Slices)),
+	}
+	for i, slice := range plan.Slices {
+		resp.Slices[i] = PlanSliceResp{
+			AmountIn:    slice.AmountIn.String(),
+			AmountOut:   slice.AmountOut.String(),
+			PriceImpact: optBps(slice.PriceImpact),
+			ExecuteAt:   slice.ExecuteAt.Unix(),
+		}
+	}
+	return resp
+}
+
+func newExecutionPlanRespV2(plan *entities.ExecutionPlan, tokenIn, tokenOut entities.Token) *ExecutionPlanRespV2 {
+	if plan == nil {
+		return nil
+	}
+	resp := &ExecutionPlanRespV2{
+		AmountIn:          newAmount(plan.AmountIn, tokenIn.Decimals),
+		ExpectedAmountOut: newAmount(plan.ExpectedAmountOut, tokenOut.Decimals),
+		SingleAmountOut:   newAmount(plan.SingleAmountOut, tokenOut.Decimals),
+		ImprovementBps:    plan.ImprovementBps,
+		TargetImpactBps:   plan.TargetImpactBps,
+		IntervalSeconds:   int64(plan.Interval / time.Second),
+		Slices:            make([]PlanSliceRespV2, len(plan.Slices)),
+	}
+	for i, slice := range plan.Slices {
+		resp.Slices[i] = PlanSliceRespV2{
+			AmountIn:    newAmount(slice.AmountIn, tokenIn.Decimals),
+			AmountOut:   newAmount(slice.AmountOut, tokenOut.Decimals),
+			PriceImpact: optBps(slice.PriceImpact),
+			ExecuteAt:   slice.ExecuteAt.Unix(),
+		}
+	}
+	return resp
+}
+
+// optBps formats a price impact in basis points, "0" when unknown
+func optBps(bps *big.Int) string {
+	if bps == nil {
+		return "0"
+	}
+	return bps.String()
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+func TestParsePlanParams(t *testing.T) {
+	params, reqErr := parsePlanParams(url.Values{})
+	if reqErr != nil {
+		t.Fatalf("parsePlanParams() error = %v", reqErr)
+	}
+	if params.slices != 0 || params.interval != services.DefaultPlanInterval || params.impactBps != services.PriceImpactWarningThreshold {
+		t.Errorf("defaults = %+v", params)
+	}
+
+	params, reqErr = parsePlanParams(url.Values{"planSlices": {"4"}, "planInterval": {"60"}, "planImpactBps": {"30"}})
+	if reqErr != nil {
+		t.Fatalf("parsePlanParams() error = %v", reqErr)
+	}
+	if params.slices != 4 || params.interval != time.Minute || params.impactBps != 30 {
+		t.Errorf("parsePlanParams() = %+v", params)
+	}
+
+	for _, q := range []url.Values{
+		{"planSlices": {"0"}},
+		{"planSlices": {"25"}},
+		{"planInterval": {"1"}},
+		{"planInterval": {"5m"}},
+		{"planImpactBps": {"0"}},
+	} {
+		if _, reqErr := parsePlanParams(q); reqErr == nil || reqErr.Code != apperror.InvalidPlan {
+			t.Errorf("parsePlanParams(%v) error = %v, want %s", q, reqErr, apperror.InvalidPlan)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type FeeHandler struct {
+	ledger       *services.FeeLedger
+	nameResolver NameResolver
+}
+
+func NewFeeHandler(ledger *services.FeeLedger, nameResolver NameResolver) *FeeHandler {
+	return &FeeHandler{
+		ledger:       ledger,
+		nameResolver: nameResolver,
+	}
+}
+
+type FeeAccrualResp struct {
+	Token  string `json:"token"`
+	Amount string `json:"amount"`
+	Swaps  uint64 `json:"swaps"`
+	// Claimed has been withdrawn from the collector; Claimable is the rest
+	Claimed   string `json:"claimed"`
+	Claimable string `json:"claimable"`
+	Claims    uint64 `json:"claims"`
+}
+
+type FeeAccrualsResponse struct {
+	Recipient    string           `json:"recipient"`
+	ThroughBlock uint64           `json:"throughBlock"` // Last block scanned for fee logs
+	Accruals     []FeeAccrualResp `json:"accruals"`
+}
+
+// GetAccrued handles GET /api/v1/fees/{recipient}
+func (h *FeeHandler) GetAccrued(w http.ResponseWriter, r *http.Request) {
+	recipient, err := parseAddress(r.Context(), h.nameResolver, chi.URLParam(r, "recipient"))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidRecipient, err))
+		return
+	}
+
+	accruals, through := h.ledger.Accrued(recipient)
+	response := FeeAccrualsResponse{
+		Recipient:    recipient.Hex(),
+		ThroughBlock: through,
+		Accruals:     make([]FeeAccrualResp, 0, len(accruals)),
+	}
+	for _, accrual := range accruals {
+		response.Accruals = append(response.Accruals, FeeAccrualResp{
+			Token:  accrual.Token.Hex(),
+			Amount: accrual.Amount.String(),
+			Swaps:  accrual.Swaps,
+
+			Claimed:   accrual.Claimed.String(),
+			Claimable: accrual.Claimable().String(),
+			Claims:    accrual.Claims,
+		})
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+type FeeClaimResponse struct {
+	Recipient    string          `json:"recipient"`
+	Token        string          `json:"token"`
+	Amount       string          `json:"amount"`
+	ThroughBlock uint64          `json:"throughBlock"`
+	Transaction  TransactionResp `json:"transaction"`
+}
+
+// PrepareClaim handles GET /api/v1/fees/{recipient}/claim?token=&to=
+func (h *FeeHandler) PrepareClaim(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	recipient, err := parseAddress(r.Context(), h.nameResolver, chi.URLParam(r, "recipient"))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidRecipient, err))
+		return
+	}
+	if !common.IsHexAddress(q.Get("token")) {
+		WriteError(w, r, apperror.New(apperror.InvalidToken, "token must be the fee token's address"))
+		return
+	}
+	token := common.HexToAddress(q.Get("token"))
+	to := recipient
+	if q.Get("to") != "" {
+		if to, err = parseAddress(r.Context(), h.nameResolver, q.Get("to")); err != nil {
+			WriteError(w, r, apperror.Wrap(apperror.InvalidReceiver, err))
+			return
+		}
+	}
+
+	claim, err := h.ledger.PrepareClaim(recipient, token, to)
+	if err != nil {
+		if errors.Is(err, services.ErrNothingToClaim) {
+			WriteError(w, r, apperror.Wrap(apperror.NothingToClaim, err))
+			return
+		}
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, FeeClaimResponse{
+		Recipient:    claim.Recipient.Hex(),
+		Token:        claim.Token.Hex(),
+		Amount:       claim.Amount.String(),
+		ThroughBlock: claim.ThroughBlock,
+		Transaction:  newTransactionResp(claim.Transaction),
+	})
+}
+
+type FeeReconciliationResp struct {
+	Token   string `json:"token"`
+	Ledger  string `json:"ledger"`  // Claimable according to the collector's logs
+	Onchain string `json:"onchain"` // The collector's accruedFees
+	Matches bool   `json:"matches"`
+}
+
+type FeeReconciliationsResponse struct {
+	Recipient       string                  `json:"recipient"`
+	Block           uint64                  `json:"block"`
+	Matched         bool                    `json:"matched"`
+	Reconciliations []FeeReconciliationResp `json:"reconciliations"`
+}
+
+// Reconcile handles GET /api/v1/fees/{recipient}/reconcile
+func (h *FeeHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	recipient, err := parseAddress(r.Context(), h.nameResolver, chi.URLParam(r, "recipient"))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidRecipient, err))
+		return
+	}
+
+	reconciliations, block, err := h.ledger.Reconcile(r.Context(), recipient)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.RPCUnavailable, err))
+		return
+	}
+	response := FeeReconciliationsResponse{
+		Recipient:       recipient.Hex(),
+		Block:           block,
+		Matched:         true,
+		Reconciliations: make([]FeeReconciliationResp, 0, len(reconciliations)),
+	}
+	for _, rec := range reconciliations {
+		response.Matched = response.Matched && rec.Matches()
+		response.Reconciliations = append(response.Reconciliations, FeeReconciliationResp{
+			Token:   rec.Token.Hex(),
+			Ledger:  rec.Ledger.String(),
+			Onchain: rec.Onchain.String(),
+			Matches: rec.Matches(),
+		})
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func (h *FeeHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
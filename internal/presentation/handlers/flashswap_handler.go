@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type FlashSwapHandler struct {
+	swapService *services.SwapService
+}
+
+func NewFlashSwapHandler(swapService *services.SwapService) *FlashSwapHandler {
+	return &FlashSwapHandler{
+		swapService: swapService,
+	}
+}
+
+type FlashSwapRequest struct {
+	Receiver  string            `json:"receiver"` // Contract implementing the flash callback
+	AmountIn  string            `json:"amountIn"`
+	MinProfit string            `json:"minProfit,omitempty"`
+	Hops      []CycleHopRequest `json:"hops"`
+}
+
+type CycleHopRequest struct {
+	DEX       string `json:"dex"`
+	Pool      string `json:"pool"`
+	TokenIn   string `json:"tokenIn"`
+	TokenOut  string `json:"tokenOut"`
+	Fee       uint64 `json:"fee,omitempty"`
+	AmountOut string `json:"amountOut"`
+}
+
+type FlashSwapResponse struct {
+	Transaction    TransactionResp `json:"transaction"`
+	Callback       string          `json:"callback"`
+	CallbackData   string          `json:"callbackData"`
+	RepayToken     string          `json:"repayToken"`
+	RepayAmount    string          `json:"repayAmount"`
+	ExpectedProfit string          `json:"expectedProfit"`
+}
+
+// BuildFlashSwap handles POST /api/v1/flashswap
+func (h *FlashSwapHandler) BuildFlashSwap(w http.ResponseWriter, r *http.Request) {
+	var req FlashSwapRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+
+	if !common.IsHexAddress(req.Receiver) {
+		WriteError(w, r, apperror.New(apperror.InvalidReceiver, "receiver must be a contract address"))
+		return
+	}
+
+	cycle, err := parseCycle(req)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidCycle, err))
+		return
+	}
+
+	minProfit := big.NewInt(0)
+	if req.MinProfit != "" {
+		var ok bool
+		minProfit, ok = new(big.Int).SetString(req.MinProfit, 10)
+		if !ok || minProfit.Sign() < 0 {
+			WriteError(w, r, apperror.New(apperror.InvalidAmount, "minProfit must be a non-negative integer"))
+			return
+		}
+	}
+
+	flash, err := h.swapService.BuildFlashSwap(r.Context(), cycle, common.HexToAddress(req.Receiver), minProfit)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidCycle, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, FlashSwapResponse{
+		Transaction: TransactionResp{
+			From:  flash.Transaction.From.Hex(),
+			To:    flash.Transaction.To.Hex(),
+			Data:  hexutil.Encode(flash.Transaction.Data),
+			Value: flash.Transaction.Value.String(),
+			Gas:   flash.Transaction.Gas,
+		},
+		Callback:       flash.Callback,
+		CallbackData:   hexutil.Encode(flash.CallbackData),
+		RepayToken:     flash.RepayToken.Hex(),
+		RepayAmount:    flash.RepayAmount.String(),
+		ExpectedProfit: flash.ExpectedProfit.String(),
+	})
+}
+
+// parseCycle converts the request's legs; the builder checks they form a cycle
+func parseCycle(req FlashSwapRequest) (*entities.ArbitrageCycle, error) {
+	amountIn, ok := new(big.Int).SetString(req.AmountIn, 10)
+	if !ok {
+		return nil, fmt.Errorf("amountIn must be an integer")
+	}
+
+	cycle := &entities.ArbitrageCycle{AmountIn: amountIn}
+	for i, hop := range req.Hops {
+		for _, addr := range []string{hop.Pool, hop.TokenIn, hop.TokenOut} {
+			if !common.IsHexAddress(addr) {
+				return nil, fmt.Errorf("hop %d: invalid address %q", i, addr)
+			}
+		}
+		amountOut, ok := new(big.Int).SetString(hop.AmountOut, 10)
+		if !ok {
+			return nil, fmt.Errorf("hop %d: amountOut must be an integer", i)
+		}
+		cycle.Hops = append(cycle.Hops, entities.CycleHop{
+			DEX:       entities.DEXType(hop.DEX),
+			Pool:      common.HexToAddress(hop.Pool),
+			TokenIn:   common.HexToAddress(hop.TokenIn),
+			TokenOut:  common.HexToAddress(hop.TokenOut),
+			Fee:       hop.Fee,
+			AmountOut: amountOut,
+		})
+	}
+	return cycle, nil
+}
+
+func (h *FlashSwapHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type GasHandler struct {
+	calibrator *services.GasCalibrator
+}
+
+func NewGasHandler(calibrator *services.GasCalibrator) *GasHandler {
+	return &GasHandler{calibrator: calibrator}
+}
+
+type GasStatsResponse struct {
+	Venues []entities.GasStats `json:"venues"`
+}
+
+// GetStats handles GET /api/v1/admin/gas
+func (h *GasHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(GasStatsResponse{Venues: h.calibrator.Stats()})
+}
@@ -0,0 +1,672 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+// maxGraphQLBatch caps the number of operations in one batched request
+const maxGraphQLBatch = 20
+
+// graphQLSchema mirrors the REST API. Amounts are base-unit strings and
+// prices are USD strings, as in v1.
+const graphQLSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	quote(
+		tokenIn: String!
+		tokenOut: String!
+		amountIn: String!
+		slippage: Int
+		deadline: Int
+		strategy: String
+		optimizeFor: String
+		sender: String
+		recipient: String
+		feeBps: Int
+		feeRecipient: String
+	): Quote!
+	price(token: String!): Price!
+	token(token: String!): Token!
+	tokens: [Token!]!
+	pools(dex: String, token: String, sort: String, order: String, offset: Int, limit: Int): PoolList!
+	gasPrice: GasPrice!
+}
+
+type Token {
+	address: String!
+	symbol: String!
+	name: String
+	decimals: Int!
+}
+
+type Quote {
+	tokenIn: Token!
+	tokenOut: Token!
+	amountIn: String!
+	amountOut: String!
+	amountInUsd: String
+	amountOutUsd: String
+	minAmountOut: String
+	slippageBps: Int!
+	quoteId: String
+	expiresAt: Int!
+	priceImpact: String!
+	priceImpactUsd: String
+	priceWarning: String
+	gasEstimate: Int!
+	quotedAtBlock: Int
+	strategy: String
+	optimizeFor: String
+	route: [RouteHop!]!
+	splitRoutes: [SplitRoute!]!
+	tokenWarnings: [TokenWarning!]!
+	integratorFee: IntegratorFee
+	gasCost: GasCost
+	transaction: Transaction
+	sources: [Source!]!
+}
+
+type RouteHop {
+	dex: String!
+	pair: String!
+	tokenIn: String!
+	tokenOut: String!
+	fee: Int!
+	amountIn: String
+	amountOut: String
+}
+
+type SplitRoute {
+	dex: String!
+	percentage: Int!
+	amountIn: String!
+	amountOut: String!
+	route: [RouteHop!]!
+}
+
+type TokenWarning {
+	token: String!
+	code: String!
+	message: String!
+}
+
+type IntegratorFee {
+	bps: Int!
+	recipient: String!
+	amount: String!
+}
+
+type GasCost {
+	baseFeePerGas: String!
+	maxFeePerGas: String!
+	maxPriorityFeePerGas: String!
+	costWei: String!
+	costEth: String!
+	costUsd: String
+}
+
+type Transaction {
+	from: String!
+	to: String!
+	data: String!
+	value: String!
+	gas: Int
+}
+
+type Source {
+	dex: String!
+	amountOut: String
+	gasEstimate: Int
+	liquidityScore: Int!
+	latencyMs: Int!
+	error: String
+}
+
+type Price {
+	token: Token!
+	priceUsd: String!
+	depegWarning: String
+	updatedAt: String!
+}
+
+type Pool {
+	dex: String!
+	address: String!
+	tokens: [Token!]!
+	feeTier: Int
+	tvlUsd: String!
+	volume24hUsd: String!
+}
+
+type PoolList {
+	pools: [Pool!]!
+	total: Int!
+	offset: Int!
+	limit: Int!
+}
+
+type GasPrice {
+	baseFeePerGas: String!
+	maxFeePerGas: String!
+	maxPriorityFeePerGas: String!
+}
+`
+
+// GraphQLHandler serves quotes, prices, tokens, pools and gas prices over
+// GraphQL so clients can fetch several of them in one round-trip
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler resolves through the REST handlers so both APIs share
+// validation. poolHandler may be nil when pool stats are disabled.
+func NewGraphQLHandler(quoteHandler *QuoteHandler, priceHandler *PriceHandler, poolHandler *PoolHandler) *GraphQLHandler {
+	resolver := &graphQLResolver{
+		quotes: quoteHandler,
+		prices: priceHandler,
+		pools:  poolHandler,
+	}
+	return &GraphQLHandler{
+		schema: graphql.MustParseSchema(graphQLSchema, resolver, graphql.UseFieldResolvers()),
+	}
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP handles POST /graphql. The body is either one request or a JSON
+// array of up to maxGraphQLBatch requests, answered in the same order.
+func (h *GraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidRequest, "invalid JSON body"))
+		return
+	}
+
+	batch := bytes.HasPrefix(bytes.TrimSpace(body), []byte("["))
+	var requests []graphQLRequest
+	if batch {
+		if err := json.Unmarshal(body, &requests); err != nil {
+			WriteError(w, r, apperror.New(apperror.InvalidRequest, "invalid batch: "+err.Error()))
+			return
+		}
+		if len(requests) == 0 || len(requests) > maxGraphQLBatch {
+			WriteError(w, r, apperror.New(apperror.InvalidBatch, "batch must hold 1-"+strconv.Itoa(maxGraphQLBatch)+" requests"))
+			return
+		}
+	} else {
+		var req graphQLRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			WriteError(w, r, apperror.New(apperror.InvalidRequest, "invalid request: "+err.Error()))
+			return
+		}
+		requests = []graphQLRequest{req}
+	}
+
+	responses := make([]*graphql.Response, len(requests))
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = h.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+		}()
+	}
+	wg.Wait()
+
+	if batch {
+		h.writeJSON(w, http.StatusOK, responses)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, responses[0])
+}
+
+func (h *GraphQLHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// graphQLError reports an API error with its code in the error extensions
+type graphQLError struct {
+	err *apperror.Error
+}
+
+func (e graphQLError) Error() string {
+	return e.err.Detail
+}
+
+func (e graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.err.Code}
+}
+
+type graphQLResolver struct {
+	quotes *QuoteHandler
+	prices *PriceHandler
+	pools  *PoolHandler // Nil when pool stats are disabled
+}
+
+type gqlQuoteArgs struct {
+	TokenIn      string
+	TokenOut     string
+	AmountIn     string
+	Slippage     *int32
+	Deadline     *int32
+	Strategy     *string
+	OptimizeFor  *string
+	Sender       *string
+	Recipient    *string
+	FeeBps       *int32
+	FeeRecipient *string
+}
+
+func (r *graphQLResolver) Quote(ctx context.Context, args gqlQuoteArgs) (*gqlQuote, error) {
+	values := url.Values{}
+	values.Set("tokenIn", args.TokenIn)
+	values.Set("tokenOut", args.TokenOut)
+	values.Set("amountIn", args.AmountIn)
+	setIntValue(values, "slippage", args.Slippage)
+	setIntValue(values, "deadline", args.Deadline)
+	setIntValue(values, "feeBps", args.FeeBps)
+	setStringValue(values, "strategy", args.Strategy)
+	setStringValue(values, "optimizeFor", args.OptimizeFor)
+	setStringValue(values, "sender", args.Sender)
+	setStringValue(values, "recipient", args.Recipient)
+	setStringValue(values, "feeRecipient", args.FeeRecipient)
+
+	params, reqErr := r.quotes.parseQuoteValues(ctx, values)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+	quote, reqErr := r.quotes.quote(ctx, params)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+	tokenIn, tokenOut := r.quotes.quoteTokens(quote)
+	return newGQLQuote(quote, tokenIn, tokenOut, r.quotes.buildQuoteResponse(quote, true)), nil
+}
+
+func (r *graphQLResolver) Price(ctx context.Context, args struct{ Token string }) (*gqlPrice, error) {
+	token, reqErr := r.quotes.resolveToken(ctx, "token", args.Token)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+	price, err := r.prices.priceService.GetTokenPrice(ctx, token)
+	if err != nil {
+		return nil, graphQLError{apperror.As(err, apperror.PriceNotFound)}
+	}
+	return &gqlPrice{
+		Token:        newGQLToken(token),
+		PriceUSD:     usdFormat.format(price, priceDecimals),
+		DepegWarning: optString(r.prices.priceService.DepegWarning(token)),
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+func (r *graphQLResolver) Token(ctx context.Context, args struct{ Token string }) (*gqlToken, error) {
+	token, reqErr := r.quotes.resolveToken(ctx, "token", args.Token)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+	resp := newGQLToken(token)
+	return &resp, nil
+}
+
+func (r *graphQLResolver) Tokens() []gqlToken {
+	tokens := r.quotes.tokenRegistry.GetAll()
+	resp := make([]gqlToken, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, newGQLToken(token))
+	}
+	return resp
+}
+
+type gqlPoolArgs struct {
+	DEX    *string
+	Token  *string
+	Sort   *string
+	Order  *string
+	Offset *int32
+	Limit  *int32
+}
+
+func (r *graphQLResolver) Pools(ctx context.Context, args gqlPoolArgs) (*gqlPoolList, error) {
+	if r.pools == nil {
+		return nil, graphQLError{apperror.New(apperror.PoolsDisabled, "pool stats are not enabled")}
+	}
+
+	values := url.Values{}
+	setStringValue(values, "dex", args.DEX)
+	setStringValue(values, "token", args.Token)
+	setStringValue(values, "sort", args.Sort)
+	setStringValue(values, "order", args.Order)
+	setIntValue(values, "offset", args.Offset)
+	setIntValue(values, "limit", args.Limit)
+
+	query, reqErr := r.pools.parsePoolQuery(ctx, values)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+	list, reqErr := r.pools.listPools(query)
+	if reqErr != nil {
+		return nil, graphQLError{reqErr}
+	}
+
+	resp := &gqlPoolList{
+		Pools:  make([]gqlPool, 0, len(list.Data)),
+		Total:  gqlInt(list.Total),
+		Offset: gqlInt(query.Offset),
+		Limit:  gqlInt(query.Limit),
+	}
+	for _, p := range list.Data {
+		tokens := make([]gqlToken, 0, len(p.Tokens))
+		for _, t := range p.Tokens {
+			tokens = append(tokens, gqlToken{Address: t.Address, Symbol: t.Symbol, Decimals: int32(t.Decimals)})
+		}
+		pool := gqlPool{
+			DEX:          p.DEX,
+			Address:      p.Address,
+			Tokens:       tokens,
+			TVLUSD:       p.TVLUSD,
+			Volume24hUSD: p.Volume24hUSD,
+		}
+		if p.FeeTier > 0 {
+			fee := gqlInt(p.FeeTier)
+			pool.FeeTier = &fee
+		}
+		resp.Pools = append(resp.Pools, pool)
+	}
+	return resp, nil
+}
+
+func (r *graphQLResolver) GasPrice(ctx context.Context) (*gqlGasPrice, error) {
+	if r.quotes.feeService == nil {
+		return nil, graphQLError{apperror.New(apperror.GasPriceUnavailable, "fee suggestions are not enabled")}
+	}
+	fees, err := r.quotes.feeService.SuggestFees(ctx)
+	if err != nil {
+		return nil, graphQLError{apperror.Wrap(apperror.GasPriceUnavailable, err)}
+	}
+	return &gqlGasPrice{
+		BaseFeePerGas:        fees.BaseFeePerGas.String(),
+		MaxFeePerGas:         fees.MaxFeePerGas.String(),
+		MaxPriorityFeePerGas: fees.MaxPriorityFeePerGas.String(),
+	}, nil
+}
+
+// Resolver types below are matched to the schema by field name
+
+type gqlToken struct {
+	Address  string
+	Symbol   string
+	Name     *string
+	Decimals int32
+}
+
+type gqlQuote struct {
+	TokenIn        gqlToken
+	TokenOut       gqlToken
+	AmountIn       string
+	AmountOut      string
+	AmountInUSD    *string
+	AmountOutUSD   *string
+	MinAmountOut   *string
+	SlippageBps    int32
+	QuoteID        *string
+	ExpiresAt      int32
+	PriceImpact    string
+	PriceImpactUSD *string
+	PriceWarning   *string
+	GasEstimate    int32
+	QuotedAtBlock  *int32
+	Strategy       *string
+	OptimizeFor    *string
+	Route          []gqlRouteHop
+	SplitRoutes    []gqlSplitRoute
+	TokenWarnings  []TokenWarningResp
+	IntegratorFee  *gqlIntegratorFee
+	GasCost        *gqlGasCost
+	Transaction    *gqlTransaction
+	Sources        []gqlSource
+}
+
+type gqlRouteHop struct {
+	DEX       string
+	Pair      string
+	TokenIn   string
+	TokenOut  string
+	Fee       int32
+	AmountIn  *string
+	AmountOut *string
+}
+
+type gqlSplitRoute struct {
+	DEX        string
+	Percentage int32
+	AmountIn   string
+	AmountOut  string
+	Route      []gqlRouteHop
+}
+
+type gqlIntegratorFee struct {
+	Bps       int32
+	Recipient string
+	Amount    string
+}
+
+type gqlGasCost struct {
+	BaseFeePerGas        string
+	MaxFeePerGas         string
+	MaxPriorityFeePerGas string
+	CostWei              string
+	CostEth              string
+	CostUSD              *string
+}
+
+type gqlTransaction struct {
+	From  string
+	To    string
+	Data  string
+	Value string
+	Gas   *int32
+}
+
+type gqlSource struct {
+	DEX            string
+	AmountOut      *string
+	GasEstimate    *int32
+	LiquidityScore int32
+	LatencyMs      int32
+	Error          *string
+}
+
+type gqlPrice struct {
+	Token        gqlToken
+	PriceUSD     string
+	DepegWarning *string
+	UpdatedAt    string
+}
+
+type gqlPool struct {
+	DEX          string
+	Address      string
+	Tokens       []gqlToken
+	FeeTier      *int32
+	TVLUSD       string
+	Volume24hUSD string
+}
+
+type gqlPoolList struct {
+	Pools  []gqlPool
+	Total  int32
+	Offset int32
+	Limit  int32
+}
+
+type gqlGasPrice struct {
+	BaseFeePerGas        string
+	MaxFeePerGas         string
+	MaxPriorityFeePerGas string
+}
+
+func newGQLToken(token entities.Token) gqlToken {
+	return gqlToken{
+		Address:  token.Address.Hex(),
+		Symbol:   token.Symbol,
+		Name:     optString(token.Name),
+		Decimals: int32(token.Decimals),
+	}
+}
+
+// newGQLQuote converts the v1 response, which already formats every amount
+func newGQLQuote(quote *entities.Quote, tokenIn, tokenOut entities.Token, v1 QuoteResponse) *gqlQuote {
+	resp := &gqlQuote{
+		TokenIn:        newGQLToken(tokenIn),
+		TokenOut:       newGQLToken(tokenOut),
+		AmountIn:       v1.AmountIn,
+		AmountOut:      v1.AmountOut,
+		AmountInUSD:    optString(v1.AmountInUSD),
+		AmountOutUSD:   optString(v1.AmountOutUSD),
+		MinAmountOut:   optString(v1.MinAmountOut),
+		SlippageBps:    gqlInt(v1.SlippageBps),
+		QuoteID:        optString(v1.QuoteID),
+		ExpiresAt:      gqlInt(v1.ExpiresAt),
+		PriceImpact:    v1.PriceImpact,
+		PriceImpactUSD: optString(v1.PriceImpactUSD),
+		PriceWarning:   optString(v1.PriceWarning),
+		GasEstimate:    gqlInt(v1.GasEstimate),
+		QuotedAtBlock:  optInt(v1.QuotedAtBlock),
+		Strategy:       optString(v1.Strategy),
+		OptimizeFor:    optString(v1.OptimizeFor),
+		Route:          newGQLRouteHops(v1.Route),
+		SplitRoutes:    make([]gqlSplitRoute, 0, len(v1.SplitRoutes)),
+		TokenWarnings:  v1.TokenWarnings,
+		Sources:        make([]gqlSource, 0, len(v1.SourceDetails)),
+	}
+	if resp.TokenWarnings == nil {
+		resp.TokenWarnings = []TokenWarningResp{}
+	}
+
+	for _, sr := range v1.SplitRoutes {
+		resp.SplitRoutes = append(resp.SplitRoutes, gqlSplitRoute{
+			DEX:        sr.DEX,
+			Percentage: gqlInt(sr.Percentage),
+			AmountIn:   sr.AmountIn,
+			AmountOut:  sr.AmountOut,
+			Route:      newGQLRouteHops(sr.Route),
+		})
+	}
+	for _, sd := range v1.SourceDetails {
+		resp.Sources = append(resp.Sources, gqlSource{
+			DEX:            sd.DEX,
+			AmountOut:      optString(sd.AmountOut),
+			GasEstimate:    optInt(sd.GasEstimate),
+			LiquidityScore: gqlInt(sd.LiquidityScore),
+			LatencyMs:      gqlInt(sd.LatencyMs),
+			Error:          optString(sd.Error),
+		})
+	}
+
+	if fee := v1.IntegratorFee; fee != nil {
+		resp.IntegratorFee = &gqlIntegratorFee{
+			Bps:       gqlInt(fee.Bps),
+			Recipient: fee.Recipient,
+			Amount:    fee.Amount,
+		}
+	}
+	if cost := v1.GasCost; cost != nil {
+		resp.GasCost = &gqlGasCost{
+			BaseFeePerGas:        cost.BaseFeePerGas,
+			MaxFeePerGas:         cost.MaxFeePerGas,
+			MaxPriorityFeePerGas: cost.MaxPriorityFeePerGas,
+			CostWei:              cost.CostWei,
+			CostEth:              cost.CostEth,
+			CostUSD:              optString(cost.CostUSD),
+		}
+	}
+	if tx := v1.Transaction; tx != nil {
+		resp.Transaction = &gqlTransaction{
+			From:  tx.From,
+			To:    tx.To,
+			Data:  tx.Data,
+			Value: tx.Value,
+			Gas:   optInt(tx.Gas),
+		}
+	}
+	return resp
+}
+
+func newGQLRouteHops(hops []RouteHop) []gqlRouteHop {
+	resp := make([]gqlRouteHop, 0, len(hops))
+	for _, hop := range hops {
+		resp = append(resp, gqlRouteHop{
+			DEX:       hop.DEX,
+			Pair:      hop.Pair,
+			TokenIn:   hop.TokenIn,
+			TokenOut:  hop.TokenOut,
+			Fee:       gqlInt(hop.Fee),
+			AmountIn:  optString(hop.AmountIn),
+			AmountOut: optString(hop.AmountOut),
+		})
+	}
+	return resp
+}
+
+// gqlInt converts to a GraphQL Int, saturating at the 32-bit bounds
+func gqlInt[T int | int64 | uint64](v T) int32 {
+	switch {
+	case int64(v) > math.MaxInt32 || (v > 0 && int64(v) < 0):
+		return math.MaxInt32
+	case int64(v) < math.MinInt32:
+		return math.MinInt32
+	}
+	return int32(v)
+}
+
+// optInt maps zero, which the REST API omits, to null
+func optInt[T int | int64 | uint64](v T) *int32 {
+	if v == 0 {
+		return nil
+	}
+	i := gqlInt(v)
+	return &i
+}
+
+// optString maps the empty string, which the REST API omits, to null
+func optString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func setStringValue(values url.Values, key string, v *string) {
+	if v != nil {
+		values.Set(key, *v)
+	}
+}
+
+func setIntValue(values url.Values, key string, v *int32) {
+	if v != nil {
+		values.Set(key, strconv.FormatInt(int64(*v), 10))
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func newTestGraphQLHandler() *GraphQLHandler {
+	registry := entities.NewTokenRegistry(entities.ChainEthereum)
+	registry.Register(entities.WETH)
+	registry.Register(entities.USDC)
+
+	quoteHandler := NewQuoteHandler(nil, nil, nil, nil, registry, nil)
+	return NewGraphQLHandler(quoteHandler, NewPriceHandler(nil, registry, nil), nil)
+}
+
+type gqlTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, h *GraphQLHandler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGraphQLSelectsFields(t *testing.T) {
+	h := newTestGraphQLHandler()
+
+	rec := postGraphQL(t, h, `{"query": "{ token(token: \"usdc\") { address decimals } tokens { symbol } }"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var resp gqlTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+
+	want := `{"address":"` + entities.USDC.Address.Hex() + `","decimals":6}`
+	if got := string(resp.Data["token"]); got != want {
+		t.Errorf("token = %s, want %s", got, want)
+	}
+	if got := string(resp.Data["tokens"]); got != `[{"symbol":"WETH"},{"symbol":"USDC"}]` {
+		t.Errorf("tokens = %s", got)
+	}
+}
+
+func TestGraphQLBatch(t *testing.T) {
+	h := newTestGraphQLHandler()
+
+	rec := postGraphQL(t, h, `[
+		{"query": "query($t: String!) { token(token: $t) { symbol } }", "variables": {"t": "WETH"}},
+		{"query": "{ pools { total } }"}
+	]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var resp []gqlTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("got %d responses, want 2", len(resp))
+	}
+	if got := string(resp[0].Data["token"]); got != `{"symbol":"WETH"}` {
+		t.Errorf("first token = %s", got)
+	}
+	if len(resp[1].Errors) != 1 || resp[1].Errors[0].Extensions["code"] != "POOLS_DISABLED" {
+		t.Errorf("second errors = %+v, want POOLS_DISABLED", resp[1].Errors)
+	}
+}
+
+func TestGraphQLRejectsBadRequests(t *testing.T) {
+	h := newTestGraphQLHandler()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not json", `{query`},
+		{"empty batch", `[]`},
+		{"oversized batch", "[" + strings.Repeat(`{"query":"{ tokens { symbol } }"},`, maxGraphQLBatch) + `{"query":"{ tokens { symbol } }"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := postGraphQL(t, h, tt.body); rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
+
+func TestGraphQLErrorCodes(t *testing.T) {
+	h := newTestGraphQLHandler()
+
+	rec := postGraphQL(t, h, `{"query": "{ token(token: \"NOPE\") { symbol } }"}`)
+	var resp gqlTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "UNSUPPORTED_TOKEN" {
+		t.Errorf("errors = %+v, want UNSUPPORTED_TOKEN", resp.Errors)
+	}
+}
+
+func TestGQLInt(t *testing.T) {
+	if got := gqlInt(uint64(math.MaxUint64)); got != math.MaxInt32 {
+		t.Errorf("gqlInt(MaxUint64) = %d", got)
+	}
+	if got := gqlInt(int64(math.MinInt64)); got != math.MinInt32 {
+		t.Errorf("gqlInt(MinInt64) = %d", got)
+	}
+	if got := gqlInt(42); got != 42 {
+		t.Errorf("gqlInt(42) = %d", got)
+	}
+	if got := optInt(uint64(0)); got != nil {
+		t.Errorf("optInt(0) = %d, want nil", *got)
+	}
+}
@@ -3,6 +3,8 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
 )
 
 type HealthResponse struct {
@@ -10,8 +12,16 @@ type HealthResponse struct {
 	Version string `json:"version"`
 }
 
+// ReadyResponse is the readiness probe's body
+type ReadyResponse struct {
+	Status  string                 `json:"status"`
+	Version string                 `json:"version"`
+	Warmup  *services.WarmupStatus `json:"warmup,omitempty"`
+}
+
 type HealthHandler struct {
 	version string
+	warmup  *services.Warmup
 }
 
 func NewHealthHandler(version string) *HealthHandler {
@@ -26,3 +36,26 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 		Version: h.version,
 	})
 }
+
+// SetWarmup holds readiness back until the startup warm-up finishes
+func (h *HealthHandler) SetWarmup(warmup *services.Warmup) {
+	h.warmup = warmup
+}
+
+// Ready is the readiness probe: 503 while hot pairs are still warming up.
+// Health stays the liveness probe.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	resp := ReadyResponse{Status: "ready", Version: h.version}
+	code := http.StatusOK
+	if h.warmup != nil {
+		status := h.warmup.Status()
+		resp.Warmup = &status
+		if !status.Ready {
+			resp.Status = "warming"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type IntentHandler struct {
+	intentService *services.IntentService
+	nameResolver  NameResolver
+	tokenRegistry *entities.TokenRegistry
+}
+
+func NewIntentHandler(intentService *services.IntentService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *IntentHandler {
+	return &IntentHandler{
+		intentService: intentService,
+		nameResolver:  nameResolver,
+		tokenRegistry: tokenRegistry,
+	}
+}
+
+type IntentRequest struct {
+	Owner        string        `json:"owner"`
+	SellToken    string        `json:"sellToken"`
+	BuyToken     string        `json:"buyToken"`
+	SellAmount   string        `json:"sellAmount"`
+	MinBuyAmount string        `json:"minBuyAmount"`
+	Deadline     uint64        `json:"deadline"`
+	Nonce        string        `json:"nonce"`
+	Signature    hexutil.Bytes `json:"signature"`
+}
+
+type IntentResponse struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	SellToken    string `json:"sellToken"`
+	BuyToken     string `json:"buyToken"`
+	SellAmount   string `json:"sellAmount"`
+	MinBuyAmount string `json:"minBuyAmount"`
+	Deadline     uint64 `json:"deadline"`
+	Status       string `json:"status"`
+	ReceivedAt   int64  `json:"receivedAt"`
+}
+
+type SettlementResponse struct {
+	TokenA   string           `json:"tokenA"`
+	TokenB   string           `json:"tokenB"`
+	MatchedA string           `json:"matchedA"`
+	MatchedB string           `json:"matchedB"`
+	AMMSwap  *AMMSwapResp     `json:"ammSwap,omitempty"`
+	Fills    []IntentFillResp `json:"fills"`
+}
+
+type AMMSwapResp struct {
+	TokenIn      string     `json:"tokenIn"`
+	TokenOut     string     `json:"tokenOut"`
+	AmountIn     string     `json:"amountIn"`
+	AmountOut    string     `json:"amountOut"`
+	MinAmountOut string     `json:"minAmountOut"`
+	Route        []RouteHop `json:"route"`
+}
+
+type IntentFillResp struct {
+	IntentID         string `json:"intentId"`
+	Owner            string `json:"owner"`
+	SellAmount       string `json:"sellAmount"`
+	BuyAmount        string `json:"buyAmount"`
+	MatchedBuyAmount string `json:"matchedBuyAmount"`
+	AMMBuyAmount     string `json:"ammBuyAmount"`
+}
+
+// Submit handles POST /api/v1/intents
+func (h *IntentHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	intent, reqErr := h.decodeIntent(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	if err := h.intentService.Submit(intent); err != nil {
+		if errors.Is(err, services.ErrInvalidIntent) {
+			WriteError(w, r, apperror.Wrap(apperror.InvalidIntent, err))
+			return
+		}
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, newIntentResponse(intent))
+}
+
+// TypedData handles POST /api/v1/intents/typed-data: the intent, sent
+// without a signature, comes back as the eth_signTypedData_v4 request its
+// owner signs before submitting it
+func (h *IntentHandler) TypedData(w http.ResponseWriter, r *http.Request) {
+	intent, reqErr := h.decodeIntent(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	request, err := newSignTypedDataRequest(intent.Owner, h.intentService.TypedData(intent))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+	h.writeJSON(w, http.StatusOK, request)
+}
+
+// decodeIntent reads an IntentRequest body
+func (h *IntentHandler) decodeIntent(r *http.Request) (*entities.Intent, *apperror.Error) {
+	var req IntentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, apperror.New(apperror.InvalidBody, "request body must be JSON")
+	}
+
+	addrs, err := parseAddresses(r.Context(), h.nameResolver, req.Owner, req.SellToken, req.BuyToken)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.InvalidAddress, err)
+	}
+
+	sellAmount, ok1 := new(big.Int).SetString(req.SellAmount, 10)
+	minBuyAmount, ok2 := new(big.Int).SetString(req.MinBuyAmount, 10)
+	nonce, ok3 := new(big.Int).SetString(req.Nonce, 10)
+	if !ok1 || !ok2 || !ok3 {
+		return nil, apperror.New(apperror.InvalidAmount, "sellAmount, minBuyAmount and nonce must be integers")
+	}
+
+	return &entities.Intent{
+		Owner:        addrs[0],
+		SellToken:    h.tokenRegistry.Resolve(r.Context(), addrs[1]),
+		BuyToken:     h.tokenRegistry.Resolve(r.Context(), addrs[2]),
+		SellAmount:   sellAmount,
+		MinBuyAmount: minBuyAmount,
+		Deadline:     req.Deadline,
+		Nonce:        nonce,
+		Signature:    req.Signature,
+	}, nil
+}
+
+// GetIntent handles GET /api/v1/intents/{id}
+func (h *IntentHandler) GetIntent(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := hexutil.Decode(idStr)
+	if err != nil || len(id) != common.HashLength {
+		WriteError(w, r, apperror.New(apperror.InvalidID, "id must be a 32-byte hex hash"))
+		return
+	}
+
+	intent, err := h.intentService.Get(common.BytesToHash(id))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.IntentNotFound, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, newIntentResponse(intent))
+}
+
+// ProposeSettlements handles POST /api/v1/intents/batch
+func (h *IntentHandler) ProposeSettlements(w http.ResponseWriter, r *http.Request) {
+	settlements, err := h.intentService.ProposeSettlements(r.Context())
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.SettlementFailed, err))
+		return
+	}
+
+	response := make([]SettlementResponse, 0, len(settlements))
+	for _, s := range settlements {
+		response = append(response, newSettlementResponse(s))
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"settlements": response})
+}
+
+func newIntentResponse(intent *entities.Intent) IntentResponse {
+	return IntentResponse{
+		ID:           intent.ID.Hex(),
+		Owner:        intent.Owner.Hex(),
+		SellToken:    intent.SellToken.Address.Hex(),
+		BuyToken:     intent.BuyToken.Address.Hex(),
+		SellAmount:   intent.SellAmount.String(),
+		MinBuyAmount: intent.MinBuyAmount.String(),
+		Deadline:     intent.Deadline,
+		Status:       string(intent.Status),
+		ReceivedAt:   intent.ReceivedAt,
+	}
+}
+
+func newSettlementResponse(s entities.Settlement) SettlementResponse {
+	response := SettlementResponse{
+		TokenA:   s.TokenA.Address.Hex(),
+		TokenB:   s.TokenB.Address.Hex(),
+		MatchedA: s.MatchedA.String(),
+		MatchedB: s.MatchedB.String(),
+	}
+
+	if s.AMMSwap != nil {
+		swap := &AMMSwapResp{
+			TokenIn:      s.AMMSwap.TokenIn.Address.Hex(),
+			TokenOut:     s.AMMSwap.TokenOut.Address.Hex(),
+			AmountIn:     s.AMMSwap.AmountIn.String(),
+			AmountOut:    s.AMMSwap.AmountOut.String(),
+			MinAmountOut: s.AMMSwap.MinAmountOut.String(),
+		}
+		swap.Route = newRouteHops(s.AMMSwap.BestRoute)
+		response.AMMSwap = swap
+	}
+
+	for _, fill := range s.Fills {
+		response.Fills = append(response.Fills, IntentFillResp{
+			IntentID:         fill.IntentID.Hex(),
+			Owner:            fill.Owner.Hex(),
+			SellAmount:       fill.SellAmount.String(),
+			BuyAmount:        fill.BuyAmount.String(),
+			MatchedBuyAmount: fill.MatchedBuyAmount.String(),
+			AMMBuyAmount:     fill.AMMBuyAmount.String(),
+		})
+	}
+
+	return response
+}
+
+func (h *IntentHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+func TestIntentHandlerTypedData(t *testing.T) {
+	domain := services.NewIntentDomain(big.NewInt(1), common.HexToAddress("0xdd"))
+	h := NewIntentHandler(services.NewIntentService(nil, domain), entities.DefaultRegistry(), nil)
+	owner := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	body := `{"owner":"` + owner.Hex() + `","sellToken":"` + entities.WETH.Address.Hex() + `","buyToken":"` + entities.USDC.Address.Hex() +
+		`","sellAmount":"1000000000000000000","minBuyAmount":"3000000000","deadline":1900000000,"nonce":"5"}`
+	rec := httptest.NewRecorder()
+	h.TypedData(rec, httptest.NewRequest(http.MethodPost, "/api/v1/intents/typed-data", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	var request struct {
+		Method string   `json:"method"`
+		Params []string `json:"params"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.Method != "eth_signTypedData_v4" || len(request.Params) != 2 || request.Params[0] != owner.Hex() {
+		t.Fatalf("request = %+v, want the owner asked to sign typed data", request)
+	}
+	var typed apitypes.TypedData
+	if err := json.Unmarshal([]byte(request.Params[1]), &typed); err != nil {
+		t.Fatalf("typed data %s: %v", request.Params[1], err)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent := &entities.Intent{
+		Owner: owner, SellToken: entities.WETH, BuyToken: entities.USDC, SellAmount: big.NewInt(1e18),
+		MinBuyAmount: big.NewInt(3000e6), Deadline: 1900000000, Nonce: big.NewInt(5),
+	}
+	if want := services.IntentHash(domain, intent); common.BytesToHash(hash) != want {
+		t.Errorf("typed data hashes to %x, want the intent's %s", hash, want.Hex())
+	}
+}
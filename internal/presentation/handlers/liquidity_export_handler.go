@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/parquet"
+)
+
+const (
+	// defaultLiquidityWindow is exported when neither from nor to is given
+	defaultLiquidityWindow = 24 * time.Hour
+	// maxLiquidityWindow bounds one export's from/to range
+	maxLiquidityWindow = 31 * 24 * time.Hour
+)
+
+type LiquidityHandler struct {
+	snapshotter   *services.LiquiditySnapshotter
+	tokenRegistry *entities.TokenRegistry
+}
+
+func NewLiquidityHandler(snapshotter *services.LiquiditySnapshotter, tokenRegistry *entities.TokenRegistry) *LiquidityHandler {
+	return &LiquidityHandler{
+		snapshotter:   snapshotter,
+		tokenRegistry: tokenRegistry,
+	}
+}
+
+// LiquidityExportRow is one pool's reserves at one snapshot
+type LiquidityExportRow struct {
+	Time     string `json:"time"`
+	Block    uint64 `json:"block"`
+	DEX      string `json:"dex"`
+	Pool     string `json:"pool"`
+	Token0   string `json:"token0"`
+	Symbol0  string `json:"symbol0"`
+	Token1   string `json:"token1"`
+	Symbol1  string `json:"symbol1"`
+	Reserve0 string `json:"reserve0"` // Raw units
+	Reserve1 string `json:"reserve1"`
+	Fee      uint64 `json:"fee"`
+
+	at time.Time
+}
+
+func newLiquidityExportRow(snapshot entities.LiquiditySnapshot) LiquidityExportRow {
+	row := LiquidityExportRow{
+		Time:    snapshot.Time.UTC().Format(time.RFC3339),
+		Block:   snapshot.Block,
+		DEX:     string(snapshot.DEX),
+		Pool:    snapshot.Pool.Hex(),
+		Token0:  snapshot.Token0.Address.Hex(),
+		Symbol0: snapshot.Token0.Symbol,
+		Token1:  snapshot.Token1.Address.Hex(),
+		Symbol1: snapshot.Token1.Symbol,
+		Fee:     snapshot.Fee,
+		at:      snapshot.Time,
+	}
+	if snapshot.Reserve0 != nil {
+		row.Reserve0 = snapshot.Reserve0.String()
+	}
+	if snapshot.Reserve1 != nil {
+		row.Reserve1 = snapshot.Reserve1.String()
+	}
+	return row
+}
+
+var liquidityExportColumns = []string{"time", "block", "dex", "pool", "token0", "symbol0", "token1", "symbol1", "reserve0", "reserve1", "fee"}
+
+// liquidityExportWriter writes export rows in one format. Close ends the
+// output, which Parquet needs for its footer.
+type liquidityExportWriter interface {
+	Write(row LiquidityExportRow) error
+	Close() error
+}
+
+type ndjsonLiquidityWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonLiquidityWriter) Write(row LiquidityExportRow) error {
+	return w.enc.Encode(row)
+}
+
+func (w *ndjsonLiquidityWriter) Close() error {
+	return nil
+}
+
+type csvLiquidityWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvLiquidityWriter) Write(row LiquidityExportRow) error {
+	return w.w.Write([]string{
+		row.Time, strconv.FormatUint(row.Block, 10), row.DEX, row.Pool,
+		row.Token0, row.Symbol0, row.Token1, row.Symbol1,
+		row.Reserve0, row.Reserve1, strconv.FormatUint(row.Fee, 10),
+	})
+}
+
+func (w *csvLiquidityWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// parquetLiquidityWriter types time as a timestamp and block and fee as
+// integers. Reserves stay decimal strings, since they overflow int64.
+type parquetLiquidityWriter struct {
+	w *parquet.Writer
+}
+
+var parquetLiquidityColumns = []parquet.Column{
+	{Name: "time", Kind: parquet.TimestampMillis},
+	{Name: "block", Kind: parquet.Int64},
+	{Name: "dex", Kind: parquet.String},
+	{Name: "pool", Kind: parquet.String},
+	{Name: "token0", Kind: parquet.String},
+	{Name: "symbol0", Kind: parquet.String},
+	{Name: "token1", Kind: parquet.String},
+	{Name: "symbol1", Kind: parquet.String},
+	{Name: "reserve0", Kind: parquet.String},
+	{Name: "reserve1", Kind: parquet.String},
+	{Name: "fee", Kind: parquet.Int64},
+}
+
+func (w *parquetLiquidityWriter) Write(row LiquidityExportRow) error {
+	return w.w.Write(
+		row.at.UnixMilli(), int64(row.Block), row.DEX, row.Pool,
+		row.Token0, row.Symbol0, row.Token1, row.Symbol1,
+		row.Reserve0, row.Reserve1, int64(row.Fee),
+	)
+}
+
+func (w *parquetLiquidityWriter) Close() error {
+	return w.w.Close()
+}
+
+// newLiquidityExportWriter returns the writer for format with its content
+// type, or false for an unknown format. CSV starts with a header row.
+func newLiquidityExportWriter(format string, out io.Writer) (liquidityExportWriter, string, bool) {
+	switch format {
+	case "", "ndjson":
+		return &ndjsonLiquidityWriter{enc: json.NewEncoder(out)}, "application/x-ndjson", true
+	case "csv":
+		w := csv.NewWriter(out)
+		w.Write(liquidityExportColumns)
+		return &csvLiquidityWriter{w: w}, "text/csv; charset=utf-8", true
+	case "parquet":
+		return &parquetLiquidityWriter{w: parquet.NewWriter(out, parquetLiquidityColumns)}, "application/vnd.apache.parquet", true
+	}
+	return nil, "", false
+}
+
+// ExportSnapshots handles GET /api/v1/export/liquidity, streaming the
+// stored pool reserves of the snapshotted pairs, oldest first, as NDJSON
+// (the default), CSV or Parquet. tokenA, tokenB and dex narrow the pools;
+// from and to (RFC 3339) pick the window, the last day by default.
+func (h *LiquidityHandler) ExportSnapshots(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if _, _, ok := newLiquidityExportWriter(format, io.Discard); !ok {
+		WriteError(w, r, apperror.New(apperror.InvalidFormat, "format must be ndjson, csv or parquet"))
+		return
+	}
+	filter, reqErr := h.parseFilter(q, time.Now())
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	// A client that goes away still ends the export, through a failed write
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), exportTimeout)
+	defer cancel()
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(exportTimeout))
+
+	// Parquet is written whole at the end, so rows are only flushed as
+	// they go for the line formats
+	var writer liquidityExportWriter
+	contentType := ""
+	started := false
+	err := h.snapshotter.Export(ctx, filter, func(snapshot entities.LiquiditySnapshot) error {
+		if !started {
+			started = true
+			writer, contentType, _ = newLiquidityExportWriter(format, w)
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(http.StatusOK)
+		}
+		if err := writer.Write(newLiquidityExportRow(snapshot)); err != nil {
+			return err
+		}
+		if format != "parquet" {
+			_ = rc.Flush()
+		}
+		return nil
+	})
+	if !started {
+		if err != nil {
+			WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+			return
+		}
+		// An empty export is still a well-formed file
+		writer, contentType, _ = newLiquidityExportWriter(format, w)
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+	}
+	if err == nil {
+		_ = writer.Close()
+	}
+}
+
+// parseFilter reads the export's pair, venue and window as of now
+func (h *LiquidityHandler) parseFilter(q url.Values, now time.Time) (entities.LiquiditySnapshotFilter, *apperror.Error) {
+	filter := entities.LiquiditySnapshotFilter{DEX: entities.DEXType(q.Get("dex"))}
+	for _, param := range []struct {
+		name string
+		addr *common.Address
+	}{{"tokenA", &filter.TokenA}, {"tokenB", &filter.TokenB}} {
+		value := q.Get(param.name)
+		if value == "" {
+			continue
+		}
+		if common.IsHexAddress(value) {
+			*param.addr = common.HexToAddress(value)
+			continue
+		}
+		token, err := h.tokenRegistry.LookupSymbol(value)
+		switch {
+		case errors.Is(err, entities.ErrAmbiguousSymbol):
+			return filter, apperror.New(apperror.AmbiguousToken, fmt.Sprintf("%s: symbol %q matches several tokens, use the token address", param.name, value))
+		case err != nil:
+			return filter, apperror.New(apperror.UnsupportedToken, fmt.Sprintf("%s: %q is not an address or known token symbol", param.name, value))
+		}
+		*param.addr = token.Address
+	}
+
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		s := q.Get(bound.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return filter, apperror.New(apperror.InvalidFilter, bound.name+" must be an RFC 3339 time")
+		}
+		*bound.t = t
+	}
+	switch {
+	case filter.From.IsZero() && filter.To.IsZero():
+		filter.To = now
+		filter.From = now.Add(-defaultLiquidityWindow)
+	case filter.To.IsZero():
+		filter.To = now
+	case filter.From.IsZero():
+		filter.From = filter.To.Add(-defaultLiquidityWindow)
+	}
+	if filter.To.Before(filter.From) {
+		return filter, apperror.New(apperror.InvalidFilter, "to is before from")
+	}
+	if filter.To.Sub(filter.From) > maxLiquidityWindow {
+		return filter, apperror.New(apperror.InvalidFilter, "from and to may be at most 31 days apart")
+	}
+	return filter, nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestLiquidityExportWriter(t *testing.T) {
+	row := newLiquidityExportRow(entities.LiquiditySnapshot{
+		Time:     time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC),
+		Block:    19000000,
+		DEX:      entities.DEXUniswapV2,
+		Pool:     entities.WETH.Address,
+		Token0:   entities.USDC,
+		Token1:   entities.WETH,
+		Reserve0: big.NewInt(3000000000),
+		Reserve1: new(big.Int).Exp(big.NewInt(10), big.NewInt(21), nil),
+		Fee:      30,
+	})
+
+	var out bytes.Buffer
+	writer, _, _ := newLiquidityExportWriter("csv", &out)
+	if err := writer.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,block,dex,pool,token0,symbol0,token1,symbol1,reserve0,reserve1,fee\n" +
+		"2026-01-01T00:05:00Z,19000000," + string(entities.DEXUniswapV2) + "," + entities.WETH.Address.Hex() + "," +
+		entities.USDC.Address.Hex() + ",USDC," + entities.WETH.Address.Hex() + ",WETH,3000000000,1000000000000000000000,30\n"
+	if out.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writer, contentType, ok := newLiquidityExportWriter("parquet", &out)
+	if !ok || contentType != "application/vnd.apache.parquet" {
+		t.Fatalf("newLiquidityExportWriter(parquet) = %q, %v", contentType, ok)
+	}
+	if err := writer.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("PAR1")) || !bytes.HasSuffix(out.Bytes(), []byte("PAR1")) {
+		t.Error("parquet output is not framed by PAR1")
+	}
+
+	if _, _, ok := newLiquidityExportWriter("xlsx", &bytes.Buffer{}); ok {
+		t.Error("xlsx accepted")
+	}
+}
+
+func TestLiquidityExportFilter(t *testing.T) {
+	registry := entities.NewTokenRegistry(entities.ChainEthereum)
+	registry.Register(entities.WETH)
+	h := NewLiquidityHandler(nil, registry)
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	filter, reqErr := h.parseFilter(url.Values{"tokenA": {"WETH"}, "tokenB": {entities.USDC.Address.Hex()}}, now)
+	if reqErr != nil {
+		t.Fatalf("parseFilter() error = %v", reqErr)
+	}
+	if filter.TokenA != entities.WETH.Address || filter.TokenB != entities.USDC.Address {
+		t.Errorf("tokens = %s, %s", filter.TokenA.Hex(), filter.TokenB.Hex())
+	}
+	if !filter.To.Equal(now) || !filter.From.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("default window = %v to %v, want the last day", filter.From, filter.To)
+	}
+
+	tests := []struct {
+		q    url.Values
+		code apperror.Code
+	}{
+		{url.Values{"tokenA": {"NOTATOKEN"}}, apperror.UnsupportedToken},
+		{url.Values{"from": {"2026-01-01"}}, apperror.InvalidFilter},
+		{url.Values{"from": {"2026-01-02T00:00:00Z"}, "to": {"2026-01-01T00:00:00Z"}}, apperror.InvalidFilter},
+		{url.Values{"from": {"2025-10-01T00:00:00Z"}}, apperror.InvalidFilter},
+	}
+	for _, tt := range tests {
+		if _, reqErr := h.parseFilter(tt.q, now); reqErr == nil || reqErr.Code != tt.code {
+			t.Errorf("parseFilter(%v) error = %v, want %s", tt.q, reqErr, tt.code)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type OrderHandler struct {
+	orderService  *services.OrderService
+	nameResolver  NameResolver
+	tokenRegistry *entities.TokenRegistry
+}
+
+func NewOrderHandler(orderService *services.OrderService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *OrderHandler {
+	return &OrderHandler{
+		orderService:  orderService,
+		nameResolver:  nameResolver,
+		tokenRegistry: tokenRegistry,
+	}
+}
+
+type DutchOrderRequest struct {
+	Owner          string        `json:"owner"`
+	TokenIn        string        `json:"tokenIn"`
+	TokenOut       string        `json:"tokenOut"`
+	AmountIn       string        `json:"amountIn"`
+	StartAmountOut string        `json:"startAmountOut"`
+	EndAmountOut   string        `json:"endAmountOut"`
+	StartTime      uint64        `json:"startTime"`
+	EndTime        uint64        `json:"endTime"`
+	Nonce          string        `json:"nonce"`
+	Signature      hexutil.Bytes `json:"signature"`
+}
+
+type OrderResponse struct {
+	ID             string         `json:"id"`
+	Type           string         `json:"type"`
+	Owner          string         `json:"owner"`
+	TokenIn        string         `json:"tokenIn"`
+	TokenOut       string         `json:"tokenOut"`
+	AmountIn       string         `json:"amountIn"`
+	StartAmountOut string         `json:"startAmountOut"`
+	EndAmountOut   string         `json:"endAmountOut"`
+	StartTime      uint64         `json:"startTime"`
+	EndTime        uint64         `json:"endTime"`
+	CurrentLimit   string         `json:"currentLimit"`
+	Status         string         `json:"status"`
+	CheckedAt      int64          `json:"checkedAt,omitempty"`
+	Fill           *OrderFillResp `json:"fill,omitempty"`
+}
+
+type OrderFillResp struct {
+	AmountOut      string           `json:"amountOut"`
+	LimitAmountOut string           `json:"limitAmountOut"`
+	Route          []RouteHop       `json:"route"`
+	Transaction    *TransactionResp `json:"transaction,omitempty"`
+	DetectedAt     int64            `json:"detectedAt"`
+}
+
+// CreateDutch handles POST /api/v1/orders/dutch
+func (h *OrderHandler) CreateDutch(w http.ResponseWriter, r *http.Request) {
+	var req DutchOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+
+	addrs, err := parseAddresses(r.Context(), h.nameResolver, req.Owner, req.TokenIn, req.TokenOut)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidAddress, err))
+		return
+	}
+
+	amountIn, ok1 := new(big.Int).SetString(req.AmountIn, 10)
+	startAmountOut, ok2 := new(big.Int).SetString(req.StartAmountOut, 10)
+	endAmountOut, ok3 := new(big.Int).SetString(req.EndAmountOut, 10)
+	nonce, ok4 := new(big.Int).SetString(req.Nonce, 10)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		WriteError(w, r, apperror.New(apperror.InvalidAmount, "amountIn, startAmountOut, endAmountOut and nonce must be integers"))
+		return
+	}
+
+	order := &entities.Order{
+		Owner:          addrs[0],
+		TokenIn:        h.tokenRegistry.Resolve(r.Context(), addrs[1]),
+		TokenOut:       h.tokenRegistry.Resolve(r.Context(), addrs[2]),
+		AmountIn:       amountIn,
+		StartAmountOut: startAmountOut,
+		EndAmountOut:   endAmountOut,
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		Nonce:          nonce,
+		Signature:      req.Signature,
+	}
+
+	if err := h.orderService.CreateDutch(order); err != nil {
+		if errors.Is(err, services.ErrInvalidOrder) {
+			WriteError(w, r, apperror.Wrap(apperror.InvalidOrder, err))
+			return
+		}
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, newOrderResponse(order))
+}
+
+// GetOrder handles GET /api/v1/orders/{id}
+func (h *OrderHandler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := hexutil.Decode(chi.URLParam(r, "id"))
+	if err != nil || len(id) != common.HashLength {
+		WriteError(w, r, apperror.New(apperror.InvalidID, "id must be a 32-byte hex hash"))
+		return
+	}
+
+	order, err := h.orderService.Get(common.BytesToHash(id))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.OrderNotFound, err))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, newOrderResponse(order))
+}
+
+// ListOrders handles GET /api/v1/orders?owner=&status=&sort=startTime|endTime&order=desc|asc&limit=&cursor=
+func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, reqErr := parsePage(q)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	status, reqErr := parseFilter(q, "status",
+		string(entities.OrderOpen), string(entities.OrderFillable), string(entities.OrderExpired))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	sortBy, reqErr := parseSort(q, "startTime", "endTime")
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	asc, reqErr := parseOrder(q, false)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	var owner *common.Address
+	if s := q.Get("owner"); s != "" {
+		addr, err := parseAddress(r.Context(), h.nameResolver, s)
+		if err != nil {
+			WriteError(w, r, apperror.New(apperror.InvalidFilter, "owner: "+err.Error()))
+			return
+		}
+		owner = &addr
+	}
+
+	var matches []*entities.Order
+	for _, order := range h.orderService.List() {
+		if owner != nil && order.Owner != *owner {
+			continue
+		}
+		if status != "" && string(order.Status) != status {
+			continue
+		}
+		matches = append(matches, order)
+	}
+
+	key := func(o *entities.Order) uint64 {
+		if sortBy == "endTime" {
+			return o.EndTime
+		}
+		return o.StartTime
+	}
+	orders := paginate(matches, page, func(a, b *entities.Order) bool {
+		if key(a) != key(b) {
+			return (key(a) < key(b)) == asc
+		}
+		// IDs break ties so pages stay stable between requests
+		return a.ID.Cmp(b.ID) < 0
+	})
+
+	data := make([]OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		data = append(data, newOrderResponse(order))
+	}
+	h.writeJSON(w, http.StatusOK, newPage(data, page, len(matches)))
+}
+
+func newOrderResponse(order *entities.Order) OrderResponse {
+	response := OrderResponse{
+		ID:             order.ID.Hex(),
+		Type:           string(order.Type),
+		Owner:          order.Owner.Hex(),
+		TokenIn:        order.TokenIn.Address.Hex(),
+		TokenOut:       order.TokenOut.Address.Hex(),
+		AmountIn:       order.AmountIn.String(),
+		StartAmountOut: order.StartAmountOut.String(),
+		EndAmountOut:   order.EndAmountOut.String(),
+		StartTime:      order.StartTime,
+		EndTime:        order.EndTime,
+		CurrentLimit:   order.LimitAt(uint64(time.Now().Unix())).String(),
+		Status:         string(order.Status),
+		CheckedAt:      order.CheckedAt,
+	}
+
+	if fill := order.Fill; fill != nil {
+		fillResp := &OrderFillResp{
+			AmountOut:      fill.Quote.AmountOut.String(),
+			LimitAmountOut: fill.LimitAmountOut.String(),
+			DetectedAt:     fill.DetectedAt,
+		}
+		fillResp.Route = newRouteHops(fill.Quote.BestRoute)
+		if tx := fill.Transaction; tx != nil {
+			// Any filler may send it, so there is no from
+			fillResp.Transaction = &TransactionResp{
+				To:    tx.To.Hex(),
+				Data:  hexutil.Encode(tx.Data),
+				Value: tx.Value.String(),
+				Gas:   tx.Gas,
+			}
+		}
+		response.Fill = fillResp
+	}
+
+	return response
+}
+
+func (h *OrderHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
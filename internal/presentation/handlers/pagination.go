@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+)
+
+// Page size bounds shared by list endpoints
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// Page is the envelope every list endpoint answers with. NextCursor goes
+// back as cursor= for the following page and is left out on the last one.
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// pageParams is where a page starts in the sorted, filtered list and how
+// many items it holds
+type pageParams struct {
+	Offset int
+	Limit  int
+}
+
+// parsePage reads limit= and either cursor= or offset=. Cursors are opaque
+// to clients so the position they encode can change later.
+func parsePage(q url.Values) (pageParams, *apperror.Error) {
+	page := pageParams{Limit: defaultPageLimit}
+
+	var err error
+	if s := q.Get("limit"); s != "" {
+		if page.Limit, err = strconv.Atoi(s); err != nil || page.Limit <= 0 || page.Limit > maxPageLimit {
+			return page, apperror.New(apperror.InvalidLimit, "limit must be 1-"+strconv.Itoa(maxPageLimit))
+		}
+	}
+
+	cursor, offset := q.Get("cursor"), q.Get("offset")
+	switch {
+	case cursor != "" && offset != "":
+		return page, apperror.New(apperror.InvalidCursor, "use cursor or offset, not both")
+	case cursor != "":
+		raw, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return page, apperror.New(apperror.InvalidCursor, "cursor is not one this API returned")
+		}
+		if page.Offset, err = strconv.Atoi(string(raw)); err != nil || page.Offset < 0 {
+			return page, apperror.New(apperror.InvalidCursor, "cursor is not one this API returned")
+		}
+	case offset != "":
+		if page.Offset, err = strconv.Atoi(offset); err != nil || page.Offset < 0 {
+			return page, apperror.New(apperror.InvalidOffset, "offset must be a non-negative integer")
+		}
+	}
+	return page, nil
+}
+
+// parseOrder reads order=asc|desc, defaulting to asc when ascByDefault
+func parseOrder(q url.Values, ascByDefault bool) (asc bool, reqErr *apperror.Error) {
+	switch q.Get("order") {
+	case "":
+		return ascByDefault, nil
+	case "asc":
+		return true, nil
+	case "desc":
+		return false, nil
+	default:
+		return false, apperror.New(apperror.InvalidSort, "order must be asc or desc")
+	}
+}
+
+// parseSort reads sort= as one of fields, the first being the default
+func parseSort(q url.Values, fields ...string) (string, *apperror.Error) {
+	s := q.Get("sort")
+	if s == "" {
+		return fields[0], nil
+	}
+	for _, field := range fields {
+		if s == field {
+			return s, nil
+		}
+	}
+	return "", apperror.New(apperror.InvalidSort, "sort must be one of "+strings.Join(fields, ", "))
+}
+
+// parseFilter reads an optional filter that must be one of values
+func parseFilter(q url.Values, name string, values ...string) (string, *apperror.Error) {
+	s := q.Get(name)
+	if s == "" {
+		return "", nil
+	}
+	for _, v := range values {
+		if s == v {
+			return s, nil
+		}
+	}
+	return "", apperror.New(apperror.InvalidFilter, name+" must be one of "+strings.Join(values, ", "))
+}
+
+// newPage wraps one page of data out of total matches
+func newPage[T any](data []T, page pageParams, total int) Page[T] {
+	if data == nil {
+		data = []T{}
+	}
+	resp := Page[T]{Data: data, Total: total}
+	if next := page.Offset + len(data); len(data) > 0 && next < total {
+		resp.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(next)))
+	}
+	return resp
+}
+
+// paginate sorts an in-memory list with less and returns the page of it
+func paginate[T any](items []T, page pageParams, less func(a, b T) bool) []T {
+	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
+	if page.Offset >= len(items) {
+		return nil
+	}
+	end := len(items)
+	if page.Offset+page.Limit < end {
+		end = page.Offset + page.Limit
+	}
+	return items[page.Offset:end]
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+)
+
+func TestParsePage(t *testing.T) {
+	cursor := newPage([]int{1, 2}, pageParams{Offset: 10, Limit: 2}, 20).NextCursor
+
+	tests := []struct {
+		name     string
+		query    string
+		want     pageParams
+		wantCode apperror.Code
+	}{
+		{"defaults", "", pageParams{Offset: 0, Limit: defaultPageLimit}, ""},
+		{"limit and offset", "limit=10&offset=30", pageParams{Offset: 30, Limit: 10}, ""},
+		{"cursor", "limit=2&cursor=" + cursor, pageParams{Offset: 12, Limit: 2}, ""},
+		{"zero limit", "limit=0", pageParams{}, apperror.InvalidLimit},
+		{"limit over max", "limit=501", pageParams{}, apperror.InvalidLimit},
+		{"negative offset", "offset=-1", pageParams{}, apperror.InvalidOffset},
+		{"garbage cursor", "cursor=%21%21", pageParams{}, apperror.InvalidCursor},
+		{"cursor and offset", "cursor=" + cursor + "&offset=5", pageParams{}, apperror.InvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, reqErr := parsePage(q)
+			if tt.wantCode != "" {
+				if reqErr == nil || reqErr.Code != tt.wantCode {
+					t.Fatalf("parsePage(%q) error = %v, want %s", tt.query, reqErr, tt.wantCode)
+				}
+				return
+			}
+			if reqErr != nil {
+				t.Fatalf("parsePage(%q) error = %v", tt.query, reqErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePage(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginateFollowsCursors(t *testing.T) {
+	items := []int{5, 3, 9, 1, 7}
+	less := func(a, b int) bool { return a < b }
+
+	var seen []int
+	q := url.Values{"limit": {"2"}}
+	for pages := 0; ; pages++ {
+		if pages > len(items) {
+			t.Fatal("cursors never ran out")
+		}
+		page, reqErr := parsePage(q)
+		if reqErr != nil {
+			t.Fatalf("parsePage() error = %v", reqErr)
+		}
+		resp := newPage(paginate(items, page, less), page, len(items))
+		if resp.Total != len(items) {
+			t.Errorf("Total = %d, want %d", resp.Total, len(items))
+		}
+		seen = append(seen, resp.Data...)
+		if resp.NextCursor == "" {
+			break
+		}
+		q.Set("cursor", resp.NextCursor)
+	}
+
+	want := []int{1, 3, 5, 7, 9}
+	if len(seen) != len(want) {
+		t.Fatalf("walked %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("walked %v, want %v", seen, want)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	q := url.Values{"status": {"open"}}
+	if got, reqErr := parseFilter(q, "status", "open", "expired"); reqErr != nil || got != "open" {
+		t.Errorf("parseFilter() = %q, %v, want open", got, reqErr)
+	}
+	q.Set("status", "cancelled")
+	if _, reqErr := parseFilter(q, "status", "open", "expired"); reqErr == nil || reqErr.Code != apperror.InvalidFilter {
+		t.Errorf("parseFilter() error = %v, want %s", reqErr, apperror.InvalidFilter)
+	}
+}
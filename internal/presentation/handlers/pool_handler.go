@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type PoolHandler struct {
+	poolService  *services.PoolService
+	nameResolver NameResolver
+}
+
+func NewPoolHandler(poolService *services.PoolService, nameResolver NameResolver) *PoolHandler {
+	return &PoolHandler{
+		poolService:  poolService,
+		nameResolver: nameResolver,
+	}
+}
+
+type PoolResp struct {
+	DEX          string      `json:"dex"`
+	Address      string      `json:"address"`
+	Tokens       []TokenResp `json:"tokens"`
+	FeeTier      uint64      `json:"feeTier,omitempty"`
+	TVLUSD       string      `json:"tvlUsd"`
+	Volume24hUSD string      `json:"volume24hUsd"`
+}
+
+// ListPools handles GET /api/v1/pools?dex=&token=&sort=tvl|volume&order=desc|asc&limit=&cursor=
+func (h *PoolHandler) ListPools(w http.ResponseWriter, r *http.Request) {
+	query, reqErr := h.parsePoolQuery(r.Context(), r.URL.Query())
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	response, reqErr := h.listPools(query)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// parsePoolQuery validates pool list parameters given as query values
+func (h *PoolHandler) parsePoolQuery(ctx context.Context, q url.Values) (services.PoolQuery, *apperror.Error) {
+	query := services.PoolQuery{DEX: entities.DEXType(q.Get("dex"))}
+
+	page, reqErr := parsePage(q)
+	if reqErr != nil {
+		return query, reqErr
+	}
+	query.Offset, query.Limit = page.Offset, page.Limit
+	if query.SortBy, reqErr = parseSort(q, services.PoolSortTVL, services.PoolSortVolume); reqErr != nil {
+		return query, reqErr
+	}
+	if query.Asc, reqErr = parseOrder(q, false); reqErr != nil {
+		return query, reqErr
+	}
+	if s := q.Get("token"); s != "" {
+		addr, err := parseAddress(ctx, h.nameResolver, s)
+		if err != nil {
+			return query, apperror.Wrap(apperror.InvalidToken, err)
+		}
+		query.Token = &addr
+	}
+	return query, nil
+}
+
+// listPools runs a validated query
+func (h *PoolHandler) listPools(query services.PoolQuery) (*Page[PoolResp], *apperror.Error) {
+	pools, total, err := h.poolService.List(query)
+	if err != nil {
+		return nil, apperror.Wrap(apperror.InvalidSort, err)
+	}
+
+	data := make([]PoolResp, 0, len(pools))
+	for _, p := range pools {
+		tokens := make([]TokenResp, 0, len(p.Tokens))
+		for _, t := range p.Tokens {
+			tokens = append(tokens, newTokenResp(t))
+		}
+		data = append(data, PoolResp{
+			DEX:          string(p.DEX),
+			Address:      p.Address.Hex(),
+			Tokens:       tokens,
+			FeeTier:      p.FeeTier,
+			TVLUSD:       usdFormat.format(p.TVLUSD, priceDecimals),
+			Volume24hUSD: usdFormat.format(p.Volume24hUSD, priceDecimals),
+		})
+	}
+	response := newPage(data, pageParams{Offset: query.Offset, Limit: query.Limit}, total)
+	return &response, nil
+}
+
+func (h *PoolHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
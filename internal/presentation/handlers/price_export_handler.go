@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+const (
+	// exportWorkers bounds the tokens priced at once during an export
+	exportWorkers = 8
+	// exportTimeout bounds a whole export, which outlives the API's usual
+	// request timeout
+	exportTimeout = 5 * time.Minute
+)
+
+// PriceExportRow is one token's line in a price export. Tokens that can't
+// be priced carry an error instead of a price.
+type PriceExportRow struct {
+	Token     string `json:"token"`
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+	PriceUSDC string `json:"priceUsdc,omitempty"` // One whole token in USDC
+	PricedAt  string `json:"pricedAt"`
+	Error     string `json:"error,omitempty"`
+}
+
+var priceExportColumns = []string{"token", "symbol", "decimals", "priceUsdc", "pricedAt", "error"}
+
+// priceExportWriter writes export rows in one format
+type priceExportWriter interface {
+	Write(row PriceExportRow) error
+}
+
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonExportWriter) Write(row PriceExportRow) error {
+	return w.enc.Encode(row)
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvExportWriter) Write(row PriceExportRow) error {
+	w.w.Write([]string{row.Token, row.Symbol, strconv.Itoa(int(row.Decimals)), row.PriceUSDC, row.PricedAt, row.Error})
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// newPriceExportWriter returns the writer for format with its content type,
+// or false for an unknown format. CSV starts with a header row.
+func newPriceExportWriter(format string, out io.Writer) (priceExportWriter, string, bool) {
+	switch format {
+	case "", "ndjson":
+		return &ndjsonExportWriter{enc: json.NewEncoder(out)}, "application/x-ndjson", true
+	case "csv":
+		w := csv.NewWriter(out)
+		w.Write(priceExportColumns)
+		return &csvExportWriter{w: w}, "text/csv; charset=utf-8", true
+	}
+	return nil, "", false
+}
+
+// ExportPrices handles GET /api/v1/export/prices, streaming every registry
+// token's USDC price as NDJSON (the default) or, with format=csv, as CSV.
+// Rows follow the registry's order and are flushed as they are priced.
+func (h *PriceHandler) ExportPrices(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	_, contentType, ok := newPriceExportWriter(format, io.Discard)
+	if !ok {
+		WriteError(w, r, apperror.New(apperror.InvalidFormat, "format must be ndjson or csv"))
+		return
+	}
+
+	// A client that goes away still ends the export, through a failed write
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), exportTimeout)
+	defer cancel()
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(exportTimeout))
+
+	tokens := h.tokenRegistry.GetAll()
+	rows := make([]chan PriceExportRow, len(tokens))
+	for i := range rows {
+		rows[i] = make(chan PriceExportRow, 1)
+	}
+	go func() {
+		slots := make(chan struct{}, exportWorkers)
+		for i, token := range tokens {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int, token entities.Token) {
+				defer func() { <-slots }()
+				rows[i] <- h.exportRow(ctx, token)
+			}(i, token)
+		}
+	}()
+
+	// Headers go out before the CSV writer's header row
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	writer, _, _ := newPriceExportWriter(format, w)
+	for _, row := range rows {
+		select {
+		case next := <-row:
+			if err := writer.Write(next); err != nil {
+				return
+			}
+			_ = rc.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// exportRow prices one token for an export
+func (h *PriceHandler) exportRow(ctx context.Context, token entities.Token) PriceExportRow {
+	row := PriceExportRow{
+		Token:    token.Address.Hex(),
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+	}
+	price, err := h.priceService.GetTokenPriceUSDC(ctx, token)
+	row.PricedAt = time.Now().UTC().Format(time.RFC3339)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.PriceUSDC = formatUnits(price, 18)
+	return row
+}
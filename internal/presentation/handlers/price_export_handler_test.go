@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPriceExportWriter(t *testing.T) {
+	rows := []PriceExportRow{
+		{Token: "0xA0b8", Symbol: "USDC", Decimals: 6, PriceUSDC: "1", PricedAt: "2026-01-01T00:00:00Z"},
+		{Token: "0xdead", Symbol: "BAD", Decimals: 18, PricedAt: "2026-01-01T00:00:00Z", Error: "no pool, anywhere"},
+	}
+	tests := []struct {
+		format      string
+		contentType string
+		want        string
+	}{
+		{"", "application/x-ndjson", `{"token":"0xA0b8","symbol":"USDC","decimals":6,"priceUsdc":"1","pricedAt":"2026-01-01T00:00:00Z"}
+{"token":"0xdead","symbol":"BAD","decimals":18,"pricedAt":"2026-01-01T00:00:00Z","error":"no pool, anywhere"}
+`},
+		{"csv", "text/csv; charset=utf-8", `token,symbol,decimals,priceUsdc,pricedAt,error
+0xA0b8,USDC,6,1,2026-01-01T00:00:00Z,
+0xdead,BAD,18,,2026-01-01T00:00:00Z,"no pool, anywhere"
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, contentType, ok := newPriceExportWriter(tt.format, &out)
+			if !ok || contentType != tt.contentType {
+				t.Fatalf("newPriceExportWriter() = %q, %v", contentType, ok)
+			}
+			for _, row := range rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if out.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+
+	if _, _, ok := newPriceExportWriter("xml", &bytes.Buffer{}); ok {
+		t.Error("xml accepted")
+	}
+}
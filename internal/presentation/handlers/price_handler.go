@@ -2,94 +2,152 @@ package handlers
 
 import (
 	"encoding/json"
+	"math/big"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
-
+	"github.com/bimakw/dex-aggregator/internal/apperror"
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 	"github.com/bimakw/dex-aggregator/internal/domain/services"
 )
 
 type PriceHandler struct {
 	priceService  *services.PriceService
-	tokenRegistry map[common.Address]entities.Token
+	nameResolver  NameResolver
+	tokenRegistry *entities.TokenRegistry
+	fx            *services.FXService // Optional, see SetFXService
+	chainHead     ChainHead           // Optional, see SetChainHead
 }
 
-func NewPriceHandler(priceService *services.PriceService) *PriceHandler {
-	registry := map[common.Address]entities.Token{
-		entities.WETH.Address: entities.WETH,
-		entities.USDC.Address: entities.USDC,
-		entities.USDT.Address: entities.USDT,
-		entities.DAI.Address:  entities.DAI,
-	}
-
+func NewPriceHandler(priceService *services.PriceService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *PriceHandler {
 	return &PriceHandler{
 		priceService:  priceService,
-		tokenRegistry: registry,
+		nameResolver:  nameResolver,
+		tokenRegistry: tokenRegistry,
 	}
 }
 
+// SetFXService lets prices be quoted in currencies other than USD with vs=
+func (h *PriceHandler) SetFXService(fx *services.FXService) {
+	h.fx = fx
+}
+
+// SetChainHead adds the head block and its age to every price
+func (h *PriceHandler) SetChainHead(head ChainHead) {
+	h.chainHead = head
+}
+
 type PriceResponse struct {
-	Token     string            `json:"token"`
-	Symbol    string            `json:"symbol"`
-	PriceUSD  string            `json:"priceUSD"`
-	Sources   map[string]string `json:"sources,omitempty"`
-	UpdatedAt string            `json:"updatedAt"`
+	Token        string            `json:"token"`
+	Symbol       string            `json:"symbol"`
+	PriceUSD     string            `json:"priceUSD"`
+	Price        string            `json:"price"`    // In Currency
+	Currency     string            `json:"currency"` // vs=, USD by default
+	Sources      map[string]string `json:"sources,omitempty"`
+	USDIndex     []USDIndexLegResp `json:"usdIndex,omitempty"`
+	DepegWarning string            `json:"depegWarning,omitempty"`
+	UpdatedAt    string            `json:"updatedAt"`
+	BlockNumber  uint64            `json:"blockNumber,omitempty"`
+	BlockAge     *int64            `json:"blockAgeSeconds,omitempty"`
+}
+
+// USDIndexLegResp is the token's USD price through one stablecoin; the
+// median leg set priceUSD
+type USDIndexLegResp struct {
+	Stable       string `json:"stable"`
+	PriceUSD     string `json:"priceUSD,omitempty"`
+	DeviationBps int64  `json:"deviationBps"`
+	Median       bool   `json:"median,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func newUSDIndexLegResps(index *services.USDIndexPrice, f decimalFormat) []USDIndexLegResp {
+	legs := make([]USDIndexLegResp, len(index.Legs))
+	for i, leg := range index.Legs {
+		legs[i] = USDIndexLegResp{Stable: leg.Stable.Symbol, DeviationBps: leg.DeviationBps, Median: leg.Median}
+		if leg.Error != nil {
+			legs[i].Error = leg.Error.Error()
+		} else {
+			legs[i].PriceUSD = f.format(leg.PriceUSD, priceDecimals)
+		}
+	}
+	return legs
 }
 
 // GetPrice handles GET /api/v1/price/{tokenAddress}
 func (h *PriceHandler) GetPrice(w http.ResponseWriter, r *http.Request) {
-	path := r.URL.Path
-	parts := strings.Split(path, "/")
-	if len(parts) < 4 {
-		h.writeError(w, http.StatusBadRequest, "missing_token", "token address is required")
+	token, reqErr := h.parsePriceToken(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
 		return
 	}
-	tokenAddr := parts[len(parts)-1]
 
-	if !common.IsHexAddress(tokenAddr) {
-		h.writeError(w, http.StatusBadRequest, "invalid_token", "invalid token address")
+	format, reqErr := parseNotation(r, usdFormat)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
 		return
 	}
 
-	token, ok := h.tokenRegistry[common.HexToAddress(tokenAddr)]
-	if !ok {
-		token = entities.Token{
-			Address:  common.HexToAddress(tokenAddr),
-			Symbol:   "UNKNOWN",
-			Decimals: 18,
-		}
-	}
-
-	price, err := h.priceService.GetTokenPrice(r.Context(), token)
-	if err != nil {
-		h.writeError(w, http.StatusNotFound, "price_not_found", err.Error())
+	index, price, currency, reqErr := h.price(r, token)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
 		return
 	}
 
-	// Format price (18 decimals -> human readable)
-	priceStr := formatPrice(price)
-
+	blockNumber, blockAge := blockLag(h.chainHead, time.Now())
 	response := PriceResponse{
-		Token:     token.Address.Hex(),
-		Symbol:    token.Symbol,
-		PriceUSD:  priceStr,
-		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+		Token:        token.Address.Hex(),
+		Symbol:       token.Symbol,
+		PriceUSD:     format.format(index.PriceUSD, priceDecimals),
+		Price:        format.format(price, priceDecimals),
+		Currency:     string(currency),
+		USDIndex:     newUSDIndexLegResps(index, format),
+		DepegWarning: h.priceService.DepegWarning(token),
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+		BlockNumber:  blockNumber,
+		BlockAge:     blockAge,
 	}
 
 	h.writeJSON(w, http.StatusOK, response)
 }
 
-// formatPrice formats a price with 18 decimals to a human-readable string
-func formatPrice(price interface{ String() string }) string {
-	priceStr := price.String()
-	if len(priceStr) <= 18 {
-		return "0." + strings.Repeat("0", 18-len(priceStr)) + priceStr
+// price prices token on the USD index and in the vs= currency, both with 18
+// decimals
+func (h *PriceHandler) price(r *http.Request, token entities.Token) (index *services.USDIndexPrice, price *big.Int, currency services.Currency, reqErr *apperror.Error) {
+	currency = services.CurrencyUSD
+	if vs := r.URL.Query().Get("vs"); vs != "" {
+		var err error
+		if currency, err = h.fx.ParseCurrency(vs); err != nil {
+			return nil, nil, "", apperror.Wrap(apperror.InvalidCurrency, err)
+		}
+	}
+
+	index, err := h.priceService.GetTokenPriceIndex(r.Context(), token)
+	if err != nil {
+		return nil, nil, "", apperror.As(err, apperror.PriceNotFound)
+	}
+	if price, err = h.fx.Convert(r.Context(), index.PriceUSD, currency); err != nil {
+		return nil, nil, "", apperror.Wrap(apperror.PriceNotFound, err)
+	}
+	return index, price, currency, nil
+}
+
+// parsePriceToken resolves the token from the last path segment
+func (h *PriceHandler) parsePriceToken(r *http.Request) (entities.Token, *apperror.Error) {
+	path := r.URL.Path
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 {
+		return entities.Token{}, apperror.New(apperror.MissingToken, "token address is required")
+	}
+	tokenAddr := parts[len(parts)-1]
+
+	addr, err := parseAddress(r.Context(), h.nameResolver, tokenAddr)
+	if err != nil {
+		return entities.Token{}, apperror.Wrap(apperror.InvalidToken, err)
 	}
-	decimalPos := len(priceStr) - 18
-	return priceStr[:decimalPos] + "." + priceStr[decimalPos:decimalPos+2]
+
+	return h.tokenRegistry.Resolve(r.Context(), addr), nil
 }
 
 func (h *PriceHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -97,10 +155,3 @@ func (h *PriceHandler) writeJSON(w http.ResponseWriter, status int, data interfa
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
-
-func (h *PriceHandler) writeError(w http.ResponseWriter, status int, code, message string) {
-	h.writeJSON(w, status, ErrorResponse{
-		Error:   code,
-		Message: message,
-	})
-}
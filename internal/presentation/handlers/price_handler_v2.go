@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// Prices from PriceService carry 18 decimals of precision
+const priceDecimals = 18
+
+type PriceResponseV2 struct {
+	Token        TokenResp           `json:"token"`
+	Price        Amount              `json:"price"`
+	Currency     string              `json:"currency"`
+	USDIndex     []USDIndexLegRespV2 `json:"usdIndex"`
+	DepegWarning string              `json:"depegWarning,omitempty"`
+	UpdatedAt    string              `json:"updatedAt"`
+	BlockNumber  uint64              `json:"blockNumber,omitempty"`
+	BlockAge     *int64              `json:"blockAgeSeconds,omitempty"`
+}
+
+// USDIndexLegRespV2 is the token's USD price through one stablecoin
+type USDIndexLegRespV2 struct {
+	Stable       TokenResp `json:"stable"`
+	PriceUSD     *Amount   `json:"priceUSD,omitempty"`
+	DeviationBps int64     `json:"deviationBps"`
+	Median       bool      `json:"median"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// GetPriceV2 handles GET /api/v2/price/{tokenAddress}
+func (h *PriceHandler) GetPriceV2(w http.ResponseWriter, r *http.Request) {
+	token, reqErr := h.parsePriceToken(r)
+	if reqErr != nil {
+		writeProblem(w, r, reqErr)
+		return
+	}
+
+	index, price, currency, reqErr := h.price(r, token)
+	if reqErr != nil {
+		writeProblem(w, r, reqErr)
+		return
+	}
+
+	blockNumber, blockAge := blockLag(h.chainHead, time.Now())
+	h.writeJSON(w, http.StatusOK, PriceResponseV2{
+		Token:        newTokenResp(token),
+		Price:        newAmount(price, priceDecimals),
+		Currency:     string(currency),
+		USDIndex:     newUSDIndexLegRespsV2(index),
+		DepegWarning: h.priceService.DepegWarning(token),
+		UpdatedAt:    time.Now().UTC().Format(time.RFC3339),
+		BlockNumber:  blockNumber,
+		BlockAge:     blockAge,
+	})
+}
+
+func newUSDIndexLegRespsV2(index *services.USDIndexPrice) []USDIndexLegRespV2 {
+	legs := make([]USDIndexLegRespV2, len(index.Legs))
+	for i, leg := range index.Legs {
+		legs[i] = USDIndexLegRespV2{Stable: newTokenResp(leg.Stable), DeviationBps: leg.DeviationBps, Median: leg.Median}
+		if leg.Error != nil {
+			legs[i].Error = leg.Error.Error()
+		} else {
+			price := newAmount(leg.PriceUSD, priceDecimals)
+			legs[i].PriceUSD = &price
+		}
+	}
+	return legs
+}
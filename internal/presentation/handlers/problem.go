@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+)
+
+// problemTypeBase prefixes the machine-readable problem type URIs
+const problemTypeBase = "https://dex-aggregator/problems/"
+
+// ProblemDetails is an RFC 7807 problem+json error body, used by the v2 API
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"` // In the client's Accept-Language
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Diagnostic explains a quote without a route, with fallback=true
+	Diagnostic *RouteDiagnosticResp `json:"diagnostic,omitempty"`
+	// Retryable errors failed on a node or venue, not on the request
+	Retryable bool `json:"retryable,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := apperror.As(err, apperror.Internal)
+	lang := apperror.Language(r.Header.Get("Accept-Language"))
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Content-Language", lang)
+	setRetryAfter(w, apiErr.Code)
+	w.WriteHeader(apiErr.Code.Status())
+	json.NewEncoder(w).Encode(newProblemDetails(apiErr, lang, r.URL.Path))
+}
+
+func newProblemDetails(apiErr *apperror.Error, lang, instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:     problemTypeBase + strings.ToLower(string(apiErr.Code)),
+		Title:    apiErr.Code.Message(lang),
+		Status:   apiErr.Code.Status(),
+		Detail:   apiErr.Detail,
+		Instance: instance,
+		Code:     string(apiErr.Code),
+
+		Diagnostic: diagnosticOf(apiErr),
+		Retryable:  apiErr.Code.Retryable(),
+	}
+}
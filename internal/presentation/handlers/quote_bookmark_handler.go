@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// RouteBookmarkRequest is the body of PUT /api/v1/bookmarks/{name}. Fields
+// take the same values as the quote parameters of the same name.
+type RouteBookmarkRequest struct {
+	TokenIn      string   `json:"tokenIn"`
+	TokenOut     string   `json:"tokenOut"`
+	DEXes        []string `json:"dexes"`
+	Slippage     string   `json:"slippage"`
+	FeeBps       uint64   `json:"feeBps"`
+	FeeRecipient string   `json:"feeRecipient"`
+}
+
+type RouteBookmarkResp struct {
+	Name         string             `json:"name"`
+	TokenIn      string             `json:"tokenIn"`
+	TokenOut     string             `json:"tokenOut"`
+	DEXes        []entities.DEXType `json:"dexes,omitempty"`
+	Slippage     string             `json:"slippage,omitempty"`
+	FeeBps       uint64             `json:"feeBps,omitempty"`
+	FeeRecipient string             `json:"feeRecipient,omitempty"`
+	CreatedAt    int64              `json:"createdAt"`
+	UpdatedAt    int64              `json:"updatedAt"`
+}
+
+func newRouteBookmarkResp(bookmark *entities.RouteBookmark) RouteBookmarkResp {
+	resp := RouteBookmarkResp{
+		Name:      bookmark.Name,
+		TokenIn:   bookmark.TokenIn.Hex(),
+		TokenOut:  bookmark.TokenOut.Hex(),
+		DEXes:     bookmark.DEXes,
+		Slippage:  bookmark.Slippage,
+		FeeBps:    bookmark.FeeBps,
+		CreatedAt: bookmark.CreatedAt.Unix(),
+		UpdatedAt: bookmark.UpdatedAt.Unix(),
+	}
+	if bookmark.FeeRecipient != nil {
+		resp.FeeRecipient = bookmark.FeeRecipient.Hex()
+	}
+	return resp
+}
+
+// SetBookmarks enables route bookmarks, kept per API key, and
+// GET /api/v1/quote/by-name/{name}
+func (h *QuoteHandler) SetBookmarks(bookmarks *services.RouteBookmarkService) {
+	h.bookmarks = bookmarks
+}
+
+// bookmarkValues are the quote parameters of a request for a bookmark. The
+// bookmark's settings replace the request's; those it leaves empty, such
+// as the amount, recipient or deadline, come from the request.
+func bookmarkValues(bookmark *entities.RouteBookmark, query url.Values) url.Values {
+	values := make(url.Values, len(query)+6)
+	for name, value := range query {
+		values[name] = value
+	}
+	values.Set("tokenIn", bookmark.TokenIn.Hex())
+	values.Set("tokenOut", bookmark.TokenOut.Hex())
+	if len(bookmark.DEXes) > 0 {
+		names := make([]string, len(bookmark.DEXes))
+		for i, dex := range bookmark.DEXes {
+			names[i] = string(dex)
+		}
+		values.Set("dexes", strings.Join(names, ","))
+	}
+	if bookmark.Slippage != "" {
+		values.Set("slippage", bookmark.Slippage)
+	}
+	if bookmark.FeeRecipient != nil {
+		values.Set("feeBps", strconv.FormatUint(bookmark.FeeBps, 10))
+		values.Set("feeRecipient", bookmark.FeeRecipient.Hex())
+	}
+	return values
+}
+
+// GetQuoteByName handles GET /api/v1/quote/by-name/{name}, quoting the
+// caller's bookmark of that name for the amountIn (or amounts) given. The
+// response is the same as GET /api/v1/quote.
+func (h *QuoteHandler) GetQuoteByName(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	bookmark, reqErr := h.callerBookmark(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	params, reqErr := h.parseQuoteValues(r.Context(), bookmarkValues(bookmark, r.URL.Query()))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	h.serveQuote(w, r, params, start)
+}
+
+// PutBookmark handles PUT /api/v1/bookmarks/{name}, creating or replacing
+// one of the caller's bookmarks. Tokens may be given as symbols or ENS
+// names and are kept as addresses.
+func (h *QuoteHandler) PutBookmark(w http.ResponseWriter, r *http.Request) {
+	key, reqErr := bookmarkOwner(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	var req RouteBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+	bookmark, reqErr := h.newBookmark(r.Context(), key.ID, chi.URLParam(r, "name"), req)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	saved, err := h.bookmarks.Save(r.Context(), *bookmark)
+	if err != nil {
+		h.writeBookmarkError(w, r, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, newRouteBookmarkResp(saved))
+}
+
+// newBookmark resolves a bookmark request and checks it quotes as a
+// request would
+func (h *QuoteHandler) newBookmark(ctx context.Context, keyID, name string, req RouteBookmarkRequest) (*entities.RouteBookmark, *apperror.Error) {
+	if req.TokenIn == "" || req.TokenOut == "" {
+		return nil, apperror.New(apperror.MissingParams, "tokenIn and tokenOut are required")
+	}
+	tokenIn, reqErr := h.resolveToken(ctx, "tokenIn", req.TokenIn)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	tokenOut, reqErr := h.resolveToken(ctx, "tokenOut", req.TokenOut)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	bookmark := &entities.RouteBookmark{
+		Name:     name,
+		KeyID:    keyID,
+		TokenIn:  tokenIn.Address,
+		TokenOut: tokenOut.Address,
+		Slippage: req.Slippage,
+		FeeBps:   req.FeeBps,
+	}
+	if len(req.DEXes) > 0 {
+		if bookmark.DEXes, reqErr = h.parseVenues(strings.Join(req.DEXes, ",")); reqErr != nil {
+			return nil, reqErr
+		}
+	}
+	if req.FeeBps > 0 || req.FeeRecipient != "" {
+		recipient, err := parseAddress(ctx, h.nameResolver, req.FeeRecipient)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidFee, "feeRecipient: "+err.Error())
+		}
+		bookmark.FeeRecipient = &recipient
+	}
+
+	// The rest is checked by quoting it, as a request for one unit would
+	if _, reqErr := h.parseQuoteValues(ctx, bookmarkValues(bookmark, url.Values{"amountIn": {"1"}})); reqErr != nil {
+		return nil, reqErr
+	}
+	return bookmark, nil
+}
+
+// ListBookmarks handles GET /api/v1/bookmarks, the caller's bookmarks by name
+func (h *QuoteHandler) ListBookmarks(w http.ResponseWriter, r *http.Request) {
+	key, reqErr := bookmarkOwner(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	bookmarks, err := h.bookmarks.List(r.Context(), key.ID)
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+	resp := make([]RouteBookmarkResp, 0, len(bookmarks))
+	for i := range bookmarks {
+		resp = append(resp, newRouteBookmarkResp(&bookmarks[i]))
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// GetBookmark handles GET /api/v1/bookmarks/{name}
+func (h *QuoteHandler) GetBookmark(w http.ResponseWriter, r *http.Request) {
+	bookmark, reqErr := h.callerBookmark(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, newRouteBookmarkResp(bookmark))
+}
+
+// DeleteBookmark handles DELETE /api/v1/bookmarks/{name}
+func (h *QuoteHandler) DeleteBookmark(w http.ResponseWriter, r *http.Request) {
+	key, reqErr := bookmarkOwner(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	if err := h.bookmarks.Delete(r.Context(), key.ID, chi.URLParam(r, "name")); err != nil {
+		h.writeBookmarkError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bookmarkOwner is the API key whose bookmarks the request works with
+func bookmarkOwner(r *http.Request) (*entities.APIKey, *apperror.Error) {
+	key := apiKeyFromContext(r.Context())
+	if key == nil {
+		return nil, apperror.New(apperror.MissingAPIKey, "bookmarks belong to an API key, send one in the "+APIKeyHeader+" header")
+	}
+	return key, nil
+}
+
+// callerBookmark looks up the {name} bookmark of the request's API key
+func (h *QuoteHandler) callerBookmark(r *http.Request) (*entities.RouteBookmark, *apperror.Error) {
+	key, reqErr := bookmarkOwner(r)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	bookmark, err := h.bookmarks.Get(r.Context(), key.ID, chi.URLParam(r, "name"))
+	switch {
+	case errors.Is(err, services.ErrBookmarkNotFound):
+		return nil, apperror.Wrap(apperror.BookmarkNotFound, err)
+	case err != nil:
+		return nil, apperror.Wrap(apperror.Internal, err)
+	}
+	return bookmark, nil
+}
+
+func (h *QuoteHandler) writeBookmarkError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrBookmarkNotFound):
+		WriteError(w, r, apperror.Wrap(apperror.BookmarkNotFound, err))
+	case errors.Is(err, services.ErrInvalidBookmark):
+		WriteError(w, r, apperror.Wrap(apperror.InvalidBookmark, err))
+	default:
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+)
+
+func TestBookmarkValues(t *testing.T) {
+	bookmark := &entities.RouteBookmark{
+		TokenIn:  entities.WETH.Address,
+		TokenOut: entities.USDC.Address,
+		DEXes:    []entities.DEXType{entities.DEXUniswapV3, entities.DEXCurve},
+		Slippage: "30",
+	}
+	values := bookmarkValues(bookmark, url.Values{
+		"tokenOut":  {"DAI"},
+		"amountIn":  {"2"},
+		"recipient": {"0x00000000000000000000000000000000000000aa"},
+	})
+	want := map[string]string{
+		"tokenIn":   entities.WETH.Address.Hex(),
+		"tokenOut":  entities.USDC.Address.Hex(), // The bookmark's, not the request's
+		"dexes":     "uniswap_v3,curve",
+		"slippage":  "30",
+		"amountIn":  "2",
+		"recipient": "0x00000000000000000000000000000000000000aa",
+		"feeBps":    "",
+	}
+	for name, value := range want {
+		if got := values.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestNewBookmark(t *testing.T) {
+	h := NewQuoteHandler(nil, nil, nil, nil, entities.DefaultRegistry(), nil)
+
+	_, reqErr := h.newBookmark(context.Background(), "k1", "buy-usdc", RouteBookmarkRequest{
+		TokenIn:  "ETH",
+		TokenOut: "USDC",
+		Slippage: "auto",
+	})
+	if reqErr == nil || reqErr.Code != apperror.InvalidSlippage {
+		t.Fatalf("newBookmark() with slippage=auto disabled error = %v, want %s", reqErr, apperror.InvalidSlippage)
+	}
+
+	bookmark, reqErr := h.newBookmark(context.Background(), "k1", "buy-usdc", RouteBookmarkRequest{
+		TokenIn:  "ETH",
+		TokenOut: "USDC",
+		DEXes:    []string{"uniswap_v3", " uniswap_v3"},
+		Slippage: "50",
+	})
+	if reqErr != nil {
+		t.Fatalf("newBookmark() error = %v", reqErr)
+	}
+	if bookmark.TokenIn != entities.NativeTokenAddress || bookmark.TokenOut != entities.USDC.Address {
+		t.Errorf("tokens = %s, %s", bookmark.TokenIn.Hex(), bookmark.TokenOut.Hex())
+	}
+	if len(bookmark.DEXes) != 1 || bookmark.DEXes[0] != entities.DEXUniswapV3 {
+		t.Errorf("dexes = %v", bookmark.DEXes)
+	}
+
+	tests := []struct {
+		name string
+		req  RouteBookmarkRequest
+		want apperror.Code
+	}{
+		{"missing token", RouteBookmarkRequest{TokenIn: "ETH"}, apperror.MissingParams},
+		{"unknown token", RouteBookmarkRequest{TokenIn: "ETH", TokenOut: "NOTATOKEN"}, apperror.UnsupportedToken},
+		{"bad slippage", RouteBookmarkRequest{TokenIn: "ETH", TokenOut: "USDC", Slippage: "20000"}, apperror.InvalidSlippage},
+		{"fee without recipient", RouteBookmarkRequest{TokenIn: "ETH", TokenOut: "USDC", FeeBps: 10}, apperror.InvalidFee},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, reqErr := h.newBookmark(context.Background(), "k1", "flow", tt.req); reqErr == nil || reqErr.Code != tt.want {
+				t.Errorf("newBookmark() error = %v, want %s", reqErr, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type QuoteComparisonResponse struct {
+	Quote      QuoteResponse        `json:"quote"`
+	References []ReferenceQuoteResp `json:"references"`
+}
+
+type ReferenceQuoteResp struct {
+	Source    string `json:"source"`
+	AmountOut string `json:"amountOut,omitempty"`
+	Delta     string `json:"delta,omitempty"` // Our amountOut minus theirs
+	DeltaBps  int64  `json:"deltaBps"`        // Positive when we beat the reference
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SetCompareService enables GET /api/v1/quote/compare
+func (h *QuoteHandler) SetCompareService(compareService *services.CompareService) {
+	h.compareService = compareService
+}
+
+// CompareQuote handles GET /api/v1/quote/compare
+func (h *QuoteHandler) CompareQuote(w http.ResponseWriter, r *http.Request) {
+	params, reqErr := h.parseQuoteParams(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	comparison, err := h.compareService.Compare(r.Context(), params.tokenIn, params.tokenOut, params.amountIn, params.slippageBps)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.NoRoute))
+		return
+	}
+	comparison.Quote.NativeIn, comparison.Quote.NativeOut = params.nativeIn, params.nativeOut
+
+	references := make([]ReferenceQuoteResp, 0, len(comparison.References))
+	for _, ref := range comparison.References {
+		resp := ReferenceQuoteResp{
+			Source:    ref.Source,
+			DeltaBps:  ref.DeltaBps,
+			LatencyMs: ref.LatencyMs,
+			Error:     ref.Error,
+		}
+		if ref.AmountOut != nil {
+			resp.AmountOut = ref.AmountOut.String()
+		}
+		if ref.Delta != nil {
+			resp.Delta = ref.Delta.String()
+		}
+		references = append(references, resp)
+	}
+
+	h.writeJSON(w, http.StatusOK, QuoteComparisonResponse{
+		Quote:      h.buildQuoteResponse(comparison.Quote, params.verbose),
+		References: references,
+	})
+}
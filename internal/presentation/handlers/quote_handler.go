@@ -1,35 +1,103 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 
+	"github.com/bimakw/dex-aggregator/internal/apperror"
 	"github.com/bimakw/dex-aggregator/internal/domain/entities"
 	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/eip712"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
 )
 
 type QuoteHandler struct {
-	routerService *services.RouterService
-	tokenRegistry map[common.Address]entities.Token
+	routerService    *services.RouterService
+	screeningService *services.TokenScreeningService
+	swapService      *services.SwapService
+	feeService       *services.FeeService
+	tokenRegistry    *entities.TokenRegistry
+	nameResolver     NameResolver
+	compareService   *services.CompareService       // Optional, see SetCompareService
+	recorder         *services.QuoteRecorder        // Optional, see SetQuoteRecorder
+	shadow           *services.ShadowEvaluator      // Optional, see SetShadowEvaluator
+	quoteBook        *services.QuoteBook            // Optional, see SetQuoteBook
+	apiKeyService    *services.APIKeyService        // Optional, see SetAPIKeyService
+	priceService     *services.PriceService         // Optional, see SetPriceService
+	slippageTuner    *services.SlippageTuner        // Optional, see SetSlippageTuner
+	latency          *services.LatencyHistograms    // Optional, see SetLatencyHistograms
+	auditLog         *services.AuditLog             // Optional, see SetAuditLog
+	bookmarks        *services.RouteBookmarkService // Optional, see SetBookmarks
+	chainHead        ChainHead                      // Optional, see SetChainHead
 }
 
-func NewQuoteHandler(routerService *services.RouterService) *QuoteHandler {
-	registry := map[common.Address]entities.Token{
-		entities.WETH.Address: entities.WETH,
-		entities.USDC.Address: entities.USDC,
-		entities.USDT.Address: entities.USDT,
-		entities.DAI.Address:  entities.DAI,
-	}
-
+func NewQuoteHandler(routerService *services.RouterService, screeningService *services.TokenScreeningService, swapService *services.SwapService, feeService *services.FeeService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *QuoteHandler {
 	return &QuoteHandler{
-		routerService: routerService,
-		tokenRegistry: registry,
+		routerService:    routerService,
+		screeningService: screeningService,
+		swapService:      swapService,
+		feeService:       feeService,
+		tokenRegistry:    tokenRegistry,
+		nameResolver:     nameResolver,
 	}
 }
 
+// SetQuoteRecorder records every quote served by the v1 and v2 quote endpoints for analytics
+func (h *QuoteHandler) SetQuoteRecorder(recorder *services.QuoteRecorder) {
+	h.recorder = recorder
+}
+
+// SetShadowEvaluator re-routes a sample of served quotes with other
+// strategies in the background
+func (h *QuoteHandler) SetShadowEvaluator(shadow *services.ShadowEvaluator) {
+	h.shadow = shadow
+}
+
+// SetSlippageTuner enables slippage=auto
+func (h *QuoteHandler) SetSlippageTuner(tuner *services.SlippageTuner) {
+	h.slippageTuner = tuner
+}
+
+// SetLatencyHistograms aggregates the stage timings of every quote served
+// by the v1 and v2 quote endpoints
+func (h *QuoteHandler) SetLatencyHistograms(latency *services.LatencyHistograms) {
+	h.latency = latency
+}
+
+// SetAuditLog keeps a sample of quote requests with the response sent
+func (h *QuoteHandler) SetAuditLog(auditLog *services.AuditLog) {
+	h.auditLog = auditLog
+}
+
+// SetAPIKeyService attributes quotes made with an API key to that key's usage
+func (h *QuoteHandler) SetAPIKeyService(apiKeyService *services.APIKeyService) {
+	h.apiKeyService = apiKeyService
+}
+
+// SetPriceService adds the USD value of each quote's input, output and
+// price impact
+func (h *QuoteHandler) SetPriceService(priceService *services.PriceService) {
+	h.priceService = priceService
+}
+
+// SetChainHead adds the head block and its age to every quote
+func (h *QuoteHandler) SetChainHead(head ChainHead) {
+	h.chainHead = head
+}
+
 type QuoteRequest struct {
 	TokenIn  string `json:"tokenIn"`
 	TokenOut string `json:"tokenOut"`
@@ -37,128 +105,884 @@ type QuoteRequest struct {
 }
 
 type QuoteResponse struct {
-	TokenIn      string            `json:"tokenIn"`
-	TokenOut     string            `json:"tokenOut"`
-	AmountIn     string            `json:"amountIn"`
-	AmountOut    string            `json:"amountOut"`
-	MinAmountOut string            `json:"minAmountOut,omitempty"`
-	SlippageBps  uint64            `json:"slippageBps,omitempty"`
-	Route        []RouteHop        `json:"route"`
-	SplitRoutes  []SplitRouteResp  `json:"splitRoutes,omitempty"`
-	PriceImpact  string            `json:"priceImpact"`
-	PriceWarning string            `json:"priceWarning,omitempty"`
-	GasEstimate  uint64            `json:"gasEstimate"`
-	Sources      map[string]string `json:"sources"`
+	TokenIn         string               `json:"tokenIn"`
+	TokenOut        string               `json:"tokenOut"`
+	AmountIn        string               `json:"amountIn"`
+	AmountOut       string               `json:"amountOut"`
+	AmountInUSD     string               `json:"amountInUsd,omitempty"`
+	AmountOutUSD    string               `json:"amountOutUsd,omitempty"`
+	MinAmountOut    string               `json:"minAmountOut,omitempty"`
+	SlippageBps     uint64               `json:"slippageBps,omitempty"`
+	SlippageDefault *SlippageDefaultResp `json:"slippageDefault,omitempty"`
+	SlippageAuto    *SlippageAutoResp    `json:"slippageAuto,omitempty"`
+	Audit           *entities.QuoteAudit `json:"audit,omitempty"` // With audit=true
+	SavingsBps      *SavingsResp         `json:"savingsBps,omitempty"`
+	TradeSize       *TradeSizeResp       `json:"tradeSize,omitempty"`
+	QuoteID         string               `json:"quoteId,omitempty"`
+	ExpiresAt       int64                `json:"expiresAt"` // Unix seconds, also the transaction deadline
+	IntegratorFee   *IntegratorFeeResp   `json:"integratorFee,omitempty"`
+	Route           []RouteHop           `json:"route"`
+	SplitRoutes     []SplitRouteResp     `json:"splitRoutes,omitempty"`
+	PriceImpact     string               `json:"priceImpact"`
+	PriceImpactUSD  string               `json:"priceImpactUsd,omitempty"`
+	PriceWarning    string               `json:"priceWarning,omitempty"`
+	TokenWarnings   []TokenWarningResp   `json:"tokenWarnings,omitempty"`
+	GasEstimate     uint64               `json:"gasEstimate"`
+	QuotedAtBlock   uint64               `json:"quotedAtBlock,omitempty"`
+	PinnedBlock     uint64               `json:"pinnedBlock,omitempty"` // With block=, the block every venue was read at
+	Strategy        string               `json:"strategy,omitempty"`
+	OptimizeFor     string               `json:"optimizeFor,omitempty"`
+	VerifiedPools   bool                 `json:"verifiedPools,omitempty"`
+	Venues          []entities.DEXType   `json:"venues,omitempty"`       // With dexes=, the venues the quote was limited to
+	ConvertedVia    string               `json:"convertedVia,omitempty"` // Equivalent token routed through when the pair had no route
+	Fallback        *RouteDiagnosticResp `json:"fallback,omitempty"`     // With fallback=true, why the pair had no route of its own
+	GasSource       string               `json:"gasSource,omitempty"`
+	GasCost         *GasCostResp         `json:"gasCost,omitempty"`
+	Transaction     *TransactionResp     `json:"transaction,omitempty"` // Only with recipient
+	Approval        *ApprovalResp        `json:"approval,omitempty"`    // Approvals to send before the transaction
+	RFQOrder        *RFQOrderResp        `json:"rfqOrder,omitempty"`    // Signed maker order to settle
+	RouteProcessor  *RouteProcessorResp  `json:"routeProcessor,omitempty"`
+	WalletRequests  []WalletRequestResp  `json:"walletRequests,omitempty"`
+	BlockNumber     uint64               `json:"blockNumber,omitempty"`
+	BlockAgeSeconds *int64               `json:"blockAgeSeconds,omitempty"`
+	Sources         map[string]string    `json:"sources"`
+	SourceDetails   []SourceDetailResp   `json:"sourceDetails,omitempty"` // Only with verbose=true
+	ExecutionPlan   *ExecutionPlanResp   `json:"executionPlan,omitempty"` // With plan=true, when the impact is over the threshold
+	Timing          *TimingResp          `json:"timing,omitempty"`        // Only with debug=true
 }
 
-type SplitRouteResp struct {
-	DEX        string `json:"dex"`
-	Percentage uint64 `json:"percentage"`
-	AmountIn   string `json:"amountIn"`
-	AmountOut  string `json:"amountOut"`
+// TimingResp is the time a quote request spent in each stage, in ms.
+// Venues are read concurrently, so stages can add up to more than the total.
+type TimingResp struct {
+	TotalMs float64            `json:"totalMs"`
+	Stages  map[string]float64 `json:"stages"`
 }
 
-type RouteHop struct {
+func newTimingResp(timing *services.QuoteTiming, total time.Duration) *TimingResp {
+	stages := make(map[string]float64)
+	for stage, d := range timing.Stages() {
+		stages[stage] = services.DurationMs(d)
+	}
+	return &TimingResp{TotalMs: services.DurationMs(total), Stages: stages}
+}
+
+type SourceDetailResp struct {
+	DEX            string `json:"dex"`
+	AmountOut      string `json:"amountOut,omitempty"`
+	GasEstimate    uint64 `json:"gasEstimate,omitempty"`
+	LiquidityScore uint64 `json:"liquidityScore"`
+	LatencyMs      int64  `json:"latencyMs"`
+	Error          string `json:"error,omitempty"`
+}
+
+type GasCostResp struct {
+	BaseFeePerGas        string `json:"baseFeePerGas"`
+	MaxFeePerGas         string `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas"`
+	CostWei              string `json:"costWei"`
+	CostEth              string `json:"costEth"`
+	CostUSD              string `json:"costUsd,omitempty"`
+}
+
+type TransactionResp struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Data  string `json:"data"`
+	Value string `json:"value"`
+	Gas   uint64 `json:"gas,omitempty"`
+}
+
+// WalletRequestResp is an EIP-1193 request, passed to a wallet's request
+// method as is. Quotes list the approvals, then the transaction, or the
+// Permit2 permit to sign when the transaction needs one.
+type WalletRequestResp struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// WalletTransactionResp is a transaction as eth_sendTransaction takes it,
+// quantities in hex. Fees are left to the wallet without a gas suggestion.
+type WalletTransactionResp struct {
+	From                 string `json:"from"`
+	To                   string `json:"to"`
+	Data                 string `json:"data"`
+	Value                string `json:"value"`
+	Gas                  string `json:"gas,omitempty"`
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"`
+	ChainID              string `json:"chainId"`
+}
+
+// RouteProcessorResp is the quote encoded for Sushi's RouteProcessor: the
+// route bytes for an integrator's own processRoute call, and that call
+type RouteProcessorResp struct {
+	Address     string          `json:"address"`
+	Route       string          `json:"route"`
+	Transaction TransactionResp `json:"transaction"`
+}
+
+// RouteDiagnosticResp explains why a pair had no route: the cause, the
+// constraints relaxed to retry it and each venue's answer
+type RouteDiagnosticResp struct {
+	Cause       string                `json:"cause"`
+	ProbeAmount string                `json:"probeAmount,omitempty"` // A thousandth of amountIn, raw units
+	Relaxed     []string              `json:"relaxed,omitempty"`
+	Venues      []VenueDiagnosticResp `json:"venues"`
+}
+
+type VenueDiagnosticResp struct {
 	DEX      string `json:"dex"`
-	Pair     string `json:"pair"`
-	TokenIn  string `json:"tokenIn"`
-	TokenOut string `json:"tokenOut"`
-	Fee      uint64 `json:"fee"`
+	Reason   string `json:"reason"`
+	Detail   string `json:"detail,omitempty"`
+	ProbeOut string `json:"probeOut,omitempty"` // Set when the venue fills the probe
+}
+
+func newRouteDiagnosticResp(diagnostic *entities.RouteDiagnostic) *RouteDiagnosticResp {
+	if diagnostic == nil {
+		return nil
+	}
+	resp := &RouteDiagnosticResp{
+		Cause:   diagnostic.Cause,
+		Relaxed: diagnostic.Relaxed,
+		Venues:  make([]VenueDiagnosticResp, 0, len(diagnostic.Venues)),
+	}
+	if diagnostic.ProbeAmount != nil {
+		resp.ProbeAmount = diagnostic.ProbeAmount.String()
+	}
+	for _, venue := range diagnostic.Venues {
+		v := VenueDiagnosticResp{DEX: string(venue.DEX), Reason: venue.Reason, Detail: venue.Detail}
+		if venue.ProbeOut != nil {
+			v.ProbeOut = venue.ProbeOut.String()
+		}
+		resp.Venues = append(resp.Venues, v)
+	}
+	return resp
+}
+
+// isNoRoute reports whether a quote failed for want of a route, which
+// fallback=true retries
+func isNoRoute(err error) bool {
+	switch apperror.CodeOf(err) {
+	case apperror.NoRoute, apperror.InsufficientLiquidity, apperror.AmountTooLarge:
+		return true
+	}
+	return false
+}
+
+// ApprovalResp lists the approvals the sender sends, in order, before the
+// transaction, along with the token quirks that shaped them
+type ApprovalResp struct {
+	Token                string            `json:"token"`
+	Spender              string            `json:"spender"`
+	Permit2Spender       string            `json:"permit2Spender,omitempty"` // Allowed on Permit2 when Spender is Permit2
+	Allowance            string            `json:"allowance"`
+	Amount               string            `json:"amount"`
+	ApproveResetRequired bool              `json:"approveResetRequired,omitempty"`
+	NoReturnValue        bool              `json:"noReturnValue,omitempty"`
+	Blacklistable        bool              `json:"blacklistable,omitempty"`
+	Frozen               bool              `json:"frozen,omitempty"`
+	Steps                []TransactionResp `json:"steps"`
+	Permit               *PermitResp       `json:"permit,omitempty"` // Signed in place of Permit2's approval, the last step
+}
+
+// PermitResp is a Permit2 permit. Its nonce and sigDeadline go back as
+// permitNonce and permitDeadline with the signature.
+type PermitResp struct {
+	Token       string `json:"token"`
+	Amount      string `json:"amount"`
+	Expiration  uint64 `json:"expiration"`
+	Nonce       uint64 `json:"nonce"`
+	Spender     string `json:"spender"`
+	SigDeadline uint64 `json:"sigDeadline"`
+	Signed      bool   `json:"signed,omitempty"` // Sent with the transaction, so the last step is gone
+}
+
+type RFQOrderResp struct {
+	MakerName string `json:"makerName"`
+	Maker     string `json:"maker"`
+	Taker     string `json:"taker"`
+	TokenIn   string `json:"tokenIn"`
+	TokenOut  string `json:"tokenOut"`
+	AmountIn  string `json:"amountIn"`
+	AmountOut string `json:"amountOut"`
+	Expiry    uint64 `json:"expiry"`
+	Nonce     string `json:"nonce"`
+	Signature string `json:"signature"`
 }
 
-type ErrorResponse struct {
-	Error   string `json:"error"`
+// IntegratorFeeResp is the referral fee already deducted from amountOut
+type IntegratorFeeResp struct {
+	Bps       uint64 `json:"bps"`
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount"`
+}
+
+// SavingsResp is the quote's gain over single venues in basis points
+type SavingsResp struct {
+	VsWorst     int64  `json:"vsWorst"`
+	VsMedian    int64  `json:"vsMedian"`
+	VsBestVenue *int64 `json:"vsBestVenue,omitempty"` // Set when splitting or a market maker won
+}
+
+// TradeSizeResp relates the trade to the depth of the pair's pools, in the
+// input token's raw units
+type TradeSizeResp struct {
+	MaxRecommendedAmountIn string `json:"maxRecommendedAmountIn"` // Split across the pools, each within the impact ceiling
+	ImpactCeilingBps       uint64 `json:"impactCeilingBps"`
+	AccessibleLiquidity    string `json:"accessibleLiquidity"`
+	ExceedsDepth           bool   `json:"exceedsDepth,omitempty"` // Over the configured share of AccessibleLiquidity
+}
+
+func newTradeSizeResp(size *entities.TradeSize) *TradeSizeResp {
+	if size == nil {
+		return nil
+	}
+	return &TradeSizeResp{
+		MaxRecommendedAmountIn: size.MaxRecommendedAmountIn.String(),
+		ImpactCeilingBps:       size.ImpactCeilingBps,
+		AccessibleLiquidity:    size.AccessibleLiquidity.String(),
+		ExceedsDepth:           size.ExceedsDepth,
+	}
+}
+
+// SlippageDefaultResp is the pair-class slippage default and why it was picked
+type SlippageDefaultResp struct {
+	Class  string `json:"class"`
+	Bps    uint64 `json:"bps"`
+	Reason string `json:"reason"`
+}
+
+// SlippageAutoResp is the slippage picked for slippage=auto and why
+type SlippageAutoResp struct {
+	Bps             uint64  `json:"bps"`
+	Samples         int     `json:"samples"`
+	FillProbability float64 `json:"fillProbability"`
+	VolatilityBps   float64 `json:"volatilityBps"`
+	Reason          string  `json:"reason"`
+}
+
+type TokenWarningResp struct {
+	Token   string `json:"token"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
-func (h *QuoteHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
-	tokenInAddr := r.URL.Query().Get("tokenIn")
-	tokenOutAddr := r.URL.Query().Get("tokenOut")
-	amountInStr := r.URL.Query().Get("amountIn")
-	slippageStr := r.URL.Query().Get("slippage")
+type SplitRouteResp struct {
+	DEX        string     `json:"dex"`
+	Percentage uint64     `json:"percentage"`
+	AmountIn   string     `json:"amountIn"`
+	AmountOut  string     `json:"amountOut"`
+	Route      []RouteHop `json:"route,omitempty"`
+}
 
-	if tokenInAddr == "" || tokenOutAddr == "" || amountInStr == "" {
-		h.writeError(w, http.StatusBadRequest, "missing_params", "tokenIn, tokenOut, and amountIn are required")
-		return
+type RouteHop struct {
+	DEX       string `json:"dex"`
+	Pair      string `json:"pair"`
+	TokenIn   string `json:"tokenIn"`
+	TokenOut  string `json:"tokenOut"`
+	Fee       uint64 `json:"fee"` // Basis points; hundredths of a bip for uniswap_v3
+	AmountIn  string `json:"amountIn,omitempty"`
+	AmountOut string `json:"amountOut,omitempty"`
+	TVLUSD    string `json:"tvlUsd,omitempty"`
+	Volume    string `json:"volume24hUsd,omitempty"`
+
+	// Set when pools are verified; the reasons explain an unverified pool
+	PoolVerificationStatus  string   `json:"poolVerificationStatus,omitempty"`
+	PoolVerificationReasons []string `json:"poolVerificationReasons,omitempty"`
+}
+
+// newRouteHops describes a route's hops, including the amount each hop
+// takes in and pays out
+func newRouteHops(route *entities.Route) []RouteHop {
+	if route == nil {
+		return nil
+	}
+	hops := make([]RouteHop, 0, len(route.Hops))
+	for _, hop := range route.Hops {
+		resp := RouteHop{
+			DEX:      string(hop.Pair.DEX),
+			Pair:     hop.Pair.Address.Hex(),
+			TokenIn:  hop.TokenIn.Hex(),
+			TokenOut: hop.TokenOut.Hex(),
+			Fee:      hop.Pair.Fee,
+		}
+		if hop.AmountIn != nil {
+			resp.AmountIn = hop.AmountIn.String()
+		}
+		if hop.AmountOut != nil {
+			resp.AmountOut = hop.AmountOut.String()
+		}
+		if hop.Pair.TVLUSD != nil {
+			resp.TVLUSD = usdFormat.format(hop.Pair.TVLUSD, priceDecimals)
+		}
+		if hop.Pair.Volume24hUSD != nil {
+			resp.Volume = usdFormat.format(hop.Pair.Volume24hUSD, priceDecimals)
+		}
+		if verification := hop.Pair.Verification; verification != nil {
+			resp.PoolVerificationStatus = verification.Status
+			resp.PoolVerificationReasons = verification.Reasons
+		}
+		hops = append(hops, resp)
 	}
+	return hops
+}
 
-	if !common.IsHexAddress(tokenInAddr) {
-		h.writeError(w, http.StatusBadRequest, "invalid_token_in", "tokenIn is not a valid address")
+// maxQuoteDeadline caps the deadline query parameter, in seconds
+const maxQuoteDeadline = 3600
+
+// quoteParams holds validated /quote query parameters shared by all API versions
+type quoteParams struct {
+	tokenIn     entities.Token
+	tokenOut    entities.Token
+	amountIn    *big.Int
+	amounts     []*big.Int // amounts=, a ladder of sizes quoted together; amountIn is the first
+	slippageBps uint64
+	autoSlip    bool // slippage=auto
+	strategy    string
+	optimizeFor services.OptimizeFor
+	venues      []entities.DEXType // dexes=, nil routes across every venue
+	block       *uint64            // block=, 0 pins to the head; nil reads each venue at its latest
+	deadline    time.Duration      // Zero keeps the router's default
+	minLiqUSD   *big.Int           // Overrides the pool liquidity floor, nil keeps it
+	verified    bool               // verifiedPools=true, route through verified pools only
+	sender      *common.Address
+	recipient   *common.Address
+	routeProc   bool // routeProcessor=true, encode the route for Sushi's RouteProcessor
+	feeBps      uint64
+	feeTo       common.Address
+	verbose     bool
+	fallback    bool        // On no route, retry with relaxed constraints and explain each venue
+	audit       bool        // Attach the on-chain inputs for offline replay
+	debug       bool        // Report the time spent in each stage
+	nativeIn    bool        // tokenIn is the wrapper of the gas token the caller pays
+	nativeOut   bool        // tokenOut is the wrapper of the gas token the caller gets
+	plan        *planParams // plan=true, nil without
+	// The sender's signed Permit2 permit, nil without
+	permit *entities.Permit2Permit
+}
+
+func (h *QuoteHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	params, reqErr := h.parseQuoteParams(r)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
 		return
 	}
-	if !common.IsHexAddress(tokenOutAddr) {
-		h.writeError(w, http.StatusBadRequest, "invalid_token_out", "tokenOut is not a valid address")
+	h.serveQuote(w, r, params, start)
+}
+
+// serveQuote quotes validated parameters and writes the v1 response, a
+// ladder when several amounts were asked for
+func (h *QuoteHandler) serveQuote(w http.ResponseWriter, r *http.Request, params *quoteParams, start time.Time) {
+	if len(params.amounts) > 0 {
+		h.writeLadder(w, r, params, start)
 		return
 	}
 
-	amountIn, ok := new(big.Int).SetString(amountInStr, 10)
-	if !ok || amountIn.Sign() <= 0 {
-		h.writeError(w, http.StatusBadRequest, "invalid_amount", "amountIn must be a positive integer")
+	ctx, timing := services.WithQuoteTiming(r.Context())
+	quote, reqErr := h.quote(ctx, params)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
 		return
 	}
 
-	// Parse slippage (optional, in basis points, default 50 = 0.5%)
+	h.writeQuote(w, r, params, []*entities.Quote{quote}, timing, start, func(timingResp *TimingResp) interface{} {
+		response := h.buildQuoteResponse(quote, params.verbose)
+		if params.audit {
+			response.Audit = services.NewQuoteAudit(quote)
+		}
+		response.Timing = timingResp
+		return response
+	})
+}
+
+// writeQuote encodes the response build returns, charging the time to
+// serialization, and adds the request's timings to the histograms. With
+// debug=true the response is built again with the timings attached. The
+// quotes served are audit logged with the body if the request is sampled.
+func (h *QuoteHandler) writeQuote(w http.ResponseWriter, r *http.Request, params *quoteParams, quotes []*entities.Quote, timing *services.QuoteTiming, start time.Time, build func(*TimingResp) interface{}) {
+	serializeStart := time.Now()
+	body, _ := json.Marshal(build(nil))
+	timing.Add(services.StageSerialization, time.Since(serializeStart))
+	total := time.Since(start)
+	if h.latency != nil {
+		h.latency.Observe(timing, total)
+	}
+	if params.debug {
+		body, _ = json.Marshal(build(newTimingResp(timing, total)))
+	}
+
+	body = append(body, '\n')
+	if h.auditLog != nil && h.auditLog.Sampled() {
+		h.auditLog.Record(newAuditLogEntry(r, params, quotes, body))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// parseQuoteParams validates the query string of a quote request
+func (h *QuoteHandler) parseQuoteParams(r *http.Request) (*quoteParams, *apperror.Error) {
+	return h.parseQuoteValues(r.Context(), r.URL.Query())
+}
+
+// parseQuoteValues validates quote parameters given as query values
+func (h *QuoteHandler) parseQuoteValues(ctx context.Context, query url.Values) (*quoteParams, *apperror.Error) {
+	tokenInParam := query.Get("tokenIn")
+	tokenOutParam := query.Get("tokenOut")
+	amountInStr := query.Get("amountIn")
+	amountsStr := query.Get("amounts")
+	slippageStr := query.Get("slippage")
+
+	if tokenInParam == "" || tokenOutParam == "" || (amountInStr == "" && amountsStr == "") {
+		return nil, apperror.New(apperror.MissingParams, "tokenIn, tokenOut, and amountIn are required")
+	}
+	if amountInStr != "" && amountsStr != "" {
+		return nil, apperror.New(apperror.InvalidAmount, "amountIn and amounts can't be combined")
+	}
+
+	tokenIn, reqErr := h.resolveToken(ctx, "tokenIn", tokenInParam)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	tokenOut, reqErr := h.resolveToken(ctx, "tokenOut", tokenOutParam)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	// Pools trade the gas token's wrapper; the built swap wraps and unwraps
+	// it. Wrapping itself is quoted through the wrapper's 1:1 virtual pool.
+	wrappedIn, nativeIn := h.tokenRegistry.Wrap(tokenIn)
+	wrappedOut, nativeOut := h.tokenRegistry.Wrap(tokenOut)
+	switch {
+	case nativeIn && nativeOut:
+		return nil, apperror.New(apperror.InvalidTokenOut, "tokenOut: the gas token can't be swapped for itself")
+	case wrappedIn.Address == wrappedOut.Address && (nativeIn || nativeOut):
+		nativeIn, nativeOut = false, false
+	default:
+		tokenIn, tokenOut = wrappedIn, wrappedOut
+	}
+
+	var amountIn *big.Int
+	var amounts []*big.Int
+	if amountsStr != "" {
+		if amounts, reqErr = parseLadderAmounts(amountsStr, tokenIn); reqErr != nil {
+			return nil, reqErr
+		}
+		amountIn = amounts[0]
+	} else if amountIn, reqErr = parseQuoteAmount("amountIn", amountInStr, tokenIn); reqErr != nil {
+		return nil, reqErr
+	}
+
+	// Parse slippage (optional, in basis points or auto, default by pair class)
 	var slippageBps uint64
-	if slippageStr != "" {
+	autoSlip := slippageStr == "auto"
+	if autoSlip && h.slippageTuner == nil {
+		return nil, apperror.New(apperror.InvalidSlippage, "slippage=auto is not enabled")
+	}
+	if slippageStr != "" && !autoSlip {
 		slippage, ok := new(big.Int).SetString(slippageStr, 10)
 		if !ok || slippage.Sign() < 0 || slippage.Cmp(big.NewInt(10000)) > 0 {
-			h.writeError(w, http.StatusBadRequest, "invalid_slippage", "slippage must be 0-10000 basis points")
-			return
+			return nil, apperror.New(apperror.InvalidSlippage, "slippage must be 0-10000 basis points")
 		}
 		slippageBps = slippage.Uint64()
 	}
 
-	tokenIn, ok := h.tokenRegistry[common.HexToAddress(tokenInAddr)]
-	if !ok {
-		tokenIn = entities.Token{
-			Address:  common.HexToAddress(tokenInAddr),
-			Symbol:   "UNKNOWN",
-			Decimals: 18,
+	var deadline time.Duration
+	if deadlineStr := query.Get("deadline"); deadlineStr != "" {
+		seconds, err := strconv.ParseUint(deadlineStr, 10, 64)
+		if err != nil || seconds == 0 || seconds > maxQuoteDeadline {
+			return nil, apperror.New(apperror.InvalidDeadline, fmt.Sprintf("deadline must be 1-%d seconds", maxQuoteDeadline))
+		}
+		deadline = time.Duration(seconds) * time.Second
+	}
+
+	// Power users may route through smaller pools, or hold them to more
+	var minLiqUSD *big.Int
+	if minLiqStr := query.Get("minLiquidityUsd"); minLiqStr != "" {
+		usd, err := strconv.ParseUint(minLiqStr, 10, 64)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidLiquidity, "minLiquidityUsd must be whole US dollars")
+		}
+		minLiqUSD = new(big.Int).Mul(new(big.Int).SetUint64(usd), big.NewInt(1e18))
+	}
+
+	var recipient *common.Address
+	if recipientStr := query.Get("recipient"); recipientStr != "" {
+		addr, err := parseAddress(ctx, h.nameResolver, recipientStr)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidRecipient, "recipient: "+err.Error())
 		}
+		recipient = &addr
+	}
+
+	var sender *common.Address
+	if senderStr := query.Get("sender"); senderStr != "" {
+		addr, err := parseAddress(ctx, h.nameResolver, senderStr)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidSender, "sender: "+err.Error())
+		}
+		sender = &addr
+	}
+	// Either one alone means the same account sends and receives
+	if sender == nil {
+		sender = recipient
+	} else if recipient == nil {
+		recipient = sender
 	}
 
-	tokenOut, ok := h.tokenRegistry[common.HexToAddress(tokenOutAddr)]
-	if !ok {
-		tokenOut = entities.Token{
-			Address:  common.HexToAddress(tokenOutAddr),
-			Symbol:   "UNKNOWN",
-			Decimals: 18,
+	routeProc := query.Get("routeProcessor") == "true"
+	if routeProc && recipient == nil {
+		return nil, apperror.New(apperror.InvalidRecipient, "routeProcessor=true needs a recipient, which the route pays")
+	}
+
+	verified := query.Get("verifiedPools") == "true"
+	if verified && !h.routerService.VerifiesPools() {
+		return nil, apperror.New(apperror.VerifyDisabled, "pool verification is not enabled")
+	}
+
+	var feeBps uint64
+	var feeTo common.Address
+	feeBpsStr, feeToStr := query.Get("feeBps"), query.Get("feeRecipient")
+	if feeBpsStr != "" || feeToStr != "" {
+		enabled := false
+		if h.swapService != nil {
+			_, enabled = h.swapService.FeeCollector()
+		}
+		if !enabled {
+			return nil, apperror.New(apperror.FeesDisabled, "integrator fees are not enabled")
 		}
+		bps, err := strconv.ParseUint(feeBpsStr, 10, 64)
+		if err != nil || bps == 0 || bps > services.MaxIntegratorFeeBps {
+			return nil, apperror.New(apperror.InvalidFee, fmt.Sprintf("feeBps must be 1-%d basis points", services.MaxIntegratorFeeBps))
+		}
+		if feeToStr == "" {
+			return nil, apperror.New(apperror.InvalidFee, "feeRecipient is required with feeBps")
+		}
+		addr, err := parseAddress(ctx, h.nameResolver, feeToStr)
+		if err != nil {
+			return nil, apperror.New(apperror.InvalidFee, "feeRecipient: "+err.Error())
+		}
+		feeBps, feeTo = bps, addr
 	}
 
-	quote, err := h.routerService.GetSmartQuote(r.Context(), tokenIn, tokenOut, amountIn, slippageBps)
+	optimizeFor, err := services.ParseOptimizeFor(query.Get("optimizeFor"))
 	if err != nil {
-		h.writeError(w, http.StatusNotFound, "no_route", err.Error())
-		return
+		return nil, apperror.New(apperror.InvalidOptimize, err.Error())
+	}
+
+	venues, reqErr := h.parseVenues(query.Get("dexes"))
+	if reqErr != nil {
+		return nil, reqErr
+	}
+
+	var block *uint64
+	switch blockStr := query.Get("block"); blockStr {
+	case "":
+	case "latest":
+		block = new(uint64)
+	default:
+		number, err := strconv.ParseUint(blockStr, 10, 64)
+		if err != nil || number == 0 {
+			return nil, apperror.New(apperror.InvalidBlock, "block must be latest or a block number")
+		}
+		block = &number
+	}
+
+	var plan *planParams
+	if query.Get("plan") == "true" {
+		if plan, reqErr = parsePlanParams(query); reqErr != nil {
+			return nil, reqErr
+		}
+	}
+
+	permit, reqErr := parsePermit(query)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	if permit != nil && recipient == nil {
+		return nil, apperror.New(apperror.InvalidRecipient, "permitSignature needs a sender, who signed it")
 	}
 
-	response := h.buildQuoteResponse(quote)
-	h.writeJSON(w, http.StatusOK, response)
+	return &quoteParams{
+		tokenIn:     tokenIn,
+		tokenOut:    tokenOut,
+		amountIn:    amountIn,
+		amounts:     amounts,
+		slippageBps: slippageBps,
+		autoSlip:    autoSlip,
+		strategy:    query.Get("strategy"),
+		optimizeFor: optimizeFor,
+		venues:      venues,
+		block:       block,
+		deadline:    deadline,
+		minLiqUSD:   minLiqUSD,
+		verified:    verified,
+		sender:      sender,
+		recipient:   recipient,
+		routeProc:   routeProc,
+		feeBps:      feeBps,
+		feeTo:       feeTo,
+		verbose:     query.Get("verbose") == "true",
+		fallback:    query.Get("fallback") == "true",
+		audit:       query.Get("audit") == "true",
+		debug:       query.Get("debug") == "true",
+		nativeIn:    nativeIn,
+		nativeOut:   nativeOut,
+		plan:        plan,
+		permit:      permit,
+	}, nil
+}
+
+// parsePermit reads the Permit2 permit a sender signed from an earlier
+// quote's walletRequests: permitSignature, with the permitNonce and
+// permitDeadline it was signed for. The rest of the permit is the quote's.
+func parsePermit(query url.Values) (*entities.Permit2Permit, *apperror.Error) {
+	sigStr := query.Get("permitSignature")
+	if sigStr == "" {
+		return nil, nil
+	}
+	sig, err := hexutil.Decode(sigStr)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return nil, apperror.New(apperror.InvalidPermit, fmt.Sprintf("permitSignature must be %d hex bytes", crypto.SignatureLength))
+	}
+	nonce, err := strconv.ParseUint(query.Get("permitNonce"), 10, 48)
+	if err != nil {
+		return nil, apperror.New(apperror.InvalidPermit, "permitNonce must be the nonce the permit was signed with")
+	}
+	deadline, err := strconv.ParseUint(query.Get("permitDeadline"), 10, 48)
+	if err != nil || deadline == 0 {
+		return nil, apperror.New(apperror.InvalidPermit, "permitDeadline must be the Unix time the permit was signed to expire")
+	}
+	return &entities.Permit2Permit{
+		Expiration:  deadline,
+		Nonce:       nonce,
+		SigDeadline: deadline,
+		Signature:   sig,
+	}, nil
 }
 
-// buildQuoteResponse converts a Quote to a QuoteResponse
-func (h *QuoteHandler) buildQuoteResponse(quote *entities.Quote) QuoteResponse {
-	var routeHops []RouteHop
-	if quote.BestRoute != nil {
-		for _, hop := range quote.BestRoute.Hops {
-			routeHops = append(routeHops, RouteHop{
-				DEX:      string(hop.Pair.DEX),
-				Pair:     hop.Pair.Address.Hex(),
-				TokenIn:  hop.TokenIn.Hex(),
-				TokenOut: hop.TokenOut.Hex(),
-				Fee:      hop.Pair.Fee,
-			})
+// parseVenues reads dexes=, a comma-separated list of the venues a quote
+// may route through
+func (h *QuoteHandler) parseVenues(value string) ([]entities.DEXType, *apperror.Error) {
+	if value == "" {
+		return nil, nil
+	}
+	var known []entities.DEXType
+	if h.routerService != nil {
+		known = h.routerService.Venues()
+	}
+	var venues []entities.DEXType
+	for _, name := range strings.Split(value, ",") {
+		venue := entities.DEXType(strings.TrimSpace(name))
+		if venue == "" {
+			continue
+		}
+		if known != nil && !slices.Contains(known, venue) {
+			return nil, apperror.New(apperror.InvalidDEX, fmt.Sprintf("dexes: %q is not a venue here (available: %s)", venue, joinVenues(known)))
+		}
+		if !slices.Contains(venues, venue) {
+			venues = append(venues, venue)
 		}
 	}
+	if len(venues) == 0 {
+		return nil, apperror.New(apperror.InvalidDEX, "dexes must name at least one venue")
+	}
+	return venues, nil
+}
+
+func joinVenues(venues []entities.DEXType) string {
+	names := make([]string, len(venues))
+	for i, venue := range venues {
+		names[i] = string(venue)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseQuoteAmount parses the positive amount of token given as param
+func parseQuoteAmount(param, value string, token entities.Token) (*big.Int, *apperror.Error) {
+	amount, err := parseAmount(value, token)
+	if err != nil {
+		code := apperror.InvalidAmount
+		if errors.Is(err, errAmountOverflow) {
+			code = apperror.AmountTooLarge
+		}
+		return nil, apperror.New(code, param+": "+err.Error())
+	}
+	if amount.Sign() <= 0 {
+		return nil, apperror.New(apperror.InvalidAmount, param+" must be positive")
+	}
+	return amount, nil
+}
+
+// resolveToken accepts an address, a registered symbol (case-insensitive)
+// or an ENS name for the token query parameter param
+func (h *QuoteHandler) resolveToken(ctx context.Context, param, value string) (entities.Token, *apperror.Error) {
+	code := apperror.InvalidToken
+	switch param {
+	case "tokenIn":
+		code = apperror.InvalidTokenIn
+	case "tokenOut":
+		code = apperror.InvalidTokenOut
+	}
+
+	// Symbols such as USDC.e contain a dot, so names are tried after them
+	if !common.IsHexAddress(value) {
+		token, err := h.tokenRegistry.LookupSymbol(value)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, entities.ErrAmbiguousSymbol):
+			return entities.Token{}, apperror.New(apperror.AmbiguousToken, fmt.Sprintf("%s: symbol %q matches several tokens, use the token address", param, value))
+		case !strings.Contains(value, "."):
+			return entities.Token{}, apperror.New(apperror.UnsupportedToken, fmt.Sprintf("%s: %q is not an address or known token symbol", param, value))
+		}
+	}
+
+	addr, err := parseAddress(ctx, h.nameResolver, value)
+	if err != nil {
+		return entities.Token{}, apperror.New(code, param+": "+err.Error())
+	}
+	return h.tokenRegistry.Resolve(ctx, addr), nil
+}
+
+// quote screens the tokens and runs the router for validated parameters
+func (h *QuoteHandler) quote(ctx context.Context, params *quoteParams) (*entities.Quote, *apperror.Error) {
+	start := time.Now()
+
+	if reqErr := h.checkBlocked(params); reqErr != nil {
+		return nil, reqErr
+	}
+	if params.minLiqUSD != nil {
+		ctx = services.WithMinPoolLiquidity(ctx, params.minLiqUSD)
+	}
+	ctx = services.WithOptimizeFor(ctx, params.optimizeFor)
+	if params.venues != nil {
+		ctx = services.WithVenues(ctx, params.venues)
+	}
+	if params.verified {
+		ctx = services.WithVerifiedPools(ctx)
+	}
+	if params.block != nil {
+		var err error
+		if ctx, err = h.routerService.PinBlock(ctx, *params.block); err != nil {
+			if errors.Is(err, services.ErrBlockAhead) {
+				return nil, apperror.Wrap(apperror.InvalidBlock, err)
+			}
+			return nil, apperror.Wrap(apperror.RPCUnavailable, err)
+		}
+	}
+
+	quote, err := h.routerService.GetStrategyQuote(ctx, params.strategy, params.tokenIn, params.tokenOut, params.amountIn, params.slippageBps)
+	if err != nil && params.fallback && isNoRoute(err) {
+		quote, err = h.routerService.FallbackQuote(ctx, params.tokenIn, params.tokenOut, params.amountIn, params.slippageBps, err)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownStrategy) {
+			return nil, apperror.Wrap(apperror.InvalidStrategy, err)
+		}
+		return nil, apperror.As(err, apperror.NoRoute)
+	}
+	prepareStart := time.Now()
+	if params.autoSlip {
+		h.slippageTuner.Tune(ctx, quote)
+	}
+	h.prepareQuote(ctx, params, quote)
+	services.QuoteTimingFrom(ctx).Add(services.StagePrepare, time.Since(prepareStart))
+	if params.plan != nil {
+		h.attachPlan(ctx, params, quote)
+	}
+
+	if h.quoteBook != nil {
+		if err := h.quoteBook.Put(quote); err != nil {
+			return nil, apperror.Wrap(apperror.Internal, err)
+		}
+	}
+
+	if h.recorder != nil {
+		h.recorder.Record(quote, time.Since(start))
+	}
+	if h.shadow != nil {
+		h.shadow.Observe(quote)
+	}
+
+	if key := apiKeyFromContext(ctx); key != nil && h.apiKeyService != nil {
+		// Usage accounting must not delay or fail the quote
+		go func(id string) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			h.apiKeyService.RecordQuote(ctx, id, quote)
+		}(key.ID)
+	}
+
+	return quote, nil
+}
+
+// checkBlocked rejects pairs with a blocklisted token
+func (h *QuoteHandler) checkBlocked(params *quoteParams) *apperror.Error {
+	if h.screeningService == nil {
+		return nil
+	}
+	if err := h.screeningService.CheckBlocked(params.tokenIn, params.tokenOut); err != nil {
+		var blocked *services.ErrTokenBlocked
+		if errors.As(err, &blocked) {
+			return apperror.Wrap(apperror.TokenBlocked, err)
+		}
+	}
+	return nil
+}
+
+// prepareQuote applies the request's options to a routed quote and adds
+// its warnings, transaction, gas cost and USD values
+func (h *QuoteHandler) prepareQuote(ctx context.Context, params *quoteParams, quote *entities.Quote) {
+	quote.NativeIn, quote.NativeOut = params.nativeIn, params.nativeOut
+	if params.deadline > 0 {
+		services.ApplyDeadline(quote, params.deadline)
+	}
+	if params.feeBps > 0 {
+		services.ApplyIntegratorFee(quote, params.feeBps, params.feeTo)
+	}
+
+	if h.screeningService != nil {
+		quote.TokenWarnings = h.screeningService.Screen(ctx, params.tokenIn, params.tokenOut)
+	}
+
+	if h.swapService != nil && params.recipient != nil {
+		// Routes the builder can't encode are still quoted, just without a
+		// transaction, and routes the Universal Router can't take come with
+		// Permit2's approval instead of the permit
+		if params.permit == nil || h.swapService.AttachPermittedTransaction(ctx, quote, *params.sender, *params.recipient, params.permit) != nil {
+			_ = h.swapService.AttachTransaction(ctx, quote, *params.sender, *params.recipient)
+		}
+		if params.routeProc {
+			// Likewise for routes through venues the processor can't swap on
+			_ = h.swapService.AttachRouteProcessor(quote, *params.sender, *params.recipient)
+		}
+	}
+
+	if h.feeService != nil {
+		// Fee suggestions are best-effort; the quote is valid without them
+		_ = h.feeService.AttachGasCost(ctx, quote)
+	}
+
+	if h.priceService != nil {
+		h.priceService.AttachUSDValues(ctx, quote)
+	}
+}
+
+// buildQuoteResponse converts a Quote to a QuoteResponse. Verbose responses
+// include per-venue details, including venues that failed.
+func (h *QuoteHandler) buildQuoteResponse(quote *entities.Quote, verbose bool) QuoteResponse {
+	routeHops := newRouteHops(quote.BestRoute)
 
 	sources := make(map[string]string)
 	for dex, amount := range quote.Sources {
 		sources[string(dex)] = amount
 	}
 
-	priceImpactBps := "0"
-	if quote.PriceImpact != nil {
-		priceImpactBps = quote.PriceImpact.String()
+	convertedVia := ""
+	if quote.ConvertedVia != nil {
+		convertedVia = quote.ConvertedVia.Address.Hex()
 	}
 
 	minAmountOut := ""
@@ -177,23 +1001,297 @@ func (h *QuoteHandler) buildQuoteResponse(quote *entities.Quote) QuoteResponse {
 			Percentage: sr.Percentage,
 			AmountIn:   sr.AmountIn.String(),
 			AmountOut:  sr.AmountOut.String(),
+			Route:      newRouteHops(sr.Route),
 		})
 	}
 
+	var tokenWarnings []TokenWarningResp
+	for _, tw := range quote.TokenWarnings {
+		tokenWarnings = append(tokenWarnings, TokenWarningResp{
+			Token:   tw.Token.Hex(),
+			Code:    tw.Code,
+			Message: tw.Message,
+		})
+	}
+
+	var sourceDetails []SourceDetailResp
+	if verbose {
+		for _, sd := range quote.SourceDetails {
+			detail := SourceDetailResp{
+				DEX:            string(sd.DEX),
+				GasEstimate:    sd.GasEstimate,
+				LiquidityScore: sd.LiquidityScore,
+				LatencyMs:      sd.LatencyMs,
+				Error:          sd.Error,
+			}
+			if sd.AmountOut != nil {
+				detail.AmountOut = sd.AmountOut.String()
+			}
+			sourceDetails = append(sourceDetails, detail)
+		}
+	}
+
+	var transaction *TransactionResp
+	if quote.Transaction != nil {
+		tx := newTransactionResp(quote.Transaction)
+		transaction = &tx
+	}
+
+	var routeProcessor *RouteProcessorResp
+	if rp := quote.RouteProcessor; rp != nil {
+		routeProcessor = &RouteProcessorResp{
+			Address:     rp.Address.Hex(),
+			Route:       hexutil.Encode(rp.Route),
+			Transaction: newTransactionResp(rp.Transaction),
+		}
+	}
+
+	var approval *ApprovalResp
+	if plan := quote.Approval; plan != nil {
+		approval = &ApprovalResp{
+			Token:                plan.Token.Hex(),
+			Spender:              plan.Spender.Hex(),
+			Allowance:            plan.Allowance.String(),
+			Amount:               plan.Amount.String(),
+			ApproveResetRequired: plan.Quirks.ApproveResetRequired,
+			NoReturnValue:        plan.Quirks.NoReturnValue,
+			Blacklistable:        plan.Quirks.Blacklistable,
+			Frozen:               plan.Frozen,
+			Steps:                make([]TransactionResp, 0, len(plan.Steps)),
+		}
+		if plan.Permit2Spender != nil {
+			approval.Permit2Spender = plan.Permit2Spender.Hex()
+		}
+		if permit := plan.Permit; permit != nil {
+			approval.Permit = &PermitResp{
+				Token:       permit.Token.Hex(),
+				Amount:      permit.Amount.String(),
+				Expiration:  permit.Expiration,
+				Nonce:       permit.Nonce,
+				Spender:     permit.Spender.Hex(),
+				SigDeadline: permit.SigDeadline,
+				Signed:      permit.Signature != nil,
+			}
+		}
+		for _, step := range plan.Steps {
+			approval.Steps = append(approval.Steps, newTransactionResp(step))
+		}
+	}
+
+	var rfqOrder *RFQOrderResp
+	if order := quote.RFQOrder; order != nil {
+		rfqOrder = &RFQOrderResp{
+			MakerName: order.MakerName,
+			Maker:     order.Maker.Hex(),
+			Taker:     order.Taker.Hex(),
+			TokenIn:   order.TokenIn.Hex(),
+			TokenOut:  order.TokenOut.Hex(),
+			AmountIn:  order.AmountIn.String(),
+			AmountOut: order.AmountOut.String(),
+			Expiry:    order.Expiry,
+			Nonce:     order.Nonce.String(),
+			Signature: hexutil.Encode(order.Signature),
+		}
+	}
+
+	var gasCost *GasCostResp
+	if quote.GasCost != nil {
+		gasCost = &GasCostResp{
+			BaseFeePerGas:        quote.GasCost.BaseFeePerGas.String(),
+			MaxFeePerGas:         quote.GasCost.MaxFeePerGas.String(),
+			MaxPriorityFeePerGas: quote.GasCost.MaxPriorityFeePerGas.String(),
+			CostWei:              quote.GasCost.CostWei.String(),
+			CostEth:              formatUnits(quote.GasCost.CostWei, 18),
+		}
+		if quote.GasCost.CostUSD != nil {
+			gasCost.CostUSD = usdFormat.format(quote.GasCost.CostUSD, priceDecimals)
+		}
+	}
+
+	var slippageDefault *SlippageDefaultResp
+	if quote.SlippageDefault != nil {
+		slippageDefault = &SlippageDefaultResp{
+			Class:  string(quote.SlippageDefault.Class),
+			Bps:    quote.SlippageDefault.Bps,
+			Reason: quote.SlippageDefault.Reason,
+		}
+	}
+
+	var slippageAuto *SlippageAutoResp
+	if quote.SlippageAuto != nil {
+		slippageAuto = &SlippageAutoResp{
+			Bps:             quote.SlippageAuto.Bps,
+			Samples:         quote.SlippageAuto.Samples,
+			FillProbability: quote.SlippageAuto.FillProbability,
+			VolatilityBps:   quote.SlippageAuto.VolatilityBps,
+			Reason:          quote.SlippageAuto.Reason,
+		}
+	}
+
+	var savings *SavingsResp
+	if quote.Savings != nil {
+		savings = &SavingsResp{
+			VsWorst:     quote.Savings.VsWorstBps,
+			VsMedian:    quote.Savings.VsMedianBps,
+			VsBestVenue: quote.Savings.VsBestVenueBps,
+		}
+	}
+
+	var integratorFee *IntegratorFeeResp
+	if fee := quote.IntegratorFee; fee != nil {
+		integratorFee = &IntegratorFeeResp{
+			Bps:       fee.Bps,
+			Recipient: fee.Recipient.Hex(),
+			Amount:    fee.Amount.String(),
+		}
+	}
+
+	blockNumber, blockAge := blockLag(h.chainHead, time.Now())
+	tokenIn, tokenOut := h.quoteTokens(quote)
 	return QuoteResponse{
-		TokenIn:      quote.TokenIn.Address.Hex(),
-		TokenOut:     quote.TokenOut.Address.Hex(),
-		AmountIn:     quote.AmountIn.String(),
-		AmountOut:    quote.AmountOut.String(),
-		MinAmountOut: minAmountOut,
-		SlippageBps:  quote.SlippageBps,
-		Route:        routeHops,
-		SplitRoutes:  splitRoutes,
-		PriceImpact:  priceImpactBps,
-		PriceWarning: quote.PriceWarning,
-		GasEstimate:  quote.GasEstimate,
-		Sources:      sources,
+		TokenIn:         tokenIn.Address.Hex(),
+		TokenOut:        tokenOut.Address.Hex(),
+		AmountIn:        quote.AmountIn.String(),
+		AmountOut:       quote.AmountOut.String(),
+		AmountInUSD:     optUSD(quote.AmountInUSD),
+		AmountOutUSD:    optUSD(quote.AmountOutUSD),
+		MinAmountOut:    minAmountOut,
+		SlippageBps:     quote.SlippageBps,
+		SlippageDefault: slippageDefault,
+		SlippageAuto:    slippageAuto,
+		SavingsBps:      savings,
+		TradeSize:       newTradeSizeResp(quote.TradeSize),
+		QuoteID:         quote.ID,
+		ExpiresAt:       quote.ExpiresAt.Unix(),
+		IntegratorFee:   integratorFee,
+		Route:           routeHops,
+		SplitRoutes:     splitRoutes,
+		PriceImpact:     optBps(quote.PriceImpact),
+		PriceImpactUSD:  optUSD(quote.PriceImpactUSD),
+		PriceWarning:    quote.PriceWarning,
+		TokenWarnings:   tokenWarnings,
+		GasEstimate:     quote.GasEstimate,
+		QuotedAtBlock:   quote.QuotedAtBlock,
+		PinnedBlock:     quote.PinnedBlock,
+		Strategy:        quote.Strategy,
+		OptimizeFor:     quote.OptimizeFor,
+		Venues:          quote.Venues,
+		VerifiedPools:   quote.VerifiedPools,
+		ConvertedVia:    convertedVia,
+		Fallback:        newRouteDiagnosticResp(quote.Fallback),
+		GasSource:       quote.GasSource,
+		GasCost:         gasCost,
+		Transaction:     transaction,
+		Approval:        approval,
+		RFQOrder:        rfqOrder,
+		RouteProcessor:  routeProcessor,
+		WalletRequests:  newWalletRequests(quote, h.tokenRegistry.ChainID()),
+		BlockNumber:     blockNumber,
+		BlockAgeSeconds: blockAge,
+		Sources:         sources,
+		SourceDetails:   sourceDetails,
+		ExecutionPlan:   newExecutionPlanResp(quote.ExecutionPlan),
+	}
+}
+
+// quoteTokens returns the tokens a quote trades as the caller named them,
+// the gas token rather than its wrapper on native legs
+func (h *QuoteHandler) quoteTokens(quote *entities.Quote) (tokenIn, tokenOut entities.Token) {
+	tokenIn, tokenOut = quote.TokenIn, quote.TokenOut
+	if native, ok := h.tokenRegistry.Native(); ok {
+		if quote.NativeIn {
+			tokenIn = native.Token()
+		}
+		if quote.NativeOut {
+			tokenOut = native.Token()
+		}
+	}
+	return tokenIn, tokenOut
+}
+
+// optUSD formats an 18-decimal USD value, or "" when it is unknown
+func optUSD(value *big.Int) string {
+	if value == nil {
+		return ""
+	}
+	return usdFormat.format(value, priceDecimals)
+}
+
+func newTransactionResp(tx *entities.SwapTransaction) TransactionResp {
+	return TransactionResp{
+		From:  tx.From.Hex(),
+		To:    tx.To.Hex(),
+		Data:  hexutil.Encode(tx.Data),
+		Value: tx.Value.String(),
+		Gas:   tx.Gas,
+	}
+}
+
+// newWalletRequests returns the quote's approvals and transaction as
+// eth_sendTransaction requests on chainID, in the order they are sent.
+// When Permit2 must allow the router and no permit was signed, the
+// approvals end with an eth_signTypedData_v4 request for the permit in
+// place of Permit2's approval, and the swap is left to a quote requested
+// with the signature.
+func newWalletRequests(quote *entities.Quote, chainID uint64) []WalletRequestResp {
+	if quote.Transaction == nil {
+		return nil
+	}
+	var txs []*entities.SwapTransaction
+	var permit *WalletRequestResp
+	if plan := quote.Approval; plan != nil {
+		txs = append(txs, plan.Steps...)
+		if plan.Permit != nil && plan.Permit.Signature == nil {
+			domain := swap.NewPermit2Domain(new(big.Int).SetUint64(chainID), plan.Spender)
+			if request, err := newSignTypedDataRequest(plan.Owner, swap.PermitTypedData(domain, plan.Permit)); err == nil {
+				// In place of Permit2's approval, the last step
+				txs = txs[:len(txs)-1]
+				permit = &request
+			}
+		}
+	}
+	if permit == nil {
+		txs = append(txs, quote.Transaction)
+	}
+
+	requests := make([]WalletRequestResp, 0, len(txs)+1)
+	for _, tx := range txs {
+		walletTx := WalletTransactionResp{
+			From:    tx.From.Hex(),
+			To:      tx.To.Hex(),
+			Data:    hexutil.Encode(tx.Data),
+			Value:   hexutil.EncodeBig(tx.Value),
+			ChainID: hexutil.EncodeUint64(chainID),
+		}
+		if tx.Gas > 0 {
+			walletTx.Gas = hexutil.EncodeUint64(tx.Gas)
+		}
+		if cost := quote.GasCost; cost != nil {
+			walletTx.MaxFeePerGas = hexutil.EncodeBig(cost.MaxFeePerGas)
+			walletTx.MaxPriorityFeePerGas = hexutil.EncodeBig(cost.MaxPriorityFeePerGas)
+		}
+		requests = append(requests, WalletRequestResp{
+			Method: "eth_sendTransaction",
+			Params: []interface{}{walletTx},
+		})
+	}
+	if permit != nil {
+		requests = append(requests, *permit)
 	}
+	return requests
+}
+
+// newSignTypedDataRequest asks signer's wallet to sign typed, passed as a
+// JSON string as wallets take it
+func newSignTypedDataRequest(signer common.Address, typed eip712.TypedData) (WalletRequestResp, error) {
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return WalletRequestResp{}, err
+	}
+	return WalletRequestResp{
+		Method: "eth_signTypedData_v4",
+		Params: []interface{}{signer.Hex(), string(data)},
+	}, nil
 }
 
 func (h *QuoteHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -201,10 +1299,3 @@ func (h *QuoteHandler) writeJSON(w http.ResponseWriter, status int, data interfa
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
-
-func (h *QuoteHandler) writeError(w http.ResponseWriter, status int, code, message string) {
-	h.writeJSON(w, status, ErrorResponse{
-		Error:   code,
-		Message: message,
-	})
-}
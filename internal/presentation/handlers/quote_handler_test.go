@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/swap"
+	"github.com/bimakw/dex-aggregator/testutil"
+)
+
+func TestParseQuoteValuesNativeToken(t *testing.T) {
+	h := NewQuoteHandler(nil, nil, nil, nil, entities.DefaultRegistry(), nil)
+	native := entities.NativeTokenAddress.Hex()
+
+	tests := []struct {
+		name                string
+		tokenIn, tokenOut   string
+		nativeIn, nativeOut bool
+		wrap                bool // Quoted through the wrapper's 1:1 pool
+		wantErr             apperror.Code
+	}{
+		{"symbol in", "ETH", "USDC", true, false, false, ""},
+		{"sentinel out", "USDC", native, false, true, false, ""},
+		{"wrapped stays wrapped", "WETH", "USDC", false, false, false, ""},
+		{"wrap", "eth", "WETH", false, false, true, ""},
+		{"unwrap", "WETH", native, false, false, true, ""},
+		{"gas token for itself", "ETH", native, false, false, false, apperror.InvalidTokenOut},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, reqErr := h.parseQuoteValues(context.Background(), url.Values{
+				"tokenIn":  {tt.tokenIn},
+				"tokenOut": {tt.tokenOut},
+				"amountIn": {"1"},
+			})
+			if tt.wantErr != "" {
+				if reqErr == nil || reqErr.Code != tt.wantErr {
+					t.Fatalf("error = %v, want %s", reqErr, tt.wantErr)
+				}
+				return
+			}
+			if reqErr != nil {
+				t.Fatalf("parseQuoteValues() error = %v", reqErr)
+			}
+			if params.nativeIn != tt.nativeIn || params.nativeOut != tt.nativeOut {
+				t.Errorf("native = %v/%v, want %v/%v", params.nativeIn, params.nativeOut, tt.nativeIn, tt.nativeOut)
+			}
+			if unwrapped := params.tokenIn.IsNative() || params.tokenOut.IsNative(); unwrapped != tt.wrap {
+				t.Errorf("gas token reached the router unwrapped = %v, want %v", unwrapped, tt.wrap)
+			}
+
+			tokenIn, tokenOut := h.quoteTokens(&entities.Quote{
+				TokenIn: params.tokenIn, TokenOut: params.tokenOut,
+				NativeIn: params.nativeIn, NativeOut: params.nativeOut,
+			})
+			wantIn, wantOut := tt.nativeIn || params.tokenIn.IsNative(), tt.nativeOut || params.tokenOut.IsNative()
+			if tokenIn.IsNative() != wantIn || tokenOut.IsNative() != wantOut {
+				t.Errorf("response tokens %s -> %s", tokenIn.Symbol, tokenOut.Symbol)
+			}
+		})
+	}
+}
+
+func TestParseQuoteValuesLadder(t *testing.T) {
+	h := NewQuoteHandler(nil, nil, nil, nil, entities.DefaultRegistry(), nil)
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    []string
+		wantErr apperror.Code
+	}{
+		{"sizes in order", url.Values{"amounts": {"1e18,5e18,25e18"}}, []string{"1000000000000000000", "5000000000000000000", "25000000000000000000"}, ""},
+		{"units", url.Values{"amounts": {"0.5 ether, 2 ether"}}, []string{"500000000000000000", "2000000000000000000"}, ""},
+		{"with amountIn", url.Values{"amountIn": {"1"}, "amounts": {"1,2"}}, nil, apperror.InvalidAmount},
+		{"empty size", url.Values{"amounts": {"1,,2"}}, nil, apperror.InvalidAmount},
+		{"zero size", url.Values{"amounts": {"1,0"}}, nil, apperror.InvalidAmount},
+		{"too many", url.Values{"amounts": {"1,2,3,4,5,6,7,8,9,10,11"}}, nil, apperror.InvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Set("tokenIn", "WETH")
+			tt.query.Set("tokenOut", "USDC")
+			params, reqErr := h.parseQuoteValues(context.Background(), tt.query)
+			if tt.wantErr != "" {
+				if reqErr == nil || reqErr.Code != tt.wantErr {
+					t.Fatalf("error = %v, want %s", reqErr, tt.wantErr)
+				}
+				return
+			}
+			if reqErr != nil {
+				t.Fatalf("parseQuoteValues() error = %v", reqErr)
+			}
+			if len(params.amounts) != len(tt.want) {
+				t.Fatalf("amounts = %v, want %v", params.amounts, tt.want)
+			}
+			for i, want := range tt.want {
+				if params.amounts[i].String() != want {
+					t.Errorf("amounts[%d] = %s, want %s", i, params.amounts[i], want)
+				}
+			}
+			if params.amountIn != params.amounts[0] {
+				t.Error("amountIn is not the first size")
+			}
+		})
+	}
+}
+
+func TestParseQuoteValuesOptimizeFor(t *testing.T) {
+	h := NewQuoteHandler(nil, nil, nil, nil, entities.DefaultRegistry(), nil)
+
+	tests := []struct {
+		value   string
+		want    services.OptimizeFor
+		wantErr bool
+	}{
+		{"", services.OptimizeOutput, false},
+		{"netOutput", services.OptimizeNetOutput, false},
+		{"gas", services.OptimizeGas, false},
+		{"price", "", true},
+	}
+	for _, tt := range tests {
+		query := url.Values{"tokenIn": {"WETH"}, "tokenOut": {"USDC"}, "amountIn": {"1"}, "optimizeFor": {tt.value}}
+		params, reqErr := h.parseQuoteValues(context.Background(), query)
+		if tt.wantErr {
+			if reqErr == nil || reqErr.Code != apperror.InvalidOptimize {
+				t.Errorf("optimizeFor=%s error = %v, want %s", tt.value, reqErr, apperror.InvalidOptimize)
+			}
+			continue
+		}
+		if reqErr != nil {
+			t.Fatalf("optimizeFor=%s error = %v", tt.value, reqErr)
+		}
+		if params.optimizeFor != tt.want {
+			t.Errorf("optimizeFor=%s parsed as %q, want %q", tt.value, params.optimizeFor, tt.want)
+		}
+	}
+}
+
+func TestParseQuoteValuesBlock(t *testing.T) {
+	h := NewQuoteHandler(nil, nil, nil, nil, entities.DefaultRegistry(), nil)
+
+	tests := []struct {
+		value   string
+		want    *uint64
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"latest", new(uint64), false},
+		{"21000000", func() *uint64 { n := uint64(21000000); return &n }(), false},
+		{"0", nil, true},
+		{"pending", nil, true},
+	}
+	for _, tt := range tests {
+		query := url.Values{"tokenIn": {"WETH"}, "tokenOut": {"USDC"}, "amountIn": {"1"}, "block": {tt.value}}
+		params, reqErr := h.parseQuoteValues(context.Background(), query)
+		if tt.wantErr {
+			if reqErr == nil || reqErr.Code != apperror.InvalidBlock {
+				t.Errorf("block=%s error = %v, want %s", tt.value, reqErr, apperror.InvalidBlock)
+			}
+			continue
+		}
+		if reqErr != nil {
+			t.Fatalf("block=%s error = %v", tt.value, reqErr)
+		}
+		if (params.block == nil) != (tt.want == nil) || (params.block != nil && *params.block != *tt.want) {
+			t.Errorf("block=%s parsed as %v, want %v", tt.value, params.block, tt.want)
+		}
+	}
+}
+
+func TestNewWalletRequests(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	token := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	router := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	quote := &entities.Quote{
+		Transaction: &entities.SwapTransaction{From: sender, To: router, Data: []byte{0x38, 0xed}, Value: big.NewInt(1e18), Gas: 150000},
+		Approval: &entities.ApprovalPlan{
+			Steps: []*entities.SwapTransaction{{From: sender, To: token, Data: []byte{0x09, 0x5e}, Value: big.NewInt(0)}},
+		},
+		GasCost: &entities.GasCost{MaxFeePerGas: big.NewInt(30e9), MaxPriorityFeePerGas: big.NewInt(1e9)},
+	}
+
+	requests := newWalletRequests(quote, 1)
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want the approval then the swap", len(requests))
+	}
+	for _, req := range requests {
+		if req.Method != "eth_sendTransaction" || len(req.Params) != 1 {
+			t.Fatalf("request = %+v, want one eth_sendTransaction param", req)
+		}
+	}
+
+	approve := requests[0].Params[0].(WalletTransactionResp)
+	if approve.To != token.Hex() || approve.Value != "0x0" || approve.Gas != "" {
+		t.Errorf("approval = %+v, want a zero-value call to the token without gas", approve)
+	}
+	swap := requests[1].Params[0].(WalletTransactionResp)
+	want := WalletTransactionResp{
+		From:                 sender.Hex(),
+		To:                   router.Hex(),
+		Data:                 "0x38ed",
+		Value:                "0xde0b6b3a7640000",
+		Gas:                  "0x249f0",
+		MaxFeePerGas:         "0x6fc23ac00",
+		MaxPriorityFeePerGas: "0x3b9aca00",
+		ChainID:              "0x1",
+	}
+	if swap != want {
+		t.Errorf("swap = %+v, want %+v", swap, want)
+	}
+
+	if got := newWalletRequests(&entities.Quote{}, 1); got != nil {
+		t.Errorf("quote without a transaction got %d requests", len(got))
+	}
+}
+
+func TestNewWalletRequestsPermit(t *testing.T) {
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	token := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	permit := &entities.Permit2Permit{
+		Token: token, Amount: big.NewInt(1e18), Expiration: 1700000000, Nonce: 7,
+		Spender: swap.UniversalRouterAddress, SigDeadline: 1700000000,
+	}
+	newQuote := func() *entities.Quote {
+		return &entities.Quote{
+			Transaction: &entities.SwapTransaction{From: sender, To: swap.UniversalRouterAddress, Data: []byte{0x35, 0x93}, Value: big.NewInt(0)},
+			Approval: &entities.ApprovalPlan{
+				Owner:   sender,
+				Spender: swap.Permit2Address,
+				Steps: []*entities.SwapTransaction{
+					{From: sender, To: token, Data: []byte{0x09, 0x5e}, Value: big.NewInt(0)},
+					{From: sender, To: swap.Permit2Address, Data: []byte{0x87, 0x51}, Value: big.NewInt(0)},
+				},
+				Permit: permit,
+			},
+		}
+	}
+
+	// Unsigned, Permit2's approval gives way to the permit, and the swap
+	// waits for its signature
+	requests := newWalletRequests(newQuote(), 1)
+	if len(requests) != 2 || requests[0].Method != "eth_sendTransaction" || requests[1].Method != "eth_signTypedData_v4" {
+		t.Fatalf("requests = %+v, want the token's approval, then the permit to sign", requests)
+	}
+	if approve := requests[0].Params[0].(WalletTransactionResp); approve.To != token.Hex() {
+		t.Errorf("first request to %s, want the token's approval of Permit2", approve.To)
+	}
+	params := requests[1].Params
+	if len(params) != 2 || params[0] != sender.Hex() {
+		t.Fatalf("params = %v, want the signer, then the typed data", params)
+	}
+	var typed apitypes.TypedData
+	if err := json.Unmarshal([]byte(params[1].(string)), &typed); err != nil {
+		t.Fatalf("typed data %v: %v", params[1], err)
+	}
+	if typed.PrimaryType != "PermitSingle" || typed.Domain.Name != "Permit2" || typed.Domain.VerifyingContract != swap.Permit2Address.Hex() || (*big.Int)(typed.Domain.ChainId).Int64() != 1 {
+		t.Errorf("typed data = %s in %+v, want a PermitSingle for Permit2 on chain 1", typed.PrimaryType, typed.Domain)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := swap.PermitHash(swap.NewPermit2Domain(big.NewInt(1), swap.Permit2Address), permit); common.BytesToHash(hash) != want {
+		t.Errorf("typed data hashes to %x, want the permit's %s", hash, want.Hex())
+	}
+
+	// Signed, the permit rides in the swap, so the swap is sent
+	quote := newQuote()
+	quote.Approval.Steps = quote.Approval.Steps[:1]
+	quote.Approval.Permit = &entities.Permit2Permit{Signature: make([]byte, 65)}
+	requests = newWalletRequests(quote, 1)
+	if len(requests) != 2 || requests[1].Method != "eth_sendTransaction" || requests[1].Params[0].(WalletTransactionResp).To != swap.UniversalRouterAddress.Hex() {
+		t.Errorf("requests = %+v, want the token's approval, then the swap", requests)
+	}
+}
+
+func TestParsePermit(t *testing.T) {
+	sig := "0x" + strings.Repeat("ab", 65)
+	tests := []struct {
+		name  string
+		query string
+		want  *entities.Permit2Permit
+		err   bool
+	}{
+		{"none", "", nil, false},
+		{"signed", "permitSignature=" + sig + "&permitNonce=7&permitDeadline=1700000000", &entities.Permit2Permit{Expiration: 1700000000, Nonce: 7, SigDeadline: 1700000000}, false},
+		{"short signature", "permitSignature=0xabcd&permitNonce=7&permitDeadline=1700000000", nil, true},
+		{"no nonce", "permitSignature=" + sig + "&permitDeadline=1700000000", nil, true},
+		{"no deadline", "permitSignature=" + sig + "&permitNonce=7", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			got, reqErr := parsePermit(query)
+			if (reqErr != nil) != tt.err {
+				t.Fatalf("parsePermit() error = %v, want error %v", reqErr, tt.err)
+			}
+			if reqErr != nil && reqErr.Code != apperror.InvalidPermit {
+				t.Errorf("code = %s, want %s", reqErr.Code, apperror.InvalidPermit)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("permit = %+v, want none", got)
+				}
+				return
+			}
+			if got == nil || got.Nonce != tt.want.Nonce || got.Expiration != tt.want.Expiration || got.SigDeadline != tt.want.SigDeadline || len(got.Signature) != 65 {
+				t.Errorf("permit = %+v, want %+v with the signature", got, tt.want)
+			}
+		})
+	}
+}
+
+// slowDEX answers after a delay, so its latency shows in the source details
+type slowDEX struct {
+	*testutil.FakeDEX
+	delay time.Duration
+}
+
+func (d *slowDEX) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	time.Sleep(d.delay)
+	return d.FakeDEX.GetPairByTokens(ctx, tokenA, tokenB)
+}
+
+func TestGetQuoteSourceDetails(t *testing.T) {
+	pair := func(dexType entities.DEXType, addr string) *entities.Pair {
+		return &entities.Pair{
+			Address:  common.HexToAddress(addr),
+			Token0:   entities.USDC,
+			Token1:   entities.WETH,
+			Reserve0: big.NewInt(30_000_000e6),
+			Reserve1: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)),
+			DEX:      dexType,
+			Fee:      30,
+		}
+	}
+	uniswap := &slowDEX{FakeDEX: testutil.NewFakeDEX(entities.DEXUniswapV2), delay: 20 * time.Millisecond}
+	uniswap.SetPair(pair(entities.DEXUniswapV2, "0x1111"))
+	sushiswap := testutil.NewFakeDEX(entities.DEXSushiswap)
+	sushiswap.SetError(errors.New("node unreachable"))
+
+	clock := testutil.NewFakeClock(time.Now())
+	router := services.NewRouterService(services.NewPriceService([]dex.DEXClient{uniswap, sushiswap}, testutil.NewFakeCache(clock.Now)))
+	h := NewQuoteHandler(router, nil, nil, nil, entities.DefaultRegistry(), nil)
+
+	tests := []struct {
+		name    string
+		verbose string
+		want    int
+	}{
+		{"verbose", "true", 2},
+		{"default", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetQuote(w, httptest.NewRequest("GET", "/api/v1/quote?tokenIn=WETH&tokenOut=USDC&amountIn=1e18&verbose="+tt.verbose, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var resp QuoteResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.SourceDetails) != tt.want {
+				t.Fatalf("got %d source details, want %d", len(resp.SourceDetails), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+
+			// Sorted by venue: sushiswap failed, uniswap_v2 quoted
+			failed, quoted := resp.SourceDetails[0], resp.SourceDetails[1]
+			if failed.DEX != string(entities.DEXSushiswap) || !strings.Contains(failed.Error, "node unreachable") || failed.AmountOut != "" {
+				t.Errorf("failed source = %+v, want sushiswap's error without an amount", failed)
+			}
+			if quoted.DEX != string(entities.DEXUniswapV2) || quoted.Error != "" || quoted.AmountOut == "" || quoted.GasEstimate == 0 {
+				t.Errorf("quoted source = %+v, want uniswap_v2's amount and gas", quoted)
+			}
+			if quoted.LatencyMs < 20 {
+				t.Errorf("uniswap_v2 latency = %dms, want at least its 20ms delay", quoted.LatencyMs)
+			}
+		})
+	}
+}
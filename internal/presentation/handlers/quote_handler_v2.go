@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type TokenResp struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+type QuoteResponseV2 struct {
+	TokenIn         TokenResp            `json:"tokenIn"`
+	TokenOut        TokenResp            `json:"tokenOut"`
+	AmountIn        Amount               `json:"amountIn"`
+	AmountOut       Amount               `json:"amountOut"`
+	AmountInUSD     string               `json:"amountInUsd,omitempty"`
+	AmountOutUSD    string               `json:"amountOutUsd,omitempty"`
+	MinAmountOut    *Amount              `json:"minAmountOut,omitempty"`
+	SlippageBps     uint64               `json:"slippageBps,omitempty"`
+	SlippageDefault *SlippageDefaultResp `json:"slippageDefault,omitempty"`
+	SlippageAuto    *SlippageAutoResp    `json:"slippageAuto,omitempty"`
+	Audit           *entities.QuoteAudit `json:"audit,omitempty"`
+	SavingsBps      *SavingsResp         `json:"savingsBps,omitempty"`
+	TradeSize       *TradeSizeResp       `json:"tradeSize,omitempty"`
+	QuoteID         string               `json:"quoteId,omitempty"`
+	ExpiresAt       int64                `json:"expiresAt"`
+	IntegratorFee   *IntegratorFeeResp   `json:"integratorFee,omitempty"`
+	Route           []RouteHop           `json:"route"`
+	SplitRoutes     []SplitRouteV2       `json:"splitRoutes,omitempty"`
+	PriceImpact     string               `json:"priceImpact"`
+	PriceImpactUSD  string               `json:"priceImpactUsd,omitempty"`
+	PriceWarning    string               `json:"priceWarning,omitempty"`
+	TokenWarnings   []TokenWarningResp   `json:"tokenWarnings,omitempty"`
+	GasEstimate     uint64               `json:"gasEstimate"`
+	QuotedAtBlock   uint64               `json:"quotedAtBlock,omitempty"`
+	PinnedBlock     uint64               `json:"pinnedBlock,omitempty"`
+	Strategy        string               `json:"strategy,omitempty"`
+	OptimizeFor     string               `json:"optimizeFor,omitempty"`
+	VerifiedPools   bool                 `json:"verifiedPools,omitempty"`
+	Venues          []entities.DEXType   `json:"venues,omitempty"`
+	ConvertedVia    *TokenResp           `json:"convertedVia,omitempty"`
+	Fallback        *RouteDiagnosticResp `json:"fallback,omitempty"`
+	GasSource       string               `json:"gasSource,omitempty"`
+	GasCost         *GasCostResp         `json:"gasCost,omitempty"`
+	Transaction     *TransactionResp     `json:"transaction,omitempty"`
+	Approval        *ApprovalResp        `json:"approval,omitempty"`
+	RFQOrder        *RFQOrderResp        `json:"rfqOrder,omitempty"`
+	RouteProcessor  *RouteProcessorResp  `json:"routeProcessor,omitempty"`
+	WalletRequests  []WalletRequestResp  `json:"walletRequests,omitempty"`
+	BlockNumber     uint64               `json:"blockNumber,omitempty"`
+	BlockAgeSeconds *int64               `json:"blockAgeSeconds,omitempty"`
+	Sources         []SourceDetailResp   `json:"sources"`
+	ExecutionPlan   *ExecutionPlanRespV2 `json:"executionPlan,omitempty"`
+	Timing          *TimingResp          `json:"timing,omitempty"`
+}
+
+type SplitRouteV2 struct {
+	DEX        string `json:"dex"`
+	Percentage uint64 `json:"percentage"`
+	AmountIn   Amount `json:"amountIn"`
+	AmountOut  Amount `json:"amountOut"`
+}
+
+// GetQuoteV2 handles GET /api/v2/quote
+func (h *QuoteHandler) GetQuoteV2(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	params, reqErr := h.parseQuoteParams(r)
+	if reqErr != nil {
+		writeProblem(w, r, reqErr)
+		return
+	}
+	if len(params.amounts) > 0 {
+		h.writeLadderV2(w, r, params, start)
+		return
+	}
+
+	ctx, timing := services.WithQuoteTiming(r.Context())
+	quote, reqErr := h.quote(ctx, params)
+	if reqErr != nil {
+		writeProblem(w, r, reqErr)
+		return
+	}
+
+	h.writeQuote(w, r, params, []*entities.Quote{quote}, timing, start, func(timingResp *TimingResp) interface{} {
+		response := h.buildQuoteResponseV2(quote)
+		if params.audit {
+			response.Audit = services.NewQuoteAudit(quote)
+		}
+		response.Timing = timingResp
+		return response
+	})
+}
+
+// buildQuoteResponseV2 reuses the v1 shape where it is unchanged and
+// converts amounts and sources to their v2 forms
+func (h *QuoteHandler) buildQuoteResponseV2(quote *entities.Quote) QuoteResponseV2 {
+	v1 := h.buildQuoteResponse(quote, true)
+
+	var minAmountOut *Amount
+	if quote.MinAmountOut != nil {
+		amount := newAmount(quote.MinAmountOut, quote.TokenOut.Decimals)
+		minAmountOut = &amount
+	}
+
+	var splitRoutes []SplitRouteV2
+	for i, sr := range quote.SplitRoutes {
+		splitRoutes = append(splitRoutes, SplitRouteV2{
+			DEX:        v1.SplitRoutes[i].DEX,
+			Percentage: sr.Percentage,
+			AmountIn:   newAmount(sr.AmountIn, quote.TokenIn.Decimals),
+			AmountOut:  newAmount(sr.AmountOut, quote.TokenOut.Decimals),
+		})
+	}
+
+	sources := v1.SourceDetails
+	if sources == nil {
+		sources = []SourceDetailResp{}
+	}
+
+	var convertedVia *TokenResp
+	if quote.ConvertedVia != nil {
+		token := newTokenResp(*quote.ConvertedVia)
+		convertedVia = &token
+	}
+
+	tokenIn, tokenOut := h.quoteTokens(quote)
+	return QuoteResponseV2{
+		TokenIn:         newTokenResp(tokenIn),
+		TokenOut:        newTokenResp(tokenOut),
+		AmountIn:        newAmount(quote.AmountIn, quote.TokenIn.Decimals),
+		AmountOut:       newAmount(quote.AmountOut, quote.TokenOut.Decimals),
+		AmountInUSD:     v1.AmountInUSD,
+		AmountOutUSD:    v1.AmountOutUSD,
+		MinAmountOut:    minAmountOut,
+		SlippageBps:     quote.SlippageBps,
+		SlippageDefault: v1.SlippageDefault,
+		SlippageAuto:    v1.SlippageAuto,
+		SavingsBps:      v1.SavingsBps,
+		TradeSize:       v1.TradeSize,
+		QuoteID:         v1.QuoteID,
+		ExpiresAt:       v1.ExpiresAt,
+		IntegratorFee:   v1.IntegratorFee,
+		Route:           v1.Route,
+		SplitRoutes:     splitRoutes,
+		PriceImpact:     v1.PriceImpact,
+		PriceImpactUSD:  v1.PriceImpactUSD,
+		PriceWarning:    v1.PriceWarning,
+		TokenWarnings:   v1.TokenWarnings,
+		GasEstimate:     v1.GasEstimate,
+		QuotedAtBlock:   v1.QuotedAtBlock,
+		PinnedBlock:     v1.PinnedBlock,
+		Strategy:        v1.Strategy,
+		OptimizeFor:     v1.OptimizeFor,
+		Venues:          v1.Venues,
+		VerifiedPools:   v1.VerifiedPools,
+		ConvertedVia:    convertedVia,
+		Fallback:        v1.Fallback,
+		GasSource:       v1.GasSource,
+		GasCost:         v1.GasCost,
+		Transaction:     v1.Transaction,
+		Approval:        v1.Approval,
+		RFQOrder:        v1.RFQOrder,
+		RouteProcessor:  v1.RouteProcessor,
+		WalletRequests:  v1.WalletRequests,
+		BlockNumber:     v1.BlockNumber,
+		BlockAgeSeconds: v1.BlockAgeSeconds,
+		Sources:         sources,
+		ExecutionPlan:   newExecutionPlanRespV2(quote.ExecutionPlan, quote.TokenIn, quote.TokenOut),
+	}
+}
+
+func newTokenResp(token entities.Token) TokenResp {
+	return TokenResp{
+		Address:  token.Address.Hex(),
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+// maxLadderAmounts caps the sizes one ladder quote may ask for
+const maxLadderAmounts = 10
+
+// LadderResponse quotes one pair at several sizes, in the order asked, so
+// a UI can draw the pair's size/impact curve from one request
+type LadderResponse struct {
+	TokenIn  string            `json:"tokenIn"`
+	TokenOut string            `json:"tokenOut"`
+	Quotes   []LadderQuoteResp `json:"quotes"`
+	Timing   *TimingResp       `json:"timing,omitempty"` // Only with debug=true
+}
+
+// LadderQuoteResp is one size of a ladder; Error is set instead of Quote
+// when that size couldn't be quoted
+type LadderQuoteResp struct {
+	AmountIn string         `json:"amountIn"`
+	Quote    *QuoteResponse `json:"quote,omitempty"`
+	Error    *ErrorResponse `json:"error,omitempty"`
+}
+
+type LadderResponseV2 struct {
+	TokenIn  TokenResp           `json:"tokenIn"`
+	TokenOut TokenResp           `json:"tokenOut"`
+	Quotes   []LadderQuoteRespV2 `json:"quotes"`
+	Timing   *TimingResp         `json:"timing,omitempty"`
+}
+
+type LadderQuoteRespV2 struct {
+	AmountIn Amount           `json:"amountIn"`
+	Quote    *QuoteResponseV2 `json:"quote,omitempty"`
+	Error    *ProblemDetails  `json:"error,omitempty"`
+}
+
+// ladderRung is one size of a ladder quote
+type ladderRung struct {
+	amountIn *big.Int
+	quote    *entities.Quote
+	err      *apperror.Error
+}
+
+// parseLadderAmounts reads amounts=, a comma-separated list of sizes in
+// any form amountIn accepts
+func parseLadderAmounts(value string, token entities.Token) ([]*big.Int, *apperror.Error) {
+	fields := strings.Split(value, ",")
+	if len(fields) > maxLadderAmounts {
+		return nil, apperror.New(apperror.InvalidAmount, fmt.Sprintf("amounts: at most %d sizes", maxLadderAmounts))
+	}
+	amounts := make([]*big.Int, len(fields))
+	for i, field := range fields {
+		amount, reqErr := parseQuoteAmount("amounts", field, token)
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		amounts[i] = amount
+	}
+	return amounts, nil
+}
+
+// quoteLadder quotes every size of params.amounts against one snapshot of
+// the pair's pools. The first size reads the pools; the others reuse them
+// concurrently, priced with each pair's own math where the venue allows.
+func (h *QuoteHandler) quoteLadder(ctx context.Context, params *quoteParams) []ladderRung {
+	ctx = services.WithPairSnapshot(ctx)
+	rungs := make([]ladderRung, len(params.amounts))
+	quoteRung := func(i int) {
+		rung := *params
+		rung.amountIn = params.amounts[i]
+		rungs[i].amountIn = rung.amountIn
+		rungs[i].quote, rungs[i].err = h.quote(ctx, &rung)
+	}
+
+	quoteRung(0)
+	var wg sync.WaitGroup
+	for i := 1; i < len(rungs); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			quoteRung(i)
+		}(i)
+	}
+	wg.Wait()
+	return rungs
+}
+
+// ladderError is the error to answer with when no size of a ladder quoted
+func ladderError(rungs []ladderRung) *apperror.Error {
+	for _, rung := range rungs {
+		if rung.err == nil {
+			return nil
+		}
+	}
+	return rungs[0].err
+}
+
+// ladderQuotes are the sizes of a ladder that quoted
+func ladderQuotes(rungs []ladderRung) []*entities.Quote {
+	var quotes []*entities.Quote
+	for _, rung := range rungs {
+		if rung.quote != nil {
+			quotes = append(quotes, rung.quote)
+		}
+	}
+	return quotes
+}
+
+func (h *QuoteHandler) writeLadder(w http.ResponseWriter, r *http.Request, params *quoteParams, start time.Time) {
+	ctx, timing := services.WithQuoteTiming(r.Context())
+	rungs := h.quoteLadder(ctx, params)
+	if reqErr := ladderError(rungs); reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	lang := apperror.Language(r.Header.Get("Accept-Language"))
+	h.writeQuote(w, r, params, ladderQuotes(rungs), timing, start, func(timingResp *TimingResp) interface{} {
+		response := LadderResponse{
+			TokenIn:  params.tokenIn.Address.Hex(),
+			TokenOut: params.tokenOut.Address.Hex(),
+			Quotes:   make([]LadderQuoteResp, len(rungs)),
+			Timing:   timingResp,
+		}
+		for i, rung := range rungs {
+			response.Quotes[i].AmountIn = rung.amountIn.String()
+			if rung.err != nil {
+				errResp := newErrorResponse(rung.err, lang)
+				response.Quotes[i].Error = &errResp
+				continue
+			}
+			quote := h.buildQuoteResponse(rung.quote, params.verbose)
+			if params.audit {
+				quote.Audit = services.NewQuoteAudit(rung.quote)
+			}
+			response.Quotes[i].Quote = &quote
+		}
+		return response
+	})
+}
+
+func (h *QuoteHandler) writeLadderV2(w http.ResponseWriter, r *http.Request, params *quoteParams, start time.Time) {
+	ctx, timing := services.WithQuoteTiming(r.Context())
+	rungs := h.quoteLadder(ctx, params)
+	if reqErr := ladderError(rungs); reqErr != nil {
+		writeProblem(w, r, reqErr)
+		return
+	}
+
+	lang := apperror.Language(r.Header.Get("Accept-Language"))
+	h.writeQuote(w, r, params, ladderQuotes(rungs), timing, start, func(timingResp *TimingResp) interface{} {
+		response := LadderResponseV2{
+			TokenIn:  newTokenResp(params.tokenIn),
+			TokenOut: newTokenResp(params.tokenOut),
+			Quotes:   make([]LadderQuoteRespV2, len(rungs)),
+			Timing:   timingResp,
+		}
+		for i, rung := range rungs {
+			response.Quotes[i].AmountIn = newAmount(rung.amountIn, params.tokenIn.Decimals)
+			if rung.err != nil {
+				problem := newProblemDetails(rung.err, lang, r.URL.Path)
+				response.Quotes[i].Error = &problem
+				continue
+			}
+			quote := h.buildQuoteResponseV2(rung.quote)
+			if params.audit {
+				quote.Audit = services.NewQuoteAudit(rung.quote)
+			}
+			response.Quotes[i].Quote = &quote
+		}
+		return response
+	})
+}
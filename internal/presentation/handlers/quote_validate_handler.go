@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type QuoteValidationResponse struct {
+	QuoteID          string `json:"quoteId"`
+	Valid            bool   `json:"valid"`
+	Reason           string `json:"reason,omitempty"`
+	ExpiresAt        int64  `json:"expiresAt"`
+	MinAmountOut     string `json:"minAmountOut,omitempty"`
+	CurrentAmountOut string `json:"currentAmountOut,omitempty"`
+}
+
+// SetQuoteBook keeps served quotes and enables
+// GET /api/v1/quote/{id}/validate
+func (h *QuoteHandler) SetQuoteBook(quoteBook *services.QuoteBook) {
+	h.quoteBook = quoteBook
+}
+
+// ValidateQuote handles GET /api/v1/quote/{id}/validate. Expired quotes are
+// rejected with 410 Gone; a live quote is re-priced against current reserves.
+func (h *QuoteHandler) ValidateQuote(w http.ResponseWriter, r *http.Request) {
+	validation, err := h.quoteBook.Validate(r.Context(), chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, services.ErrQuoteNotFound):
+		WriteError(w, r, apperror.New(apperror.QuoteNotFound, "Quote not found"))
+		return
+	case errors.Is(err, services.ErrQuoteExpired):
+		WriteError(w, r, apperror.New(apperror.QuoteExpired, "Quote expired; request a new one"))
+		return
+	case err != nil:
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+
+	resp := QuoteValidationResponse{
+		QuoteID:   validation.QuoteID,
+		Valid:     validation.Valid,
+		Reason:    validation.Reason,
+		ExpiresAt: validation.ExpiresAt.Unix(),
+	}
+	if validation.MinAmountOut != nil {
+		resp.MinAmountOut = validation.MinAmountOut.String()
+	}
+	if validation.CurrentAmountOut != nil {
+		resp.CurrentAmountOut = validation.CurrentAmountOut.String()
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type RouteEvaluationRequest struct {
+	AmountIn  string            `json:"amountIn"`
+	Slippage  string            `json:"slippage,omitempty"` // Basis points or auto, as on GET /quote
+	Sender    string            `json:"sender,omitempty"`
+	Recipient string            `json:"recipient,omitempty"`
+	Hops      []RouteHopRequest `json:"hops"`
+}
+
+type RouteHopRequest struct {
+	DEX      string `json:"dex,omitempty"`
+	Pool     string `json:"pool"`
+	TokenIn  string `json:"tokenIn"`
+	TokenOut string `json:"tokenOut"`
+}
+
+type RouteEvaluationResponse struct {
+	Route     QuoteResponse  `json:"route"` // The submitted route
+	Best      *QuoteResponse `json:"best,omitempty"`
+	BestError string         `json:"bestError,omitempty"`
+	DeltaBps  *int64         `json:"deltaBps,omitempty"` // Positive when the submitted route pays more
+}
+
+// EvaluateRoute handles POST /api/v1/route/evaluate, pricing a route through
+// the pools the client picked and comparing it to the router's best
+func (h *QuoteHandler) EvaluateRoute(w http.ResponseWriter, r *http.Request) {
+	var req RouteEvaluationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, r, apperror.New(apperror.InvalidBody, "request body must be JSON"))
+		return
+	}
+	if len(req.Hops) == 0 || len(req.Hops) > services.MaxRouteLegs {
+		WriteError(w, r, apperror.New(apperror.InvalidRoute, fmt.Sprintf("hops must list 1-%d pools", services.MaxRouteLegs)))
+		return
+	}
+
+	// The route's ends and options are validated like a quote request
+	params, reqErr := h.parseQuoteValues(r.Context(), url.Values{
+		"tokenIn":   {req.Hops[0].TokenIn},
+		"tokenOut":  {req.Hops[len(req.Hops)-1].TokenOut},
+		"amountIn":  {req.AmountIn},
+		"slippage":  {req.Slippage},
+		"sender":    {req.Sender},
+		"recipient": {req.Recipient},
+	})
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	if reqErr := h.checkBlocked(params); reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	legs := make([]services.RouteLeg, 0, len(req.Hops))
+	for i, hop := range req.Hops {
+		if !common.IsHexAddress(hop.Pool) {
+			WriteError(w, r, apperror.New(apperror.InvalidRoute, fmt.Sprintf("hops[%d].pool must be an address", i)))
+			return
+		}
+		tokenIn, reqErr := h.resolveToken(r.Context(), fmt.Sprintf("hops[%d].tokenIn", i), hop.TokenIn)
+		if reqErr != nil {
+			WriteError(w, r, reqErr)
+			return
+		}
+		tokenOut, reqErr := h.resolveToken(r.Context(), fmt.Sprintf("hops[%d].tokenOut", i), hop.TokenOut)
+		if reqErr != nil {
+			WriteError(w, r, reqErr)
+			return
+		}
+		tokenIn, _ = h.tokenRegistry.Wrap(tokenIn)
+		tokenOut, _ = h.tokenRegistry.Wrap(tokenOut)
+		legs = append(legs, services.RouteLeg{
+			DEX:      entities.DEXType(hop.DEX),
+			Pool:     common.HexToAddress(hop.Pool),
+			TokenIn:  tokenIn,
+			TokenOut: tokenOut,
+		})
+	}
+
+	evaluation, err := h.routerService.EvaluateRoute(r.Context(), legs, params.amountIn, params.slippageBps)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.RPCUnavailable))
+		return
+	}
+	if params.autoSlip {
+		h.slippageTuner.Tune(r.Context(), evaluation.Quote)
+	}
+	h.prepareQuote(r.Context(), params, evaluation.Quote)
+
+	response := RouteEvaluationResponse{Route: h.buildQuoteResponse(evaluation.Quote, false)}
+	if evaluation.Best != nil {
+		evaluation.Best.NativeIn, evaluation.Best.NativeOut = params.nativeIn, params.nativeOut
+		best := h.buildQuoteResponse(evaluation.Best, false)
+		response.Best = &best
+		response.DeltaBps = &evaluation.DeltaBps
+	} else if evaluation.BestErr != nil {
+		response.BestError = evaluation.BestErr.Error()
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
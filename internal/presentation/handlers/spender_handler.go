@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type SpenderHandler struct {
+	directory *services.SpenderDirectory
+}
+
+func NewSpenderHandler(directory *services.SpenderDirectory) *SpenderHandler {
+	return &SpenderHandler{directory: directory}
+}
+
+type SpenderResp struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	DEXes   []string `json:"dexes,omitempty"`
+	Purpose string   `json:"purpose"`
+}
+
+type SpendersResponse struct {
+	ChainID  uint64        `json:"chainId"`
+	Spenders []SpenderResp `json:"spenders"`
+}
+
+// ListSpenders handles GET /api/v1/spenders, listing the contracts users
+// approve for this deployment's swaps and orders. dex= keeps the spenders
+// that swap on that venue, and chainId= must name the served chain.
+func (h *SpenderHandler) ListSpenders(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if chainStr := q.Get("chainId"); chainStr != "" {
+		chainID, err := strconv.ParseUint(chainStr, 10, 64)
+		if err != nil || chainID != h.directory.ChainID() {
+			WriteError(w, r, apperror.New(apperror.InvalidChain, "chainId must be "+strconv.FormatUint(h.directory.ChainID(), 10)))
+			return
+		}
+	}
+	dex, reqErr := parseFilter(q, "dex",
+		string(entities.DEXUniswapV2), string(entities.DEXUniswapV3), string(entities.DEXSushiswap),
+		string(entities.DEXCurve), string(entities.DEXBalancer), string(entities.DEXLido), string(entities.DEXWrapper), string(entities.DEXRFQ),
+		string(entities.DEXMakerPSM))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	response := SpendersResponse{ChainID: h.directory.ChainID(), Spenders: []SpenderResp{}}
+	for _, spender := range h.directory.List(entities.DEXType(dex)) {
+		resp := SpenderResp{Name: spender.Name, Address: spender.Address.Hex(), Purpose: spender.Purpose}
+		for _, d := range spender.DEXes {
+			resp.DEXes = append(resp.DEXes, string(d))
+		}
+		response.Spenders = append(response.Spenders, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
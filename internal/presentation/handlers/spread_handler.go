@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type SpreadHandler struct {
+	spreadService *services.SpreadService
+	tokenRegistry *entities.TokenRegistry
+	nameResolver  NameResolver
+}
+
+func NewSpreadHandler(spreadService *services.SpreadService, tokenRegistry *entities.TokenRegistry, nameResolver NameResolver) *SpreadHandler {
+	return &SpreadHandler{
+		spreadService: spreadService,
+		tokenRegistry: tokenRegistry,
+		nameResolver:  nameResolver,
+	}
+}
+
+// VenuePriceResp is one venue's price for one whole tokenA in tokenB
+type VenuePriceResp struct {
+	DEX  string `json:"dex"`
+	Pool string `json:"pool"`
+	Bid  string `json:"bid"`
+	Ask  string `json:"ask"`
+}
+
+type SpreadResponse struct {
+	TokenA        string           `json:"tokenA"`
+	TokenB        string           `json:"tokenB"`
+	Bid           string           `json:"bid"`
+	BidDEX        string           `json:"bidDex"`
+	Ask           string           `json:"ask"`
+	AskDEX        string           `json:"askDex"`
+	SpreadBps     int64            `json:"spreadBps"`
+	DivergenceBps uint64           `json:"divergenceBps"`
+	Venues        []VenuePriceResp `json:"venues"`
+	Block         uint64           `json:"block,omitempty"`
+	UpdatedAt     int64            `json:"updatedAt"`
+}
+
+// GetSpread handles GET /api/v1/spread?tokenA=&tokenB=
+func (h *SpreadHandler) GetSpread(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("tokenA") == "" || query.Get("tokenB") == "" {
+		WriteError(w, r, apperror.New(apperror.MissingParams, "tokenA and tokenB are required"))
+		return
+	}
+	tokenA, reqErr := h.resolveToken(r, "tokenA", query.Get("tokenA"))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	tokenB, reqErr := h.resolveToken(r, "tokenB", query.Get("tokenB"))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	if tokenA.Address == tokenB.Address {
+		WriteError(w, r, apperror.New(apperror.InvalidToken, "tokenA and tokenB must differ"))
+		return
+	}
+	format, reqErr := parseNotation(r, tokenFormat(tokenB))
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	spread, err := h.spreadService.GetSpread(r.Context(), tokenA, tokenB)
+	if err != nil {
+		WriteError(w, r, apperror.As(err, apperror.NoRoute))
+		return
+	}
+
+	venues := make([]VenuePriceResp, 0, len(spread.Venues))
+	for _, venue := range spread.Venues {
+		venues = append(venues, VenuePriceResp{
+			DEX:  string(venue.DEX),
+			Pool: venue.Pool.Hex(),
+			Bid:  format.format(venue.Bid, tokenB.Decimals),
+			Ask:  format.format(venue.Ask, tokenB.Decimals),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SpreadResponse{
+		TokenA:        tokenA.Address.Hex(),
+		TokenB:        tokenB.Address.Hex(),
+		Bid:           format.format(spread.Bid, tokenB.Decimals),
+		BidDEX:        string(spread.BidDEX),
+		Ask:           format.format(spread.Ask, tokenB.Decimals),
+		AskDEX:        string(spread.AskDEX),
+		SpreadBps:     spread.SpreadBps,
+		DivergenceBps: spread.DivergenceBps,
+		Venues:        venues,
+		Block:         spread.Block,
+		UpdatedAt:     spread.UpdatedAt,
+	})
+}
+
+// resolveToken accepts an address or ENS name; the gas token is priced as
+// its wrapper
+func (h *SpreadHandler) resolveToken(r *http.Request, param, value string) (entities.Token, *apperror.Error) {
+	addr, err := parseAddress(r.Context(), h.nameResolver, value)
+	if err != nil {
+		return entities.Token{}, apperror.New(apperror.InvalidToken, param+": "+err.Error())
+	}
+	token, _ := h.tokenRegistry.Wrap(h.tokenRegistry.Resolve(r.Context(), addr))
+	return token, nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/bimakw/dex-aggregator/internal/apperror"
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type TokenHandler struct {
+	tokenRegistry *entities.TokenRegistry
+	taxService    *services.TokenTaxService
+	nameResolver  NameResolver
+}
+
+func NewTokenHandler(tokenRegistry *entities.TokenRegistry, taxService *services.TokenTaxService, nameResolver NameResolver) *TokenHandler {
+	return &TokenHandler{
+		tokenRegistry: tokenRegistry,
+		taxService:    taxService,
+		nameResolver:  nameResolver,
+	}
+}
+
+type TokenMetadataResponse struct {
+	entities.Token
+	Tax      *entities.TokenTax `json:"tax,omitempty"`
+	TaxError string             `json:"taxError,omitempty"` // Why taxes could not be measured
+}
+
+// GetToken handles GET /api/v1/tokens/{address}
+func (h *TokenHandler) GetToken(w http.ResponseWriter, r *http.Request) {
+	addr, err := parseAddress(r.Context(), h.nameResolver, chi.URLParam(r, "address"))
+	if err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.InvalidToken, err))
+		return
+	}
+
+	token := h.tokenRegistry.Resolve(r.Context(), addr)
+	response := TokenMetadataResponse{Token: token}
+	if token.Address != entities.WETH.Address && !token.IsNative() {
+		response.Tax, err = h.taxService.Detect(r.Context(), token)
+		if err != nil {
+			response.TaxError = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListTokens handles GET /api/v1/tokens?search=&sort=symbol|address&order=asc|desc&limit=&cursor=
+func (h *TokenHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, reqErr := parsePage(q)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	sortBy, reqErr := parseSort(q, "symbol", "address")
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	asc, reqErr := parseOrder(q, true)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+	search := strings.ToLower(q.Get("search"))
+
+	var matches []entities.Token
+	for _, token := range h.tokenRegistry.GetAll() {
+		if search == "" ||
+			strings.Contains(strings.ToLower(token.Symbol), search) ||
+			strings.Contains(strings.ToLower(token.Name), search) {
+			matches = append(matches, token)
+		}
+	}
+
+	tokens := paginate(matches, page, func(a, b entities.Token) bool {
+		if sortBy == "symbol" && !strings.EqualFold(a.Symbol, b.Symbol) {
+			return (strings.ToLower(a.Symbol) < strings.ToLower(b.Symbol)) == asc
+		}
+		// Addresses are unique, so they order ties and keep pages stable
+		return (a.Address.Cmp(b.Address) < 0) == asc
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newPage(tokens, page, len(matches)))
+}
+
+type TokenReloadResponse struct {
+	Tokens int `json:"tokens"` // Registered after the reload
+}
+
+// ReloadTokens handles POST /api/v1/admin/tokens/reload, re-reading the
+// token list without a restart
+func (h *TokenHandler) ReloadTokens(w http.ResponseWriter, r *http.Request) {
+	if err := h.tokenRegistry.Reload(); err != nil {
+		WriteError(w, r, apperror.Wrap(apperror.Internal, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TokenReloadResponse{Tokens: h.tokenRegistry.Count()})
+}
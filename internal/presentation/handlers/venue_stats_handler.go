@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/domain/services"
+)
+
+type VenueStatsHandler struct {
+	stats  *services.VenueStats
+	venues []entities.DEXType
+}
+
+// NewVenueStatsHandler serves stats for venues, the ones dex= may name
+func NewVenueStatsHandler(stats *services.VenueStats, venues []entities.DEXType) *VenueStatsHandler {
+	return &VenueStatsHandler{stats: stats, venues: venues}
+}
+
+type VenueStatsResponse struct {
+	WindowSeconds int64                 `json:"windowSeconds"`
+	Venues        []entities.VenueStats `json:"venues"`
+}
+
+// GetStats handles GET /api/v1/stats/venues: each venue's quote successes,
+// errors and empty results over the window, per pair, and its circuit
+// state. dex= keeps one venue.
+func (h *VenueStatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, len(h.venues))
+	for i, venue := range h.venues {
+		names[i] = string(venue)
+	}
+	dex, reqErr := parseFilter(r.URL.Query(), "dex", names...)
+	if reqErr != nil {
+		WriteError(w, r, reqErr)
+		return
+	}
+
+	response := VenueStatsResponse{WindowSeconds: int64(h.stats.Window().Seconds()), Venues: []entities.VenueStats{}}
+	for _, venue := range h.stats.Stats() {
+		if dex == "" || venue.DEX == entities.DEXType(dex) {
+			response.Venues = append(response.Venues, venue)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
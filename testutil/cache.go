@@ -0,0 +1,130 @@
+package testutil
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/cache"
+)
+
+// FakeCache is a cache.Cache in memory whose entries expire by a clock, so
+// TTL behaviour can be tested by advancing a FakeClock
+type FakeCache struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]fakeEntry
+	err     error
+	hits    int
+	misses  int
+}
+
+type fakeEntry struct {
+	pair      *entities.Pair
+	price     string
+	expiresAt time.Time
+}
+
+var _ cache.Cache = (*FakeCache)(nil)
+
+// NewFakeCache expires entries by now, e.g. a FakeClock's Now
+func NewFakeCache(now func() time.Time) *FakeCache {
+	return &FakeCache{now: now, entries: make(map[string]fakeEntry)}
+}
+
+// SetError makes every call fail with err, as an unreachable Redis would;
+// nil clears it
+func (c *FakeCache) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Hits and Misses count lookups of live and of missing or expired entries
+func (c *FakeCache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+func (c *FakeCache) Misses() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+func (c *FakeCache) GetPair(ctx context.Context, key string) (*entities.Pair, error) {
+	entry, ok, err := c.get(key)
+	if !ok || entry.pair == nil {
+		return nil, err
+	}
+	return entry.pair, nil
+}
+
+func (c *FakeCache) SetPair(ctx context.Context, key string, pair *entities.Pair, ttl time.Duration) error {
+	return c.set(key, fakeEntry{pair: pair}, ttl)
+}
+
+func (c *FakeCache) GetPrice(ctx context.Context, key string) (string, error) {
+	entry, _, err := c.get(key)
+	return entry.price, err
+}
+
+func (c *FakeCache) SetPrice(ctx context.Context, key string, price string, ttl time.Duration) error {
+	return c.set(key, fakeEntry{price: price}, ttl)
+}
+
+func (c *FakeCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	delete(c.entries, key)
+	return nil
+}
+
+// Flush drops pairs and prices, like RedisCache.Flush
+func (c *FakeCache) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	for key := range c.entries {
+		if strings.HasPrefix(key, "pair:") || strings.HasPrefix(key, "price:") {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+func (c *FakeCache) get(key string) (fakeEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return fakeEntry{}, false, c.err
+	}
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		c.misses++
+		return fakeEntry{}, false, nil
+	}
+	c.hits++
+	return entry, true, nil
+}
+
+func (c *FakeCache) set(key string, entry fakeEntry, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	entry.expiresAt = c.now().Add(ttl)
+	c.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,118 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FakeChain is a node that accepts transactions into a mempool it never
+// mines by itself. It serves the txmanager backend, gas estimates and fee
+// history, so a swap can be quoted, signed, broadcast and confirmed
+// without one.
+type FakeChain struct {
+	mu          sync.Mutex
+	nonce       uint64
+	rejections  int
+	sent        []*types.Transaction
+	receipts    map[common.Hash]*types.Receipt
+	blockNumber uint64
+	gas         uint64
+	baseFee     *big.Int
+	tip         *big.Int
+}
+
+// NewFakeChain estimates every call at gas and prices blocks at baseFee
+// plus tip
+func NewFakeChain(gas uint64, baseFee, tip *big.Int) *FakeChain {
+	return &FakeChain{
+		receipts: make(map[common.Hash]*types.Receipt),
+		gas:      gas,
+		baseFee:  baseFee,
+		tip:      tip,
+	}
+}
+
+// RejectUnderpriced fails the next n sends as the mempool does a
+// replacement without enough fee bump
+func (c *FakeChain) RejectUnderpriced(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rejections = n
+}
+
+// Sent returns the transactions accepted so far
+func (c *FakeChain) Sent() []*types.Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*types.Transaction(nil), c.sent...)
+}
+
+// Mine includes the transaction with hash in block and moves the head there
+func (c *FakeChain) Mine(hash common.Hash, block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.receipts[hash] = &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      hash,
+		BlockNumber: new(big.Int).SetUint64(block),
+		GasUsed:     c.gas,
+	}
+	c.blockNumber = max(c.blockNumber, block)
+}
+
+func (c *FakeChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nonce, nil
+}
+
+func (c *FakeChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rejections > 0 {
+		c.rejections--
+		return errors.New("replacement transaction underpriced")
+	}
+	c.sent = append(c.sent, tx)
+	c.nonce = max(c.nonce, tx.Nonce()+1)
+	return nil
+}
+
+func (c *FakeChain) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if receipt, ok := c.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *FakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.blockNumber, nil
+}
+
+func (c *FakeChain) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return c.gas, nil
+}
+
+func (c *FakeChain) FeeHistory(ctx context.Context, blockCount uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	history := &ethereum.FeeHistory{OldestBlock: new(big.Int)}
+	for range blockCount {
+		history.BaseFee = append(history.BaseFee, c.baseFee)
+		reward := make([]*big.Int, len(rewardPercentiles))
+		for i := range reward {
+			reward[i] = c.tip
+		}
+		history.Reward = append(history.Reward, reward)
+	}
+	history.BaseFee = append(history.BaseFee, c.baseFee) // The pending block
+	return history, nil
+}
@@ -0,0 +1,141 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+// FakeDEX is a dex.DEXClient serving the pools it is given. Amounts come
+// from each pool's own math unless fixed with SetAmountOut.
+type FakeDEX struct {
+	dexType entities.DEXType
+
+	mu        sync.Mutex
+	pairs     map[[2]common.Address]*entities.Pair
+	amountOut *big.Int
+	err       error
+	caps      dex.Capabilities
+	calls     int
+	reads     map[[2]common.Address]int
+}
+
+var _ dex.DEXClient = (*FakeDEX)(nil)
+
+func NewFakeDEX(dexType entities.DEXType) *FakeDEX {
+	return &FakeDEX{
+		dexType: dexType,
+		pairs:   make(map[[2]common.Address]*entities.Pair),
+		reads:   make(map[[2]common.Address]int),
+		caps:    dex.Capabilities{FeeModel: dex.FeeFixed},
+	}
+}
+
+// SetPair serves pair for its two tokens, in either order
+func (d *FakeDEX) SetPair(pair *entities.Pair) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pairs[fakePairKey(pair.Token0.Address, pair.Token1.Address)] = pair
+}
+
+// SetAmountOut makes every GetAmountOut return amount; nil restores the
+// pools' math
+func (d *FakeDEX) SetAmountOut(amount *big.Int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.amountOut = amount
+}
+
+// SetError makes every call fail with err, as an unreachable node would;
+// nil clears it
+func (d *FakeDEX) SetError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+}
+
+func (d *FakeDEX) SetCapabilities(caps dex.Capabilities) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.caps = caps
+}
+
+// Calls returns how many pool and amount reads the client has served,
+// to check what a cache saved
+func (d *FakeDEX) Calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.calls
+}
+
+// Reads returns how many of those calls asked for the pool of two tokens,
+// in either order
+func (d *FakeDEX) Reads(tokenA, tokenB common.Address) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.reads[fakePairKey(tokenA, tokenB)]
+}
+
+func (d *FakeDEX) GetPairAddress(ctx context.Context, tokenA, tokenB common.Address) (common.Address, error) {
+	pair, err := d.pair(tokenA, tokenB)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return pair.Address, nil
+}
+
+func (d *FakeDEX) GetPairByTokens(ctx context.Context, tokenA, tokenB entities.Token) (*entities.Pair, error) {
+	return d.pair(tokenA.Address, tokenB.Address)
+}
+
+func (d *FakeDEX) GetAmountOut(ctx context.Context, amountIn *big.Int, tokenIn, tokenOut entities.Token) (*big.Int, error) {
+	pair, err := d.pair(tokenIn.Address, tokenOut.Address)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	fixed := d.amountOut
+	d.mu.Unlock()
+	if fixed != nil {
+		return new(big.Int).Set(fixed), nil
+	}
+	return pair.GetAmountOut(amountIn, tokenIn.Address), nil
+}
+
+func (d *FakeDEX) DEXType() entities.DEXType {
+	return d.dexType
+}
+
+func (d *FakeDEX) Capabilities() dex.Capabilities {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.caps
+}
+
+func (d *FakeDEX) pair(tokenA, tokenB common.Address) (*entities.Pair, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	d.reads[fakePairKey(tokenA, tokenB)]++
+	if d.err != nil {
+		return nil, d.err
+	}
+	pair, ok := d.pairs[fakePairKey(tokenA, tokenB)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no %s/%s pool", dex.ErrPoolNotFound, d.dexType, tokenA.Hex(), tokenB.Hex())
+	}
+	return pair, nil
+}
+
+func fakePairKey(tokenA, tokenB common.Address) [2]common.Address {
+	if tokenB.Hex() < tokenA.Hex() {
+		tokenA, tokenB = tokenB, tokenA
+	}
+	return [2]common.Address{tokenA, tokenB}
+}
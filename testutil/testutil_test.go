@@ -0,0 +1,108 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/bimakw/dex-aggregator/internal/domain/entities"
+	"github.com/bimakw/dex-aggregator/internal/infrastructure/dex"
+)
+
+func TestFakeCacheExpiresByClock(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	c := NewFakeCache(clock.Now)
+
+	c.SetPrice(ctx, "price:a", "1.5", time.Minute)
+	clock.Advance(59 * time.Second)
+	if price, _ := c.GetPrice(ctx, "price:a"); price != "1.5" {
+		t.Errorf("GetPrice(live) = %q, want 1.5", price)
+	}
+	clock.Advance(time.Second)
+	if price, _ := c.GetPrice(ctx, "price:a"); price != "" {
+		t.Errorf("GetPrice(expired) = %q, want a miss", price)
+	}
+	if c.Hits() != 1 || c.Misses() != 1 {
+		t.Errorf("hits, misses = %d, %d, want 1, 1", c.Hits(), c.Misses())
+	}
+
+	down := errors.New("connection refused")
+	c.SetError(down)
+	if _, err := c.GetPair(ctx, "pair:a"); !errors.Is(err, down) {
+		t.Errorf("GetPair() error = %v, want %v", err, down)
+	}
+}
+
+func TestFakeDEX(t *testing.T) {
+	ctx := context.Background()
+	tokenA := entities.Token{Address: common.HexToAddress("0x01"), Decimals: 18}
+	tokenB := entities.Token{Address: common.HexToAddress("0x02"), Decimals: 18}
+	fake := NewFakeDEX(entities.DEXUniswapV2)
+	fake.SetPair(&entities.Pair{
+		Address: common.HexToAddress("0xaa"), Token0: tokenA, Token1: tokenB,
+		Reserve0: big.NewInt(1e6), Reserve1: big.NewInt(1e6), DEX: entities.DEXUniswapV2, Fee: 30,
+	})
+
+	// Either token order finds the pool, which prices the trade
+	pair, err := fake.GetPairByTokens(ctx, tokenB, tokenA)
+	if err != nil || pair.Address != common.HexToAddress("0xaa") {
+		t.Fatalf("GetPairByTokens() = %v, %v", pair, err)
+	}
+	out, err := fake.GetAmountOut(ctx, big.NewInt(1000), tokenA, tokenB)
+	if err != nil || out.Cmp(pair.GetAmountOut(big.NewInt(1000), tokenA.Address)) != 0 {
+		t.Errorf("GetAmountOut() = %v, %v", out, err)
+	}
+	fake.SetAmountOut(big.NewInt(7))
+	if out, _ := fake.GetAmountOut(ctx, big.NewInt(1000), tokenA, tokenB); out.Int64() != 7 {
+		t.Errorf("GetAmountOut(fixed) = %v, want 7", out)
+	}
+
+	other := entities.Token{Address: common.HexToAddress("0x03")}
+	if _, err := fake.GetPairByTokens(ctx, tokenA, other); !errors.Is(err, dex.ErrPoolNotFound) {
+		t.Errorf("GetPairByTokens(no pool) error = %v, want ErrPoolNotFound", err)
+	}
+	if fake.Calls() != 4 {
+		t.Errorf("Calls() = %d, want 4", fake.Calls())
+	}
+}
+
+func TestFakeChain(t *testing.T) {
+	ctx := context.Background()
+	chain := NewFakeChain(21000, big.NewInt(10), big.NewInt(2))
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 4})
+
+	chain.RejectUnderpriced(1)
+	if err := chain.SendTransaction(ctx, tx); err == nil {
+		t.Fatal("SendTransaction() accepted a send it was told to reject")
+	}
+	if err := chain.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("SendTransaction() error = %v", err)
+	}
+	if nonce, _ := chain.PendingNonceAt(ctx, common.Address{}); nonce != 5 || len(chain.Sent()) != 1 {
+		t.Errorf("pending nonce = %d, sent = %d, want 5 and 1", nonce, len(chain.Sent()))
+	}
+
+	if _, err := chain.TransactionReceipt(ctx, tx.Hash()); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("TransactionReceipt(pending) error = %v, want NotFound", err)
+	}
+	chain.Mine(tx.Hash(), 12)
+	receipt, err := chain.TransactionReceipt(ctx, tx.Hash())
+	if err != nil || receipt.BlockNumber.Uint64() != 12 || receipt.GasUsed != 21000 {
+		t.Errorf("TransactionReceipt(mined) = %+v, %v", receipt, err)
+	}
+	if head, _ := chain.BlockNumber(ctx); head != 12 {
+		t.Errorf("BlockNumber() = %d, want 12", head)
+	}
+
+	history, _ := chain.FeeHistory(ctx, 3, []float64{50})
+	if len(history.BaseFee) != 4 || len(history.Reward) != 3 || history.Reward[2][0].Int64() != 2 {
+		t.Errorf("FeeHistory() = %+v, want 3 blocks and the pending base fee", history)
+	}
+}